@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -41,6 +42,9 @@ var (
 	errSocketMode    = errors.New("socket server mode requested")
 )
 
+// promptInput is where "-" reads the prompt from; replaced in tests
+var promptInput io.Reader = os.Stdin
+
 type stringSlice []string
 
 const maxPasswordAttempts = 3
@@ -95,6 +99,10 @@ func run() (err error) {
 		}
 	}
 
+	if cliOptions != nil {
+		tui.SetColorMode(cliOptions.Color)
+	}
+
 	// Start pprof handler if configured
 	var pprofHandler *pprof.Handler
 	if pprofCfg != nil && (pprofCfg.HTTPAddr != "" || pprofCfg.CPUProfile != "" || pprofCfg.HeapProfile != "" ||
@@ -178,6 +186,7 @@ func run() (err error) {
 	if err != nil {
 		return fmt.Errorf("failed to initialize provider manager: %w", err)
 	}
+	useProviderRedactor(cfg, providerMgr)
 
 	// Refresh models from APIs - use synchronous version in CLI mode to ensure models are available
 	// before orchestrator creation, use async in TUI mode for better startup performance
@@ -230,6 +239,16 @@ func runCLI(cfg *config.Config, providerMgr *provider.Manager, prompt string, op
 		)
 	}
 
+	if options != nil && options.DryRun && options.SocketClientMode {
+		return fmt.Errorf("--dry-run cannot be combined with --connect-to-socket")
+	}
+	if options != nil && options.ReadOnly && options.SocketClientMode {
+		return fmt.Errorf("--read-only cannot be combined with --connect-to-socket")
+	}
+	if options != nil && options.PolicyFile != "" && options.SocketClientMode {
+		return fmt.Errorf("--policy cannot be combined with --connect-to-socket")
+	}
+
 	// Check if socket client mode is explicitly enabled or auto-detected
 	useSocketMode := false
 	if options != nil && options.SocketClientMode {
@@ -417,6 +436,7 @@ func runACPMode() error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize provider manager: %w", err)
 	}
+	useProviderRedactor(cfg, providerMgr)
 
 	// Refresh models from APIs
 	ctx := context.Background()
@@ -627,10 +647,24 @@ func runSocketServer(cfg *config.Config, providerMgr *provider.Manager, secretsP
 	<-sigChan
 	logger.Info("Shutdown signal received")
 
+	// Let running generations finish; a second signal forces the shutdown
+	drainTimeout := cfg.Socket.GetShutdownTimeout()
+	fmt.Fprintf(os.Stderr, "Shutting down, waiting up to %s for running generations (signal again to force)...\n", drainTimeout)
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	go func() {
+		select {
+		case <-sigChan:
+			logger.Info("Second shutdown signal received, forcing shutdown")
+			cancelDrain()
+		case <-drainCtx.Done():
+		}
+	}()
+
 	// Stop server (this also removes the socket file)
-	if err := srv.Stop(); err != nil {
+	if err := srv.StopWithTimeout(drainCtx); err != nil {
 		logger.Error("Error stopping socket server: %v", err)
 	}
+	cancelDrain()
 
 	// Explicitly clean up lockfile and pidfile before returning
 	// (defers may not run if process exits too quickly)
@@ -677,6 +711,14 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 		jsonOutput         bool
 		jsonExtended       bool
 		jsonFull           bool
+		jsonTrace          bool
+		dryRun             bool
+		readOnly           bool
+		policyFile         string
+		promptFile         string
+		colorFlag          string
+		temperature        *float64
+		seed               *int
 
 		// pprof flags
 		pprofAddr                 string
@@ -709,6 +751,28 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 	fs.BoolVar(&jsonOutput, "json", false, "Output final assistant message and usage as JSON")
 	fs.BoolVar(&jsonExtended, "json-extended", false, "Output all messages as JSON one-liners plus usage statistics")
 	fs.BoolVar(&jsonFull, "json-full", false, "Output all messages with full tool call outputs as single JSON object")
+	fs.BoolVar(&jsonTrace, "json-trace", false, "Output --json plus an ordered trace of tool calls with parameters, truncated results and timing (combines with --json-full)")
+	fs.BoolVar(&dryRun, "dry-run", false, "Simulate file changes, shell commands and go_sandbox runs instead of executing them")
+	fs.BoolVar(&readOnly, "read-only", false, "Only allow reading and searching; write tools, shell commands and go_sandbox are unavailable")
+	fs.StringVar(&policyFile, "policy", "", "Authorization policy file whose allow/deny rules decide tool calls without prompting")
+	fs.StringVar(&promptFile, "prompt-file", "", "Read the prompt from a file instead of the arguments")
+	fs.StringVar(&colorFlag, "color", "auto", "Colorize output: auto (honors NO_COLOR), always or never")
+	fs.Func("temperature", "Sampling temperature for the prompt (overrides SCRIPTSCHNELL_TEMPERATURE)", func(value string) error {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		temperature = &parsed
+		return nil
+	})
+	fs.Func("seed", "Sampling seed for the prompt, where the provider supports it (overrides SCRIPTSCHNELL_SEED)", func(value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		seed = &parsed
+		return nil
+	})
 	fs.BoolVar(&showHelp, "help", false, "Show CLI usage information")
 
 	// pprof flags
@@ -726,6 +790,10 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 			fmt.Printf("Failed to write usage: %v\n", err)
 			return
 		}
+		if _, err := fmt.Fprintln(fs.Output(), "Pass - as the prompt to read it from stdin, or use --prompt-file."); err != nil {
+			fmt.Printf("Failed to write usage: %v\n", err)
+			return
+		}
 		if _, err := fmt.Fprintln(fs.Output(), "CLI mode automatically connects to a running socket server if one is detected."); err != nil {
 			fmt.Printf("Failed to write usage: %v\n", err)
 			return
@@ -758,7 +826,13 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 		return "", nil, false, nil, false, false, false, "", false, flag.ErrHelp
 	}
 
+	colorMode, err := tui.ParseColorMode(colorFlag)
+	if err != nil {
+		return "", nil, false, nil, false, false, false, "", false, err
+	}
+
 	remaining := fs.Args()
+	hasPrompt := len(remaining) > 0 || promptFile != ""
 	optionsUsed := dangerous || allowNetwork || len(allowDirs) > 0 || len(allowFiles) > 0 || len(allowDomains) > 0
 
 	// Build pprof config (used across modes)
@@ -776,7 +850,7 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 
 	// Handle ACP mode
 	if acpMode {
-		if len(remaining) > 0 {
+		if hasPrompt {
 			return "", nil, false, nil, false, false, false, "", false, nil
 		}
 		if optionsUsed {
@@ -788,7 +862,7 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 
 	// Handle socket server mode
 	if socketServerMode {
-		if len(remaining) > 0 {
+		if hasPrompt {
 			return "", nil, false, nil, false, false, false, "", false, flag.ErrHelp
 		}
 		if optionsUsed {
@@ -800,7 +874,7 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 
 	// Handle web mode
 	if webMode {
-		if len(remaining) > 0 {
+		if hasPrompt {
 			return "", nil, false, nil, false, false, false, "", false, flag.ErrHelp
 		}
 		if optionsUsed {
@@ -810,20 +884,21 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 		return "", nil, false, pprofCfg, true, webDebug, false, "", requireSandboxAuth, nil
 	}
 
-	if len(remaining) == 0 {
+	if !hasPrompt {
 		// TUI mode - return options with RequireSandboxAuth and socket flags
 		opts := &cli.Options{
 			RequireSandboxAuth: requireSandboxAuth,
 			SocketClientMode:   socketClientMode,
 			SocketClientPath:   socketClientPath,
 			NoSocket:           noSocket,
+			Color:              colorMode,
 		}
 		return "", opts, false, pprofCfg, false, false, false, "", false, nil
 	}
 
-	prompt := strings.TrimSpace(strings.Join(remaining, " "))
-	if prompt == "" {
-		return "", nil, false, nil, false, false, false, "", false, fmt.Errorf("prompt must not be empty")
+	prompt, err := resolvePrompt(remaining, promptFile, promptInput)
+	if err != nil {
+		return "", nil, false, nil, false, false, false, "", false, err
 	}
 
 	opts := &cli.Options{
@@ -838,6 +913,13 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 		JSONOutput:          jsonOutput,
 		JSONExtended:        jsonExtended,
 		JSONFull:            jsonFull,
+		JSONTrace:           jsonTrace,
+		DryRun:              dryRun,
+		ReadOnly:            readOnly,
+		PolicyFile:          policyFile,
+		Temperature:         temperature,
+		Seed:                seed,
+		Color:               colorMode,
 	}
 	if dangerous {
 		opts.AllowAllNetwork = true
@@ -850,6 +932,42 @@ func parseCLIArgs(args []string) (string, *cli.Options, bool, *pprof.Config, boo
 	return prompt, opts, true, pprofCfg, false, false, false, "", false, nil
 }
 
+// resolvePrompt returns the CLI prompt from the inline arguments, stdin (a
+// single "-" argument) or a prompt file. Only one source may be used.
+func resolvePrompt(args []string, promptFile string, stdin io.Reader) (string, error) {
+	var prompt string
+	switch {
+	case promptFile != "":
+		if len(args) > 0 {
+			return "", fmt.Errorf("--prompt-file cannot be combined with an inline prompt")
+		}
+		data, err := os.ReadFile(promptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		prompt = string(data)
+	case len(args) == 1 && args[0] == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt from stdin: %w", err)
+		}
+		prompt = string(data)
+	default:
+		for _, arg := range args {
+			if arg == "-" {
+				return "", fmt.Errorf("- (read prompt from stdin) cannot be combined with an inline prompt")
+			}
+		}
+		prompt = strings.Join(args, " ")
+	}
+
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return "", fmt.Errorf("prompt must not be empty")
+	}
+	return prompt, nil
+}
+
 func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.Options) error {
 	logger.Info("Running in TUI mode")
 
@@ -1340,14 +1458,22 @@ func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.O
 					return nil
 
 				case tui.MenuTypeNewTab:
-					// Create a new tab with the specified name
+					// Create a new tab with the specified name and directory
 					if program != nil {
-						program.Send(tui.NewTabMsg{Name: menuResult.TabName})
+						program.Send(tui.NewTabMsg{Name: menuResult.TabName, WorkingDir: menuResult.TabDir})
+					}
+					return nil
+
+				case tui.MenuTypeSubmitPrompt:
+					// Submit an expanded snippet as if it was typed
+					if program != nil {
+						program.Send(tui.SubmitPromptMsg{Prompt: menuResult.Prompt})
 					}
 					return nil
 
 				case tui.MenuTypeSession:
-					// Open session management menu
+					// Open session management menu for the active tab's directory
+					sessionDir := model.ActiveWorkingDir()
 					var loadedSessionInfo *tui.LoadedSessionInfo
 					err := runOverlayMenu(func() error {
 						// Get session storage actor from factory
@@ -1356,7 +1482,7 @@ func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.O
 							return fmt.Errorf("session storage not initialized")
 						}
 
-						menu := tui.NewSessionMenu(ctx, storageRef, cfg.WorkingDir, 0, 0)
+						menu := tui.NewSessionMenu(ctx, storageRef, sessionDir, 0, 0)
 						subProgram := tea.NewProgram(menu, tea.WithAltScreen())
 						finalModel, err := subProgram.Run()
 						if err != nil {
@@ -1406,13 +1532,13 @@ func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.O
 								}
 
 								// Load the session using actor
-								loadedSession, err := actor.LoadSessionViaActor(ctx, storageRef, cfg.WorkingDir, sessionID)
+								loadedSession, err := actor.LoadSessionViaActor(ctx, storageRef, sessionDir, sessionID)
 								if err != nil {
 									return fmt.Errorf("failed to load session: %w", err)
 								}
 
 								// Get session name for display
-								sessions, _ := actor.ListSessionsViaActor(ctx, storageRef, cfg.WorkingDir)
+								sessions, _ := actor.ListSessionsViaActor(ctx, storageRef, sessionDir)
 								var sessionName string
 								for _, sess := range sessions {
 									if sess.ID == sessionID {
@@ -1444,7 +1570,7 @@ func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.O
 
 								// Delete the session using actor
 								deleteMsg := actor.SessionStorageDeleteMsg{
-									WorkingDir:   cfg.WorkingDir,
+									WorkingDir:   sessionDir,
 									SessionID:    sessionID,
 									ResponseChan: make(chan actor.SessionStorageDeleteResponse, 1),
 								}
@@ -1535,7 +1661,7 @@ func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.O
 	}
 
 	// Run TUI
-	program = tea.NewProgram(model, tea.WithAltScreen())
+	program = tea.NewProgram(model, tea.WithAltScreen(), tea.WithReportFocus())
 
 	// Set program reference for self-messaging (critical for per-tab message routing)
 	model.SetProgram(program)
@@ -1560,3 +1686,12 @@ func runTUI(cfg *config.Config, providerMgr *provider.Manager, cliOptions *cli.O
 
 	return nil
 }
+
+// useProviderRedactor masks the configured provider API keys in log lines,
+// using the replacement and entropy settings from the config
+func useProviderRedactor(cfg *config.Config, providerMgr *provider.Manager) {
+	redactor := providerMgr.Redactor()
+	redactor.SetReplacement(cfg.Redaction.Replacement)
+	redactor.SetHighEntropy(cfg.Redaction.HighEntropy)
+	logger.SetRedactor(redactor)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCLIArgsInlinePrompt(t *testing.T) {
+	prompt, opts, cliMode, _, _, _, _, _, _, err := parseCLIArgs([]string{"--json", "fix", "the", "tests"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cliMode || opts == nil || !opts.JSONOutput {
+		t.Fatalf("expected CLI mode with JSON output, got cliMode=%v opts=%+v", cliMode, opts)
+	}
+	if prompt != "fix the tests" {
+		t.Fatalf("prompt = %q, want %q", prompt, "fix the tests")
+	}
+}
+
+func TestParseCLIArgsPromptFromStdin(t *testing.T) {
+	previous := promptInput
+	promptInput = strings.NewReader("  # Task\nRefactor the parser\n")
+	defer func() { promptInput = previous }()
+
+	prompt, _, cliMode, _, _, _, _, _, _, err := parseCLIArgs([]string{"-"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cliMode {
+		t.Fatalf("expected CLI mode when reading the prompt from stdin")
+	}
+	if prompt != "# Task\nRefactor the parser" {
+		t.Fatalf("prompt = %q", prompt)
+	}
+}
+
+func TestParseCLIArgsPromptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.md")
+	if err := os.WriteFile(path, []byte("Write a changelog\n"), 0o644); err != nil {
+		t.Fatalf("failed to write prompt file: %v", err)
+	}
+
+	prompt, _, cliMode, _, _, _, _, _, _, err := parseCLIArgs([]string{"--prompt-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cliMode || prompt != "Write a changelog" {
+		t.Fatalf("expected prompt from file in CLI mode, got %q (cliMode=%v)", prompt, cliMode)
+	}
+}
+
+func TestParseCLIArgsMissingPromptFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.md")
+	_, _, _, _, _, _, _, _, _, err := parseCLIArgs([]string{"--prompt-file", missing})
+	if err == nil || !strings.Contains(err.Error(), "failed to read prompt file") {
+		t.Fatalf("expected read error for missing prompt file, got %v", err)
+	}
+}
+
+func TestParseCLIArgsPromptSourceConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.md")
+	if err := os.WriteFile(path, []byte("from file"), 0o644); err != nil {
+		t.Fatalf("failed to write prompt file: %v", err)
+	}
+
+	cases := [][]string{
+		{"--prompt-file", path, "inline", "prompt"},
+		{"--prompt-file", path, "-"},
+		{"-", "inline"},
+	}
+	for _, args := range cases {
+		if _, _, _, _, _, _, _, _, _, err := parseCLIArgs(args); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+			t.Fatalf("parseCLIArgs(%q) expected conflict error, got %v", args, err)
+		}
+	}
+}
+
+func TestParseCLIArgsEmptyStdinPrompt(t *testing.T) {
+	previous := promptInput
+	promptInput = strings.NewReader(" \n")
+	defer func() { promptInput = previous }()
+
+	if _, _, _, _, _, _, _, _, _, err := parseCLIArgs([]string{"-"}); err == nil {
+		t.Fatalf("expected error for empty prompt on stdin")
+	}
+}
+
+func TestParseCLIArgsSamplingOverrides(t *testing.T) {
+	_, opts, _, _, _, _, _, _, _, err := parseCLIArgs([]string{"--temperature", "0.2", "--seed", "42", "summarize"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Temperature == nil || *opts.Temperature != 0.2 {
+		t.Errorf("Temperature = %v, want 0.2", opts.Temperature)
+	}
+	if opts.Seed == nil || *opts.Seed != 42 {
+		t.Errorf("Seed = %v, want 42", opts.Seed)
+	}
+
+	_, opts, _, _, _, _, _, _, _, err = parseCLIArgs([]string{"summarize"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Temperature != nil || opts.Seed != nil {
+		t.Errorf("expected no sampling overrides without flags, got %v and %v", opts.Temperature, opts.Seed)
+	}
+
+	if _, _, _, _, _, _, _, _, _, err := parseCLIArgs([]string{"--seed", "abc", "summarize"}); err == nil {
+		t.Error("expected an invalid seed to be rejected")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/config"
@@ -54,8 +55,45 @@ type SlashCommand struct {
 	Handler     func(sessionID, args string) (string, error)
 }
 
-// GetAvailableCommands returns the list of available slash commands
+// GetAvailableCommands returns the list of available slash commands,
+// including one command per configured prompt snippet
 func (a *ScriptschnellAIAgent) GetAvailableCommands() []acp.AvailableCommand {
+	commands := builtinCommands()
+	if a.config == nil {
+		return commands
+	}
+
+	for _, name := range a.config.SnippetNames() {
+		if isBuiltinCommand(name) {
+			// Still available as /snippet <name>
+			continue
+		}
+		text, _ := a.config.GetSnippet(name)
+		commands = append(commands, acp.AvailableCommand{
+			Name:        name,
+			Description: "Snippet: " + truncateForLog(strings.Join(strings.Fields(text), " ")),
+			Input: &acp.AvailableCommandInput{
+				UnstructuredCommandInput: &acp.AvailableCommandUnstructuredCommandInput{
+					Hint: "snippet arguments",
+				},
+			},
+		})
+	}
+	return commands
+}
+
+// isBuiltinCommand reports whether name is one of the built-in slash commands
+func isBuiltinCommand(name string) bool {
+	for _, cmd := range builtinCommands() {
+		if cmd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinCommands returns the slash commands handled by executeSlashCommand
+func builtinCommands() []acp.AvailableCommand {
 	commands := []acp.AvailableCommand{
 		{
 			Name:        "init",
@@ -96,11 +134,59 @@ func (a *ScriptschnellAIAgent) GetAvailableCommands() []acp.AvailableCommand {
 				},
 			},
 		},
+		{
+			Name:        "snippet",
+			Description: "Submit or manage prompt snippets",
+			Input: &acp.AvailableCommandInput{
+				UnstructuredCommandInput: &acp.AvailableCommandUnstructuredCommandInput{
+					Hint: "<name> [args] | list | add <name> <template> | remove <name>",
+				},
+			},
+		},
 	}
 
 	return commands
 }
 
+// expandSnippetCommand expands "/snippet <name> [args]" and "/<name> [args]"
+// for configured snippets into the prompt to process. It reports false for
+// other commands, including the /snippet management subcommands.
+func (a *ScriptschnellAIAgent) expandSnippetCommand(command, args string) (string, bool, error) {
+	if a.config == nil {
+		return "", false, nil
+	}
+
+	fields := strings.Fields(args)
+	name := command
+	if command == "snippet" {
+		if len(fields) == 0 || isSnippetSubcommand(fields[0]) {
+			return "", false, nil
+		}
+		name, fields = fields[0], fields[1:]
+	} else if isBuiltinCommand(command) {
+		return "", false, nil
+	} else if _, ok := a.config.GetSnippet(command); !ok {
+		return "", false, nil
+	}
+
+	prompt, err := a.config.ExpandSnippet(name, fields)
+	if err != nil {
+		return "", true, err
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return "", true, fmt.Errorf("snippet %s expanded to an empty prompt", name)
+	}
+	return prompt, true, nil
+}
+
+func isSnippetSubcommand(arg string) bool {
+	switch arg {
+	case "list", "add", "remove", "help":
+		return true
+	}
+	return false
+}
+
 // parseSlashCommand parses a prompt to detect and extract slash commands
 func (a *ScriptschnellAIAgent) parseSlashCommand(promptText string) (command, args string, isCommand bool) {
 	// Trim leading whitespace
@@ -121,10 +207,9 @@ func (a *ScriptschnellAIAgent) parseSlashCommand(promptText string) (command, ar
 	}
 
 	command = strings.TrimPrefix(parts[0], "/")
-	if len(parts) > 1 {
-		// Join all remaining parts as args
-		args = strings.Join(parts[1:], " ")
-	}
+	// The args are the rest as typed, so commands like /snippet add keep
+	// line breaks and indentation
+	args = strings.TrimLeftFunc(strings.TrimPrefix(promptText, parts[0]), unicode.IsSpace)
 
 	logger.Debug("parseSlashCommand: detected command=%q args=%q", command, truncateForLog(args))
 	return command, args, true
@@ -162,6 +247,8 @@ func (a *ScriptschnellAIAgent) executeSlashCommand(sessionID, command, args stri
 		resp, err = a.handleContextCommand(args)
 	case "session":
 		resp, err = a.handleSessionCommand(args)
+	case "snippet":
+		resp, err = a.handleSnippetCommand(args)
 	default:
 		err = fmt.Errorf("unknown command: /%s", command)
 	}
@@ -350,6 +437,71 @@ func (a *ScriptschnellAIAgent) handleContextCommand(args string) (string, error)
 	}
 }
 
+// handleSnippetCommand handles the /snippet management subcommands
+func (a *ScriptschnellAIAgent) handleSnippetCommand(args string) (string, error) {
+	parts := strings.Fields(args)
+	if len(parts) == 0 || parts[0] == "help" {
+		return a.snippetHelp(), nil
+	}
+
+	switch parts[0] {
+	case "list":
+		names := a.config.SnippetNames()
+		if len(names) == 0 {
+			return "No snippets configured.\n\nUse /snippet add <name> <template> to add one.", nil
+		}
+		response := "📝 Configured snippets:\n\n"
+		for _, name := range names {
+			text, _ := a.config.GetSnippet(name)
+			response += fmt.Sprintf("- %s: %s\n", name, truncateForLog(text))
+		}
+		return response, nil
+	case "add":
+		name, text, err := config.ParseSnippetAddArgs(strings.TrimPrefix(strings.TrimLeftFunc(args, unicode.IsSpace), "add"))
+		if err != nil {
+			return "", err
+		}
+		if err := a.config.SetSnippet(name, text); err != nil {
+			return "", err
+		}
+		if err := a.config.Save(config.GetConfigPath()); err != nil {
+			return "", fmt.Errorf("failed to save config: %w", err)
+		}
+		return fmt.Sprintf("✓ Saved snippet %s. Use it with /%s [args] or /snippet %s [args]", name, name, name), nil
+	case "remove":
+		if len(parts) != 2 {
+			return "", fmt.Errorf("usage: /snippet remove <name>")
+		}
+		if !a.config.RemoveSnippet(parts[1]) {
+			return "", fmt.Errorf("snippet not found: %s", parts[1])
+		}
+		if err := a.config.Save(config.GetConfigPath()); err != nil {
+			return "", fmt.Errorf("failed to save config: %w", err)
+		}
+		return fmt.Sprintf("✓ Removed snippet: %s", parts[1]), nil
+	default:
+		return "", fmt.Errorf("unknown /snippet subcommand: %s", parts[0])
+	}
+}
+
+func (a *ScriptschnellAIAgent) snippetHelp() string {
+	return `📝 Prompt Snippet Commands:
+
+/snippet <name> [args]  (or /<name> [args])
+    Expand the snippet with the arguments and submit it as a prompt.
+
+/snippet list
+    Show the configured snippets.
+
+/snippet add <name> <template>
+    Add or replace a snippet. The template uses Go text/template syntax:
+    {{.Args}} is replaced by all arguments, {{index .Arg 0}} by the first one.
+
+/snippet remove <name>
+    Remove a snippet.
+`
+}
+
 func (a *ScriptschnellAIAgent) contextHelp() string {
 	return `📁 Context Directory Commands:
 
@@ -806,14 +958,14 @@ type ScriptschnellAIAgent struct {
 	clientCaps   *acp.ClientCapabilities // Store client capabilities
 	mu           sync.Mutex
 	ctx          context.Context
-	cancel       context.CancelFunc
+	cancel       context.CancelCauseFunc
 }
 
 type statcodeSession struct {
 	sessionID     string
 	orchestrator  *orchestrator.Orchestrator
 	promptCtx     context.Context
-	promptCancel  context.CancelFunc
+	promptCancel  context.CancelCauseFunc
 	isActive      bool
 	toolLocations map[string][]acp.ToolCallLocation
 	toolParams    map[string]map[string]interface{}
@@ -904,7 +1056,7 @@ func NewScriptschnellAIAgent(ctx context.Context, cfg *config.Config, providerMg
 	}
 	logger.Debug("NewScriptschnellAIAgent: base orchestrator created (%T)", orch)
 
-	agentCtx, agentCancel := context.WithCancel(ctx)
+	agentCtx, agentCancel := context.WithCancelCause(ctx)
 
 	agent := &ScriptschnellAIAgent{
 		config:       cfg,
@@ -1026,7 +1178,7 @@ func (a *ScriptschnellAIAgent) NewSession(ctx context.Context, params acp.NewSes
 		logger.Debug("NewSession[%s]: using orchestrator with ACP filesystem + todo actor", sessionID)
 	}
 
-	promptCtx, promptCancel := context.WithCancel(a.ctx)
+	promptCtx, promptCancel := context.WithCancelCause(a.ctx)
 
 	session := &statcodeSession{
 		sessionID:     sessionID,
@@ -1113,7 +1265,7 @@ func (a *ScriptschnellAIAgent) Cancel(ctx context.Context, params acp.CancelNoti
 	a.mu.Lock()
 	session, exists := a.sessions[sessionID]
 	if exists && session.promptCancel != nil {
-		session.promptCancel()
+		session.promptCancel(orchestrator.ErrCancelledByUser)
 		session.isActive = false
 		logger.Debug("Cancel[%s]: prompt cancelled", sessionID)
 	} else if !exists {
@@ -1143,11 +1295,11 @@ func (a *ScriptschnellAIAgent) Prompt(ctx context.Context, params acp.PromptRequ
 	// Cancel any previous prompt for this session
 	if session.promptCancel != nil {
 		logger.Debug("Prompt[%s]: cancelling previous prompt context", sessionID)
-		session.promptCancel()
+		session.promptCancel(orchestrator.ErrCancelledByNewPrompt)
 	}
 
 	// Create new context for this prompt
-	promptCtx, promptCancel := context.WithCancel(a.ctx)
+	promptCtx, promptCancel := context.WithCancelCause(a.ctx)
 	session.promptCtx = promptCtx
 	session.promptCancel = promptCancel
 	session.isActive = true
@@ -1170,6 +1322,24 @@ func (a *ScriptschnellAIAgent) Prompt(ctx context.Context, params acp.PromptRequ
 
 	// Check for slash commands at the beginning of the prompt
 	command, args, isCommand := a.parseSlashCommand(promptText)
+	if isCommand {
+		expanded, isSnippet, err := a.expandSnippetCommand(command, args)
+		if err != nil {
+			logger.Error("Error expanding snippet: %v", err)
+			if sendErr := a.conn.SessionUpdate(session.promptCtx, acp.SessionNotification{
+				SessionId: acp.SessionId(session.sessionID),
+				Update:    acp.UpdateAgentMessageText(fmt.Sprintf("❌ Error expanding snippet: %v", err)),
+			}); sendErr != nil {
+				logger.Error("Failed to send snippet error response: %v", sendErr)
+			}
+			return acp.PromptResponse{}, err
+		}
+		if isSnippet {
+			logger.Debug("Prompt[%s]: expanded snippet command /%s", sessionID, command)
+			promptText = expanded
+			isCommand = false
+		}
+	}
 	if isCommand {
 		logger.Info("Detected slash command: /%s", command)
 		logger.Debug("Prompt[%s]: slash command args=%q", sessionID, truncateForLog(args))
@@ -1211,6 +1381,7 @@ func (a *ScriptschnellAIAgent) Prompt(ctx context.Context, params acp.PromptRequ
 	if err != nil {
 		if session.promptCtx.Err() != nil {
 			// Prompt was cancelled
+			logger.Info("Prompt[%s]: cancelled (%v)", sessionID, context.Cause(session.promptCtx))
 			return acp.PromptResponse{StopReason: acp.StopReasonCancelled}, nil
 		}
 		logger.Error("Error processing prompt: %v", err)
@@ -1336,11 +1507,11 @@ func (a *ScriptschnellAIAgent) processPromptWithStreaming(session *statcodeSessi
 	}
 
 	// Create authorization callback for ACP
-	authCallback := func(toolName string, params map[string]interface{}, reason string) (bool, error) {
+	authCallback := func(toolName string, params map[string]interface{}, reason string) (orchestrator.AuthorizationResponse, error) {
 		logger.Debug("authCallback[%s]: tool=%s reason=%q params=%s", session.sessionID, toolName, reason, truncateMapForLog(params))
-		allowed, err := a.handleACPAuthorization(session, toolName, params, reason)
-		logger.Debug("authCallback[%s]: tool=%s allowed=%t err=%v", session.sessionID, toolName, allowed, err)
-		return allowed, err
+		resp, err := a.handleACPAuthorization(session, toolName, params, reason)
+		logger.Debug("authCallback[%s]: tool=%s allowed=%t edited=%t err=%v", session.sessionID, toolName, resp.Approved, resp.EditedParams != nil, err)
+		return resp, err
 	}
 
 	// Create tool call callbacks
@@ -1444,8 +1615,10 @@ func (a *ScriptschnellAIAgent) processPromptWithStreaming(session *statcodeSessi
 	return err
 }
 
-// handleACPAuthorization handles permission requests via ACP
-func (a *ScriptschnellAIAgent) handleACPAuthorization(session *statcodeSession, toolName string, params map[string]interface{}, reason string) (bool, error) {
+// handleACPAuthorization handles permission requests via ACP. Clients may
+// return edited tool parameters with an approval in the response's _meta
+// under editedParamsMetaKey.
+func (a *ScriptschnellAIAgent) handleACPAuthorization(session *statcodeSession, toolName string, params map[string]interface{}, reason string) (orchestrator.AuthorizationResponse, error) {
 	// Request permission from the client
 	logger.Debug("handleACPAuthorization[%s]: requesting permission for tool=%s", session.sessionID, toolName)
 	permResp, err := a.conn.RequestPermission(session.promptCtx, acp.RequestPermissionRequest{
@@ -1466,30 +1639,46 @@ func (a *ScriptschnellAIAgent) handleACPAuthorization(session *statcodeSession,
 
 	if err != nil {
 		logger.Warn("handleACPAuthorization[%s]: permission request failed: %v", session.sessionID, err)
-		return false, err
+		return orchestrator.AuthorizationResponse{}, err
 	}
 
 	if permResp.Outcome.Cancelled != nil {
 		logger.Debug("handleACPAuthorization[%s]: permission cancelled by client", session.sessionID)
-		return false, fmt.Errorf("authorization cancelled")
+		return orchestrator.AuthorizationResponse{}, fmt.Errorf("authorization cancelled")
 	}
 
 	if permResp.Outcome.Selected == nil {
 		logger.Debug("handleACPAuthorization[%s]: no option selected", session.sessionID)
-		return false, fmt.Errorf("no authorization option selected")
+		return orchestrator.AuthorizationResponse{}, fmt.Errorf("no authorization option selected")
 	}
 
 	switch string(permResp.Outcome.Selected.OptionId) {
 	case "allow":
-		logger.Debug("handleACPAuthorization[%s]: tool=%s authorized", session.sessionID, toolName)
-		return true, nil
+		edited := editedParamsFromMeta(permResp.Meta)
+		logger.Debug("handleACPAuthorization[%s]: tool=%s authorized (edited=%t)", session.sessionID, toolName, edited != nil)
+		return orchestrator.AuthorizationResponse{Approved: true, EditedParams: edited}, nil
 	case "deny":
 		logger.Debug("handleACPAuthorization[%s]: tool=%s denied", session.sessionID, toolName)
-		return false, nil
+		return orchestrator.AuthorizationResponse{}, nil
 	default:
 		logger.Warn("handleACPAuthorization[%s]: unexpected option %s", session.sessionID, permResp.Outcome.Selected.OptionId)
-		return false, fmt.Errorf("unexpected authorization option: %s", permResp.Outcome.Selected.OptionId)
+		return orchestrator.AuthorizationResponse{}, fmt.Errorf("unexpected authorization option: %s", permResp.Outcome.Selected.OptionId)
+	}
+}
+
+// editedParamsMetaKey is the _meta key of a permission response holding
+// edited tool parameters
+const editedParamsMetaKey = "editedParams"
+
+// editedParamsFromMeta extracts edited tool parameters from the _meta of a
+// permission response, nil if there are none
+func editedParamsFromMeta(meta any) map[string]interface{} {
+	fields, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil
 	}
+	params, _ := fields[editedParamsMetaKey].(map[string]interface{})
+	return params
 }
 
 // getToolKind determines the appropriate tool kind based on tool name and parameters
@@ -1839,6 +2028,11 @@ func (a *ScriptschnellAIAgent) handleToolCallResult(session *statcodeSession, to
 	if errorMsg != "" {
 		rawOutput["error"] = errorMsg
 	}
+	if session.orchestrator != nil {
+		if metadata := session.orchestrator.ToolResultMetadata(toolID); metadata != nil && metadata.Diff != nil {
+			rawOutput["diff_summary"] = metadata.Diff
+		}
+	}
 
 	updateOpts := []acp.ToolCallUpdateOpt{
 		acp.WithUpdateStatus(status),
@@ -1907,7 +2101,7 @@ func (a *ScriptschnellAIAgent) createFilesystemForSession(sessionID string, clie
 func (a *ScriptschnellAIAgent) Close() error {
 	logger.Info("Closing ACP agent")
 
-	a.cancel()
+	a.cancel(orchestrator.ErrCancelledByShutdown)
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -1917,7 +2111,7 @@ func (a *ScriptschnellAIAgent) Close() error {
 	// Cancel all active sessions and clean up orchestrators
 	for sessionID, session := range a.sessions {
 		if session.promptCancel != nil {
-			session.promptCancel()
+			session.promptCancel(orchestrator.ErrCancelledByShutdown)
 		}
 		logger.Debug("Close: tearing down session %s", sessionID)
 		if err := session.orchestrator.Close(); err != nil {
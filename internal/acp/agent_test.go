@@ -2,6 +2,7 @@ package acp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -450,3 +451,84 @@ func TestHandleContextAdd_HomeDirectoryRejection(t *testing.T) {
 		t.Errorf("expected no home directory error for subdirectory, got: %v", err)
 	}
 }
+
+func TestSnippetCommandsAdvertisedAndExpanded(t *testing.T) {
+	agent := newTestAgent(t)
+	if err := agent.config.SetSnippet("review", "Review {{.Args}} for security issues"); err != nil {
+		t.Fatalf("SetSnippet failed: %v", err)
+	}
+	if err := agent.config.SetSnippet("status", "Shadowed by the built-in {{.Args}}"); err != nil {
+		t.Fatalf("SetSnippet failed: %v", err)
+	}
+
+	advertised := make(map[string]int)
+	for _, cmd := range agent.GetAvailableCommands() {
+		advertised[cmd.Name]++
+	}
+	if advertised["review"] != 1 || advertised["snippet"] != 1 {
+		t.Errorf("expected the review snippet and /snippet to be advertised, got %v", advertised)
+	}
+	if advertised["status"] != 1 {
+		t.Errorf("expected a snippet not to shadow a built-in command, got %v", advertised)
+	}
+
+	tests := []struct {
+		command, args string
+		want          string
+		isSnippet     bool
+	}{
+		{"review", "main.go", "Review main.go for security issues", true},
+		{"snippet", "review main.go", "Review main.go for security issues", true},
+		{"snippet", "status x", "Shadowed by the built-in x", true},
+		{"snippet", "list", "", false},
+		{"status", "", "", false},
+		{"unknown", "", "", false},
+	}
+	for _, tt := range tests {
+		got, isSnippet, err := agent.expandSnippetCommand(tt.command, tt.args)
+		if err != nil {
+			t.Errorf("/%s %s: unexpected error %v", tt.command, tt.args, err)
+		}
+		if isSnippet != tt.isSnippet || got != tt.want {
+			t.Errorf("/%s %s: got (%q, %v), want (%q, %v)", tt.command, tt.args, got, isSnippet, tt.want, tt.isSnippet)
+		}
+	}
+
+	if _, _, err := agent.expandSnippetCommand("snippet", "missing"); err == nil {
+		t.Error("expected an error for an unknown snippet")
+	}
+}
+
+func TestSnippetAddKeepsTemplateWhitespace(t *testing.T) {
+	agent := newTestAgent(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+
+	command, args, ok := agent.parseSlashCommand("/snippet add checklist\n  Review {{.Args}}:\n\t- naming")
+	if !ok || command != "snippet" {
+		t.Fatalf("expected a /snippet command, got %q (%v)", command, ok)
+	}
+	if _, err := agent.handleSnippetCommand(args); err != nil {
+		t.Fatalf("adding the snippet failed: %v", err)
+	}
+	if got, _ := agent.config.GetSnippet("checklist"); got != "  Review {{.Args}}:\n\t- naming" {
+		t.Errorf("expected the template as typed, got %q", got)
+	}
+}
+
+func TestEditedParamsFromMeta(t *testing.T) {
+	var meta any
+	if err := json.Unmarshal([]byte(`{"editedParams":{"command":"make deploy --dry-run"}}`), &meta); err != nil {
+		t.Fatalf("failed to parse meta: %v", err)
+	}
+	params := editedParamsFromMeta(meta)
+	if params["command"] != "make deploy --dry-run" {
+		t.Errorf("expected the edited command, got %+v", params)
+	}
+
+	for _, meta := range []any{nil, "editedParams", map[string]interface{}{"other": true}, map[string]interface{}{"editedParams": "make"}} {
+		if params := editedParamsFromMeta(meta); params != nil {
+			t.Errorf("expected no edited params for %v, got %+v", meta, params)
+		}
+	}
+}
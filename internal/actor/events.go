@@ -16,6 +16,8 @@ const (
 	EventTypeProgress EventType = "progress"
 	// EventTypeMessage indicates a chat message event
 	EventTypeMessage EventType = "message"
+	// EventTypeReasoning indicates reasoning/thinking content of the model
+	EventTypeReasoning EventType = "reasoning"
 	// EventTypeToolCall indicates a tool execution event
 	EventTypeToolCall EventType = "tool_call"
 	// EventTypeToolResult indicates a tool execution result event
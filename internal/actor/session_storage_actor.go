@@ -83,12 +83,12 @@ func (a *SessionStorageActor) Receive(ctx context.Context, msg Message) error {
 		return nil
 	case SessionStorageListMsg:
 		logger.Debug("SessionStorageActor: received list message for workspace %s", m.WorkingDir)
-		sessions, err := a.storage.ListSessions(m.WorkingDir)
-		logger.Debug("SessionStorageActor: ListSessions returned %d sessions, err=%v", len(sessions), err)
+		sessions, total, err := a.storage.ListSessionsWithOptions(m.WorkingDir, m.Options)
+		logger.Debug("SessionStorageActor: ListSessions returned %d of %d sessions, err=%v", len(sessions), total, err)
 		if err != nil && a.health != nil {
 			a.health.RecordError(err)
 		}
-		m.ResponseChan <- SessionStorageListResponse{Sessions: sessions, Err: err}
+		m.ResponseChan <- SessionStorageListResponse{Sessions: sessions, Total: total, Err: err}
 		return nil
 	case SessionStorageDeleteMsg:
 		logger.Debug("SessionStorageActor: received delete message for session %s", m.SessionID)
@@ -99,6 +99,22 @@ func (a *SessionStorageActor) Receive(ctx context.Context, msg Message) error {
 		}
 		m.ResponseChan <- SessionStorageDeleteResponse{Err: err}
 		return nil
+	case SessionStorageRenameMsg:
+		logger.Debug("SessionStorageActor: received rename message for session %s", m.SessionID)
+		err := a.storage.RenameSession(m.WorkingDir, m.SessionID, m.Title)
+		if err != nil && a.health != nil {
+			a.health.RecordError(err)
+		}
+		m.ResponseChan <- SessionStorageRenameResponse{Err: err}
+		return nil
+	case SessionStorageDuplicateMsg:
+		logger.Debug("SessionStorageActor: received duplicate message for session %s", m.SessionID)
+		metadata, err := a.storage.DuplicateSession(m.WorkingDir, m.SessionID, m.Title)
+		if err != nil && a.health != nil {
+			a.health.RecordError(err)
+		}
+		m.ResponseChan <- SessionStorageDuplicateResponse{Session: metadata, Err: err}
+		return nil
 	case SessionStorageStartAutoSaveMsg:
 		logger.Debug("SessionStorageActor: received start autosave message for session %s", m.Session.ID)
 		a.storage.StartAutoSave(m.Session, m.Name)
@@ -160,6 +176,7 @@ type SessionStorageLoadResponse struct {
 
 type SessionStorageListMsg struct {
 	WorkingDir   string
+	Options      session.ListOptions
 	ResponseChan chan SessionStorageListResponse
 }
 
@@ -167,6 +184,7 @@ func (SessionStorageListMsg) Type() string { return "sessionStorageListMsg" }
 
 type SessionStorageListResponse struct {
 	Sessions []session.SessionMetadata
+	Total    int // number of sessions matching the options, before paging
 	Err      error
 }
 
@@ -182,6 +200,33 @@ type SessionStorageDeleteResponse struct {
 	Err error
 }
 
+type SessionStorageRenameMsg struct {
+	WorkingDir   string
+	SessionID    string
+	Title        string
+	ResponseChan chan SessionStorageRenameResponse
+}
+
+func (SessionStorageRenameMsg) Type() string { return "sessionStorageRenameMsg" }
+
+type SessionStorageRenameResponse struct {
+	Err error
+}
+
+type SessionStorageDuplicateMsg struct {
+	WorkingDir   string
+	SessionID    string
+	Title        string // Title of the copy (empty = "<original> (copy)")
+	ResponseChan chan SessionStorageDuplicateResponse
+}
+
+func (SessionStorageDuplicateMsg) Type() string { return "sessionStorageDuplicateMsg" }
+
+type SessionStorageDuplicateResponse struct {
+	Session *session.SessionMetadata
+	Err     error
+}
+
 type SessionStorageStartAutoSaveMsg struct {
 	Session      *session.Session
 	Name         string
@@ -270,22 +315,30 @@ func LoadSessionViaActor(ctx context.Context, storageRef *ActorRef, workingDir,
 
 // ListSessions returns all sessions for a workspace
 func ListSessionsViaActor(ctx context.Context, storageRef *ActorRef, workingDir string) ([]session.SessionMetadata, error) {
+	sessions, _, err := ListSessionsWithOptionsViaActor(ctx, storageRef, workingDir, session.ListOptions{})
+	return sessions, err
+}
+
+// ListSessionsWithOptionsViaActor returns one page of the sessions of a
+// workspace that match opts, together with the total number of matches
+func ListSessionsWithOptionsViaActor(ctx context.Context, storageRef *ActorRef, workingDir string, opts session.ListOptions) ([]session.SessionMetadata, int, error) {
 	responseChan := make(chan SessionStorageListResponse, 1)
 
 	msg := SessionStorageListMsg{
 		WorkingDir:   workingDir,
+		Options:      opts,
 		ResponseChan: responseChan,
 	}
 
 	if err := storageRef.Send(msg); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	select {
 	case response := <-responseChan:
-		return response.Sessions, response.Err
+		return response.Sessions, response.Total, response.Err
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, 0, ctx.Err()
 	}
 }
 
@@ -311,6 +364,52 @@ func DeleteSessionViaActor(ctx context.Context, storageRef *ActorRef, workingDir
 	}
 }
 
+// RenameSessionViaActor changes the title of a saved session
+func RenameSessionViaActor(ctx context.Context, storageRef *ActorRef, workingDir, sessionID, title string) error {
+	responseChan := make(chan SessionStorageRenameResponse, 1)
+
+	msg := SessionStorageRenameMsg{
+		WorkingDir:   workingDir,
+		SessionID:    sessionID,
+		Title:        title,
+		ResponseChan: responseChan,
+	}
+
+	if err := storageRef.Send(msg); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-responseChan:
+		return response.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DuplicateSessionViaActor copies a saved session to a new ID
+func DuplicateSessionViaActor(ctx context.Context, storageRef *ActorRef, workingDir, sessionID, title string) (*session.SessionMetadata, error) {
+	responseChan := make(chan SessionStorageDuplicateResponse, 1)
+
+	msg := SessionStorageDuplicateMsg{
+		WorkingDir:   workingDir,
+		SessionID:    sessionID,
+		Title:        title,
+		ResponseChan: responseChan,
+	}
+
+	if err := storageRef.Send(msg); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responseChan:
+		return response.Session, response.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // StartAutoSaveViaActor starts automatic saving for a session
 func StartAutoSaveViaActor(ctx context.Context, storageRef *ActorRef, session *session.Session, name string) error {
 	logger.Debug("StartAutoSaveViaActor: starting autosave for session %s with name %s", session.ID, name)
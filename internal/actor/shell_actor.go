@@ -2,6 +2,7 @@ package actor
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/codefionn/scriptschnell/internal/sandbox"
@@ -12,6 +13,10 @@ type ShellMessage interface {
 	Type() string
 }
 
+// ShellOutputFunc receives output of a running command as it is produced.
+// Calls are serialized; isStderr tells which stream the chunk came from.
+type ShellOutputFunc func(chunk string, isStderr bool)
+
 // ShellExecuteRequest is a message to execute a shell command
 type ShellExecuteRequest struct {
 	Command    []string
@@ -19,6 +24,9 @@ type ShellExecuteRequest struct {
 	Timeout    time.Duration
 	Background bool
 	Stdin      string
+	StdinFile  *os.File          // Optional, passed to the command as stdin instead of Stdin
+	Env        map[string]string // Extra environment variables for the command
+	OnOutput   ShellOutputFunc   // Optional, streams foreground output while the command runs
 	ResponseCh chan ShellExecuteResponse
 }
 
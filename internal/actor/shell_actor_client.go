@@ -3,6 +3,7 @@ package actor
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 )
 
@@ -18,21 +19,54 @@ func NewShellActorClient(ref *ActorRef) *ShellActorClient {
 
 // ExecuteCommand executes a command synchronously using argv (no shell parsing).
 func (c *ShellActorClient) ExecuteCommand(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string) (string, string, int, error) {
-	if len(args) == 0 {
-		return "", "", -1, fmt.Errorf("command args are required")
-	}
+	return c.ExecuteCommandWithEnv(ctx, args, workingDir, timeout, stdin, nil)
+}
 
-	responseCh := make(chan ShellExecuteResponse, 1)
+// ExecuteCommandWithEnv executes a command synchronously using argv with
+// additional environment variables set for this command only.
+func (c *ShellActorClient) ExecuteCommandWithEnv(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string, env map[string]string) (string, string, int, error) {
+	return c.ExecuteCommandStreaming(ctx, args, workingDir, timeout, stdin, env, nil)
+}
 
-	msg := ShellExecuteRequest{
-		Command:    append([]string(nil), args...),
+// ExecuteCommandStreaming executes a command synchronously like
+// ExecuteCommandWithEnv and passes output to onOutput while the command runs.
+// The full stdout and stderr are still returned once it completes.
+func (c *ShellActorClient) ExecuteCommandStreaming(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string, env map[string]string, onOutput ShellOutputFunc) (string, string, int, error) {
+	return c.execute(ctx, ShellExecuteRequest{
+		Command:    args,
 		WorkingDir: workingDir,
 		Timeout:    timeout,
-		Background: false,
 		Stdin:      stdin,
-		ResponseCh: responseCh,
+		Env:        env,
+		OnOutput:   onOutput,
+	})
+}
+
+// ExecuteCommandWithStdinFile executes a command synchronously like
+// ExecuteCommandStreaming with an open file as stdin. The file is handed to
+// the process as is, so its content is never loaded into memory. onOutput
+// may be nil.
+func (c *ShellActorClient) ExecuteCommandWithStdinFile(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdinFile *os.File, env map[string]string, onOutput ShellOutputFunc) (string, string, int, error) {
+	return c.execute(ctx, ShellExecuteRequest{
+		Command:    args,
+		WorkingDir: workingDir,
+		Timeout:    timeout,
+		StdinFile:  stdinFile,
+		Env:        env,
+		OnOutput:   onOutput,
+	})
+}
+
+// execute sends a foreground execution request and waits for its response
+func (c *ShellActorClient) execute(ctx context.Context, msg ShellExecuteRequest) (string, string, int, error) {
+	if len(msg.Command) == 0 {
+		return "", "", -1, fmt.Errorf("command args are required")
 	}
 
+	responseCh := make(chan ShellExecuteResponse, 1)
+	msg.Command = append([]string(nil), msg.Command...)
+	msg.ResponseCh = responseCh
+
 	if err := c.ref.Send(msg); err != nil {
 		return "", "", 0, err
 	}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -147,6 +148,26 @@ func (a *shellActorImpl) Receive(ctx context.Context, msg Message) error {
 }
 
 func (a *shellActorImpl) ExecuteCommand(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string) (string, string, int, error) {
+	return a.executeCommand(ctx, args, workingDir, timeout, stringStdin(stdin), nil, nil)
+}
+
+// ExecuteCommandStreaming executes a command synchronously and passes its
+// output to onOutput while it runs
+func (a *shellActorImpl) ExecuteCommandStreaming(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string, env map[string]string, onOutput ShellOutputFunc) (string, string, int, error) {
+	return a.executeCommand(ctx, args, workingDir, timeout, stringStdin(stdin), env, onOutput)
+}
+
+// stringStdin returns a reader for stdin passed as a string, nil if it is empty
+func stringStdin(stdin string) io.Reader {
+	if stdin == "" {
+		return nil
+	}
+	return strings.NewReader(stdin)
+}
+
+// executeCommand runs a command synchronously with optional extra environment
+// variables, streaming output to onOutput if set
+func (a *shellActorImpl) executeCommand(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin io.Reader, extraEnv map[string]string, onOutput ShellOutputFunc) (string, string, int, error) {
 	if len(args) == 0 {
 		return "", "", -1, fmt.Errorf("no command provided")
 	}
@@ -172,14 +193,23 @@ func (a *shellActorImpl) ExecuteCommand(ctx context.Context, args []string, work
 	cmd := exec.CommandContext(cmdCtx, resolvedArgs[0], resolvedArgs[1:]...)
 	cmd.Dir = workingDir
 	cmd.Env = a.buildCommandEnv()
+	for _, key := range sortedEnvKeys(extraEnv) {
+		cmd.Env = replaceOrAppendEnv(cmd.Env, key, extraEnv[key])
+	}
 
-	if stdin != "" {
-		cmd.Stdin = strings.NewReader(stdin)
+	if stdin != nil {
+		cmd.Stdin = stdin
 	}
 
 	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	if onOutput != nil {
+		stream := &shellOutputStream{onOutput: onOutput}
+		cmd.Stdout = &shellStreamWriter{stream: stream, buf: &stdout}
+		cmd.Stderr = &shellStreamWriter{stream: stream, buf: &stderr, isStderr: true}
+	} else {
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+	}
 
 	err = cmd.Run()
 	exitCode := 0
@@ -323,7 +353,11 @@ func (a *shellActorImpl) handleExecuteRequest(ctx context.Context, req ShellExec
 		err      error
 	)
 
-	stdout, stderr, exitCode, err = a.ExecuteCommand(ctx, req.Command, req.WorkingDir, req.Timeout, req.Stdin)
+	stdin := stringStdin(req.Stdin)
+	if req.StdinFile != nil {
+		stdin = req.StdinFile
+	}
+	stdout, stderr, exitCode, err = a.executeCommand(ctx, req.Command, req.WorkingDir, req.Timeout, stdin, req.Env, req.OnOutput)
 	if err != nil {
 		return ShellExecuteResponse{
 			ExitCode: exitCode,
@@ -415,6 +449,40 @@ func (a *shellActorImpl) buildCommandEnv() []string {
 	return env
 }
 
+// shellOutputStream guards the captured output of the stdout and stderr
+// writers of one command
+type shellOutputStream struct {
+	mu       sync.Mutex
+	onOutput ShellOutputFunc
+}
+
+// shellStreamWriter captures one output stream of a command and forwards each
+// write to the command's output callback. The callback runs outside the lock
+// so a slow consumer cannot stall the other stream.
+type shellStreamWriter struct {
+	stream   *shellOutputStream
+	buf      *strings.Builder
+	isStderr bool
+}
+
+func (w *shellStreamWriter) Write(p []byte) (int, error) {
+	w.stream.mu.Lock()
+	w.buf.Write(p)
+	w.stream.mu.Unlock()
+	w.stream.onOutput(string(p), w.isStderr)
+	return len(p), nil
+}
+
+// sortedEnvKeys returns the keys of env in a deterministic order
+func sortedEnvKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // replaceOrAppendEnv replaces an existing environment variable or appends it.
 func replaceOrAppendEnv(env []string, key, value string) []string {
 	prefix := key + "="
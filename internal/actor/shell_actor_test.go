@@ -0,0 +1,124 @@
+package actor
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShellActorClientExecuteCommandWithEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	ctx := context.Background()
+	ref := NewActorRef("shell-env", NewShellActor("shell-env", nil), 10)
+	if err := ref.Start(ctx); err != nil {
+		t.Fatalf("failed to start shell actor: %v", err)
+	}
+	t.Cleanup(func() {
+		stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		_ = ref.Stop(stopCtx)
+	})
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+
+	client := NewShellActorClient(ref)
+	stdout, stderr, exitCode, err := client.ExecuteCommandWithEnv(ctx,
+		[]string{"sh", "-c", `pwd; echo "$SHELL_ACTOR_TEST_VAR"`},
+		dir, 10*time.Second, "",
+		map[string]string{"SHELL_ACTOR_TEST_VAR": "from-env"})
+	if err != nil || exitCode != 0 {
+		t.Fatalf("command failed (exit %d): %v, stderr: %s", exitCode, err, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || lines[0] != dir || lines[1] != "from-env" {
+		t.Fatalf("expected cwd %q and env value, got %q", dir, stdout)
+	}
+}
+
+func TestShellActorClientExecuteCommandStreaming(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	ctx := context.Background()
+	ref := NewActorRef("shell-stream", NewShellActor("shell-stream", nil), 10)
+	if err := ref.Start(ctx); err != nil {
+		t.Fatalf("failed to start shell actor: %v", err)
+	}
+	t.Cleanup(func() {
+		stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		_ = ref.Stop(stopCtx)
+	})
+
+	var (
+		mu     sync.Mutex
+		chunks []string
+	)
+	firstChunk := make(chan struct{})
+	onOutput := func(chunk string, isStderr bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if isStderr {
+			chunks = append(chunks, "stderr:"+chunk)
+		} else {
+			chunks = append(chunks, chunk)
+		}
+		if len(chunks) == 1 {
+			close(firstChunk)
+		}
+	}
+
+	type result struct {
+		stdout, stderr string
+		exitCode       int
+		err            error
+	}
+	done := make(chan result, 1)
+	client := NewShellActorClient(ref)
+	go func() {
+		stdout, stderr, exitCode, err := client.ExecuteCommandStreaming(ctx,
+			[]string{"sh", "-c", "echo one; sleep 1; echo two; echo oops >&2"},
+			"", 10*time.Second, "", nil, onOutput)
+		done <- result{stdout, stderr, exitCode, err}
+	}()
+
+	select {
+	case <-firstChunk:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected output to be streamed while the command runs")
+	}
+	select {
+	case <-done:
+		t.Fatal("expected the first chunk before the command completed")
+	default:
+	}
+
+	res := <-done
+	if res.err != nil || res.exitCode != 0 {
+		t.Fatalf("command failed (exit %d): %v", res.exitCode, res.err)
+	}
+	if res.stdout != "one\ntwo\n" || res.stderr != "oops\n" {
+		t.Fatalf("expected full output in the final result, got stdout %q stderr %q", res.stdout, res.stderr)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chunks) < 3 || chunks[0] != "one\n" {
+		t.Fatalf("expected one callback per chunk, got %q", chunks)
+	}
+	if !strings.Contains(strings.Join(chunks, ""), "stderr:oops\n") {
+		t.Errorf("expected stderr chunk to be streamed, got %q", chunks)
+	}
+}
@@ -62,6 +62,12 @@ type UserInteractionResponse struct {
 	RequestID string
 	// Approved is used for authorization requests (true = approved, false = denied)
 	Approved bool
+	// SessionOnly limits an approval to the current session; it must not be
+	// saved to the configuration
+	SessionOnly bool
+	// EditedParams replace the tool's parameters of an approved
+	// authorization; nil runs the tool as requested
+	EditedParams map[string]interface{}
 	// Answer is used for single-answer questions
 	Answer string
 	// Answers is used for multiple questions (question -> answer mapping)
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,10 +32,22 @@ type Options struct {
 	Provider            string
 	JSONOutput          bool
 	JSONExtended        bool
-	JSONFull            bool   // Include full tool call outputs in JSON
-	SocketClientMode    bool   // Connect to running Unix socket server
-	SocketClientPath    string // Path to Unix socket for CLI mode
-	NoSocket            bool   // Disable auto-detection of socket server
+	JSONFull            bool          // Include full tool call outputs in JSON
+	JSONTrace           bool          // Output JSON like JSONOutput plus a trace of tool calls
+	SocketClientMode    bool          // Connect to running Unix socket server
+	SocketClientPath    string        // Path to Unix socket for CLI mode
+	NoSocket            bool          // Disable auto-detection of socket server
+	DryRun              bool          // Simulate tool calls that would change files or run programs
+	ReadOnly            bool          // Only register read/search tools and deny every write
+	PolicyFile          string        // Authorization policy file deciding tool calls before prompting
+	Temperature         *float64      // --temperature: per-prompt temperature override (SCRIPTSCHNELL_TEMPERATURE)
+	Seed                *int          // --seed: per-prompt sampling seed, where supported (SCRIPTSCHNELL_SEED)
+	Color               tui.ColorMode // --color: auto (honors NO_COLOR), always or never
+}
+
+// jsonMode reports whether any JSON output format was requested
+func (o *Options) jsonMode() bool {
+	return o != nil && (o.JSONOutput || o.JSONExtended || o.JSONFull || o.JSONTrace)
 }
 
 // CLI handles command-line interface using the orchestrator
@@ -42,6 +56,14 @@ type CLI struct {
 	providerMgr  *provider.Manager
 	orchestrator *tui.Orchestrator
 	options      *Options
+
+	// out and errOut default to os.Stdout and os.Stderr
+	out    io.Writer
+	errOut io.Writer
+
+	trace *toolTracer // Set for --json-trace runs
+
+	result *tui.PromptResult // Result of the last processed prompt
 }
 
 func New(cfg *config.Config, providerMgr *provider.Manager, opts *Options) (*CLI, error) {
@@ -81,6 +103,16 @@ func New(cfg *config.Config, providerMgr *provider.Manager, opts *Options) (*CLI
 		}
 	}
 
+	if opts != nil && opts.DryRun {
+		cfg.DryRun = true
+	}
+	if opts != nil && opts.ReadOnly {
+		cfg.ReadOnly = true
+	}
+	if opts != nil && opts.PolicyFile != "" {
+		cfg.AuthorizationPolicy = opts.PolicyFile
+	}
+
 	// Create orchestrator which handles all the tool execution logic
 	// CLI mode is always unattended, so pass cliMode=true
 	requireSandboxAuth := false
@@ -128,6 +160,56 @@ func (c *CLI) Close() error {
 	return nil
 }
 
+// promptOverrides collects the sampling overrides from the CLI options, falling
+// back to the SCRIPTSCHNELL_TEMPERATURE and SCRIPTSCHNELL_SEED environment variables.
+func (c *CLI) promptOverrides() (tui.PromptOverrides, bool) {
+	var overrides tui.PromptOverrides
+	if c.options != nil {
+		overrides.Temperature = c.options.Temperature
+		overrides.Seed = c.options.Seed
+	}
+
+	if overrides.Temperature == nil {
+		if envTemp := strings.TrimSpace(os.Getenv("SCRIPTSCHNELL_TEMPERATURE")); envTemp != "" {
+			if temp, err := strconv.ParseFloat(envTemp, 64); err == nil {
+				overrides.Temperature = &temp
+			} else {
+				fmt.Fprintf(os.Stderr, "Ignoring invalid SCRIPTSCHNELL_TEMPERATURE %q: %v\n", envTemp, err)
+			}
+		}
+	}
+	if overrides.Seed == nil {
+		if envSeed := strings.TrimSpace(os.Getenv("SCRIPTSCHNELL_SEED")); envSeed != "" {
+			if seed, err := strconv.Atoi(envSeed); err == nil {
+				overrides.Seed = &seed
+			} else {
+				fmt.Fprintf(os.Stderr, "Ignoring invalid SCRIPTSCHNELL_SEED %q: %v\n", envSeed, err)
+			}
+		}
+	}
+
+	return overrides, overrides.Temperature != nil || overrides.Seed != nil
+}
+
+// jsonMode reports whether any JSON output format was requested
+func (c *CLI) jsonMode() bool {
+	return c.options.jsonMode()
+}
+
+func (c *CLI) stdout() io.Writer {
+	if c.out != nil {
+		return c.out
+	}
+	return os.Stdout
+}
+
+func (c *CLI) stderr() io.Writer {
+	if c.errOut != nil {
+		return c.errOut
+	}
+	return os.Stderr
+}
+
 // Run executes a single prompt using the orchestrator
 func (c *CLI) Run(ctx context.Context, prompt string) error {
 	// Convert HTML to markdown if detected
@@ -136,28 +218,27 @@ func (c *CLI) Run(ctx context.Context, prompt string) error {
 		fmt.Fprintln(os.Stderr, "[Detected and converted HTML to markdown]")
 	}
 
-	// Progress callback: print streaming to stdout and status to stderr
-	progressCallback := func(update progress.Update) error {
-		if c.options != nil && (c.options.JSONOutput || c.options.JSONExtended || c.options.JSONFull) {
-			return nil
+	// In text mode stream the answer to stdout and tool notices to stderr as
+	// they happen; JSON modes stay silent until the single final document.
+	var (
+		progressCallback   progress.Callback
+		toolCallCallback   tui.ToolCallCallback
+		toolResultCallback tui.ToolResultCallback
+		printer            *streamPrinter
+	)
+	if c.jsonMode() {
+		progressCallback = func(progress.Update) error { return nil }
+		if c.options.JSONTrace {
+			c.trace = newToolTracer()
+			toolCallCallback = c.trace.toolCall
+			toolResultCallback = c.trace.toolResult
 		}
-		normalized := progress.Normalize(update)
-		if normalized.ShouldStatus() {
-			if normalized.Message == "" {
-				return nil
-			}
-			msg := normalized.Message
-			if !strings.HasSuffix(msg, "\n") {
-				msg += "\n"
-			}
-			fmt.Fprint(os.Stderr, msg)
-			return nil
-		}
-		if normalized.Message == "" || !normalized.ShouldStream() {
-			return nil
-		}
-		fmt.Print(normalized.Message)
-		return nil
+	} else {
+		printer = newStreamPrinter(c.stdout(), c.stderr())
+		printer.plain = !tui.ColorEnabled()
+		progressCallback = printer.progress
+		toolCallCallback = printer.toolCall
+		toolResultCallback = printer.toolResult
 	}
 
 	// Context callback: we can ignore this in CLI mode
@@ -166,7 +247,7 @@ func (c *CLI) Run(ctx context.Context, prompt string) error {
 	}
 
 	// Authorization callback: auto-approve if dangerous-allow-all is set
-	authCallback := func(toolName string, params map[string]interface{}, reason string) (bool, error) {
+	authCallback := tui.ApprovalOnly(func(toolName string, params map[string]interface{}, reason string) (bool, error) {
 		if c.options != nil && c.options.DangerouslyAllowAll {
 			// Auto-approve everything
 			fmt.Fprintf(os.Stderr, "[Auto-approved: %s]\n", toolName)
@@ -210,7 +291,7 @@ func (c *CLI) Run(ctx context.Context, prompt string) error {
 		// In CLI mode without auto-approval, deny by default
 		// (Interactive approval would require more complex TTY handling)
 		return false, fmt.Errorf("authorization required but not granted via CLI flags")
-	}
+	})
 
 	// Usage callback: session now accumulates usage internally, no-op here
 	usageCallback := func(usage map[string]interface{}) error {
@@ -218,21 +299,27 @@ func (c *CLI) Run(ctx context.Context, prompt string) error {
 		return nil
 	}
 
+	// Pin temperature/seed for this prompt if requested (used by evals for reproducibility)
+	if overrides, ok := c.promptOverrides(); ok {
+		ctx = tui.ContextWithPromptOverrides(ctx, overrides)
+	}
+
 	// Use the orchestrator to process the prompt with automatic verification retry
-	err := c.orchestrator.ProcessPromptWithVerification(ctx, prompt, progressCallback, contextCallback, authCallback, nil, nil, usageCallback)
+	result, err := c.orchestrator.ProcessPromptWithVerificationResult(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, usageCallback)
+	c.result = result
 	if err != nil {
 		return fmt.Errorf("failed to process prompt: %w", err)
 	}
 
-	if c.options == nil || (!c.options.JSONOutput && !c.options.JSONExtended && !c.options.JSONFull) {
-		fmt.Println() // Final newline
+	if printer != nil {
+		printer.finish()
 	}
 
 	// Print accumulated usage statistics from session
 	session := c.orchestrator.GetSession()
 	if session != nil {
 		usageStats := session.GetUsageStats()
-		if len(usageStats) > 0 && (c.options == nil || (!c.options.JSONOutput && !c.options.JSONExtended && !c.options.JSONFull)) {
+		if len(usageStats) > 0 && !c.jsonMode() {
 			fmt.Fprintf(os.Stderr, "\n--- Usage Statistics ---\n")
 
 			// Get totals from session
@@ -262,7 +349,7 @@ func (c *CLI) Run(ctx context.Context, prompt string) error {
 		return c.outputJSONFull()
 	}
 
-	if c.options != nil && c.options.JSONOutput {
+	if c.options != nil && (c.options.JSONOutput || c.options.JSONTrace) {
 		return c.outputJSON()
 	}
 
@@ -285,6 +372,11 @@ func (c *CLI) outputJSON() error {
 	if usage := c.buildUsageSummary(); len(usage) > 0 {
 		result["usage"] = usage
 	}
+	c.addStopReason(result)
+
+	if c.trace != nil {
+		result["tool_trace"] = c.trace.Entries()
+	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
@@ -353,6 +445,7 @@ func (c *CLI) outputJSONExtended() error {
 	if usage := c.buildUsageSummary(); len(usage) > 0 {
 		usageObj["usage"] = usage
 	}
+	c.addStopReason(usageObj)
 
 	data, err := json.Marshal(usageObj)
 	if err != nil {
@@ -422,6 +515,12 @@ func (c *CLI) outputJSONFull() error {
 	if usage := c.buildUsageSummary(); len(usage) > 0 {
 		result["usage"] = usage
 	}
+	c.addStopReason(result)
+
+	// --json-trace adds the tool trace to the full output too
+	if c.trace != nil {
+		result["tool_trace"] = c.trace.Entries()
+	}
 
 	// Marshal with indentation for readability
 	data, err := json.MarshalIndent(result, "", "  ")
@@ -459,6 +558,16 @@ func (c *CLI) lastAssistantMessage() string {
 	return ""
 }
 
+// addStopReason records why the prompt stopped (e.g. "completed" or
+// "max_iterations") and how many loop iterations it took in JSON output.
+func (c *CLI) addStopReason(output map[string]interface{}) {
+	if c.result == nil {
+		return
+	}
+	output["stop_reason"] = string(c.result.Reason)
+	output["iterations"] = c.result.Iterations
+}
+
 // buildUsageSummary prepares usage information suitable for JSON output.
 func (c *CLI) buildUsageSummary() map[string]interface{} {
 	summary := make(map[string]interface{})
@@ -26,8 +26,10 @@ func DetectSocketServer(cfg *config.Config) bool {
 // ShouldUseSocketMode determines if CLI should use socket mode based on config and detection.
 // Deprecated: Use socketutil.ShouldUseSocketMode instead.
 func ShouldUseSocketMode(cfg *config.Config, opts *Options) bool {
-	// Check if socket mode is explicitly disabled via flag
-	noSocket := opts != nil && opts.NoSocket
+	// Check if socket mode is explicitly disabled via flag. Dry runs,
+	// read-only runs and runs with a policy file always run locally since
+	// the server's config decides what tools may do.
+	noSocket := opts != nil && (opts.NoSocket || opts.DryRun || opts.ReadOnly || opts.PolicyFile != "")
 	return socketutil.ShouldUseSocketMode(cfg, noSocket)
 }
 
@@ -39,6 +41,7 @@ type SocketCLI struct {
 	sessionID  string
 	socketPath string
 	completion atomic.Bool
+	trace      *toolTracer // Set for --json-trace runs
 }
 
 // NewSocket creates a new socket-based CLI runner
@@ -101,7 +104,7 @@ func (c *SocketCLI) Close() error {
 func (c *SocketCLI) Run(ctx context.Context, prompt string) error {
 	// Set up progress callback for streaming output
 	progressCallback := func(update progress.Update) error {
-		if c.options != nil && (c.options.JSONOutput || c.options.JSONExtended || c.options.JSONFull) {
+		if c.options.jsonMode() {
 			return nil
 		}
 		normalized := progress.Normalize(update)
@@ -146,6 +149,28 @@ func (c *SocketCLI) Run(ctx context.Context, prompt string) error {
 		}
 	})
 
+	// Record tool calls for --json-trace
+	if c.options.jsonMode() && c.options.JSONTrace {
+		c.trace = newToolTracer()
+		c.client.SetToolCallCallback(func(msg socketclient.ToolCall) {
+			_ = c.trace.toolCall(msg.ToolName, msg.ToolID, msg.Parameters)
+		})
+		c.client.SetToolResultCallback(func(msg socketclient.ToolResult) {
+			var result, errorMsg string
+			if msg.Result != nil {
+				result = *msg.Result
+			}
+			if msg.Error != nil {
+				errorMsg = *msg.Error
+			}
+			_ = c.trace.toolResult("", msg.ToolID, result, errorMsg)
+		})
+	}
+
+	c.client.SetServerClosingCallback(func(msg socketclient.ServerClosing) {
+		fmt.Fprintf(os.Stderr, "[Server is shutting down; waiting up to %ds for running generations]\n", msg.EstimatedWaitSeconds)
+	})
+
 	// Register authorization callback
 	c.client.SetAuthorizationCallback(func(req socketclient.AuthorizationRequest) (bool, error) {
 		if c.options != nil && c.options.DangerouslyAllowAll {
@@ -200,7 +225,7 @@ func (c *SocketCLI) Run(ctx context.Context, prompt string) error {
 	}
 
 	// Print final newline if not JSON mode
-	if c.options == nil || (!c.options.JSONOutput && !c.options.JSONExtended && !c.options.JSONFull) {
+	if !c.options.jsonMode() {
 		fmt.Println()
 	}
 
@@ -214,7 +239,7 @@ func (c *SocketCLI) Run(ctx context.Context, prompt string) error {
 		if c.options.JSONFull {
 			return c.outputJSONFull(ctx)
 		}
-		if c.options.JSONOutput {
+		if c.options.JSONOutput || c.options.JSONTrace {
 			return c.outputJSON(ctx)
 		}
 		if c.options.JSONExtended {
@@ -303,7 +328,7 @@ func (c *SocketCLI) printUsageStats(ctx context.Context) error {
 		return nil
 	}
 
-	if c.options != nil && (c.options.JSONOutput || c.options.JSONExtended || c.options.JSONFull) {
+	if c.options.jsonMode() {
 		return nil
 	}
 
@@ -337,6 +362,10 @@ func (c *SocketCLI) outputJSON(ctx context.Context) error {
 		result["usage"] = usage
 	}
 
+	if c.trace != nil {
+		result["tool_trace"] = c.trace.Entries()
+	}
+
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON output: %w", err)
@@ -411,15 +440,16 @@ func (c *SocketCLI) outputJSONFull(ctx context.Context) error {
 		"messages": sessionInfo.MessageHistory,
 	}
 
-	// Add tool calls if available
-	// Note: Tool call details would need to be tracked separately
-	// For now, we'll include what's available in the session info
-
 	// Add usage statistics
 	if usage := c.buildUsageSummary(ctx); len(usage) > 0 {
 		output["usage"] = usage
 	}
 
+	// --json-trace adds the tool trace to the full output too
+	if c.trace != nil {
+		output["tool_trace"] = c.trace.Entries()
+	}
+
 	data, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON output: %w", err)
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/tui"
+)
+
+// streamPrinter writes a run incrementally in text mode: assistant text goes
+// to out as it arrives, status lines and tool notices go to errOut so piping
+// stdout only captures the answer.
+type streamPrinter struct {
+	out    io.Writer
+	errOut io.Writer
+	plain  bool // Strip ANSI escape sequences (NO_COLOR, --color=never, piped output)
+}
+
+func newStreamPrinter(out, errOut io.Writer) *streamPrinter {
+	return &streamPrinter{out: out, errOut: errOut}
+}
+
+// progress is the orchestrator progress callback
+func (p *streamPrinter) progress(update progress.Update) error {
+	normalized := progress.Normalize(update)
+	if normalized.ShouldStatus() {
+		if normalized.Message == "" {
+			return nil
+		}
+		msg := p.text(normalized.Message)
+		if !strings.HasSuffix(msg, "\n") {
+			msg += "\n"
+		}
+		fmt.Fprint(p.errOut, msg)
+		return nil
+	}
+	if normalized.Message == "" || !normalized.ShouldStream() {
+		return nil
+	}
+	if _, err := io.WriteString(p.out, p.text(normalized.Message)); err != nil {
+		return err
+	}
+	flush(p.out)
+	return nil
+}
+
+// toolCall announces a tool call on errOut
+func (p *streamPrinter) toolCall(toolName, toolID string, parameters map[string]interface{}) error {
+	if summary := toolCallSummary(parameters); summary != "" {
+		fmt.Fprintf(p.errOut, "[tool] %s %s\n", toolName, summary)
+	} else {
+		fmt.Fprintf(p.errOut, "[tool] %s\n", toolName)
+	}
+	return nil
+}
+
+// toolResult reports failed tool calls on errOut; successful results are
+// left to the assistant's answer.
+func (p *streamPrinter) toolResult(toolName, toolID, result, errorMsg string) error {
+	if errorMsg != "" {
+		fmt.Fprintf(p.errOut, "[tool] %s failed: %s\n", toolName, p.text(firstLine(errorMsg)))
+	}
+	return nil
+}
+
+// text strips styling from s in plain mode
+func (p *streamPrinter) text(s string) string {
+	if p.plain {
+		return tui.StripANSI(s)
+	}
+	return s
+}
+
+// finish ends the streamed answer with a newline
+func (p *streamPrinter) finish() {
+	fmt.Fprintln(p.out)
+	flush(p.out)
+}
+
+// toolCallSummary picks the parameter that best identifies a tool call
+func toolCallSummary(parameters map[string]interface{}) string {
+	for _, key := range []string{"path", "file_path", "command", "pattern", "query", "url"} {
+		if value, ok := parameters[key].(string); ok && strings.TrimSpace(value) != "" {
+			return truncateNotice(firstLine(value))
+		}
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx] + " ..."
+	}
+	return s
+}
+
+func truncateNotice(s string) string {
+	const maxNoticeRunes = 80
+	runes := []rune(s)
+	if len(runes) <= maxNoticeRunes {
+		return s
+	}
+	return string(runes[:maxNoticeRunes]) + "..."
+}
+
+// flush pushes buffered output through if w buffers it (os.Stdout does not)
+func flush(w io.Writer) {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+}
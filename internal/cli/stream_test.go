@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/progress"
+)
+
+// recordingWriter keeps every write separately to observe incremental output
+type recordingWriter struct {
+	writes []string
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestStreamPrinterWritesChunksIncrementally(t *testing.T) {
+	out := &recordingWriter{}
+	var errOut bytes.Buffer
+	printer := newStreamPrinter(out, &errOut)
+
+	chunks := []string{"Hello", ", wor", "ld!"}
+	for i, chunk := range chunks {
+		if err := printer.progress(progress.Update{Message: chunk, Mode: progress.ReportNoStatus}); err != nil {
+			t.Fatalf("progress failed: %v", err)
+		}
+		// Each chunk must be on stdout before the next one arrives
+		if got := len(out.writes); got != i+1 {
+			t.Fatalf("after chunk %d expected %d writes, got %d", i, i+1, got)
+		}
+		if out.writes[i] != chunk {
+			t.Fatalf("write %d = %q, want %q", i, out.writes[i], chunk)
+		}
+	}
+
+	if err := printer.progress(progress.Update{Message: "Thinking...", Mode: progress.ReportJustStatus}); err != nil {
+		t.Fatalf("status progress failed: %v", err)
+	}
+	if err := printer.toolCall("read_file", "call-1", map[string]interface{}{"path": "main.go"}); err != nil {
+		t.Fatalf("tool call notice failed: %v", err)
+	}
+	if err := printer.toolResult("shell", "call-2", "", "exit status 1\nmore output"); err != nil {
+		t.Fatalf("tool result notice failed: %v", err)
+	}
+	printer.finish()
+
+	if got := strings.Join(out.writes, ""); got != "Hello, world!\n" {
+		t.Fatalf("stdout = %q, want only the streamed answer", got)
+	}
+
+	stderr := errOut.String()
+	for _, want := range []string{"Thinking...\n", "[tool] read_file main.go\n", "[tool] shell failed: exit status 1 ...\n"} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("stderr %q missing %q", stderr, want)
+		}
+	}
+}
+
+func TestToolCallSummaryTruncates(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	got := toolCallSummary(map[string]interface{}{"command": long})
+	if len([]rune(got)) != 83 || !strings.HasSuffix(got, "...") {
+		t.Fatalf("expected truncated summary, got %q", got)
+	}
+	if got := toolCallSummary(map[string]interface{}{"other": "value"}); got != "" {
+		t.Fatalf("expected empty summary for unknown parameters, got %q", got)
+	}
+}
+
+func TestStreamPrinterPlainStripsANSI(t *testing.T) {
+	var out, errOut bytes.Buffer
+	printer := newStreamPrinter(&out, &errOut)
+	printer.plain = true
+
+	if err := printer.progress(progress.Update{Message: "\x1b[1mbold\x1b[0m answer", Mode: progress.ReportNoStatus}); err != nil {
+		t.Fatalf("progress failed: %v", err)
+	}
+	if err := printer.toolResult("shell", "call_1", "", "\x1b[31mexit status 1\x1b[0m"); err != nil {
+		t.Fatalf("toolResult failed: %v", err)
+	}
+
+	if out.String() != "bold answer" {
+		t.Errorf("expected plain stdout, got %q", out.String())
+	}
+	if strings.Contains(errOut.String(), "\x1b[") {
+		t.Errorf("expected plain stderr, got %q", errOut.String())
+	}
+}
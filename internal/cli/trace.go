@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTraceResultBytes caps each tool result in the JSON trace; CI logs only
+// need enough to audit what happened.
+const maxTraceResultBytes = 2000
+
+// toolTraceEntry is one tool invocation in the --json-trace output
+type toolTraceEntry struct {
+	Index           int                    `json:"index"`
+	ToolID          string                 `json:"tool_id,omitempty"`
+	Name            string                 `json:"name"`
+	Parameters      map[string]interface{} `json:"parameters,omitempty"`
+	Result          string                 `json:"result,omitempty"`
+	ResultTruncated bool                   `json:"result_truncated,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+	StartedAt       time.Time              `json:"started_at"`
+	DurationMillis  int64                  `json:"duration_ms"`
+	Completed       bool                   `json:"completed"`
+}
+
+// toolTracer records tool calls and their results in call order. Callbacks
+// may arrive from several goroutines when tools run in parallel.
+type toolTracer struct {
+	mu      sync.Mutex
+	entries []*toolTraceEntry
+	byID    map[string]*toolTraceEntry
+	now     func() time.Time
+}
+
+func newToolTracer() *toolTracer {
+	return &toolTracer{
+		byID: make(map[string]*toolTraceEntry),
+		now:  time.Now,
+	}
+}
+
+// toolCall records the start of a tool invocation
+func (t *toolTracer) toolCall(toolName, toolID string, parameters map[string]interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := &toolTraceEntry{
+		Index:      len(t.entries),
+		ToolID:     toolID,
+		Name:       toolName,
+		Parameters: parameters,
+		StartedAt:  t.now(),
+	}
+	t.entries = append(t.entries, entry)
+	if toolID != "" {
+		t.byID[toolID] = entry
+	}
+	return nil
+}
+
+// toolResult completes the matching invocation. Results are matched by tool
+// ID, falling back to the oldest open call of the same tool.
+func (t *toolTracer) toolResult(toolName, toolID, result, errorMsg string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	entry := t.openEntryLocked(toolName, toolID)
+	if entry == nil {
+		// Result without a recorded call; keep it so the trace stays complete
+		entry = &toolTraceEntry{Index: len(t.entries), ToolID: toolID, Name: toolName, StartedAt: now}
+		t.entries = append(t.entries, entry)
+	}
+
+	entry.Result, entry.ResultTruncated = truncateTraceResult(result)
+	entry.Error = errorMsg
+	entry.DurationMillis = now.Sub(entry.StartedAt).Milliseconds()
+	entry.Completed = true
+	return nil
+}
+
+func (t *toolTracer) openEntryLocked(toolName, toolID string) *toolTraceEntry {
+	if toolID != "" {
+		if entry, ok := t.byID[toolID]; ok && !entry.Completed {
+			return entry
+		}
+	}
+	if toolName == "" {
+		return nil
+	}
+	for _, entry := range t.entries {
+		if !entry.Completed && entry.Name == toolName {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Entries returns a copy of the trace in call order
+func (t *toolTracer) Entries() []toolTraceEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries := make([]toolTraceEntry, len(t.entries))
+	for i, entry := range t.entries {
+		entries[i] = *entry
+	}
+	return entries
+}
+
+func truncateTraceResult(result string) (string, bool) {
+	if len(result) <= maxTraceResultBytes {
+		return result, false
+	}
+	cut := maxTraceResultBytes
+	for cut > 0 && !utf8.RuneStart(result[cut]) {
+		cut--
+	}
+	return result[:cut], true
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolTracerRecordsRunSequence(t *testing.T) {
+	tracer := newToolTracer()
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracer.now = func() time.Time {
+		clock = clock.Add(10 * time.Millisecond)
+		return clock
+	}
+
+	// Mocked run: a read, two parallel calls completing out of order, and a
+	// result delivered without a tool ID (matched by name)
+	steps := []func() error{
+		func() error {
+			return tracer.toolCall("read_file", "call-1", map[string]interface{}{"path": "main.go"})
+		},
+		func() error { return tracer.toolResult("read_file", "call-1", "package main", "") },
+		func() error {
+			return tracer.toolCall("shell", "call-2", map[string]interface{}{"command": "go test ./..."})
+		},
+		func() error {
+			return tracer.toolCall("search_files", "call-3", map[string]interface{}{"pattern": "TODO"})
+		},
+		func() error { return tracer.toolResult("search_files", "call-3", "", "") },
+		func() error { return tracer.toolResult("shell", "call-2", "", "exit status 1") },
+		func() error { return tracer.toolCall("todo", "", nil) },
+		func() error { return tracer.toolResult("todo", "", strings.Repeat("x", maxTraceResultBytes+10), "") },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d failed: %v", i, err)
+		}
+	}
+
+	entries := tracer.Entries()
+	wantNames := []string{"read_file", "shell", "search_files", "todo"}
+	if len(entries) != len(wantNames) {
+		t.Fatalf("expected %d trace entries, got %d: %+v", len(wantNames), len(entries), entries)
+	}
+	for i, name := range wantNames {
+		if entries[i].Name != name || entries[i].Index != i || !entries[i].Completed {
+			t.Fatalf("entry %d = %+v, want completed %s", i, entries[i], name)
+		}
+	}
+
+	if entries[0].Result != "package main" || entries[0].Parameters["path"] != "main.go" {
+		t.Fatalf("unexpected read_file entry: %+v", entries[0])
+	}
+	if entries[1].Error != "exit status 1" {
+		t.Fatalf("expected shell error to be recorded, got %+v", entries[1])
+	}
+	// shell started at step 3 and finished at step 6: three 10ms ticks later
+	if entries[1].DurationMillis != 30 {
+		t.Fatalf("expected shell duration 30ms, got %d", entries[1].DurationMillis)
+	}
+	if !entries[3].ResultTruncated || len(entries[3].Result) != maxTraceResultBytes {
+		t.Fatalf("expected truncated todo result, got %d bytes (truncated=%v)", len(entries[3].Result), entries[3].ResultTruncated)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"tool_trace": entries})
+	if err != nil {
+		t.Fatalf("failed to marshal trace: %v", err)
+	}
+	var decoded struct {
+		ToolTrace []struct {
+			Name       string                 `json:"name"`
+			ToolID     string                 `json:"tool_id"`
+			Parameters map[string]interface{} `json:"parameters"`
+			DurationMS int64                  `json:"duration_ms"`
+		} `json:"tool_trace"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("trace is not valid JSON: %v", err)
+	}
+	if decoded.ToolTrace[2].ToolID != "call-3" || decoded.ToolTrace[2].Parameters["pattern"] != "TODO" {
+		t.Fatalf("unexpected decoded entry: %+v", decoded.ToolTrace[2])
+	}
+}
+
+func TestToolTracerKeepsResultWithoutCall(t *testing.T) {
+	tracer := newToolTracer()
+	if err := tracer.toolResult("", "orphan", "done", ""); err != nil {
+		t.Fatalf("toolResult failed: %v", err)
+	}
+	entries := tracer.Entries()
+	if len(entries) != 1 || entries[0].ToolID != "orphan" || !entries[0].Completed {
+		t.Fatalf("expected orphan result to be kept, got %+v", entries)
+	}
+}
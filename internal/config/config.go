@@ -5,14 +5,20 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/prompttemplate"
 	"github.com/codefionn/scriptschnell/internal/secrets"
 )
 
@@ -44,6 +50,52 @@ type PerplexityConfig struct {
 // MCPConfig stores user-defined MCP servers
 type MCPConfig struct {
 	Servers map[string]*MCPServerConfig `json:"servers"`
+
+	// ToolTimeoutSeconds bounds each MCP tool call, separately from local tools
+	ToolTimeoutSeconds int `json:"tool_timeout_seconds,omitempty"`
+	// FailureThreshold is the number of consecutive failed calls after which
+	// a server's tools are disabled for CooldownSeconds
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+	CooldownSeconds  int `json:"cooldown_seconds,omitempty"`
+}
+
+// Defaults for the MCP tool call limits
+const (
+	DefaultMCPToolTimeoutSeconds = 120
+	DefaultMCPFailureThreshold   = 3
+	DefaultMCPCooldownSeconds    = 300
+)
+
+// GetToolTimeout returns the timeout of a single MCP tool call; 0 means no
+// timeout
+func (m *MCPConfig) GetToolTimeout() time.Duration {
+	switch {
+	case m.ToolTimeoutSeconds > 0:
+		return time.Duration(m.ToolTimeoutSeconds) * time.Second
+	case m.ToolTimeoutSeconds < 0:
+		return 0
+	}
+	return DefaultMCPToolTimeoutSeconds * time.Second
+}
+
+// GetFailureThreshold returns the number of consecutive failures that
+// disable a server's tools; 0 means servers are never disabled
+func (m *MCPConfig) GetFailureThreshold() int {
+	switch {
+	case m.FailureThreshold > 0:
+		return m.FailureThreshold
+	case m.FailureThreshold < 0:
+		return 0
+	}
+	return DefaultMCPFailureThreshold
+}
+
+// GetCooldown returns how long a failing server's tools stay disabled
+func (m *MCPConfig) GetCooldown() time.Duration {
+	if m.CooldownSeconds > 0 {
+		return time.Duration(m.CooldownSeconds) * time.Second
+	}
+	return DefaultMCPCooldownSeconds * time.Second
 }
 
 // MCPServerConfig describes a custom MCP server
@@ -94,6 +146,288 @@ type AutoSaveConfig struct {
 	MaxConcurrentSaves  int  `json:"max_concurrent_saves"`
 }
 
+// RedactionConfig controls masking of secrets in logs and tool results
+type RedactionConfig struct {
+	Replacement string `json:"replacement,omitempty"` // Text secrets are replaced with (default "[REDACTED]")
+	ToolResults bool   `json:"tool_results"`          // Also mask secrets in tool results before the model sees them
+	HighEntropy bool   `json:"high_entropy"`          // Mask long high-entropy tokens (may also hide hashes or encoded data)
+}
+
+// PromptTemplatesConfig overrides built-in prompts with Go text/template
+// templates; empty fields keep the defaults. See package prompttemplate for
+// the data available to each template.
+type PromptTemplatesConfig struct {
+	CompactionSummary string `json:"compaction_summary,omitempty"` // Instruction for summarizing compacted context
+	ErrorJudge        string `json:"error_judge,omitempty"`        // Prompt deciding whether to retry failed LLM calls; must keep the DECISION/SLEEP_SECONDS/TRIGGER_COMPACTION/REASON format
+}
+
+// ToolResultLimitsConfig caps how much of a tool result is fed back to the
+// model. Display in the UI is not affected.
+type ToolResultLimitsConfig struct {
+	DefaultBytes int            `json:"default_bytes,omitempty"` // Cap for tools without their own limit (0 = default 64 KiB, -1 = unlimited)
+	Tools        map[string]int `json:"tools,omitempty"`         // Tool name -> cap in bytes (0 = built-in default, -1 = unlimited)
+}
+
+// DefaultToolResultBytes is the result cap for tools without a built-in limit
+const DefaultToolResultBytes = 64 * 1024
+
+// defaultToolResultLimits are the built-in caps for tools that tend to
+// produce large results
+var defaultToolResultLimits = map[string]int{
+	"shell":               32 * 1024,
+	"command":             32 * 1024,
+	"search_files":        32 * 1024,
+	"search_file_content": 32 * 1024,
+	"read_file":           100 * 1024,
+}
+
+// GetLimit returns the maximum number of result bytes sent to the model for
+// toolName; 0 means unlimited
+func (l *ToolResultLimitsConfig) GetLimit(toolName string) int {
+	limit, ok := l.Tools[toolName]
+	if !ok || limit == 0 {
+		limit, ok = defaultToolResultLimits[toolName]
+		if !ok {
+			limit = l.DefaultBytes
+		}
+	}
+
+	switch {
+	case limit > 0:
+		return limit
+	case limit < 0:
+		return 0
+	}
+	return DefaultToolResultBytes
+}
+
+// CompactionConfig controls when context compaction may run and how much of
+// the recent conversation it leaves untouched
+type CompactionConfig struct {
+	MinMessages    int `json:"min_messages,omitempty"`    // Messages a session needs before compaction is considered (0 = default 4)
+	PreserveRecent int `json:"preserve_recent,omitempty"` // Recent exchanges never compacted; a tool call and its results count as one (0 = default 2)
+
+	// Older user prompts are carried into the compaction summary verbatim
+	// while they use less than UserPromptVerbatimPercent of the context
+	// window, otherwise condensed. A higher threshold preserves the exact
+	// wording of more requests at the cost of context left after
+	// compaction; a lower one frees more context but loses detail.
+	UserPromptVerbatimPercent int `json:"user_prompt_verbatim_percent,omitempty"` // 0 = default 5
+	// Condensed prompts are cut to UserPromptChars characters if there is a
+	// single one and to UserPromptListChars each if there are several.
+	// Larger limits keep more of each request, smaller ones make the summary
+	// cheaper.
+	UserPromptChars     int `json:"user_prompt_chars,omitempty"`      // 0 = default 400
+	UserPromptListChars int `json:"user_prompt_list_chars,omitempty"` // 0 = default 200
+}
+
+// Default compaction floors and user prompt condense limits
+const (
+	DefaultCompactionMinMessages    = 4
+	DefaultCompactionPreserveRecent = 2
+
+	DefaultUserPromptVerbatimPercent = 5
+	DefaultUserPromptChars           = 400
+	DefaultUserPromptListChars       = 200
+)
+
+// GetMinMessages returns the number of messages needed before compaction
+func (c *CompactionConfig) GetMinMessages() int {
+	if c.MinMessages > 0 {
+		return c.MinMessages
+	}
+	return DefaultCompactionMinMessages
+}
+
+// GetPreserveRecent returns the number of recent exchanges kept un-compacted
+func (c *CompactionConfig) GetPreserveRecent() int {
+	if c.PreserveRecent > 0 {
+		return c.PreserveRecent
+	}
+	return DefaultCompactionPreserveRecent
+}
+
+// GetUserPromptVerbatimPercent returns the share of the context window up to
+// which older user prompts are kept verbatim during compaction
+func (c *CompactionConfig) GetUserPromptVerbatimPercent() int {
+	if c != nil && c.UserPromptVerbatimPercent > 0 {
+		return c.UserPromptVerbatimPercent
+	}
+	return DefaultUserPromptVerbatimPercent
+}
+
+// GetUserPromptChars returns the length a single condensed user prompt is cut to
+func (c *CompactionConfig) GetUserPromptChars() int {
+	if c != nil && c.UserPromptChars > 0 {
+		return c.UserPromptChars
+	}
+	return DefaultUserPromptChars
+}
+
+// GetUserPromptListChars returns the length each of several condensed user
+// prompts is cut to
+func (c *CompactionConfig) GetUserPromptListChars() int {
+	if c != nil && c.UserPromptListChars > 0 {
+		return c.UserPromptListChars
+	}
+	return DefaultUserPromptListChars
+}
+
+// DefaultIgnoredDirs are the directory names file search skips when
+// IgnoredDirs is not configured
+var DefaultIgnoredDirs = []string{".git", "node_modules", "vendor", "__pycache__", ".venv"}
+
+// GetIgnoredDirs returns the directory names file search always skips
+func (c *Config) GetIgnoredDirs() []string {
+	if c == nil || len(c.IgnoredDirs) == 0 {
+		return append([]string(nil), DefaultIgnoredDirs...)
+	}
+	return append([]string(nil), c.IgnoredDirs...)
+}
+
+// DisabledToolPattern returns the entry of DisabledTools matching a tool name.
+// Entries are tool names or path.Match globs. For MCP tools, mcpServerKey is
+// the sanitized server key the tool name is prefixed with (mcp_<key>_<tool>);
+// such tools also match entries naming just <tool>.
+func (c *Config) DisabledToolPattern(name, mcpServerKey string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	candidates := []string{name}
+	if mcpServerKey != "" {
+		if tool, ok := strings.CutPrefix(name, "mcp_"+mcpServerKey+"_"); ok && tool != "" {
+			candidates = append(candidates, tool)
+		}
+	}
+
+	for _, pattern := range c.DisabledTools {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		for _, candidate := range candidates {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// DefaultContextFiles are the candidate context files when ContextFiles is
+// not configured. With the default ContextFilesModeFirst, AGENTS.local.md
+// replaces AGENTS.md.
+var DefaultContextFiles = []string{"AGENTS.local.md", "AGENTS.md"}
+
+// DefaultContextFilesMaxBytes caps the combined size of the context files
+// in the system prompt
+const DefaultContextFilesMaxBytes = 32 * 1024
+
+// GetContextFiles returns the candidate context files in priority order
+func (c *Config) GetContextFiles() []string {
+	if c == nil || len(c.ContextFiles) == 0 {
+		return append([]string(nil), DefaultContextFiles...)
+	}
+	return append([]string(nil), c.ContextFiles...)
+}
+
+// ConcatenatesContextFiles reports whether all existing context files are
+// injected instead of only the first one. The same rule applies to configured
+// ContextFiles and to DefaultContextFiles.
+func (c *Config) ConcatenatesContextFiles() bool {
+	return c != nil && c.ContextFilesMode == ContextFilesModeAll
+}
+
+// GetContextFilesMaxBytes returns the cap of the combined context files
+func (c *Config) GetContextFilesMaxBytes() int {
+	if c == nil || c.ContextFilesMaxBytes <= 0 {
+		return DefaultContextFilesMaxBytes
+	}
+	return c.ContextFilesMaxBytes
+}
+
+// GetSpinnerInterval returns the configured spinner frame interval, or 0 to
+// keep the rate of the spinner style
+func (c *Config) GetSpinnerInterval() time.Duration {
+	if c == nil || c.SpinnerIntervalMs <= 0 {
+		return 0
+	}
+	return time.Duration(c.SpinnerIntervalMs) * time.Millisecond
+}
+
+// ContextWindowOverride returns the context window configured for modelID in
+// ContextWindowOverrides, or 0 if none applies. Keys match case-insensitively:
+// an exact match wins, then the longest key that is a prefix of the model ID,
+// then the longest key contained in it.
+func (c *Config) ContextWindowOverride(modelID string) int {
+	if c == nil || len(c.ContextWindowOverrides) == 0 || modelID == "" {
+		return 0
+	}
+	model := strings.ToLower(modelID)
+
+	prefixLen, prefixWindow := 0, 0
+	substrLen, substrWindow := 0, 0
+	for key, window := range c.ContextWindowOverrides {
+		pattern := strings.ToLower(strings.TrimSpace(key))
+		if pattern == "" || window <= 0 {
+			continue
+		}
+		switch {
+		case pattern == model:
+			return window
+		case strings.HasPrefix(model, pattern):
+			if len(pattern) > prefixLen || (len(pattern) == prefixLen && window > prefixWindow) {
+				prefixLen, prefixWindow = len(pattern), window
+			}
+		case strings.Contains(model, pattern):
+			if len(pattern) > substrLen || (len(pattern) == substrLen && window > substrWindow) {
+				substrLen, substrWindow = len(pattern), window
+			}
+		}
+	}
+	if prefixWindow > 0 {
+		return prefixWindow
+	}
+	return substrWindow
+}
+
+// Paste ANSI modes control how the TUI treats escape sequences in prompt input
+const (
+	PasteANSIModeStrip  = "strip"  // Remove escape sequences (default)
+	PasteANSIModeEscape = "escape" // Show escape sequences as visible text (e.g. \x1b)
+	PasteANSIModeFenced = "fenced" // Keep escape sequences verbatim inside fenced code blocks, strip elsewhere
+)
+
+// Completion notify modes control when the TUI signals a finished generation
+const (
+	CompletionNotifyUnfocused = "unfocused" // Only when the terminal window is not focused (default)
+	CompletionNotifyAlways    = "always"    // After every generation
+	CompletionNotifyOff       = "off"       // Never
+)
+
+// Read file modes select the read_file variant offered to the model
+const (
+	ReadFileModeAuto     = "auto"     // Choose by model family (default)
+	ReadFileModeNumbered = "numbered" // Prefix every line with its line number
+	ReadFileModePlain    = "plain"    // Return the file content unchanged
+)
+
+// Context files modes select which existing context files are injected into
+// the system prompt
+const (
+	ContextFilesModeFirst = "first" // Only the first existing candidate (default)
+	ContextFilesModeAll   = "all"   // Every existing candidate, concatenated up to ContextFilesMaxBytes
+)
+
+// TUIConfig holds settings that only affect the terminal UI
+type TUIConfig struct {
+	PasteANSIMode       string `json:"paste_ansi_mode,omitempty"`      // "strip" (default), "escape" or "fenced"
+	MaxPasteBytes       int    `json:"max_paste_bytes,omitempty"`      // Larger pastes are saved to a temp file and attached as @path (0 = default 100 KiB, -1 = never)
+	CompletionNotify    string `json:"completion_notify,omitempty"`    // "unfocused" (default), "always" or "off"
+	DesktopNotification bool   `json:"desktop_notification,omitempty"` // Also send an OSC 9 desktop notification with the bell
+}
+
 // SandboxOutputCompactionConfig holds configuration for sandbox output compaction
 type SandboxOutputCompactionConfig struct {
 	Enabled              bool    `json:"enabled"`
@@ -111,6 +445,40 @@ type LoopConfig struct {
 	EnableLLMAutoContinueJudge     bool   `json:"enable_llm_auto_continue_judge"`          // Enable LLM-based auto-continue decisions
 	LLMAutoContinueJudgeTimeout    int    `json:"llm_auto_continue_judge_timeout_seconds"` // LLM judge timeout in seconds (0 = use default 15s)
 	LLMAutoContinueJudgeTokenLimit int    `json:"llm_auto_continue_judge_token_limit"`     // LLM judge token limit (0 = use default 1000)
+	AutoContinueJudgeModel         string `json:"auto_continue_judge_model,omitempty"`     // Model of the LLM auto-continue judge (empty = summarize model, then orchestration model)
+	MaxRunRetries                  int    `json:"max_run_retries,omitempty"`               // Total LLM retries across one prompt run (0 = use default, negative = unlimited)
+	DeduplicateToolCalls           bool   `json:"deduplicate_tool_calls,omitempty"`        // Execute identical tool calls of one response only once and share the result
+	MaxRepeatedToolFailures        int    `json:"max_repeated_tool_failures,omitempty"`    // Identical tool calls failing in a row before the model is told to change approach; one more failure stops the loop (0 = use default, negative = disabled)
+}
+
+// DefaultLoopMaxRunRetries is used when MaxRunRetries is not set
+const DefaultLoopMaxRunRetries = 30
+
+// GetMaxRunRetries returns how many failed LLM calls may be retried in total
+// during a single prompt run; 0 means unlimited
+func (l *LoopConfig) GetMaxRunRetries() int {
+	switch {
+	case l.MaxRunRetries > 0:
+		return l.MaxRunRetries
+	case l.MaxRunRetries < 0:
+		return 0
+	}
+	return DefaultLoopMaxRunRetries
+}
+
+// DefaultLoopMaxRepeatedToolFailures is used when MaxRepeatedToolFailures is not set
+const DefaultLoopMaxRepeatedToolFailures = 3
+
+// GetMaxRepeatedToolFailures returns how often an identical tool call may fail
+// in a row before the model is corrected; 0 means disabled
+func (l *LoopConfig) GetMaxRepeatedToolFailures() int {
+	switch {
+	case l.MaxRepeatedToolFailures > 0:
+		return l.MaxRepeatedToolFailures
+	case l.MaxRepeatedToolFailures < 0:
+		return 0
+	}
+	return DefaultLoopMaxRepeatedToolFailures
 }
 
 // SandboxConfig holds configuration for shell command sandboxing
@@ -131,24 +499,111 @@ type SandboxConfig struct {
 	// rules cannot be enforced (e.g., due to insufficient kernel support).
 	// When false, landlock will fail if it cannot fully enforce all restrictions.
 	BestEffort bool `json:"best_effort,omitempty"`
+
+	// MaxExecutionTimeoutSeconds caps the per-call timeout of go_sandbox
+	// executions (0 = use the built-in maximum of 3600 seconds)
+	MaxExecutionTimeoutSeconds int `json:"max_execution_timeout_seconds,omitempty"`
+
+	// AllowedModules are the third-party modules go_sandbox programs may
+	// import besides the standard library, as "path@version h1:<module hash>
+	// h1:<go.mod hash>" with the hashes of the module's go.sum lines (empty =
+	// a vetted default set including gopkg.in/yaml.v3)
+	AllowedModules []string `json:"allowed_modules,omitempty"`
+
+	// SkipUnavailableGoSandbox leaves go_sandbox out of the tool list when
+	// TinyGo is unavailable instead of registering a tool that always fails
+	SkipUnavailableGoSandbox bool `json:"skip_unavailable_go_sandbox,omitempty"`
 }
 
 // SocketConfig holds configuration for the Unix socket server
 type SocketConfig struct {
-	Enabled               bool   `json:"enabled"`                     // Enable/disable socket server
-	AutoConnect           bool   `json:"auto_connect"`                // Auto-detect and connect to socket server in clients
-	Path                  string `json:"path"`                        // Socket file path (~/.scriptschnell.sock)
-	Permissions           string `json:"permissions,omitempty"`       // Octal permissions (e.g., "0600")
-	RequireAuth           bool   `json:"require_auth"`                // Whether auth is required
-	AuthMethod            string `json:"auth_method,omitempty"`       // "file", "token", "challenge", "peercred"
-	Token                 string `json:"token,omitempty"`             // Pre-shared token (empty string = not encrypted)
-	AllowedUIDs           []int  `json:"allowed_uids,omitempty"`      // Allowed user IDs for peercred
-	AllowedGIDs           []int  `json:"allowed_gids,omitempty"`      // Allowed group IDs for peercred
-	MaxConnections        int    `json:"max_connections"`             // Max concurrent connections
-	MaxSessionsPerConn    int    `json:"max_sessions_per_connection"` // Max sessions per connection
-	ConnectionTimeoutSecs int    `json:"connection_timeout_seconds"`  // Idle timeout in seconds
-	EnableBatching        bool   `json:"enable_batching"`             // Enable message batching
-	BatchSize             int    `json:"batch_size"`                  // Messages per batch
+	Enabled                bool   `json:"enabled"`                      // Enable/disable socket server
+	AutoConnect            bool   `json:"auto_connect"`                 // Auto-detect and connect to socket server in clients
+	Path                   string `json:"path"`                         // Socket file path (~/.scriptschnell.sock)
+	Permissions            string `json:"permissions,omitempty"`        // Octal permissions (e.g., "0600")
+	RequireAuth            bool   `json:"require_auth"`                 // Whether auth is required
+	AuthMethod             string `json:"auth_method,omitempty"`        // "file", "token", "challenge", "peercred"
+	Token                  string `json:"token,omitempty"`              // Pre-shared token (empty string = not encrypted)
+	AllowedUIDs            []int  `json:"allowed_uids,omitempty"`       // Allowed user IDs for peercred (default: the server's user)
+	AllowedGIDs            []int  `json:"allowed_gids,omitempty"`       // Allowed group IDs for peercred
+	DisablePeerCheck       bool   `json:"disable_peer_check,omitempty"` // Skip the default peer UID check (auth_method "peercred" and allowlists still enforce it)
+	MaxConnections         int    `json:"max_connections"`              // Max concurrent connections
+	MaxSessionsPerConn     int    `json:"max_sessions_per_connection"`  // Max sessions per connection
+	ConnectionTimeoutSecs  int    `json:"connection_timeout_seconds"`   // Idle timeout in seconds
+	EnableBatching         bool   `json:"enable_batching"`              // Enable message batching
+	BatchSize              int    `json:"batch_size"`                   // Messages per batch
+	EnableCompression      bool   `json:"enable_compression"`           // Allow gzip compression of large messages
+	MaxQueuedPrompts       int    `json:"max_queued_prompts"`           // Prompts queued per session while generating (0 = default)
+	ShutdownTimeoutSecs    int    `json:"shutdown_timeout_seconds"`     // How long shutdown waits for running generations (0 = default)
+	SessionIdleTimeoutSecs int    `json:"session_idle_timeout_seconds"` // Evict detached sessions idle this long (0 = never)
+	MaxMessageBytes        int    `json:"max_message_bytes"`            // Largest accepted client message, also after decompression (0 = default, <0 = unlimited)
+	MaxMessagesPerSecond   int    `json:"max_messages_per_second"`      // Client messages per second per connection (0 = default, <0 = unlimited)
+	DisconnectOnLimit      bool   `json:"disconnect_on_limit"`          // Close connections exceeding a message limit instead of rejecting the message
+	AuditLogMaxBytes       int    `json:"audit_log_max_bytes"`          // Size at which a workspace audit log is rotated (0 = default, <0 = no audit log)
+	MetricsAddr            string `json:"metrics_addr,omitempty"`       // Loopback address of the Prometheus metrics endpoint, e.g. "127.0.0.1:9464" (empty = disabled)
+}
+
+// DefaultSocketShutdownTimeout is used when ShutdownTimeoutSecs is not set
+const DefaultSocketShutdownTimeout = 60 * time.Second
+
+// GetShutdownTimeout returns how long a shutdown waits for running generations
+func (s *SocketConfig) GetShutdownTimeout() time.Duration {
+	if s.ShutdownTimeoutSecs > 0 {
+		return time.Duration(s.ShutdownTimeoutSecs) * time.Second
+	}
+	return DefaultSocketShutdownTimeout
+}
+
+// DefaultSocketMaxMessageBytes is used when MaxMessageBytes is not set
+const DefaultSocketMaxMessageBytes = 16 << 20
+
+// DefaultSocketMaxMessagesPerSecond is used when MaxMessagesPerSecond is not set
+const DefaultSocketMaxMessagesPerSecond = 100
+
+// GetMaxMessageBytes returns the largest accepted client message; 0 means unlimited
+func (s *SocketConfig) GetMaxMessageBytes() int {
+	switch {
+	case s.MaxMessageBytes > 0:
+		return s.MaxMessageBytes
+	case s.MaxMessageBytes < 0:
+		return 0
+	}
+	return DefaultSocketMaxMessageBytes
+}
+
+// DefaultSocketAuditLogMaxBytes is used when AuditLogMaxBytes is not set
+const DefaultSocketAuditLogMaxBytes = 5 << 20
+
+// GetAuditLogMaxBytes returns the size at which a workspace audit log is
+// rotated; 0 means the audit log is disabled
+func (s *SocketConfig) GetAuditLogMaxBytes() int64 {
+	switch {
+	case s.AuditLogMaxBytes > 0:
+		return int64(s.AuditLogMaxBytes)
+	case s.AuditLogMaxBytes < 0:
+		return 0
+	}
+	return DefaultSocketAuditLogMaxBytes
+}
+
+// GetMaxMessagesPerSecond returns the per-connection message rate; 0 means unlimited
+func (s *SocketConfig) GetMaxMessagesPerSecond() int {
+	switch {
+	case s.MaxMessagesPerSecond > 0:
+		return s.MaxMessagesPerSecond
+	case s.MaxMessagesPerSecond < 0:
+		return 0
+	}
+	return DefaultSocketMaxMessagesPerSecond
+}
+
+// GetSessionIdleTimeout returns after how long a detached, inactive session is
+// evicted from memory; 0 disables eviction
+func (s *SocketConfig) GetSessionIdleTimeout() time.Duration {
+	if s.SessionIdleTimeoutSecs > 0 {
+		return time.Duration(s.SessionIdleTimeoutSecs) * time.Second
+	}
+	return 0
 }
 
 // DefaultSocketPath is the default socket path
@@ -196,29 +651,52 @@ type Config struct {
 	MaxTokens               int                                    `json:"max_tokens,omitempty"` // DEPRECATED: Only used as fallback when model doesn't specify context window
 	ProviderConfigPath      string                                 `json:"-"`
 	DisableAnimations       bool                                   `json:"disable_animations"`
-	LogLevel                string                                 `json:"log_level"` // debug, info, warn, error, none
+	SpinnerStyle            string                                 `json:"spinner_style,omitempty"`       // line (default), dot, minidot, jump, pulse, points, globe, moon, monkey, meter, hamburger, ellipsis
+	SpinnerIntervalMs       int                                    `json:"spinner_interval_ms,omitempty"` // Spinner frame interval; 0 uses the style's own rate
+	LogLevel                string                                 `json:"log_level"`                     // debug, info, warn, error, none
 	LogPath                 string                                 `json:"-"`
-	LogToConsole            bool                                   `json:"log_to_console"`                // Enable console logging in addition to file logging
-	AuthorizedDomains       map[string]bool                        `json:"authorized_domains,omitempty"`  // Permanently authorized domains for network access
-	AuthorizedCommands      map[string]bool                        `json:"authorized_commands,omitempty"` // Permanently authorized command prefixes for this project
-	Search                  SearchConfig                           `json:"search"`                        // Web search provider configuration
-	MCP                     MCPConfig                              `json:"mcp,omitempty"`                 // Custom MCP server configuration
-	Secrets                 SecretsSettings                        `json:"secrets,omitempty"`             // Encryption settings
-	EnablePromptCache       bool                                   `json:"enable_prompt_cache"`           // Enable prompt caching for compatible providers (Anthropic, OpenAI). Disabled by default as some providers like Mistral don't support cache_control ephemeral
-	PromptCacheTTL          string                                 `json:"prompt_cache_ttl,omitempty"`    // Cache TTL: "5m" or "1h" (default: "1h", Anthropic only)
-	ContextDirectories      map[string][]string                    `json:"context_directories,omitempty"` // Workspace-specific context directories (map of workspace path -> directories)
-	OpenTabs                map[string]*WorkspaceTabState          `json:"open_tabs,omitempty"`           // Workspace-specific open tabs state (map of workspace path -> tab state)
-	LandlockApprovals       map[string]*LandlockWorkspaceApprovals `json:"landlock_approvals,omitempty"`  // Workspace-specific landlock approvals (map of workspace hash -> approvals)
-	Sandbox                 SandboxConfig                          `json:"sandbox,omitempty"`             // Sandbox configuration for shell commands
-	AutoSave                AutoSaveConfig                         `json:"auto_save,omitempty"`           // Session auto-save configuration
-	AutoResume              bool                                   `json:"auto_resume"`                   // Automatically resume last session on startup
-	SandboxOutputCompaction SandboxOutputCompactionConfig          `json:"sandbox_output_compaction"`     // Sandbox output compaction configuration
-	Socket                  SocketConfig                           `json:"socket,omitempty"`              // Unix socket server configuration
-	Loop                    LoopConfig                             `json:"loop,omitempty"`                // Loop abstraction configuration
+	LogToConsole            bool                                   `json:"log_to_console"`                     // Enable console logging in addition to file logging
+	AuthorizedDomains       map[string]bool                        `json:"authorized_domains,omitempty"`       // Permanently authorized domains for network access
+	AuthorizedCommands      map[string]bool                        `json:"authorized_commands,omitempty"`      // Permanently authorized command prefixes for this project
+	Search                  SearchConfig                           `json:"search"`                             // Web search provider configuration
+	MCP                     MCPConfig                              `json:"mcp,omitempty"`                      // Custom MCP server configuration
+	Secrets                 SecretsSettings                        `json:"secrets,omitempty"`                  // Encryption settings
+	EnablePromptCache       bool                                   `json:"enable_prompt_cache"`                // Enable prompt caching for compatible providers (Anthropic, OpenAI). Disabled by default as some providers like Mistral don't support cache_control ephemeral
+	PromptCacheTTL          string                                 `json:"prompt_cache_ttl,omitempty"`         // Cache TTL: "5m" or "1h" (default: "1h", Anthropic only)
+	ContextDirectories      map[string][]string                    `json:"context_directories,omitempty"`      // Workspace-specific context directories (map of workspace path -> directories)
+	OpenTabs                map[string]*WorkspaceTabState          `json:"open_tabs,omitempty"`                // Workspace-specific open tabs state (map of workspace path -> tab state)
+	LandlockApprovals       map[string]*LandlockWorkspaceApprovals `json:"landlock_approvals,omitempty"`       // Workspace-specific landlock approvals (map of workspace hash -> approvals)
+	WorkspaceTemplates      map[string]*WorkspaceTemplate          `json:"workspace_templates,omitempty"`      // Bootstrapping of new session worktrees (map of base repository root -> template)
+	Sandbox                 SandboxConfig                          `json:"sandbox,omitempty"`                  // Sandbox configuration for shell commands
+	AutoSave                AutoSaveConfig                         `json:"auto_save,omitempty"`                // Session auto-save configuration
+	AutoResume              bool                                   `json:"auto_resume"`                        // Automatically resume last session on startup
+	SandboxOutputCompaction SandboxOutputCompactionConfig          `json:"sandbox_output_compaction"`          // Sandbox output compaction configuration
+	Socket                  SocketConfig                           `json:"socket,omitempty"`                   // Unix socket server configuration
+	Loop                    LoopConfig                             `json:"loop,omitempty"`                     // Loop abstraction configuration
+	AutoInstallTinyGo       bool                                   `json:"auto_install_tinygo"`                // Download the pinned TinyGo release for go_sandbox if it is not installed
+	Redaction               RedactionConfig                        `json:"redaction,omitempty"`                // Secret masking in logs and tool results
+	TUI                     TUIConfig                              `json:"tui,omitempty"`                      // Terminal UI settings
+	DryRun                  bool                                   `json:"-"`                                  // Simulate tool calls that would change files or run programs (runtime only, set by --dry-run)
+	ReadOnly                bool                                   `json:"read_only,omitempty"`                // Only register read/search tools and deny every write
+	ReadFileMode            string                                 `json:"read_file_mode,omitempty"`           // "auto" (default), "numbered" or "plain"
+	AuthorizationPolicy     string                                 `json:"authorization_policy,omitempty"`     // Path of a JSON policy file whose allow/deny rules decide tool calls before prompting (empty = none)
+	ToolResultLimits        ToolResultLimitsConfig                 `json:"tool_result_limits,omitempty"`       // Caps on tool result size sent to the model
+	Compaction              CompactionConfig                       `json:"compaction,omitempty"`               // Floors for context compaction
+	IgnoredDirs             []string                               `json:"ignored_dirs,omitempty"`             // Directory names file search always skips, in addition to .gitignore (empty = DefaultIgnoredDirs)
+	ContextFiles            []string                               `json:"context_files,omitempty"`            // Candidate files relative to the working directory injected into the system prompt, in order (empty = DefaultContextFiles)
+	ContextFilesMode        string                                 `json:"context_files_mode,omitempty"`       // "first" (default) or "all" existing candidates
+	ContextFilesMaxBytes    int                                    `json:"context_files_max_bytes,omitempty"`  // Cap of the combined context files (0 = DefaultContextFilesMaxBytes)
+	ErrorJudgeModel         string                                 `json:"error_judge_model,omitempty"`        // Model deciding retries on LLM errors (empty = summarize model, then orchestration model)
+	ContextWindowOverrides  map[string]int                         `json:"context_window_overrides,omitempty"` // Model ID, prefix or substring -> context window in tokens; wins over provider metadata and heuristics
+	PromptTemplates         PromptTemplatesConfig                  `json:"prompt_templates,omitempty"`         // Go text/template overrides for the compaction and error judge prompts
+	Snippets                map[string]string                      `json:"snippets,omitempty"`                 // Snippet name -> text/template prompt, invoked as /snippet <name> [args]
+	DisabledTools           []string                               `json:"disabled_tools,omitempty"`           // Tool names or globs (e.g. "web_*") that are never registered; also matches MCP tools by their name without the mcp_<server>_ prefix
 
 	authMu          sync.RWMutex `json:"-"` // Protects AuthorizedDomains and AuthorizedCommands for concurrent access
 	secretsPassword string       `json:"-"` // Kept for backward compatibility
 	mu              sync.RWMutex `json:"-"` // Protects the entire config during save/load operations
+	updateMu        sync.Mutex   `json:"-"` // Serializes UpdateVersioned
+	version         uint64       `json:"-"` // Advanced by every save that changes the file (atomic)
 }
 
 // SecretsSettings keeps track of password-protection state.
@@ -233,6 +711,7 @@ type WorkspaceTabState struct {
 	TabIDs        []int          `json:"tab_ids"`                  // Ordered list of tab IDs
 	TabNames      map[int]string `json:"tab_names,omitempty"`      // Tab ID -> name mapping
 	WorktreePaths map[int]string `json:"worktree_paths,omitempty"` // Tab ID -> worktree path
+	WorkingDirs   map[int]string `json:"working_dirs,omitempty"`   // Tab ID -> directory the tab is pinned to
 }
 
 // LandlockApproval represents an approved directory path for sandboxed shell execution
@@ -246,6 +725,27 @@ type LandlockWorkspaceApprovals struct {
 	Directories []LandlockApproval `json:"directories,omitempty"`
 }
 
+// WorkspaceTemplate bootstraps a new session worktree of a repository. Git
+// worktrees only contain tracked files, so untracked files like .env or local
+// tool configuration are copied over, and a setup command (e.g. installing
+// dependencies) runs in the new worktree.
+type WorkspaceTemplate struct {
+	Files            []string `json:"files,omitempty"`                 // Paths relative to the repository root copied into the worktree; missing files are skipped
+	SetupCommand     string   `json:"setup_command,omitempty"`         // Shell command run in the worktree after copying (empty = none)
+	SetupTimeoutSecs int      `json:"setup_timeout_seconds,omitempty"` // How long the setup command may run (0 = default)
+}
+
+// DefaultWorkspaceSetupTimeout is used when SetupTimeoutSecs is not set
+const DefaultWorkspaceSetupTimeout = 5 * time.Minute
+
+// GetSetupTimeout returns how long the setup command may run
+func (t *WorkspaceTemplate) GetSetupTimeout() time.Duration {
+	if t != nil && t.SetupTimeoutSecs > 0 {
+		return time.Duration(t.SetupTimeoutSecs) * time.Second
+	}
+	return DefaultWorkspaceSetupTimeout
+}
+
 func defaultConfigDir() string {
 	switch runtime.GOOS {
 	case "linux":
@@ -283,6 +783,12 @@ func defaultStateDir() string {
 	}
 }
 
+// DefaultDocsDir returns the directory the fetch_docs tool downloads
+// documentation into
+func DefaultDocsDir() string {
+	return filepath.Join(defaultStateDir(), "docs")
+}
+
 // DefaultConfig returns default configuration
 func DefaultConfig() *Config {
 	configDir := defaultConfigDir()
@@ -333,17 +839,24 @@ func DefaultConfig() *Config {
 			BestEffort:               true, // Best-effort mode for better compatibility
 		},
 		Socket: SocketConfig{
-			Enabled:               true,
-			AutoConnect:           true,
-			Path:                  "~/.scriptschnell.sock",
-			Permissions:           "0600",
-			RequireAuth:           false,
-			AuthMethod:            "file",
-			MaxConnections:        10,
-			MaxSessionsPerConn:    1,
-			ConnectionTimeoutSecs: 300,
-			EnableBatching:        true,
-			BatchSize:             10,
+			Enabled:                true,
+			AutoConnect:            true,
+			Path:                   "~/.scriptschnell.sock",
+			Permissions:            "0600",
+			RequireAuth:            false,
+			AuthMethod:             "file",
+			MaxConnections:         10,
+			MaxSessionsPerConn:     1,
+			ConnectionTimeoutSecs:  300,
+			EnableBatching:         true,
+			BatchSize:              10,
+			EnableCompression:      true,
+			MaxQueuedPrompts:       10,
+			ShutdownTimeoutSecs:    60,
+			SessionIdleTimeoutSecs: 3600,
+			MaxMessageBytes:        DefaultSocketMaxMessageBytes,
+			MaxMessagesPerSecond:   DefaultSocketMaxMessagesPerSecond,
+			AuditLogMaxBytes:       DefaultSocketAuditLogMaxBytes,
 		},
 	}
 }
@@ -403,6 +916,14 @@ func Load(path string) (*Config, error) {
 	if config.LandlockApprovals == nil {
 		config.LandlockApprovals = make(map[string]*LandlockWorkspaceApprovals)
 	}
+	if err := prompttemplate.Validate(config.PromptTemplates.CompactionSummary, config.PromptTemplates.ErrorJudge); err != nil {
+		return nil, fmt.Errorf("invalid prompt_templates: %w", err)
+	}
+	for name, text := range config.Snippets {
+		if err := prompttemplate.ValidateSnippet(name, text); err != nil {
+			return nil, fmt.Errorf("invalid snippets: %w", err)
+		}
+	}
 
 	return config, nil
 }
@@ -447,6 +968,20 @@ func (c *Config) IsCommandAuthorized(commandPrefix string) bool {
 	return c.AuthorizedCommands[commandPrefix]
 }
 
+// AuthorizedCommandPrefixes returns the enabled, permanently authorized
+// command prefixes
+func (c *Config) AuthorizedCommandPrefixes() []string {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	prefixes := make([]string, 0, len(c.AuthorizedCommands))
+	for prefix, enabled := range c.AuthorizedCommands {
+		if enabled && prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
 // AddContextDirectory adds a directory to the context directories list for a specific workspace
 // The workspace parameter should be an absolute path to the workspace directory
 func (c *Config) AddContextDirectory(workspace, dir string) {
@@ -529,6 +1064,22 @@ func (c *Config) GetContextDirectories(workspace string) []string {
 	return result
 }
 
+// GetWorkspaceTemplate returns the template for new worktrees of the
+// repository at repoRoot, or nil if there is none
+func (c *Config) GetWorkspaceTemplate(repoRoot string) *WorkspaceTemplate {
+	if c.WorkspaceTemplates == nil {
+		return nil
+	}
+
+	absRoot := repoRoot
+	if !filepath.IsAbs(repoRoot) {
+		if abs, err := filepath.Abs(repoRoot); err == nil {
+			absRoot = abs
+		}
+	}
+	return c.WorkspaceTemplates[filepath.Clean(absRoot)]
+}
+
 // SetOpenTabState sets the tab state for a workspace in a thread-safe manner.
 // This method acquires a write lock to ensure safe concurrent access.
 func (c *Config) SetOpenTabState(workspace string, tabState *WorkspaceTabState) {
@@ -722,7 +1273,54 @@ func (c *Config) Save(path string) error {
 	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
 		return err
 	}
-	return os.Rename(tmpPath, path)
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	atomic.AddUint64(&c.version, 1)
+	return nil
+}
+
+// ErrVersionConflict is returned by UpdateVersioned when the config was
+// changed since the expected version was read
+var ErrVersionConflict = errors.New("config was changed concurrently")
+
+// Version returns the in-memory revision of the config. It starts at 0 in
+// every process and advances with every Save that writes the config file and
+// every UpdateVersioned, so clients of one server can detect concurrent
+// changes. It is not stored in the config file.
+func (c *Config) Version() uint64 {
+	return atomic.LoadUint64(&c.version)
+}
+
+// UpdateVersioned applies update and, with a non-empty path, saves the config
+// to path, unless the config changed since expectedVersion was read
+// (ErrVersionConflict). With an empty path the update is only applied in
+// memory. Returns the version after the update, or the current version on a
+// conflict.
+func (c *Config) UpdateVersioned(path string, expectedVersion uint64, update func(*Config)) (uint64, error) {
+	c.updateMu.Lock()
+	defer c.updateMu.Unlock()
+
+	current := c.Version()
+	if current != expectedVersion {
+		return current, ErrVersionConflict
+	}
+
+	c.mu.Lock()
+	update(c)
+	c.mu.Unlock()
+
+	if path == "" {
+		return atomic.AddUint64(&c.version, 1), nil
+	}
+	if err := c.Save(path); err != nil {
+		return c.Version(), err
+	}
+	if c.Version() == current {
+		// Save skipped writing the unchanged file
+		atomic.AddUint64(&c.version, 1)
+	}
+	return c.Version(), nil
 }
 
 // GetConfigPath returns the default config path
@@ -810,6 +1408,8 @@ func (c *Config) marshalWithEncryptedSecrets() ([]byte, error) {
 		MaxTokens:               c.MaxTokens,
 		ProviderConfigPath:      c.ProviderConfigPath,
 		DisableAnimations:       c.DisableAnimations,
+		SpinnerStyle:            c.SpinnerStyle,
+		SpinnerIntervalMs:       c.SpinnerIntervalMs,
 		LogLevel:                c.LogLevel,
 		LogPath:                 c.LogPath,
 		LogToConsole:            c.LogToConsole,
@@ -823,12 +1423,30 @@ func (c *Config) marshalWithEncryptedSecrets() ([]byte, error) {
 		PromptCacheTTL:          c.PromptCacheTTL,
 		ContextDirectories:      c.ContextDirectories,
 		OpenTabs:                c.OpenTabs,
+		WorkspaceTemplates:      c.WorkspaceTemplates,
 		AutoResume:              c.AutoResume,
 		SandboxOutputCompaction: c.SandboxOutputCompaction,
 		LandlockApprovals:       c.LandlockApprovals,
 		Sandbox:                 c.Sandbox,
 		Socket:                  c.Socket,
 		Loop:                    c.Loop,
+		AutoInstallTinyGo:       c.AutoInstallTinyGo,
+		Redaction:               c.Redaction,
+		ToolResultLimits:        c.ToolResultLimits,
+		Compaction:              c.Compaction,
+		IgnoredDirs:             c.IgnoredDirs,
+		ContextFiles:            c.ContextFiles,
+		ContextFilesMode:        c.ContextFilesMode,
+		ContextFilesMaxBytes:    c.ContextFilesMaxBytes,
+		ErrorJudgeModel:         c.ErrorJudgeModel,
+		ContextWindowOverrides:  c.ContextWindowOverrides,
+		PromptTemplates:         c.PromptTemplates,
+		Snippets:                c.Snippets,
+		DisabledTools:           c.DisabledTools,
+		TUI:                     c.TUI,
+		ReadOnly:                c.ReadOnly,
+		ReadFileMode:            c.ReadFileMode,
+		AuthorizationPolicy:     c.AuthorizationPolicy,
 		secretsPassword:         c.secretsPassword,
 	}
 
@@ -30,6 +30,9 @@ func TestAllFieldsPreservedThroughSaveLoad(t *testing.T) {
 	originalCfg.LogLevel = "debug"
 	originalCfg.Search.Provider = "exa"
 	originalCfg.Search.Exa.APIKey = "test-api-key"
+	originalCfg.ReadOnly = true
+	originalCfg.AuthorizationPolicy = "/etc/scriptschnell/policy.json"
+	originalCfg.ContextFilesMode = ContextFilesModeAll
 
 	// Set Sandbox config (note: BestEffort defaults to true, so we don't override it)
 	originalCfg.Sandbox.AdditionalReadOnlyPaths = []string{"/read-only-path"}
@@ -85,6 +88,18 @@ func TestAllFieldsPreservedThroughSaveLoad(t *testing.T) {
 			loadedCfg.Loop.EnableAutoContinue, originalCfg.Loop.EnableAutoContinue)
 	}
 
+	if loadedCfg.ReadOnly != originalCfg.ReadOnly {
+		t.Errorf("ReadOnly not preserved: got %v, want %v", loadedCfg.ReadOnly, originalCfg.ReadOnly)
+	}
+
+	if loadedCfg.AuthorizationPolicy != originalCfg.AuthorizationPolicy {
+		t.Errorf("AuthorizationPolicy not preserved: got %q, want %q", loadedCfg.AuthorizationPolicy, originalCfg.AuthorizationPolicy)
+	}
+
+	if loadedCfg.ContextFilesMode != originalCfg.ContextFilesMode {
+		t.Errorf("ContextFilesMode not preserved: got %q, want %q", loadedCfg.ContextFilesMode, originalCfg.ContextFilesMode)
+	}
+
 	if loadedCfg.Temperature != originalCfg.Temperature {
 		t.Errorf("Temperature not preserved: got %v, want %v", loadedCfg.Temperature, originalCfg.Temperature)
 	}
@@ -133,3 +148,22 @@ func TestDefaultsUsedForMissingFields(t *testing.T) {
 		t.Logf("Loop.Strategy is %q", cfg.Loop.Strategy)
 	}
 }
+
+// Test that settings only set for a single run are never written to the file
+func TestRuntimeOnlyFieldsNotSaved(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := DefaultConfig()
+	cfg.DryRun = true
+	if err := cfg.Save(configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	loadedCfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if loadedCfg.DryRun {
+		t.Error("DryRun must not be persisted")
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAddContextDirectory(t *testing.T) {
@@ -249,3 +252,205 @@ func TestWorkspaceIsolation(t *testing.T) {
 		t.Error("Expected workspace1 to be removed from map when all directories removed")
 	}
 }
+
+func TestContextWindowOverride(t *testing.T) {
+	cfg := &Config{ContextWindowOverrides: map[string]int{
+		"qwen3-coder":      262144,
+		"qwen3":            32768,
+		"my-model-exact":   50000,
+		"glm":              131072,
+		"ignored-negative": -1,
+	}}
+
+	tests := []struct {
+		modelID string
+		want    int
+	}{
+		{"my-model-exact", 50000},
+		{"Qwen3-Coder-480B", 262144}, // longest prefix wins
+		{"qwen3-8b", 32768},
+		{"z-ai/glm-4.6", 131072}, // substring match
+		{"ignored-negative-model", 0},
+		{"gpt-4o", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := cfg.ContextWindowOverride(tt.modelID); got != tt.want {
+			t.Errorf("ContextWindowOverride(%q) = %d, want %d", tt.modelID, got, tt.want)
+		}
+	}
+
+	var nilCfg *Config
+	if got := nilCfg.ContextWindowOverride("qwen3"); got != 0 {
+		t.Errorf("expected 0 for nil config, got %d", got)
+	}
+}
+
+func TestLoadRejectsInvalidPromptTemplate(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configJSON := `{
+		"prompt_templates": {
+			"compaction_summary": "{{.Instruction"
+		}
+	}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), "prompt_templates") {
+		t.Fatalf("expected prompt_templates error, got %v", err)
+	}
+}
+
+func TestCompactionConfigDefaults(t *testing.T) {
+	var cfg CompactionConfig
+	if cfg.GetMinMessages() != DefaultCompactionMinMessages || cfg.GetPreserveRecent() != DefaultCompactionPreserveRecent {
+		t.Errorf("expected the defaults, got %d/%d", cfg.GetMinMessages(), cfg.GetPreserveRecent())
+	}
+
+	cfg = CompactionConfig{MinMessages: 12, PreserveRecent: 4}
+	if cfg.GetMinMessages() != 12 || cfg.GetPreserveRecent() != 4 {
+		t.Errorf("expected the configured floors, got %d/%d", cfg.GetMinMessages(), cfg.GetPreserveRecent())
+	}
+}
+
+func TestCompactionConfigUserPromptLimits(t *testing.T) {
+	var unset *CompactionConfig
+	if unset.GetUserPromptVerbatimPercent() != DefaultUserPromptVerbatimPercent ||
+		unset.GetUserPromptChars() != DefaultUserPromptChars ||
+		unset.GetUserPromptListChars() != DefaultUserPromptListChars {
+		t.Error("expected a nil config to use the default limits")
+	}
+
+	cfg := &CompactionConfig{UserPromptVerbatimPercent: 20, UserPromptChars: 1000, UserPromptListChars: 300}
+	if cfg.GetUserPromptVerbatimPercent() != 20 || cfg.GetUserPromptChars() != 1000 || cfg.GetUserPromptListChars() != 300 {
+		t.Errorf("expected the configured limits, got %+v", cfg)
+	}
+}
+
+func TestGetWorkspaceTemplate(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.GetWorkspaceTemplate("/test/repo") != nil {
+		t.Error("Expected no template by default")
+	}
+
+	template := &WorkspaceTemplate{Files: []string{".env"}, SetupCommand: "npm install"}
+	cfg.WorkspaceTemplates = map[string]*WorkspaceTemplate{"/test/repo": template}
+	if got := cfg.GetWorkspaceTemplate("/test/repo/"); got != template {
+		t.Errorf("Expected the template of the cleaned repository root, got %+v", got)
+	}
+
+	if got := template.GetSetupTimeout(); got != DefaultWorkspaceSetupTimeout {
+		t.Errorf("Expected the default setup timeout, got %v", got)
+	}
+	template.SetupTimeoutSecs = 30
+	if got := template.GetSetupTimeout(); got != 30*time.Second {
+		t.Errorf("Expected a 30s setup timeout, got %v", got)
+	}
+}
+
+func TestUpdateVersionedRejectsStaleVersion(t *testing.T) {
+	cfg := DefaultConfig()
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	read := cfg.Version()
+	version, err := cfg.UpdateVersioned(path, read, func(c *Config) { c.Temperature = 0.3 })
+	if err != nil {
+		t.Fatalf("first update failed: %v", err)
+	}
+	if version <= read {
+		t.Fatalf("expected the version to advance past %d, got %d", read, version)
+	}
+
+	// A second writer still holding the old version is rejected
+	current, err := cfg.UpdateVersioned(path, read, func(c *Config) { c.Temperature = 0.9 })
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected a version conflict, got %v", err)
+	}
+	if current != version || cfg.Temperature != 0.3 {
+		t.Fatalf("expected the first update to stay (version %d, temperature 0.3), got version %d, temperature %v", version, current, cfg.Temperature)
+	}
+
+	// Saving the same content again still advances the version
+	next, err := cfg.UpdateVersioned(path, version, func(c *Config) { c.Temperature = 0.3 })
+	if err != nil || next <= version {
+		t.Fatalf("expected an unchanged update to advance the version, got %d, %v", next, err)
+	}
+
+	// Without a path the update is only applied in memory
+	inMemory, err := cfg.UpdateVersioned("", next, func(c *Config) { c.Temperature = 0.5 })
+	if err != nil || inMemory <= next || cfg.Temperature != 0.5 {
+		t.Fatalf("expected an in-memory update to advance the version, got %d, %v", inMemory, err)
+	}
+	if saved, err := Load(path); err != nil || saved.Temperature != 0.3 {
+		t.Fatalf("expected the in-memory update not to touch the file, got %v", err)
+	}
+	next = inMemory
+
+	// Other saves that change the file advance it too
+	cfg.MaxTokens = 1234
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if cfg.Version() <= next {
+		t.Fatalf("expected Save to advance the version past %d, got %d", next, cfg.Version())
+	}
+}
+
+func TestDisabledToolPattern(t *testing.T) {
+	cfg := &Config{DisabledTools: []string{"web_search", "go_*", " ", "[bad"}}
+
+	for _, tc := range []struct {
+		name, serverKey, want string
+	}{
+		{"web_search", "", "web_search"},
+		{"go_sandbox", "", "go_*"},
+		{"mcp_search_web_search", "search", "web_search"},
+		{"mcp_my_server_web_search", "my_server", "web_search"},
+		{"mcp_tools_go_run", "tools", "go_*"},
+		{"read_file", "", ""},
+		{"web_fetch", "", ""},
+		{"mcp_web_search_results", "web_search", ""},
+		// Without the server key the tool part is unknown
+		{"mcp_my_server_web_search", "", ""},
+	} {
+		pattern, ok := cfg.DisabledToolPattern(tc.name, tc.serverKey)
+		if ok != (tc.want != "") || pattern != tc.want {
+			t.Errorf("%s: expected %q, got %q (%v)", tc.name, tc.want, pattern, ok)
+		}
+	}
+
+	var nilCfg *Config
+	if _, ok := nilCfg.DisabledToolPattern("web_search", ""); ok {
+		t.Error("expected a nil config to disable nothing")
+	}
+}
+
+func TestDisabledToolsSurviveSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := DefaultConfig()
+	cfg.DisabledTools = []string{"web_search", "go_*"}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(loaded.DisabledTools) != 2 || loaded.DisabledTools[0] != "web_search" || loaded.DisabledTools[1] != "go_*" {
+		t.Fatalf("expected disabled tools to survive a save, got %v", loaded.DisabledTools)
+	}
+}
+
+func TestAuthorizedCommandPrefixes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AuthorizeCommand("make")
+	cfg.AuthorizedCommands["npm"] = false
+
+	prefixes := cfg.AuthorizedCommandPrefixes()
+	if len(prefixes) != 1 || prefixes[0] != "make" {
+		t.Errorf("expected only the enabled prefix, got %v", prefixes)
+	}
+}
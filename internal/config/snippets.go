@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/codefionn/scriptschnell/internal/prompttemplate"
+)
+
+// snippetNamePattern restricts snippet names to what can be typed as a
+// single command argument
+var snippetNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// reservedSnippetNames are the /snippet subcommands
+var reservedSnippetNames = map[string]bool{"list": true, "add": true, "remove": true, "help": true}
+
+// SetSnippet adds or replaces a prompt snippet after validating its name and
+// template
+func (c *Config) SetSnippet(name, text string) error {
+	if !snippetNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid snippet name %q: use letters, digits, '-' and '_'", name)
+	}
+	if reservedSnippetNames[name] {
+		return fmt.Errorf("invalid snippet name %q: reserved for /snippet %s", name, name)
+	}
+	if err := prompttemplate.ValidateSnippet(name, text); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Snippets == nil {
+		c.Snippets = make(map[string]string)
+	}
+	c.Snippets[name] = text
+	return nil
+}
+
+// ParseSnippetAddArgs splits the arguments of "/snippet add" into the snippet
+// name and its template. The template is the raw rest of the command line, so
+// multi-line and indented templates keep their whitespace; only the blanks
+// after the name and a line break right after them are dropped.
+func ParseSnippetAddArgs(args string) (name, text string, err error) {
+	args = strings.TrimLeftFunc(args, unicode.IsSpace)
+	end := strings.IndexFunc(args, unicode.IsSpace)
+	if end < 0 {
+		return "", "", fmt.Errorf("usage: /snippet add <name> <template>")
+	}
+
+	name = args[:end]
+	text = strings.TrimLeft(args[end:], " \t")
+	if rest, ok := strings.CutPrefix(text, "\r\n"); ok {
+		text = rest
+	} else {
+		text = strings.TrimPrefix(text, "\n")
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", "", fmt.Errorf("usage: /snippet add <name> <template>")
+	}
+	return name, text, nil
+}
+
+// RemoveSnippet removes a prompt snippet; it reports whether it existed
+func (c *Config) RemoveSnippet(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.Snippets[name]; !ok {
+		return false
+	}
+	delete(c.Snippets, name)
+	return true
+}
+
+// GetSnippet returns the template of a prompt snippet
+func (c *Config) GetSnippet(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	text, ok := c.Snippets[name]
+	return text, ok
+}
+
+// SnippetNames returns the names of all prompt snippets, sorted
+func (c *Config) SnippetNames() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.Snippets))
+	for name := range c.Snippets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandSnippet renders a prompt snippet with the given arguments
+func (c *Config) ExpandSnippet(name string, args []string) (string, error) {
+	text, ok := c.GetSnippet(name)
+	if !ok {
+		return "", fmt.Errorf("unknown snippet: %s", name)
+	}
+	return prompttemplate.RenderSnippet(name, text, args)
+}
@@ -104,6 +104,7 @@ func (d *Database) migrate() error {
 		raw_output TEXT,
 		errors TEXT,
 		detailed_execution_info TEXT,
+		stop_reason TEXT,
 		FOREIGN KEY (run_id) REFERENCES eval_runs(id) ON DELETE CASCADE
 	);
 
@@ -588,12 +589,12 @@ func (d *Database) CreateEvalResult(result *EvalResult) error {
 		INSERT INTO eval_results 
 		(run_id, test_case_id, passed, actual_output, expected_output, error, 
 		 input_tokens, output_tokens, estimate_cost, response_time, execution_time,
-		 container_name, raw_output, errors, detailed_execution_info)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 container_name, raw_output, errors, detailed_execution_info, stop_reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, result.RunID, result.TestCaseID, result.Passed, result.ActualOutput,
 		result.ExpectedOutput, result.Error, result.InputTokens, result.OutputTokens,
 		result.EstimateCost, result.ResponseTime, result.ExecutionTime,
-		result.ContainerName, result.RawOutput, result.Errors, result.DetailedExecutionInfo)
+		result.ContainerName, result.RawOutput, result.Errors, result.DetailedExecutionInfo, result.StopReason)
 	return err
 }
 
@@ -602,7 +603,7 @@ func (d *Database) GetEvalResults(runID int64) ([]EvalResult, error) {
 	rows, err := d.db.Query(`
 		SELECT id, run_id, test_case_id, passed, actual_output, expected_output, error,
 			   input_tokens, output_tokens, estimate_cost, response_time, execution_time,
-			   container_name, raw_output, errors, detailed_execution_info
+			   container_name, raw_output, errors, detailed_execution_info, stop_reason
 		FROM eval_results WHERE run_id = ?
 		ORDER BY id
 	`, runID)
@@ -616,13 +617,13 @@ func (d *Database) GetEvalResults(runID int64) ([]EvalResult, error) {
 	var results []EvalResult
 	for rows.Next() {
 		var result EvalResult
-		var containerName, rawOutput, errors, detailedExecutionInfo sql.NullString
+		var containerName, rawOutput, errors, detailedExecutionInfo, stopReason sql.NullString
 		err := rows.Scan(
 			&result.ID, &result.RunID, &result.TestCaseID, &result.Passed,
 			&result.ActualOutput, &result.ExpectedOutput, &result.Error,
 			&result.InputTokens, &result.OutputTokens, &result.EstimateCost,
 			&result.ResponseTime, &result.ExecutionTime,
-			&containerName, &rawOutput, &errors, &detailedExecutionInfo)
+			&containerName, &rawOutput, &errors, &detailedExecutionInfo, &stopReason)
 		if err != nil {
 			return nil, err
 		}
@@ -640,6 +641,9 @@ func (d *Database) GetEvalResults(runID int64) ([]EvalResult, error) {
 		if detailedExecutionInfo.Valid {
 			result.DetailedExecutionInfo = detailedExecutionInfo.String
 		}
+		if stopReason.Valid {
+			result.StopReason = stopReason.String
+		}
 
 		results = append(results, result)
 	}
@@ -57,6 +57,7 @@ type EvalResult struct {
 	RawOutput             string  `json:"raw_output,omitempty" db:"raw_output"`
 	Errors                string  `json:"errors,omitempty" db:"errors"`
 	DetailedExecutionInfo string  `json:"detailed_execution_info,omitempty" db:"detailed_execution_info"`
+	StopReason            string  `json:"stop_reason,omitempty" db:"stop_reason"` // why the agent stopped, e.g. "completed" or "max_iterations"
 }
 
 // EvalConfig stores configuration
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +17,13 @@ import (
 	"github.com/codefionn/scriptschnell/internal/provider"
 )
 
+// Sampling parameters pinned for every eval run so results are reproducible.
+// The temperature is low but non-zero because most providers treat 0 as unset.
+const (
+	evalTemperature = 0.2
+	evalSeed        = 42
+)
+
 // Service manages the eval system
 type Service struct {
 	db              *Database
@@ -353,6 +361,10 @@ func (s *Service) executeEval(runID int64, evalDef *EvalDefinition, model *EvalM
 	// Enable full JSON output for eval tracking (includes all messages and tool outputs)
 	env["SCRIPTSCHNELL_JSON_FULL"] = "1"
 
+	// Pin sampling parameters for reproducibility
+	env["SCRIPTSCHNELL_TEMPERATURE"] = strconv.FormatFloat(evalTemperature, 'f', -1, 64)
+	env["SCRIPTSCHNELL_SEED"] = strconv.Itoa(evalSeed)
+
 	// Prepare container config
 	config := &ContainerConfig{
 		Image:        imageName,
@@ -391,10 +403,14 @@ func (s *Service) executeEval(runID int64, evalDef *EvalDefinition, model *EvalM
 		// Continue with nil usage - test results will still be created with zero values
 	}
 
+	// Record why the agent stopped (e.g. hitting the iteration limit) with each test result
+	stopReason := s.parseStopReasonFromOutput(result.Output)
+
 	// Run CLI tests
 	allPassed := true
 	for _, testCase := range evalDef.CLITests {
 		testResult := s.runCLITestInContainer(runID, testCase, evalDef, executor, config, responseTime, usage)
+		testResult.StopReason = stopReason
 		if !testResult.Passed {
 			allPassed = false
 		}
@@ -542,6 +558,28 @@ func (s *Service) collectAPIKeys() map[string]string {
 	return env
 }
 
+// parseStopReasonFromOutput extracts the "stop_reason" the CLI adds to all
+// JSON output formats. The last occurrence wins, as it belongs to the final
+// object (or usage line) after any message content. Returns "" if the output
+// has no stop reason.
+func (s *Service) parseStopReasonFromOutput(output string) string {
+	const key = `"stop_reason"`
+	idx := strings.LastIndex(output, key)
+	if idx < 0 {
+		return ""
+	}
+	rest := strings.TrimSpace(output[idx+len(key):])
+	if !strings.HasPrefix(rest, ":") {
+		return ""
+	}
+
+	var reason string
+	if err := json.NewDecoder(strings.NewReader(rest[1:])).Decode(&reason); err != nil {
+		return ""
+	}
+	return reason
+}
+
 // parseUsageFromOutput extracts token usage from container output (JSON format)
 // Handles multiple JSON output formats:
 // - --json: Simple format with message and usage
@@ -0,0 +1,23 @@
+package llm
+
+import "testing"
+
+func TestAnthropicBuildMessageParamsIgnoresSeed(t *testing.T) {
+	client := &AnthropicClient{model: "claude-sonnet-4-5"}
+
+	seed := 7
+	req := &CompletionRequest{
+		Messages:    []*Message{{Role: "user", Content: "hi"}},
+		Temperature: 0.3,
+		MaxTokens:   128,
+		Seed:        &seed,
+	}
+
+	params, err := client.buildMessageParams(req)
+	if err != nil {
+		t.Fatalf("expected unsupported seed to be ignored, got error: %v", err)
+	}
+	if len(params.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(params.Messages))
+	}
+}
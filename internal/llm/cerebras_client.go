@@ -95,7 +95,7 @@ func (c *CerebrasClient) CompleteWithRequest(ctx context.Context, req *Completio
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("cerebras completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("cerebras completion failed", resp, body)
 	}
 
 	var chatResp cerebrasChatResponse
@@ -146,7 +146,7 @@ func (c *CerebrasClient) Stream(ctx context.Context, req *CompletionRequest, cal
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("cerebras stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("cerebras stream failed", resp, body)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
@@ -211,6 +211,11 @@ func (c *CerebrasClient) buildChatRequest(req *CompletionRequest, stream bool) (
 		payload.MaxCompletionTokens = req.MaxTokens
 	}
 
+	if req.Seed != nil {
+		seed := *req.Seed
+		payload.Seed = &seed
+	}
+
 	if req.TopP > 0 {
 		payload.TopP = req.TopP
 	}
@@ -378,6 +383,7 @@ type cerebrasChatRequest struct {
 	ToolChoice          interface{}              `json:"tool_choice,omitempty"`
 	ResponseFormat      map[string]interface{}   `json:"response_format,omitempty"`
 	TopP                float64                  `json:"top_p,omitempty"`
+	Seed                *int                     `json:"seed,omitempty"`
 	Stop                []string                 `json:"stop,omitempty"`
 	User                string                   `json:"user,omitempty"`
 	LogProbs            *bool                    `json:"logprobs,omitempty"`
@@ -35,6 +35,7 @@ type CompletionRequest struct {
 	ClearThinking      *bool                    `json:"clear_thinking,omitempty"`       // Cerebras: preserve reasoning traces (false recommended for agentic workflows)
 	PreviousResponseID string                   `json:"previous_response_id,omitempty"` // For OpenRouter: reference previous response for better prompt caching
 	ReasoningEffort    string                   `json:"reasoning_effort,omitempty"`     // Reasoning effort level: "xhigh", "high", "medium", "low", "minimal", "none"
+	Seed               *int                     `json:"seed,omitempty"`                 // Sampling seed for reproducible output (ignored by providers without seed support)
 }
 
 // CompletionResponse represents a completion response
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// contextFile is a context file with its trimmed content
+type contextFile struct {
+	name    string
+	content string
+}
+
+// FindContextFiles returns the candidate context files existing in
+// workingDir, in candidate order. Candidates are relative to workingDir.
+func FindContextFiles(ctx context.Context, filesystem fs.FileSystem, workingDir string, candidates []string) []string {
+	var found []string
+	seen := make(map[string]bool, len(candidates))
+	for _, name := range candidates {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		exists, err := filesystem.Exists(ctx, contextFilePath(workingDir, name))
+		if err == nil && exists {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// ContextFilesForConfig returns the context files of cfg existing in
+// workingDir that are injected into the system prompt
+func ContextFilesForConfig(ctx context.Context, filesystem fs.FileSystem, workingDir string, cfg *config.Config) []string {
+	found := FindContextFiles(ctx, filesystem, workingDir, cfg.GetContextFiles())
+	if len(found) > 1 && !cfg.ConcatenatesContextFiles() {
+		found = found[:1]
+	}
+	return found
+}
+
+// contextFilePath resolves a context file name against workingDir
+func contextFilePath(workingDir, name string) string {
+	if workingDir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(workingDir, name)
+}
+
+// joinContextFiles concatenates the context files, headed by their names
+// when there is more than one, and cuts the result at maxBytes. Files that
+// did not fit completely are named in a truncation notice.
+func joinContextFiles(files []contextFile, maxBytes int) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for idx, file := range files {
+		section := file.content
+		if len(files) > 1 {
+			section = fmt.Sprintf("### %s\n%s", file.name, file.content)
+		}
+		if idx > 0 {
+			section = "\n\n" + section
+		}
+
+		if b.Len()+len(section) > maxBytes {
+			b.WriteString(truncateAtRune(section, maxBytes-b.Len()))
+
+			var rest []string
+			for _, omitted := range files[idx:] {
+				rest = append(rest, omitted.name)
+			}
+			fmt.Fprintf(&b, "\n[Context files truncated at %d bytes; read %s for the rest]", maxBytes, strings.Join(rest, ", "))
+			logger.Warn("Context files truncated at %d bytes in the system prompt: %s", maxBytes, strings.Join(rest, ", "))
+			break
+		}
+		b.WriteString(section)
+	}
+	return b.String()
+}
+
+// truncateAtRune returns at most maxBytes of s without splitting a rune
+func truncateAtRune(s string, maxBytes int) string {
+	if maxBytes <= 0 {
+		return ""
+	}
+	if len(s) <= maxBytes {
+		return s
+	}
+	for maxBytes > 0 && !utf8.RuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return s[:maxBytes]
+}
@@ -331,6 +331,11 @@ func buildGenAIGenerationConfig(req *CompletionRequest) *genai.GenerateContentCo
 		cfg.MaxOutputTokens = int32(req.MaxTokens)
 	}
 
+	if req.Seed != nil {
+		seed := int32(*req.Seed)
+		cfg.Seed = &seed
+	}
+
 	if len(req.Tools) > 0 {
 		cfg.Tools = convertToolsToGenAI(req.Tools)
 		cfg.ToolConfig = &genai.ToolConfig{
@@ -186,7 +186,7 @@ func (c *GroqClient) completeWithGroqResponses(ctx context.Context, req *Complet
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("groq responses completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		return nil, newHTTPStatusError("groq responses completion failed", resp, bodyBytes)
 	}
 
 	var responsesResp groqResponsesResponse
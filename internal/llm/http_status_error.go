@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// HTTPStatusError is returned when a provider answers with a non-success status.
+// RetryAfter holds the delay requested by a Retry-After header (0 if absent).
+type HTTPStatusError struct {
+	Op         string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: status %d: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// newHTTPStatusError builds an HTTPStatusError from a failed response
+func newHTTPStatusError(op string, resp *http.Response, body []byte) error {
+	return &HTTPStatusError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header value, given either in seconds
+// or as an HTTP date. It returns 0 for empty, invalid or past values.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// RetryAfterFromError returns the delay a provider asked for with a
+// Retry-After header on a 429 or 503 response.
+func RetryAfterFromError(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		if isRetryAfterStatus(statusErr.StatusCode) && statusErr.RetryAfter > 0 {
+			return statusErr.RetryAfter, true
+		}
+		return 0, false
+	}
+
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) && apiErr.Response != nil && isRetryAfterStatus(apiErr.StatusCode) {
+		if delay := ParseRetryAfter(apiErr.Response.Header.Get("Retry-After"), time.Now()); delay > 0 {
+			return delay, true
+		}
+	}
+	return 0, false
+}
+
+func isRetryAfterStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
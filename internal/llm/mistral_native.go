@@ -103,7 +103,7 @@ func (c *MistralClient) CompleteWithRequest(ctx context.Context, req *Completion
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("mistral completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("mistral completion failed", resp, body)
 	}
 
 	var chatResp mistralChatResponse
@@ -157,7 +157,7 @@ func (c *MistralClient) Stream(ctx context.Context, req *CompletionRequest, call
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("mistral stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("mistral stream failed", resp, body)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
@@ -241,6 +241,10 @@ func (c *MistralClient) buildChatRequest(req *CompletionRequest, stream bool) (*
 	if req.MaxTokens > 0 {
 		payload.MaxTokens = req.MaxTokens
 	}
+	if req.Seed != nil {
+		seed := *req.Seed
+		payload.RandomSeed = &seed
+	}
 	if len(req.Tools) > 0 {
 		payload.Tools = convertMistralTools(req.Tools)
 		payload.ParallelToolCall = true
@@ -354,6 +358,7 @@ type mistralChatRequest struct {
 	Messages         []mistralChatMessage `json:"messages"`
 	Temperature      *float64             `json:"temperature,omitempty"`
 	MaxTokens        int                  `json:"max_tokens,omitempty"`
+	RandomSeed       *int                 `json:"random_seed,omitempty"`
 	Tools            []mistralTool        `json:"tools,omitempty"`
 	ParallelToolCall bool                 `json:"parallel_tool_calls,omitempty"`
 	Stream           bool                 `json:"stream,omitempty"`
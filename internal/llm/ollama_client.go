@@ -87,7 +87,7 @@ func (c *OllamaClient) CompleteWithRequest(ctx context.Context, req *CompletionR
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("ollama completion failed", resp, body)
 	}
 
 	var chatResp ollamaChatResponse
@@ -141,7 +141,7 @@ func (c *OllamaClient) Stream(ctx context.Context, req *CompletionRequest, callb
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("ollama stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("ollama stream failed", resp, body)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
@@ -207,6 +207,9 @@ func (c *OllamaClient) buildChatRequest(req *CompletionRequest, stream bool) (*o
 	if req.MaxTokens > 0 {
 		options["num_predict"] = req.MaxTokens
 	}
+	if req.Seed != nil {
+		options["seed"] = *req.Seed
+	}
 	if len(options) == 0 {
 		options = nil
 	}
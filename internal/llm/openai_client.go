@@ -131,7 +131,7 @@ func (c *OpenAIClient) completeWithChat(ctx context.Context, req *CompletionRequ
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("openai completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("openai completion failed", resp, body)
 	}
 
 	var chatResp openAIChatResponse
@@ -184,7 +184,7 @@ func (c *OpenAIClient) streamWithChat(ctx context.Context, req *CompletionReques
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("openai stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("openai stream failed", resp, body)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
@@ -622,6 +622,7 @@ type openAIChatRequest struct {
 	Tools       []map[string]interface{} `json:"tools,omitempty"`
 	Temperature *float64                 `json:"temperature,omitempty"`
 	MaxTokens   int                      `json:"max_tokens,omitempty"`
+	Seed        *int                     `json:"seed,omitempty"`
 	Stream      bool                     `json:"stream,omitempty"`
 }
 
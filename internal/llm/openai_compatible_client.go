@@ -110,7 +110,7 @@ func (c *OpenAICompatibleClient) CompleteWithRequest(ctx context.Context, req *C
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("openai-compatible completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("openai-compatible completion failed", resp, body)
 	}
 
 	// Accumulate streaming response
@@ -211,7 +211,7 @@ func (c *OpenAICompatibleClient) Stream(ctx context.Context, req *CompletionRequ
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("openai-compatible stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("openai-compatible stream failed", resp, body)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
@@ -108,3 +108,28 @@ func TestOpenAICompatibleClient_UsageData_NoUsage(t *testing.T) {
 		t.Errorf("Expected usage data to be nil, got %v", resp.Usage)
 	}
 }
+
+func TestConvertRequestToOpenAI_Seed(t *testing.T) {
+	seed := 7
+	req := &CompletionRequest{
+		Messages: []*Message{{Role: "user", Content: "hi"}},
+		Seed:     &seed,
+	}
+
+	payload, err := convertRequestToOpenAI(req, "gpt-4o", false, false)
+	if err != nil {
+		t.Fatalf("convertRequestToOpenAI failed: %v", err)
+	}
+	if payload.Seed == nil || *payload.Seed != seed {
+		t.Fatalf("expected seed %d in payload, got %v", seed, payload.Seed)
+	}
+
+	req.Seed = nil
+	payload, err = convertRequestToOpenAI(req, "gpt-4o", false, false)
+	if err != nil {
+		t.Fatalf("convertRequestToOpenAI failed: %v", err)
+	}
+	if payload.Seed != nil {
+		t.Fatalf("expected no seed when unset, got %d", *payload.Seed)
+	}
+}
@@ -24,6 +24,11 @@ func convertRequestToOpenAI(req *CompletionRequest, model string, stream bool, e
 		payload.MaxTokens = req.MaxTokens
 	}
 
+	if req.Seed != nil {
+		seed := *req.Seed
+		payload.Seed = &seed
+	}
+
 	if len(req.Tools) > 0 {
 		payload.Tools = req.Tools
 	}
@@ -121,7 +121,7 @@ func (c *OpenRouterClient) doCompletion(ctx context.Context, req *CompletionRequ
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("openrouter completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("openrouter completion failed", resp, body)
 	}
 
 	var chatResp openRouterChatResponse
@@ -200,7 +200,7 @@ func (c *OpenRouterClient) doStream(ctx context.Context, req *CompletionRequest,
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("openrouter stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("openrouter stream failed", resp, body)
 	}
 
 	logger.Debug("OpenRouter: stream connection established, processing chunks")
@@ -302,6 +302,10 @@ func (c *OpenRouterClient) buildChatRequest(req *CompletionRequest, stream bool)
 		payload.MaxTokens = req.MaxTokens
 		logger.Debug("OpenRouter: set max_tokens to %d", req.MaxTokens)
 	}
+	if req.Seed != nil {
+		seed := *req.Seed
+		payload.Seed = &seed
+	}
 	if len(req.Tools) > 0 {
 		payload.Tools = req.Tools
 		logger.Debug("OpenRouter: set %d tools", len(req.Tools))
@@ -646,6 +650,7 @@ type openRouterChatRequest struct {
 	Tools              []map[string]interface{}     `json:"tools,omitempty"`
 	Temperature        *float64                     `json:"temperature,omitempty"`
 	MaxTokens          int                          `json:"max_tokens,omitempty"`
+	Seed               *int                         `json:"seed,omitempty"`
 	Stream             bool                         `json:"stream,omitempty"`
 	PreviousResponseID string                       `json:"previous_response_id,omitempty"` // For prompt caching
 	Reasoning          *openRouterReasoningConfig   `json:"reasoning,omitempty"`
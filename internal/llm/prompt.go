@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/codefionn/scriptschnell/internal/config"
 	"github.com/codefionn/scriptschnell/internal/fs"
@@ -17,6 +18,14 @@ import (
 const AgentsFileName = "AGENTS.md"
 const AgentsLocalFileName = "AGENTS.local.md"
 
+// ProjectMemoryFileName is the per-workspace notes file of the memory tool,
+// relative to the working directory
+const ProjectMemoryFileName = ".scriptschnell/memory.md"
+
+// MaxProjectMemoryPromptBytes caps the project memory injected into the
+// system prompt; older notes beyond it stay available through the memory tool
+const MaxProjectMemoryPromptBytes = 8 * 1024
+
 var defaultToolDescriptions = []string{
 	"read_file: Read files with optional line range support.",
 	"create_file: Create new files with provided content.",
@@ -26,12 +35,14 @@ var defaultToolDescriptions = []string{
 	"todo: Manage todo items for planning and progress tracking.",
 	"read_file_summarized: Summarize large files before deep dives.",
 	"status: Inspect background job progress.",
+	"memory: Read, append and search persistent project notes shared across sessions.",
 }
 
 type systemPromptData struct {
 	WorkingDir       string
 	Files            []string
 	ProjectContext   string
+	ProjectMemory    string
 	ModelSpecific    string
 	Tools            []string
 	IsCLIMode        bool
@@ -85,6 +96,7 @@ func (pb *PromptBuilder) BuildSystemPrompt(ctx context.Context, modelName string
 		WorkingDir:       pb.workingDir,
 		Files:            files,
 		ProjectContext:   pb.projectSpecificContext(ctx),
+		ProjectMemory:    pb.projectMemoryContext(ctx),
 		ModelSpecific:    pb.modelSpecificPrompt(modelName, availableTools),
 		Tools:            tools,
 		IsCLIMode:        cliMode,
@@ -119,23 +131,58 @@ func (pb *PromptBuilder) projectLanguageContext(ctx context.Context) (string, st
 	return bestMatch.Name, bestMatch.Description, nil
 }
 
+// projectSpecificContext returns the context files existing in the working
+// directory, capped at the configured size
 func (pb *PromptBuilder) projectSpecificContext(ctx context.Context) string {
-	agentsPath := filepath.Join(pb.workingDir, AgentsLocalFileName)
-	exists, err := pb.fs.Exists(ctx, agentsPath)
-	if err != nil || !exists {
-		agentsPath = filepath.Join(pb.workingDir, AgentsFileName)
-		exists, err = pb.fs.Exists(ctx, agentsPath)
-		if err != nil || !exists {
-			return ""
+	var files []contextFile
+	for _, name := range ContextFilesForConfig(ctx, pb.fs, pb.workingDir, pb.config) {
+		data, err := pb.fs.ReadFile(ctx, contextFilePath(pb.workingDir, name))
+		if err != nil {
+			continue
+		}
+		if content := strings.TrimSpace(string(data)); content != "" {
+			files = append(files, contextFile{name: name, content: content})
 		}
 	}
 
-	data, err := pb.fs.ReadFile(ctx, agentsPath)
+	return joinContextFiles(files, pb.config.GetContextFilesMaxBytes())
+}
+
+// projectMemoryContext returns the project memory notes for the system
+// prompt. Notes are appended over time, so when the file exceeds
+// MaxProjectMemoryPromptBytes only the most recent notes are kept.
+func (pb *PromptBuilder) projectMemoryContext(ctx context.Context) string {
+	memoryPath := filepath.Join(pb.workingDir, ProjectMemoryFileName)
+	exists, err := pb.fs.Exists(ctx, memoryPath)
+	if err != nil || !exists {
+		return ""
+	}
+
+	data, err := pb.fs.ReadFile(ctx, memoryPath)
 	if err != nil {
 		return ""
 	}
 
-	return strings.TrimSpace(string(data))
+	return limitProjectMemory(strings.TrimSpace(string(data)), MaxProjectMemoryPromptBytes)
+}
+
+// limitProjectMemory keeps the last maxBytes of memory, starting at a line
+// boundary, and notes that older notes were omitted
+func limitProjectMemory(memory string, maxBytes int) string {
+	if len(memory) <= maxBytes {
+		return memory
+	}
+
+	tail := memory[len(memory)-maxBytes:]
+	if idx := strings.Index(tail, "\n"); idx >= 0 {
+		tail = tail[idx+1:]
+	} else {
+		for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+			tail = tail[1:]
+		}
+	}
+
+	return "[Older notes omitted; use the memory tool to read or search all notes]\n" + strings.TrimSpace(tail)
 }
 
 func (pb *PromptBuilder) modelSpecificPrompt(modelName string, availableTools []map[string]interface{}) string {
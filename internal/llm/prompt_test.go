@@ -1,6 +1,15 @@
 package llm
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/fs"
+)
 
 func TestModelSpecificPrompt_MistralFamily(t *testing.T) {
 	pb := &PromptBuilder{}
@@ -13,3 +22,156 @@ func TestModelSpecificPrompt_MistralFamily(t *testing.T) {
 		t.Fatalf("unexpected modelSpecificPrompt for mistral: %q", got)
 	}
 }
+
+func TestBuildSystemPromptIncludesCappedProjectMemory(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	mockFS := fs.NewMockFS()
+
+	var memory strings.Builder
+	memory.WriteString("# Project Memory\n\n## 2024-01-01 10:00\n\nOLDEST-NOTE\n")
+	for i := 0; i < 400; i++ {
+		fmt.Fprintf(&memory, "\n## 2024-01-02 10:00\n\nfiller note %d about the build setup\n", i)
+	}
+	memory.WriteString("\n## 2024-02-01 10:00\n\nNEWEST-NOTE\n")
+	if err := mockFS.WriteFile(ctx, filepath.Join(workingDir, ProjectMemoryFileName), []byte(memory.String())); err != nil {
+		t.Fatalf("failed to write memory: %v", err)
+	}
+
+	prompt, err := NewPromptBuilder(mockFS, workingDir, nil).BuildSystemPrompt(ctx, "gpt-4o", false, nil)
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt failed: %v", err)
+	}
+
+	idx := strings.Index(prompt, "## Project Memory")
+	if idx < 0 {
+		t.Fatal("expected project memory section in system prompt")
+	}
+	if !strings.Contains(prompt, "NEWEST-NOTE") {
+		t.Error("expected most recent note in system prompt")
+	}
+	if strings.Contains(prompt, "OLDEST-NOTE") {
+		t.Error("expected oldest note to be cut from system prompt")
+	}
+	if !strings.Contains(prompt, "Older notes omitted") {
+		t.Error("expected truncation notice in system prompt")
+	}
+
+	section := prompt[idx:]
+	if end := strings.Index(section, "\n## Tooling"); end >= 0 {
+		section = section[:end]
+	}
+	if len(section) > MaxProjectMemoryPromptBytes+512 {
+		t.Errorf("project memory section is %d bytes, expected about %d", len(section), MaxProjectMemoryPromptBytes)
+	}
+}
+
+func TestBuildSystemPromptWithoutProjectMemory(t *testing.T) {
+	prompt, err := NewPromptBuilder(fs.NewMockFS(), t.TempDir(), nil).BuildSystemPrompt(context.Background(), "gpt-4o", false, nil)
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt failed: %v", err)
+	}
+	if strings.Contains(prompt, "## Project Memory") {
+		t.Error("expected no project memory section without a memory file")
+	}
+}
+
+func TestLimitProjectMemory(t *testing.T) {
+	if got := limitProjectMemory("short", 100); got != "short" {
+		t.Errorf("expected short memory unchanged, got %q", got)
+	}
+
+	got := limitProjectMemory("line one\nline two\nline three", 14)
+	if !strings.HasSuffix(got, "\nline three") || strings.Contains(got, "two") {
+		t.Errorf("expected tail starting at a line boundary, got %q", got)
+	}
+}
+
+func TestFindContextFilesKeepsConfiguredOrder(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	mockFS := fs.NewMockFS()
+	for _, name := range []string{"AGENTS.md", "CONTRIBUTING.md", ".cursorrules"} {
+		if err := mockFS.WriteFile(ctx, filepath.Join(workingDir, name), []byte(name)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	got := FindContextFiles(ctx, mockFS, workingDir, []string{".cursorrules", "CLAUDE.md", "AGENTS.md", ".cursorrules"})
+	if want := ".cursorrules,AGENTS.md"; strings.Join(got, ",") != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+
+	got = FindContextFiles(ctx, mockFS, workingDir, (*config.Config)(nil).GetContextFiles())
+	if want := "AGENTS.md"; strings.Join(got, ",") != want {
+		t.Fatalf("expected defaults to find %s, got %v", want, got)
+	}
+}
+
+func TestBuildSystemPromptDefaultLocalContextFileReplacesAgents(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	mockFS := fs.NewMockFS()
+	for name, content := range map[string]string{AgentsFileName: "SHARED-RULES", AgentsLocalFileName: "LOCAL-RULES"} {
+		if err := mockFS.WriteFile(ctx, filepath.Join(workingDir, name), []byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	prompt, err := NewPromptBuilder(mockFS, workingDir, nil).BuildSystemPrompt(ctx, "gpt-4o", false, nil)
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "LOCAL-RULES") || strings.Contains(prompt, "SHARED-RULES") {
+		t.Fatalf("expected %s to replace %s:\n%s", AgentsLocalFileName, AgentsFileName, prompt)
+	}
+}
+
+func TestBuildSystemPromptConcatenatesContextFiles(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	mockFS := fs.NewMockFS()
+	files := map[string]string{
+		"CLAUDE.md":       "CLAUDE-RULES",
+		"CONTRIBUTING.md": "CONTRIBUTING-RULES " + strings.Repeat("x", 200) + " CONTRIBUTING-END",
+	}
+	for name, content := range files {
+		if err := mockFS.WriteFile(ctx, filepath.Join(workingDir, name), []byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg := &config.Config{ContextFiles: []string{"CLAUDE.md", "AGENTS.md", "CONTRIBUTING.md"}, ContextFilesMaxBytes: 100}
+	prompt, err := NewPromptBuilder(mockFS, workingDir, cfg).BuildSystemPrompt(ctx, "gpt-4o", false, nil)
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "CLAUDE-RULES") || strings.Contains(prompt, "CONTRIBUTING-RULES") {
+		t.Fatalf("expected only the first existing file by default:\n%s", prompt)
+	}
+
+	cfg.ContextFilesMode = config.ContextFilesModeAll
+	prompt, err = NewPromptBuilder(mockFS, workingDir, cfg).BuildSystemPrompt(ctx, "gpt-4o", false, nil)
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt failed: %v", err)
+	}
+
+	claude := strings.Index(prompt, "### CLAUDE.md\nCLAUDE-RULES")
+	contributing := strings.Index(prompt, "### CONTRIBUTING.md\nCONTRIBUTING-RULES")
+	if claude < 0 || contributing < 0 || claude > contributing {
+		t.Fatalf("expected both files in configured order:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "CONTRIBUTING-END") {
+		t.Error("expected the context files to be cut at the size cap")
+	}
+	if !strings.Contains(prompt, "[Context files truncated at 100 bytes; read CONTRIBUTING.md for the rest]") {
+		t.Errorf("expected a truncation notice naming the cut file:\n%s", prompt)
+	}
+}
+
+func TestJoinContextFilesSingleFileHasNoHeader(t *testing.T) {
+	got := joinContextFiles([]contextFile{{name: "AGENTS.md", content: "rules"}}, 100)
+	if got != "rules" {
+		t.Fatalf("expected a single file unchanged, got %q", got)
+	}
+}
@@ -3,7 +3,6 @@ package llm
 import (
 	"context"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -12,15 +11,11 @@ const (
 	minTokenEstimate             = 8
 )
 
-// rateLimitedClient wraps another Client and enforces request and token-based throttling.
+// rateLimitedClient wraps another Client and waits on a RateLimiter before each call.
+// Errors carrying a Retry-After delay pause the limiter for every client sharing it.
 type rateLimitedClient struct {
-	delegate     Client
-	interval     time.Duration
-	mu           sync.Mutex
-	nextAllowed  time.Time
-	tokenMu      sync.Mutex
-	nextToken    time.Time
-	tokensPerMin int
+	delegate Client
+	limiter  *RateLimiter
 }
 
 // NewRateLimitedClient returns a Client that throttles calls using QPS and token budgets.
@@ -31,90 +26,30 @@ func NewRateLimitedClient(base Client, interval time.Duration, tokensPerMinute i
 	if interval <= 0 && tokensPerMinute <= 0 {
 		return base
 	}
-	client := &rateLimitedClient{
-		delegate: base,
-		interval: interval,
-	}
-	if tokensPerMinute > 0 {
-		client.tokensPerMin = tokensPerMinute
-	}
-	return client
+	return NewRateLimitedClientWithLimiter(base, NewRateLimiter(interval, 1, tokensPerMinute))
 }
 
-func (c *rateLimitedClient) wait(ctx context.Context, tokens int) error {
-	if err := c.waitInterval(ctx); err != nil {
-		return err
+// NewRateLimitedClientWithLimiter returns a Client that waits on a shared limiter,
+// so all clients of one provider draw from the same budget.
+func NewRateLimitedClientWithLimiter(base Client, limiter *RateLimiter) Client {
+	if base == nil || limiter == nil {
+		return base
 	}
-	if err := c.waitTokens(ctx, tokens); err != nil {
-		return err
+	return &rateLimitedClient{
+		delegate: base,
+		limiter:  limiter,
 	}
-	return nil
 }
 
-func (c *rateLimitedClient) waitInterval(ctx context.Context) error {
-	if c.interval <= 0 {
-		return nil
-	}
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	for {
-		c.mu.Lock()
-		now := time.Now()
-		if c.nextAllowed.IsZero() || !now.Before(c.nextAllowed) {
-			c.nextAllowed = now.Add(c.interval)
-			c.mu.Unlock()
-			return nil
-		}
-
-		wait := time.Until(c.nextAllowed)
-		c.mu.Unlock()
-
-		if wait <= 0 {
-			continue
-		}
-
-		timer := time.NewTimer(wait)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			return ctx.Err()
-		case <-timer.C:
-		}
-	}
+func (c *rateLimitedClient) wait(ctx context.Context, tokens int) error {
+	return c.limiter.Wait(ctx, tokens)
 }
 
-func (c *rateLimitedClient) waitTokens(ctx context.Context, tokens int) error {
-	if c.tokensPerMin <= 0 || tokens <= 0 {
-		return nil
-	}
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	delay := tokensToDuration(tokens, c.tokensPerMin)
-
-	c.tokenMu.Lock()
-	defer c.tokenMu.Unlock()
-
-	start := time.Now()
-	if c.nextToken.Before(start) {
-		c.nextToken = start
-	}
-	waitUntil := c.nextToken
-	c.nextToken = c.nextToken.Add(delay)
-
-	if waitUntil.After(start) {
-		timer := time.NewTimer(waitUntil.Sub(start))
-		defer timer.Stop()
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-timer.C:
-		}
+// observe pauses the limiter when the provider asked to retry later
+func (c *rateLimitedClient) observe(err error) {
+	if delay, ok := RetryAfterFromError(err); ok {
+		c.limiter.Pause(delay)
 	}
-	return nil
 }
 
 func (c *rateLimitedClient) Complete(ctx context.Context, prompt string) (string, error) {
@@ -122,7 +57,9 @@ func (c *rateLimitedClient) Complete(ctx context.Context, prompt string) (string
 	if err := c.wait(ctx, tokens); err != nil {
 		return "", err
 	}
-	return c.delegate.Complete(ctx, prompt)
+	result, err := c.delegate.Complete(ctx, prompt)
+	c.observe(err)
+	return result, err
 }
 
 func (c *rateLimitedClient) CompleteWithRequest(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
@@ -130,7 +67,9 @@ func (c *rateLimitedClient) CompleteWithRequest(ctx context.Context, req *Comple
 	if err := c.wait(ctx, tokens); err != nil {
 		return nil, err
 	}
-	return c.delegate.CompleteWithRequest(ctx, req)
+	resp, err := c.delegate.CompleteWithRequest(ctx, req)
+	c.observe(err)
+	return resp, err
 }
 
 func (c *rateLimitedClient) Stream(ctx context.Context, req *CompletionRequest, callback func(chunk string) error) error {
@@ -138,7 +77,9 @@ func (c *rateLimitedClient) Stream(ctx context.Context, req *CompletionRequest,
 	if err := c.wait(ctx, tokens); err != nil {
 		return err
 	}
-	return c.delegate.Stream(ctx, req, callback)
+	err := c.delegate.Stream(ctx, req, callback)
+	c.observe(err)
+	return err
 }
 
 func (c *rateLimitedClient) GetModelName() string {
@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token-bucket limiter shared by all clients of one provider.
+// Requests are spaced by interval with up to burst requests allowed at once,
+// an optional token budget paces large prompts, and Pause holds every request
+// back, e.g. after the provider answered with Retry-After.
+type RateLimiter struct {
+	mu           sync.Mutex
+	interval     time.Duration
+	burst        int
+	tokensPerMin int
+
+	// tat is the theoretical arrival time of the next request (GCRA)
+	tat         time.Time
+	nextToken   time.Time
+	pausedUntil time.Time
+
+	waiting int
+	granted int64
+	paused  int64
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RateLimiterState is a snapshot of a RateLimiter for diagnostics
+type RateLimiterState struct {
+	Interval        time.Duration `json:"interval"`
+	Burst           int           `json:"burst"`
+	TokensPerMinute int           `json:"tokens_per_minute,omitempty"`
+	NextAllowed     time.Time     `json:"next_allowed"`
+	PausedUntil     time.Time     `json:"paused_until,omitempty"`
+	Waiting         int           `json:"waiting"`
+	Granted         int64         `json:"granted"`
+	Pauses          int64         `json:"pauses"`
+}
+
+// NewRateLimiter creates a limiter allowing one request per interval with the
+// given burst, and tokensPerMinute estimated tokens per minute. Zero values
+// disable the respective limit; the limiter still honors Pause.
+func NewRateLimiter(interval time.Duration, burst, tokensPerMinute int) *RateLimiter {
+	if interval < 0 {
+		interval = 0
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	if tokensPerMinute < 0 {
+		tokensPerMinute = 0
+	}
+	return &RateLimiter{
+		interval:     interval,
+		burst:        burst,
+		tokensPerMin: tokensPerMinute,
+		now:          time.Now,
+		sleep:        sleepContext,
+	}
+}
+
+// Wait blocks until a request estimated at tokens may be sent, or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	if l == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	l.mu.Lock()
+	l.waiting++
+	at := l.reserveLocked(tokens)
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		// A pause issued while waiting delays the reserved slot as well
+		if l.pausedUntil.After(at) {
+			at = l.pausedUntil
+		}
+		delay := at.Sub(l.now())
+		if delay <= 0 {
+			l.granted++
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserveLocked books the earliest slot for a request and returns its time
+func (l *RateLimiter) reserveLocked(tokens int) time.Time {
+	now := l.now()
+	at := now
+
+	if l.interval > 0 {
+		// Up to burst requests may be sent before tat catches up with now
+		earliest := l.tat.Add(-time.Duration(l.burst-1) * l.interval)
+		if earliest.After(at) {
+			at = earliest
+		}
+	}
+	if l.pausedUntil.After(at) {
+		at = l.pausedUntil
+	}
+	if l.tokensPerMin > 0 && l.nextToken.After(at) {
+		at = l.nextToken
+	}
+
+	if l.interval > 0 {
+		if l.tat.Before(at) {
+			l.tat = at
+		}
+		l.tat = l.tat.Add(l.interval)
+	}
+	if l.tokensPerMin > 0 && tokens > 0 {
+		l.nextToken = at.Add(tokensToDuration(tokens, l.tokensPerMin))
+	}
+	return at
+}
+
+// Pause holds back all requests for d, extending an active pause if it ends
+// earlier. Used to honor Retry-After headers.
+func (l *RateLimiter) Pause(d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := l.now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		l.paused++
+	}
+}
+
+// State returns a snapshot of the limiter for diagnostics
+func (l *RateLimiter) State() RateLimiterState {
+	if l == nil {
+		return RateLimiterState{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	next := now
+	if l.interval > 0 {
+		if earliest := l.tat.Add(-time.Duration(l.burst-1) * l.interval); earliest.After(next) {
+			next = earliest
+		}
+	}
+	if l.tokensPerMin > 0 && l.nextToken.After(next) {
+		next = l.nextToken
+	}
+	if l.pausedUntil.After(next) {
+		next = l.pausedUntil
+	}
+
+	state := RateLimiterState{
+		Interval:        l.interval,
+		Burst:           l.burst,
+		TokensPerMinute: l.tokensPerMin,
+		NextAllowed:     next,
+		Waiting:         l.waiting,
+		Granted:         l.granted,
+		Pauses:          l.paused,
+	}
+	if l.pausedUntil.After(now) {
+		state.PausedUntil = l.pausedUntil
+	}
+	return state
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
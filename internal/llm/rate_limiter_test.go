@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock drives a RateLimiter without real sleeping; sleeps advance the clock
+type fakeClock struct {
+	mu      sync.Mutex
+	current time.Time
+	onSleep func(d time.Duration)
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.current = c.current.Add(d)
+	hook := c.onSleep
+	c.mu.Unlock()
+	if hook != nil {
+		hook(d)
+	}
+	return nil
+}
+
+func newFakeClockLimiter(interval time.Duration, burst, tokensPerMinute int) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(interval, burst, tokensPerMinute)
+	limiter.now = clock.now
+	limiter.sleep = clock.sleep
+	return limiter, clock
+}
+
+func TestRateLimiterSpacesRequestsByInterval(t *testing.T) {
+	const interval = time.Second
+	limiter, clock := newFakeClockLimiter(interval, 1, 0)
+	start := clock.now()
+
+	var granted []time.Duration
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background(), 0); err != nil {
+			t.Fatalf("wait %d failed: %v", i, err)
+		}
+		granted = append(granted, clock.now().Sub(start))
+	}
+
+	for i, got := range granted {
+		if want := time.Duration(i) * interval; got != want {
+			t.Fatalf("request %d granted at %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	const interval = time.Second
+	limiter, clock := newFakeClockLimiter(interval, 3, 0)
+	start := clock.now()
+
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), 0); err != nil {
+			t.Fatalf("wait %d failed: %v", i, err)
+		}
+	}
+	if elapsed := clock.now().Sub(start); elapsed != 0 {
+		t.Fatalf("expected burst of 3 without waiting, waited %v", elapsed)
+	}
+
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("wait after burst failed: %v", err)
+	}
+	if elapsed := clock.now().Sub(start); elapsed != interval {
+		t.Fatalf("expected request after burst at %v, got %v", interval, elapsed)
+	}
+}
+
+func TestRateLimiterPacesTokens(t *testing.T) {
+	limiter, clock := newFakeClockLimiter(0, 1, 600) // 10 tokens/sec
+	start := clock.now()
+
+	if err := limiter.Wait(context.Background(), 50); err != nil {
+		t.Fatalf("first wait failed: %v", err)
+	}
+	if err := limiter.Wait(context.Background(), 50); err != nil {
+		t.Fatalf("second wait failed: %v", err)
+	}
+
+	if elapsed := clock.now().Sub(start); elapsed != 5*time.Second {
+		t.Fatalf("expected second request after 5s of token budget, got %v", elapsed)
+	}
+}
+
+func TestRateLimiterPauseExtendsWait(t *testing.T) {
+	const interval = time.Second
+	limiter, clock := newFakeClockLimiter(interval, 1, 0)
+	start := clock.now()
+
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("first wait failed: %v", err)
+	}
+
+	limiter.Pause(5 * time.Second)
+	// A shorter pause must not shorten the active one
+	limiter.Pause(2 * time.Second)
+
+	state := limiter.State()
+	if want := start.Add(5 * time.Second); !state.PausedUntil.Equal(want) {
+		t.Fatalf("expected paused until %v, got %v", want, state.PausedUntil)
+	}
+	if !state.NextAllowed.Equal(state.PausedUntil) {
+		t.Fatalf("expected next allowed to follow the pause, got %v", state.NextAllowed)
+	}
+
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("second wait failed: %v", err)
+	}
+	if elapsed := clock.now().Sub(start); elapsed != 5*time.Second {
+		t.Fatalf("expected second request after the 5s pause, got %v", elapsed)
+	}
+
+	// The interval applies again after the pause
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("third wait failed: %v", err)
+	}
+	if elapsed := clock.now().Sub(start); elapsed != 6*time.Second {
+		t.Fatalf("expected third request one interval after the pause, got %v", elapsed)
+	}
+}
+
+func TestRateLimiterPauseDuringWait(t *testing.T) {
+	limiter, clock := newFakeClockLimiter(time.Second, 1, 0)
+	start := clock.now()
+
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("first wait failed: %v", err)
+	}
+
+	paused := false
+	clock.onSleep = func(time.Duration) {
+		if !paused {
+			paused = true
+			limiter.Pause(10 * time.Second)
+		}
+	}
+
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("second wait failed: %v", err)
+	}
+	// Slept 1s for the interval, then the pause issued at 1s held it until 11s
+	if elapsed := clock.now().Sub(start); elapsed != 11*time.Second {
+		t.Fatalf("expected waiting request to honor the new pause, got %v", elapsed)
+	}
+}
+
+func TestRateLimiterWaitRespectsContext(t *testing.T) {
+	limiter := NewRateLimiter(0, 1, 0)
+	limiter.Pause(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if state := limiter.State(); state.Waiting != 0 || state.Granted != 0 {
+		t.Fatalf("expected no waiting or granted requests, got %+v", state)
+	}
+}
+
+type retryAfterClient struct {
+	fakeClient
+	err error
+}
+
+func (c *retryAfterClient) Complete(ctx context.Context, prompt string) (string, error) {
+	c.recordCall()
+	return "", c.err
+}
+
+func TestRateLimitedClientSharesLimiterAndHonorsRetryAfter(t *testing.T) {
+	limiter, clock := newFakeClockLimiter(time.Second, 1, 0)
+	start := clock.now()
+
+	failing := &retryAfterClient{err: &HTTPStatusError{
+		Op:         "chat completion failed",
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: 30 * time.Second,
+	}}
+	first := NewRateLimitedClientWithLimiter(failing, limiter)
+	second := NewRateLimitedClientWithLimiter(&fakeClient{}, limiter)
+
+	if _, err := first.Complete(context.Background(), "hi"); err == nil {
+		t.Fatalf("expected 429 error from delegate")
+	}
+	if _, err := second.Complete(context.Background(), "hi"); err != nil {
+		t.Fatalf("second client failed: %v", err)
+	}
+
+	if elapsed := clock.now().Sub(start); elapsed != 30*time.Second {
+		t.Fatalf("expected other client to wait for Retry-After, waited %v", elapsed)
+	}
+	if state := limiter.State(); state.Pauses != 1 || state.Granted != 2 {
+		t.Fatalf("unexpected limiter state: %+v", state)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"1.5", 1500 * time.Millisecond},
+		{"-3", 0},
+		{"soon", 0},
+		{now.Add(20 * time.Second).Format(http.TimeFormat), 20 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfterFromErrorIgnoresOtherStatuses(t *testing.T) {
+	err := &HTTPStatusError{StatusCode: http.StatusBadRequest, RetryAfter: time.Second}
+	if _, ok := RetryAfterFromError(err); ok {
+		t.Fatalf("expected Retry-After on 400 to be ignored")
+	}
+	if _, ok := RetryAfterFromError(errors.New("boom")); ok {
+		t.Fatalf("expected plain errors to have no Retry-After")
+	}
+}
@@ -157,6 +157,47 @@ func StripThinkTags(content string) string {
 	return content
 }
 
+// inlineReasoningTags are the tags reasoning models wrap reasoning in when
+// the provider has no separate reasoning field
+var inlineReasoningTags = [][2]string{{"<think>", "</think>"}, {"<thinking>", "</thinking>"}}
+
+// SplitThinkTags separates <think>...</think> and <thinking>...</thinking>
+// blocks at the start of content from the answer. Only leading blocks are
+// taken, so tags quoted later in an answer are kept. It returns the reasoning
+// inside the blocks and the remaining content; reasoning is empty if there
+// were no blocks.
+func SplitThinkTags(content string) (reasoning, rest string) {
+	var blocks []string
+	rest = content
+	for {
+		trimmed := strings.TrimLeft(rest, " \t\r\n")
+		found := false
+		for _, tags := range inlineReasoningTags {
+			if !strings.HasPrefix(trimmed, tags[0]) {
+				continue
+			}
+			end := strings.Index(trimmed, tags[1])
+			if end == -1 {
+				break
+			}
+			if block := strings.TrimSpace(trimmed[len(tags[0]):end]); block != "" {
+				blocks = append(blocks, block)
+			}
+			rest = trimmed[end+len(tags[1]):]
+			found = true
+			break
+		}
+		if !found {
+			break
+		}
+	}
+
+	if rest == content {
+		return "", content
+	}
+	return strings.Join(blocks, "\n\n"), strings.TrimSpace(rest)
+}
+
 // JSONParseError represents an error that occurred while parsing LLM JSON response.
 type JSONParseError struct {
 	Response string
@@ -318,3 +318,54 @@ func TestExtractFromXMLTags(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitThinkTags(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantReasoning string
+		wantRest      string
+	}{
+		{
+			name:          "think block",
+			input:         "<think>check the docs</think>\n\nThe answer is 42.",
+			wantReasoning: "check the docs",
+			wantRest:      "The answer is 42.",
+		},
+		{
+			name:          "several leading blocks",
+			input:         "  <thinking>first</thinking><think>\nsecond\n</think>Done",
+			wantReasoning: "first\n\nsecond",
+			wantRest:      "Done",
+		},
+		{
+			name:     "no blocks",
+			input:    "plain content",
+			wantRest: "plain content",
+		},
+		{
+			name:     "tags later in the answer are kept",
+			input:    "Use <think>...</think> tags",
+			wantRest: "Use <think>...</think> tags",
+		},
+		{
+			name:     "unclosed block",
+			input:    "<think>still thinking",
+			wantRest: "<think>still thinking",
+		},
+		{
+			name:          "only reasoning",
+			input:         "<think>nothing to say</think>",
+			wantReasoning: "nothing to say",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasoning, rest := SplitThinkTags(tt.input)
+			if reasoning != tt.wantReasoning || rest != tt.wantRest {
+				t.Errorf("SplitThinkTags() = (%q, %q), want (%q, %q)", reasoning, rest, tt.wantReasoning, tt.wantRest)
+			}
+		})
+	}
+}
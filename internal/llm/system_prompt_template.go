@@ -138,6 +138,12 @@ When you are assigned a task from a planning board:
 {{ .ProjectContext }}
 {{- end }}
 
+{{- if .ProjectMemory }}
+## Project Memory
+Notes saved with the memory tool in earlier sessions (decisions, gotchas). Append new findings worth keeping with the memory tool.
+{{ .ProjectMemory }}
+{{- end }}
+
 ## Tooling
 {{- range .Tools }}
 - {{ . }}
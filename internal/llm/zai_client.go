@@ -100,7 +100,7 @@ func (c *ZaiClient) CompleteWithRequest(ctx context.Context, req *CompletionRequ
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("zai completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newHTTPStatusError("zai completion failed", resp, body)
 	}
 
 	var chatResp zaiChatResponse
@@ -152,7 +152,7 @@ func (c *ZaiClient) Stream(ctx context.Context, req *CompletionRequest, callback
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("zai stream failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return newHTTPStatusError("zai stream failed", resp, body)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
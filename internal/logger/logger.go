@@ -8,7 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/codefionn/scriptschnell/internal/secretdetect"
 )
 
 // Level represents a logging level
@@ -80,6 +83,35 @@ var (
 	once         sync.Once
 )
 
+// Redactor masks sensitive values (API keys, tokens) before they are written
+type Redactor interface {
+	Redact(text string) string
+}
+
+// redactorHolder wraps the active Redactor so it can be stored atomically
+type redactorHolder struct {
+	r Redactor
+}
+
+var activeRedactor atomic.Pointer[redactorHolder]
+
+func init() {
+	activeRedactor.Store(&redactorHolder{r: secretdetect.NewRedactor("")})
+}
+
+// SetRedactor replaces the redactor applied to every log line (all loggers).
+// By default only the built-in secret patterns are masked; nil disables redaction.
+func SetRedactor(r Redactor) {
+	activeRedactor.Store(&redactorHolder{r: r})
+}
+
+func redact(msg string) string {
+	if holder := activeRedactor.Load(); holder != nil && holder.r != nil {
+		return holder.r.Redact(msg)
+	}
+	return msg
+}
+
 // Init initializes the global logger
 func Init(level Level, logPath string) error {
 	return InitWithConsole(level, logPath, false)
@@ -205,7 +237,7 @@ func (l *Logger) log(level Level, format string, args ...interface{}) {
 	}
 
 	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	msg := fmt.Sprintf(format, args...)
+	msg := redact(fmt.Sprintf(format, args...))
 
 	prefix := l.prefix
 	if prefix != "" {
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/secretdetect"
 )
 
 func TestParseLevel(t *testing.T) {
@@ -220,3 +222,36 @@ func TestGlobalLogger(t *testing.T) {
 	Warn("warn")
 	Error("error")
 }
+
+func TestLoggerRedactsKnownSecrets(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "test.log")
+
+	redactor := secretdetect.NewRedactor("***")
+	redactor.AddKnownSecret("provider-api-key-0123456789")
+	SetRedactor(redactor)
+	defer SetRedactor(secretdetect.NewRedactor(""))
+
+	logger, err := New(LevelDebug, logPath, "")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Debug("request headers: x-api-key=%s Authorization: Bearer %s", "provider-api-key-0123456789", "sess-token")
+	if err := logger.Close(); err != nil {
+		t.Errorf("Failed to close logger: %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	contentStr := string(content)
+	if strings.Contains(contentStr, "provider-api-key-0123456789") || strings.Contains(contentStr, "sess-token") {
+		t.Fatalf("secret leaked into debug log: %q", contentStr)
+	}
+	if !strings.Contains(contentStr, "x-api-key=*** Authorization: Bearer ***") {
+		t.Errorf("expected masked values in log line, got %q", contentStr)
+	}
+}
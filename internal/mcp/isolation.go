@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// serverHealth tracks consecutive failed calls to the tools of one MCP server
+// and disables them for a cooldown once too many calls failed in a row. It
+// outlives the tools, which are rebuilt whenever the tool set changes.
+type serverHealth struct {
+	mu            sync.Mutex
+	server        string
+	failures      int
+	disabledUntil time.Time
+	now           func() time.Time
+}
+
+func newServerHealth(server string) *serverHealth {
+	return &serverHealth{server: server, now: time.Now}
+}
+
+// unavailable returns an error message while the server's tools are disabled
+func (h *serverHealth) unavailable() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.disabledUntil.IsZero() {
+		return ""
+	}
+	remaining := h.disabledUntil.Sub(h.now())
+	if remaining <= 0 {
+		h.disabledUntil = time.Time{}
+		logger.Info("MCP server %q re-enabled after cooldown", h.server)
+		return ""
+	}
+	return fmt.Sprintf("MCP server %q is temporarily disabled after repeated failures; its tools are available again in %s. Continue without this tool or try again later.",
+		h.server, remaining.Round(time.Second))
+}
+
+// record counts the outcome of a call. It returns true if the failure
+// disabled the server.
+func (h *serverHealth) record(failed bool, threshold int, cooldown time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !failed {
+		h.failures = 0
+		return false
+	}
+	h.failures++
+	if threshold <= 0 || h.failures < threshold {
+		return false
+	}
+
+	h.failures = 0
+	h.disabledUntil = h.now().Add(cooldown)
+	logger.Warn("MCP server %q disabled for %s after %d consecutive failures", h.server, cooldown, threshold)
+	return true
+}
+
+// isolatedTool runs the calls of an MCP tool with their own deadline, so a
+// hung server fails only its own call instead of blocking the tool executor
+type isolatedTool struct {
+	tools.Tool
+	health    *serverHealth
+	timeout   time.Duration
+	threshold int
+	cooldown  time.Duration
+}
+
+// Unwrap returns the underlying tool
+func (t *isolatedTool) Unwrap() tools.Tool {
+	return t.Tool
+}
+
+// UnwrapTool returns the tool an MCP tool wraps, or the tool itself
+func UnwrapTool(tool tools.Tool) tools.Tool {
+	if wrapped, ok := tool.(*isolatedTool); ok {
+		return wrapped.Unwrap()
+	}
+	return tool
+}
+
+// Execute runs the wrapped tool in its own goroutine. A call exceeding the
+// timeout is abandoned and reported as a tool error the LLM can recover from.
+func (t *isolatedTool) Execute(ctx context.Context, params map[string]interface{}) *tools.ToolResult {
+	if msg := t.health.unavailable(); msg != "" {
+		return &tools.ToolResult{Error: msg}
+	}
+
+	var (
+		callCtx context.Context
+		cancel  context.CancelFunc
+	)
+	if t.timeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, t.timeout)
+	} else {
+		callCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	done := make(chan *tools.ToolResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- &tools.ToolResult{Error: fmt.Sprintf("MCP tool %s panicked: %v", t.Name(), r)}
+			}
+		}()
+		done <- t.Tool.Execute(callCtx, params)
+	}()
+
+	var result *tools.ToolResult
+	select {
+	case result = <-done:
+		if result == nil {
+			result = &tools.ToolResult{Error: fmt.Sprintf("MCP tool %s returned no result", t.Name())}
+		}
+	case <-callCtx.Done():
+		if ctx.Err() != nil {
+			// Cancelled by the caller, which says nothing about the server
+			return &tools.ToolResult{Error: fmt.Sprintf("MCP tool %s cancelled: %v", t.Name(), ctx.Err())}
+		}
+		result = &tools.ToolResult{Error: fmt.Sprintf("MCP tool %s timed out after %s", t.Name(), t.timeout)}
+	}
+
+	if t.health.record(result.Error != "", t.threshold, t.cooldown) {
+		result.Error = fmt.Sprintf("%s (MCP server %q is now disabled for %s after %d consecutive failures)",
+			result.Error, t.health.server, t.cooldown, t.threshold)
+	}
+	return result
+}
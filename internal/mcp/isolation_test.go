@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// stubTool is an MCP tool that runs a configurable function
+type stubTool struct {
+	calls   int
+	execute func(ctx context.Context) *tools.ToolResult
+}
+
+func (s *stubTool) Name() string                       { return "mcp_stub" }
+func (s *stubTool) Description() string                { return "stub MCP tool" }
+func (s *stubTool) Parameters() map[string]interface{} { return map[string]interface{}{} }
+
+func (s *stubTool) Execute(ctx context.Context, params map[string]interface{}) *tools.ToolResult {
+	s.calls++
+	return s.execute(ctx)
+}
+
+func TestIsolatedToolTimesOutHungCall(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	hung := &stubTool{execute: func(ctx context.Context) *tools.ToolResult {
+		// Ignores its context like a hung server would
+		<-release
+		return &tools.ToolResult{Result: "too late"}
+	}}
+	tool := &isolatedTool{
+		Tool:      hung,
+		health:    newServerHealth("hung"),
+		timeout:   50 * time.Millisecond,
+		threshold: 3,
+		cooldown:  time.Minute,
+	}
+
+	start := time.Now()
+	result := tool.Execute(context.Background(), nil)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the call to be abandoned after the timeout, took %s", elapsed)
+	}
+	if !strings.Contains(result.Error, "timed out") {
+		t.Errorf("expected a timeout error, got %+v", result)
+	}
+}
+
+func TestIsolatedToolDisablesFailingServer(t *testing.T) {
+	failing := &stubTool{execute: func(ctx context.Context) *tools.ToolResult {
+		return &tools.ToolResult{Error: "connection refused"}
+	}}
+	now := time.Now()
+	health := newServerHealth("flaky")
+	health.now = func() time.Time { return now }
+	tool := &isolatedTool{
+		Tool:      failing,
+		health:    health,
+		timeout:   time.Second,
+		threshold: 3,
+		cooldown:  time.Minute,
+	}
+
+	for i := 1; i <= 3; i++ {
+		result := tool.Execute(context.Background(), nil)
+		if !strings.Contains(result.Error, "connection refused") {
+			t.Fatalf("call %d: expected the tool error, got %+v", i, result)
+		}
+		if disabled := strings.Contains(result.Error, "now disabled"); disabled != (i == 3) {
+			t.Errorf("call %d: unexpected disable notice in %q", i, result.Error)
+		}
+	}
+
+	result := tool.Execute(context.Background(), nil)
+	if !strings.Contains(result.Error, "temporarily disabled") {
+		t.Errorf("expected the disabled server to reject calls, got %+v", result)
+	}
+	if failing.calls != 3 {
+		t.Errorf("expected the disabled server not to be called, got %d calls", failing.calls)
+	}
+
+	// After the cooldown the server gets another chance
+	now = now.Add(2 * time.Minute)
+	failing.execute = func(ctx context.Context) *tools.ToolResult {
+		return &tools.ToolResult{Result: "ok"}
+	}
+	if result := tool.Execute(context.Background(), nil); result.Error != "" {
+		t.Errorf("expected the server to be re-enabled after the cooldown, got %+v", result)
+	}
+}
+
+func TestIsolatedToolCallerCancellationIsNoFailure(t *testing.T) {
+	health := newServerHealth("slow")
+	tool := &isolatedTool{
+		Tool: &stubTool{execute: func(ctx context.Context) *tools.ToolResult {
+			<-ctx.Done()
+			return &tools.ToolResult{Error: ctx.Err().Error()}
+		}},
+		health:    health,
+		timeout:   time.Minute,
+		threshold: 1,
+		cooldown:  time.Minute,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := tool.Execute(ctx, nil); !strings.Contains(result.Error, "cancelled") {
+		t.Errorf("expected a cancellation error, got %+v", result)
+	}
+	if msg := health.unavailable(); msg != "" {
+		t.Errorf("expected a cancelled call not to disable the server, got %q", msg)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/codefionn/scriptschnell/internal/config"
@@ -16,12 +18,24 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// ToolSchemaVersion identifies how MCP tool names are derived from server
+// names. It changes whenever generated names change; version 2 gives servers
+// whose sanitized names collide a numeric suffix instead of sharing a prefix.
+const ToolSchemaVersion = 2
+
 // Manager converts MCP server definitions into executable tools.
 type Manager struct {
 	cfg         *config.Config
 	workingDir  string
 	httpClient  *http.Client
 	providerMgr *provider.Manager
+
+	healthMu sync.Mutex
+	health   map[string]*serverHealth // per server, kept across BuildTools calls
+
+	namesMu     sync.RWMutex
+	serverKeys  map[string]string // server name -> sanitized key used as tool prefix
+	toolServers map[string]string // tool name -> server name, from the last BuildTools
 }
 
 // NewManager creates a new MCP manager.
@@ -34,6 +48,12 @@ func NewManager(cfg *config.Config, workingDir string, providerMgr *provider.Man
 	}
 }
 
+// Close releases the connections held for the OpenAPI servers. Tools built
+// by the manager must not be used afterwards.
+func (m *Manager) Close() {
+	m.httpClient.CloseIdleConnections()
+}
+
 // BuildTools materializes configured MCP servers into tool implementations.
 // Returns created tools and any errors that occurred while building individual servers.
 func (m *Manager) BuildTools() ([]tools.Tool, []error) {
@@ -42,48 +62,188 @@ func (m *Manager) BuildTools() ([]tools.Tool, []error) {
 	}
 
 	var (
-		result    []tools.Tool
-		errs      []error
-		nameUsage = make(map[string]int)
+		result      []tools.Tool
+		nameUsage   = make(map[string]int)
+		toolServers = make(map[string]string)
+		serverNames []string
 	)
 
 	for serverName, serverCfg := range m.cfg.MCP.Servers {
-		if serverCfg == nil {
-			continue
-		}
-		if serverCfg.Disabled {
+		if serverCfg == nil || serverCfg.Disabled {
 			continue
 		}
+		serverNames = append(serverNames, serverName)
+	}
+	serverKeys, errs := assignServerKeys(serverNames)
 
-		toolsForServer, err := m.buildServerTools(serverName, serverCfg, nameUsage)
+	for _, serverName := range serverNames {
+		toolsForServer, err := m.buildServerTools(serverName, serverKeys[serverName], m.cfg.MCP.Servers[serverName], nameUsage)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("%s: %w", serverName, err))
 			continue
 		}
-		result = append(result, toolsForServer...)
+		for _, tool := range toolsForServer {
+			toolServers[tool.Name()] = serverName
+		}
+		result = append(result, m.isolateTools(serverName, toolsForServer)...)
 	}
 
+	m.namesMu.Lock()
+	m.serverKeys = serverKeys
+	m.toolServers = toolServers
+	m.namesMu.Unlock()
+
 	return result, errs
 }
 
-func (m *Manager) buildServerTools(serverName string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
+// ServerForTool returns the name of the server that provides a tool built by
+// the last BuildTools call, or "" if the tool is unknown
+func (m *Manager) ServerForTool(toolName string) string {
+	if m == nil {
+		return ""
+	}
+	m.namesMu.RLock()
+	defer m.namesMu.RUnlock()
+	return m.toolServers[toolName]
+}
+
+// ServerKey returns the sanitized key a server's tool names are prefixed
+// with (mcp_<key>), or "" if the server was not part of the last BuildTools
+func (m *Manager) ServerKey(serverName string) string {
+	if m == nil {
+		return ""
+	}
+	m.namesMu.RLock()
+	defer m.namesMu.RUnlock()
+	return m.serverKeys[serverName]
+}
+
+// ServerByKey returns the name of the server with a sanitized key, or "" if
+// no server of the last BuildTools has it
+func (m *Manager) ServerByKey(key string) string {
+	if m == nil {
+		return ""
+	}
+	m.namesMu.RLock()
+	defer m.namesMu.RUnlock()
+	for name, serverKey := range m.serverKeys {
+		if serverKey == key {
+			return name
+		}
+	}
+	return ""
+}
+
+// assignServerKeys sanitizes the server names into unique tool prefixes.
+// Servers whose names sanitize identically (e.g. "my-server" and
+// "my_server") get a numeric suffix in name order, so neither shadows the
+// other; every collision is reported as an error. names is sorted in place.
+func assignServerKeys(names []string) (map[string]string, []error) {
+	sort.Strings(names)
+
+	groups := make(map[string][]string)
+	var order []string
+	for _, name := range names {
+		key := sanitizeName(name)
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+
+	// The first server of every group keeps the plain key, so suffixed keys
+	// must not take the plain key of another server (e.g. "my_server_2")
+	used := make(map[string]bool, len(order))
+	for _, key := range order {
+		used[key] = true
+	}
+
+	keys := make(map[string]string, len(names))
+	var errs []error
+	for _, key := range order {
+		servers := groups[key]
+		keys[servers[0]] = key
+		if len(servers) == 1 {
+			continue
+		}
+
+		renamed := make([]string, 0, len(servers)-1)
+		suffix := 2
+		for _, server := range servers[1:] {
+			candidate := fmt.Sprintf("%s_%d", key, suffix)
+			for used[candidate] {
+				suffix++
+				candidate = fmt.Sprintf("%s_%d", key, suffix)
+			}
+			suffix++
+			used[candidate] = true
+			keys[server] = candidate
+			renamed = append(renamed, fmt.Sprintf("%q uses mcp_%s", server, candidate))
+		}
+		errs = append(errs, fmt.Errorf("MCP servers %s all sanitize to %q; %q keeps mcp_%s, %s",
+			quoteJoin(servers), key, servers[0], key, strings.Join(renamed, ", ")))
+	}
+
+	return keys, errs
+}
+
+func quoteJoin(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// isolateTools wraps the tools of a server so every call runs with the MCP
+// tool timeout and counts towards the server's consecutive failures
+func (m *Manager) isolateTools(serverName string, serverTools []tools.Tool) []tools.Tool {
+	health := m.serverHealth(serverName)
+	isolated := make([]tools.Tool, len(serverTools))
+	for i, tool := range serverTools {
+		isolated[i] = &isolatedTool{
+			Tool:      tool,
+			health:    health,
+			timeout:   m.cfg.MCP.GetToolTimeout(),
+			threshold: m.cfg.MCP.GetFailureThreshold(),
+			cooldown:  m.cfg.MCP.GetCooldown(),
+		}
+	}
+	return isolated
+}
+
+func (m *Manager) serverHealth(serverName string) *serverHealth {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if m.health == nil {
+		m.health = make(map[string]*serverHealth)
+	}
+	health, ok := m.health[serverName]
+	if !ok {
+		health = newServerHealth(serverName)
+		m.health[serverName] = health
+	}
+	return health
+}
+
+func (m *Manager) buildServerTools(serverName, serverKey string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
 	if serverCfg == nil {
 		return nil, fmt.Errorf("empty server configuration")
 	}
 
 	switch strings.ToLower(serverCfg.Type) {
 	case "command":
-		return m.buildCommandTools(serverName, serverCfg, nameUsage)
+		return m.buildCommandTools(serverName, serverKey, serverCfg, nameUsage)
 	case "openapi":
-		return m.buildOpenAPITools(serverName, serverCfg, nameUsage)
+		return m.buildOpenAPITools(serverName, serverKey, serverCfg, nameUsage)
 	case "openai":
-		return m.buildOpenAITools(serverName, serverCfg, nameUsage)
+		return m.buildOpenAITools(serverName, serverKey, serverCfg, nameUsage)
 	default:
 		return nil, fmt.Errorf("unsupported MCP server type: %s", serverCfg.Type)
 	}
 }
 
-func (m *Manager) buildCommandTools(serverName string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
+func (m *Manager) buildCommandTools(serverName, serverKey string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
 	cmdCfg := serverCfg.Command
 	if cmdCfg == nil {
 		return nil, fmt.Errorf("command configuration missing")
@@ -97,7 +257,7 @@ func (m *Manager) buildCommandTools(serverName string, serverCfg *config.MCPServ
 		timeout = consts.Timeout60
 	}
 
-	name := uniqueToolName(fmt.Sprintf("mcp_%s", sanitizeName(serverName)), nameUsage)
+	name := uniqueToolName(fmt.Sprintf("mcp_%s", serverKey), nameUsage)
 	description := serverCfg.Description
 	if description == "" {
 		description = fmt.Sprintf("Execute %s via MCP command server", strings.Join(cmdCfg.Exec, " "))
@@ -115,13 +275,13 @@ func (m *Manager) buildCommandTools(serverName string, serverCfg *config.MCPServ
 	return []tools.Tool{tool}, nil
 }
 
-func (m *Manager) buildOpenAITools(serverName string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
+func (m *Manager) buildOpenAITools(serverName, serverKey string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
 	openAICfg := serverCfg.OpenAI
 	if openAICfg == nil {
 		return nil, fmt.Errorf("openai configuration missing")
 	}
 
-	name := uniqueToolName(fmt.Sprintf("mcp_%s", sanitizeName(serverName)), nameUsage)
+	name := uniqueToolName(fmt.Sprintf("mcp_%s", serverKey), nameUsage)
 	modelID := strings.TrimSpace(openAICfg.Model)
 	if modelID == "" {
 		if m.providerMgr != nil {
@@ -154,7 +314,7 @@ func (m *Manager) buildOpenAITools(serverName string, serverCfg *config.MCPServe
 	return []tools.Tool{tool}, nil
 }
 
-func (m *Manager) buildOpenAPITools(serverName string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
+func (m *Manager) buildOpenAPITools(serverName, serverKey string, serverCfg *config.MCPServerConfig, nameUsage map[string]int) ([]tools.Tool, error) {
 	apiCfg := serverCfg.OpenAPI
 	if apiCfg == nil {
 		return nil, fmt.Errorf("openapi configuration missing")
@@ -208,7 +368,7 @@ func (m *Manager) buildOpenAPITools(serverName string, serverCfg *config.MCPServ
 			parameters := collectParameters(pathItem.Parameters, operation.Parameters)
 			requestBody := collectRequestBody(operation.RequestBody)
 
-			toolNameBase := fmt.Sprintf("mcp_%s_%s", sanitizeName(serverName), sanitizeName(detectOperationName(operation, method, path)))
+			toolNameBase := fmt.Sprintf("mcp_%s_%s", serverKey, sanitizeName(detectOperationName(operation, method, path)))
 			toolName := uniqueToolName(toolNameBase, nameUsage)
 
 			description := operation.Summary
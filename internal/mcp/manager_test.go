@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func commandServer(output string) *config.MCPServerConfig {
+	return &config.MCPServerConfig{
+		Type:    "command",
+		Command: &config.MCPCommandConfig{Exec: []string{"echo", output}},
+	}
+}
+
+func TestBuildToolsDisambiguatesCollidingServerNames(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MCP.Servers = map[string]*config.MCPServerConfig{
+		"my_server": commandServer("underscore"),
+		"my-server": commandServer("dash"),
+		"other":     commandServer("other"),
+	}
+	manager := NewManager(cfg, t.TempDir(), nil)
+
+	built, errs := manager.BuildTools()
+	if len(errs) != 1 {
+		t.Fatalf("expected one collision error, got %v", errs)
+	}
+	for _, server := range []string{`"my-server"`, `"my_server"`, "mcp_my_server_2"} {
+		if !strings.Contains(errs[0].Error(), server) {
+			t.Errorf("expected the error to mention %s, got %v", server, errs[0])
+		}
+	}
+
+	// Servers are keyed in name order, so the names are stable across builds
+	want := map[string]string{
+		"mcp_my_server":   "dash",
+		"mcp_my_server_2": "underscore",
+		"mcp_other":       "other",
+	}
+	if len(built) != len(want) {
+		t.Fatalf("expected %d tools, got %d", len(want), len(built))
+	}
+	for _, tool := range built {
+		output, ok := want[tool.Name()]
+		if !ok {
+			t.Fatalf("unexpected tool %s", tool.Name())
+		}
+		result := tool.Execute(context.Background(), map[string]interface{}{})
+		if result.Error != "" {
+			t.Fatalf("%s failed: %s", tool.Name(), result.Error)
+		}
+		if got := result.Result.(map[string]interface{})["stdout"]; !strings.Contains(got.(string), output) {
+			t.Errorf("expected %s to run its own server, got %v", tool.Name(), got)
+		}
+	}
+
+	if server := manager.ServerForTool("mcp_my_server_2"); server != "my_server" {
+		t.Errorf("expected mcp_my_server_2 to belong to my_server, got %q", server)
+	}
+	if key := manager.ServerKey("my-server"); key != "my_server" {
+		t.Errorf("expected my-server to keep the plain key, got %q", key)
+	}
+	if server := manager.ServerByKey("my_server_2"); server != "my_server" {
+		t.Errorf("expected key my_server_2 to resolve to my_server, got %q", server)
+	}
+}
+
+func TestAssignServerKeysSkipsTakenSuffixes(t *testing.T) {
+	keys, errs := assignServerKeys([]string{"My Server", "my_server_2", "my-server", "my.server"})
+	if len(errs) != 1 {
+		t.Fatalf("expected one collision error, got %v", errs)
+	}
+
+	want := map[string]string{
+		"My Server":   "my_server",
+		"my-server":   "my_server_3",
+		"my.server":   "my_server_4",
+		"my_server_2": "my_server_2",
+	}
+	for server, key := range want {
+		if keys[server] != key {
+			t.Errorf("%s: expected key %q, got %q", server, key, keys[server])
+		}
+	}
+}
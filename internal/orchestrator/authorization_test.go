@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// authorizingExecFn asks for authorization unless approved and records the
+// commands that ran
+func authorizingExecFn(ran *[]string, suggestedPrefix string) toolExecutionFunc {
+	return func(ctx context.Context, call *tools.ToolCall, toolName string, progressCb progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
+		command, _ := call.Parameters["command"].(string)
+		if !approved {
+			return &tools.ToolResult{
+				ID:                     call.ID,
+				RequiresUserInput:      true,
+				AuthReason:             "command modifies the repository",
+				SuggestedCommandPrefix: suggestedPrefix,
+			}, nil
+		}
+		*ran = append(*ran, command)
+		return &tools.ToolResult{ID: call.ID, Result: "ran: " + command}, nil
+	}
+}
+
+func TestProcessToolCallsRunsEditedParameters(t *testing.T) {
+	// Approved prefixes are saved to the user's config
+	t.Setenv("HOME", t.TempDir())
+
+	var ran []string
+	var asked []string
+	authCb := func(toolName string, params map[string]interface{}, reason string) (AuthorizationResponse, error) {
+		command, _ := params["command"].(string)
+		asked = append(asked, command)
+		return AuthorizationResponse{
+			Approved:     true,
+			EditedParams: map[string]interface{}{"command": command + " --dry-run"},
+		}, nil
+	}
+
+	calls := []map[string]interface{}{
+		{"id": "call-1", "type": "function", "function": map[string]interface{}{"name": "shell", "arguments": `{"command":"git clean -fd"}`}},
+	}
+	sess := session.NewSession("test", ".")
+	sess.AddMessage(&session.Message{Role: "assistant", ToolCalls: calls})
+
+	orch := &Orchestrator{config: &config.Config{}}
+	if err := orch.processToolCalls(context.Background(), calls, sess, nil, authCb, nil, nil, authorizingExecFn(&ran, "git clean")); err != nil {
+		t.Fatalf("processToolCalls returned error: %v", err)
+	}
+
+	if len(asked) != 1 || asked[0] != "git clean -fd" {
+		t.Errorf("expected the original command to be authorized, got %v", asked)
+	}
+	if len(ran) != 1 || ran[0] != "git clean -fd --dry-run" {
+		t.Fatalf("expected only the edited command to run, got %v", ran)
+	}
+
+	messages := sess.GetMessages()
+	if got := messages[len(messages)-1].Content; got != "ran: git clean -fd --dry-run" {
+		t.Errorf("expected the result of the edited command, got %q", got)
+	}
+	// The model sees the call that actually ran
+	fn := messages[0].ToolCalls[0]["function"].(map[string]interface{})
+	if args, _ := fn["arguments"].(string); !strings.Contains(args, "--dry-run") {
+		t.Errorf("expected the tool call in history to carry the edited parameters, got %s", args)
+	}
+	// An edited approval is not remembered for the original command
+	if sess.IsCommandAuthorized("git clean -fd") {
+		t.Error("expected the suggested prefix not to be authorized after an edit")
+	}
+}
+
+func TestProcessToolCallsRunsUneditedParametersOnPlainApproval(t *testing.T) {
+	var ran []string
+	authCb := ApprovalOnly(func(toolName string, params map[string]interface{}, reason string) (bool, error) {
+		return true, nil
+	})
+
+	calls := []map[string]interface{}{
+		{"id": "call-1", "type": "function", "function": map[string]interface{}{"name": "shell", "arguments": `{"command":"git clean -fd"}`}},
+	}
+	sess := session.NewSession("test", ".")
+	orch := &Orchestrator{config: &config.Config{}}
+	if err := orch.processToolCalls(context.Background(), calls, sess, nil, authCb, nil, nil, authorizingExecFn(&ran, "")); err != nil {
+		t.Fatalf("processToolCalls returned error: %v", err)
+	}
+
+	if len(ran) != 1 || ran[0] != "git clean -fd" {
+		t.Errorf("expected the requested command to run, got %v", ran)
+	}
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Causes of a cancelled generation. They are set with StopWithCause (or
+// context.WithCancelCause by callers) and matched by the error the prompt
+// returns, which still matches context.Canceled.
+var (
+	// ErrCancelledByUser means the user stopped the generation
+	ErrCancelledByUser = errors.New("stopped by user")
+	// ErrCancelledByShutdown means the orchestrator or its frontend shut down
+	ErrCancelledByShutdown = errors.New("shutting down")
+	// ErrCancelledByNewPrompt means a newer prompt of the session replaced it
+	ErrCancelledByNewPrompt = errors.New("replaced by a newer prompt")
+)
+
+// cancelCauses are the causes CancelCause reports
+var cancelCauses = []error{
+	ErrCancelledByUser,
+	ErrCancelledByShutdown,
+	ErrCancelledByNewPrompt,
+}
+
+// CancelCause returns why a prompt was cancelled, judging by the error it
+// returned, or nil if it wasn't cancelled for a known reason. A timeout is
+// not a cancellation, see IsTimeout.
+func CancelCause(err error) error {
+	for _, cause := range cancelCauses {
+		if errors.Is(err, cause) {
+			return cause
+		}
+	}
+	return nil
+}
+
+// IsTimeout reports whether a prompt failed because a deadline passed, such
+// as the timeout of a caller's context or of a provider request, rather than
+// being cancelled
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && CancelCause(err) == nil
+}
+
+// withCancelCause annotates the error of a cancelled prompt with the cause
+// of ctx, so callers can tell a user stop from a shutdown or a timeout
+func withCancelCause(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || errors.Is(err, cause) {
+		return err
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", err, cause)
+}
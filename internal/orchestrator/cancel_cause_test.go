@@ -0,0 +1,148 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// waitForCancellation blocks like a prompt waiting on the model until the
+// combined context is done and returns the annotated error
+func waitForCancellation(ctx context.Context, o *Orchestrator) error {
+	combined, cancel := combineContexts(ctx, o.ctx)
+	defer cancel()
+
+	<-combined.Done()
+	return withCancelCause(combined, combined.Err())
+}
+
+func newCancellableOrchestrator() *Orchestrator {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Orchestrator{ctx: ctx, cancel: cancel}
+}
+
+func TestCancelCauseAfterStop(t *testing.T) {
+	o := newCancellableOrchestrator()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- waitForCancellation(context.Background(), o) }()
+
+	time.Sleep(10 * time.Millisecond)
+	o.Stop()
+
+	err := <-errCh
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error to match context.Canceled, got %v", err)
+	}
+	if cause := CancelCause(err); cause != ErrCancelledByUser {
+		t.Fatalf("expected cause %v, got %v", ErrCancelledByUser, cause)
+	}
+
+	if o.ctx.Err() != nil {
+		t.Fatal("expected a fresh context after Stop")
+	}
+}
+
+func TestCancelCauseAfterClose(t *testing.T) {
+	o := newCancellableOrchestrator()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- waitForCancellation(context.Background(), o) }()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := o.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if cause := CancelCause(<-errCh); cause != ErrCancelledByShutdown {
+		t.Fatalf("expected cause %v, got %v", ErrCancelledByShutdown, cause)
+	}
+}
+
+func TestCancelCauseFromCallerContext(t *testing.T) {
+	o := newCancellableOrchestrator()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- waitForCancellation(ctx, o) }()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel(ErrCancelledByNewPrompt)
+
+	if cause := CancelCause(<-errCh); cause != ErrCancelledByNewPrompt {
+		t.Fatalf("expected cause %v, got %v", ErrCancelledByNewPrompt, cause)
+	}
+}
+
+func TestCancelCauseAfterDeadline(t *testing.T) {
+	o := newCancellableOrchestrator()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := waitForCancellation(ctx, o)
+	if cause := CancelCause(err); cause != nil {
+		t.Fatalf("expected a timeout not to count as cancellation, got cause %v", cause)
+	}
+	if !IsTimeout(err) {
+		t.Fatalf("expected %v to be a timeout", err)
+	}
+}
+
+func TestIsTimeoutDistinguishesCancellation(t *testing.T) {
+	// A provider request timing out inside a running prompt
+	if !IsTimeout(fmt.Errorf("request failed: %w", context.DeadlineExceeded)) {
+		t.Error("expected a wrapped deadline error to be a timeout")
+	}
+	if IsTimeout(fmt.Errorf("%w: %w", context.Canceled, ErrCancelledByUser)) {
+		t.Error("expected a user stop not to be a timeout")
+	}
+	// A user stop that interrupted a request reporting its deadline
+	if IsTimeout(fmt.Errorf("%w: %w", context.DeadlineExceeded, ErrCancelledByUser)) {
+		t.Error("expected a cancel cause to take precedence over the deadline")
+	}
+	if IsTimeout(errors.New("provider failed")) {
+		t.Error("expected other errors not to be timeouts")
+	}
+}
+
+func TestCancelCauseIgnoresOtherErrors(t *testing.T) {
+	if cause := CancelCause(errors.New("provider failed")); cause != nil {
+		t.Fatalf("expected no cause, got %v", cause)
+	}
+	if cause := CancelCause(context.Canceled); cause != nil {
+		t.Fatalf("expected no cause for a plain cancellation, got %v", cause)
+	}
+
+	// Errors of prompts that were not cancelled are passed through
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrCancelledByUser)
+	providerErr := errors.New("provider failed")
+	if err := withCancelCause(ctx, providerErr); err != providerErr {
+		t.Fatalf("expected error to be unchanged, got %v", err)
+	}
+}
+
+func TestPromptResultRecordsCancelCause(t *testing.T) {
+	o := newCancellableOrchestrator()
+	o.session = session.NewSession("test", ".")
+	tracker := &promptResultTracker{orch: o}
+
+	err := withCancelCause(func() context.Context {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(ErrCancelledByUser)
+		return ctx
+	}(), context.Canceled)
+
+	result := tracker.finish(nil, err)
+	if result.Reason != StopReasonCancelled {
+		t.Fatalf("expected reason %q, got %q", StopReasonCancelled, result.Reason)
+	}
+	if result.Cause != ErrCancelledByUser.Error() {
+		t.Fatalf("expected cause %q, got %q", ErrCancelledByUser.Error(), result.Cause)
+	}
+}
@@ -226,6 +226,7 @@ func (a *CodebaseInvestigatorAgent) investigateInternal(ctx context.Context, obj
 
 	// Create limited registry (with authorizer to enforce network/domain rules)
 	registry := tools.NewRegistryWithSecrets(a.orch.authorizer, secretdetect.NewDetector())
+	registry.SetDryRun(a.orch.dryRun())
 
 	// Register tools
 	modelFamily := llm.DetectModelFamily(a.orch.getSummarizeModelID())
@@ -683,7 +684,7 @@ func (a *CodebaseInvestigatorAgent) getInvestigationContextWindow() int {
 		return 0
 	}
 
-	modelID := a.orch.providerMgr.GetOrchestrationModel()
+	modelID := a.orch.orchestrationModelID()
 	if modelID == "" {
 		return 0
 	}
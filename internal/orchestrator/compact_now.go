@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+var (
+	// ErrCompactionBusy is returned by CompactNow while a prompt is being
+	// processed or another compaction is running
+	ErrCompactionBusy = errors.New("cannot compact while a prompt is being processed")
+	// ErrNothingToCompact is returned by CompactNow if the session has too
+	// little history outside the recent exchanges
+	ErrNothingToCompact = errors.New("not enough earlier context to compact")
+)
+
+// CompactionResult describes a compaction requested with CompactNow
+type CompactionResult struct {
+	CompactedMessages int // Messages replaced by the summary
+	FreedPercent      int // Share of the context freed
+}
+
+// CompactNow summarizes the earlier context of the session right away instead
+// of waiting for the context window to fill up. Pinned messages and the most
+// recent exchanges are kept, as for automatic compaction.
+func (o *Orchestrator) CompactNow(ctx context.Context, progressCallback progress.Callback, contextCallback ContextUsageCallback) (*CompactionResult, error) {
+	// ProcessPrompt registers itself under compactionMu, so checking for
+	// active prompts and claiming the compaction cannot race with it
+	o.compactionMu.Lock()
+	if o.activePrompts.Load() > 0 || o.compactionInProgress {
+		o.compactionMu.Unlock()
+		return nil, ErrCompactionBusy
+	}
+	o.compactionInProgress = true
+	o.compactionMu.Unlock()
+
+	// compactContextWithAttempt clears the flag once it ran
+	started := false
+	defer func() {
+		if !started {
+			o.compactionMu.Lock()
+			o.compactionInProgress = false
+			o.compactionMu.Unlock()
+		}
+	}()
+
+	if o.session == nil {
+		return nil, fmt.Errorf("no active session")
+	}
+
+	minMessages, preserveRecent := o.compactionFloors()
+	sessionMessages := o.session.GetMessages()
+	if len(sessionMessages) < minMessages {
+		return nil, ErrNothingToCompact
+	}
+
+	prefixCount := clampCompactionPrefix(sessionMessages, len(sessionMessages), preserveRecent)
+	messages := append([]*session.Message(nil), sessionMessages[:prefixCount]...)
+	summarized := len(unpinnedMessages(messages))
+	if summarized == 0 {
+		return nil, ErrNothingToCompact
+	}
+
+	modelID := o.orchestrationModelID()
+	systemPrompt, err := o.getOrBuildSystemPrompt(ctx, modelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build system prompt: %w", err)
+	}
+
+	started = true
+	freed, compacted := o.compactContextWithAttempt(ctx, modelID, systemPrompt, contextCallback, messages, progressCallback, 1)
+	if !compacted {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("compaction failed")
+	}
+	return &CompactionResult{CompactedMessages: summarized, FreedPercent: freed}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/codefionn/scriptschnell/internal/config"
 	"github.com/codefionn/scriptschnell/internal/session"
 )
 
@@ -16,7 +17,7 @@ func TestBuildUserCompactionSection_UnifyUnderThreshold(t *testing.T) {
 
 	perMessageTokens := []int{50, 10, 10}
 
-	section := buildUserCompactionSection(messages, perMessageTokens, 2000, "Latest detailed instruction")
+	section := buildUserCompactionSection(messages, perMessageTokens, 2000, "Latest detailed instruction", nil)
 
 	if !strings.Contains(section, "unified verbatim") {
 		t.Fatalf("expected unified verbatim section, got: %s", section)
@@ -40,7 +41,7 @@ func TestBuildUserCompactionSection_CondenseOverThreshold(t *testing.T) {
 
 	perMessageTokens := []int{800, 20, 600}
 
-	section := buildUserCompactionSection(messages, perMessageTokens, 1000, "Ship it")
+	section := buildUserCompactionSection(messages, perMessageTokens, 1000, "Ship it", nil)
 
 	if !strings.Contains(section, "condensed summary") {
 		t.Fatalf("expected condensed summary section, got: %s", section)
@@ -55,6 +56,47 @@ func TestBuildUserCompactionSection_CondenseOverThreshold(t *testing.T) {
 	}
 }
 
+func TestBuildUserCompactionSection_VerbatimThresholdConfigurable(t *testing.T) {
+	messages := []*session.Message{
+		{Role: "user", Content: "First request " + strings.Repeat("with details ", 40)},
+		{Role: "user", Content: "Second request"},
+	}
+	perMessageTokens := []int{30, 10}
+
+	// 40 of 1000 tokens is below the default threshold of 5%
+	section := buildUserCompactionSection(messages, perMessageTokens, 1000, "", nil)
+	if !strings.Contains(section, "unified verbatim") {
+		t.Fatalf("expected verbatim prompts with the default threshold, got: %s", section)
+	}
+
+	section = buildUserCompactionSection(messages, perMessageTokens, 1000, "", &config.CompactionConfig{UserPromptVerbatimPercent: 2})
+	if !strings.Contains(section, "(>=2% of context) condensed summary") {
+		t.Fatalf("expected a lower threshold to condense older prompts, got: %s", section)
+	}
+	if strings.Contains(section, messages[0].Content) {
+		t.Errorf("expected the long prompt to be condensed, got: %s", section)
+	}
+}
+
+func TestCompactUserPrompts_CondenseLimitConfigurable(t *testing.T) {
+	prompt := strings.Repeat("word ", 200)
+
+	short := compactUserPrompts([]string{prompt}, nil)
+	long := compactUserPrompts([]string{prompt}, &config.CompactionConfig{UserPromptChars: 800})
+	if len(long) <= len(short) {
+		t.Errorf("expected a higher limit to keep more characters, got %d <= %d", len(long), len(short))
+	}
+	if len(long) > 800 {
+		t.Errorf("expected the condensed prompt to respect the limit, got %d characters", len(long))
+	}
+
+	shortList := compactUserPrompts([]string{prompt, prompt}, nil)
+	longList := compactUserPrompts([]string{prompt, prompt}, &config.CompactionConfig{UserPromptListChars: 500})
+	if len(longList) <= len(shortList) {
+		t.Errorf("expected a higher per-prompt limit to keep more characters, got %d <= %d", len(longList), len(shortList))
+	}
+}
+
 func TestAdjustCompactionBoundaryForTools_Backward(t *testing.T) {
 	messages := []*session.Message{
 		{Role: "assistant", ToolCalls: []map[string]interface{}{{"id": "call-1"}}},
@@ -218,7 +260,7 @@ func TestCompactUserPrompts(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := compactUserPrompts(tt.prompts)
+			result := compactUserPrompts(tt.prompts, nil)
 			tt.check(t, result)
 		})
 	}
@@ -430,7 +472,7 @@ func TestBuildUserCompactionSection_EdgeCases(t *testing.T) {
 		messages := []*session.Message{
 			{Role: "assistant", Content: "hi"},
 		}
-		section := buildUserCompactionSection(messages, []int{10}, 1000, "")
+		section := buildUserCompactionSection(messages, []int{10}, 1000, "", nil)
 		if section != "" {
 			t.Fatalf("expected empty section, got %q", section)
 		}
@@ -440,7 +482,7 @@ func TestBuildUserCompactionSection_EdgeCases(t *testing.T) {
 		messages := []*session.Message{
 			{Role: "assistant", Content: "hi"},
 		}
-		section := buildUserCompactionSection(messages, []int{10}, 1000, "do stuff")
+		section := buildUserCompactionSection(messages, []int{10}, 1000, "do stuff", nil)
 		if !strings.Contains(section, "do stuff") {
 			t.Fatalf("expected latest prompt, got %q", section)
 		}
@@ -453,7 +495,7 @@ func TestBuildUserCompactionSection_EdgeCases(t *testing.T) {
 		messages := []*session.Message{
 			{Role: "user", Content: "a"},
 		}
-		section := buildUserCompactionSection(messages, []int{10}, 0, "go")
+		section := buildUserCompactionSection(messages, []int{10}, 0, "go", nil)
 		if !strings.Contains(section, "condensed summary") {
 			t.Fatalf("expected condensed summary for zero context window, got %q", section)
 		}
@@ -465,7 +507,7 @@ func TestBuildUserCompactionSection_EdgeCases(t *testing.T) {
 			{Role: "assistant", Content: "b"},
 		}
 		// perMessageTokens has only 1 element for 2 messages
-		section := buildUserCompactionSection(messages, []int{10}, 1000, "x")
+		section := buildUserCompactionSection(messages, []int{10}, 1000, "x", nil)
 		if section == "" {
 			t.Fatalf("expected non-empty section even with mismatched tokens")
 		}
@@ -475,9 +517,68 @@ func TestBuildUserCompactionSection_EdgeCases(t *testing.T) {
 		messages := []*session.Message{
 			{Role: "user", Content: "a"},
 		}
-		section := buildUserCompactionSection(messages, []int{5}, 10000, "")
+		section := buildUserCompactionSection(messages, []int{5}, 10000, "", nil)
 		if strings.Contains(section, "Continue to implement this.") {
 			t.Fatalf("should not have continuation directive with empty latest prompt, got %q", section)
 		}
 	})
 }
+
+func TestRecentExchangesStart(t *testing.T) {
+	messages := []*session.Message{
+		{Role: "user", Content: "start"},
+		{Role: "assistant", Content: "ok"},
+		{Role: "user", Content: "run the tests"},
+		{Role: "assistant", ToolCalls: []map[string]interface{}{{"id": "c1"}, {"id": "c2"}}},
+		{Role: "tool", ToolID: "c1", Content: "r1"},
+		{Role: "tool", ToolID: "c2", Content: "r2"},
+	}
+
+	tests := []struct {
+		keep     int
+		expected int
+	}{
+		{0, 6},
+		{1, 3}, // the tool call and both results are one exchange
+		{2, 2},
+		{3, 1},
+		{10, 0},
+	}
+	for _, tt := range tests {
+		if got := recentExchangesStart(messages, tt.keep); got != tt.expected {
+			t.Errorf("keep %d: expected %d, got %d", tt.keep, tt.expected, got)
+		}
+	}
+}
+
+func TestClampCompactionPrefixKeepsRecentToolExchange(t *testing.T) {
+	messages := []*session.Message{
+		{Role: "user", Content: "a"},
+		{Role: "assistant", Content: "b"},
+		{Role: "user", Content: "c"},
+		{Role: "assistant", ToolCalls: []map[string]interface{}{{"id": "c1"}}},
+		{Role: "tool", ToolID: "c1", Content: "r1"},
+		{Role: "assistant", ToolCalls: []map[string]interface{}{{"id": "c2"}, {"id": "c3"}}},
+		{Role: "tool", ToolID: "c2", Content: "r2"},
+		{Role: "tool", ToolID: "c3", Content: "r3"},
+	}
+
+	for preserve := 1; preserve <= 3; preserve++ {
+		protected := recentExchangesStart(messages, preserve)
+		for prefix := 0; prefix <= len(messages); prefix++ {
+			got := clampCompactionPrefix(messages, prefix, preserve)
+			if got > protected {
+				t.Errorf("preserve %d, prefix %d: compacted into the recent exchanges (boundary %d > %d)", preserve, prefix, got, protected)
+			}
+			// A boundary right after a tool call or between its results splits the pair
+			if got > 0 && got < len(messages) && messages[got].Role == "tool" {
+				t.Errorf("preserve %d, prefix %d: boundary %d splits a tool call from its results", preserve, prefix, got)
+			}
+		}
+	}
+
+	// Preserving one exchange keeps the whole last tool call with both results
+	if got := clampCompactionPrefix(messages, len(messages), 1); got != 5 {
+		t.Errorf("expected the boundary before the last tool call, got %d", got)
+	}
+}
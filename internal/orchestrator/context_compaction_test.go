@@ -1,8 +1,15 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/provider"
 	"github.com/codefionn/scriptschnell/internal/session"
 )
 
@@ -171,3 +178,279 @@ func TestHeuristicContextWindow_MorePatterns(t *testing.T) {
 		})
 	}
 }
+
+func TestGetContextWindowOverrides(t *testing.T) {
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	orch := &Orchestrator{
+		config: &config.Config{ContextWindowOverrides: map[string]int{
+			"claude-":   1000000,
+			"new-model": 65536,
+		}},
+		providerMgr: providerMgr,
+	}
+
+	tests := []struct {
+		modelID string
+		want    int
+	}{
+		{"claude-sonnet-4", 1000000},   // override wins over the Claude heuristic
+		{"vendor/new-model-v2", 65536}, // substring override for a model without metadata
+		{"gpt-4o", 128000},             // unmatched models keep the heuristic
+		{"my-custom-model", 8192},
+	}
+	for _, tt := range tests {
+		if got := orch.getContextWindow(tt.modelID); got != tt.want {
+			t.Errorf("getContextWindow(%q) = %d, want %d", tt.modelID, got, tt.want)
+		}
+	}
+}
+
+func TestSelectCompactionPrefixSkipsPinnedMessages(t *testing.T) {
+	messages := []*session.Message{
+		{Role: "user", Content: "long spec", Pinned: true},
+		{Role: "assistant", Content: "a"},
+		{Role: "user", Content: "b"},
+		{Role: "assistant", Content: "c"},
+		{Role: "user", Content: "d"},
+	}
+	tokens := []int{50, 15, 15, 15, 5}
+	total := 100
+
+	// Without pinning the large spec alone reaches the 40% threshold
+	if prefix := selectCompactionPrefix(tokens, total); prefix != 1 {
+		t.Fatalf("expected prefix 1 without pinning, got %d", prefix)
+	}
+
+	prefix := selectCompactionPrefix(compactableTokens(messages, tokens), total)
+	if prefix != 4 {
+		t.Fatalf("expected the prefix to extend past the pinned spec to 4, got %d", prefix)
+	}
+
+	summarized := unpinnedMessages(messages[:prefix])
+	if len(summarized) != 3 {
+		t.Fatalf("expected 3 messages to summarize, got %d", len(summarized))
+	}
+	for _, msg := range summarized {
+		if msg.Pinned {
+			t.Errorf("pinned message %q must not be summarized", msg.Content)
+		}
+	}
+}
+
+func TestCompactionFreedPercent(t *testing.T) {
+	tests := []struct {
+		before, after, want int
+	}{
+		{1000, 250, 75},
+		{1000, 1000, 0},
+		{1000, 1200, 0},
+		{0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := compactionFreedPercent(tt.before, tt.after); got != tt.want {
+			t.Errorf("compactionFreedPercent(%d, %d) = %d, want %d", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestCompactContextReportsProgress(t *testing.T) {
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	sess := session.NewSession("test", t.TempDir())
+	for i := 0; i < 6; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		sess.AddMessage(&session.Message{Role: role, Content: strings.Repeat("earlier discussion about the build ", 200)})
+	}
+
+	interval := compactionProgressInterval
+	compactionProgressInterval = 5 * time.Millisecond
+	t.Cleanup(func() { compactionProgressInterval = interval })
+
+	orch := &Orchestrator{
+		config:      &config.Config{},
+		providerMgr: providerMgr,
+		session:     sess,
+		summarizeClient: &MockClient{
+			CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+				// A slow summarization reports that it is still running
+				time.Sleep(50 * time.Millisecond)
+				return "The build was discussed.", nil
+			},
+		},
+	}
+
+	var updates []progress.Update
+	callback := func(update progress.Update) error {
+		updates = append(updates, update)
+		return nil
+	}
+
+	messages := sess.GetMessages()[:4]
+	orch.compactContext("gpt-4o", "", nil, messages, callback)
+
+	if len(updates) < 3 {
+		t.Fatalf("expected at least 3 progress updates, got %d: %+v", len(updates), updates)
+	}
+	if first := updates[0]; first.Message != "🧹 Compacting 4 earlier messages..." || first.Mode != progress.ReportJustStatus || !first.Ephemeral {
+		t.Errorf("unexpected first update %+v", first)
+	}
+	var heartbeats, done int
+	for _, update := range updates[1 : len(updates)-1] {
+		if !strings.HasPrefix(update.Message, "🧹 Summarizing earlier context") || !update.Ephemeral {
+			t.Errorf("unexpected intermediate update %+v", update)
+		}
+		if strings.HasSuffix(update.Message, "s)...") {
+			heartbeats++
+		}
+		if strings.Contains(update.Message, "100%") {
+			done++
+		}
+	}
+	if heartbeats == 0 || done == 0 {
+		t.Errorf("expected elapsed time and percentage updates while summarizing, got %+v", updates)
+	}
+	last := updates[len(updates)-1]
+	if !strings.Contains(last.Message, "Auto-compacted 4 earlier messages, freed ~") || last.Ephemeral {
+		t.Errorf("unexpected final update %+v", last)
+	}
+	if strings.Contains(last.Message, "freed ~0%") {
+		t.Errorf("expected compaction to free context, got %q", last.Message)
+	}
+	if got := len(sess.GetMessages()); got != 3 {
+		t.Errorf("expected the summary and 2 remaining messages, got %d", got)
+	}
+}
+
+func TestCompactionInstructionUsesTemplate(t *testing.T) {
+	orch := &Orchestrator{config: &config.Config{}}
+	messages := []*session.Message{
+		{Role: "user", Content: "Fix the parser"},
+		{Role: "assistant", Content: "Done"},
+	}
+
+	if got := orch.compactionInstruction("built-in", "standard", 1, messages, "Fix the parser"); got != "built-in" {
+		t.Fatalf("expected built-in instruction without template, got %q", got)
+	}
+
+	orch.config.PromptTemplates.CompactionSummary = "{{.Instruction}} ({{.Level}}, {{len .Messages}} messages, last: {{.LatestUserPrompt}})"
+	want := "built-in (forceful, 2 messages, last: Fix the parser)"
+	if got := orch.compactionInstruction("built-in", "forceful", 2, messages, "Fix the parser"); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	orch.config.PromptTemplates.CompactionSummary = "{{.Unknown}}"
+	if got := orch.compactionInstruction("built-in", "standard", 1, messages, ""); got != "built-in" {
+		t.Fatalf("expected fallback to built-in instruction, got %q", got)
+	}
+}
+
+func TestCompactNowSummarizesEarlierContext(t *testing.T) {
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	sess := session.NewSession("test", t.TempDir())
+	for i := 0; i < 8; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		sess.AddMessage(&session.Message{Role: role, Content: strings.Repeat("earlier discussion about the build ", 200)})
+	}
+
+	orch := &Orchestrator{
+		config:             &config.Config{},
+		providerMgr:        providerMgr,
+		session:            sess,
+		cachedSystemPrompt: "You are a helpful assistant.",
+		summarizeClient: &MockClient{
+			CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+				return "The build was discussed.", nil
+			},
+		},
+	}
+	orch.models.orchestration = "gpt-4o"
+
+	result, err := orch.CompactNow(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("CompactNow failed: %v", err)
+	}
+	if result.CompactedMessages != 6 {
+		t.Errorf("expected 6 compacted messages, got %d", result.CompactedMessages)
+	}
+	if result.FreedPercent <= 0 {
+		t.Errorf("expected compaction to free context, got %d%%", result.FreedPercent)
+	}
+
+	messages := sess.GetMessages()
+	if len(messages) != 3 {
+		t.Fatalf("expected the summary and the 2 recent messages, got %d", len(messages))
+	}
+	if !strings.HasPrefix(messages[0].Content, "Summary of earlier context") {
+		t.Errorf("expected a summary message first, got %q", messages[0].Content)
+	}
+
+	if _, err := orch.CompactNow(context.Background(), nil, nil); !errors.Is(err, ErrNothingToCompact) {
+		t.Errorf("expected ErrNothingToCompact for a compacted session, got %v", err)
+	}
+}
+
+func TestCompactNowRejectedDuringGeneration(t *testing.T) {
+	sess := session.NewSession("test", t.TempDir())
+	for i := 0; i < 8; i++ {
+		sess.AddMessage(&session.Message{Role: "user", Content: "message"})
+	}
+	orch := &Orchestrator{config: &config.Config{}, session: sess}
+	orch.activePrompts.Add(1)
+
+	if _, err := orch.CompactNow(context.Background(), nil, nil); !errors.Is(err, ErrCompactionBusy) {
+		t.Errorf("expected ErrCompactionBusy, got %v", err)
+	}
+	if got := len(sess.GetMessages()); got != 8 {
+		t.Errorf("expected the session to be untouched, got %d messages", got)
+	}
+}
+
+func TestCompactNowCancelledKeepsMessages(t *testing.T) {
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	sess := session.NewSession("test", t.TempDir())
+	for i := 0; i < 8; i++ {
+		sess.AddMessage(&session.Message{Role: "user", Content: strings.Repeat("earlier discussion ", 100)})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	orch := &Orchestrator{
+		config:             &config.Config{},
+		providerMgr:        providerMgr,
+		session:            sess,
+		cachedSystemPrompt: "You are a helpful assistant.",
+		summarizeClient: &MockClient{
+			CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+				cancel()
+				return "", ctx.Err()
+			},
+		},
+	}
+	orch.models.orchestration = "gpt-4o"
+
+	if _, err := orch.CompactNow(ctx, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := len(sess.GetMessages()); got != 8 {
+		t.Errorf("expected the session to be untouched, got %d messages", got)
+	}
+	if orch.compactionInProgress {
+		t.Error("expected the compaction to be released after cancelling")
+	}
+}
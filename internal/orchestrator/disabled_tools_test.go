@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/provider"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+func TestDisabledToolsAreNotRegistered(t *testing.T) {
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	cfg := &config.Config{
+		WorkingDir:    t.TempDir(),
+		DisabledTools: []string{tools.ToolNameWebSearch, tools.ToolNameCreateFile},
+	}
+	orch, err := NewOrchestrator(cfg, providerMgr, false)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	t.Cleanup(func() { orch.Stop() })
+
+	// Critical tools are disabled too
+	for _, name := range cfg.DisabledTools {
+		if _, ok := orch.toolRegistry.GetExecutor(name); ok {
+			t.Errorf("expected %s not to be registered", name)
+		}
+	}
+	for _, name := range []string{tools.ToolNameReadFile, tools.ToolNameWebFetch, tools.ToolNameSearchFiles} {
+		if _, ok := orch.toolRegistry.GetExecutor(name); !ok {
+			t.Errorf("expected %s to stay registered", name)
+		}
+	}
+}
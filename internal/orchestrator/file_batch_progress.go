@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// isFileWriteTool reports whether the named tool writes files to disk.
+func isFileWriteTool(name string) bool {
+	switch name {
+	case tools.ToolNameCreateFile, tools.ToolNameReplaceFile, tools.ToolNameEditFile:
+		return true
+	default:
+		return false
+	}
+}
+
+// fileBatchProgress reports aggregate progress ("3/10 files written") while a
+// batch of file-writing tool calls from a single iteration is executed.
+type fileBatchProgress struct {
+	mu     sync.Mutex
+	cb     progress.Callback
+	total  int
+	done   int
+	failed int
+}
+
+// newFileBatchProgress counts the file-writing calls in toolCalls. It returns
+// nil when there is no callback or fewer than two file writes, since a single
+// write is already covered by the per-tool status.
+func newFileBatchProgress(toolCalls []map[string]interface{}, cb progress.Callback) *fileBatchProgress {
+	if cb == nil {
+		return nil
+	}
+
+	total := 0
+	for _, toolCall := range toolCalls {
+		function, ok := toolCall["function"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _ := function["name"].(string); isFileWriteTool(name) {
+			total++
+		}
+	}
+	if total < 2 {
+		return nil
+	}
+
+	return &fileBatchProgress{cb: cb, total: total}
+}
+
+// complete records a finished file-writing call and emits the updated count.
+// Safe to call on a nil receiver and for non-file tools (both are no-ops).
+func (p *fileBatchProgress) complete(toolName string, failed bool) {
+	if p == nil || !isFileWriteTool(toolName) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done >= p.total {
+		return
+	}
+	p.done++
+	if failed {
+		p.failed++
+	}
+
+	message := fmt.Sprintf("%d/%d files written", p.done, p.total)
+	if p.failed > 0 {
+		message = fmt.Sprintf("%s (%d failed)", message, p.failed)
+	}
+
+	dispatchProgress(p.cb, progress.Update{
+		Message:   message,
+		Mode:      progress.ReportJustStatus,
+		Ephemeral: true,
+	})
+}
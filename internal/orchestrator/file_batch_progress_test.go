@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+func fileToolCall(id, name, path string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   id,
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":      name,
+			"arguments": fmt.Sprintf(`{"path":%q}`, path),
+		},
+	}
+}
+
+func collectFileProgress(t *testing.T, toolCalls []map[string]interface{}, execFn toolExecutionFunc) []progress.Update {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		updates []progress.Update
+	)
+	progressCb := func(update progress.Update) error {
+		if strings.Contains(update.Message, "files written") {
+			mu.Lock()
+			updates = append(updates, update)
+			mu.Unlock()
+		}
+		return nil
+	}
+
+	orch := &Orchestrator{}
+	sess := session.NewSession("test", ".")
+	if err := orch.processToolCalls(context.Background(), toolCalls, sess, progressCb, nil, nil, nil, execFn); err != nil {
+		t.Fatalf("processToolCalls returned error: %v", err)
+	}
+	return updates
+}
+
+func TestProcessToolCallsReportsFileBatchProgress(t *testing.T) {
+	toolCalls := []map[string]interface{}{
+		fileToolCall("call-1", tools.ToolNameCreateFile, "a.go"),
+		fileToolCall("call-2", tools.ToolNameReadFile, "b.go"),
+		fileToolCall("call-3", tools.ToolNameEditFile, "c.go"),
+		fileToolCall("call-4", tools.ToolNameReplaceFile, "d.go"),
+	}
+
+	execFn := func(ctx context.Context, call *tools.ToolCall, toolName string, progressCb progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
+		if call.ID == "call-3" {
+			return &tools.ToolResult{ID: call.ID, Error: "old_string not found"}, nil
+		}
+		return &tools.ToolResult{ID: call.ID, Result: "ok"}, nil
+	}
+
+	updates := collectFileProgress(t, toolCalls, execFn)
+	if len(updates) != 3 {
+		t.Fatalf("expected 3 file progress updates, got %d: %+v", len(updates), updates)
+	}
+
+	for i, update := range updates {
+		prefix := fmt.Sprintf("%d/3 files written", i+1)
+		if !strings.HasPrefix(update.Message, prefix) {
+			t.Errorf("update %d: expected prefix %q, got %q", i, prefix, update.Message)
+		}
+		if update.Mode != progress.ReportJustStatus || !update.Ephemeral {
+			t.Errorf("update %d: expected ephemeral status-only update, got %+v", i, update)
+		}
+	}
+
+	if last := updates[len(updates)-1].Message; last != "3/3 files written (1 failed)" {
+		t.Errorf("expected final update to report the failure, got %q", last)
+	}
+}
+
+func TestProcessToolCallsSkipsFileProgressForSingleWrite(t *testing.T) {
+	toolCalls := []map[string]interface{}{
+		fileToolCall("call-1", tools.ToolNameCreateFile, "a.go"),
+		fileToolCall("call-2", tools.ToolNameReadFile, "b.go"),
+	}
+
+	execFn := func(ctx context.Context, call *tools.ToolCall, toolName string, progressCb progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
+		return &tools.ToolResult{ID: call.ID, Result: "ok"}, nil
+	}
+
+	if updates := collectFileProgress(t, toolCalls, execFn); len(updates) != 0 {
+		t.Fatalf("expected no file progress updates for a single write, got %+v", updates)
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/progress"
+)
+
+func TestOrchestrationLoop_RoutesReasoningSeparately(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	orch.orchestrationClient = newSequentialMockClient(&llm.CompletionResponse{
+		Content:    "<think>inline plan</think>\n\nThe answer is 42.",
+		Reasoning:  "native reasoning",
+		StopReason: "stop",
+	})
+
+	var reasoning, streamed strings.Builder
+	progressCb := func(update progress.Update) error {
+		if update.Reasoning != "" {
+			reasoning.WriteString(update.Reasoning)
+		}
+		if update.ShouldStream() {
+			streamed.WriteString(update.Message)
+		}
+		return nil
+	}
+
+	if _, err := orch.ProcessPromptWithResult(context.Background(), "what is the answer?", progressCb, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("ProcessPrompt failed: %v", err)
+	}
+
+	if got := reasoning.String(); got != "native reasoning\n\ninline plan" {
+		t.Errorf("expected native and inline reasoning on the reasoning channel, got %q", got)
+	}
+	if got := streamed.String(); !strings.Contains(got, "The answer is 42.") || strings.Contains(got, "reasoning") || strings.Contains(got, "inline plan") {
+		t.Errorf("expected only the answer to be streamed as content, got %q", got)
+	}
+
+	var found bool
+	for _, msg := range orch.session.GetMessages() {
+		if msg.Role != "assistant" {
+			continue
+		}
+		found = true
+		if msg.Content != "The answer is 42." || msg.Reasoning != "native reasoning\n\ninline plan" {
+			t.Errorf("expected content and reasoning to be stored separately, got content %q reasoning %q", msg.Content, msg.Reasoning)
+		}
+	}
+	if !found {
+		t.Fatal("expected an assistant message in the session")
+	}
+}
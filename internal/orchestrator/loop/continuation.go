@@ -0,0 +1,218 @@
+package loop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContinuationKind describes where a truncated response was cut off
+type ContinuationKind int
+
+const (
+	// ContinuationGeneric means the response ended in prose (or looks complete)
+	ContinuationGeneric ContinuationKind = iota
+	// ContinuationCodeFence means the response ended inside an unterminated code fence
+	ContinuationCodeFence
+	// ContinuationToolCallJSON means the response ended inside an unfinished JSON object
+	ContinuationToolCallJSON
+)
+
+// genericContinuePrompt is sent when the truncation point is not structurally significant
+const genericContinuePrompt = "continue"
+
+// minContinuationOverlap is the shortest repeated text that is removed when
+// stitching a continuation; shorter overlaps are too likely to be accidental.
+const minContinuationOverlap = 8
+
+// maxContinuationOverlap bounds the search for repeated text
+const maxContinuationOverlap = 2000
+
+// ContinuationStitcher is implemented by sessions that can merge a continued
+// response into the truncated assistant message it continues, so that the
+// conversation keeps a single well-formed assistant turn.
+type ContinuationStitcher interface {
+	// StitchContinuation finds the last user message with content prompt that
+	// sits between two assistant messages, replaces the three messages with one
+	// assistant message whose content is stitch(previous, continued), and
+	// returns the merged content. It returns false if no such turn exists.
+	StitchContinuation(prompt string, stitch func(prev, next string) string) (string, bool)
+}
+
+// IsMaxTokensStopReason reports whether a provider stop reason means the
+// response was cut off by the output token limit
+func IsMaxTokensStopReason(reason string) bool {
+	switch strings.ToLower(strings.TrimSpace(reason)) {
+	case "length", "max_tokens", "max_output_tokens", "model_length":
+		return true
+	default:
+		return false
+	}
+}
+
+// DetectContinuation classifies where content was cut off
+func DetectContinuation(content string) ContinuationKind {
+	if _, open := openCodeFence(content); open {
+		return ContinuationCodeFence
+	}
+	if unfinishedJSON(content) {
+		return ContinuationToolCallJSON
+	}
+	return ContinuationGeneric
+}
+
+// BuildContinuationPrompt returns the user message asking the model to resume
+// content. Structured truncations get a precise instruction so the model does
+// not restart the block or add prose in the middle of it.
+func BuildContinuationPrompt(content string) (string, ContinuationKind) {
+	kind := DetectContinuation(content)
+	switch kind {
+	case ContinuationCodeFence:
+		fence, _ := openCodeFence(content)
+		return fmt.Sprintf("Your previous response was cut off inside a %s code block. "+
+			"Continue exactly where it stopped, starting with the next character of the code. "+
+			"Do not repeat any earlier text, do not open a new code block and do not add any explanation before the code. "+
+			"Close the code block when the code is complete.", describeFence(fence)), kind
+	case ContinuationToolCallJSON:
+		return "Your previous response was cut off in the middle of a JSON object. " +
+			"Continue exactly where it stopped, starting with the next character, so that the JSON becomes valid. " +
+			"Do not repeat any earlier text and do not add any explanation.", kind
+	default:
+		return genericContinuePrompt, kind
+	}
+}
+
+// StitchContinuation appends a continued response to the truncated content it
+// continues. A code fence reopened by the model and text repeated from the end
+// of prev are dropped, so the result reads as a single response.
+func StitchContinuation(prev, next string) string {
+	if fence, open := openCodeFence(prev); open {
+		next = dropReopenedFence(fence, next)
+	}
+
+	if overlap := continuationOverlap(prev, next); overlap > 0 {
+		next = next[overlap:]
+	}
+	return prev + next
+}
+
+// codeFence is an opening fence line, e.g. "```go"
+type codeFence struct {
+	marker string // the fence characters, e.g. "```" or "~~~~"
+	lang   string
+}
+
+// openCodeFence returns the fence that is still open at the end of content
+func openCodeFence(content string) (codeFence, bool) {
+	var current codeFence
+	open := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		marker := fenceMarker(trimmed)
+		if marker == "" {
+			continue
+		}
+		if !open {
+			current = codeFence{marker: marker, lang: strings.TrimSpace(trimmed[len(marker):])}
+			open = true
+			continue
+		}
+		// A fence closes only with the same character, at least as long, and no info string
+		if marker[0] == current.marker[0] && len(marker) >= len(current.marker) && strings.TrimSpace(trimmed[len(marker):]) == "" {
+			open = false
+		}
+	}
+	return current, open
+}
+
+// fenceMarker returns the leading run of ``` or ~~~ of a line, or ""
+func fenceMarker(line string) string {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return line[:n]
+}
+
+func describeFence(fence codeFence) string {
+	if fence.lang == "" {
+		return "fenced"
+	}
+	return fence.lang
+}
+
+// dropReopenedFence removes a fence opening line at the start of next that
+// restarts the block that is still open in the previous content
+func dropReopenedFence(fence codeFence, next string) string {
+	trimmed := strings.TrimLeft(next, " \t\r\n")
+	line, rest, found := strings.Cut(trimmed, "\n")
+	if !found {
+		return next
+	}
+	marker := fenceMarker(strings.TrimSpace(line))
+	if marker == "" || marker[0] != fence.marker[0] {
+		return next
+	}
+	lang := strings.TrimSpace(strings.TrimSpace(line)[len(marker):])
+	// A bare fence closes the open block and must be kept
+	if lang == "" || lang != fence.lang {
+		return next
+	}
+	return rest
+}
+
+// continuationOverlap returns the length of the longest prefix of next that
+// prev already ends with
+func continuationOverlap(prev, next string) int {
+	limit := len(next)
+	if len(prev) < limit {
+		limit = len(prev)
+	}
+	if limit > maxContinuationOverlap {
+		limit = maxContinuationOverlap
+	}
+	for n := limit; n >= minContinuationOverlap; n-- {
+		if strings.HasSuffix(prev, next[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// unfinishedJSON reports whether content ends inside a JSON object, such as a
+// tool call the model was writing as text when it hit the token limit
+func unfinishedJSON(content string) bool {
+	start := strings.LastIndex(content, "\n{")
+	if start >= 0 {
+		start++
+	} else if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		start = strings.Index(content, "{")
+	} else {
+		return false
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for _, r := range content[start:] {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			depth--
+		}
+	}
+	return depth > 0 || inString
+}
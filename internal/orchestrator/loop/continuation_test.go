@@ -0,0 +1,237 @@
+package loop
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+// stitchingSession is a MockSession that merges continuations like the
+// orchestrator's session adapter
+type stitchingSession struct {
+	MockSession
+}
+
+func (s *stitchingSession) StitchContinuation(prompt string, stitch func(prev, next string) string) (string, bool) {
+	n := len(s.Messages)
+	if n < 3 || s.Messages[n-2].GetRole() != "user" || s.Messages[n-2].GetContent() != prompt {
+		return "", false
+	}
+	merged := &SimpleMessage{
+		Role:    "assistant",
+		Content: stitch(s.Messages[n-3].GetContent(), s.Messages[n-1].GetContent()),
+	}
+	s.Messages = append(s.Messages[:n-3], merged)
+	return merged.Content, true
+}
+
+var _ ContinuationStitcher = (*stitchingSession)(nil)
+
+func TestDetectContinuation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    ContinuationKind
+	}{
+		{"prose", "The function is done.", ContinuationGeneric},
+		{"closed fence", "Code:\n```go\nfunc main() {}\n```\n", ContinuationGeneric},
+		{"open fence", "Code:\n```go\nfunc main() {\n\tfmt.Println(", ContinuationCodeFence},
+		{"open tilde fence", "~~~python\nprint(", ContinuationCodeFence},
+		{"fence with info string does not close", "```go\nx := 1\n```go\n", ContinuationCodeFence},
+		{"unfinished json", "Calling the tool:\n{\"name\": \"write_file\", \"arguments\": {\"path\": \"a.go\", \"content\": \"pack", ContinuationToolCallJSON},
+		{"finished json", "{\"name\": \"read_file\", \"arguments\": {\"path\": \"a.go\"}}", ContinuationGeneric},
+		{"braces inside strings", "{\"content\": \"}}}\"", ContinuationToolCallJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContinuation(tt.content); got != tt.want {
+				t.Errorf("DetectContinuation(%q) = %d, want %d", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContinuationPrompt(t *testing.T) {
+	if prompt, kind := BuildContinuationPrompt("Let me explain:"); prompt != "continue" || kind != ContinuationGeneric {
+		t.Errorf("expected generic continue prompt, got %q (%d)", prompt, kind)
+	}
+
+	prompt, kind := BuildContinuationPrompt("```go\nfunc main() {")
+	if kind != ContinuationCodeFence || !strings.Contains(prompt, "go code block") || !strings.Contains(prompt, "Do not repeat") {
+		t.Errorf("unexpected code fence prompt %q (%d)", prompt, kind)
+	}
+}
+
+func TestStitchContinuation(t *testing.T) {
+	tests := []struct {
+		name string
+		prev string
+		next string
+		want string
+	}{
+		{
+			name: "exact resume",
+			prev: "```go\nfunc main() {\n\tfmt.Pri",
+			next: "ntln(\"hi\")\n}\n```\n",
+			want: "```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n",
+		},
+		{
+			name: "reopened fence and repeated line",
+			prev: "Here is the code:\n\n```go\nfunc main() {\n\tfmt.Println(\"hel",
+			next: "```go\n\tfmt.Println(\"hello\")\n}\n```\n",
+			want: "Here is the code:\n\n```go\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n```\n",
+		},
+		{
+			name: "bare closing fence is kept",
+			prev: "```go\nfunc main() {}\n",
+			next: "```\nDone.",
+			want: "```go\nfunc main() {}\n```\nDone.",
+		},
+		{
+			name: "short accidental overlap is kept",
+			prev: "```go\nx := f(",
+			next: "(y)\n```",
+			want: "```go\nx := f((y)\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StitchContinuation(tt.prev, tt.next); got != tt.want {
+				t.Errorf("StitchContinuation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMaxTokensStopReason(t *testing.T) {
+	for _, reason := range []string{"length", "max_tokens", "MAX_TOKENS"} {
+		if !IsMaxTokensStopReason(reason) {
+			t.Errorf("expected %q to be a max tokens stop reason", reason)
+		}
+	}
+	for _, reason := range []string{"", "stop", "end_turn", "tool_calls"} {
+		if IsMaxTokensStopReason(reason) {
+			t.Errorf("expected %q not to be a max tokens stop reason", reason)
+		}
+	}
+}
+
+func TestOrchestratorLoopStitchesTruncatedCodeFence(t *testing.T) {
+	config := &Config{MaxIterations: 10, MaxAutoContinueAttempts: 3, EnableAutoContinue: true}
+	sess := &stitchingSession{}
+	sess.AddMessage(&SimpleMessage{Role: "user", Content: "write main.go"})
+
+	chunks := []string{
+		"Here is the program:\n\n```go\npackage main\n\nfunc main() {\n\tprintln(\"hel",
+		"```go\n\tprintln(\"hello\")\n}\n```\n\nRun it with `go run main.go`.",
+	}
+	var prompts []string
+	iteration := &MockIteration{
+		ExecuteFunc: func(ctx context.Context, state State) (*IterationOutcome, error) {
+			msgs := sess.GetMessages()
+			prompts = append(prompts, msgs[len(msgs)-1].GetContent())
+
+			content := chunks[len(prompts)-1]
+			stopReason := "stop"
+			if len(prompts) == 1 {
+				stopReason = "length"
+			}
+			sess.AddMessage(&SimpleMessage{Role: "assistant", Content: content})
+			return &IterationOutcome{
+				Result:   Break,
+				Content:  content,
+				Response: &llm.CompletionResponse{Content: content, StopReason: stopReason},
+			}, nil
+		},
+	}
+
+	loop := NewOrchestratorLoop(config, NewDefaultStrategy(config), iteration, &Dependencies{})
+	result, err := loop.Run(context.Background(), sess, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if iteration.ExecuteCount != 2 {
+		t.Fatalf("expected 2 iterations, got %d", iteration.ExecuteCount)
+	}
+	if !strings.Contains(prompts[1], "cut off inside a go code block") {
+		t.Errorf("expected precise continuation prompt, got %q", prompts[1])
+	}
+	if !result.Success {
+		t.Errorf("expected success, got %+v", result)
+	}
+
+	msgs := sess.GetMessages()
+	if len(msgs) != 2 || msgs[1].GetRole() != "assistant" {
+		t.Fatalf("expected the continuation to be merged into one assistant message, got %d messages", len(msgs))
+	}
+	want := "Here is the program:\n\n```go\npackage main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n```\n\nRun it with `go run main.go`."
+	if got := msgs[1].GetContent(); got != want {
+		t.Errorf("reassembled content = %q, want %q", got, want)
+	}
+	if DetectContinuation(msgs[1].GetContent()) != ContinuationGeneric {
+		t.Error("reassembled content should have no open code fence")
+	}
+}
+
+func TestOrchestratorLoopContinuationRespectsLimit(t *testing.T) {
+	config := &Config{MaxIterations: 20, MaxAutoContinueAttempts: 2, EnableAutoContinue: true}
+	sess := &stitchingSession{}
+	sess.AddMessage(&SimpleMessage{Role: "user", Content: "write a long file"})
+
+	iteration := &MockIteration{
+		ExecuteFunc: func(ctx context.Context, state State) (*IterationOutcome, error) {
+			content := "```go\n// more code\n"
+			sess.AddMessage(&SimpleMessage{Role: "assistant", Content: content})
+			return &IterationOutcome{
+				Result:   Break,
+				Content:  content,
+				Response: &llm.CompletionResponse{Content: content, StopReason: "max_tokens"},
+			}, nil
+		},
+	}
+
+	loop := NewOrchestratorLoop(config, NewDefaultStrategy(config), iteration, &Dependencies{})
+	if _, err := loop.Run(context.Background(), sess, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if iteration.ExecuteCount != 3 {
+		t.Errorf("expected 1 iteration plus 2 continuations, got %d", iteration.ExecuteCount)
+	}
+	if loop.GetState().AutoContinueAttempts() != 2 {
+		t.Errorf("expected 2 auto-continue attempts, got %d", loop.GetState().AutoContinueAttempts())
+	}
+}
+
+func TestOrchestratorLoopContinuationStopsAtIterationLimit(t *testing.T) {
+	config := &Config{MaxIterations: 3, MaxAutoContinueAttempts: 5, EnableAutoContinue: true}
+	sess := &stitchingSession{}
+	sess.AddMessage(&SimpleMessage{Role: "user", Content: "write a long file"})
+
+	iteration := &MockIteration{
+		ExecuteFunc: func(ctx context.Context, state State) (*IterationOutcome, error) {
+			content := "```go\n// more code\n"
+			sess.AddMessage(&SimpleMessage{Role: "assistant", Content: content})
+			return &IterationOutcome{
+				Result:   Break,
+				Content:  content,
+				Response: &llm.CompletionResponse{Content: content, StopReason: "max_tokens"},
+			}, nil
+		},
+	}
+
+	loop := NewOrchestratorLoop(config, NewDefaultStrategy(config), iteration, &Dependencies{})
+	if _, err := loop.Run(context.Background(), sess, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if iteration.ExecuteCount != 2 {
+		t.Errorf("expected 2 iterations before the limit, got %d", iteration.ExecuteCount)
+	}
+	msgs := sess.GetMessages()
+	if last := msgs[len(msgs)-1]; last.GetRole() != "assistant" {
+		t.Errorf("expected no unanswered continuation prompt, got %s message %q", last.GetRole(), last.GetContent())
+	}
+}
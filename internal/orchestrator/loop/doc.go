@@ -180,14 +180,15 @@
 //
 // # Integration
 //
-// The loop abstraction is designed to integrate with the existing orchestrator.
-// The Orchestrator can use the loop abstraction by:
+// The orchestrator's ProcessPrompt runs every prompt through an
+// OrchestratorLoop. Per prompt it:
 //
-// 1. Creating appropriate dependencies
-// 2. Configuring the loop with desired settings
-// 3. Calling Run() instead of the inline loop
+// 1. Wires the session, tools, system prompt and context management into Dependencies
+// 2. Creates the Strategy selected by the "strategy" loop setting
+// 3. Creates an Iteration performing the LLM call, tool execution and compaction checks
+// 4. Calls Run()
 //
-// See the orchestrator package for the integration implementation.
+// See loop_integration.go in the orchestrator package for the implementation.
 //
 // See example_reuse_test.go for complete examples of reusing the loop abstraction.
 package loop
@@ -1,6 +1,7 @@
 package loop
 
 import (
+	"context"
 	"testing"
 
 	"github.com/codefionn/scriptschnell/internal/llm"
@@ -400,3 +401,57 @@ func TestInvalidLLMResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestLLMJudgeAutoContinueConfig(t *testing.T) {
+	// runLoop runs a loop whose model always stops mid-task while the judge
+	// always asks to continue, and returns the number of iterations
+	runLoop := func(t *testing.T, config *Config) (int, *MockLLMClient) {
+		t.Helper()
+		judge := &MockLLMClient{MockResponse: &llm.CompletionResponse{Content: "CONTINUE"}}
+		session := &MockSession{Messages: []Message{
+			&SimpleMessage{Role: "user", Content: "Refactor the parser"},
+			&SimpleMessage{Role: "assistant", Content: "I updated the lexer"},
+		}}
+		iteration := &MockIteration{
+			ExecuteFunc: func(ctx context.Context, state State) (*IterationOutcome, error) {
+				return &IterationOutcome{Result: Break, Content: "I updated the lexer"}, nil
+			},
+		}
+
+		strategy := NewLLMJudgeStrategy(config, judge, "judge-model", session)
+		loop := NewOrchestratorLoop(config, strategy, iteration, &Dependencies{})
+		if _, err := loop.Run(context.Background(), session, nil); err != nil {
+			t.Fatalf("loop failed: %v", err)
+		}
+		return iteration.ExecuteCount, judge
+	}
+
+	t.Run("disabled stops immediately", func(t *testing.T) {
+		config := DefaultConfig()
+		config.EnableLLMAutoContinueJudge = true
+		config.EnableAutoContinue = false
+
+		iterations, judge := runLoop(t, config)
+		if iterations != 1 {
+			t.Errorf("expected a single iteration, got %d", iterations)
+		}
+		if judge.CallCount != 0 {
+			t.Errorf("expected the judge not to be asked, got %d calls", judge.CallCount)
+		}
+	})
+
+	t.Run("raised limit continues more often", func(t *testing.T) {
+		config := DefaultConfig()
+		config.EnableLLMAutoContinueJudge = true
+
+		config.MaxAutoContinueAttempts = 2
+		low, _ := runLoop(t, config)
+
+		config.MaxAutoContinueAttempts = 6
+		high, _ := runLoop(t, config)
+
+		if low != 3 || high != 7 {
+			t.Errorf("expected 3 and 7 iterations (limit + 1), got %d and %d", low, high)
+		}
+	})
+}
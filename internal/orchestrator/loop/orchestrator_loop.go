@@ -18,6 +18,10 @@ type OrchestratorLoop struct {
 	iteration  Iteration
 	deps       *Dependencies
 	ctxManager ContextManager
+
+	// pendingStitch is the continuation prompt whose response should be
+	// merged into the truncated assistant message, or "" if none
+	pendingStitch string
 }
 
 // NewOrchestratorLoop creates a new OrchestratorLoop
@@ -41,6 +45,7 @@ func (l *OrchestratorLoop) Run(ctx context.Context, session Session, progressCb
 	l.state.ResetLoopDetection()
 	l.state.ResetAutoContinue()
 	l.state.ResetCompactionAttempts()
+	l.pendingStitch = ""
 
 	var lastOutcome *IterationOutcome
 	var terminatedEarly bool
@@ -101,35 +106,19 @@ func (l *OrchestratorLoop) Run(ctx context.Context, session Session, progressCb
 			}
 		}
 
-		// Check if we should continue
-		if !l.strategy.ShouldContinue(l.state, outcome) {
-			goto done
+		if l.pendingStitch != "" && outcome.Result != Error {
+			l.stitchContinuation(session, outcome)
 		}
 
-		// Handle auto-continue for incomplete responses
-		if outcome.Result == Break && outcome.Content != "" {
-			if l.strategy.ShouldAutoContinue(l.state, outcome.Content) {
-				// Add continue message to session
-				session.AddMessage(&SimpleMessage{
-					Role:    "user",
-					Content: "continue",
-				})
-
-				l.state.IncrementAutoContinue()
-				outcome.Result = BreakWithAutoContinue
-
-				// Notify progress
-				if progressCb != nil {
-					_ = progressCb(progress.Update{
-						Message:    "⏭ Auto-continue.\n",
-						AddNewLine: false,
-						Mode:       progress.ReportNoStatus,
-					})
-				}
+		// Handle auto-continue for incomplete or truncated responses. This has
+		// to happen before ShouldContinue, which stops on a plain Break.
+		if outcome.Result == Break && outcome.Content != "" && l.shouldAutoContinue(outcome) {
+			l.requestContinuation(session, outcome, progressCb)
+		}
 
-				// Continue to next iteration
-				continue
-			}
+		// Check if we should continue
+		if !l.strategy.ShouldContinue(l.state, outcome) {
+			goto done
 		}
 	}
 done:
@@ -172,6 +161,72 @@ done:
 	return result, nil
 }
 
+// shouldAutoContinue reports whether a response without tool calls should be
+// continued. Responses cut off by the output token limit are always continued
+// while the auto-continue budget lasts. Nothing is continued when the next
+// iteration would hit the iteration limit, as the continuation prompt would be
+// left in the session without a reply.
+func (l *OrchestratorLoop) shouldAutoContinue(outcome *IterationOutcome) bool {
+	if l.state.Iteration()+1 >= l.state.MaxIterations() {
+		return false
+	}
+	if l.strategy.ShouldAutoContinue(l.state, outcome.Content) {
+		return true
+	}
+	return outcome.Response != nil && IsMaxTokensStopReason(outcome.Response.StopReason) &&
+		l.config.EnableAutoContinue && !l.state.HasReachedAutoContinueLimit()
+}
+
+// requestContinuation asks the model to resume a response that was cut off
+func (l *OrchestratorLoop) requestContinuation(session Session, outcome *IterationOutcome, progressCb progress.Callback) {
+	prompt, kind := BuildContinuationPrompt(outcome.Content)
+	session.AddMessage(&SimpleMessage{
+		Role:    "user",
+		Content: prompt,
+	})
+	if kind != ContinuationGeneric {
+		l.pendingStitch = prompt
+	}
+
+	l.state.IncrementAutoContinue()
+	outcome.Result = BreakWithAutoContinue
+
+	if progressCb == nil {
+		return
+	}
+	// A precise continuation is only shown as a status, so the streamed code
+	// block is not interrupted
+	if kind != ContinuationGeneric {
+		_ = progressCb(progress.Update{
+			Message:   "Response was cut off, continuing...",
+			Mode:      progress.ReportJustStatus,
+			Ephemeral: true,
+		})
+		return
+	}
+	_ = progressCb(progress.Update{
+		Message:    "⏭ Auto-continue.\n",
+		AddNewLine: false,
+		Mode:       progress.ReportNoStatus,
+	})
+}
+
+// stitchContinuation merges the response to a precise continuation prompt
+// into the truncated assistant message, so later truncation checks see the
+// reassembled content
+func (l *OrchestratorLoop) stitchContinuation(session Session, outcome *IterationOutcome) {
+	prompt := l.pendingStitch
+	l.pendingStitch = ""
+
+	stitcher, ok := session.(ContinuationStitcher)
+	if !ok {
+		return
+	}
+	if merged, ok := stitcher.StitchContinuation(prompt, StitchContinuation); ok {
+		outcome.Content = merged
+	}
+}
+
 // RunIteration executes a single iteration and returns the outcome.
 func (l *OrchestratorLoop) RunIteration(ctx context.Context, state State) (*IterationOutcome, error) {
 	return l.iteration.Execute(ctx, state)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/config"
 	"github.com/codefionn/scriptschnell/internal/consts"
 	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/logger"
@@ -36,6 +38,14 @@ func (a *sessionAdapter) AddMessage(msg loop.Message) {
 	})
 }
 
+var _ loop.ContinuationStitcher = (*sessionAdapter)(nil)
+
+// StitchContinuation merges a continued response into the truncated assistant
+// message. The merged message drops the native format since its content changed.
+func (a *sessionAdapter) StitchContinuation(prompt string, stitch func(prev, next string) string) (string, bool) {
+	return a.session.MergeContinuation(prompt, stitch)
+}
+
 // GetMessages returns all messages in the session as loop.Message interfaces
 func (a *sessionAdapter) GetMessages() []loop.Message {
 	sessionMessages := a.session.GetMessages()
@@ -71,12 +81,12 @@ func newOrchestratorSystemPromptProvider(orch *Orchestrator) *orchestratorSystem
 }
 
 func (p *orchestratorSystemPromptProvider) GetSystemPrompt(ctx context.Context) (string, error) {
-	modelID := p.orch.providerMgr.GetOrchestrationModel()
+	modelID := p.orch.orchestrationModelID()
 	return p.orch.getOrBuildSystemPrompt(ctx, modelID)
 }
 
 func (p *orchestratorSystemPromptProvider) GetModelID() string {
-	return p.orch.providerMgr.GetOrchestrationModel()
+	return p.orch.orchestrationModelID()
 }
 
 // orchestratorContextManager implements loop.ContextManager
@@ -172,6 +182,9 @@ type orchestratorIteration struct {
 	toolCallCallback   ToolCallCallback
 	toolResultCallback ToolResultCallback
 	contextCallback    ContextUsageCallback
+
+	// Identical tool calls failing in a row across iterations
+	failingCalls *failingToolCallTracker
 }
 
 func newOrchestratorIteration(
@@ -182,6 +195,11 @@ func newOrchestratorIteration(
 	toolResultCallback ToolResultCallback,
 	contextCallback ContextUsageCallback,
 ) *orchestratorIteration {
+	threshold := config.DefaultLoopMaxRepeatedToolFailures
+	if orch.config != nil {
+		threshold = orch.config.Loop.GetMaxRepeatedToolFailures()
+	}
+
 	return &orchestratorIteration{
 		orch:               orch,
 		progressCallback:   progressCallback,
@@ -189,6 +207,7 @@ func newOrchestratorIteration(
 		toolCallCallback:   toolCallCallback,
 		toolResultCallback: toolResultCallback,
 		contextCallback:    contextCallback,
+		failingCalls:       newFailingToolCallTracker(threshold),
 	}
 }
 
@@ -211,7 +230,12 @@ func (i *orchestratorIteration) Execute(ctx context.Context, state loop.State) (
 		})
 	}
 
-	modelID := i.orch.providerMgr.GetOrchestrationModel()
+	// Honour pause requests between iterations (never mid-tool)
+	if err := i.orch.waitWhilePaused(ctx, i.progressCallback); err != nil {
+		return nil, err
+	}
+
+	modelID := i.orch.orchestrationModelID()
 	systemPrompt, err := i.orch.getOrBuildSystemPrompt(ctx, modelID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build system prompt: %w", err)
@@ -271,6 +295,10 @@ func (i *orchestratorIteration) Execute(ctx context.Context, state loop.State) (
 	if i.orch.toolRegistry != nil {
 		toolsJSON = i.orch.toolRegistry.ToJSONSchema()
 	}
+	if caps, err := i.orch.providerMgr.GetModelCapabilities(modelID); err == nil && caps.Known && !caps.SupportsTools && len(toolsJSON) > 0 {
+		logger.Warn("Model %s does not support tool calling; sending request without tools", modelID)
+		toolsJSON = nil
+	}
 	req := &llm.CompletionRequest{
 		Messages:      llmMessages,
 		Tools:         toolsJSON,
@@ -288,6 +316,7 @@ func (i *orchestratorIteration) Execute(ctx context.Context, state loop.State) (
 	}
 
 	i.orch.applyModelSpecificDefaults(req, modelID)
+	applyPromptOverrides(ctx, req)
 
 	if i.progressCallback != nil && len(llmMessages) > 1 {
 		sendStatus("Thinking...")
@@ -323,9 +352,23 @@ func (i *orchestratorIteration) Execute(ctx context.Context, state loop.State) (
 		return outcome, err
 	}
 
+	if response.Usage != nil {
+		i.orch.session.AccumulateUsage(response.Usage)
+	}
+
 	// Normalize tool calls across providers (fixes missing type, non-string arguments, missing IDs)
 	response.ToolCalls = llm.NormalizeToolCallIDs(response.ToolCalls)
 
+	// Models without a native reasoning field put it in <think> blocks; show
+	// and store it as reasoning instead of as part of the answer
+	if inline, content := llm.SplitThinkTags(response.Content); inline != "" {
+		response.Content = content
+		if response.Reasoning != "" {
+			inline = response.Reasoning + "\n\n" + inline
+		}
+		response.Reasoning = inline
+	}
+
 	outcome.Response = response
 	outcome.Content = response.Content
 	outcome.Reasoning = response.Reasoning
@@ -405,11 +448,45 @@ func (i *orchestratorIteration) Execute(ctx context.Context, state loop.State) (
 	if !outcome.HasToolCalls {
 		outcome.Result = loop.Break
 	} else {
-		// Execute tool calls
-		if err := i.orch.processToolCalls(ctx, response.ToolCalls, i.orch.session, i.progressCallback, i.authCallback, i.toolCallCallback, i.toolResultCallback, nil); err != nil {
+		// Execute tool calls, noting which of them failed
+		var failuresMu sync.Mutex
+		failures := make(map[string]string)
+		toolResultCallback := func(toolName, toolID, result, errorMsg string) error {
+			if errorMsg != "" {
+				failuresMu.Lock()
+				failures[toolID] = errorMsg
+				failuresMu.Unlock()
+			}
+			if i.toolResultCallback != nil {
+				return i.toolResultCallback(toolName, toolID, result, errorMsg)
+			}
+			return nil
+		}
+		if err := i.orch.processToolCalls(ctx, response.ToolCalls, i.orch.session, i.progressCallback, i.authCallback, i.toolCallCallback, toolResultCallback, nil); err != nil {
 			logger.Warn("Error processing tool calls: %v", err)
 		}
 		outcome.Result = loop.Continue
+
+		// A call that still fails after the model was told to change
+		// approach stops the loop
+		if repeated := i.failingCalls.record(response.ToolCalls, failures); repeated != nil {
+			if repeated.count > i.failingCalls.threshold {
+				logger.Warn("Stopping loop: %s", repeated.pattern())
+				outcome.Result = loop.BreakLoopDetected
+				outcome.Metadata = map[string]interface{}{
+					"loop_pattern": repeated.pattern(),
+					"loop_count":   repeated.count,
+				}
+				return outcome, nil
+			}
+
+			notice := repeated.notice()
+			sendStream(fmt.Sprintf("\n⚠️ %s\n", notice), false)
+			i.orch.session.AddMessage(&session.Message{
+				Role:    "system",
+				Content: notice,
+			})
+		}
 	}
 
 	return outcome, nil
@@ -465,17 +542,9 @@ func (o *Orchestrator) createLoopStrategy(config *loop.Config) loop.Strategy {
 
 	// Check if we should use LLM judge strategy
 	if strategyMode == "llm-judge" && config.EnableLLMAutoContinueJudge {
-		// Use summarization client for LLM judge (faster/cheaper than orchestration model)
-		llmClient := o.summarizeClient
-		if llmClient == nil {
-			// Fall back to orchestration client if summarization client is not available
-			llmClient = o.orchestrationClient
-		}
-
-		modelID := o.providerMgr.GetSummarizeModel()
-		if modelID == "" {
-			modelID = o.providerMgr.GetOrchestrationModel()
-		}
+		// Use the configured judge model, else the summarization client
+		// (faster/cheaper than the orchestration model)
+		llmClient, modelID := o.newAutoContinueJudgeClient()
 
 		// Create session adapter for the strategy
 		session := newSessionAdapter(o.session)
@@ -498,30 +567,33 @@ func (o *Orchestrator) initializeLoop() error {
 	// Build configuration from orchestrator settings
 	o.loopConfig = o.buildLoopConfig()
 
+	// The actual iteration will be created per-request with callbacks
+	o.loop = loop.NewOrchestratorLoop(o.loopConfig, o.createLoopStrategy(o.loopConfig), nil, o.newLoopDependencies(o.currentProgressCb))
+
+	return nil
+}
+
+// newLoopDependencies wires the orchestrator's session, tools, prompts and
+// context management into loop dependencies
+func (o *Orchestrator) newLoopDependencies(progressCallback progress.Callback) *loop.Dependencies {
 	deps := &loop.Dependencies{
 		LLMClient:            o.orchestrationClient,
 		Session:              newSessionAdapter(o.session),
 		ToolRegistry:         newOrchestratorToolRegistry(o),
 		SystemPromptProvider: newOrchestratorSystemPromptProvider(o),
 		ContextManager:       newOrchestratorContextManager(o),
-		ProgressCallback:     o.currentProgressCb,
+		ProgressCallback:     progressCallback,
 	}
 
 	// Set reasoning effort from model config if available
 	if o.providerMgr != nil {
-		modelID := o.providerMgr.GetOrchestrationModel()
+		modelID := o.orchestrationModelID()
 		if model, ok := o.providerMgr.GetModel(modelID); ok && model.ReasoningEffort != "" {
 			deps.ReasoningEffort = model.ReasoningEffort
 		}
 	}
 
-	// Create strategy based on configuration
-	strategy := o.createLoopStrategy(o.loopConfig)
-
-	// The actual iteration will be created per-request with callbacks
-	o.loop = loop.NewOrchestratorLoop(o.loopConfig, strategy, nil, deps)
-
-	return nil
+	return deps
 }
 
 // SetLoopStrategy sets the loop strategy mode ("default", "conservative", "aggressive")
@@ -531,7 +603,9 @@ func (o *Orchestrator) SetLoopStrategy(strategy string) {
 	}
 }
 
-// runOrchestrationLoopWithAbstraction uses the new loop abstraction
+// runOrchestrationLoopWithAbstraction runs the prompt through the loop
+// abstraction: an orchestratorIteration performs each LLM call and its tool
+// executions, while the configured strategy decides when to stop
 func (o *Orchestrator) runOrchestrationLoopWithAbstraction(
 	ctx context.Context,
 	progressCallback progress.Callback,
@@ -539,46 +613,24 @@ func (o *Orchestrator) runOrchestrationLoopWithAbstraction(
 	authCallback AuthorizationCallback,
 	toolCallCallback ToolCallCallback,
 	toolResultCallback ToolResultCallback,
-) error {
-	if o.loop == nil {
-		if err := o.initializeLoop(); err != nil {
-			return fmt.Errorf("failed to initialize loop: %w", err)
-		}
+) (*loop.Result, error) {
+	if o.loopConfig == nil {
+		o.loopConfig = o.buildLoopConfig()
 	}
 
-	// Create the iteration with the current callbacks
+	// Each prompt gets a fresh loop (and state) with the current callbacks;
+	// the strategy is created anew so configuration changes take effect
 	iteration := newOrchestratorIteration(o, progressCallback, authCallback, toolCallCallback, toolResultCallback, contextCallback)
-
-	// Set the iteration on the loop
-	if _, ok := o.loop.(*loop.OrchestratorLoop); ok {
-		// We need to create a new loop with the proper iteration
-		strategy := o.createLoopStrategy(o.loopConfig)
-		deps := &loop.Dependencies{
-			LLMClient:            o.orchestrationClient,
-			Session:              newSessionAdapter(o.session),
-			ToolRegistry:         newOrchestratorToolRegistry(o),
-			SystemPromptProvider: newOrchestratorSystemPromptProvider(o),
-			ContextManager:       newOrchestratorContextManager(o),
-			ProgressCallback:     progressCallback,
-		}
-		// Set reasoning effort from model config if available
-		if o.providerMgr != nil {
-			modelID := o.providerMgr.GetOrchestrationModel()
-			if model, ok := o.providerMgr.GetModel(modelID); ok && model.ReasoningEffort != "" {
-				deps.ReasoningEffort = model.ReasoningEffort
-			}
-		}
-		o.loop = loop.NewOrchestratorLoop(o.loopConfig, strategy, iteration, deps)
-	}
+	o.loop = loop.NewOrchestratorLoop(o.loopConfig, o.createLoopStrategy(o.loopConfig), iteration, o.newLoopDependencies(progressCallback))
 
 	result, err := o.loop.Run(ctx, newSessionAdapter(o.session), progressCallback)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	if result.HitIterationLimit {
 		logger.Warn("Orchestration loop reached maximum iteration limit")
 	}
 
-	return nil
+	return result, nil
 }
@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/orchestrator/loop"
+)
+
+func scriptedToolCall(id string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		Content: "Checking " + id,
+		ToolCalls: []map[string]interface{}{
+			{
+				"id":   id,
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      "todo",
+					"arguments": `{"action":"list"}`,
+				},
+			},
+		},
+		StopReason: "tool_use",
+	}
+}
+
+func scriptedAnswer(content string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{Content: content, StopReason: "stop"}
+}
+
+// TestProcessPromptScriptedScenarios runs scripted conversations through
+// ProcessPrompt with every loop strategy. The transcripts are those of the
+// former inline loop: one assistant message per LLM call, followed by the
+// tool results of its calls, ending at the first answer without tool calls.
+func TestProcessPromptScriptedScenarios(t *testing.T) {
+	scenarios := []struct {
+		name      string
+		responses []*llm.CompletionResponse
+		requests  int
+		roles     []string
+	}{
+		{
+			name:      "plain answer",
+			responses: []*llm.CompletionResponse{scriptedAnswer("Hello.")},
+			requests:  1,
+			roles:     []string{"user", "assistant"},
+		},
+		{
+			name:      "tool call then answer",
+			responses: []*llm.CompletionResponse{scriptedToolCall("call_1"), scriptedAnswer("No todos.")},
+			requests:  2,
+			roles:     []string{"user", "assistant", "tool", "assistant"},
+		},
+		{
+			name: "two tool rounds",
+			responses: []*llm.CompletionResponse{
+				scriptedToolCall("call_1"),
+				scriptedToolCall("call_2"),
+				scriptedAnswer("Still no todos."),
+			},
+			requests: 3,
+			roles:    []string{"user", "assistant", "tool", "assistant", "tool", "assistant"},
+		},
+	}
+
+	for _, strategy := range []string{"default", "conservative", "aggressive"} {
+		for _, scenario := range scenarios {
+			t.Run(fmt.Sprintf("%s/%s", strategy, scenario.name), func(t *testing.T) {
+				orch := createTestOrchestrator(t)
+				defer func() {
+					_ = orch.Close()
+				}()
+				orch.featureFlags.SetPlanningEnabled(false)
+				orch.SetLoopStrategy(strategy)
+
+				client := newSequentialMockClient(scenario.responses...)
+				orch.orchestrationClient = client
+
+				if err := orch.ProcessPrompt(context.Background(), "list the todos", nil, nil, nil, nil, nil, nil); err != nil {
+					t.Fatalf("ProcessPrompt failed: %v", err)
+				}
+
+				if client.RequestCount() != scenario.requests {
+					t.Errorf("expected %d LLM requests, got %d", scenario.requests, client.RequestCount())
+				}
+
+				var roles []string
+				for _, msg := range orch.session.GetMessages() {
+					roles = append(roles, msg.Role)
+				}
+				if !reflect.DeepEqual(roles, scenario.roles) {
+					t.Errorf("expected transcript %v, got %v", scenario.roles, roles)
+				}
+
+				if _, ok := orch.loop.(*loop.OrchestratorLoop); !ok {
+					t.Errorf("expected the prompt to run through loop.OrchestratorLoop, got %T", orch.loop)
+				}
+			})
+		}
+	}
+}
+
+func TestCreateLoopStrategyFromConfig(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+
+	tests := []struct {
+		mode string
+		want loop.Strategy
+	}{
+		{"", &loop.DefaultStrategy{}},
+		{"default", &loop.DefaultStrategy{}},
+		{"conservative", &loop.ConservativeStrategy{}},
+		{"aggressive", &loop.AggressiveStrategy{}},
+		// Without an enabled judge the LLM judge falls back to the default
+		{"llm-judge", &loop.DefaultStrategy{}},
+	}
+	for _, tt := range tests {
+		orch.SetLoopStrategy(tt.mode)
+		got := orch.createLoopStrategy(orch.buildLoopConfig())
+		if reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+			t.Errorf("strategy %q: expected %T, got %T", tt.mode, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/provider"
+)
+
+// modelOverrides holds models selected for this orchestrator only (e.g. the
+// defaults of a socket workspace). Empty values fall back to the provider
+// manager's global selection.
+type modelOverrides struct {
+	mu            sync.RWMutex
+	orchestration string
+	summarize     string
+}
+
+// orchestrationModelID returns the orchestration model used by this orchestrator
+func (o *Orchestrator) orchestrationModelID() string {
+	o.models.mu.RLock()
+	modelID := o.models.orchestration
+	o.models.mu.RUnlock()
+	if modelID != "" {
+		return modelID
+	}
+	return o.providerMgr.GetOrchestrationModel()
+}
+
+// summarizeModelID returns the configured summarize model, which may be empty
+// (callers then fall back to the orchestration model)
+func (o *Orchestrator) summarizeModelID() string {
+	o.models.mu.RLock()
+	modelID := o.models.summarize
+	o.models.mu.RUnlock()
+	if modelID != "" {
+		return modelID
+	}
+	return o.providerMgr.GetSummarizeModel()
+}
+
+// SetModelOverrides selects the orchestration and summarize models for this
+// orchestrator, overriding the provider manager's selection. An empty model ID
+// keeps the global selection. The LLM clients are recreated when the selection
+// changes.
+func (o *Orchestrator) SetModelOverrides(orchestrationModel, summarizeModel string) error {
+	o.models.mu.Lock()
+	changed := o.models.orchestration != orchestrationModel || o.models.summarize != summarizeModel
+	o.models.orchestration = orchestrationModel
+	o.models.summarize = summarizeModel
+	o.models.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	// Drop the summarize client so it is rebuilt (or falls back to the new
+	// orchestration client) instead of keeping the previous model
+	o.clientInitMu.Lock()
+	o.summarizeClient = nil
+	o.clientInitMu.Unlock()
+
+	o.systemPromptMu.Lock()
+	o.cachedSystemPrompt = ""
+	o.systemPromptMu.Unlock()
+
+	logger.Info("Model overrides set: orchestration=%q summarize=%q", orchestrationModel, summarizeModel)
+	return o.initializeClients()
+}
+
+// UseModelForPrompt switches the orchestration model to modelID until the
+// returned restore function is called, e.g. for a single socket generation.
+// A transient client is created for the model; the summarize model and the
+// overrides set by SetModelOverrides are kept. If the model was changed in the
+// meantime, restoring keeps the newer selection. Unknown models yield a
+// *provider.UnknownModelError.
+func (o *Orchestrator) UseModelForPrompt(modelID string) (func(), error) {
+	if _, ok := o.providerMgr.GetModel(modelID); !ok {
+		return nil, &provider.UnknownModelError{Model: modelID}
+	}
+	client, err := o.providerMgr.CreateClient(modelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %w", modelID, err)
+	}
+
+	o.models.mu.Lock()
+	previousModel := o.models.orchestration
+	o.models.orchestration = modelID
+	o.models.mu.Unlock()
+
+	o.clientInitMu.Lock()
+	previousClient := o.orchestrationClient
+	o.orchestrationClient = client
+	o.clientInitMu.Unlock()
+
+	o.systemPromptMu.Lock()
+	o.cachedSystemPrompt = ""
+	o.systemPromptMu.Unlock()
+
+	logger.Info("Using model %s for this prompt", modelID)
+	return func() {
+		o.models.mu.Lock()
+		stillOverridden := o.models.orchestration == modelID
+		if stillOverridden {
+			o.models.orchestration = previousModel
+		}
+		o.models.mu.Unlock()
+		if !stillOverridden {
+			logger.Debug("Model changed to %s during the prompt; not restoring %s", o.orchestrationModelID(), previousModel)
+			return
+		}
+
+		o.clientInitMu.Lock()
+		o.orchestrationClient = previousClient
+		o.clientInitMu.Unlock()
+
+		o.systemPromptMu.Lock()
+		o.cachedSystemPrompt = ""
+		o.systemPromptMu.Unlock()
+	}, nil
+}
+
+// errorJudgeModelID returns the model used by the error judge: the configured
+// error judge model, else the summarize model, else the orchestration model
+func (o *Orchestrator) errorJudgeModelID() string {
+	if o.config != nil && o.config.ErrorJudgeModel != "" {
+		return o.config.ErrorJudgeModel
+	}
+	return o.getSummarizeModelID()
+}
+
+// newErrorJudgeClient returns the LLM client for the error judge actor. Without
+// a configured error judge model, or if its client cannot be created, the
+// summarize client is used (which itself falls back to the orchestration client).
+func (o *Orchestrator) newErrorJudgeClient() llm.Client {
+	if o.config == nil || o.config.ErrorJudgeModel == "" || o.providerMgr == nil {
+		return o.summarizeClient
+	}
+
+	client, err := o.providerMgr.CreateClient(o.config.ErrorJudgeModel)
+	if err != nil {
+		logger.Warn("Failed to create error judge client for %s, using summarize model: %v", o.config.ErrorJudgeModel, err)
+		return o.summarizeClient
+	}
+	return client
+}
+
+// autoContinueJudgeModelID returns the model used by the LLM auto-continue
+// judge: the configured judge model, else the summarize model, else the
+// orchestration model
+func (o *Orchestrator) autoContinueJudgeModelID() string {
+	if o.config != nil && o.config.Loop.AutoContinueJudgeModel != "" {
+		return o.config.Loop.AutoContinueJudgeModel
+	}
+	return o.getSummarizeModelID()
+}
+
+// newAutoContinueJudgeClient returns the LLM client for the auto-continue
+// judge. Without a configured judge model, or if its client cannot be created,
+// the summarize client is used (falling back to the orchestration client).
+func (o *Orchestrator) newAutoContinueJudgeClient() (llm.Client, string) {
+	fallback := func() (llm.Client, string) {
+		if o.summarizeClient != nil {
+			return o.summarizeClient, o.getSummarizeModelID()
+		}
+		return o.orchestrationClient, o.getSummarizeModelID()
+	}
+
+	if o.config == nil || o.config.Loop.AutoContinueJudgeModel == "" || o.providerMgr == nil {
+		return fallback()
+	}
+
+	client, err := o.providerMgr.CreateClient(o.config.Loop.AutoContinueJudgeModel)
+	if err != nil {
+		logger.Warn("Failed to create auto-continue judge client for %s, using summarize model: %v", o.config.Loop.AutoContinueJudgeModel, err)
+		return fallback()
+	}
+	return client, o.autoContinueJudgeModelID()
+}
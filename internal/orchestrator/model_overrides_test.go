@@ -0,0 +1,182 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/provider"
+)
+
+func newErrorJudgeTestOrchestrator(t *testing.T, summarizeModel, errorJudgeModel string) *Orchestrator {
+	t.Helper()
+
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	_ = providerMgr.AddProvider("openai", "test-key", []*provider.Model{
+		{ID: "gpt-4", Name: "GPT-4", Provider: "openai"},
+		{ID: "gpt-4o-mini", Name: "GPT-4o mini", Provider: "openai"},
+		{ID: "o3", Name: "o3", Provider: "openai"},
+	})
+	_ = providerMgr.SetOrchestrationModel("gpt-4")
+	if summarizeModel != "" {
+		_ = providerMgr.SetSummarizeModel(summarizeModel)
+	}
+
+	orch := &Orchestrator{
+		config:      &config.Config{ErrorJudgeModel: errorJudgeModel},
+		providerMgr: providerMgr,
+	}
+	if err := orch.initializeClients(); err != nil {
+		t.Fatalf("Failed to initialize clients: %v", err)
+	}
+	return orch
+}
+
+func TestErrorJudgeModelSelection(t *testing.T) {
+	tests := []struct {
+		name            string
+		summarizeModel  string
+		errorJudgeModel string
+		want            string
+	}{
+		{"configured error judge model", "gpt-4o-mini", "o3", "o3"},
+		{"falls back to summarize model", "gpt-4o-mini", "", "gpt-4o-mini"},
+		{"falls back to orchestration model", "", "", "gpt-4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orch := newErrorJudgeTestOrchestrator(t, tt.summarizeModel, tt.errorJudgeModel)
+
+			if got := orch.errorJudgeModelID(); got != tt.want {
+				t.Errorf("errorJudgeModelID() = %q, want %q", got, tt.want)
+			}
+			client := orch.newErrorJudgeClient()
+			if client == nil {
+				t.Fatal("expected an error judge client")
+			}
+			if got := client.GetModelName(); got != tt.want {
+				t.Errorf("error judge client model = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorJudgeClientFallsBackWhenModelUnavailable(t *testing.T) {
+	orch := newErrorJudgeTestOrchestrator(t, "gpt-4o-mini", "unknown-model")
+
+	client := orch.newErrorJudgeClient()
+	if client == nil || client != orch.summarizeClient {
+		t.Errorf("expected the summarize client when the error judge model cannot be created, got %v", client)
+	}
+}
+
+func TestAutoContinueJudgeModelSelection(t *testing.T) {
+	orch := newErrorJudgeTestOrchestrator(t, "gpt-4o-mini", "")
+
+	client, modelID := orch.newAutoContinueJudgeClient()
+	if client != orch.summarizeClient || modelID != "gpt-4o-mini" {
+		t.Errorf("expected the summarize model without a judge model, got %q", modelID)
+	}
+
+	orch.config.Loop.AutoContinueJudgeModel = "o3"
+	client, modelID = orch.newAutoContinueJudgeClient()
+	if client == nil || client.GetModelName() != "o3" || modelID != "o3" {
+		t.Errorf("expected the configured judge model, got %q", modelID)
+	}
+
+	orch.config.Loop.AutoContinueJudgeModel = "unknown-model"
+	if client, _ := orch.newAutoContinueJudgeClient(); client != orch.summarizeClient {
+		t.Errorf("expected the summarize client when the judge model cannot be created")
+	}
+}
+
+func TestBuildLoopConfigAutoContinue(t *testing.T) {
+	orch := newErrorJudgeTestOrchestrator(t, "", "")
+
+	orch.config = config.DefaultConfig()
+	orch.config.Loop.EnableAutoContinue = true
+	orch.config.Loop.MaxAutoContinueAttempts = 12
+	loopCfg := orch.buildLoopConfig()
+	if !loopCfg.EnableAutoContinue {
+		t.Error("expected auto-continue to be enabled")
+	}
+	if loopCfg.MaxAutoContinueAttempts != 12 {
+		t.Errorf("expected 12 auto-continue attempts, got %d", loopCfg.MaxAutoContinueAttempts)
+	}
+
+	orch.config.Loop.EnableAutoContinue = false
+	if loopCfg := orch.buildLoopConfig(); loopCfg.EnableAutoContinue {
+		t.Error("expected auto-continue to be disabled")
+	}
+}
+
+func TestUseModelForPromptRestoresSessionModel(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+	_ = orch.providerMgr.AddProvider("openai", "test-key", []*provider.Model{
+		{ID: "gpt-4", Name: "GPT-4", Provider: "openai"},
+		{ID: "o3", Name: "o3", Provider: "openai"},
+		{ID: "gpt-4o", Name: "GPT-4o", Provider: "openai"},
+	})
+	if err := orch.SetModelOverrides("gpt-4", ""); err != nil {
+		t.Fatalf("SetModelOverrides failed: %v", err)
+	}
+	sessionClient := newSequentialMockClient(scriptedAnswer("Unused."))
+	orch.orchestrationClient = sessionClient
+
+	var unknown *provider.UnknownModelError
+	if _, err := orch.UseModelForPrompt("missing-model"); !errors.As(err, &unknown) {
+		t.Fatalf("expected an UnknownModelError, got %v", err)
+	}
+
+	restore, err := orch.UseModelForPrompt("o3")
+	if err != nil {
+		t.Fatalf("UseModelForPrompt failed: %v", err)
+	}
+	if got := orch.orchestrationClient.GetModelName(); got != "o3" {
+		t.Errorf("expected a transient o3 client, got %q", got)
+	}
+	// Stand in for the transient client so no request leaves the test
+	promptClient := newSequentialMockClient(scriptedAnswer("Reviewed."))
+	orch.orchestrationClient = promptClient
+	if err := orch.ProcessPrompt(context.Background(), "review this", nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("ProcessPrompt failed: %v", err)
+	}
+	restore()
+
+	if promptClient.RequestCount() != 1 || sessionClient.RequestCount() != 0 {
+		t.Errorf("expected the prompt to use the override, got %d override and %d session requests",
+			promptClient.RequestCount(), sessionClient.RequestCount())
+	}
+	if got := orch.orchestrationModelID(); got != "gpt-4" {
+		t.Errorf("expected the session model gpt-4 after the prompt, got %q", got)
+	}
+	if orch.orchestrationClient != sessionClient {
+		t.Error("expected the session client to be restored")
+	}
+
+	// A model selected while the prompt runs survives the restore
+	restore, err = orch.UseModelForPrompt("o3")
+	if err != nil {
+		t.Fatalf("UseModelForPrompt failed: %v", err)
+	}
+	if err := orch.SetModelOverrides("gpt-4o", ""); err != nil {
+		t.Fatalf("SetModelOverrides failed: %v", err)
+	}
+	selectedClient := orch.orchestrationClient
+	restore()
+	if got := orch.orchestrationModelID(); got != "gpt-4o" {
+		t.Errorf("expected the model selected during the prompt to stay, got %q", got)
+	}
+	if orch.orchestrationClient != selectedClient {
+		t.Error("expected the client of the newer selection to stay")
+	}
+}
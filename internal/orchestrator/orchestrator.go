@@ -9,8 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-	"unicode"
 
 	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/config"
@@ -24,6 +24,7 @@ import (
 	"github.com/codefionn/scriptschnell/internal/orchestrator/loop"
 	"github.com/codefionn/scriptschnell/internal/planning"
 	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/prompttemplate"
 	"github.com/codefionn/scriptschnell/internal/provider"
 	"github.com/codefionn/scriptschnell/internal/safety"
 	"github.com/codefionn/scriptschnell/internal/sandbox"
@@ -34,9 +35,24 @@ import (
 	"github.com/codefionn/scriptschnell/internal/vcs"
 )
 
+// AuthorizationResponse is the user's decision on a tool authorization
+type AuthorizationResponse struct {
+	Approved bool
+	// EditedParams replace the tool's parameters before it runs, e.g. to add
+	// --dry-run to a shell command; nil runs the tool as requested
+	EditedParams map[string]interface{}
+}
+
 // AuthorizationCallback is called when a tool requires user authorization
-// It should return true if approved, false if denied
-type AuthorizationCallback func(toolName string, params map[string]interface{}, reason string) (bool, error)
+type AuthorizationCallback func(toolName string, params map[string]interface{}, reason string) (AuthorizationResponse, error)
+
+// ApprovalOnly adapts a callback that can only approve or deny
+func ApprovalOnly(fn func(toolName string, params map[string]interface{}, reason string) (bool, error)) AuthorizationCallback {
+	return func(toolName string, params map[string]interface{}, reason string) (AuthorizationResponse, error) {
+		approved, err := fn(toolName, params, reason)
+		return AuthorizationResponse{Approved: approved}, err
+	}
+}
 
 // ToolCallCallback is called when a tool is being executed
 type ToolCallCallback func(toolName, toolID string, parameters map[string]interface{}) error
@@ -62,11 +78,14 @@ type Orchestrator struct {
 	safetyEvaluator        *safety.Evaluator
 	config                 *config.Config
 	workingDir             string
+	activePrompts          atomic.Int32 // prompts currently inside ProcessPrompt
 	ctx                    context.Context
-	cancel                 context.CancelFunc
+	cancel                 context.CancelCauseFunc
 	actorSystem            *actor.System
 	authorizer             tools.Authorizer
+	authOptions            *tools.AuthorizationOptions // Options the authorization actor was started with
 	actorCancel            context.CancelFunc
+	workspaceMu            sync.RWMutex // Held for reading by prompts, for writing while SetWorkingDir swaps fs, workingDir, mcpManager and authorizer
 	compactionMu           sync.Mutex
 	compactionInProgress   bool
 	cliMode                bool
@@ -98,6 +117,7 @@ type Orchestrator struct {
 	lastPreconnectAttempt  time.Time
 	preconnectCompleted    bool
 	clientInitMu           sync.Mutex
+	models                 modelOverrides
 	cachedSystemPrompt     string
 	systemPromptMu         sync.RWMutex
 	healthManager          *actor.SessionHealthManager
@@ -107,6 +127,7 @@ type Orchestrator struct {
 	compactionAttemptCount int // Tracks compaction attempts for current request
 	compactionAttemptMu    sync.Mutex
 	consecutiveCompactions int // Tracks consecutive compactions to limit to 2 in immediate succession
+	totalCompactions       int // Counts all compactions, for prompt results
 	lastCompactionTime     time.Time
 	userInputCb            UserInputCallback
 	userInteractionRef     *actor.ActorRef
@@ -126,6 +147,14 @@ type Orchestrator struct {
 	// Planning user message channel - allows UI to inject messages during planning
 	planningUserMsgChan   chan string
 	planningUserMsgChanMu sync.RWMutex
+	// Pause/resume between loop iterations
+	pause pauseGate
+	// LLM retries shared by all calls of the current prompt run
+	retryBudget runRetryBudget
+	retrySleep  func(ctx context.Context, d time.Duration) error // Overrides the retry backoff in tests
+	// Execution metadata of the tool results currently being delivered
+	toolResultMetadata   map[string]*tools.ExecutionMetadata
+	toolResultMetadataMu sync.RWMutex
 }
 
 const (
@@ -243,10 +272,23 @@ func NewOrchestratorWithFSAndTodoActor(cfg *config.Config, providerMgr *provider
 	return NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg, providerMgr, cliMode, customFS, customTodoActor, false)
 }
 
+// loadAuthorizationPolicy loads the authorization policy file configured in
+// cfg, if any
+func loadAuthorizationPolicy(cfg *config.Config) (*tools.AuthorizationPolicy, error) {
+	if cfg == nil || cfg.AuthorizationPolicy == "" {
+		return nil, nil
+	}
+	return tools.LoadAuthorizationPolicy(cfg.AuthorizationPolicy)
+}
+
 // NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth creates a new orchestrator with custom filesystem, todo actor, and sandbox auth requirement
 func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config, providerMgr *provider.Manager, cliMode bool, customFS fs.FileSystem, customTodoActor tools.TodoActorInterface, requireSandboxAuth bool) (*Orchestrator, error) {
 	logger.Debug("Creating new orchestrator with working_dir=%s, cliMode=%v, requireSandboxAuth=%v", cfg.WorkingDir, cliMode, requireSandboxAuth)
-	ctx, cancel := context.WithCancel(context.Background())
+	authPolicy, err := loadAuthorizationPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
 
 	// Create filesystem (use custom if provided, otherwise create default)
 	var filesystem fs.FileSystem
@@ -295,52 +337,12 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 	}
 
 	// Set up authorization actor with summarize client
-	authorizationCtx, authorizationCancel := context.WithCancel(context.Background())
-	allowedCommandPrefixes := make([]string, 0, len(cfg.AuthorizedCommands))
-	for prefix, enabled := range cfg.AuthorizedCommands {
-		if enabled {
-			allowedCommandPrefixes = append(allowedCommandPrefixes, prefix)
-		}
-	}
-
-	allowedDomainPatterns := make([]string, 0, len(cfg.AuthorizedDomains))
-	for domain, enabled := range cfg.AuthorizedDomains {
-		if enabled {
-			allowedDomainPatterns = append(allowedDomainPatterns, domain)
-		}
-	}
-
-	// Allow reading from sandbox output directory so the LLM can read large output files
-	var allowedDirs []string
-	if outputDir := sess.GetSandboxOutputDir(); outputDir != "" {
-		allowedDirs = append(allowedDirs, outputDir)
-	}
-
-	authOpts := &tools.AuthorizationOptions{
-		AllowedCommands:    allowedCommandPrefixes,
-		AllowedDomains:     allowedDomainPatterns,
-		AllowedDirs:        allowedDirs,
-		RequireSandboxAuth: requireSandboxAuth,
-	}
-
-	authActor := tools.NewAuthorizationActor("authorization", filesystem, sess, orch.summarizeClient, authOpts)
-	authRef, err := orch.actorSystem.Spawn(authorizationCtx, "authorization", authActor, 32)
-	if err != nil {
-		authorizationCancel()
-		cancel()
+	authOpts := authorizationOptions(cfg, sess, requireSandboxAuth, authPolicy)
+	if err := orch.startAuthorizationActor(authOpts); err != nil {
+		cancel(nil)
 		logger.Error("Failed to start authorization actor: %v", err)
-		return nil, fmt.Errorf("failed to start authorization actor: %w", err)
-	}
-	logger.Debug("Authorization actor spawned")
-	baseAuthorizer := tools.NewAuthorizationActorClient(authRef)
-
-	// Create secret detector and wrap authorizer for secret-based authorization
-	if features.Enabled["secret_based_auth"] {
-		orch.authorizer = tools.NewSecretAwareAuthorizer(baseAuthorizer, authActor)
-	} else {
-		orch.authorizer = baseAuthorizer
+		return nil, err
 	}
-	orch.actorCancel = authorizationCancel
 
 	// Set up todo actor
 	todoCtx, todoCancel := context.WithCancel(context.Background())
@@ -355,8 +357,8 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 	todoRef, err := orch.actorSystem.SpawnWithOptions(todoCtx, "todo", todoActor, 16, actor.WithSequentialProcessing())
 	if err != nil {
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start todo actor: %v", err)
 		return nil, fmt.Errorf("failed to start todo actor: %w", err)
 	}
@@ -371,18 +373,7 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 	orch.todoActorCancel = todoCancel
 
 	// Initialize landlock sandbox for shell command execution
-	var sandboxCfg *sandbox.SandboxConfig
-	if cfg != nil {
-		// Check if Linux sandbox is enabled via feature flags
-		disableSandbox := cfg.Sandbox.DisableSandbox || !orch.featureFlags.IsToolEnabled("linux_sandbox")
-		sandboxCfg = &sandbox.SandboxConfig{
-			AdditionalReadOnlyPaths:  cfg.Sandbox.AdditionalReadOnlyPaths,
-			AdditionalReadWritePaths: cfg.Sandbox.AdditionalReadWritePaths,
-			DisableSandbox:           disableSandbox,
-			BestEffort:               cfg.Sandbox.BestEffort,
-		}
-	}
-	orch.sandbox = sandbox.NewLandlockSandbox(cfg.WorkingDir, sandboxCfg)
+	orch.sandbox = sandbox.NewLandlockSandbox(cfg.WorkingDir, orch.landlockSandboxConfig())
 	if orch.sandbox.IsEnabled() {
 		logger.Info("Landlock sandbox enabled for workspace: %s", cfg.WorkingDir)
 	} else {
@@ -416,8 +407,8 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 	if err != nil {
 		shellCancel()
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start shell actor: %v", err)
 		return nil, fmt.Errorf("failed to start shell actor: %w", err)
 	}
@@ -439,8 +430,8 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 		domainBlockerCancel()
 		shellCancel()
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start domain blocker actor: %v", err)
 		return nil, fmt.Errorf("failed to start domain blocker actor: %w", err)
 	}
@@ -457,8 +448,8 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 	if err != nil {
 		shellCancel()
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to create session storage actor: %v", err)
 		return nil, fmt.Errorf("failed to create session storage actor: %w", err)
 	}
@@ -469,8 +460,8 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 		sessionStorageCancel()
 		shellCancel()
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start session storage actor: %v", err)
 		return nil, fmt.Errorf("failed to start session storage actor: %w", err)
 	}
@@ -527,19 +518,22 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 		}
 	}
 
-	// Set up error judge actor with summarize client
+	// Set up error judge actor with the error judge model
 	errorJudgeCtx, errorJudgeCancel := context.WithCancel(context.Background())
-	errorJudgeActor := tools.NewErrorJudgeActor("error_judge", orch.summarizeClient)
+	errorJudgeActor := tools.NewErrorJudgeActor("error_judge", orch.newErrorJudgeClient())
+	if orch.config != nil {
+		errorJudgeActor.SetPromptTemplate(orch.config.PromptTemplates.ErrorJudge)
+	}
 	errorJudgeRef, err := orch.actorSystem.Spawn(errorJudgeCtx, "error_judge", errorJudgeActor, 8)
 	if err != nil {
 		errorJudgeCancel()
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start error judge actor: %v", err)
 		return nil, fmt.Errorf("failed to start error judge actor: %w", err)
 	}
-	logger.Debug("Error judge actor spawned")
+	logger.Debug("Error judge actor spawned (model: %s)", orch.errorJudgeModelID())
 	orch.errorJudge = tools.NewErrorJudgeActorClient(errorJudgeRef)
 	orch.errorJudgeCancel = errorJudgeCancel
 
@@ -560,8 +554,8 @@ func NewOrchestratorWithFSAndTodoActorAndRequireSandboxAuth(cfg *config.Config,
 		toolExecutorCancel()
 		errorJudgeCancel()
 		todoCancel()
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start tool executor actor: %v", err)
 		return nil, fmt.Errorf("failed to start tool executor actor: %w", err)
 	}
@@ -595,7 +589,11 @@ func NewOrchestratorWithSharedResources(
 	requireSandboxAuth bool,
 ) (*Orchestrator, error) {
 	logger.Debug("Creating orchestrator with shared resources for session %s, requireSandboxAuth=%v", sess.ID, requireSandboxAuth)
-	ctx, cancel := context.WithCancel(context.Background())
+	authPolicy, err := loadAuthorizationPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
 
 	// Use provided shared filesystem
 	filesystem := sharedFS
@@ -625,52 +623,12 @@ func NewOrchestratorWithSharedResources(
 	}
 
 	// Set up authorization actor with summarize client
-	authorizationCtx, authorizationCancel := context.WithCancel(context.Background())
-	allowedCommandPrefixes := make([]string, 0, len(cfg.AuthorizedCommands))
-	for prefix, enabled := range cfg.AuthorizedCommands {
-		if enabled {
-			allowedCommandPrefixes = append(allowedCommandPrefixes, prefix)
-		}
-	}
-
-	allowedDomainPatterns := make([]string, 0, len(cfg.AuthorizedDomains))
-	for domain, enabled := range cfg.AuthorizedDomains {
-		if enabled {
-			allowedDomainPatterns = append(allowedDomainPatterns, domain)
-		}
-	}
-
-	// Allow reading from sandbox output directory so the LLM can read large output files
-	var allowedDirs []string
-	if outputDir := sess.GetSandboxOutputDir(); outputDir != "" {
-		allowedDirs = append(allowedDirs, outputDir)
-	}
-
-	authOpts := &tools.AuthorizationOptions{
-		AllowedCommands:    allowedCommandPrefixes,
-		AllowedDomains:     allowedDomainPatterns,
-		AllowedDirs:        allowedDirs,
-		RequireSandboxAuth: requireSandboxAuth,
-	}
-
-	authActor := tools.NewAuthorizationActor("authorization", filesystem, sess, orch.summarizeClient, authOpts)
-	authRef, err := orch.actorSystem.Spawn(authorizationCtx, "authorization", authActor, 32)
-	if err != nil {
-		authorizationCancel()
-		cancel()
+	authOpts := authorizationOptions(cfg, sess, requireSandboxAuth, authPolicy)
+	if err := orch.startAuthorizationActor(authOpts); err != nil {
+		cancel(nil)
 		logger.Error("Failed to start authorization actor: %v", err)
-		return nil, fmt.Errorf("failed to start authorization actor: %w", err)
-	}
-	logger.Debug("Authorization actor spawned")
-	baseAuthorizer := tools.NewAuthorizationActorClient(authRef)
-
-	// Create secret detector and wrap authorizer for secret-based authorization
-	if features.Enabled["secret_based_auth"] {
-		orch.authorizer = tools.NewSecretAwareAuthorizer(baseAuthorizer, authActor)
-	} else {
-		orch.authorizer = baseAuthorizer
+		return nil, err
 	}
-	orch.actorCancel = authorizationCancel
 
 	// Set up todo actor (shared if provided)
 	var todoCancel context.CancelFunc
@@ -689,8 +647,8 @@ func NewOrchestratorWithSharedResources(
 			if todoCancel != nil {
 				todoCancel()
 			}
-			authorizationCancel()
-			cancel()
+			orch.actorCancel()
+			cancel(nil)
 			logger.Error("Failed to start todo actor: %v", err)
 			return nil, fmt.Errorf("failed to start todo actor: %w", err)
 		}
@@ -701,18 +659,7 @@ func NewOrchestratorWithSharedResources(
 	}
 
 	// Initialize landlock sandbox for shell command execution
-	var sandboxCfg *sandbox.SandboxConfig
-	if cfg != nil {
-		// Check if Linux sandbox is enabled via feature flags
-		disableSandbox := cfg.Sandbox.DisableSandbox || !orch.featureFlags.IsToolEnabled("linux_sandbox")
-		sandboxCfg = &sandbox.SandboxConfig{
-			AdditionalReadOnlyPaths:  cfg.Sandbox.AdditionalReadOnlyPaths,
-			AdditionalReadWritePaths: cfg.Sandbox.AdditionalReadWritePaths,
-			DisableSandbox:           disableSandbox,
-			BestEffort:               cfg.Sandbox.BestEffort,
-		}
-	}
-	orch.sandbox = sandbox.NewLandlockSandbox(cfg.WorkingDir, sandboxCfg)
+	orch.sandbox = sandbox.NewLandlockSandbox(cfg.WorkingDir, orch.landlockSandboxConfig())
 	if orch.sandbox.IsEnabled() {
 		logger.Info("Landlock sandbox enabled for workspace: %s", cfg.WorkingDir)
 	} else {
@@ -750,8 +697,8 @@ func NewOrchestratorWithSharedResources(
 		if todoCancel != nil {
 			todoCancel()
 		}
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start shell actor: %v", err)
 		return nil, fmt.Errorf("failed to start shell actor: %w", err)
 	}
@@ -819,21 +766,24 @@ func NewOrchestratorWithSharedResources(
 		cancel()
 	}
 
-	// Set up error judge actor with summarize client
+	// Set up error judge actor with the error judge model
 	errorJudgeCtx, errorJudgeCancel := context.WithCancel(context.Background())
-	errorJudgeActor := tools.NewErrorJudgeActor("error_judge", orch.summarizeClient)
+	errorJudgeActor := tools.NewErrorJudgeActor("error_judge", orch.newErrorJudgeClient())
+	if orch.config != nil {
+		errorJudgeActor.SetPromptTemplate(orch.config.PromptTemplates.ErrorJudge)
+	}
 	errorJudgeRef, err := orch.actorSystem.Spawn(errorJudgeCtx, "error_judge", errorJudgeActor, 8)
 	if err != nil {
 		errorJudgeCancel()
 		if todoCancel != nil {
 			todoCancel()
 		}
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start error judge actor: %v", err)
 		return nil, fmt.Errorf("failed to start error judge actor: %w", err)
 	}
-	logger.Debug("Error judge actor spawned")
+	logger.Debug("Error judge actor spawned (model: %s)", orch.errorJudgeModelID())
 	orch.errorJudge = tools.NewErrorJudgeActorClient(errorJudgeRef)
 	orch.errorJudgeCancel = errorJudgeCancel
 
@@ -856,8 +806,8 @@ func NewOrchestratorWithSharedResources(
 		if todoCancel != nil {
 			todoCancel()
 		}
-		authorizationCancel()
-		cancel()
+		orch.actorCancel()
+		cancel(nil)
 		logger.Error("Failed to start tool executor actor: %v", err)
 		return nil, fmt.Errorf("failed to start tool executor actor: %w", err)
 	}
@@ -881,8 +831,8 @@ func (o *Orchestrator) initializeClients() error {
 	o.clientInitMu.Lock()
 	defer o.clientInitMu.Unlock()
 
-	orchModelID := o.providerMgr.GetOrchestrationModel()
-	summModelID := o.providerMgr.GetSummarizeModel()
+	orchModelID := o.orchestrationModelID()
+	summModelID := o.summarizeModelID()
 	planModelID := o.providerMgr.GetPlanningModel()
 
 	if orchModelID != "" {
@@ -936,9 +886,9 @@ func (o *Orchestrator) initializeClients() error {
 }
 
 func (o *Orchestrator) getSummarizeModelID() string {
-	modelID := o.providerMgr.GetSummarizeModel()
+	modelID := o.summarizeModelID()
 	if modelID == "" {
-		modelID = o.providerMgr.GetOrchestrationModel()
+		modelID = o.orchestrationModelID()
 	}
 	return modelID
 }
@@ -954,7 +904,7 @@ func (o *Orchestrator) expandFileReferences(ctx context.Context, prompt string)
 	}
 
 	// Get the context window size for the current model
-	modelID := o.providerMgr.GetOrchestrationModel()
+	modelID := o.orchestrationModelID()
 	contextWindow := o.getContextWindow(modelID)
 	if contextWindow <= 0 {
 		contextWindow = 8192 // Default fallback
@@ -1234,10 +1184,26 @@ type toolSpec struct {
 	mcpKey   string
 }
 
+// newResultRedactor creates the redactor for tool results from the redaction
+// settings of this orchestrator, masking the API keys known to its provider
+// manager at the time the tools are built
+func (o *Orchestrator) newResultRedactor() *secretdetect.Redactor {
+	redactor := secretdetect.NewRedactor(o.config.Redaction.Replacement)
+	redactor.SetHighEntropy(o.config.Redaction.HighEntropy)
+	if o.providerMgr != nil {
+		for _, secret := range o.providerMgr.Redactor().KnownSecrets() {
+			redactor.AddKnownSecret(secret)
+		}
+	}
+	return redactor
+}
+
 func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 	var errs []error
 
 	specs := make([]toolSpec, 0, 16)
+	readOnly := o.readOnly()
+	var disabled []string
 	addSpec := func(spec tools.ToolSpec, critical bool, factory tools.ToolFactory, isMCP bool, mcpKey string) {
 		if spec == nil || factory == nil {
 			return
@@ -1246,6 +1212,18 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 		if !critical && !o.featureFlags.IsToolEnabled(spec.Name()) {
 			return
 		}
+		// Read-only sessions only get tools that cannot change anything
+		if readOnly && !tools.IsReadOnlyTool(spec.Name()) {
+			return
+		}
+		// Tools the user disabled in the config, critical ones included
+		if pattern, ok := o.config.DisabledToolPattern(spec.Name(), mcpKey); ok {
+			if critical {
+				logger.Warn("Disabling critical tool %s (matches %q in disabled_tools)", spec.Name(), pattern)
+			}
+			disabled = append(disabled, spec.Name())
+			return
+		}
 		specs = append(specs, toolSpec{
 			spec:     spec,
 			critical: critical,
@@ -1263,7 +1241,7 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 		addSpec(spec, critical, factory, isMCP, mcpKey)
 	}
 
-	modelFamily := llm.DetectModelFamily(o.providerMgr.GetOrchestrationModel())
+	modelFamily := llm.DetectModelFamily(o.orchestrationModelID())
 
 	// Core filesystem tools - using new pattern for migrated tools
 	readFileSpec, readFileFactory := o.getReadFileToolSpec(modelFamily, o.session)
@@ -1285,6 +1263,15 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 			"",
 		)
 	}
+	// search_files and replace_in_files skip the paths git ignores
+	repo := vcs.NewGit(o.workingDir)
+	addSpec(
+		&tools.ReplaceInFilesToolSpec{},
+		false,
+		tools.NewReplaceInFilesToolFactory(o.fs, o.session, o.config.GetIgnoredDirs(), repo, o.workingDir),
+		false,
+		"",
+	)
 	if o.shouldUseNonDiffUpdateTool(modelFamily) {
 		addSpec(&tools.WriteFileJSONToolSpec{}, true, tools.NewWriteFileJSONToolFactory(o.fs, o.session), false, "")
 	} else if o.shouldUseSimpleSingleDiffTool(modelFamily) {
@@ -1302,7 +1289,7 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 	}
 
 	// Discovery / search tools
-	addSpec(&tools.SearchFilesToolSpec{}, false, tools.NewSearchFilesToolFactory(o.fs), false, "")
+	addSpec(&tools.SearchFilesToolSpec{}, false, tools.NewSearchFilesToolFactory(o.fs, o.config.GetIgnoredDirs(), repo, o.workingDir), false, "")
 	addSpec(&tools.SearchFileContentToolSpec{}, false, tools.NewSearchFileContentToolFactory(o.fs), false, "")
 	addSpec(&tools.CodebaseInvestigatorToolSpec{}, false, tools.NewCodebaseInvestigatorToolFactory(NewCodebaseInvestigatorAgent(o)), false, "")
 	addSpec(&tools.RefactoringAgentToolSpec{}, false, tools.NewRefactoringAgentToolFactory(NewRefactoringAgent(o)), false, "")
@@ -1317,11 +1304,15 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 	addSpec(&tools.GrepContextFilesToolSpec{}, false, tools.NewGrepContextFilesToolFactory(o.fs, o.config, o.session), false, "")
 	addSpec(&tools.ReadContextFileToolSpec{}, false, tools.NewReadContextFileToolFactory(o.fs, o.config, o.session), false, "")
 	addSpec(&tools.AddContextDirectoryToolSpec{}, false, tools.NewAddContextDirectoryToolFactory(o.fs, o.config, o.session), false, "")
+	addSpec(&tools.FetchDocsToolSpec{}, false, tools.NewFetchDocsToolFactory(nil, o.fs, o.config, o.session, o.authorizer, config.DefaultDocsDir()), false, "")
 
 	// Task management
 	addSpec(&tools.TodoToolSpec{}, false, tools.NewTodoToolFactory(o.todoClient), false, "")
 	addSpec(&tools.TaskSummaryToolSpec{}, false, tools.NewTaskSummaryToolFactory(o.session), false, "")
 
+	// Project memory shared across sessions
+	addSpec(&tools.MemoryToolSpec{}, false, tools.NewMemoryToolFactory(o.fs, o.workingDir), false, "")
+
 	// Shell tooling
 	if o.shouldUseShellTool(modelFamily) {
 		addSpec(&tools.ShellToolSpec{}, true, tools.NewShellToolFactory(o.session, o.workingDir), false, "")
@@ -1331,15 +1322,25 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 	addSpec(&tools.StatusProgramToolSpec{}, true, tools.NewStatusProgramToolFactory(o.session), false, "")
 	addSpec(&tools.WaitProgramToolSpec{}, true, tools.NewWaitProgramToolFactory(o.session), false, "")
 	addSpec(&tools.StopProgramToolSpec{}, true, tools.NewStopProgramToolFactory(o.session), false, "")
+	sessionProcesses := tools.NewSessionProcessTable(func() []*session.Session { return []*session.Session{o.session} })
+	addSpec(&tools.BackgroundProcessesToolSpec{}, true, tools.NewBackgroundProcessesToolFactory(sessionProcesses), false, "")
+	addSpec(&tools.WaitForFileToolSpec{}, false, tools.NewWaitForFileToolFactory(o.fs), false, "")
 
 	// Sandbox tool with TinyGo status forwarding - needs custom factory for configuration
-	sandboxSpec, _ := tools.WrapLegacyTool(tools.NewSandboxToolWithFS(o.workingDir, o.config.TempDir, o.fs, o.session, o.shellActorClient))
-	sandboxFactory := func(_ *tools.Registry) tools.ToolExecutor {
-		instance := tools.NewSandboxToolWithFS(o.workingDir, o.config.TempDir, o.fs, o.session, o.shellActorClient)
-		o.configureSandboxTool(instance)
-		return instance
+	sandboxTool := tools.NewSandboxToolWithFS(o.workingDir, o.config.TempDir, o.fs, o.session, o.shellActorClient)
+	// The description lists the configured modules
+	sandboxTool.SetAllowedModules(o.config.Sandbox.AllowedModules)
+	if err := sandboxTool.Available(); err != nil && o.config.Sandbox.SkipUnavailableGoSandbox {
+		logger.Warn("Not registering %s: %v", tools.ToolNameGoSandbox, err)
+	} else {
+		sandboxSpec, _ := tools.WrapLegacyTool(sandboxTool)
+		sandboxFactory := func(_ *tools.Registry) tools.ToolExecutor {
+			instance := tools.NewSandboxToolWithFS(o.workingDir, o.config.TempDir, o.fs, o.session, o.shellActorClient)
+			o.configureSandboxTool(instance)
+			return instance
+		}
+		addSpec(sandboxSpec, true, sandboxFactory, false, "")
 	}
-	addSpec(sandboxSpec, true, sandboxFactory, false, "")
 
 	// Directory access request tool - allows LLM to request additional sandbox permissions
 	if o.sandboxManager != nil && o.sandbox.IsEnabled() {
@@ -1351,18 +1352,18 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 	if o.shouldUseParallelTool(modelFamily) {
 		parallelSpec, _ := tools.WrapLegacyTool(tools.NewParallelTool(nil))
 		parallelFactory := func(reg *tools.Registry) tools.ToolExecutor {
-			return tools.NewParallelTool(reg)
+			return tools.NewParallelToolWithFS(reg, o.fs, o.session)
 		}
 		addSpec(parallelSpec, false, parallelFactory, false, "")
 	}
 
 	// Summarization-related tools
 	if o.summarizeClient != nil {
-		addSpec(&tools.SummarizeFileToolSpec{}, false, tools.NewSummarizeFileToolFactory(o.fs, o.session, o.summarizeClient), false, "")
+		addSpec(&tools.SummarizeFileToolSpec{}, false, tools.NewSummarizeFileToolFactory(o.fs, o.session, o.summaryService()), false, "")
 
-		summarizeSpec, _ := tools.WrapLegacyTool(tools.NewToolSummarizeTool(nil, o.summarizeClient))
+		summarizeSpec, _ := tools.WrapLegacyTool(tools.NewToolSummarizeTool(nil, o.summaryService()))
 		summarizeFactory := func(reg *tools.Registry) tools.ToolExecutor {
-			return tools.NewToolSummarizeTool(reg, o.summarizeClient)
+			return tools.NewToolSummarizeTool(reg, o.summaryService())
 		}
 		addSpec(summarizeSpec, false, summarizeFactory, false, "")
 	}
@@ -1377,11 +1378,15 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 		}
 		for _, tool := range mcpTools {
 			t := tool
-			mcpKey := extractMCPSanitizedServer(t.Name())
+			mcpKey := o.mcpServerKey(t.Name())
 			addLegacyTool(t, false, true, mcpKey)
 		}
 	}
 
+	if len(disabled) > 0 {
+		logger.Info("Disabled tools: %s", strings.Join(disabled, ", "))
+	}
+
 	filteredSpecs := specs
 	if applyFilter {
 		var (
@@ -1428,6 +1433,10 @@ func (o *Orchestrator) rebuildTools(applyFilter bool) []error {
 	}
 
 	registry := tools.NewRegistryWithSecrets(o.authorizer, secretdetect.NewDetector())
+	if o.config != nil && o.config.Redaction.ToolResults {
+		registry.SetResultRedactor(o.newResultRedactor())
+	}
+	registry.SetDryRun(o.dryRun())
 	o.toolRegistry = registry
 
 	// Initialize tool call rewriter with summarization model
@@ -1516,7 +1525,18 @@ func (o *Orchestrator) shouldUseShellTool(modelFamily llm.ModelFamily) bool {
 	return false
 }
 
+// shouldUseNumberedReadFileTool follows the configured read file mode and
+// falls back to the model family for "auto"
 func (o *Orchestrator) shouldUseNumberedReadFileTool(modelFamily llm.ModelFamily) bool {
+	if o.config != nil {
+		switch o.config.ReadFileMode {
+		case config.ReadFileModeNumbered:
+			return true
+		case config.ReadFileModePlain:
+			return false
+		}
+	}
+	// No model family currently reads numbered lines better
 	return false
 }
 
@@ -1580,6 +1600,8 @@ func (o *Orchestrator) configureSandboxTool(sandboxTool *tools.SandboxTool) {
 		sandboxTool.SetAuthorizer(o.authorizer)
 	}
 	sandboxTool.SetSummarizeClient(o.summarizeClient)
+	sandboxTool.SetMaxTimeout(o.config.Sandbox.MaxExecutionTimeoutSeconds)
+	sandboxTool.SetAllowedModules(o.config.Sandbox.AllowedModules)
 	// Set output compaction configuration
 	sandboxTool.SetCompactionConfig(o.config.SandboxOutputCompaction)
 	// Use session's sandbox output directory for large output files
@@ -1591,7 +1613,10 @@ func (o *Orchestrator) configureSandboxTool(sandboxTool *tools.SandboxTool) {
 	// Set context window from orchestration model for compaction decisions
 	if o.orchestrationClient != nil {
 		modelID := o.orchestrationClient.GetModelName()
-		contextWindow := o.providerMgr.GetModelContextWindow(modelID)
+		contextWindow := o.config.ContextWindowOverride(modelID)
+		if contextWindow <= 0 {
+			contextWindow = o.providerMgr.GetModelContextWindow(modelID)
+		}
 		if contextWindow > 0 {
 			sandboxTool.SetContextWindow(contextWindow)
 		} else {
@@ -1614,7 +1639,11 @@ func (o *Orchestrator) configureSandboxTool(sandboxTool *tools.SandboxTool) {
 	sandboxTool.SetSecretDetector(secretdetect.NewDetector())
 	sandboxTool.SetFeatureFlags(o.featureFlags)
 	sandboxTool.SetProgressCallback(o.GetCurrentProgressCallback())
+	if o.config != nil && o.config.Redaction.ToolResults {
+		sandboxTool.SetOutputRedactor(o.newResultRedactor())
+	}
 	if sandboxTool.GetTinyGoManager() != nil {
+		sandboxTool.GetTinyGoManager().SetAutoInstall(o.config.AutoInstallTinyGo)
 		sandboxTool.GetTinyGoManager().SetStatusCallback(func(status string) {
 			dispatchProgress(o.GetCurrentProgressCallback(), progress.Update{
 				Message:   status,
@@ -1630,6 +1659,16 @@ func (o *Orchestrator) RefreshMCPTools() []error {
 	return o.rebuildTools(false)
 }
 
+// SetReadOnly switches the session's read-only mode and re-registers the tools,
+// so write tools disappear from (or return to) the model's tool list.
+func (o *Orchestrator) SetReadOnly(readOnly bool) []error {
+	if o.session == nil {
+		return nil
+	}
+	o.session.SetReadOnly(readOnly)
+	return o.rebuildTools(false)
+}
+
 // TestMCPServer attempts to build tools for a specific MCP server to validate configuration.
 func (o *Orchestrator) TestMCPServer(serverName string) error {
 	if o.mcpManager == nil {
@@ -1719,7 +1758,7 @@ func (o *Orchestrator) resolveMCPServerNames(keys []string) []string {
 
 // getAutoContinueMaxAttempts returns the appropriate auto-continue limit based on model family
 func (o *Orchestrator) getAutoContinueMaxAttempts() int {
-	modelID := o.providerMgr.GetOrchestrationModel()
+	modelID := o.orchestrationModelID()
 	modelFamily := llm.DetectModelFamily(modelID)
 
 	if modelFamily == llm.FamilyKimi {
@@ -1735,6 +1774,9 @@ func (o *Orchestrator) getAutoContinueMaxAttempts() int {
 }
 
 func (o *Orchestrator) lookupServerBySanitizedKey(key string) string {
+	if name := o.mcpManager.ServerByKey(key); name != "" {
+		return name
+	}
 	if o.config == nil {
 		return ""
 	}
@@ -1755,6 +1797,16 @@ func (o *Orchestrator) GetActiveMCPServers() []string {
 	return append([]string(nil), o.activeMCPServers...)
 }
 
+// mcpServerKey returns the sanitized key of the MCP server providing a tool.
+// Keys can contain underscores and collision suffixes, so the manager's
+// mapping is preferred over parsing the tool name.
+func (o *Orchestrator) mcpServerKey(toolName string) string {
+	if server := o.mcpManager.ServerForTool(toolName); server != "" {
+		return o.mcpManager.ServerKey(server)
+	}
+	return extractMCPSanitizedServer(toolName)
+}
+
 func extractMCPSanitizedServer(name string) string {
 	if !strings.HasPrefix(name, "mcp_") {
 		return ""
@@ -1805,7 +1857,7 @@ func dispatchProgress(cb progress.Callback, update progress.Update) {
 	if cb == nil {
 		return
 	}
-	if update.Message == "" && !update.ShouldStatus() {
+	if update.Message == "" && update.Reasoning == "" && !update.ShouldStatus() {
 		return
 	}
 	if err := progress.Dispatch(cb, update); err != nil {
@@ -1881,7 +1933,7 @@ func (o *Orchestrator) buildReadOnlyPlanningTools(allowedMCPs []string) ([]plann
 			if tool == nil {
 				continue
 			}
-			serverKey := extractMCPSanitizedServer(tool.Name())
+			serverKey := o.mcpServerKey(tool.Name())
 			if serverKey == "" {
 				continue
 			}
@@ -1930,7 +1982,7 @@ func (o *Orchestrator) isReadOnlyMCPTool(serverName string, serverCfg *config.MC
 
 	switch strings.ToLower(serverCfg.Type) {
 	case "openapi":
-		if openapiTool, ok := tool.(*tools.OpenAPITool); ok {
+		if openapiTool, ok := mcp.UnwrapTool(tool).(*tools.OpenAPITool); ok {
 			method := strings.ToUpper(openapiTool.HTTPMethod())
 			return method == "GET" || method == "HEAD" || method == "OPTIONS"
 		}
@@ -2340,16 +2392,46 @@ func (o *Orchestrator) runPlanningPhaseIfNeeded(ctx context.Context, prompt stri
 
 // ProcessPrompt processes a user prompt
 func (o *Orchestrator) ProcessPrompt(ctx context.Context, prompt string, progressCallback progress.Callback, contextCallback ContextUsageCallback, authCallback AuthorizationCallback, toolCallCallback ToolCallCallback, toolResultCallback ToolResultCallback, openRouterUsageCallback OpenRouterUsageCallback) error {
+	_, err := o.ProcessPromptWithResult(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback)
+	return err
+}
+
+// ProcessPromptWithResult processes a user prompt like ProcessPrompt and
+// reports why processing stopped. The result is returned even when err is
+// non-nil.
+func (o *Orchestrator) ProcessPromptWithResult(ctx context.Context, prompt string, progressCallback progress.Callback, contextCallback ContextUsageCallback, authCallback AuthorizationCallback, toolCallCallback ToolCallCallback, toolResultCallback ToolResultCallback, openRouterUsageCallback OpenRouterUsageCallback) (*Result, error) {
+	tracker := o.newPromptResultTracker()
+	loopResult, err := o.processPrompt(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback)
+	return tracker.finish(loopResult, err), err
+}
+
+// processPrompt runs a prompt and returns the result of its loop, which is
+// nil if the loop didn't run
+func (o *Orchestrator) processPrompt(ctx context.Context, prompt string, progressCallback progress.Callback, contextCallback ContextUsageCallback, authCallback AuthorizationCallback, toolCallCallback ToolCallCallback, toolResultCallback ToolResultCallback, openRouterUsageCallback OpenRouterUsageCallback) (result *loop.Result, err error) {
 	combinedCtx, cancel := combineContexts(ctx, o.ctx)
 	if cancel != nil {
 		defer cancel()
 	}
 	ctx = combinedCtx
+	defer func() {
+		if err = withCancelCause(ctx, err); err != nil {
+			if cause := CancelCause(err); cause != nil {
+				logger.Info("ProcessPrompt: cancelled (%v)", cause)
+			}
+		}
+	}()
 
 	if o.orchestrationClient == nil {
-		return fmt.Errorf("no orchestration model configured. Use /provider and /models commands to set up")
+		return nil, fmt.Errorf("no orchestration model configured. Use /provider and /models commands to set up")
 	}
 
+	o.workspaceMu.RLock()
+	defer o.workspaceMu.RUnlock()
+	o.compactionMu.Lock()
+	o.activePrompts.Add(1)
+	o.compactionMu.Unlock()
+	defer o.endPrompt()
+
 	// Store progress callback for use by tools (e.g., TinyGo download progress)
 	o.progressCbMu.Lock()
 	o.currentProgressCb = progressCallback
@@ -2389,8 +2471,9 @@ func (o *Orchestrator) ProcessPrompt(ctx context.Context, prompt string, progres
 		}()
 	}
 
-	// Reset loop detector for new prompt
+	// Reset loop detector and retry budget for new prompt
 	o.loopDetector.Reset()
+	o.resetRetryBudget()
 	logger.Debug("Loop detector reset for new prompt")
 
 	if err := o.runPlanningPhaseIfNeeded(ctx, prompt, progressCallback, toolCallCallback, toolResultCallback); err != nil {
@@ -2399,14 +2482,14 @@ func (o *Orchestrator) ProcessPrompt(ctx context.Context, prompt string, progres
 
 	// Check if a planning board was created and execute primary tasks serially
 	if planningBoard := o.session.GetPlanningBoard(); planningBoard != nil && len(planningBoard.PrimaryTasks) > 0 {
-		return o.executePlanningBoard(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback, planningBoard)
+		return nil, o.executePlanningBoard(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback, planningBoard)
 	}
 
 	// Get or build system prompt (cached for the session)
-	modelID := o.providerMgr.GetOrchestrationModel()
+	modelID := o.orchestrationModelID()
 	systemPrompt, err := o.getOrBuildSystemPrompt(ctx, modelID)
 	if err != nil {
-		return fmt.Errorf("failed to build system prompt: %w", err)
+		return nil, fmt.Errorf("failed to build system prompt: %w", err)
 	}
 
 	// Broadcast initial context usage after recording the user message
@@ -2526,7 +2609,7 @@ func (o *Orchestrator) executePlanningBoard(
 
 		// Execute task in clean orchestrator
 		var taskSummary *session.TaskExecutionSummary
-		if err := cleanOrch.runOrchestrationLoopForTask(ctx, taskPrompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback); err != nil {
+		if _, err := cleanOrch.runOrchestrationLoopForTask(ctx, taskPrompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback); err != nil {
 			logger.Warn("Task %d failed: %v", i+1, err)
 			sendStream(fmt.Sprintf("⚠️  Task %d failed: %v\n", i+1, err), false)
 			primaryTask.Status = "failed"
@@ -2685,7 +2768,7 @@ func (o *Orchestrator) runOrchestrationLoopForTask(
 	toolCallCallback ToolCallCallback,
 	toolResultCallback ToolResultCallback,
 	openRouterUsageCallback OpenRouterUsageCallback,
-) error {
+) (*loop.Result, error) {
 	// Reset loop detector for this task
 	o.loopDetector.Reset()
 
@@ -2696,10 +2779,10 @@ func (o *Orchestrator) runOrchestrationLoopForTask(
 	})
 
 	// Get system prompt
-	modelID := o.providerMgr.GetOrchestrationModel()
+	modelID := o.orchestrationModelID()
 	systemPrompt, err := o.getOrBuildSystemPrompt(ctx, modelID)
 	if err != nil {
-		return fmt.Errorf("failed to build system prompt: %w", err)
+		return nil, fmt.Errorf("failed to build system prompt: %w", err)
 	}
 
 	// Broadcast initial context usage
@@ -2741,7 +2824,7 @@ func (o *Orchestrator) runOrchestrationLoopCore(
 	authCallback AuthorizationCallback,
 	toolCallCallback ToolCallCallback,
 	toolResultCallback ToolResultCallback,
-) error {
+) (*loop.Result, error) {
 	// The prompt parameter is already added to the session by the caller
 	// Delegate to the new loop abstraction
 	return o.runOrchestrationLoopWithAbstraction(ctx, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback)
@@ -2763,8 +2846,26 @@ func (o *Orchestrator) ProcessPromptWithVerification(
 	toolResultCallback ToolResultCallback,
 	openRouterUsageCallback OpenRouterUsageCallback,
 ) error {
+	_, err := o.ProcessPromptWithVerificationResult(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback)
+	return err
+}
+
+// ProcessPromptWithVerificationResult is ProcessPromptWithVerification
+// reporting why processing stopped. Counters and usage add up over all
+// verification attempts; the reason is that of the last attempt.
+func (o *Orchestrator) ProcessPromptWithVerificationResult(
+	ctx context.Context,
+	prompt string,
+	progressCallback progress.Callback,
+	contextCallback ContextUsageCallback,
+	authCallback AuthorizationCallback,
+	toolCallCallback ToolCallCallback,
+	toolResultCallback ToolResultCallback,
+	openRouterUsageCallback OpenRouterUsageCallback,
+) (*Result, error) {
 	// Track queued prompt count to detect new prompts during verification
 	initialQueuedCount := 0 // Will be read from session
+	total := &Result{Reason: StopReasonCompleted}
 
 	for attempt := 1; attempt <= maxVerificationRetries; attempt++ {
 		// Mark verification attempt in session
@@ -2772,10 +2873,11 @@ func (o *Orchestrator) ProcessPromptWithVerification(
 		initialQueuedCount = o.session.GetQueuedUserPromptCount()
 
 		// Run main orchestration loop
-		err := o.ProcessPrompt(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback)
+		promptResult, err := o.ProcessPromptWithResult(ctx, prompt, progressCallback, contextCallback, authCallback, toolCallCallback, toolResultCallback, openRouterUsageCallback)
+		total.add(promptResult)
 		if err != nil {
 			o.session.ResetVerification()
-			return err
+			return total, err
 		}
 
 		userMsgCountAfterPrompt := o.session.UserMessageCount()
@@ -2790,14 +2892,14 @@ func (o *Orchestrator) ProcessPromptWithVerification(
 		// If verification skipped (nil) or passed, we're done
 		if result == nil || result.IsSuccess() {
 			o.session.ResetVerification()
-			return nil
+			return total, nil
 		}
 
 		// Verification failed - check if we should retry
 		if o.session.HasNewUserPromptOrQueued(userMsgCountAfterPrompt, initialQueuedCount) {
 			logger.Info("New user prompt detected, stopping verification retry")
 			o.session.ResetVerification()
-			return nil
+			return total, nil
 		}
 
 		// Check if we've hit max attempts
@@ -2808,7 +2910,7 @@ func (o *Orchestrator) ProcessPromptWithVerification(
 				Mode:    progress.ReportNoStatus,
 			})
 			o.session.ResetVerification()
-			return nil
+			return total, nil
 		}
 
 		// Feed failure back to LLM for next attempt
@@ -2839,7 +2941,8 @@ func (o *Orchestrator) ProcessPromptWithVerification(
 		select {
 		case <-ctx.Done():
 			o.session.ResetVerification()
-			return ctx.Err()
+			total.Reason = stopReasonFor(nil, ctx.Err())
+			return total, ctx.Err()
 		case <-time.After(time.Duration(backoffSeconds) * time.Second):
 			// Continue to retry
 		}
@@ -2849,7 +2952,7 @@ func (o *Orchestrator) ProcessPromptWithVerification(
 	}
 
 	o.session.ResetVerification()
-	return nil
+	return total, nil
 }
 
 // runVerificationPhaseIfNeeded runs verification after the main orchestration loop completes.
@@ -2954,6 +3057,9 @@ func (o *Orchestrator) processToolCalls(
 		authMu  sync.Mutex // serialize user auth prompts to avoid overlapping requests
 	)
 
+	fileProgress := newFileBatchProgress(toolCalls, progressCb)
+	dedup := newToolCallDeduplicator(o.deduplicateToolCalls())
+
 	for i, toolCall := range toolCalls {
 		toolID, _ := toolCall["id"].(string)
 		toolType, _ := toolCall["type"].(string)
@@ -3017,8 +3123,8 @@ func (o *Orchestrator) processToolCalls(
 		})
 
 		// Parse arguments
-		var args map[string]interface{}
-		if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+		args, err := parseToolArguments(argsJSON)
+		if err != nil {
 			results[i] = &toolCallResult{
 				idx: i,
 				message: &session.Message{
@@ -3128,6 +3234,12 @@ func (o *Orchestrator) processToolCalls(
 			}
 		}
 
+		// Identical calls in the same response share the first call's result
+		if dedup.observe(i, toolName, args) {
+			logger.Info("Skipping duplicate tool call %s (%s), reusing the result of an identical call", toolID, toolName)
+			continue
+		}
+
 		wg.Add(1)
 		go func(idx int, toolName, toolID string, args map[string]interface{}, callObj *tools.ToolCall) {
 			defer wg.Done()
@@ -3198,6 +3310,7 @@ func (o *Orchestrator) processToolCalls(
 			if result.RequiresUserInput {
 				// Ask user for approval - prefer userInteractionClient, fall back to authCb
 				approved := false
+				var editedParams map[string]interface{}
 				suggestedPrefix := result.SuggestedCommandPrefix
 				tabID := o.GetUserInteractionTabID()
 
@@ -3231,6 +3344,7 @@ func (o *Orchestrator) processToolCalls(
 						}
 					} else if resp.Approved {
 						approved = true
+						editedParams = resp.EditedParams
 					} else {
 						result = &tools.ToolResult{
 							ID:    toolID,
@@ -3239,10 +3353,11 @@ func (o *Orchestrator) processToolCalls(
 					}
 				} else if authCb != nil {
 					// Fall back to legacy callback
-					var err error
 					authMu.Lock()
-					approved, err = authCb(toolName, args, result.AuthReason)
+					resp, err := authCb(toolName, args, result.AuthReason)
 					authMu.Unlock()
+					approved = err == nil && resp.Approved
+					editedParams = resp.EditedParams
 					if err != nil {
 						result = &tools.ToolResult{
 							ID:    toolID,
@@ -3262,6 +3377,17 @@ func (o *Orchestrator) processToolCalls(
 					}
 				}
 
+				// The user may have edited the parameters before approving
+				if approved && editedParams != nil {
+					logger.Info("Running %s (tool call %s) with parameters edited during authorization", toolName, toolID)
+					args = editedParams
+					callObj.Parameters = editedParams
+					o.rewriteToolCallInHistory(sess, toolID, toolName, editedParams)
+					// The suggested prefix was derived from the original command;
+					// an edited call is approved only this once
+					suggestedPrefix = ""
+				}
+
 				// If approved, persist command prefix and re-execute
 				if approved {
 					if suggestedPrefix != "" {
@@ -3290,7 +3416,7 @@ func (o *Orchestrator) processToolCalls(
 			// Format result as string for LLM and UI
 			var toolResult string // For LLM
 			var uiResult string   // For UI display
-			var executionMetadata *tools.ExecutionMetadata
+			executionMetadata := result.ExecutionMetadata
 
 			if result.Error != "" {
 				toolResult = fmt.Sprintf("Error: %s", result.Error)
@@ -3323,6 +3449,9 @@ func (o *Orchestrator) processToolCalls(
 				} else {
 					uiResult = toolResult
 				}
+
+				// Cap what goes back to the model; the UI keeps the full result
+				toolResult = truncateToolResult(toolResult, o.toolResultLimit(toolName))
 			}
 
 			results[idx] = &toolCallResult{
@@ -3339,11 +3468,31 @@ func (o *Orchestrator) processToolCalls(
 				errorMsg: result.Error,
 				metadata: executionMetadata,
 			}
+
+			fileProgress.complete(toolName, result.Error != "")
 		}(i, toolName, toolID, args, toolCallObj)
 	}
 
 	wg.Wait()
 
+	if dedup != nil {
+		for idx, firstIdx := range dedup.duplicates {
+			orig := results[firstIdx]
+			if orig == nil {
+				continue
+			}
+			toolID, _ := toolCalls[idx]["id"].(string)
+			message := *orig.message
+			message.ToolID = toolID
+			dup := *orig
+			dup.idx = idx
+			dup.message = &message
+			dup.toolID = toolID
+			results[idx] = &dup
+			fileProgress.complete(dup.toolName, dup.errorMsg != "")
+		}
+	}
+
 	for _, res := range results {
 		if res == nil {
 			continue
@@ -3522,7 +3671,7 @@ func isIllegalMessagesError(err error) bool {
 
 // completeWithRetry wraps LLM completion with error retry logic
 func (o *Orchestrator) completeWithRetry(ctx context.Context, req *llm.CompletionRequest, progressCallback progress.Callback) (*llm.CompletionResponse, error) {
-	modelID := o.providerMgr.GetOrchestrationModel()
+	modelID := o.orchestrationModelID()
 
 	sendStatus := func(msg string) {
 		dispatchProgress(progressCallback, progress.Update{
@@ -3602,6 +3751,14 @@ func (o *Orchestrator) completeWithRetry(ctx context.Context, req *llm.Completio
 			return nil, &contextSizeExceededError{inner: err, reason: decision.Reason}
 		}
 
+		// Check the retry budget shared by the whole run
+		if !o.retryBudget.take() {
+			used, limit := o.retryBudget.status()
+			logger.Warn("Retry budget exhausted (%d/%d retries used in this run), aborting: %v", used, limit, err)
+			sendStream(fmt.Sprintf("\n⛔ Giving up: the provider kept failing and all %d retries for this prompt are used up. Last error: %v\n", limit, err))
+			return nil, fmt.Errorf("%w (%d retries): %w", errRetryBudgetExhausted, limit, err)
+		}
+
 		// Notify user about retry
 		logger.Info("Error judge decided to retry (attempt %d/%d, sleep %ds): %s",
 			attempt, errorRetryMaxAttempts, decision.SleepSeconds, decision.Reason)
@@ -3612,13 +3769,8 @@ func (o *Orchestrator) completeWithRetry(ctx context.Context, req *llm.Completio
 		sendStatus(fmt.Sprintf("Retrying in %ds...", decision.SleepSeconds))
 
 		// Sleep before retry
-		if decision.SleepSeconds > 0 {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(time.Duration(decision.SleepSeconds) * time.Second):
-				// Continue to retry
-			}
+		if err := o.waitBeforeRetry(ctx, time.Duration(decision.SleepSeconds)*time.Second); err != nil {
+			return nil, err
 		}
 
 		// Update status to show we're retrying
@@ -3714,7 +3866,8 @@ func (o *Orchestrator) heuristicErrorDecision(err error, attemptNumber int) tool
 }
 
 func (o *Orchestrator) maybeCompactContext(modelID, systemPrompt string, sessionMessages []*session.Message, perMessageTokens []int, totalTokens int, progressCallback progress.Callback, contextCallback ContextUsageCallback) {
-	if len(sessionMessages) < 4 {
+	minMessages, preserveRecent := o.compactionFloors()
+	if len(sessionMessages) < minMessages {
 		return
 	}
 
@@ -3727,29 +3880,20 @@ func (o *Orchestrator) maybeCompactContext(modelID, systemPrompt string, session
 		return
 	}
 
-	prefixCount := selectCompactionPrefix(perMessageTokens, totalTokens)
+	prefixCount := selectCompactionPrefix(compactableTokens(sessionMessages, perMessageTokens), totalTokens)
 	if prefixCount <= 0 {
 		return
 	}
 
-	if prefixCount >= len(sessionMessages) {
-		prefixCount = len(sessionMessages) - 1
-	}
-
-	// Ensure we keep at least two recent messages un-compacted
-	if len(sessionMessages)-prefixCount < 2 {
-		prefixCount = len(sessionMessages) - 2
-		if prefixCount <= 0 {
-			return
-		}
-	}
-
-	prefixCount = adjustCompactionBoundaryForTools(sessionMessages, prefixCount)
+	prefixCount = clampCompactionPrefix(sessionMessages, prefixCount, preserveRecent)
 	if prefixCount <= 0 {
 		return
 	}
 
 	messagesCopy := append([]*session.Message(nil), sessionMessages[:prefixCount]...)
+	if len(unpinnedMessages(messagesCopy)) == 0 {
+		return
+	}
 
 	// Check if we've already had 2 consecutive compactions - if so, skip this one
 	o.compactionMu.Lock()
@@ -3769,12 +3913,13 @@ func (o *Orchestrator) maybeCompactContext(modelID, systemPrompt string, session
 }
 
 func (o *Orchestrator) compactContext(modelID, systemPrompt string, contextCallback ContextUsageCallback, messages []*session.Message, progressCallback progress.Callback) {
-	o.compactContextWithAttempt(modelID, systemPrompt, contextCallback, messages, progressCallback, 1)
+	o.compactContextWithAttempt(context.Background(), modelID, systemPrompt, contextCallback, messages, progressCallback, 1)
 }
 
 // compactContextWithAttempt performs compaction with a specific attempt number,
-// using increasingly forceful prompts for subsequent attempts.
-func (o *Orchestrator) compactContextWithAttempt(modelID, systemPrompt string, contextCallback ContextUsageCallback, messages []*session.Message, progressCallback progress.Callback, attemptNumber int) {
+// using increasingly forceful prompts for subsequent attempts. It returns the
+// share of the context freed and whether the messages were compacted.
+func (o *Orchestrator) compactContextWithAttempt(ctx context.Context, modelID, systemPrompt string, contextCallback ContextUsageCallback, messages []*session.Message, progressCallback progress.Callback, attemptNumber int) (freed int, compacted bool) {
 	defer func() {
 		o.compactionMu.Lock()
 		o.compactionInProgress = false
@@ -3782,7 +3927,7 @@ func (o *Orchestrator) compactContextWithAttempt(modelID, systemPrompt string, c
 	}()
 
 	if len(messages) == 0 {
-		return
+		return 0, false
 	}
 
 	// Clamp attempt number to valid range
@@ -3793,8 +3938,14 @@ func (o *Orchestrator) compactContextWithAttempt(modelID, systemPrompt string, c
 		attemptNumber = maxCompactionAttempts
 	}
 
+	// Pinned messages are kept verbatim, so only the rest is summarized
+	summarized := unpinnedMessages(messages)
+	if len(summarized) == 0 {
+		return 0, false
+	}
+
 	contextWindow := o.getContextWindow(modelID)
-	_, perMessageTokens, _ := estimateContextTokens(modelID, "", messages)
+	_, perMessageTokens, _ := estimateContextTokens(modelID, "", summarized)
 	latestUserPrompt := findLatestUserPrompt(o.session.GetMessages())
 
 	summary := ""
@@ -3825,44 +3976,44 @@ func (o *Orchestrator) compactContextWithAttempt(modelID, systemPrompt string, c
 
 	logger.Info("compaction: attempt %d/%d using %s prompt (max %d bytes)", attemptNumber, maxCompactionAttempts, attemptDesc, maxBytes)
 
-	// Use the abstracted chunked summarizer
-	if o.summarizeClient != nil {
-		// Build conversation content for summarization
-		conversationContent := buildConversationContent(messages)
-
-		// Create chunked summarizer
-		chunkedSummarizer := summarizer.NewChunkedSummarizer(o.summarizeClient)
+	tokensBefore, _, _ := estimateContextTokens(modelID, systemPrompt, o.session.GetMessages())
+	dispatchProgress(progressCallback, progress.Update{
+		Message:   fmt.Sprintf("🧹 Compacting %d earlier messages...", len(summarized)),
+		Mode:      progress.ReportJustStatus,
+		Ephemeral: true,
+	})
 
-		// Summarize with automatic chunking
-		result, err := chunkedSummarizer.Summarize(context.Background(), conversationContent, summarizer.SummarizeOptions{
-			BasePrompt: basePrompt,
-			MaxBytes:   maxBytes,
-			ProgressCallback: func(status string) {
+	// Summarize with automatic chunking; falls back to condensed messages.
+	// The model calls can take a while, so progress is reported as they
+	// finish and in between.
+	summaryProgress := newCompactionProgress(progressCallback)
+	var result summarizer.ConversationSummary
+	summaryProgress.run(func() {
+		result = o.summaryService().SummarizeConversation(ctx, summarized, summarizer.ConversationOptions{
+			Instruction: o.compactionInstruction(basePrompt, attemptDesc, attemptNumber, summarized, latestUserPrompt),
+			MaxBytes:    maxBytes,
+			Progress: func(status string) {
 				logger.Debug("compaction[%d]: %s", attemptNumber, status)
+				summaryProgress.setStatus(status)
 			},
+			Percent: summaryProgress.setPercent,
 		})
-
-		if err != nil {
-			logger.Error("compaction[%d]: summarization failed: %v", attemptNumber, err)
-			summary = fallbackConversationSummary(messages)
-		} else {
-			summary = strings.TrimSpace(result.Summary)
-			logger.Info("compaction[%d]: summarized %d messages using %d chunks, %d total tokens, %d chars output",
-				attemptNumber, len(messages), result.ChunksUsed, result.TotalTokens, len(summary))
-		}
-	} else {
-		logger.Error("compaction[%d]: no summarization client available, using fallback", attemptNumber)
-		summary = fallbackConversationSummary(messages)
+	})
+	summary = result.Summary
+	if ctx.Err() != nil {
+		logger.Info("compaction[%d]: cancelled, keeping the messages", attemptNumber)
+		return 0, false
 	}
-
-	if summary == "" {
-		logger.Error("compaction[%d]: summarization produced empty summary, using fallback", attemptNumber)
-		summary = fallbackConversationSummary(messages)
+	if result.Fallback {
+		logger.Error("compaction[%d]: summarization failed, using fallback: %v", attemptNumber, result.Err)
+	} else {
+		logger.Info("compaction[%d]: summarized %d messages using %d chunks, %d total tokens, %d chars output",
+			attemptNumber, len(summarized), result.ChunksUsed, result.TotalTokens, len(summary))
 	}
 
 	if summary == "" {
 		logger.Error("compaction[%d]: fallback summary also empty, aborting compaction", attemptNumber)
-		return
+		return 0, false
 	}
 
 	// Update summary content to indicate attempt number if it's a retry
@@ -3874,35 +4025,139 @@ func (o *Orchestrator) compactContextWithAttempt(modelID, systemPrompt string, c
 	}
 
 	summaryContent := fmt.Sprintf("Summary of earlier context (%s):\n%s", summaryLabel, summary)
-	userSection := buildUserCompactionSection(messages, perMessageTokens, contextWindow, latestUserPrompt)
+	userSection := buildUserCompactionSection(summarized, perMessageTokens, contextWindow, latestUserPrompt, o.compactionConfig())
 	if userSection != "" {
 		summaryContent = fmt.Sprintf("%s\n\n%s", summaryContent, userSection)
 	}
 
 	if !o.session.CompactWithSummary(messages, summaryContent) {
 		logger.Error("compaction[%d]: session head changed before compaction could apply", attemptNumber)
-		return
+		return 0, false
 	}
 
 	// Track consecutive compactions - increment counter
 	o.compactionMu.Lock()
 	o.consecutiveCompactions++
+	o.totalCompactions++
 	o.lastCompactionTime = time.Now()
 	o.compactionMu.Unlock()
 
 	o.broadcastContextUsage(modelID, systemPrompt, contextCallback)
 
+	tokensAfter, _, _ := estimateContextTokens(modelID, systemPrompt, o.session.GetMessages())
+	freed = compactionFreedPercent(tokensBefore, tokensAfter)
+	logger.Info("compaction[%d]: context went from ~%d to ~%d tokens (freed ~%d%%)", attemptNumber, tokensBefore, tokensAfter, freed)
+
 	// Update progress message based on attempt number
 	var progressMsg string
 	if attemptNumber == 1 {
-		progressMsg = "\n🧹 Auto-compacted earlier context.\n"
+		progressMsg = fmt.Sprintf("\n🧹 Auto-compacted %d earlier messages, freed ~%d%% of context.\n", len(summarized), freed)
 	} else {
-		progressMsg = fmt.Sprintf("\n🧹 Auto-compacted earlier context (attempt %d/%d).\n", attemptNumber, maxCompactionAttempts)
+		progressMsg = fmt.Sprintf("\n🧹 Auto-compacted %d earlier messages (attempt %d/%d), freed ~%d%% of context.\n",
+			len(summarized), attemptNumber, maxCompactionAttempts, freed)
 	}
 
 	dispatchProgress(progressCallback, progress.Update{
 		Message: progressMsg,
 	})
+	return freed, true
+}
+
+// compactionProgressInterval is how often a running compaction reports that
+// it is still summarizing
+var compactionProgressInterval = 3 * time.Second
+
+// compactionProgress reports the summarization of a compaction as ephemeral
+// status updates. Updates come from the summarizer and from a heartbeat, so
+// they are serialized.
+type compactionProgress struct {
+	mu       sync.Mutex
+	callback progress.Callback
+	started  time.Time
+	status   string
+	percent  int
+}
+
+func newCompactionProgress(callback progress.Callback) *compactionProgress {
+	return &compactionProgress{callback: callback, started: time.Now()}
+}
+
+// run calls summarize, reporting the elapsed time every
+// compactionProgressInterval until it returns
+func (p *compactionProgress) run(summarize func()) {
+	if p.callback == nil {
+		summarize()
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(compactionProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.report(true)
+			}
+		}
+	}()
+
+	summarize()
+	close(done)
+	<-stopped
+}
+
+func (p *compactionProgress) setStatus(status string) {
+	p.mu.Lock()
+	p.status = strings.ToLower(status)
+	p.mu.Unlock()
+	p.report(false)
+}
+
+func (p *compactionProgress) setPercent(percent int) {
+	p.mu.Lock()
+	p.percent = percent
+	p.mu.Unlock()
+	p.report(false)
+}
+
+func (p *compactionProgress) report(withElapsed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var details []string
+	if p.status != "" {
+		details = append(details, p.status)
+	}
+	if p.percent > 0 {
+		details = append(details, fmt.Sprintf("%d%%", p.percent))
+	}
+	if withElapsed {
+		details = append(details, fmt.Sprintf("%ds", int(time.Since(p.started).Seconds())))
+	}
+
+	message := "🧹 Summarizing earlier context..."
+	if len(details) > 0 {
+		message = fmt.Sprintf("🧹 Summarizing earlier context (%s)...", strings.Join(details, ", "))
+	}
+	dispatchProgress(p.callback, progress.Update{
+		Message:   message,
+		Mode:      progress.ReportJustStatus,
+		Ephemeral: true,
+	})
+}
+
+// compactionFreedPercent returns the share of the context freed by a
+// compaction, from the token estimates before and after it
+func compactionFreedPercent(before, after int) int {
+	if before <= 0 || after >= before {
+		return 0
+	}
+	return (before - after) * 100 / before
 }
 
 // forceCompactContext runs compaction synchronously when context size is exceeded.
@@ -3969,7 +4224,13 @@ func (o *Orchestrator) forceCompactContext(modelID, systemPrompt string, session
 	o.compactionMu.Unlock()
 
 	messagesCopy := append([]*session.Message(nil), sessionMessages[:prefixCount]...)
-	logger.Info("forceCompactContext: compacting %d messages", len(messagesCopy))
+	// Pinned messages are kept verbatim, so only the rest is summarized
+	summarized := unpinnedMessages(messagesCopy)
+	if len(summarized) == 0 {
+		logger.Debug("forceCompactContext: all messages in the prefix are pinned")
+		return
+	}
+	logger.Info("forceCompactContext: compacting %d messages (%d pinned kept)", len(summarized), len(messagesCopy)-len(summarized))
 
 	// Run compaction synchronously with attempt-based retry
 	contextWindow := o.getContextWindow(modelID)
@@ -3982,59 +4243,45 @@ func (o *Orchestrator) forceCompactContext(modelID, systemPrompt string, session
 	attemptNum := o.compactionAttemptCount + 1
 	o.compactionAttemptMu.Unlock()
 
-	if o.summarizeClient != nil {
-		conversationContent := buildConversationContent(messagesCopy)
-		chunkedSummarizer := summarizer.NewChunkedSummarizer(o.summarizeClient)
-
-		// Determine prompt and max bytes based on attempt number
-		var basePrompt string
-		var maxBytes int
-		var attemptDesc string
-
-		switch attemptNum {
-		case 1:
-			basePrompt = compactionPromptStandard
-			maxBytes = compactionMaxBytesAttempt1
-			attemptDesc = "standard"
-		case 2:
-			basePrompt = compactionPromptForceful
-			maxBytes = compactionMaxBytesAttempt2
-			attemptDesc = "forceful"
-		case 3:
-			basePrompt = compactionPromptExtreme
-			maxBytes = compactionMaxBytesAttempt3
-			attemptDesc = "extreme"
-		default:
-			basePrompt = compactionPromptExtreme
-			maxBytes = compactionMaxBytesAttempt3
-			attemptDesc = "extreme"
-		}
+	// Determine prompt and max bytes based on attempt number
+	var basePrompt string
+	var maxBytes int
+	var attemptDesc string
 
-		logger.Info("forceCompactContext: attempt %d/%d using %s prompt (max %d bytes)", attemptNum, maxCompactionAttempts, attemptDesc, maxBytes)
+	switch attemptNum {
+	case 1:
+		basePrompt = compactionPromptStandard
+		maxBytes = compactionMaxBytesAttempt1
+		attemptDesc = "standard"
+	case 2:
+		basePrompt = compactionPromptForceful
+		maxBytes = compactionMaxBytesAttempt2
+		attemptDesc = "forceful"
+	case 3:
+		basePrompt = compactionPromptExtreme
+		maxBytes = compactionMaxBytesAttempt3
+		attemptDesc = "extreme"
+	default:
+		basePrompt = compactionPromptExtreme
+		maxBytes = compactionMaxBytesAttempt3
+		attemptDesc = "extreme"
+	}
 
-		result, err := chunkedSummarizer.Summarize(context.Background(), conversationContent, summarizer.SummarizeOptions{
-			BasePrompt: basePrompt,
-			MaxBytes:   maxBytes,
-			ProgressCallback: func(status string) {
-				logger.Debug("forceCompactContext[%d]: %s", attemptNum, status)
-			},
-		})
+	logger.Info("forceCompactContext: attempt %d/%d using %s prompt (max %d bytes)", attemptNum, maxCompactionAttempts, attemptDesc, maxBytes)
 
-		if err != nil {
-			logger.Error("forceCompactContext[%d]: summarization failed: %v", attemptNum, err)
-			summary = fallbackConversationSummary(messagesCopy)
-		} else {
-			summary = strings.TrimSpace(result.Summary)
-			logger.Info("forceCompactContext[%d]: summarized %d messages using %d chunks, %d total tokens, %d chars output",
-				attemptNum, len(messagesCopy), result.ChunksUsed, result.TotalTokens, len(summary))
-		}
+	result := o.summaryService().SummarizeConversation(context.Background(), summarized, summarizer.ConversationOptions{
+		Instruction: o.compactionInstruction(basePrompt, attemptDesc, attemptNum, summarized, latestUserPrompt),
+		MaxBytes:    maxBytes,
+		Progress: func(status string) {
+			logger.Debug("forceCompactContext[%d]: %s", attemptNum, status)
+		},
+	})
+	summary = result.Summary
+	if result.Fallback {
+		logger.Error("forceCompactContext[%d]: summarization failed, using fallback: %v", attemptNum, result.Err)
 	} else {
-		logger.Error("forceCompactContext[%d]: no summarization client available, using fallback", attemptNum)
-	}
-
-	if summary == "" {
-		logger.Error("forceCompactContext[%d]: summarization produced empty summary, using fallback", attemptNum)
-		summary = fallbackConversationSummary(messagesCopy)
+		logger.Info("forceCompactContext[%d]: summarized %d messages using %d chunks, %d total tokens, %d chars output",
+			attemptNum, len(summarized), result.ChunksUsed, result.TotalTokens, len(summary))
 	}
 
 	if summary == "" {
@@ -4056,7 +4303,7 @@ func (o *Orchestrator) forceCompactContext(modelID, systemPrompt string, session
 	}
 
 	summaryContent := fmt.Sprintf("Summary of earlier context (%s):\n%s", summaryLabel, summary)
-	userSection := buildUserCompactionSection(messagesCopy, perMessageTokens, contextWindow, latestUserPrompt)
+	userSection := buildUserCompactionSection(summarized, perMessageTokens, contextWindow, latestUserPrompt, o.compactionConfig())
 	if userSection != "" {
 		summaryContent = fmt.Sprintf("%s\n\n%s", summaryContent, userSection)
 	}
@@ -4069,6 +4316,7 @@ func (o *Orchestrator) forceCompactContext(modelID, systemPrompt string, session
 	// Track consecutive compactions - increment counter
 	o.compactionMu.Lock()
 	o.consecutiveCompactions++
+	o.totalCompactions++
 	o.lastCompactionTime = time.Now()
 	o.compactionMu.Unlock()
 
@@ -4136,12 +4384,16 @@ func (o *Orchestrator) getContextWindow(modelID string) int {
 		return 0
 	}
 
+	if window := o.config.ContextWindowOverride(modelID); window > 0 {
+		return window
+	}
+
 	if window := o.providerMgr.GetModelContextWindow(modelID); window > 0 {
 		return window
 	}
 
-	// Fallback to default context window
-	return 8192
+	// Fall back to a guess based on the model name
+	return heuristicContextWindow(modelID)
 }
 
 // resetCompactionAttempts resets the compaction attempt counter and consecutive compactions counter
@@ -4181,6 +4433,91 @@ func selectCompactionPrefix(perMessageTokens []int, totalTokens int) int {
 	return len(perMessageTokens)
 }
 
+// compactableTokens returns perMessageTokens without the tokens of pinned
+// messages, so selectCompactionPrefix extends past them: they are kept
+// verbatim and compacting them would not reduce the context.
+func compactableTokens(messages []*session.Message, perMessageTokens []int) []int {
+	if len(messages) != len(perMessageTokens) {
+		return perMessageTokens
+	}
+	tokens := make([]int, len(perMessageTokens))
+	for i, count := range perMessageTokens {
+		if messages[i] != nil && messages[i].Pinned {
+			continue
+		}
+		tokens[i] = count
+	}
+	return tokens
+}
+
+// unpinnedMessages returns the messages that compaction may summarize
+func unpinnedMessages(messages []*session.Message) []*session.Message {
+	result := make([]*session.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg != nil && !msg.Pinned {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// compactionFloors returns the number of messages a session needs before it
+// is compacted and the number of recent exchanges compaction leaves alone
+func (o *Orchestrator) compactionFloors() (minMessages, preserveRecent int) {
+	if o.config == nil {
+		return config.DefaultCompactionMinMessages, config.DefaultCompactionPreserveRecent
+	}
+	return o.config.Compaction.GetMinMessages(), o.config.Compaction.GetPreserveRecent()
+}
+
+// compactionConfig returns the compaction settings, nil for the defaults
+func (o *Orchestrator) compactionConfig() *config.CompactionConfig {
+	if o.config == nil {
+		return nil
+	}
+	return &o.config.Compaction
+}
+
+// clampCompactionPrefix limits prefixCount so the last preserveRecent
+// exchanges stay un-compacted and no tool call is separated from its results
+func clampCompactionPrefix(messages []*session.Message, prefixCount, preserveRecent int) int {
+	if maxPrefix := recentExchangesStart(messages, preserveRecent); prefixCount > maxPrefix {
+		prefixCount = maxPrefix
+	}
+	if prefixCount <= 0 {
+		return 0
+	}
+	return adjustCompactionBoundaryForTools(messages, prefixCount)
+}
+
+// recentExchangesStart returns the index of the first of the last keep
+// exchanges. An assistant message with tool calls and the tool results
+// following it form one exchange; any other message is an exchange of its own.
+func recentExchangesStart(messages []*session.Message, keep int) int {
+	start := len(messages)
+	for kept := 0; kept < keep && start > 0; kept++ {
+		start--
+		if !isToolResultMessage(messages[start]) {
+			continue
+		}
+		for start > 0 && isToolResultMessage(messages[start-1]) {
+			start--
+		}
+		if start > 0 && isToolCallMessage(messages[start-1]) {
+			start--
+		}
+	}
+	return start
+}
+
+func isToolResultMessage(msg *session.Message) bool {
+	return msg != nil && strings.EqualFold(msg.Role, "tool")
+}
+
+func isToolCallMessage(msg *session.Message) bool {
+	return msg != nil && strings.EqualFold(msg.Role, "assistant") && len(msg.ToolCalls) > 0
+}
+
 // adjustCompactionBoundaryForTools ensures we never compact one half of a tool
 // exchange and leave the other half dangling in the un-compacted tail.
 func adjustCompactionBoundaryForTools(messages []*session.Message, prefixCount int) int {
@@ -4224,7 +4561,10 @@ func adjustCompactionBoundaryForTools(messages []*session.Message, prefixCount i
 	return prefixCount
 }
 
-func buildUserCompactionSection(messages []*session.Message, perMessageTokens []int, contextWindow int, latestUserPrompt string) string {
+// buildUserCompactionSection carries the user prompts of the compacted
+// messages into the summary, verbatim or condensed depending on their share of
+// the context window. A nil cfg uses the default limits.
+func buildUserCompactionSection(messages []*session.Message, perMessageTokens []int, contextWindow int, latestUserPrompt string, cfg *config.CompactionConfig) string {
 	trimmedLatest := strings.TrimSpace(latestUserPrompt)
 	userPrompts := make([]string, 0)
 
@@ -4264,16 +4604,17 @@ func buildUserCompactionSection(messages []*session.Message, perMessageTokens []
 	}
 
 	userPercent := (userTokens * 100) / windowTokens
+	verbatimPercent := cfg.GetUserPromptVerbatimPercent()
 
 	var sb strings.Builder
 	if len(userPrompts) > 0 {
 		sb.WriteString("User prompt compaction:\n")
-		if userPercent < 5 {
-			sb.WriteString("Older prompts (<5% of context) unified verbatim:\n")
+		if userPercent < verbatimPercent {
+			fmt.Fprintf(&sb, "Older prompts (<%d%% of context) unified verbatim:\n", verbatimPercent)
 			sb.WriteString(strings.Join(userPrompts, "\n---\n"))
 		} else {
-			sb.WriteString("Older prompts (>=5% of context) condensed summary:\n")
-			sb.WriteString(compactUserPrompts(userPrompts))
+			fmt.Fprintf(&sb, "Older prompts (>=%d%% of context) condensed summary:\n", verbatimPercent)
+			sb.WriteString(compactUserPrompts(userPrompts, cfg))
 		}
 		if trimmedLatest != "" {
 			sb.WriteString("\n\n")
@@ -4289,23 +4630,49 @@ func buildUserCompactionSection(messages []*session.Message, perMessageTokens []
 	return strings.TrimSpace(sb.String())
 }
 
-func compactUserPrompts(prompts []string) string {
+func compactUserPrompts(prompts []string, cfg *config.CompactionConfig) string {
 	if len(prompts) == 0 {
 		return ""
 	}
 
 	if len(prompts) == 1 {
-		return condenseContent(prompts[0], 400)
+		return condenseContent(prompts[0], cfg.GetUserPromptChars())
 	}
 
+	limit := cfg.GetUserPromptListChars()
 	var sb strings.Builder
 	for i, prompt := range prompts {
-		fmt.Fprintf(&sb, "- #%d: %s\n", i+1, condenseContent(prompt, 200))
+		fmt.Fprintf(&sb, "- #%d: %s\n", i+1, condenseContent(prompt, limit))
 	}
 
 	return strings.TrimSpace(sb.String())
 }
 
+// compactionInstruction renders the configured compaction summary template.
+// The built-in instruction of the attempt is used if the template fails.
+func (o *Orchestrator) compactionInstruction(instruction, level string, attempt int, messages []*session.Message, latestUserPrompt string) string {
+	if o.config == nil || strings.TrimSpace(o.config.PromptTemplates.CompactionSummary) == "" {
+		return instruction
+	}
+	data := prompttemplate.CompactionData{
+		Instruction:      instruction,
+		Level:            level,
+		Attempt:          attempt,
+		MaxAttempts:      maxCompactionAttempts,
+		Messages:         make([]prompttemplate.CompactionMessage, 0, len(messages)),
+		LatestUserPrompt: latestUserPrompt,
+	}
+	for _, msg := range messages {
+		data.Messages = append(data.Messages, prompttemplate.CompactionMessage{Role: msg.Role, Content: msg.Content})
+	}
+	rendered, err := prompttemplate.RenderCompactionSummary(o.config.PromptTemplates.CompactionSummary, data)
+	if err != nil {
+		logger.Warn("compaction: %v, using built-in instruction", err)
+		return instruction
+	}
+	return rendered
+}
+
 func findLatestUserPrompt(messages []*session.Message) string {
 	for i := len(messages) - 1; i >= 0; i-- {
 		if strings.EqualFold(messages[i].Role, "user") {
@@ -4315,72 +4682,37 @@ func findLatestUserPrompt(messages []*session.Message) string {
 	return ""
 }
 
+// readOnly reports whether the agent may only read and search, either because
+// of the configuration or because the session is in read-only mode
+func (o *Orchestrator) readOnly() bool {
+	return (o.config != nil && o.config.ReadOnly) || (o.session != nil && o.session.IsReadOnly())
+}
+
+// dryRun reports whether mutating tool calls are simulated instead of executed
+func (o *Orchestrator) dryRun() bool {
+	return o.config != nil && o.config.DryRun
+}
+
+// summaryService wraps the summarize client in the shared summarization service
+func (o *Orchestrator) summaryService() *summarizer.Summarizer {
+	return summarizer.NewSummarizer(o.summarizeClient)
+}
+
 // buildConversationContent builds the conversation content for summarization
 func buildConversationContent(messages []*session.Message) string {
-	var sb strings.Builder
-	for _, msg := range messages {
-		role := formatRoleLabel(msg)
-		sb.WriteString(role)
-		sb.WriteString(": ")
-		sb.WriteString(strings.TrimSpace(msg.Content))
-		sb.WriteString("\n---\n")
-	}
-	return sb.String()
+	return summarizer.FormatConversation(messages)
 }
 
 func fallbackConversationSummary(messages []*session.Message) string {
-	if len(messages) == 0 {
-		return ""
-	}
-
-	var sb strings.Builder
-	sb.WriteString("Key points retained:\n")
-	for _, msg := range messages {
-		role := formatRoleLabel(msg)
-		sb.WriteString("- ")
-		sb.WriteString(role)
-		sb.WriteString(": ")
-		sb.WriteString(condenseContent(msg.Content, 200))
-		sb.WriteString("\n")
-	}
-
-	return strings.TrimSpace(sb.String())
+	return summarizer.FallbackConversationSummary(messages)
 }
 
 func condenseContent(content string, limit int) string {
-	if limit <= 0 {
-		return ""
-	}
-
-	collapsed := strings.Join(strings.Fields(content), " ")
-	if collapsed == "" {
-		return "(no content)"
-	}
-
-	runes := []rune(collapsed)
-	if len(runes) <= limit {
-		return collapsed
-	}
-
-	if limit <= 3 {
-		return string(runes[:limit])
-	}
-
-	return string(runes[:limit-3]) + "..."
+	return summarizer.CondenseContent(content, limit)
 }
 
 func formatRoleLabel(msg *session.Message) string {
-	role := strings.TrimSpace(msg.Role)
-	if role == "" {
-		role = "unknown"
-	}
-	runes := []rune(role)
-	runes[0] = unicode.ToUpper(runes[0])
-	label := string(runes)
-	if msg.ToolName != "" {
-		label += " (" + msg.ToolName + ")"
-	}
-	return label
+	return summarizer.RoleLabel(msg)
 }
 
 func heuristicContextWindow(modelID string) int {
@@ -4406,7 +4738,8 @@ func heuristicContextWindow(modelID string) int {
 	return 8192
 }
 
-// combineContexts returns a cancelable context that is cancelled when either input context is done.
+// combineContexts returns a cancelable context that is cancelled when either
+// input context is done, keeping the cancellation cause of the input.
 func combineContexts(primary, secondary context.Context) (context.Context, context.CancelFunc) {
 	switch {
 	case primary == nil && secondary == nil:
@@ -4416,31 +4749,41 @@ func combineContexts(primary, secondary context.Context) (context.Context, conte
 	case secondary == nil:
 		return context.WithCancel(primary)
 	default:
-		combined, cancel := context.WithCancel(primary)
+		combined, cancel := context.WithCancelCause(primary)
 		go func() {
 			select {
 			case <-combined.Done():
 			case <-secondary.Done():
-				cancel()
+				cancel(context.Cause(secondary))
 			}
 		}()
-		return combined, cancel
+		return combined, func() { cancel(nil) }
 	}
 }
 
-// Stop stops the current generation
+// Stop stops the current generation on behalf of the user. A pending pause
+// is dropped so the next prompt does not start paused; a loop waiting on it is
+// woken by the cancellation.
 func (o *Orchestrator) Stop() {
+	o.StopWithCause(ErrCancelledByUser)
+}
+
+// StopWithCause stops the current generation like Stop, recording cause as
+// the reason. The prompt's error then matches cause (see CancelCause).
+func (o *Orchestrator) StopWithCause(cause error) {
 	if o.cancel != nil {
-		o.cancel()
+		logger.Info("Stopping generation: %v", cause)
+		o.cancel(cause)
 		// Recreate context for next use
-		o.ctx, o.cancel = context.WithCancel(context.Background())
+		o.ctx, o.cancel = context.WithCancelCause(context.Background())
 	}
+	o.pause.clear()
 }
 
 // Close closes the orchestrator
 func (o *Orchestrator) Close() error {
 	if o.cancel != nil {
-		o.cancel()
+		o.cancel(ErrCancelledByShutdown)
 	}
 
 	if o.actorCancel != nil {
@@ -4521,6 +4864,10 @@ func (o *Orchestrator) Close() error {
 		}
 	}
 
+	if o.mcpManager != nil {
+		o.mcpManager.Close()
+	}
+
 	// Close the filesystem watcher if it's a CachedFS
 	if cachedFS, ok := o.fs.(*fs.CachedFS); ok {
 		if err := cachedFS.Close(); err != nil && firstErr == nil {
@@ -4531,24 +4878,97 @@ func (o *Orchestrator) Close() error {
 	return firstErr
 }
 
-// enhancedToolResultCallback forwards tool results with metadata to the UI
-func (o *Orchestrator) enhancedToolResultCallback(callback ToolResultCallback, toolName, toolID, result, errorMsg string, metadata *tools.ExecutionMetadata) error {
-	// For now, call the original callback
-	// In the future, we can extend the callback interface to include metadata
-	if err := callback(toolName, toolID, result, errorMsg); err != nil {
-		return err
+// authorizationOptions returns the options of the authorization actor for the
+// permanent authorizations in cfg and the directories of sess
+func authorizationOptions(cfg *config.Config, sess *session.Session, requireSandboxAuth bool, policy *tools.AuthorizationPolicy) *tools.AuthorizationOptions {
+	opts := &tools.AuthorizationOptions{
+		RequireSandboxAuth: requireSandboxAuth,
+		Policy:             policy,
+	}
+	if cfg != nil {
+		opts.AllowedCommands = make([]string, 0, len(cfg.AuthorizedCommands))
+		for prefix, enabled := range cfg.AuthorizedCommands {
+			if enabled {
+				opts.AllowedCommands = append(opts.AllowedCommands, prefix)
+			}
+		}
+
+		opts.AllowedDomains = make([]string, 0, len(cfg.AuthorizedDomains))
+		for domain, enabled := range cfg.AuthorizedDomains {
+			if enabled {
+				opts.AllowedDomains = append(opts.AllowedDomains, domain)
+			}
+		}
+
+		opts.DryRun = cfg.DryRun
+		opts.ReadOnly = cfg.ReadOnly
+	}
+
+	// Allow reading from sandbox output directory so the LLM can read large output files
+	if sess != nil {
+		if outputDir := sess.GetSandboxOutputDir(); outputDir != "" {
+			opts.AllowedDirs = append(opts.AllowedDirs, outputDir)
+		}
 	}
+	return opts
+}
 
-	// Note: Could store metadata for TUI access when we extend the callback interface
-	// This could be done via a side channel, context, or enhanced callback signature
-	// Current implementation uses progress callbacks for most metadata needs
+// startAuthorizationActor spawns the authorization actor for the current
+// filesystem and session and points the authorizer at it
+func (o *Orchestrator) startAuthorizationActor(opts *tools.AuthorizationOptions) error {
+	authorizationCtx, authorizationCancel := context.WithCancel(context.Background())
+	authActor := tools.NewAuthorizationActor("authorization", o.fs, o.session, o.summarizeClient, opts)
+	authRef, err := o.actorSystem.Spawn(authorizationCtx, "authorization", authActor, 32)
+	if err != nil {
+		authorizationCancel()
+		return fmt.Errorf("failed to start authorization actor: %w", err)
+	}
+	logger.Debug("Authorization actor spawned")
+	baseAuthorizer := tools.NewAuthorizationActorClient(authRef)
 
+	// Create secret detector and wrap authorizer for secret-based authorization
+	if features.Enabled["secret_based_auth"] {
+		o.authorizer = tools.NewSecretAwareAuthorizer(baseAuthorizer, authActor)
+	} else {
+		o.authorizer = baseAuthorizer
+	}
+	o.authOptions = opts
+	o.actorCancel = authorizationCancel
 	return nil
 }
 
+// enhancedToolResultCallback forwards tool results to the UI. While the
+// callback runs, the result's metadata is available via ToolResultMetadata.
+func (o *Orchestrator) enhancedToolResultCallback(callback ToolResultCallback, toolName, toolID, result, errorMsg string, metadata *tools.ExecutionMetadata) error {
+	if metadata != nil && toolID != "" {
+		o.toolResultMetadataMu.Lock()
+		if o.toolResultMetadata == nil {
+			o.toolResultMetadata = make(map[string]*tools.ExecutionMetadata)
+		}
+		o.toolResultMetadata[toolID] = metadata
+		o.toolResultMetadataMu.Unlock()
+
+		defer func() {
+			o.toolResultMetadataMu.Lock()
+			delete(o.toolResultMetadata, toolID)
+			o.toolResultMetadataMu.Unlock()
+		}()
+	}
+
+	return callback(toolName, toolID, result, errorMsg)
+}
+
+// ToolResultMetadata returns the execution metadata of the tool result with
+// the given ID. It is only available from within the tool result callback.
+func (o *Orchestrator) ToolResultMetadata(toolID string) *tools.ExecutionMetadata {
+	o.toolResultMetadataMu.RLock()
+	defer o.toolResultMetadataMu.RUnlock()
+	return o.toolResultMetadata[toolID]
+}
+
 // ExecuteTool executes a tool call with optional callbacks; approved bypasses authorization.
 func (o *Orchestrator) ExecuteTool(ctx context.Context, toolCall *tools.ToolCall, toolName string, progressCallback progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
-	ctx, cleanup := o.prepareShellExecutionContext(ctx, toolCall, toolName)
+	ctx, cleanup := o.prepareShellExecutionContext(ctx, toolCall, toolName, progressCallback)
 	if cleanup != nil {
 		defer cleanup()
 	}
@@ -4590,6 +5010,12 @@ func (o *Orchestrator) ExecuteTool(ctx context.Context, toolCall *tools.ToolCall
 		}
 	}
 
+	return o.executeToolAnsweringQuestions(ctx, toolCall, toolName, progressFn, toolCallCb, toolResultCb, approved)
+}
+
+// executeToolOnce runs a tool call through the tool executor, or the registry
+// if no executor is configured
+func (o *Orchestrator) executeToolOnce(ctx context.Context, toolCall *tools.ToolCall, toolName string, progressFn progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
 	if o.toolExecutor != nil {
 		return o.toolExecutor.ExecuteWithCallbacks(ctx, toolCall, toolName, progressFn, toolCallCb, toolResultCb, approved)
 	}
@@ -4601,7 +5027,7 @@ func (o *Orchestrator) ExecuteTool(ctx context.Context, toolCall *tools.ToolCall
 	return result, nil
 }
 
-func (o *Orchestrator) prepareShellExecutionContext(ctx context.Context, toolCall *tools.ToolCall, toolName string) (context.Context, func()) {
+func (o *Orchestrator) prepareShellExecutionContext(ctx context.Context, toolCall *tools.ToolCall, toolName string, progressCallback progress.Callback) (context.Context, func()) {
 	if toolName != "shell" || toolCall == nil {
 		return ctx, nil
 	}
@@ -4613,6 +5039,11 @@ func (o *Orchestrator) prepareShellExecutionContext(ctx context.Context, toolCal
 	ch := make(chan struct{}, 1)
 	o.setActiveShellChannel(ch)
 	newCtx := tools.ContextWithShellBackground(ctx, ch)
+	var redactor *secretdetect.Redactor
+	if o.toolRegistry != nil {
+		redactor = o.toolRegistry.ResultRedactor()
+	}
+	newCtx = tools.ContextWithShellOutput(newCtx, tools.ShellOutputStatus(progressCallback, redactor))
 	return newCtx, func() {
 		o.clearActiveShellChannel(ch)
 	}
@@ -4632,6 +5063,15 @@ func (o *Orchestrator) clearActiveShellChannel(ch chan struct{}) {
 	o.activeShellMu.Unlock()
 }
 
+// StopBackgroundJob sends signal (SIGTERM or SIGKILL) to a background job
+// started by this orchestrator's shell actor
+func (o *Orchestrator) StopBackgroundJob(ctx context.Context, jobID, signal string) error {
+	if o.shellActorClient == nil {
+		return fmt.Errorf("shell actor not available")
+	}
+	return o.shellActorClient.StopJob(ctx, jobID, signal)
+}
+
 // BackgroundCurrentShellJob requests that the currently running foreground shell command continue in the background.
 func (o *Orchestrator) BackgroundCurrentShellJob() error {
 	o.activeShellMu.Lock()
@@ -4712,7 +5152,7 @@ func (o *Orchestrator) TriggerPreconnect() {
 			}
 		}
 		modelIDs := []string{
-			o.providerMgr.GetOrchestrationModel(),
+			o.orchestrationModelID(),
 			o.getSummarizeModelID(),
 		}
 		attempted, warmed := o.providerMgr.WarmConnections(o.ctx, modelIDs...)
@@ -4727,16 +5167,22 @@ func (o *Orchestrator) TriggerPreconnect() {
 	}()
 }
 
-// GetContextFile returns the context file used to prime the LLM, if available.
+// GetContextFile returns the first configured context file used to prime the
+// LLM, if available.
 func (o *Orchestrator) GetContextFile() string {
-	exists, err := o.fs.Exists(o.ctx, llm.AgentsFileName)
-	if err != nil || !exists {
-		return ""
+	if files := o.GetContextFiles(); len(files) > 0 {
+		return files[0]
 	}
-	return llm.AgentsFileName
+	return ""
+}
+
+// GetContextFiles returns the existing context files injected into the
+// system prompt, in order.
+func (o *Orchestrator) GetContextFiles() []string {
+	return llm.ContextFilesForConfig(o.ctx, o.fs, "", o.config)
 }
 
-// GetExtendedContextFile returns the standard context file if present, otherwise falls back to README variants.
+// GetExtendedContextFile returns the first context file if present, otherwise falls back to README variants.
 func (o *Orchestrator) GetExtendedContextFile() string {
 	if path := o.GetContextFile(); path != "" {
 		return path
@@ -4762,11 +5208,15 @@ func (o *Orchestrator) GetInitPrompt() string {
 
 // GetFilesystem returns the filesystem instance for use in TUI autocomplete
 func (o *Orchestrator) GetFilesystem() fs.FileSystem {
+	o.workspaceMu.RLock()
+	defer o.workspaceMu.RUnlock()
 	return o.fs
 }
 
 // GetWorkingDir returns the working directory
 func (o *Orchestrator) GetWorkingDir() string {
+	o.workspaceMu.RLock()
+	defer o.workspaceMu.RUnlock()
 	return o.workingDir
 }
 
@@ -4780,6 +5230,17 @@ func (o *Orchestrator) GetSandboxManager() *sandbox.Manager {
 	return o.sandboxManager
 }
 
+// ApplyWorkspaceLandlock restricts shell commands to the read/write paths approved
+// for the workspace, in addition to the working directory and default paths.
+// Returns sandbox.ErrUnsupported on platforms without Landlock, in which case
+// the authorizer remains the only gate.
+func (o *Orchestrator) ApplyWorkspaceLandlock(cfg *sandbox.SandboxConfig) error {
+	if o.sandbox == nil || cfg == nil {
+		return nil
+	}
+	return o.sandbox.SetWorkspacePaths(cfg.AdditionalReadOnlyPaths, cfg.AdditionalReadWritePaths)
+}
+
 // SetSandboxAuthorizationCallback sets the callback for directory access authorization
 func (o *Orchestrator) SetSandboxAuthorizationCallback(cb sandbox.AuthorizationCallback) {
 	if o.sandboxManager != nil {
@@ -4891,6 +5352,12 @@ func (o *Orchestrator) SetSession(newSession *session.Session) {
 	}
 }
 
+// SessionStorageRef returns the storage actor that saves and autosaves the
+// current session, or nil if there is none
+func (o *Orchestrator) SessionStorageRef() *actor.ActorRef {
+	return o.sessionStorageRef
+}
+
 // SaveCurrentSession saves the current session to persistent storage
 func (o *Orchestrator) SaveCurrentSession(ctx context.Context) error {
 	if o.session == nil {
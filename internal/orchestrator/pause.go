@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/progress"
+)
+
+// pauseGate lets a human suspend the orchestration loop between iterations.
+// The loop blocks on the condition variable until resumed or cancelled.
+type pauseGate struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	paused bool
+}
+
+// condLocked returns the gate's condition variable, creating it on first use.
+// The caller must hold mu.
+func (g *pauseGate) condLocked() *sync.Cond {
+	if g.cond == nil {
+		g.cond = sync.NewCond(&g.mu)
+	}
+	return g.cond
+}
+
+// clear drops a pending pause without waking the loop. Used by Stop, where
+// the cancelled context ends the wait instead of letting the loop continue.
+func (g *pauseGate) clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paused = false
+}
+
+// errNothingToPause is returned by Pause while no prompt is being processed
+var errNothingToPause = errors.New("no generation is running")
+
+// Pause requests that the orchestration loop stops before its next iteration.
+// A running LLM call or tool execution is allowed to finish first. Pausing
+// while idle is rejected, so a later prompt does not start paused.
+func (o *Orchestrator) Pause() error {
+	o.pause.mu.Lock()
+	defer o.pause.mu.Unlock()
+
+	if o.activePrompts.Load() == 0 {
+		return errNothingToPause
+	}
+	if !o.pause.paused {
+		logger.Info("Orchestrator pause requested")
+	}
+	o.pause.paused = true
+	return nil
+}
+
+// endPrompt marks a prompt as finished and drops a pending pause once no
+// prompt is left, under the pause lock so Pause cannot slip in between
+func (o *Orchestrator) endPrompt() {
+	o.pause.mu.Lock()
+	defer o.pause.mu.Unlock()
+	if o.activePrompts.Add(-1) == 0 {
+		o.pause.paused = false
+	}
+}
+
+// Resume lets a paused orchestration loop continue
+func (o *Orchestrator) Resume() {
+	o.pause.mu.Lock()
+	defer o.pause.mu.Unlock()
+
+	if o.pause.paused {
+		logger.Info("Orchestrator resumed")
+	}
+	o.pause.paused = false
+	o.pause.condLocked().Broadcast()
+}
+
+// IsPaused reports whether a pause has been requested
+func (o *Orchestrator) IsPaused() bool {
+	o.pause.mu.Lock()
+	defer o.pause.mu.Unlock()
+	return o.pause.paused
+}
+
+// waitWhilePaused blocks the loop while the orchestrator is paused. It returns
+// the context's error if the generation is cancelled (e.g. by Stop) meanwhile.
+func (o *Orchestrator) waitWhilePaused(ctx context.Context, progressCallback progress.Callback) error {
+	o.pause.mu.Lock()
+	if !o.pause.paused {
+		o.pause.mu.Unlock()
+		return nil
+	}
+	o.pause.mu.Unlock()
+
+	dispatchProgress(progressCallback, progress.Update{
+		Message: "Paused, waiting for resume",
+		Mode:    progress.ReportJustStatus,
+	})
+
+	// Wake the waiter when the generation is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		o.pause.mu.Lock()
+		o.pause.condLocked().Broadcast()
+		o.pause.mu.Unlock()
+	})
+	defer stop()
+
+	o.pause.mu.Lock()
+	cond := o.pause.condLocked()
+	for o.pause.paused && ctx.Err() == nil {
+		cond.Wait()
+	}
+	o.pause.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dispatchProgress(progressCallback, progress.Update{
+		Message:   "Resumed",
+		Mode:      progress.ReportJustStatus,
+		Ephemeral: true,
+	})
+	return nil
+}
@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/progress"
+)
+
+func statusToolCallResponse(id string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		Content: "Checking status.",
+		ToolCalls: []map[string]interface{}{
+			{
+				"id":   id,
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      "status",
+					"arguments": "{}",
+				},
+			},
+		},
+		StopReason: "tool_use",
+	}
+}
+
+// pausingNotifier returns a progress callback that pauses the orchestrator
+// once the first tool call starts, and closes the channel once the loop
+// reports it is paused
+func pausingNotifier(t *testing.T, orch *Orchestrator) (progress.Callback, <-chan struct{}) {
+	paused := make(chan struct{})
+	var pauseOnce, pausedOnce sync.Once
+	return func(update progress.Update) error {
+		if strings.HasPrefix(update.Message, "Calling tool:") {
+			pauseOnce.Do(func() {
+				if err := orch.Pause(); err != nil {
+					t.Errorf("pause during generation failed: %v", err)
+				}
+			})
+		}
+		if update.Message == "Paused, waiting for resume" {
+			pausedOnce.Do(func() { close(paused) })
+		}
+		return nil
+	}, paused
+}
+
+func TestPauseBlocksFurtherLLMCallsUntilResume(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	mockClient := newSequentialMockClient(
+		statusToolCallResponse("call_1"),
+		&llm.CompletionResponse{Content: "All done.", StopReason: "stop"},
+	)
+	orch.orchestrationClient = mockClient
+
+	// Pause while the first iteration's tool call runs
+	progressCb, paused := pausingNotifier(t, orch)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- orch.ProcessPrompt(context.Background(), "check status", progressCb, nil, nil, nil, nil, nil)
+	}()
+
+	select {
+	case <-paused:
+	case err := <-done:
+		t.Fatalf("ProcessPrompt finished without pausing: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("loop did not pause")
+	}
+
+	// No further LLM calls may happen while paused
+	time.Sleep(100 * time.Millisecond)
+	if got := mockClient.RequestCount(); got != 1 {
+		t.Fatalf("expected 1 LLM request while paused, got %d", got)
+	}
+	if !orch.IsPaused() {
+		t.Fatal("expected orchestrator to report paused")
+	}
+
+	orch.Resume()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ProcessPrompt failed after resume: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("loop did not finish after resume")
+	}
+
+	if got := mockClient.RequestCount(); got != 2 {
+		t.Errorf("expected 2 LLM requests after resume, got %d", got)
+	}
+}
+
+func TestStopWhilePausedEndsGeneration(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	mockClient := newSequentialMockClient(statusToolCallResponse("call_1"))
+	orch.orchestrationClient = mockClient
+
+	progressCb, paused := pausingNotifier(t, orch)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- orch.ProcessPrompt(context.Background(), "check status", progressCb, nil, nil, nil, nil, nil)
+	}()
+
+	select {
+	case <-paused:
+	case <-time.After(10 * time.Second):
+		t.Fatal("loop did not pause")
+	}
+
+	orch.Stop()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled after stop, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("stop did not end the paused generation")
+	}
+
+	if got := mockClient.RequestCount(); got != 1 {
+		t.Errorf("expected no LLM requests after stop, got %d", got)
+	}
+	if orch.IsPaused() {
+		t.Error("expected stop to drop the pending pause")
+	}
+}
+
+func TestPauseWhileIdleIsRejected(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	if err := orch.Pause(); err == nil {
+		t.Fatal("expected pausing an idle orchestrator to fail")
+	}
+	if orch.IsPaused() {
+		t.Fatal("a rejected pause must not be recorded")
+	}
+
+	// The next prompt runs without waiting for a resume
+	orch.orchestrationClient = newSequentialMockClient(&llm.CompletionResponse{Content: "Done.", StopReason: "stop"})
+	done := make(chan error, 1)
+	go func() {
+		done <- orch.ProcessPrompt(context.Background(), "hello", nil, nil, nil, nil, nil, nil)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ProcessPrompt failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("prompt after a rejected pause did not finish")
+	}
+}
@@ -414,8 +414,8 @@ func dummyContextUsageCallback(freePercent int, contextWindow int) error {
 	return nil
 }
 
-func dummyAuthCallback(toolName string, params map[string]interface{}, reason string) (bool, error) {
-	return true, nil
+func dummyAuthCallback(toolName string, params map[string]interface{}, reason string) (AuthorizationResponse, error) {
+	return AuthorizationResponse{Approved: true}, nil
 }
 
 func dummyToolCallCallback(toolName, toolID string, parameters map[string]interface{}) error {
@@ -65,6 +65,45 @@ func TestProcessPromptSendsInitialMessages(t *testing.T) {
 	}
 }
 
+func TestProcessPromptAppliesPromptOverrides(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	mockClient := &captureRequestClient{}
+	orch.orchestrationClient = mockClient
+
+	temperature := 0.1
+	seed := 42
+	ctx := ContextWithPromptOverrides(context.Background(), PromptOverrides{Temperature: &temperature, Seed: &seed})
+	if err := orch.ProcessPrompt(ctx, "hello", nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("ProcessPrompt failed: %v", err)
+	}
+	if len(mockClient.requests) == 0 {
+		t.Fatal("expected a completion request")
+	}
+
+	req := mockClient.requests[0]
+	if req.Temperature != temperature {
+		t.Errorf("expected temperature override %v, got %v", temperature, req.Temperature)
+	}
+	if req.Seed == nil || *req.Seed != seed {
+		t.Errorf("expected seed override %d, got %v", seed, req.Seed)
+	}
+
+	// Without overrides the config defaults apply and no seed is sent
+	mockClient.requests = nil
+	if err := orch.ProcessPrompt(context.Background(), "again", nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("ProcessPrompt failed: %v", err)
+	}
+	req = mockClient.requests[0]
+	if req.Temperature != orch.config.Temperature || req.Seed != nil {
+		t.Errorf("expected config temperature %v and no seed, got %v / %v", orch.config.Temperature, req.Temperature, req.Seed)
+	}
+}
+
 type captureRequestClient struct {
 	requests []*llm.CompletionRequest
 }
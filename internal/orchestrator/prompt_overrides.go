@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+// PromptOverrides pins sampling parameters for a single ProcessPrompt call,
+// e.g. for reproducible evals. Unset fields fall back to the config defaults.
+type PromptOverrides struct {
+	Temperature *float64
+	Seed        *int // Only sent to providers that support seeding
+}
+
+type promptOverridesKey struct{}
+
+// ContextWithPromptOverrides attaches per-prompt overrides to ctx. Pass the
+// returned context to ProcessPrompt (or ProcessPromptWithVerification).
+func ContextWithPromptOverrides(ctx context.Context, overrides PromptOverrides) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, promptOverridesKey{}, overrides)
+}
+
+func promptOverridesFromContext(ctx context.Context) (PromptOverrides, bool) {
+	if ctx == nil {
+		return PromptOverrides{}, false
+	}
+	overrides, ok := ctx.Value(promptOverridesKey{}).(PromptOverrides)
+	return overrides, ok
+}
+
+// applyPromptOverrides sets the overrides carried by ctx on req. It runs after
+// the model-specific defaults so an explicit override always wins.
+func applyPromptOverrides(ctx context.Context, req *llm.CompletionRequest) {
+	overrides, ok := promptOverridesFromContext(ctx)
+	if !ok || req == nil {
+		return
+	}
+	if overrides.Temperature != nil {
+		req.Temperature = *overrides.Temperature
+	}
+	if overrides.Seed != nil {
+		seed := *overrides.Seed
+		req.Seed = &seed
+	}
+}
@@ -0,0 +1,150 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/orchestrator/loop"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// StopReason describes why processing a prompt stopped
+type StopReason string
+
+const (
+	// StopReasonCompleted means the model answered without further tool calls
+	StopReasonCompleted StopReason = "completed"
+	// StopReasonMaxIterations means the loop hit its iteration limit
+	StopReasonMaxIterations StopReason = "max_iterations"
+	// StopReasonLoopDetected means a repetitive pattern stopped the loop
+	StopReasonLoopDetected StopReason = "loop_detected"
+	// StopReasonCancelled means the context was cancelled
+	StopReasonCancelled StopReason = "cancelled"
+	// StopReasonTimeout means the context deadline was exceeded
+	StopReasonTimeout StopReason = "timeout"
+	// StopReasonError means an error ended the prompt
+	StopReasonError StopReason = "error"
+)
+
+// PromptUsage is the token usage and cost accrued while processing a prompt
+type PromptUsage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Result summarizes how a prompt was processed
+type Result struct {
+	Reason        StopReason  `json:"reason"`
+	Cause         string      `json:"cause,omitempty"` // Why the prompt was cancelled, e.g. "stopped by user"
+	Iterations    int         `json:"iterations"`
+	AutoContinues int         `json:"auto_continues"`
+	Compactions   int         `json:"compactions"`
+	Usage         PromptUsage `json:"usage"`
+}
+
+// promptResultTracker snapshots the session counters when a prompt starts, so
+// the result reports only what the prompt itself accrued
+type promptResultTracker struct {
+	orch        *Orchestrator
+	usage       PromptUsage
+	compactions int
+}
+
+func (o *Orchestrator) newPromptResultTracker() *promptResultTracker {
+	return &promptResultTracker{
+		orch:        o,
+		usage:       o.sessionUsage(),
+		compactions: o.compactionCount(),
+	}
+}
+
+// finish builds the result from the loop result (nil when the loop didn't
+// run) and the error that ended the prompt
+func (t *promptResultTracker) finish(loopResult *loop.Result, err error) *Result {
+	result := &Result{
+		Reason:      stopReasonFor(loopResult, err),
+		Compactions: t.orch.compactionCount() - t.compactions,
+	}
+	if cause := CancelCause(err); cause != nil {
+		result.Cause = cause.Error()
+	}
+	if loopResult != nil {
+		result.Iterations = loopResult.IterationsExecuted
+		result.AutoContinues = loopResult.AutoContinueAttempts
+	}
+
+	usage := t.orch.sessionUsage()
+	result.Usage = PromptUsage{
+		PromptTokens:     usage.PromptTokens - t.usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens - t.usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens - t.usage.TotalTokens,
+		Cost:             usage.Cost - t.usage.Cost,
+	}
+	t.orch.session.RecordPromptUsage(session.UsageRecord{
+		Timestamp:        time.Now(),
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		Cost:             result.Usage.Cost,
+	})
+	return result
+}
+
+// add accumulates the counters of another result, keeping its stop reason
+func (r *Result) add(other *Result) {
+	if other == nil {
+		return
+	}
+	r.Reason = other.Reason
+	r.Cause = other.Cause
+	r.Iterations += other.Iterations
+	r.AutoContinues += other.AutoContinues
+	r.Compactions += other.Compactions
+	r.Usage.PromptTokens += other.Usage.PromptTokens
+	r.Usage.CompletionTokens += other.Usage.CompletionTokens
+	r.Usage.TotalTokens += other.Usage.TotalTokens
+	r.Usage.Cost += other.Usage.Cost
+}
+
+func stopReasonFor(loopResult *loop.Result, err error) StopReason {
+	switch {
+	case CancelCause(err) != nil:
+		return StopReasonCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return StopReasonTimeout
+	case errors.Is(err, context.Canceled):
+		return StopReasonCancelled
+	case err != nil:
+		return StopReasonError
+	case loopResult == nil:
+		return StopReasonCompleted
+	case loopResult.LoopDetected:
+		return StopReasonLoopDetected
+	case loopResult.HitIterationLimit:
+		return StopReasonMaxIterations
+	default:
+		return StopReasonCompleted
+	}
+}
+
+// sessionUsage returns the usage counters of the session
+func (o *Orchestrator) sessionUsage() PromptUsage {
+	stats := o.session.GetUsageStats()
+	usage := PromptUsage{}
+	usage.PromptTokens, _ = stats["total_prompt_tokens"].(int)
+	usage.CompletionTokens, _ = stats["total_completion_tokens"].(int)
+	usage.TotalTokens, _ = stats["total_tokens"].(int)
+	usage.Cost, _ = stats["total_cost"].(float64)
+	return usage
+}
+
+// compactionCount returns the number of compactions since the orchestrator
+// was created
+func (o *Orchestrator) compactionCount() int {
+	o.compactionMu.Lock()
+	defer o.compactionMu.Unlock()
+	return o.totalCompactions
+}
@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestProcessPromptWithResultCompleted(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+	orch.orchestrationClient = newSequentialMockClient(scriptedToolCall("call_1"), scriptedAnswer("No todos."))
+
+	result, err := orch.ProcessPromptWithResult(context.Background(), "list the todos", nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessPromptWithResult failed: %v", err)
+	}
+	if result.Reason != StopReasonCompleted {
+		t.Errorf("expected reason %q, got %q", StopReasonCompleted, result.Reason)
+	}
+	if result.Iterations != 2 {
+		t.Errorf("expected 2 iterations, got %d", result.Iterations)
+	}
+	if result.Compactions != 0 {
+		t.Errorf("expected no compactions, got %d", result.Compactions)
+	}
+}
+
+func TestProcessPromptWithResultMaxIterations(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+	orch.config.Loop.MaxIterations = 3
+	orch.loopConfig = nil
+
+	callCount := 0
+	orch.orchestrationClient = &countingToolCallClient{callCount: &callCount, maxCalls: 100}
+
+	result, err := orch.ProcessPromptWithResult(context.Background(), "keep calling tools", nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessPromptWithResult failed: %v", err)
+	}
+	if result.Reason != StopReasonMaxIterations {
+		t.Errorf("expected reason %q, got %q", StopReasonMaxIterations, result.Reason)
+	}
+	if callCount >= 100 {
+		t.Errorf("expected the iteration limit to stop the loop, got %d calls", callCount)
+	}
+}
+
+func TestProcessPromptWithResultCancelled(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+	client := newSequentialMockClient(scriptedAnswer("Too late."))
+	orch.orchestrationClient = client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := orch.ProcessPromptWithResult(ctx, "never answered", nil, nil, nil, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for a cancelled prompt")
+	}
+	if result == nil || result.Reason != StopReasonCancelled {
+		t.Fatalf("expected reason %q, got %+v", StopReasonCancelled, result)
+	}
+	if client.RequestCount() != 0 {
+		t.Errorf("expected no LLM requests, got %d", client.RequestCount())
+	}
+}
+
+func TestProcessPromptWithResultNoModel(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.orchestrationClient = nil
+
+	result, err := orch.ProcessPromptWithResult(context.Background(), "hello", nil, nil, nil, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error without an orchestration model")
+	}
+	if result.Reason != StopReasonError || result.Iterations != 0 {
+		t.Errorf("expected an error result without iterations, got %+v", result)
+	}
+}
+
+func TestProcessPromptWithResultReportsUsage(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	answer := scriptedAnswer("Done.")
+	answer.Usage = map[string]interface{}{"prompt_tokens": float64(1000), "completion_tokens": float64(50), "total_tokens": float64(1050), "cost": 0.002}
+	orch.orchestrationClient = newSequentialMockClient(answer)
+
+	result, err := orch.ProcessPromptWithResult(context.Background(), "hello", nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessPromptWithResult failed: %v", err)
+	}
+	if result.Usage.PromptTokens != 1000 || result.Usage.CompletionTokens != 50 || result.Usage.TotalTokens != 1050 {
+		t.Errorf("expected the usage of the response, got %+v", result.Usage)
+	}
+	if result.Usage.Cost <= 0 {
+		t.Errorf("expected a cost, got %v", result.Usage.Cost)
+	}
+}
+
+func TestProcessPromptWithResultRecordsUsageHistory(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+
+	answer := func(content string, prompt, completion int) *llm.CompletionResponse {
+		resp := scriptedAnswer(content)
+		resp.Usage = map[string]interface{}{"prompt_tokens": float64(prompt), "completion_tokens": float64(completion), "cost": 0.002}
+		return resp
+	}
+	orch.orchestrationClient = newSequentialMockClient(answer("First.", 1000, 50), answer("Second.", 1500, 80))
+
+	for i, want := range []int{1050, 1580} {
+		result, err := orch.ProcessPromptWithResult(context.Background(), "hello", nil, nil, nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("prompt %d failed: %v", i+1, err)
+		}
+		if result.Usage.TotalTokens != want {
+			t.Errorf("prompt %d: expected %d tokens, got %d", i+1, want, result.Usage.TotalTokens)
+		}
+	}
+
+	history := orch.session.GetUsageHistory()
+	if len(history) != 2 {
+		t.Fatalf("expected one usage record per prompt, got %+v", history)
+	}
+	if history[0].PromptTokens != 1000 || history[1].CompletionTokens != 80 {
+		t.Errorf("unexpected usage history %+v", history)
+	}
+	if total := session.SumUsage(history); total.TotalTokens != 2630 || total.TotalTokens != orch.session.GetTotalTokens() {
+		t.Errorf("expected the history to add up to the session total of 2630 tokens, got %d", total.TotalTokens)
+	}
+}
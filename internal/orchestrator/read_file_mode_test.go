@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/provider"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+var readFileModeFamilies = []llm.ModelFamily{llm.FamilyUnknown, llm.FamilyClaude45, llm.FamilyGPT5, llm.FamilyZaiGLM}
+
+func TestReadFileModeSelectsToolRegardlessOfFamily(t *testing.T) {
+	for mode, wantNumbered := range map[string]bool{
+		config.ReadFileModeNumbered: true,
+		config.ReadFileModePlain:    false,
+	} {
+		orch := &Orchestrator{config: &config.Config{ReadFileMode: mode}}
+		for _, family := range readFileModeFamilies {
+			spec, _ := orch.getReadFileToolSpec(family, nil)
+			_, numbered := spec.(*tools.ReadFileNumberedSpec)
+			if numbered != wantNumbered {
+				t.Errorf("mode %s, family %v: expected numbered=%v, got %T", mode, family, wantNumbered, spec)
+			}
+		}
+	}
+}
+
+func TestReadFileModeAutoKeepsFamilyHeuristic(t *testing.T) {
+	for _, mode := range []string{"", config.ReadFileModeAuto} {
+		orch := &Orchestrator{config: &config.Config{ReadFileMode: mode}}
+		for _, family := range readFileModeFamilies {
+			spec, _ := orch.getReadFileToolSpec(family, nil)
+			if _, plain := spec.(*tools.ReadFileToolSpec); !plain {
+				t.Errorf("mode %q, family %v: expected the plain read_file tool, got %T", mode, family, spec)
+			}
+		}
+	}
+}
+
+func TestReadFileModeRegistersNumberedTool(t *testing.T) {
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	orch, err := NewOrchestrator(&config.Config{WorkingDir: t.TempDir(), ReadFileMode: config.ReadFileModeNumbered}, providerMgr, false)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	t.Cleanup(func() { orch.Stop() })
+
+	for _, spec := range orch.toolRegistry.ListSpecs() {
+		if spec.Name() != tools.ToolNameReadFile {
+			continue
+		}
+		if _, numbered := spec.(*tools.ReadFileNumberedSpec); !numbered {
+			t.Errorf("expected the numbered read_file tool to be registered, got %T", spec)
+		}
+		return
+	}
+	t.Fatal("expected read_file to be registered")
+}
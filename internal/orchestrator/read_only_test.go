@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/provider"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+func newReadOnlyTestOrchestrator(t *testing.T, readOnly bool) *Orchestrator {
+	t.Helper()
+
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	orch, err := NewOrchestrator(&config.Config{WorkingDir: t.TempDir(), ReadOnly: readOnly}, providerMgr, false)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	t.Cleanup(func() { orch.Stop() })
+	return orch
+}
+
+var writeToolNames = []string{
+	tools.ToolNameCreateFile,
+	tools.ToolNameEditFile,
+	tools.ToolNameReplaceFile,
+	tools.ToolNameReplaceInFiles,
+	tools.ToolNameShell,
+	tools.ToolNameGoSandbox,
+	tools.ToolNameStopProgram,
+}
+
+func TestReadOnlyModeRegistersOnlyReadTools(t *testing.T) {
+	orch := newReadOnlyTestOrchestrator(t, true)
+
+	for _, name := range writeToolNames {
+		if _, ok := orch.toolRegistry.GetExecutor(name); ok {
+			t.Errorf("expected %s not to be registered in read-only mode", name)
+		}
+	}
+	if _, ok := orch.toolRegistry.GetExecutor(tools.ToolNameReadFile); !ok {
+		t.Error("expected read_file to be registered in read-only mode")
+	}
+	for _, spec := range orch.toolRegistry.ListSpecs() {
+		if !tools.IsReadOnlyTool(spec.Name()) {
+			t.Errorf("unexpected tool %s in read-only mode", spec.Name())
+		}
+	}
+
+	decision, err := orch.authorizer.Authorize(context.Background(), tools.ToolNameCreateFile, map[string]interface{}{"path": "new.txt", "content": "x"})
+	if err != nil {
+		t.Fatalf("Authorize returned error: %v", err)
+	}
+	if decision.Allowed || !strings.Contains(decision.Reason, "Read-only mode") {
+		t.Errorf("expected write attempt to be denied, got %+v", decision)
+	}
+}
+
+func TestSetReadOnlyRebuildsTools(t *testing.T) {
+	orch := newReadOnlyTestOrchestrator(t, false)
+
+	if _, ok := orch.toolRegistry.GetExecutor(tools.ToolNameCreateFile); !ok {
+		t.Fatal("expected create_file to be registered by default")
+	}
+
+	orch.SetReadOnly(true)
+	if !orch.session.IsReadOnly() {
+		t.Error("expected the session to be read-only")
+	}
+	if _, ok := orch.toolRegistry.GetExecutor(tools.ToolNameCreateFile); ok {
+		t.Error("expected create_file to be removed in read-only mode")
+	}
+
+	orch.SetReadOnly(false)
+	if _, ok := orch.toolRegistry.GetExecutor(tools.ToolNameCreateFile); !ok {
+		t.Error("expected create_file to be registered again")
+	}
+}
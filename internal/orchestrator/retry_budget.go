@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errRetryBudgetExhausted is returned by completeWithRetry when the current
+// prompt run has used up its retries
+var errRetryBudgetExhausted = errors.New("retry budget for this run exhausted")
+
+// runRetryBudget caps the LLM retries of a whole ProcessPrompt run, on top of
+// the per-call errorRetryMaxAttempts, so a persistently failing provider ends
+// the run instead of retrying every iteration from scratch.
+type runRetryBudget struct {
+	mu    sync.Mutex
+	limit int // 0 = unlimited
+	used  int
+}
+
+// reset starts a new run with limit retries (0 = unlimited)
+func (b *runRetryBudget) reset(limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = limit
+	b.used = 0
+}
+
+// take consumes one retry and reports whether it was available
+func (b *runRetryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit > 0 && b.used >= b.limit {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// status returns the retries used and the limit of the current run
+func (b *runRetryBudget) status() (used, limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used, b.limit
+}
+
+// resetRetryBudget starts the retry budget for a new prompt run
+func (o *Orchestrator) resetRetryBudget() {
+	limit := 0
+	if o.config != nil {
+		limit = o.config.Loop.GetMaxRunRetries()
+	}
+	o.retryBudget.reset(limit)
+}
+
+// waitBeforeRetry sleeps d unless ctx is cancelled first
+func (o *Orchestrator) waitBeforeRetry(ctx context.Context, d time.Duration) error {
+	if o.retrySleep != nil {
+		return o.retrySleep(ctx, d)
+	}
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/progress"
+)
+
+// failingClient fails every completion with a retryable provider error
+type failingClient struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *failingClient) CompleteWithRequest(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return nil, errors.New("status 503: service unavailable")
+}
+
+func (c *failingClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", errors.New("status 503: service unavailable")
+}
+
+func (c *failingClient) Stream(ctx context.Context, req *llm.CompletionRequest, callback func(chunk string) error) error {
+	return errors.New("status 503: service unavailable")
+}
+
+func (c *failingClient) GetModelName() string                    { return "failing-model" }
+func (c *failingClient) GetLastResponseID() string               { return "" }
+func (c *failingClient) SetPreviousResponseID(responseID string) {}
+
+func (c *failingClient) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestProcessPrompt_StopsAtRunRetryBudget(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+
+	orch.featureFlags.SetPlanningEnabled(false)
+	orch.config.Loop.MaxRunRetries = 3
+	orch.errorJudge = nil // use the built-in heuristics, which retry 503s up to the per-call limit
+	orch.retrySleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+
+	client := &failingClient{}
+	orch.orchestrationClient = client
+
+	var mu sync.Mutex
+	var streamed strings.Builder
+	progressCb := func(update progress.Update) error {
+		mu.Lock()
+		defer mu.Unlock()
+		streamed.WriteString(update.Message)
+		return nil
+	}
+
+	err := orch.ProcessPrompt(context.Background(), "do something", progressCb, nil, nil, nil, nil, nil)
+	if !errors.Is(err, errRetryBudgetExhausted) {
+		t.Fatalf("expected errRetryBudgetExhausted, got %v", err)
+	}
+
+	// One initial call plus the 3 retries of the run budget, well below the
+	// per-call limit
+	if calls := client.Calls(); calls != 4 {
+		t.Fatalf("expected 4 completion calls, got %d (per-call limit is %d)", calls, errorRetryMaxAttempts)
+	}
+
+	mu.Lock()
+	output := streamed.String()
+	mu.Unlock()
+	if !strings.Contains(output, "all 3 retries for this prompt are used up") {
+		t.Errorf("expected budget exhaustion message in stream, got %q", output)
+	}
+
+	// The next prompt starts with a fresh budget
+	err = orch.ProcessPrompt(context.Background(), "try again", nil, nil, nil, nil, nil, nil)
+	if !errors.Is(err, errRetryBudgetExhausted) {
+		t.Fatalf("expected errRetryBudgetExhausted on second run, got %v", err)
+	}
+	if calls := client.Calls(); calls != 8 {
+		t.Fatalf("expected the budget to reset for the next prompt, got %d calls", calls)
+	}
+}
+
+func TestRunRetryBudget(t *testing.T) {
+	var budget runRetryBudget
+	budget.reset(2)
+	if !budget.take() || !budget.take() {
+		t.Fatal("expected two retries to be available")
+	}
+	if budget.take() {
+		t.Fatal("expected third retry to be refused")
+	}
+	if used, limit := budget.status(); used != 2 || limit != 2 {
+		t.Fatalf("expected 2/2 used, got %d/%d", used, limit)
+	}
+
+	budget.reset(0)
+	for i := 0; i < 100; i++ {
+		if !budget.take() {
+			t.Fatalf("expected unlimited budget, refused after %d retries", i)
+		}
+	}
+}
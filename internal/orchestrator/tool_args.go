@@ -0,0 +1,140 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// maxRawToolArgumentsInError caps the raw arguments echoed back to the model
+// when they cannot be parsed
+const maxRawToolArgumentsInError = 2000
+
+// parseToolArguments parses the JSON arguments of a tool call. Models
+// sometimes emit almost-JSON (trailing commas, single quotes, raw newlines in
+// strings, code fences); such arguments are repaired before giving up.
+// Truncated arguments are never completed, since whatever was cut off would be
+// silently lost. The error includes the raw arguments so the model can
+// correct the call.
+func parseToolArguments(argsJSON string) (map[string]interface{}, error) {
+	var args map[string]interface{}
+	err := json.Unmarshal([]byte(argsJSON), &args)
+	if err == nil {
+		return args, nil
+	}
+
+	repaired, truncated := repairToolArgumentsJSON(argsJSON)
+	if truncated {
+		err = fmt.Errorf("the arguments are truncated (an unterminated string, object or array)")
+	} else if repaired != argsJSON {
+		var recovered map[string]interface{}
+		if json.Unmarshal([]byte(repaired), &recovered) == nil && recovered != nil {
+			logger.Info("Recovered malformed tool arguments: %v", err)
+			return recovered, nil
+		}
+	}
+
+	raw := argsJSON
+	if len(raw) > maxRawToolArgumentsInError {
+		cut := maxRawToolArgumentsInError
+		for cut > 0 && !utf8.RuneStart(raw[cut]) {
+			cut--
+		}
+		raw = raw[:cut] + "... (truncated)"
+	}
+	return nil, fmt.Errorf("%v\nRaw arguments: %s\nSend the arguments again as a valid JSON object", err, raw)
+}
+
+// repairToolArgumentsJSON applies lossless fixes to almost-JSON: code fences
+// are stripped, single-quoted strings become double-quoted, control characters
+// in strings are escaped and trailing commas are dropped. Empty arguments
+// become an empty object. truncated is true if a string, object or array is
+// left open; such input is reported instead of being completed.
+func repairToolArgumentsJSON(input string) (repaired string, truncated bool) {
+	text := strings.TrimSpace(input)
+	if text == "" {
+		return "{}", false
+	}
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
+	}
+
+	var sb strings.Builder
+	var closers []byte
+	var quote byte // quote character of the current string, 0 outside strings
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if quote != 0 {
+			switch {
+			case c == '\\' && i+1 < len(text):
+				next := text[i+1]
+				i++
+				if next == '\'' {
+					// \' is not a valid JSON escape
+					sb.WriteByte('\'')
+				} else {
+					sb.WriteByte('\\')
+					sb.WriteByte(next)
+				}
+			case c == quote:
+				sb.WriteByte('"')
+				quote = 0
+			case c == '"':
+				sb.WriteString(`\"`)
+			case c == '\n':
+				sb.WriteString(`\n`)
+			case c == '\r':
+				sb.WriteString(`\r`)
+			case c == '\t':
+				sb.WriteString(`\t`)
+			case c < 0x20:
+				fmt.Fprintf(&sb, `\u%04x`, c)
+			default:
+				sb.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+			sb.WriteByte('"')
+		case '{':
+			closers = append(closers, '}')
+			sb.WriteByte(c)
+		case '[':
+			closers = append(closers, ']')
+			sb.WriteByte(c)
+		case '}', ']':
+			trimTrailingComma(&sb)
+			if len(closers) > 0 {
+				closers = closers[:len(closers)-1]
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), quote != 0 || len(closers) > 0
+}
+
+// trimTrailingComma removes a comma (and the whitespace after it) at the end
+// of the builder
+func trimTrailingComma(sb *strings.Builder) {
+	s := strings.TrimRight(sb.String(), " \t\r\n")
+	if !strings.HasSuffix(s, ",") {
+		return
+	}
+	s = strings.TrimSuffix(s, ",")
+	sb.Reset()
+	sb.WriteString(s)
+}
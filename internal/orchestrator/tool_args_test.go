@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseToolArgumentsRecoversTrailingComma(t *testing.T) {
+	args, err := parseToolArguments(`{"path": "main.go", "lines": [1, 2,],}`)
+	if err != nil {
+		t.Fatalf("expected trailing commas to be recovered, got %v", err)
+	}
+	if args["path"] != "main.go" {
+		t.Errorf("unexpected path %v", args["path"])
+	}
+	if lines, ok := args["lines"].([]interface{}); !ok || len(lines) != 2 {
+		t.Errorf("unexpected lines %v", args["lines"])
+	}
+}
+
+func TestParseToolArgumentsRecoversCommonMistakes(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		key  string
+		want interface{}
+	}{
+		{"single quotes", `{'path': 'it\'s "here".go'}`, "path", `it's "here".go`},
+		{"raw newline", "{\"content\": \"line 1\nline 2\"}", "content", "line 1\nline 2"},
+		{"code fence", "```json\n{\"path\": \"a.go\"}\n```", "path", "a.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := parseToolArguments(tt.raw)
+			if err != nil {
+				t.Fatalf("expected arguments to be recovered, got %v", err)
+			}
+			if args[tt.key] != tt.want {
+				t.Errorf("expected %s=%#v, got %#v", tt.key, tt.want, args[tt.key])
+			}
+		})
+	}
+
+	args, err := parseToolArguments("  ")
+	if err != nil || len(args) != 0 {
+		t.Errorf("expected empty arguments to parse as an empty object, got %v (err %v)", args, err)
+	}
+}
+
+func TestParseToolArgumentsIrrecoverable(t *testing.T) {
+	raw := `read_file(path=main.go)`
+	args, err := parseToolArguments(raw)
+	if err == nil {
+		t.Fatalf("expected an error, got %v", args)
+	}
+	if !strings.Contains(err.Error(), "Raw arguments: "+raw) {
+		t.Errorf("expected the raw arguments in the error, got %q", err.Error())
+	}
+}
+
+func TestParseToolArgumentsRejectsTruncated(t *testing.T) {
+	for _, raw := range []string{
+		`{"command": "go test ./...", "timeout": 30`,
+		`{"path": "main.go", "content": "package main`,
+		`{"paths": ["a.go", "b.go"`,
+	} {
+		args, err := parseToolArguments(raw)
+		if err == nil {
+			t.Errorf("expected truncated arguments %q to be rejected, got %v", raw, args)
+			continue
+		}
+		if !strings.Contains(err.Error(), "truncated") || !strings.Contains(err.Error(), "Raw arguments: "+raw) {
+			t.Errorf("expected a truncation error with the raw arguments, got %q", err.Error())
+		}
+	}
+}
+
+func TestParseToolArgumentsTruncatesRawOnRuneBoundary(t *testing.T) {
+	// The two-byte runes straddle the byte limit
+	raw := "x" + strings.Repeat("ü", maxRawToolArgumentsInError)
+	_, err := parseToolArguments(raw)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !utf8.ValidString(err.Error()) {
+		t.Errorf("expected the echoed arguments to be valid UTF-8, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "... (truncated)") {
+		t.Errorf("expected the raw arguments to be truncated, got %q", err.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"encoding/json"
+)
+
+// deduplicateToolCalls reports whether identical tool calls within one
+// response should be executed only once (opt-in, since repeating a call with
+// side effects can be intentional)
+func (o *Orchestrator) deduplicateToolCalls() bool {
+	return o.config != nil && o.config.Loop.DeduplicateToolCalls
+}
+
+// toolCallDedupKey identifies a tool call by name and parameters. Map keys are
+// marshalled in sorted order, so argument order does not matter.
+func toolCallDedupKey(toolName string, args map[string]interface{}) (string, bool) {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return toolName + "\x00" + string(argsJSON), true
+}
+
+// toolCallDeduplicator maps each tool call index to the earlier identical call
+// whose result it reuses
+type toolCallDeduplicator struct {
+	first      map[string]int
+	duplicates map[int]int
+}
+
+func newToolCallDeduplicator(enabled bool) *toolCallDeduplicator {
+	if !enabled {
+		return nil
+	}
+	return &toolCallDeduplicator{
+		first:      make(map[string]int),
+		duplicates: make(map[int]int),
+	}
+}
+
+// observe records call idx and reports whether it duplicates an earlier call.
+// A nil deduplicator never reports duplicates.
+func (d *toolCallDeduplicator) observe(idx int, toolName string, args map[string]interface{}) bool {
+	if d == nil {
+		return false
+	}
+	key, ok := toolCallDedupKey(toolName, args)
+	if !ok {
+		return false
+	}
+	if firstIdx, seen := d.first[key]; seen {
+		d.duplicates[idx] = firstIdx
+		return true
+	}
+	d.first[key] = idx
+	return false
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+func duplicateReadFileCalls() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"id":   "call-1",
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":      "read_file",
+				"arguments": `{"path":"main.go","from_line":1}`,
+			},
+		},
+		{
+			"id":   "call-2",
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":      "read_file",
+				"arguments": `{"from_line":1,"path":"main.go"}`,
+			},
+		},
+		{
+			"id":   "call-3",
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":      "read_file",
+				"arguments": `{"path":"other.go"}`,
+			},
+		},
+	}
+}
+
+func runDedupToolCalls(t *testing.T, dedup bool) (*session.Session, int32) {
+	t.Helper()
+
+	var executions int32
+	execFn := func(ctx context.Context, call *tools.ToolCall, toolName string, progressCb progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
+		atomic.AddInt32(&executions, 1)
+		path, _ := call.Parameters["path"].(string)
+		return &tools.ToolResult{ID: call.ID, Result: "contents of " + path}, nil
+	}
+
+	sess := session.NewSession("test", ".")
+	orch := &Orchestrator{config: &config.Config{Loop: config.LoopConfig{DeduplicateToolCalls: dedup}}}
+	if err := orch.processToolCalls(context.Background(), duplicateReadFileCalls(), sess, nil, nil, nil, nil, execFn); err != nil {
+		t.Fatalf("processToolCalls returned error: %v", err)
+	}
+	return sess, atomic.LoadInt32(&executions)
+}
+
+func TestProcessToolCallsDeduplicatesIdenticalCalls(t *testing.T) {
+	sess, executions := runDedupToolCalls(t, true)
+	if executions != 2 {
+		t.Fatalf("expected 2 executions (duplicate collapsed), got %d", executions)
+	}
+
+	messages := sess.GetMessages()
+	if len(messages) != 3 {
+		t.Fatalf("expected a tool result for every call, got %d messages", len(messages))
+	}
+	want := []struct{ id, content string }{
+		{"call-1", "contents of main.go"},
+		{"call-2", "contents of main.go"},
+		{"call-3", "contents of other.go"},
+	}
+	for i, w := range want {
+		if messages[i].ToolID != w.id || messages[i].ToolName != "read_file" || messages[i].Content != w.content {
+			t.Errorf("message %d: expected %s with %q, got %+v", i, w.id, w.content, messages[i])
+		}
+	}
+}
+
+func TestProcessToolCallsDeduplicationIsOptIn(t *testing.T) {
+	sess, executions := runDedupToolCalls(t, false)
+	if executions != 3 {
+		t.Fatalf("expected every call to execute without deduplication, got %d", executions)
+	}
+	if got := len(sess.GetMessages()); got != 3 {
+		t.Fatalf("expected 3 tool messages, got %d", got)
+	}
+}
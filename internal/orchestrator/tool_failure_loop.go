@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// repeatedFailureDetailLimit caps the arguments and error quoted in notices
+const repeatedFailureDetailLimit = 200
+
+// failingToolCallTracker detects a model calling the same tool with the same
+// parameters again and again although the call keeps failing, e.g. reading a
+// file that does not exist. It lives for the iterations of one prompt.
+type failingToolCallTracker struct {
+	threshold int            // Failures in a row that count as repeated (0 = disabled)
+	counts    map[string]int // call signature -> failures in a row
+}
+
+// repeatedToolFailure describes a tool call that failed threshold times or more
+type repeatedToolFailure struct {
+	toolName string
+	args     string
+	err      string
+	count    int
+}
+
+func newFailingToolCallTracker(threshold int) *failingToolCallTracker {
+	return &failingToolCallTracker{
+		threshold: threshold,
+		counts:    make(map[string]int),
+	}
+}
+
+// record updates the failure counts with the tool calls of one response and
+// their errors by tool ID. It returns the most repeated failure once it
+// reached the threshold, nil otherwise. A successful call resets its count.
+func (t *failingToolCallTracker) record(toolCalls []map[string]interface{}, errs map[string]string) *repeatedToolFailure {
+	if t == nil || t.threshold <= 0 {
+		return nil
+	}
+
+	var worst *repeatedToolFailure
+	seen := make(map[string]bool, len(toolCalls))
+	for _, call := range toolCalls {
+		toolName, args := toolCallSignature(call)
+		signature := toolName + "\x00" + args
+		if seen[signature] {
+			continue
+		}
+		seen[signature] = true
+
+		toolID, _ := call["id"].(string)
+		errMsg, failed := errs[toolID]
+		if !failed {
+			delete(t.counts, signature)
+			continue
+		}
+
+		t.counts[signature]++
+		count := t.counts[signature]
+		if count >= t.threshold && (worst == nil || count > worst.count) {
+			worst = &repeatedToolFailure{toolName: toolName, args: args, err: errMsg, count: count}
+		}
+	}
+	return worst
+}
+
+// toolCallSignature returns the tool name and the arguments of a call in a
+// canonical form, so calls only differing in key order or whitespace match
+func toolCallSignature(call map[string]interface{}) (toolName, args string) {
+	fn, _ := call["function"].(map[string]interface{})
+	toolName, _ = fn["name"].(string)
+
+	raw, _ := fn["arguments"].(string)
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return toolName, raw
+	}
+	canonical, err := json.Marshal(parsed)
+	if err != nil {
+		return toolName, raw
+	}
+	return toolName, string(canonical)
+}
+
+// pattern describes the failure for loop detection results
+func (f *repeatedToolFailure) pattern() string {
+	return fmt.Sprintf("%s(%s) failing with: %s", f.toolName,
+		truncateForPrompt(f.args, repeatedFailureDetailLimit), truncateForPrompt(f.err, repeatedFailureDetailLimit))
+}
+
+// notice tells the model to stop repeating the failing call
+func (f *repeatedToolFailure) notice() string {
+	return fmt.Sprintf("The tool call %s(%s) failed %d times in a row with: %s\nThis call keeps failing. Do not repeat it with the same parameters; try a different approach.",
+		f.toolName, truncateForPrompt(f.args, repeatedFailureDetailLimit), f.count, truncateForPrompt(f.err, repeatedFailureDetailLimit))
+}
@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+func readMissingFileCall(id, args string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       id,
+		"type":     "function",
+		"function": map[string]interface{}{"name": "read_file", "arguments": args},
+	}
+}
+
+func TestFailingToolCallTrackerCountsIdenticalFailures(t *testing.T) {
+	tracker := newFailingToolCallTracker(2)
+	failed := map[string]string{"c1": "file not found"}
+
+	if got := tracker.record([]map[string]interface{}{readMissingFileCall("c1", `{"path":"a.go","sections":[]}`)}, failed); got != nil {
+		t.Fatalf("expected no repeated failure after one failure, got %+v", got)
+	}
+	// Key order and whitespace don't make a call different
+	got := tracker.record([]map[string]interface{}{readMissingFileCall("c1", `{ "sections": [], "path": "a.go" }`)}, failed)
+	if got == nil || got.count != 2 || got.err != "file not found" || got.toolName != "read_file" {
+		t.Fatalf("expected the second identical failure to be reported, got %+v", got)
+	}
+
+	// A success resets the count
+	tracker.record([]map[string]interface{}{readMissingFileCall("c1", `{"path":"a.go","sections":[]}`)}, nil)
+	if got := tracker.record([]map[string]interface{}{readMissingFileCall("c1", `{"path":"a.go","sections":[]}`)}, failed); got != nil {
+		t.Errorf("expected the count to restart after a success, got %+v", got)
+	}
+	// Other parameters are another call
+	if got := tracker.record([]map[string]interface{}{readMissingFileCall("c1", `{"path":"b.go"}`)}, failed); got != nil {
+		t.Errorf("expected a call with other parameters to be counted separately, got %+v", got)
+	}
+
+	disabled := newFailingToolCallTracker(0)
+	for range 5 {
+		if got := disabled.record([]map[string]interface{}{readMissingFileCall("c1", `{}`)}, failed); got != nil {
+			t.Fatalf("expected a disabled tracker to report nothing, got %+v", got)
+		}
+	}
+}
+
+func TestOrchestrationLoop_BreaksRepeatedFailingToolCall(t *testing.T) {
+	orch := createTestOrchestrator(t)
+	defer func() {
+		_ = orch.Close()
+	}()
+	orch.featureFlags.SetPlanningEnabled(false)
+	orch.config.Loop.MaxRepeatedToolFailures = 2
+
+	// The model insists on reading a file that does not exist
+	responses := make([]*llm.CompletionResponse, 10)
+	for idx := range responses {
+		responses[idx] = &llm.CompletionResponse{
+			ToolCalls:  []map[string]interface{}{readMissingFileCall("call_1", `{"path":"missing.txt"}`)},
+			StopReason: "tool_use",
+		}
+	}
+	mockClient := newSequentialMockClient(responses...)
+	orch.orchestrationClient = mockClient
+
+	var failures int
+	toolResultCb := func(toolName, toolID, result, errorMsg string) error {
+		if errorMsg != "" {
+			failures++
+		}
+		return nil
+	}
+
+	result, err := orch.ProcessPromptWithResult(context.Background(), "read missing.txt", nil, nil, nil, nil, toolResultCb, nil)
+	if err != nil {
+		t.Fatalf("ProcessPrompt failed: %v", err)
+	}
+	if result.Reason != StopReasonLoopDetected {
+		t.Errorf("expected the loop to stop as detected loop, got %s", result.Reason)
+	}
+
+	// Two failures trigger the notice, the third stops the loop
+	if failures != 3 || len(mockClient.requests) != 3 {
+		t.Errorf("expected the loop to stop after 3 failing calls, got %d failures in %d requests", failures, len(mockClient.requests))
+	}
+
+	notices := 0
+	for _, msg := range orch.session.GetMessages() {
+		if msg.Role == "system" && strings.Contains(msg.Content, "keeps failing") {
+			notices++
+			if !strings.Contains(msg.Content, "missing.txt") || !strings.Contains(msg.Content, "try a different approach") {
+				t.Errorf("expected the notice to name the call and ask for another approach, got %q", msg.Content)
+			}
+		}
+	}
+	if notices != 1 {
+		t.Errorf("expected one corrective notice, got %d", notices)
+	}
+
+	// The model gets the notice as a system message, not as a user turn
+	if len(mockClient.requests) == 3 {
+		var noticeRoles []string
+		for _, msg := range mockClient.requests[2].Messages {
+			if strings.Contains(msg.Content, "keeps failing") {
+				noticeRoles = append(noticeRoles, msg.Role)
+			}
+		}
+		if len(noticeRoles) != 1 || noticeRoles[0] != "system" {
+			t.Errorf("expected the notice to be sent as one system message, got roles %v", noticeRoles)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// maxToolQuestionRounds bounds how often a single tool call may ask the user
+// follow-up questions before it is treated as failed
+const maxToolQuestionRounds = 3
+
+// executeToolAnsweringQuestions executes a tool call and, while the tool
+// returns questions, asks the user through the question callback and invokes
+// the tool again with all answers collected so far. Only the first invocation
+// gets the tool call and result callbacks: the UI already shows the events of
+// the call, the invocations answering questions continue it.
+func (o *Orchestrator) executeToolAnsweringQuestions(ctx context.Context, toolCall *tools.ToolCall, toolName string, progressFn progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
+	answers := make(map[string]string)
+	for round := 0; ; round++ {
+		result, err := o.executeToolOnce(tools.ContextWithQuestionAnswers(ctx, answers), toolCall, toolName, progressFn, toolCallCb, toolResultCb, approved)
+		if err != nil || result == nil || len(result.Questions) == 0 {
+			return result, err
+		}
+		toolCallCb, toolResultCb = nil, nil
+
+		if round >= maxToolQuestionRounds {
+			return &tools.ToolResult{
+				ID:    result.ID,
+				Error: fmt.Sprintf("%s still had questions after %d rounds of answers", toolName, maxToolQuestionRounds),
+			}, nil
+		}
+		if o.userInputCb == nil {
+			return &tools.ToolResult{
+				ID:    result.ID,
+				Error: fmt.Sprintf("%s needs input from the user, but no question callback is available", toolName),
+			}, nil
+		}
+
+		for _, question := range result.Questions {
+			logger.Debug("Tool %s asks question %q", toolName, question.ID)
+			answer, err := o.userInputCb(formatToolQuestion(toolName, question))
+			if err != nil {
+				return &tools.ToolResult{
+					ID:    result.ID,
+					Error: fmt.Sprintf("failed to get user input for %s: %v", toolName, err),
+				}, nil
+			}
+			answers[question.ID] = resolveToolQuestionAnswer(question, answer)
+		}
+	}
+}
+
+// formatToolQuestion renders a tool question like the planning agent's
+// questions, with lettered options
+func formatToolQuestion(toolName string, question tools.ToolQuestion) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s\n", toolName, question.Question)
+	for i, option := range question.Options {
+		fmt.Fprintf(&sb, "   %c. %s\n", 'a'+i, option)
+	}
+	return sb.String()
+}
+
+// resolveToolQuestionAnswer maps an answer that only names an option letter
+// (e.g. "b" or "b.") to the option's text
+func resolveToolQuestionAnswer(question tools.ToolQuestion, answer string) string {
+	answer = strings.TrimSpace(answer)
+	letter := strings.TrimSuffix(strings.ToLower(answer), ".")
+	if len(letter) == 1 && letter[0] >= 'a' && int(letter[0]-'a') < len(question.Options) {
+		return question.Options[letter[0]-'a']
+	}
+	return answer
+}
@@ -0,0 +1,131 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// pickFileTool asks which of several files was meant and opens the answer
+type pickFileTool struct {
+	calls int
+}
+
+func (t *pickFileTool) Name() string        { return "pick_file" }
+func (t *pickFileTool) Description() string { return "Opens a file matching a name" }
+func (t *pickFileTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+
+func (t *pickFileTool) Execute(ctx context.Context, params map[string]interface{}) *tools.ToolResult {
+	t.calls++
+	if file, ok := tools.QuestionAnswersFromContext(ctx)["file"]; ok {
+		return &tools.ToolResult{Result: "opened " + file}
+	}
+	return &tools.ToolResult{Questions: []tools.ToolQuestion{{
+		ID:       "file",
+		Question: "Which of these files did you mean?",
+		Options:  []string{"cmd/main.go", "internal/main.go", "tools/main.go"},
+	}}}
+}
+
+func TestExecuteToolAnswersToolQuestions(t *testing.T) {
+	registry := tools.NewRegistry(nil)
+	tool := &pickFileTool{}
+	registry.Register(tool)
+
+	var asked []string
+	orch := &Orchestrator{
+		toolRegistry: registry,
+		userInputCb: func(question string) (string, error) {
+			asked = append(asked, question)
+			return "b", nil
+		},
+	}
+
+	result, err := orch.ExecuteTool(context.Background(), &tools.ToolCall{ID: "call-1", Name: "pick_file", Parameters: map[string]interface{}{}}, "pick_file", nil, nil, nil, true)
+	if err != nil {
+		t.Fatalf("ExecuteTool failed: %v", err)
+	}
+	if result.Error != "" || result.Result != "opened internal/main.go" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if tool.calls != 2 {
+		t.Errorf("expected the tool to be invoked twice, got %d", tool.calls)
+	}
+	if len(asked) != 1 || !strings.Contains(asked[0], "Which of these files did you mean?") || !strings.Contains(asked[0], "c. tools/main.go") {
+		t.Errorf("unexpected questions %q", asked)
+	}
+}
+
+// announcingPickFileTool reports its lookup as a tool call, like tools
+// running other tools do
+type announcingPickFileTool struct {
+	pickFileTool
+}
+
+func (t *announcingPickFileTool) ExecuteWithCallbacks(ctx context.Context, params map[string]interface{}, progressCb progress.Callback, toolCallCb func(string, string, map[string]interface{}) error, toolResultCb func(string, string, string, string) error) *tools.ToolResult {
+	if toolCallCb != nil {
+		_ = toolCallCb("search_files", "lookup", map[string]interface{}{"pattern": "main.go"})
+	}
+	return t.Execute(ctx, params)
+}
+
+func TestExecuteToolQuestionsEmitToolEventsOnce(t *testing.T) {
+	registry := tools.NewRegistry(nil)
+	tool := &announcingPickFileTool{}
+	registry.Register(tool)
+
+	orch := &Orchestrator{
+		toolRegistry: registry,
+		userInputCb:  func(question string) (string, error) { return "a", nil },
+	}
+
+	var events []string
+	toolCallCb := func(toolName, toolID string, parameters map[string]interface{}) error {
+		events = append(events, toolName)
+		return nil
+	}
+	result, err := orch.ExecuteTool(context.Background(), &tools.ToolCall{ID: "call-1", Name: "pick_file", Parameters: map[string]interface{}{}}, "pick_file", nil, toolCallCb, nil, true)
+	if err != nil || result.Result != "opened cmd/main.go" {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+	if tool.calls != 2 {
+		t.Errorf("expected the tool to be invoked twice, got %d", tool.calls)
+	}
+	if len(events) != 1 {
+		t.Errorf("expected the tool call event once, got %v", events)
+	}
+}
+
+func TestExecuteToolQuestionsWithoutCallback(t *testing.T) {
+	registry := tools.NewRegistry(nil)
+	registry.Register(&pickFileTool{})
+	orch := &Orchestrator{toolRegistry: registry}
+
+	result, err := orch.ExecuteTool(context.Background(), &tools.ToolCall{ID: "call-1", Name: "pick_file", Parameters: map[string]interface{}{}}, "pick_file", nil, nil, nil, true)
+	if err != nil {
+		t.Fatalf("ExecuteTool failed: %v", err)
+	}
+	if !strings.Contains(result.Error, "no question callback") {
+		t.Errorf("expected an error without a question callback, got %+v", result)
+	}
+}
+
+func TestResolveToolQuestionAnswer(t *testing.T) {
+	question := tools.ToolQuestion{Options: []string{"one", "two"}}
+	tests := map[string]string{
+		"a":             "one",
+		" B. ":          "two",
+		"c":             "c",
+		"the first one": "the first one",
+	}
+	for answer, want := range tests {
+		if got := resolveToolQuestionAnswer(question, answer); got != want {
+			t.Errorf("resolveToolQuestionAnswer(%q) = %q, want %q", answer, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// toolResultLimit returns the maximum number of result bytes of toolName fed
+// back to the model; 0 means unlimited
+func (o *Orchestrator) toolResultLimit(toolName string) int {
+	if o.config == nil {
+		return 0
+	}
+	return o.config.ToolResultLimits.GetLimit(toolName)
+}
+
+// truncateToolResult cuts result to at most limit bytes (on a UTF-8 boundary)
+// and appends a marker telling the model how much was left out
+func truncateToolResult(result string, limit int) string {
+	if limit <= 0 || len(result) <= limit {
+		return result
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(result[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n... (%d bytes truncated, use read_file with a range to see more)", result[:cut], len(result)-cut)
+}
@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+func TestProcessToolCallsTruncatesLargeResults(t *testing.T) {
+	large := strings.Repeat("x", 40*1024)
+	outputs := map[string]string{
+		"call-1": large,
+		"call-2": "small output",
+		"call-3": large,
+	}
+	execFn := func(ctx context.Context, call *tools.ToolCall, toolName string, progressCb progress.Callback, toolCallCb ToolCallCallback, toolResultCb ToolResultCallback, approved bool) (*tools.ToolResult, error) {
+		return &tools.ToolResult{ID: call.ID, Result: outputs[call.ID]}, nil
+	}
+
+	var uiResults []string
+	toolResultCb := func(toolName, toolID, result, errorMsg string) error {
+		uiResults = append(uiResults, result)
+		return nil
+	}
+
+	calls := []map[string]interface{}{
+		{"id": "call-1", "type": "function", "function": map[string]interface{}{"name": "shell", "arguments": `{"command":"cat big.log"}`}},
+		{"id": "call-2", "type": "function", "function": map[string]interface{}{"name": "shell", "arguments": `{"command":"echo hi"}`}},
+		{"id": "call-3", "type": "function", "function": map[string]interface{}{"name": "todo", "arguments": `{"action":"list"}`}},
+	}
+
+	sess := session.NewSession("test", ".")
+	orch := &Orchestrator{config: &config.Config{}}
+	if err := orch.processToolCalls(context.Background(), calls, sess, nil, nil, nil, toolResultCb, execFn); err != nil {
+		t.Fatalf("processToolCalls returned error: %v", err)
+	}
+
+	messages := sess.GetMessages()
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 tool messages, got %d", len(messages))
+	}
+
+	// shell results are capped at 32 KiB by default
+	shell := messages[0].Content
+	if !strings.HasPrefix(shell, strings.Repeat("x", 32*1024)+"\n") || !strings.HasSuffix(shell, "... (8192 bytes truncated, use read_file with a range to see more)") {
+		t.Errorf("expected truncated shell result, got %d bytes ending in %q", len(shell), shell[len(shell)-80:])
+	}
+	if messages[1].Content != "small output" {
+		t.Errorf("expected small result to pass through, got %q", messages[1].Content)
+	}
+	// Other tools fall back to the 64 KiB default
+	if messages[2].Content != large {
+		t.Errorf("expected result under the default cap to pass through, got %d bytes", len(messages[2].Content))
+	}
+
+	if len(uiResults) == 0 || uiResults[0] != large {
+		t.Errorf("expected the UI to receive the full result")
+	}
+}
+
+func TestToolResultLimitConfig(t *testing.T) {
+	orch := &Orchestrator{config: &config.Config{ToolResultLimits: config.ToolResultLimitsConfig{
+		DefaultBytes: 1000,
+		Tools:        map[string]int{"shell": -1, "read_file": 10},
+	}}}
+
+	if got := orch.toolResultLimit("shell"); got != 0 {
+		t.Errorf("expected -1 to disable the shell cap, got %d", got)
+	}
+	if got := orch.toolResultLimit("read_file"); got != 10 {
+		t.Errorf("expected configured read_file cap, got %d", got)
+	}
+	if got := orch.toolResultLimit("search_files"); got != 32*1024 {
+		t.Errorf("expected built-in search cap, got %d", got)
+	}
+	if got := orch.toolResultLimit("todo"); got != 1000 {
+		t.Errorf("expected configured default cap, got %d", got)
+	}
+
+	if got := truncateToolResult("héllo wörld", 2); got != "h\n... (12 bytes truncated, use read_file with a range to see more)" {
+		t.Errorf("expected cut on a rune boundary, got %q", got)
+	}
+}
@@ -107,9 +107,10 @@ Respond with ONLY one word: "QUESTION" or "IMPLEMENTATION"`
 func (a *VerificationAgent) buildToolRegistry(verificationSession *session.Session) *tools.Registry {
 	// Use the orchestrator's authorizer (which respects session-level authorizations)
 	registry := tools.NewRegistryWithSecrets(a.orch.authorizer, secretdetect.NewDetector())
+	registry.SetDryRun(a.orch.dryRun())
 
 	// Register tools
-	modelFamily := llm.DetectModelFamily(a.orch.providerMgr.GetOrchestrationModel())
+	modelFamily := llm.DetectModelFamily(a.orch.orchestrationModelID())
 
 	// Read File - essential for checking modified files
 	registry.Register(a.orch.getReadFileTool(modelFamily, verificationSession))
@@ -154,13 +155,16 @@ func (a *VerificationAgent) buildToolRegistry(verificationSession *session.Sessi
 	registry.Register(tools.NewSearchFileContentTool(a.orch.fs))
 
 	// Go Sandbox tool - for running build/lint/test commands and code execution
-	sandboxSpec, _ := tools.WrapLegacyTool(tools.NewSandboxToolWithFS(a.orch.workingDir, a.orch.config.TempDir, a.orch.fs, verificationSession, a.orch.shellActorClient))
-	sandboxFactory := func(_ *tools.Registry) tools.ToolExecutor {
-		instance := tools.NewSandboxToolWithFS(a.orch.workingDir, a.orch.config.TempDir, a.orch.fs, verificationSession, a.orch.shellActorClient)
-		a.orch.configureSandboxTool(instance)
-		return instance
+	sandboxTool := tools.NewSandboxToolWithFS(a.orch.workingDir, a.orch.config.TempDir, a.orch.fs, verificationSession, a.orch.shellActorClient)
+	if err := sandboxTool.Available(); err == nil || !a.orch.config.Sandbox.SkipUnavailableGoSandbox {
+		sandboxSpec, _ := tools.WrapLegacyTool(sandboxTool)
+		sandboxFactory := func(_ *tools.Registry) tools.ToolExecutor {
+			instance := tools.NewSandboxToolWithFS(a.orch.workingDir, a.orch.config.TempDir, a.orch.fs, verificationSession, a.orch.shellActorClient)
+			a.orch.configureSandboxTool(instance)
+			return instance
+		}
+		registry.RegisterSpec(sandboxSpec, sandboxFactory)
 	}
-	registry.RegisterSpec(sandboxSpec, sandboxFactory)
 
 	// Parallel execution tool
 	registry.Register(tools.NewParallelTool(registry))
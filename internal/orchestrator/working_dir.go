@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/mcp"
+	"github.com/codefionn/scriptschnell/internal/sandbox"
+	"github.com/codefionn/scriptschnell/internal/tools"
+	"github.com/codefionn/scriptschnell/internal/vcs"
+)
+
+// SetWorkingDir moves the orchestrator and its session to another directory,
+// e.g. a different subproject. The filesystem is re-created for the new root,
+// tools bound to the old directory are re-registered and the sandbox and VCS
+// state are resolved again. The authorization actor and the MCP manager are
+// replaced as well, so no component keeps using the old directory or the
+// closed filesystem. It fails while a prompt is being processed.
+func (o *Orchestrator) SetWorkingDir(ctx context.Context, dir string) error {
+	if !o.workspaceMu.TryLock() {
+		return fmt.Errorf("cannot change the working directory while a prompt is being processed")
+	}
+	defer o.workspaceMu.Unlock()
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return fmt.Errorf("invalid working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("working directory %s is not a directory", absDir)
+	}
+	if absDir == o.workingDir {
+		return nil
+	}
+
+	var cacheTTL time.Duration
+	maxCacheEntries := 0
+	if o.config != nil {
+		cacheTTL = time.Duration(o.config.CacheTTL) * time.Second
+		maxCacheEntries = o.config.MaxCacheEntries
+	}
+	oldFS := o.fs
+	o.fs = fs.NewCachedFS(absDir, cacheTTL, maxCacheEntries)
+	if cachedFS, ok := oldFS.(*fs.CachedFS); ok {
+		if err := cachedFS.Close(); err != nil {
+			logger.Warn("Failed to close filesystem of %s: %v", o.workingDir, err)
+		}
+	}
+
+	logger.Info("Changing working directory from %s to %s", o.workingDir, absDir)
+	o.workingDir = absDir
+	if o.session != nil {
+		o.session.SetWorkingDir(absDir)
+	}
+
+	if o.sandbox != nil {
+		o.sandbox = sandbox.NewLandlockSandbox(absDir, o.landlockSandboxConfig())
+	}
+	if o.sandboxManager != nil {
+		o.sandboxManager.SetWorkspaceDir(absDir)
+	}
+	o.resolveVCS(ctx)
+	if o.mcpManager != nil {
+		o.mcpManager.Close()
+		o.mcpManager = mcp.NewManager(o.config, absDir, o.providerMgr)
+	}
+
+	// The authorization actor resolves paths against the directory and
+	// filesystem it was created with, so it is restarted with options
+	// recomputed for the moved session
+	if o.authorizer != nil {
+		if err := o.actorSystem.Stop(ctx, "authorization"); err != nil {
+			logger.Warn("Failed to stop authorization actor: %v", err)
+		}
+		if o.actorCancel != nil {
+			o.actorCancel()
+		}
+		requireSandboxAuth := false
+		var policy *tools.AuthorizationPolicy
+		if o.authOptions != nil {
+			requireSandboxAuth = o.authOptions.RequireSandboxAuth
+			policy = o.authOptions.Policy
+		}
+		if err := o.startAuthorizationActor(authorizationOptions(o.config, o.session, requireSandboxAuth, policy)); err != nil {
+			return err
+		}
+	}
+
+	if o.toolRegistry != nil {
+		for _, err := range o.rebuildTools(false) {
+			if err != nil {
+				logger.Warn("Tool registration warning: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// landlockSandboxConfig returns the configuration of the shell sandbox. The
+// Linux sandbox can also be disabled via feature flags.
+func (o *Orchestrator) landlockSandboxConfig() *sandbox.SandboxConfig {
+	if o.config == nil {
+		return nil
+	}
+	return &sandbox.SandboxConfig{
+		AdditionalReadOnlyPaths:  o.config.Sandbox.AdditionalReadOnlyPaths,
+		AdditionalReadWritePaths: o.config.Sandbox.AdditionalReadWritePaths,
+		DisableSandbox:           o.config.Sandbox.DisableSandbox || (o.featureFlags != nil && !o.featureFlags.IsToolEnabled("linux_sandbox")),
+		BestEffort:               o.config.Sandbox.BestEffort,
+	}
+}
+
+// resolveVCS detects whether the working directory is under version control
+// and points the VCS actor at it
+func (o *Orchestrator) resolveVCS(ctx context.Context) {
+	var repo vcs.VCS = vcs.NewGit(o.workingDir)
+	if _, err := repo.RepositoryRoot(ctx, o.workingDir); err != nil {
+		logger.Debug("Not in a git repository, VCS actor disabled")
+		repo = nil
+	}
+
+	branch := ""
+	if o.vcsClient != nil {
+		current, err := o.vcsClient.SetRepository(repo)
+		if err != nil {
+			logger.Warn("Failed to update VCS actor: %v", err)
+		}
+		branch = current
+	}
+	if o.session != nil {
+		o.session.SetHasVCS(repo != nil)
+		o.session.SetCurrentBranch(branch)
+	}
+}
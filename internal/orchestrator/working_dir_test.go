@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/provider"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+func readFileViaTool(t *testing.T, orch *Orchestrator, path string) string {
+	t.Helper()
+
+	result, err := orch.ExecuteTool(context.Background(), &tools.ToolCall{ID: "read", Name: tools.ToolNameReadFile, Parameters: map[string]interface{}{"path": path}}, tools.ToolNameReadFile, nil, nil, nil, true)
+	if err != nil {
+		t.Fatalf("ExecuteTool failed: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("read_file failed: %s", result.Error)
+	}
+	return fmt.Sprint(result.Result)
+}
+
+func TestSetWorkingDirResolvesFilesAgainstNewRoot(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(oldDir, "notes.txt"), []byte("old project\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(newDir, "notes.txt"), []byte("new project\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+	cfg := &config.Config{WorkingDir: oldDir}
+	orch, err := NewOrchestrator(cfg, providerMgr, false)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	t.Cleanup(func() { orch.Stop() })
+
+	if got := readFileViaTool(t, orch, "notes.txt"); !strings.Contains(got, "old project") {
+		t.Fatalf("expected the old project file, got %q", got)
+	}
+
+	// Authorizations granted since startup apply after the move
+	cfg.AuthorizeCommand("make test")
+	oldAuthorizer := orch.authorizer
+	if err := orch.SetWorkingDir(context.Background(), newDir); err != nil {
+		t.Fatalf("SetWorkingDir failed: %v", err)
+	}
+	if orch.authorizer == oldAuthorizer {
+		t.Error("expected the authorization actor to be rebuilt for the new root")
+	}
+	if !slices.Contains(orch.authOptions.AllowedCommands, "make test") {
+		t.Errorf("expected recomputed authorization options, got %+v", orch.authOptions)
+	}
+	if orch.GetWorkingDir() != newDir || orch.session.WorkingDir != newDir {
+		t.Errorf("expected working dir %s, got orchestrator %s and session %s", newDir, orch.GetWorkingDir(), orch.session.WorkingDir)
+	}
+	if got := readFileViaTool(t, orch, "notes.txt"); !strings.Contains(got, "new project") {
+		t.Errorf("expected the file of the new root, got %q", got)
+	}
+}
+
+func TestSetWorkingDirRejectedDuringGeneration(t *testing.T) {
+	dir := t.TempDir()
+	orch := &Orchestrator{workingDir: t.TempDir()}
+	// ProcessPrompt holds the workspace lock for reading
+	orch.workspaceMu.RLock()
+	defer orch.workspaceMu.RUnlock()
+
+	if err := orch.SetWorkingDir(context.Background(), dir); err == nil || !strings.Contains(err.Error(), "prompt is being processed") {
+		t.Errorf("expected the change to be rejected mid-generation, got %v", err)
+	}
+	if orch.GetWorkingDir() == dir {
+		t.Error("working dir must not change mid-generation")
+	}
+}
+
+func TestSetWorkingDirRejectsFiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	orch := &Orchestrator{workingDir: t.TempDir()}
+	if err := orch.SetWorkingDir(context.Background(), file); err == nil {
+		t.Error("expected an error for a file")
+	}
+}
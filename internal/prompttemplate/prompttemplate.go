@@ -0,0 +1,146 @@
+// Package prompttemplate renders the configurable prompts of the context
+// compaction and the error judge, and user-defined prompt snippets.
+// Templates use Go text/template syntax; an empty template selects the
+// built-in default.
+package prompttemplate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// CompactionMessage is a message of the conversation that is being compacted
+type CompactionMessage struct {
+	Role    string
+	Content string
+}
+
+// CompactionData is available to the compaction summary template
+type CompactionData struct {
+	// Instruction is the built-in instruction for the attempt
+	Instruction string
+	// Level is "standard", "forceful" or "extreme"; later attempts are more forceful
+	Level       string
+	Attempt     int
+	MaxAttempts int
+	// Messages are the messages that are summarized
+	Messages         []CompactionMessage
+	LatestUserPrompt string
+}
+
+// ErrorJudgeData is available to the error judge template
+type ErrorJudgeData struct {
+	Attempt     int
+	MaxAttempts int
+	Model       string
+	Error       string
+}
+
+// SnippetData is available to prompt snippet templates
+type SnippetData struct {
+	// Args are all arguments joined by spaces
+	Args string
+	// Arg are the individual arguments, e.g. {{index .Arg 0}}
+	Arg []string
+}
+
+// DefaultCompactionSummary uses the built-in instruction of the attempt
+const DefaultCompactionSummary = "{{.Instruction}}"
+
+// DefaultErrorJudge is the built-in error judge prompt. Custom templates must
+// ask for the same response format, since the decision is parsed from it.
+const DefaultErrorJudge = `You are an error recovery judge for an LLM-powered application.
+Analyze the error and decide whether to retry the request or halt.
+
+Your response must be in this exact format:
+DECISION: RETRY or HALT
+SLEEP_SECONDS: <number>
+TRIGGER_COMPACTION: YES or NO
+REASON: <brief explanation>
+
+Guidelines:
+- Rate limit errors: RETRY with exponential backoff (5s, 15s, 30s, 60s, 120, 240s, 360s)
+- Temporary service errors (500, 503, timeout): RETRY with moderate delays (2s, 5s, 10s, 20s, 30s, 240s, 360s)
+- Network errors: RETRY with short delays (1s, 3s, 5s)
+- Token/context limit errors (context_length_exceeded, max tokens, prompt too long, input too long): RETRY with TRIGGER_COMPACTION=YES
+- Authentication errors: HALT (invalid credentials)
+- Invalid request/parameter errors: HALT (bad input)
+- Unknown errors after 3+ attempts: HALT (prevent infinite loops)
+
+Current attempt: {{.Attempt}} of {{.MaxAttempts}}
+Model: {{.Model}}
+Error: {{.Error}}
+
+Analyze this error and provide your decision in the exact format above.`
+
+// RenderCompactionSummary renders the instruction given to the summarizer
+// when compacting the context
+func RenderCompactionSummary(text string, data CompactionData) (string, error) {
+	return render("compaction_summary", text, DefaultCompactionSummary, data)
+}
+
+// RenderErrorJudge renders the prompt of the error judge
+func RenderErrorJudge(text string, data ErrorJudgeData) (string, error) {
+	return render("error_judge", text, DefaultErrorJudge, data)
+}
+
+// RenderSnippet expands the prompt snippet name with the given arguments
+func RenderSnippet(name, text string, args []string) (string, error) {
+	return render("snippet "+name, text, "", SnippetData{
+		Args: strings.Join(args, " "),
+		Arg:  args,
+	})
+}
+
+// ValidateSnippet checks that a snippet parses and renders with sample
+// arguments, so that unknown fields are reported when it is saved
+func ValidateSnippet(name, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("snippet %s is empty", name)
+	}
+	sample := make([]string, 9)
+	for i := range sample {
+		sample[i] = fmt.Sprintf("arg%d", i+1)
+	}
+	_, err := RenderSnippet(name, text, sample)
+	return err
+}
+
+// Validate checks that the templates parse and render with sample data, so
+// that unknown fields are reported when the configuration is loaded
+func Validate(compactionSummary, errorJudge string) error {
+	if _, err := RenderCompactionSummary(compactionSummary, CompactionData{
+		Instruction: "Summarize the conversation.",
+		Level:       "standard",
+		Attempt:     1,
+		MaxAttempts: 3,
+		Messages:    []CompactionMessage{{Role: "user", Content: "Hello"}},
+	}); err != nil {
+		return err
+	}
+	if _, err := RenderErrorJudge(errorJudge, ErrorJudgeData{
+		Attempt:     1,
+		MaxAttempts: 3,
+		Model:       "model",
+		Error:       "error",
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func render(name, text, fallback string, data interface{}) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,95 @@
+package prompttemplate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCompactionSummaryCustomTemplate(t *testing.T) {
+	tmpl := `Fasse die folgenden {{len .Messages}} Nachrichten auf Deutsch zusammen (Versuch {{.Attempt}}/{{.MaxAttempts}}, {{.Level}}).
+{{range .Messages}}- {{.Role}}: {{.Content}}
+{{end}}Letzte Anfrage: {{.LatestUserPrompt}}`
+
+	got, err := RenderCompactionSummary(tmpl, CompactionData{
+		Instruction: "ignored",
+		Level:       "forceful",
+		Attempt:     2,
+		MaxAttempts: 3,
+		Messages: []CompactionMessage{
+			{Role: "user", Content: "Baue einen Parser"},
+			{Role: "assistant", Content: "Parser angelegt"},
+		},
+		LatestUserPrompt: "Teste den Parser",
+	})
+	if err != nil {
+		t.Fatalf("RenderCompactionSummary failed: %v", err)
+	}
+
+	want := `Fasse die folgenden 2 Nachrichten auf Deutsch zusammen (Versuch 2/3, forceful).
+- user: Baue einen Parser
+- assistant: Parser angelegt
+Letzte Anfrage: Teste den Parser`
+	if got != want {
+		t.Errorf("rendered template = %q, want %q", got, want)
+	}
+}
+
+func TestRenderDefaults(t *testing.T) {
+	got, err := RenderCompactionSummary("", CompactionData{Instruction: "Summarize."})
+	if err != nil || got != "Summarize." {
+		t.Errorf("expected the built-in instruction, got %q (%v)", got, err)
+	}
+
+	got, err = RenderErrorJudge("  ", ErrorJudgeData{Attempt: 2, MaxAttempts: 5, Model: "gpt-4", Error: "rate limited"})
+	if err != nil {
+		t.Fatalf("RenderErrorJudge failed: %v", err)
+	}
+	for _, want := range []string{"DECISION: RETRY or HALT", "Current attempt: 2 of 5", "Model: gpt-4", "Error: rate limited"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected default error judge prompt to contain %q", want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("", ""); err != nil {
+		t.Errorf("defaults must be valid: %v", err)
+	}
+	if err := Validate("{{.Instruction}} in French", "Error: {{.Error}}"); err != nil {
+		t.Errorf("expected valid templates, got %v", err)
+	}
+
+	tests := map[string][2]string{
+		"syntax error":  {"{{.Instruction", ""},
+		"unknown field": {"{{.Summary}}", ""},
+		"error judge":   {"", "{{.Missing}}"},
+	}
+	for name, tt := range tests {
+		if err := Validate(tt[0], tt[1]); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestRenderSnippet(t *testing.T) {
+	got, err := RenderSnippet("review", "Review {{.Args}} for security issues, starting with {{index .Arg 0}}.", []string{"auth.go", "login.go"})
+	if err != nil {
+		t.Fatalf("RenderSnippet failed: %v", err)
+	}
+	if want := "Review auth.go login.go for security issues, starting with auth.go."; got != want {
+		t.Errorf("rendered snippet = %q, want %q", got, want)
+	}
+
+	if _, err := RenderSnippet("review", "Start with {{index .Arg 0}}", nil); err == nil {
+		t.Error("expected an error for a missing argument")
+	}
+
+	if err := ValidateSnippet("review", "Review {{.Args}}"); err != nil {
+		t.Errorf("expected a valid snippet, got %v", err)
+	}
+	for _, text := range []string{"", "Review {{.Files}}", "Review {{.Args"} {
+		if err := ValidateSnippet("bad", text); err == nil {
+			t.Errorf("expected snippet %q to be invalid", text)
+		}
+	}
+}
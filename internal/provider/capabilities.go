@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+// Capabilities describes what a model supports according to the metadata
+// reported by its provider's model listing.
+type Capabilities struct {
+	// Known is false when the listing does not say whether the model supports
+	// tool calling; the other flags should then be treated as "unknown".
+	Known             bool `json:"known"`
+	SupportsTools     bool `json:"supports_tools"`
+	SupportsVision    bool `json:"supports_vision"`
+	SupportsStreaming bool `json:"supports_streaming"`
+	SupportsJSONMode  bool `json:"supports_json_mode"`
+	MaxOutputTokens   int  `json:"max_output_tokens,omitempty"`
+}
+
+// GetModelCapabilities returns the capabilities of a model. Models without
+// listing metadata (e.g. added manually or cached by an older version) get
+// conservative defaults with Known set to false.
+func (m *Manager) GetModelCapabilities(modelID string) (Capabilities, error) {
+	model, ok := m.GetModel(modelID)
+	if !ok {
+		return Capabilities{}, fmt.Errorf("model not found: %s", modelID)
+	}
+	return model.capabilities(), nil
+}
+
+func (model *Model) capabilities() Capabilities {
+	if model.Capabilities == nil {
+		return Capabilities{MaxOutputTokens: model.MaxOutputTokens}
+	}
+	caps := *model.Capabilities
+	if caps.MaxOutputTokens == 0 {
+		caps.MaxOutputTokens = model.MaxOutputTokens
+	}
+	return caps
+}
+
+// modelFromInfo converts a provider model listing entry to a Model
+func modelFromInfo(info *llm.ModelInfo, providerName string) *Model {
+	return &Model{
+		ID:              info.ID,
+		Name:            info.Name,
+		Provider:        providerName,
+		Description:     info.Description,
+		ContextWindow:   info.ContextWindow,
+		MaxOutputTokens: info.MaxOutputTokens,
+		Capabilities:    capabilitiesFromInfo(info),
+	}
+}
+
+// capabilitiesFromInfo derives capabilities from the loosely structured
+// listing metadata; providers use different names for the same feature.
+// Known is only set when the listing carries tool metadata: either tool
+// support is reported or the provider returned an explicit feature list.
+// Listings such as Gemini's only name generation methods, so a missing tool
+// entry there says nothing about tool support.
+func capabilitiesFromInfo(info *llm.ModelInfo) *Capabilities {
+	features := make([]string, 0, len(info.Capabilities)+len(info.SupportedFeatures)+len(info.SupportedSamplingParams))
+	features = append(features, info.Capabilities...)
+	features = append(features, info.SupportedFeatures...)
+	features = append(features, info.SupportedSamplingParams...)
+
+	supportsTools := info.SupportsToolCalling || hasAnyFeature(features, "tools", "tool-use", "tool_use", "function_calling")
+
+	return &Capabilities{
+		Known:             supportsTools || len(info.SupportedFeatures) > 0,
+		SupportsTools:     supportsTools,
+		SupportsVision:    hasAnyFeature(info.InputModalities, "image") || hasAnyFeature(features, "vision"),
+		SupportsStreaming: info.SupportsStreaming,
+		SupportsJSONMode:  hasAnyFeature(features, "json_mode", "json-mode", "response_format", "structured_outputs"),
+		MaxOutputTokens:   info.MaxOutputTokens,
+	}
+}
+
+func hasAnyFeature(features []string, names ...string) bool {
+	for _, feature := range features {
+		feature = strings.ToLower(strings.TrimSpace(feature))
+		for _, name := range names {
+			if feature == name {
+				return true
+			}
+		}
+	}
+	return false
+}
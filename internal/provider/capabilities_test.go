@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+func newCapabilitiesTestManager(models ...*Model) *Manager {
+	return &Manager{
+		config: &Config{
+			Providers: map[string]*Provider{
+				"stub": {Name: "stub", Models: models},
+			},
+		},
+	}
+}
+
+func TestGetModelCapabilitiesFromListingMetadata(t *testing.T) {
+	vision := modelFromInfo(&llm.ModelInfo{
+		ID:                      "vision-model",
+		MaxOutputTokens:         8192,
+		SupportsToolCalling:     true,
+		SupportsStreaming:       true,
+		InputModalities:         []string{"text", "image"},
+		SupportedSamplingParams: []string{"temperature", "response_format"},
+	}, "stub")
+	textOnly := modelFromInfo(&llm.ModelInfo{
+		ID:                "text-model",
+		SupportsStreaming: true,
+		SupportedFeatures: []string{"streaming"},
+	}, "stub")
+
+	m := newCapabilitiesTestManager(vision, textOnly)
+
+	caps, err := m.GetModelCapabilities("vision-model")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Capabilities{
+		Known:             true,
+		SupportsTools:     true,
+		SupportsVision:    true,
+		SupportsStreaming: true,
+		SupportsJSONMode:  true,
+		MaxOutputTokens:   8192,
+	}
+	if caps != want {
+		t.Fatalf("vision-model capabilities = %+v, want %+v", caps, want)
+	}
+
+	caps, err = m.GetModelCapabilities("text-model")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !caps.Known || caps.SupportsTools || caps.SupportsVision || caps.SupportsJSONMode || !caps.SupportsStreaming {
+		t.Fatalf("unexpected text-model capabilities: %+v", caps)
+	}
+}
+
+func TestGetModelCapabilitiesFeatureAliases(t *testing.T) {
+	caps := capabilitiesFromInfo(&llm.ModelInfo{
+		Capabilities:      []string{"Vision", "tool-use"},
+		SupportedFeatures: []string{"json_mode"},
+	})
+	if !caps.SupportsTools || !caps.SupportsVision || !caps.SupportsJSONMode {
+		t.Fatalf("expected aliases to be recognized, got %+v", caps)
+	}
+}
+
+func TestGetModelCapabilitiesConservativeWhenUnknown(t *testing.T) {
+	m := newCapabilitiesTestManager(&Model{ID: "manual-model", Provider: "stub", MaxOutputTokens: 4096})
+
+	caps, err := m.GetModelCapabilities("manual-model")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Capabilities{MaxOutputTokens: 4096}
+	if caps != want {
+		t.Fatalf("expected conservative defaults %+v, got %+v", want, caps)
+	}
+
+	if _, err := m.GetModelCapabilities("missing-model"); err == nil {
+		t.Fatalf("expected error for unknown model")
+	}
+}
+
+func TestGetModelCapabilitiesUnknownWithoutToolMetadata(t *testing.T) {
+	// Gemini lists generation methods only; that must not be read as "no tools"
+	caps := capabilitiesFromInfo(&llm.ModelInfo{
+		ID:                "gemini-model",
+		SupportsStreaming: true,
+		Capabilities:      []string{"generateContent", "streamGenerateContent"},
+	})
+	if caps.Known {
+		t.Fatalf("expected capabilities without tool metadata to be unknown, got %+v", caps)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/secretdetect"
 	"github.com/codefionn/scriptschnell/internal/secrets"
 	"github.com/codefionn/scriptschnell/internal/securemem"
 	"github.com/codefionn/scriptschnell/internal/stringsearch"
@@ -37,6 +38,8 @@ type RateLimitConfig struct {
 	MinIntervalMillis int `json:"min_interval_ms,omitempty"`
 	// TokensPerMinute limits how many prompt tokens (including tool output) are sent per minute.
 	TokensPerMinute int `json:"tokens_per_minute,omitempty"`
+	// Burst allows this many requests at once before the interval applies (default 1).
+	Burst int `json:"burst,omitempty"`
 }
 
 func (p *Provider) rateLimitInterval() time.Duration {
@@ -64,6 +67,13 @@ func (p *Provider) tokensPerMinute() int {
 	return p.RateLimit.TokensPerMinute
 }
 
+func (p *Provider) rateLimitBurst() int {
+	if p == nil || p.RateLimit == nil || p.RateLimit.Burst < 1 {
+		return 1
+	}
+	return p.RateLimit.Burst
+}
+
 // Model represents an LLM model
 type Model struct {
 	ID              string `json:"id"`
@@ -73,6 +83,8 @@ type Model struct {
 	ContextWindow   int    `json:"context_window,omitempty"`    // Input context window size
 	MaxOutputTokens int    `json:"max_output_tokens,omitempty"` // Maximum output tokens
 	ReasoningEffort string `json:"reasoning_effort,omitempty"`  // Reasoning effort: "xhigh", "high", "medium", "low", "minimal", "none"
+
+	Capabilities *Capabilities `json:"capabilities,omitempty"` // From the provider's model listing, nil if unknown
 }
 
 // Config stores provider configuration
@@ -98,6 +110,19 @@ type Manager struct {
 	password       string            // For backward compatibility, kept as plaintext
 	securePassword *securemem.String // Secure password storage
 	refreshWg      sync.WaitGroup    // Tracks ongoing model refresh operations
+
+	limitersMu sync.Mutex
+	limiters   map[string]*providerLimiter // Shared rate limiters keyed by provider name
+
+	redactor *secretdetect.Redactor // Masks the configured API keys
+}
+
+// providerLimiter remembers the settings a shared limiter was built from
+type providerLimiter struct {
+	limiter         *llm.RateLimiter
+	interval        time.Duration
+	burst           int
+	tokensPerMinute int
 }
 
 // NewManager creates a new provider manager
@@ -120,6 +145,7 @@ func NewManager(configPath, password string) (*Manager, error) {
 			Providers: make(map[string]*Provider),
 		},
 		password: password,
+		redactor: secretdetect.NewRedactor(""),
 	}
 
 	// Load config if exists
@@ -160,6 +186,7 @@ func NewManagerSecure(configPath string, password *securemem.String) (*Manager,
 		},
 		password:       passwordStr,
 		securePassword: password,
+		redactor:       secretdetect.NewRedactor(""),
 	}
 
 	// Load config if exists
@@ -173,6 +200,20 @@ func NewManagerSecure(configPath string, password *securemem.String) (*Manager,
 	return m, nil
 }
 
+// Redactor returns the redactor masking the API keys of this manager's
+// providers, e.g. for log lines
+func (m *Manager) Redactor() *secretdetect.Redactor {
+	return m.redactor
+}
+
+// registerAPIKeyForRedaction makes sure a configured provider key is masked
+// verbatim in logs and (if enabled) tool results, whatever its format
+func (m *Manager) registerAPIKeyForRedaction(apiKey string) {
+	if m.redactor != nil {
+		m.redactor.AddKnownSecret(apiKey)
+	}
+}
+
 // Load loads configuration from disk
 func (m *Manager) Load() error {
 	data, err := os.ReadFile(m.configPath)
@@ -194,6 +235,7 @@ func (m *Manager) Load() error {
 		if provider == nil {
 			continue
 		}
+		m.registerAPIKeyForRedaction(provider.APIKey)
 
 		if len(provider.Models) > 0 {
 			// Persist embedded models to cache, but don't keep in memory during startup
@@ -350,6 +392,7 @@ func (m *Manager) AddProviderWithBaseURL(name, apiKey, baseURL string, models []
 		BaseURL: baseURL,
 		Models:  models,
 	}
+	m.registerAPIKeyForRedaction(apiKey)
 
 	if err := m.saveProviderModels(name, models); err != nil {
 		return fmt.Errorf("failed to cache models for provider %s: %w", name, err)
@@ -369,6 +412,7 @@ func (m *Manager) AddProviderWithAPIListingAndBaseURL(ctx context.Context, name,
 	if canonicalProviderName(name) == "z.ai" && strings.TrimSpace(baseURL) == "" {
 		baseURL = zAIGeneralBaseURL
 	}
+	m.registerAPIKeyForRedaction(apiKey)
 
 	// Create provider instance
 	llmProvider, err := m.createLLMProviderWithBaseURL(name, apiKey, baseURL)
@@ -386,14 +430,7 @@ func (m *Manager) AddProviderWithAPIListingAndBaseURL(ctx context.Context, name,
 	canonicalName := canonicalProviderName(name)
 	models := make([]*Model, len(modelInfos))
 	for i, info := range modelInfos {
-		models[i] = &Model{
-			ID:              info.ID,
-			Name:            info.Name,
-			Provider:        canonicalName,
-			Description:     info.Description,
-			ContextWindow:   info.ContextWindow,
-			MaxOutputTokens: info.MaxOutputTokens,
-		}
+		models[i] = modelFromInfo(info, canonicalName)
 	}
 
 	// Add provider with fetched models
@@ -426,14 +463,7 @@ func (m *Manager) RefreshModels(ctx context.Context, providerName string) error
 	canonicalName := canonicalProviderName(providerName)
 	models := make([]*Model, len(modelInfos))
 	for i, info := range modelInfos {
-		models[i] = &Model{
-			ID:              info.ID,
-			Name:            info.Name,
-			Provider:        canonicalName,
-			Description:     info.Description,
-			ContextWindow:   info.ContextWindow,
-			MaxOutputTokens: info.MaxOutputTokens,
-		}
+		models[i] = modelFromInfo(info, canonicalName)
 	}
 
 	// Update provider
@@ -619,6 +649,9 @@ func (m *Manager) UpdateProviderRateLimit(providerName string, cfg *RateLimitCon
 			if copyCfg.TokensPerMinute < 0 {
 				copyCfg.TokensPerMinute = 0
 			}
+			if copyCfg.Burst < 0 {
+				copyCfg.Burst = 0
+			}
 			normalized = &copyCfg
 		}
 	}
@@ -647,6 +680,7 @@ func (m *Manager) UpdateProviderConnection(providerName, apiKey, baseURL string)
 	updated := false
 	if apiKey != "" && apiKey != p.APIKey {
 		p.APIKey = apiKey
+		m.registerAPIKeyForRedaction(apiKey)
 		updated = true
 	}
 	if baseURL != "" && baseURL != p.BaseURL {
@@ -792,6 +826,57 @@ func (m *Manager) GetModel(modelID string) (*Model, bool) {
 	return nil, false
 }
 
+// UnknownModelError is returned when a requested model or provider is not
+// configured
+type UnknownModelError struct {
+	Model    string
+	Provider string
+}
+
+func (e *UnknownModelError) Error() string {
+	switch {
+	case e.Model == "":
+		return fmt.Sprintf("no models available for provider %q", e.Provider)
+	case e.Provider != "":
+		return fmt.Sprintf("unknown model %q for provider %q", e.Model, e.Provider)
+	default:
+		return fmt.Sprintf("unknown model %q", e.Model)
+	}
+}
+
+// ResolveModel returns the ID of a configured model. Given only a provider,
+// its preferred default model is chosen; given both, the model must belong to
+// the provider. Unknown models and providers yield an *UnknownModelError.
+func (m *Manager) ResolveModel(modelID, providerName string) (string, error) {
+	if providerName == "" {
+		if _, ok := m.GetModel(modelID); !ok {
+			return "", &UnknownModelError{Model: modelID}
+		}
+		return modelID, nil
+	}
+
+	p, ok := m.GetProviderWithModels(providerName)
+	if !ok || p == nil {
+		return "", &UnknownModelError{Model: modelID, Provider: providerName}
+	}
+	if modelID == "" {
+		chosen, err := m.ChooseDefaultModel(providerName, PreferredModels[providerName])
+		if err != nil {
+			return "", &UnknownModelError{Provider: providerName}
+		}
+		return chosen, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, model := range p.Models {
+		if model.ID == modelID {
+			return modelID, nil
+		}
+	}
+	return "", &UnknownModelError{Model: modelID, Provider: providerName}
+}
+
 // GetModelContextWindow returns the configured context window for the model if known
 func (m *Manager) GetModelContextWindow(modelID string) int {
 	model, ok := m.GetModel(modelID)
@@ -927,13 +1012,50 @@ func (m *Manager) CreateClient(modelID string) (llm.Client, error) {
 	// Wrap with caching-aware client to disable caching for OpenAI-compatible providers
 	client = llm.NewCachingAwareClient(client, provName)
 
-	interval := provider.rateLimitInterval()
-	tokensPerMinute := provider.tokensPerMinute()
-	if interval > 0 || tokensPerMinute > 0 {
-		return llm.NewRateLimitedClient(client, interval, tokensPerMinute), nil
+	// All clients of a provider share one limiter, so concurrent tabs and
+	// sub-agents smooth their bursts together and honor Retry-After jointly
+	return llm.NewRateLimitedClientWithLimiter(client, m.limiterFor(provider)), nil
+}
+
+// limiterFor returns the shared rate limiter of a provider, rebuilding it
+// when the provider's rate limit settings changed.
+func (m *Manager) limiterFor(p *Provider) *llm.RateLimiter {
+	interval := p.rateLimitInterval()
+	burst := p.rateLimitBurst()
+	tokensPerMinute := p.tokensPerMinute()
+
+	m.limitersMu.Lock()
+	defer m.limitersMu.Unlock()
+
+	if existing, ok := m.limiters[p.Name]; ok &&
+		existing.interval == interval && existing.burst == burst && existing.tokensPerMinute == tokensPerMinute {
+		return existing.limiter
 	}
 
-	return client, nil
+	if m.limiters == nil {
+		m.limiters = make(map[string]*providerLimiter)
+	}
+	limiter := llm.NewRateLimiter(interval, burst, tokensPerMinute)
+	m.limiters[p.Name] = &providerLimiter{
+		limiter:         limiter,
+		interval:        interval,
+		burst:           burst,
+		tokensPerMinute: tokensPerMinute,
+	}
+	return limiter
+}
+
+// RateLimiterStates returns the state of each provider's rate limiter that
+// has been used by a client so far, keyed by provider name.
+func (m *Manager) RateLimiterStates() map[string]llm.RateLimiterState {
+	m.limitersMu.Lock()
+	defer m.limitersMu.Unlock()
+
+	states := make(map[string]llm.RateLimiterState, len(m.limiters))
+	for name, entry := range m.limiters {
+		states[name] = entry.limiter.State()
+	}
+	return states
 }
 
 type warmupSpec struct {
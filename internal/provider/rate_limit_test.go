@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterForSharesPerProvider(t *testing.T) {
+	m := &Manager{}
+	p := &Provider{Name: "openai", RateLimit: &RateLimitConfig{RequestsPerMinute: 60, Burst: 2}}
+
+	first := m.limiterFor(p)
+	if second := m.limiterFor(p); second != first {
+		t.Fatalf("expected clients of one provider to share a limiter")
+	}
+	if other := m.limiterFor(&Provider{Name: "anthropic"}); other == first {
+		t.Fatalf("expected separate limiters per provider")
+	}
+
+	state := m.RateLimiterStates()["openai"]
+	if state.Interval != time.Second || state.Burst != 2 {
+		t.Fatalf("unexpected limiter state: %+v", state)
+	}
+
+	p.RateLimit.RequestsPerMinute = 30
+	if rebuilt := m.limiterFor(p); rebuilt == first {
+		t.Fatalf("expected limiter to be rebuilt after the rate limit changed")
+	}
+	if got := m.RateLimiterStates()["openai"].Interval; got != 2*time.Second {
+		t.Fatalf("expected rebuilt interval of 2s, got %v", got)
+	}
+}
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManagerRedactorIsPerInstance(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	first, err := NewManager(filepath.Join(dir, "first.json"), "")
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	second, err := NewManager(filepath.Join(dir, "second.json"), "")
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	const apiKey = "first-manager-key-0123456789"
+	if err := first.AddProvider("custom", apiKey, nil); err != nil {
+		t.Fatalf("AddProvider failed: %v", err)
+	}
+
+	if got := first.Redactor().Redact("key=" + apiKey); strings.Contains(got, apiKey) {
+		t.Errorf("expected the key to be masked by its manager, got %q", got)
+	}
+	if got := second.Redactor().Redact("key=" + apiKey); !strings.Contains(got, apiKey) {
+		t.Errorf("expected another manager not to know the key, got %q", got)
+	}
+}
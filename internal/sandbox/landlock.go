@@ -4,6 +4,12 @@
 // On non-Linux systems or when Landlock is unavailable, operations proceed without sandboxing.
 package sandbox
 
+import "errors"
+
+// ErrUnsupported is returned by operations that need Landlock on platforms
+// where it is not implemented. Callers should fall back to the authorizer.
+var ErrUnsupported = errors.New("landlock sandboxing is unsupported on this platform")
+
 // AccessLevel represents the type of filesystem access granted to a path.
 type AccessLevel int
 
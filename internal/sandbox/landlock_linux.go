@@ -209,15 +209,17 @@ func getPackageManagerConfigs() []PackageManagerConfig {
 
 // LandlockSandbox provides filesystem sandboxing using Linux Landlock LSM.
 type LandlockSandbox struct {
-	workspaceDir    string
-	allowedPaths    []DirectoryPermission
-	additionalPaths []DirectoryPermission
-	customROPaths   []string // Custom read-only paths from config
-	customRWPaths   []string // Custom read-write paths from config
-	enabled         bool
-	available       bool
-	bestEffort      bool
-	disabled        bool // Explicitly disabled via config
+	workspaceDir     string
+	allowedPaths     []DirectoryPermission
+	additionalPaths  []DirectoryPermission
+	customROPaths    []string // Custom read-only paths from config
+	customRWPaths    []string // Custom read-write paths from config
+	workspaceROPaths []string // Read-only paths approved for the workspace
+	workspaceRWPaths []string // Read-write paths approved for the workspace
+	enabled          bool
+	available        bool
+	bestEffort       bool
+	disabled         bool // Explicitly disabled via config
 }
 
 // NewLandlockSandbox creates a new Landlock sandbox for the given workspace.
@@ -412,6 +414,20 @@ func (s *LandlockSandbox) SetAdditionalPaths(paths []DirectoryPermission) {
 	s.additionalPaths = paths
 }
 
+// SetWorkspacePaths replaces the read-only and read-write paths approved for
+// the workspace. They are applied on the next Restrict; paths that do not
+// exist are skipped.
+func (s *LandlockSandbox) SetWorkspacePaths(readPaths, writePaths []string) error {
+	s.workspaceROPaths = append([]string(nil), readPaths...)
+	s.workspaceRWPaths = append([]string(nil), writePaths...)
+	return nil
+}
+
+// Supported reports whether Landlock sandboxing is implemented on this platform.
+func Supported() bool {
+	return true
+}
+
 // IsEnabled returns whether sandboxing is currently enabled.
 func (s *LandlockSandbox) IsEnabled() bool {
 	return s.enabled && s.available
@@ -487,8 +503,8 @@ func (s *LandlockSandbox) Restrict() error {
 		}
 	}
 
-	// Add custom paths from config
-	for _, path := range s.customROPaths {
+	// Add custom paths from config and workspace approvals
+	for _, path := range append(append([]string{}, s.customROPaths...), s.workspaceROPaths...) {
 		absPath, err := filepath.Abs(path)
 		if err != nil {
 			absPath = path
@@ -499,7 +515,7 @@ func (s *LandlockSandbox) Restrict() error {
 			logger.Debug("Added custom read-only path: %s", absPath)
 		}
 	}
-	for _, path := range s.customRWPaths {
+	for _, path := range append(append([]string{}, s.customRWPaths...), s.workspaceRWPaths...) {
 		absPath, err := filepath.Abs(path)
 		if err != nil {
 			absPath = path
@@ -4,8 +4,12 @@ package sandbox
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	llsys "github.com/landlock-lsm/go-landlock/landlock/syscall"
 )
 
 func TestNewLandlockSandbox(t *testing.T) {
@@ -241,3 +245,89 @@ func TestAddPathIfExists(t *testing.T) {
 		}
 	}
 }
+
+// landlockWriteHelperEnv makes the test binary act as the restricted child
+// process, since Landlock restrictions cannot be lifted once applied.
+const landlockWriteHelperEnv = "SCRIPTSCHNELL_LANDLOCK_WRITE_HELPER"
+
+func TestLandlockWorkspacePathsBlockOutsideWrites(t *testing.T) {
+	if dirs := os.Getenv(landlockWriteHelperEnv); dirs != "" {
+		runLandlockWriteHelper(t, filepath.SplitList(dirs))
+		return
+	}
+
+	if abi, err := llsys.LandlockGetABIVersion(); err != nil || abi < 1 {
+		t.Skip("Landlock is not supported by this kernel")
+	}
+
+	// Temp dirs are allowed by default, so the test dirs live next to the package
+	base, err := os.MkdirTemp(".", "landlock-test-")
+	if err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(base) })
+	base, err = filepath.Abs(base)
+	if err != nil {
+		t.Fatalf("failed to resolve test directory: %v", err)
+	}
+	for _, tmp := range []string{os.TempDir(), "/tmp", "/var/tmp"} {
+		if strings.HasPrefix(base, tmp+string(filepath.Separator)) {
+			t.Skipf("package directory %s is inside default-allowed %s", base, tmp)
+		}
+	}
+
+	workspace := filepath.Join(base, "workspace")
+	allowed := filepath.Join(base, "allowed")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{workspace, allowed, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	// Default rules cover /dev/stdout and /dev/stderr, which Landlock rejects
+	// when they are pipes, so the helper writes its output to a regular file
+	output, err := os.CreateTemp(t.TempDir(), "helper-output-")
+	if err != nil {
+		t.Fatalf("failed to create helper output file: %v", err)
+	}
+	defer output.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLandlockWorkspacePathsBlockOutsideWrites$")
+	cmd.Env = append(os.Environ(), landlockWriteHelperEnv+"="+strings.Join([]string{workspace, allowed, outside}, string(os.PathListSeparator)))
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		out, _ := os.ReadFile(output.Name())
+		t.Fatalf("restricted helper failed: %v\n%s", err, out)
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "blocked.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected write outside approved paths to be blocked, stat err=%v", err)
+	}
+}
+
+func runLandlockWriteHelper(t *testing.T, dirs []string) {
+	if len(dirs) != 3 {
+		t.Fatalf("expected workspace, allowed and outside dirs, got %v", dirs)
+	}
+	workspace, allowed, outside := dirs[0], dirs[1], dirs[2]
+
+	sb := NewLandlockSandbox(workspace, nil)
+	if err := sb.SetWorkspacePaths(nil, []string{allowed}); err != nil {
+		t.Fatalf("SetWorkspacePaths failed: %v", err)
+	}
+	if err := sb.Restrict(); err != nil {
+		t.Fatalf("Restrict failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(workspace, "ok.txt"), []byte("ok"), 0644); err != nil {
+		t.Errorf("expected write inside workspace to succeed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(allowed, "ok.txt"), []byte("ok"), 0644); err != nil {
+		t.Errorf("expected write to approved workspace path to succeed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "blocked.txt"), []byte("no"), 0644); err == nil {
+		t.Errorf("expected write outside approved paths to be blocked")
+	}
+}
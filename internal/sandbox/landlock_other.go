@@ -41,6 +41,17 @@ func (s *LandlockSandbox) SetAdditionalPaths(paths []DirectoryPermission) {
 	s.additionalPaths = paths
 }
 
+// SetWorkspacePaths returns ErrUnsupported on non-Linux; the authorizer
+// remains the only gate for paths outside the workspace.
+func (s *LandlockSandbox) SetWorkspacePaths(readPaths, writePaths []string) error {
+	return ErrUnsupported
+}
+
+// Supported always returns false on non-Linux.
+func Supported() bool {
+	return false
+}
+
 // IsEnabled always returns false on non-Linux.
 func (s *LandlockSandbox) IsEnabled() bool {
 	return false
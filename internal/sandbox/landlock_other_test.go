@@ -3,6 +3,7 @@
 package sandbox
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -70,3 +71,13 @@ func TestManager_NonLinux(t *testing.T) {
 		}
 	})
 }
+
+func TestSetWorkspacePaths_NonLinux(t *testing.T) {
+	sb := NewLandlockSandbox("/workspace", nil)
+	if err := sb.SetWorkspacePaths([]string{"/read"}, []string{"/write"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported on non-Linux, got %v", err)
+	}
+	if Supported() {
+		t.Error("expected Supported to be false on non-Linux")
+	}
+}
@@ -32,25 +32,27 @@ type Manager struct {
 
 // NewManager creates a new sandbox manager.
 func NewManager(cfg *config.Config, workspaceDir string) *Manager {
-	// Convert config.SandboxConfig to sandbox.SandboxConfig
-	var sandboxCfg *SandboxConfig
-	if cfg != nil {
-		sandboxCfg = &SandboxConfig{
-			AdditionalReadOnlyPaths:  cfg.Sandbox.AdditionalReadOnlyPaths,
-			AdditionalReadWritePaths: cfg.Sandbox.AdditionalReadWritePaths,
-			DisableSandbox:           cfg.Sandbox.DisableSandbox,
-			BestEffort:               cfg.Sandbox.BestEffort,
-		}
-	}
-
 	return &Manager{
-		sandbox:      NewLandlockSandbox(workspaceDir, sandboxCfg),
+		sandbox:      NewLandlockSandbox(workspaceDir, sandboxConfigFrom(cfg)),
 		config:       cfg,
 		workspaceDir: workspaceDir,
 		sessionPaths: make([]DirectoryPermission, 0),
 	}
 }
 
+// sandboxConfigFrom converts config.SandboxConfig to sandbox.SandboxConfig
+func sandboxConfigFrom(cfg *config.Config) *SandboxConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &SandboxConfig{
+		AdditionalReadOnlyPaths:  cfg.Sandbox.AdditionalReadOnlyPaths,
+		AdditionalReadWritePaths: cfg.Sandbox.AdditionalReadWritePaths,
+		DisableSandbox:           cfg.Sandbox.DisableSandbox,
+		BestEffort:               cfg.Sandbox.BestEffort,
+	}
+}
+
 // SetAuthorizationCallback sets the callback function for requesting authorization.
 func (m *Manager) SetAuthorizationCallback(cb AuthorizationCallback) {
 	m.mu.Lock()
@@ -142,6 +144,18 @@ func (m *Manager) RequestMultiplePaths(requests []RequestedDirectory) map[string
 	return results
 }
 
+// SetWorkspaceDir moves the manager to another workspace. Paths approved for
+// the session are dropped and the approvals of the new workspace are loaded.
+func (m *Manager) SetWorkspaceDir(workspaceDir string) {
+	m.mu.Lock()
+	m.sandbox = NewLandlockSandbox(workspaceDir, sandboxConfigFrom(m.config))
+	m.workspaceDir = workspaceDir
+	m.sessionPaths = make([]DirectoryPermission, 0)
+	m.mu.Unlock()
+
+	m.LoadWorkspaceApprovals()
+}
+
 // LoadWorkspaceApprovals loads previously approved paths from the workspace config.
 func (m *Manager) LoadWorkspaceApprovals() {
 	if m.config == nil {
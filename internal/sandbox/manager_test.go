@@ -254,3 +254,22 @@ func TestManagerRequestMultiplePaths(t *testing.T) {
 		t.Errorf("expected /path2 to be approved for session")
 	}
 }
+
+func TestManagerSetWorkspaceDir(t *testing.T) {
+	mgr := NewManager(nil, "/workspace")
+	mgr.SetAuthorizationCallback(func(req RequestedDirectory) AuthorizationDecision {
+		return DecisionApprovedSession
+	})
+	mgr.RequestPathAccess("/path1", AccessReadOnly, "test")
+
+	mgr.SetWorkspaceDir("/other")
+	if mgr.workspaceDir != "/other" {
+		t.Errorf("expected workspaceDir /other, got %s", mgr.workspaceDir)
+	}
+	if got := mgr.GetSandbox().GetWorkspaceDir(); got != "/other" {
+		t.Errorf("expected sandbox workspace /other, got %s", got)
+	}
+	if paths := mgr.GetSessionPaths(); len(paths) != 0 {
+		t.Errorf("expected session paths to be dropped, got %v", paths)
+	}
+}
@@ -0,0 +1,132 @@
+package secretdetect
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultRedactionPlaceholder replaces secrets masked by a Redactor.
+const DefaultRedactionPlaceholder = "[REDACTED]"
+
+// minKnownSecretLength avoids masking short, common strings that were
+// registered as secrets by accident (e.g. placeholder keys like "none").
+const minKnownSecretLength = 8
+
+// minEntropyTokenLength keeps the entropy heuristic away from short
+// identifiers; real keys and tokens are considerably longer.
+const minEntropyTokenLength = 20
+
+// bearerRegex matches bearer credentials in HTTP headers and header dumps,
+// keeping the header name so the redacted line stays readable.
+var bearerRegex = regexp.MustCompile(`(?i)(authorization"?\s*[:=]\s*"?bearer\s+)[^\s"',;]+`)
+
+// Redactor masks secrets in free-form text such as log lines and tool results.
+// It combines exact matches for registered values (e.g. configured provider
+// API keys), the default secret patterns, bearer tokens and, optionally,
+// high-entropy strings. It is safe for concurrent use.
+type Redactor struct {
+	mu          sync.RWMutex
+	replacement string
+	patterns    []*regexp.Regexp
+	known       []string // sorted longest first so overlapping values mask fully
+	highEntropy bool
+}
+
+// NewRedactor creates a redactor using the default secret patterns. An empty
+// replacement falls back to DefaultRedactionPlaceholder.
+func NewRedactor(replacement string) *Redactor {
+	r := &Redactor{}
+	r.SetReplacement(replacement)
+	for _, pattern := range GetDefaultPatterns() {
+		r.patterns = append(r.patterns, pattern.Regex)
+	}
+	return r
+}
+
+// SetReplacement changes the text secrets are replaced with.
+func (r *Redactor) SetReplacement(replacement string) {
+	if replacement == "" {
+		replacement = DefaultRedactionPlaceholder
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replacement = replacement
+}
+
+// SetHighEntropy enables masking of long high-entropy tokens. This catches
+// unknown key formats at the risk of masking hashes or encoded data.
+func (r *Redactor) SetHighEntropy(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.highEntropy = enabled
+}
+
+// AddKnownSecret registers a value that is always masked verbatim.
+// Values shorter than 8 characters are ignored.
+func (r *Redactor) AddKnownSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minKnownSecretLength {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, known := range r.known {
+		if known == value {
+			return
+		}
+	}
+	r.known = append(r.known, value)
+	sort.Slice(r.known, func(i, j int) bool {
+		return len(r.known[i]) > len(r.known[j])
+	})
+}
+
+// KnownSecrets returns a copy of the registered values.
+func (r *Redactor) KnownSecrets() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.known...)
+}
+
+// Redact returns text with all detected secrets replaced.
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, known := range r.known {
+		if strings.Contains(text, known) {
+			text = strings.ReplaceAll(text, known, r.replacement)
+		}
+	}
+
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllLiteralString(text, r.replacement)
+	}
+
+	text = bearerRegex.ReplaceAllStringFunc(text, func(match string) string {
+		prefix := bearerRegex.FindStringSubmatch(match)[1]
+		return prefix + r.replacement
+	})
+
+	if r.highEntropy {
+		for _, line := range strings.Split(text, "\n") {
+			for _, token := range TokenizeAndCheckEntropy(line, DefaultEntropyThreshold) {
+				if len(token) >= minEntropyTokenLength && !strings.Contains(token, r.replacement) {
+					text = strings.ReplaceAll(text, token, r.replacement)
+				}
+			}
+		}
+	}
+
+	return text
+}
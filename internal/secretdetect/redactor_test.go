@@ -0,0 +1,79 @@
+package secretdetect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor_KnownSecret(t *testing.T) {
+	r := NewRedactor("***")
+	r.AddKnownSecret("my-provider-key-1234567")
+	r.AddKnownSecret("short")
+
+	got := r.Redact("using key my-provider-key-1234567 for short request")
+	if got != "using key *** for short request" {
+		t.Fatalf("unexpected redaction: %q", got)
+	}
+}
+
+func TestRedactor_PatternsAndBearer(t *testing.T) {
+	r := NewRedactor("")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		leaking string
+	}{
+		{
+			name:    "OpenAI key",
+			input:   "key=sk-abcdefghijklmnopqrstuvwxyz123456",
+			want:    "key=[REDACTED]",
+			leaking: "sk-abcdef",
+		},
+		{
+			name:    "Authorization header",
+			input:   "Authorization: Bearer abc.def-ghi",
+			want:    "Authorization: Bearer [REDACTED]",
+			leaking: "abc.def-ghi",
+		},
+		{
+			name:    "JSON header dump",
+			input:   `{"Authorization":"Bearer tok_123"}`,
+			want:    `{"Authorization":"Bearer [REDACTED]"}`,
+			leaking: "tok_123",
+		},
+		{
+			name:  "No secrets",
+			input: "plain log line with commit 3f2a9c1d",
+			want:  "plain log line with commit 3f2a9c1d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Redact(tt.input)
+			if got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if tt.leaking != "" && strings.Contains(got, tt.leaking) {
+				t.Errorf("secret %q leaked in %q", tt.leaking, got)
+			}
+		})
+	}
+}
+
+func TestRedactor_HighEntropy(t *testing.T) {
+	token := "Zx9Qw2Er7Ty4Ui1Op6As3Df8Gh5Jk0Lm"
+	line := "token " + token
+
+	r := NewRedactor("")
+	if got := r.Redact(line); got != line {
+		t.Fatalf("expected high-entropy masking to be off by default, got %q", got)
+	}
+
+	r.SetHighEntropy(true)
+	if got := r.Redact(line); got != "token [REDACTED]" {
+		t.Fatalf("expected high-entropy token to be masked, got %q", got)
+	}
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// exportTimeFormat is used for timestamps in exports; UTC with nanoseconds so
+// messages created in the same second keep their order
+const exportTimeFormat = time.RFC3339Nano
+
+// ExportedMessage is a message in a session export
+type ExportedMessage struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Reasoning string    `json:"reasoning,omitempty"`
+	ToolName  string    `json:"tool_name,omitempty"`
+	ToolCalls []string  `json:"tool_calls,omitempty"` // names of the called tools
+	Pinned    bool      `json:"pinned,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExportedSession is the JSON export of a session. Messages are in
+// conversation order, which matches the order of their timestamps.
+type ExportedSession struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title,omitempty"`
+	WorkingDir string            `json:"working_dir"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Messages   []ExportedMessage `json:"messages"`
+}
+
+// Export returns a snapshot of the session for exporting
+func (s *Session) Export() *ExportedSession {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exported := &ExportedSession{
+		ID:         s.ID,
+		Title:      s.Title,
+		WorkingDir: s.WorkingDir,
+		CreatedAt:  s.CreatedAt.UTC(),
+		UpdatedAt:  s.UpdatedAt.UTC(),
+		Messages:   make([]ExportedMessage, 0, len(s.Messages)),
+	}
+	for _, msg := range s.Messages {
+		if msg == nil {
+			continue
+		}
+		exported.Messages = append(exported.Messages, ExportedMessage{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Reasoning: msg.Reasoning,
+			ToolName:  msg.ToolName,
+			ToolCalls: toolCallNames(msg.ToolCalls),
+			Pinned:    msg.Pinned,
+			Timestamp: msg.Timestamp.UTC(),
+		})
+	}
+	return exported
+}
+
+// ExportJSON returns the session as indented JSON
+func (s *Session) ExportJSON() ([]byte, error) {
+	return json.MarshalIndent(s.Export(), "", "  ")
+}
+
+// ExportMarkdown renders the session as markdown with one section per
+// message, headed by its role and timestamp
+func (s *Session) ExportMarkdown() string {
+	exported := s.Export()
+
+	var sb strings.Builder
+	title := exported.Title
+	if title == "" {
+		title = "Session " + exported.ID
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "- Working directory: `%s`\n", exported.WorkingDir)
+	fmt.Fprintf(&sb, "- Created: %s\n", formatExportTime(exported.CreatedAt))
+	fmt.Fprintf(&sb, "- Updated: %s\n", formatExportTime(exported.UpdatedAt))
+
+	for _, msg := range exported.Messages {
+		heading := exportRoleHeading(msg)
+		fmt.Fprintf(&sb, "\n## %s (%s)\n\n", heading, formatExportTime(msg.Timestamp))
+		if msg.Pinned {
+			sb.WriteString("_Pinned_\n\n")
+		}
+		if msg.Role == "tool" {
+			fmt.Fprintf(&sb, "```\n%s\n```\n", strings.TrimRight(msg.Content, "\n"))
+		} else if content := strings.TrimSpace(msg.Content); content != "" {
+			sb.WriteString(content)
+			sb.WriteString("\n")
+		}
+		if len(msg.ToolCalls) > 0 {
+			fmt.Fprintf(&sb, "\nCalled: %s\n", strings.Join(msg.ToolCalls, ", "))
+		}
+	}
+	return sb.String()
+}
+
+func exportRoleHeading(msg ExportedMessage) string {
+	switch msg.Role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "system":
+		return "Summary"
+	case "tool":
+		if msg.ToolName != "" {
+			return "Tool: " + msg.ToolName
+		}
+		return "Tool"
+	default:
+		return msg.Role
+	}
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format(exportTimeFormat)
+}
+
+// toolCallNames returns the function names of tool calls in unified format
+func toolCallNames(toolCalls []map[string]interface{}) []string {
+	var names []string
+	for _, call := range toolCalls {
+		if fn, ok := call["function"].(map[string]interface{}); ok {
+			if name, ok := fn["name"].(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExportTestSession() *Session {
+	s := NewSession("bright-silver-falcon", "/work")
+	s.Title = "Fix the parser"
+	base := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	s.Messages = []*Message{
+		{Role: "user", Content: "Fix the parser", Timestamp: base},
+		{Role: "assistant", Content: "Reading it", Timestamp: base.Add(time.Second), ToolCalls: []map[string]interface{}{
+			{"id": "call_1", "type": "function", "function": map[string]interface{}{"name": "read_file", "arguments": "{}"}},
+		}},
+		{Role: "tool", ToolName: "read_file", Content: "package parser", Timestamp: base.Add(1500 * time.Millisecond)},
+	}
+	return s
+}
+
+func TestExportMarkdownRendersTimestamps(t *testing.T) {
+	markdown := newExportTestSession().ExportMarkdown()
+
+	for _, want := range []string{
+		"# Fix the parser",
+		"## User (2025-01-15T10:30:00Z)",
+		"## Assistant (2025-01-15T10:30:01Z)",
+		"Called: read_file",
+		"## Tool: read_file (2025-01-15T10:30:01.5Z)",
+		"```\npackage parser\n```",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestExportJSONIncludesTimestamps(t *testing.T) {
+	data, err := newExportTestSession().ExportJSON()
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+
+	var exported ExportedSession
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid JSON export: %v", err)
+	}
+	if len(exported.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(exported.Messages))
+	}
+	for i, msg := range exported.Messages {
+		if msg.Timestamp.IsZero() {
+			t.Errorf("message %d has no timestamp", i)
+		}
+		if i > 0 && msg.Timestamp.Before(exported.Messages[i-1].Timestamp) {
+			t.Errorf("message %d is out of order", i)
+		}
+	}
+	if got := exported.Messages[1].ToolCalls; len(got) != 1 || got[0] != "read_file" {
+		t.Errorf("expected the tool call name, got %v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,6 +24,7 @@ type Message struct {
 	ToolName        string                   `json:"tool_name,omitempty"`        // Name of the tool for tool responses
 	ToolDescription string                   `json:"tool_description,omitempty"` // Description of the tool for tool responses
 	Timestamp       time.Time                `json:"timestamp"`
+	Pinned          bool                     `json:"pinned,omitempty"` // Kept verbatim when older context is compacted
 
 	// Native format storage (for prompt caching)
 	NativeFormat      interface{} `json:"native_format,omitempty"`       // Provider-specific message format
@@ -79,6 +81,8 @@ type Session struct {
 	CurrentBranch             string                // Current Git branch (if in a repository)
 	HasVCS                    bool                  // Whether a VCS (e.g., git) is available in the workspace
 	TaskExecutionSummary      *TaskExecutionSummary // Summary of work completed in this task session
+	SaveSummary               *SessionSummary       // Metadata generated when the session was last saved
+	ReadOnly                  bool                  // Only read/search tools are available; writes are denied
 
 	// Verification retry tracking
 	VerificationAttempt      int  // Current verification attempt number (1-3)
@@ -95,6 +99,9 @@ type Session struct {
 	TotalCacheCreationTokens int     // Total cache creation tokens
 	TotalCacheReadTokens     int     // Total cache read tokens
 
+	// Usage of every prompt, persisted with the session
+	UsageHistory []UsageRecord
+
 	// Shell temp directory - a random subdirectory in temp for shell command execution
 	ShellTempDir string
 	// SandboxOutputDir - directory for storing large sandbox output files that exceed context window limits
@@ -214,12 +221,27 @@ func GenerateID() string {
 func (s *Session) AddMessage(msg *Message) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	msg.Timestamp = time.Now()
+	msg.Timestamp = s.nextMessageTimestamp(time.Now())
 	s.Messages = append(s.Messages, msg)
 	s.UpdatedAt = time.Now()
 	s.Dirty = true
 }
 
+// nextMessageTimestamp returns now, or the timestamp of the last message if
+// the wall clock went backwards, so message timestamps never decrease.
+// Callers hold s.mu.
+func (s *Session) nextMessageTimestamp(now time.Time) time.Time {
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if msg := s.Messages[i]; msg != nil && !msg.Timestamp.IsZero() {
+			if now.Before(msg.Timestamp) {
+				return msg.Timestamp
+			}
+			break
+		}
+	}
+	return now
+}
+
 // GetMessages returns all messages
 func (s *Session) GetMessages() []*Message {
 	s.mu.RLock()
@@ -243,28 +265,66 @@ func (s *Session) UserMessageCount() int {
 	return count
 }
 
-// TrackFileRead tracks that a file was read
+// TrackFileRead tracks that a file was read. The main tools and the sandbox
+// share this record, so a file read by one may be written by the other.
 func (s *Session) TrackFileRead(path, content string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.FilesRead[path] = content
+	s.FilesRead[s.trackedPath(path)] = content
+	s.UpdatedAt = time.Now()
+	s.Dirty = true
+}
+
+// SetReadOnly switches the session's read-only mode, in which the agent may
+// read and search but not change files or run programs
+func (s *Session) SetReadOnly(readOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ReadOnly == readOnly {
+		return
+	}
+	s.ReadOnly = readOnly
 	s.UpdatedAt = time.Now()
 	s.Dirty = true
 }
 
+// IsReadOnly reports whether the session is in read-only mode
+func (s *Session) IsReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ReadOnly
+}
+
 // WasFileRead checks if a file was read in this session
 func (s *Session) WasFileRead(path string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	_, ok := s.FilesRead[path]
+	_, ok := s.FilesRead[s.trackedPath(path)]
 	return ok
 }
 
+// trackedPath returns the key of path in FilesRead and FilesModified. Tools
+// and the sandbox pass paths as "./a.go", "a.go" or "/work/a.go", which all
+// name the same file: paths are cleaned, and absolute paths inside the
+// working directory are made relative to it. Callers hold s.mu.
+func (s *Session) trackedPath(path string) string {
+	clean := filepath.Clean(path)
+	if s.WorkingDir == "" || !filepath.IsAbs(clean) {
+		return clean
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(s.WorkingDir), clean)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return clean
+	}
+	return rel
+}
+
 // TrackFileModified tracks that a file was modified
 func (s *Session) TrackFileModified(path string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.FilesModified[path] = true
+	s.FilesModified[s.trackedPath(path)] = true
 	s.UpdatedAt = time.Now()
 	s.Dirty = true
 }
@@ -570,8 +630,55 @@ func (s *Session) GetAuthorizedCommands() []string {
 	return commands
 }
 
+// PinMessage marks the message at index so compaction keeps it verbatim.
+// Only user and assistant messages without tool calls can be pinned, because
+// keeping half of a tool exchange would leave the conversation malformed.
+func (s *Session) PinMessage(index int) error {
+	return s.setMessagePinned(index, true)
+}
+
+// UnpinMessage allows the message at index to be compacted again
+func (s *Session) UnpinMessage(index int) error {
+	return s.setMessagePinned(index, false)
+}
+
+func (s *Session) setMessagePinned(index int, pinned bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index < 0 || index >= len(s.Messages) || s.Messages[index] == nil {
+		return fmt.Errorf("message index %d out of range (session has %d messages)", index, len(s.Messages))
+	}
+	msg := s.Messages[index]
+	if pinned && ((msg.Role != "user" && msg.Role != "assistant") || len(msg.ToolCalls) > 0) {
+		return fmt.Errorf("message %d cannot be pinned: only user and assistant messages without tool calls can be pinned", index)
+	}
+	if msg.Pinned == pinned {
+		return nil
+	}
+
+	msg.Pinned = pinned
+	s.UpdatedAt = time.Now()
+	s.Dirty = true
+	return nil
+}
+
+// PinnedMessageIndices returns the indices of all pinned messages
+func (s *Session) PinnedMessageIndices() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var indices []int
+	for i, msg := range s.Messages {
+		if msg != nil && msg.Pinned {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
 // CompactWithSummary replaces the oldest messages with a summary message.
-// The original slice must correspond to the current head of the session when the
+// Pinned messages among them are kept. The original slice must correspond to the current head of the session when the
 // compaction is applied; otherwise it will no-op and return false.
 func (s *Session) CompactWithSummary(original []*Message, summary string) bool {
 	if len(original) == 0 {
@@ -591,14 +698,29 @@ func (s *Session) CompactWithSummary(original []*Message, summary string) bool {
 		}
 	}
 
+	// The summary takes the place of the oldest compacted message, so it
+	// carries its timestamp and the messages stay ordered
+	timestamp := time.Now()
+	for _, msg := range original {
+		if msg != nil && !msg.Timestamp.IsZero() {
+			timestamp = msg.Timestamp
+			break
+		}
+	}
 	summaryMsg := &Message{
 		Role:      "system",
 		Content:   summary,
-		Timestamp: time.Now(),
+		Timestamp: timestamp,
 	}
 
+	// Pinned messages are kept verbatim right after the summary
 	newMessages := make([]*Message, 0, len(s.Messages)-len(original)+1)
 	newMessages = append(newMessages, summaryMsg)
+	for _, msg := range original {
+		if msg != nil && msg.Pinned {
+			newMessages = append(newMessages, msg)
+		}
+	}
 	newMessages = append(newMessages, s.Messages[len(original):]...)
 	s.Messages = newMessages
 	s.UpdatedAt = time.Now()
@@ -658,6 +780,22 @@ func (s *Session) GetCurrentBranch() string {
 	return s.CurrentBranch
 }
 
+// GetWorkingDir returns the session's working directory
+func (s *Session) GetWorkingDir() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.WorkingDir
+}
+
+// SetWorkingDir moves the session to another working directory
+func (s *Session) SetWorkingDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.WorkingDir = dir
+	s.UpdatedAt = time.Now()
+	s.Dirty = true
+}
+
 // SetHasVCS sets whether a VCS (e.g., git) is available in the workspace
 func (s *Session) SetHasVCS(hasVCS bool) {
 	s.mu.Lock()
@@ -746,28 +884,32 @@ func (s *Session) AccumulateUsage(usage map[string]interface{}) {
 	// Accumulate cost (may be "cost" or "total_cost" depending on provider)
 	if cost, ok := usage["cost"]; ok {
 		s.TotalCost += toFloat64(cost)
-	}
-	if cost, ok := usage["total_cost"]; ok {
+	} else if cost, ok := usage["total_cost"]; ok {
 		s.TotalCost += toFloat64(cost)
 	}
 
-	// Accumulate tokens
-	if promptTokens, ok := usage["prompt_tokens"]; ok {
-		s.TotalPromptTokens += toInt(promptTokens)
+	// Accumulate tokens. Some providers report the alternative field names
+	// (input_tokens, output_tokens) as well, so they only count if the
+	// primary ones are missing.
+	promptTokens, completionTokens := 0, 0
+	if v, ok := usage["prompt_tokens"]; ok {
+		promptTokens = toInt(v)
+	} else if v, ok := usage["input_tokens"]; ok {
+		promptTokens = toInt(v)
 	}
-	if completionTokens, ok := usage["completion_tokens"]; ok {
-		s.TotalCompletionTokens += toInt(completionTokens)
+	if v, ok := usage["completion_tokens"]; ok {
+		completionTokens = toInt(v)
+	} else if v, ok := usage["output_tokens"]; ok {
+		completionTokens = toInt(v)
 	}
+	s.TotalPromptTokens += promptTokens
+	s.TotalCompletionTokens += completionTokens
+
+	// Derive the total of the call if it is not provided
 	if totalTokens, ok := usage["total_tokens"]; ok {
 		s.TotalTokens += toInt(totalTokens)
-	}
-
-	// Alternative field names used by some providers
-	if inputTokens, ok := usage["input_tokens"]; ok {
-		s.TotalPromptTokens += toInt(inputTokens)
-	}
-	if outputTokens, ok := usage["output_tokens"]; ok {
-		s.TotalCompletionTokens += toInt(outputTokens)
+	} else {
+		s.TotalTokens += promptTokens + completionTokens
 	}
 
 	// Cache-related tokens
@@ -781,11 +923,6 @@ func (s *Session) AccumulateUsage(usage map[string]interface{}) {
 		s.TotalCacheReadTokens += toInt(cacheReadTokens)
 	}
 
-	// Recalculate total tokens if not directly provided
-	if s.TotalTokens == 0 && (s.TotalPromptTokens > 0 || s.TotalCompletionTokens > 0) {
-		s.TotalTokens = s.TotalPromptTokens + s.TotalCompletionTokens
-	}
-
 	s.UpdatedAt = time.Now()
 	s.Dirty = true
 }
@@ -821,6 +958,54 @@ func (s *Session) GetUsageStats() map[string]interface{} {
 	return stats
 }
 
+// UsageRecord is the token usage and cost accrued by one prompt
+type UsageRecord struct {
+	Timestamp        time.Time `json:"timestamp"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+}
+
+// SumUsage adds up usage records; the timestamp is the one of the newest record
+func SumUsage(records []UsageRecord) UsageRecord {
+	var total UsageRecord
+	for _, record := range records {
+		total.PromptTokens += record.PromptTokens
+		total.CompletionTokens += record.CompletionTokens
+		total.TotalTokens += record.TotalTokens
+		total.Cost += record.Cost
+		if record.Timestamp.After(total.Timestamp) {
+			total.Timestamp = record.Timestamp
+		}
+	}
+	return total
+}
+
+// RecordPromptUsage appends the usage of a finished prompt to the usage
+// history. Prompts that used no tokens are not recorded.
+func (s *Session) RecordPromptUsage(record UsageRecord) {
+	if record.TotalTokens == 0 && record.PromptTokens == 0 && record.CompletionTokens == 0 && record.Cost == 0 {
+		return
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.UsageHistory = append(s.UsageHistory, record)
+	s.UpdatedAt = time.Now()
+	s.Dirty = true
+}
+
+// GetUsageHistory returns the usage of every recorded prompt, oldest first
+func (s *Session) GetUsageHistory() []UsageRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]UsageRecord(nil), s.UsageHistory...)
+}
+
 // StartVerificationAttempt increments and returns the verification attempt counter
 func (s *Session) StartVerificationAttempt() int {
 	s.mu.Lock()
@@ -932,6 +1117,58 @@ func (s *Session) ModifyLastAssistantMessage(modifier func(msg *Message) bool) b
 	return false
 }
 
+// MergeContinuation merges an assistant response to a continuation prompt into
+// the truncated assistant message it continues. It looks for the last user
+// message with content prompt that directly follows an assistant message
+// without tool calls and is directly followed by an assistant message. Those
+// three messages are replaced by one assistant message with content
+// merge(previous, continued) and the tool calls of the continued message.
+// Returns the merged content and true if such a turn was found.
+func (s *Session) MergeContinuation(prompt string, merge func(prev, next string) string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.Messages) - 2; i >= 1; i-- {
+		msg := s.Messages[i]
+		if msg == nil || msg.Role != "user" || msg.Content != prompt {
+			continue
+		}
+		prev, next := s.Messages[i-1], s.Messages[i+1]
+		if prev == nil || prev.Role != "assistant" || len(prev.ToolCalls) > 0 || next == nil || next.Role != "assistant" {
+			return "", false
+		}
+
+		merged := &Message{
+			Role:      "assistant",
+			Content:   merge(prev.Content, next.Content),
+			Reasoning: joinNonEmpty(prev.Reasoning, next.Reasoning),
+			ToolCalls: next.ToolCalls,
+			Timestamp: prev.Timestamp,
+			Pinned:    prev.Pinned,
+		}
+		messages := make([]*Message, 0, len(s.Messages)-2)
+		messages = append(messages, s.Messages[:i-1]...)
+		messages = append(messages, merged)
+		messages = append(messages, s.Messages[i+2:]...)
+		s.Messages = messages
+		s.UpdatedAt = time.Now()
+		s.Dirty = true
+		return merged.Content, true
+	}
+	return "", false
+}
+
+func joinNonEmpty(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n\n" + b
+	}
+}
+
 // AddPlanningQuestionAnswer adds a question and answer pair from the planning phase
 func (s *Session) AddPlanningQuestionAnswer(question, answer string) {
 	s.mu.Lock()
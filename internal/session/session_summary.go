@@ -0,0 +1,174 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+const (
+	// maxSummaryKeyFiles caps the files listed in a session summary
+	maxSummaryKeyFiles = 20
+	// maxSummaryPromptChars caps each conversation excerpt sent to the model
+	maxSummaryPromptChars = 1000
+	// maxSummaryUserPrompts caps the user prompts sent to the model
+	maxSummaryUserPrompts = 5
+)
+
+// SessionSummary is machine-readable metadata describing a saved session, so
+// clients can label it without reading the conversation
+type SessionSummary struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	KeyFiles    []string  `json:"key_files,omitempty"`
+	Generated   bool      `json:"generated"` // false if built without the summarize model
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SetSaveSummary stores the summary generated when the session was saved
+func (s *Session) SetSaveSummary(summary *SessionSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SaveSummary = summary
+	s.Dirty = true
+}
+
+// GetSaveSummary returns the summary generated when the session was last saved
+func (s *Session) GetSaveSummary() *SessionSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.SaveSummary
+}
+
+// GenerateSummary builds a session summary (title, description, key files)
+// with the summarize client. If the client is unavailable or fails, the title
+// falls back to the truncated first user prompt.
+func (tg *TitleGenerator) GenerateSummary(ctx context.Context, sess *Session) *SessionSummary {
+	messages := sess.GetMessages()
+	summary := &SessionSummary{
+		KeyFiles:  sessionKeyFiles(sess),
+		CreatedAt: time.Now(),
+	}
+
+	var userPrompts []string
+	var lastAssistant string
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user":
+			userPrompts = append(userPrompts, msg.Content)
+		case "assistant":
+			if strings.TrimSpace(msg.Content) != "" {
+				lastAssistant = msg.Content
+			}
+		}
+	}
+
+	firstPrompt := ""
+	if len(userPrompts) > 0 {
+		firstPrompt = userPrompts[0]
+	}
+	summary.Title = generateSimpleTitle(firstPrompt)
+
+	if tg.summarizeClient == nil || len(userPrompts) == 0 {
+		return summary
+	}
+
+	response, err := tg.summarizeClient.Complete(ctx, buildSessionSummaryPrompt(userPrompts, lastAssistant, summary.KeyFiles))
+	if err != nil {
+		logger.Warn("Failed to generate session summary with LLM, using fallback: %v", err)
+		return summary
+	}
+
+	var result struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	if err := llm.ParseLLMJSONResponse(response, &result); err != nil {
+		logger.Warn("Failed to parse LLM session summary, using fallback: %v", err)
+		return summary
+	}
+
+	title := strings.TrimSpace(result.Title)
+	if title == "" {
+		logger.Warn("LLM returned empty session summary title, using fallback")
+		return summary
+	}
+	if utf8.RuneCountInString(title) > 80 {
+		title = truncateRunes(title, 77) + "..."
+	}
+
+	summary.Title = title
+	summary.Description = strings.TrimSpace(result.Description)
+	summary.Generated = true
+	return summary
+}
+
+// sessionKeyFiles returns the files modified in the session, or the files
+// read if nothing was modified
+func sessionKeyFiles(sess *Session) []string {
+	files := sess.GetModifiedFiles()
+	if len(files) == 0 {
+		files = sess.GetFilesRead()
+	}
+	sort.Strings(files)
+	if len(files) > maxSummaryKeyFiles {
+		files = files[:maxSummaryKeyFiles]
+	}
+	return files
+}
+
+// buildSessionSummaryPrompt builds the prompt for session summary generation
+func buildSessionSummaryPrompt(userPrompts []string, lastAssistant string, keyFiles []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are summarizing a coding session so it can be found again later.\n\n")
+
+	sb.WriteString("User requests (in order):\n")
+	for i, prompt := range userPrompts {
+		if i >= maxSummaryUserPrompts {
+			fmt.Fprintf(&sb, "... and %d more requests\n", len(userPrompts)-maxSummaryUserPrompts)
+			break
+		}
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, truncateSummaryExcerpt(prompt))
+	}
+	sb.WriteString("\n")
+
+	if lastAssistant != "" {
+		fmt.Fprintf(&sb, "Last assistant response:\n%s\n\n", truncateSummaryExcerpt(lastAssistant))
+	}
+
+	if len(keyFiles) > 0 {
+		sb.WriteString("Files touched:\n")
+		for _, file := range keyFiles {
+			fmt.Fprintf(&sb, "- %s\n", file)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Provide:\n")
+	sb.WriteString("- title: a concise, descriptive title (maximum 80 characters)\n")
+	sb.WriteString("- description: one short paragraph describing the goal of the session and what was done\n\n")
+	sb.WriteString("Respond with ONLY a JSON object in this exact format (no markdown, no code blocks):\n")
+	sb.WriteString(`{"title": "...", "description": "..."}`)
+
+	return sb.String()
+}
+
+func truncateSummaryExcerpt(text string) string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxSummaryPromptChars {
+		return truncateRunes(text, maxSummaryPromptChars) + "..."
+	}
+	return text
+}
+
+// truncateRunes returns the first n runes of text
+func truncateRunes(text string, n int) string {
+	return string([]rune(text)[:n])
+}
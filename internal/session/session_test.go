@@ -1,9 +1,13 @@
 package session
 
 import (
+	"path/filepath"
 	"runtime"
+	"sort"
 	"testing"
 	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
 )
 
 func TestCompactWithSummarySuccess(t *testing.T) {
@@ -265,6 +269,125 @@ func TestCompactWithSummary_SetsDirtyFlag(t *testing.T) {
 	}
 }
 
+type fakeAutoSaveTicker struct {
+	ch chan time.Time
+}
+
+func (f *fakeAutoSaveTicker) C() <-chan time.Time { return f.ch }
+func (f *fakeAutoSaveTicker) Stop()               {}
+
+func newAutoSaveStorageWithFakeClock(t *testing.T) (*SessionStorage, *fakeAutoSaveTicker) {
+	t.Helper()
+	setSessionStorageEnv(t, t.TempDir())
+
+	storage, err := NewSessionStorageWithConfig(func() *config.AutoSaveConfig {
+		return &config.AutoSaveConfig{Enabled: true, SaveIntervalSeconds: 60}
+	})
+	if err != nil {
+		t.Fatalf("Failed to create session storage: %v", err)
+	}
+
+	ticker := &fakeAutoSaveTicker{ch: make(chan time.Time)}
+	storage.newTicker = func(d time.Duration) autoSaveTicker {
+		if d != 60*time.Second {
+			t.Errorf("expected 60s auto-save interval, got %v", d)
+		}
+		return ticker
+	}
+	return storage, ticker
+}
+
+func TestAutoSaveSavesOnTickAndSkipsWhenIdle(t *testing.T) {
+	storage, ticker := newAutoSaveStorageWithFakeClock(t)
+
+	s := NewSession("autosave", t.TempDir())
+	s.AddMessage(&Message{Role: "user", Content: "hello"})
+
+	storage.StartAutoSave(s, "Autosave")
+	ticker.ch <- time.Now()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.IsDirty() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected auto-save to persist the session after a tick")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	savedAt := s.LastSavedAt
+
+	// Nothing changed: further ticks and the final flush must not save again
+	ticker.ch <- time.Now()
+	ticker.ch <- time.Now()
+	storage.StopAutoSave()
+
+	if !s.LastSavedAt.Equal(savedAt) {
+		t.Fatalf("expected idle session not to be re-saved, last save moved from %v to %v", savedAt, s.LastSavedAt)
+	}
+
+	sessions, err := storage.ListSessions(s.WorkingDir)
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected a single autosave slot, got %d sessions", len(sessions))
+	}
+}
+
+func TestStopAutoSaveFlushesPendingChanges(t *testing.T) {
+	storage, _ := newAutoSaveStorageWithFakeClock(t)
+
+	s := NewSession("autosave-flush", t.TempDir())
+	storage.StartAutoSave(s, "Autosave")
+	s.AddMessage(&Message{Role: "user", Content: "unsaved"})
+
+	storage.StopAutoSave()
+
+	if s.IsDirty() {
+		t.Fatal("expected StopAutoSave to flush pending changes")
+	}
+	loaded, err := storage.LoadSession(s.WorkingDir, s.ID)
+	if err != nil {
+		t.Fatalf("Failed to load flushed session: %v", err)
+	}
+	if got := len(loaded.GetMessages()); got != 1 {
+		t.Fatalf("expected 1 message in flushed session, got %d", got)
+	}
+}
+
+func TestRenameSessionUpdatesAutoSaveName(t *testing.T) {
+	storage, ticker := newAutoSaveStorageWithFakeClock(t)
+
+	s := NewSession("autosave-rename", t.TempDir())
+	s.AddMessage(&Message{Role: "user", Content: "hello"})
+	if err := storage.SaveSession(s, "Autosave"); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	storage.StartAutoSave(s, "Autosave")
+	defer storage.StopAutoSave()
+
+	if err := storage.RenameSession(s.WorkingDir, s.ID, "Renamed"); err != nil {
+		t.Fatalf("Failed to rename session: %v", err)
+	}
+
+	s.AddMessage(&Message{Role: "user", Content: "more"})
+	ticker.ch <- time.Now()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.IsDirty() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected auto-save to persist the session after a tick")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stored, err := storage.loadStoredSession(s.WorkingDir, s.ID)
+	if err != nil {
+		t.Fatalf("Failed to load stored session: %v", err)
+	}
+	if stored.Name != "Renamed" {
+		t.Fatalf("expected autosave to keep the new name, got %q", stored.Name)
+	}
+}
+
 func setSessionStorageEnv(t *testing.T, dir string) {
 	t.Helper()
 	switch runtime.GOOS {
@@ -276,3 +399,409 @@ func setSessionStorageEnv(t *testing.T, dir string) {
 		t.Setenv("HOME", dir)
 	}
 }
+
+func TestMergeContinuation(t *testing.T) {
+	s := NewSession("test", ".")
+	s.AddMessage(&Message{Role: "user", Content: "write main.go"})
+	s.AddMessage(&Message{Role: "assistant", Content: "```go\nfunc main() {", NativeFormat: map[string]interface{}{"role": "assistant"}})
+	s.AddMessage(&Message{Role: "user", Content: "resume"})
+	s.AddMessage(&Message{Role: "assistant", Content: "\n}\n```", ToolCalls: []map[string]interface{}{{"id": "call-1"}}})
+	s.AddMessage(&Message{Role: "tool", ToolID: "call-1", Content: "ok"})
+	s.MarkSaved(time.Now())
+
+	merged, ok := s.MergeContinuation("resume", func(prev, next string) string { return prev + next })
+	if !ok || merged != "```go\nfunc main() {\n}\n```" {
+		t.Fatalf("unexpected merge result %q (%v)", merged, ok)
+	}
+
+	msgs := s.GetMessages()
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages after merge, got %d", len(msgs))
+	}
+	if msgs[1].Role != "assistant" || msgs[1].Content != merged || len(msgs[1].ToolCalls) != 1 || msgs[1].NativeFormat != nil {
+		t.Errorf("unexpected merged message: %+v", msgs[1])
+	}
+	if msgs[2].Role != "tool" {
+		t.Errorf("expected tool result to follow the merged message, got %s", msgs[2].Role)
+	}
+	if !s.IsDirty() {
+		t.Error("expected merge to mark the session dirty")
+	}
+
+	if _, ok := s.MergeContinuation("resume", func(prev, next string) string { return prev + next }); ok {
+		t.Error("expected no second merge")
+	}
+}
+
+func TestCompactWithSummaryKeepsPinnedMessages(t *testing.T) {
+	s := NewSession("test", ".")
+	spec := &Message{Role: "user", Content: "Spec: the parser must never allocate"}
+	s.AddMessage(spec)
+	s.AddMessage(&Message{Role: "assistant", Content: "Understood"})
+	s.AddMessage(&Message{Role: "user", Content: "now add tests"})
+	s.AddMessage(&Message{Role: "assistant", Content: "Added tests"})
+	s.AddMessage(&Message{Role: "user", Content: "thanks"})
+
+	if err := s.PinMessage(0); err != nil {
+		t.Fatalf("PinMessage failed: %v", err)
+	}
+
+	original := s.GetMessages()[:3]
+	if !s.CompactWithSummary(original, "summary of setup") {
+		t.Fatal("expected compaction to apply")
+	}
+
+	msgs := s.GetMessages()
+	if len(msgs) != 4 {
+		t.Fatalf("expected summary, pinned spec and 2 recent messages, got %d messages", len(msgs))
+	}
+	if msgs[0].Role != "system" || msgs[0].Content != "summary of setup" {
+		t.Errorf("expected summary first, got %+v", msgs[0])
+	}
+	if msgs[1] != spec || !msgs[1].Pinned {
+		t.Errorf("expected the pinned spec to survive verbatim, got %+v", msgs[1])
+	}
+	if msgs[2].Content != "Added tests" || msgs[3].Content != "thanks" {
+		t.Errorf("unexpected recent messages: %q, %q", msgs[2].Content, msgs[3].Content)
+	}
+	if got := s.PinnedMessageIndices(); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected pinned index 1 after compaction, got %v", got)
+	}
+}
+
+func TestPinMessageValidation(t *testing.T) {
+	s := NewSession("test", ".")
+	s.AddMessage(&Message{Role: "user", Content: "run ls"})
+	s.AddMessage(&Message{Role: "assistant", ToolCalls: []map[string]interface{}{{"id": "call-1"}}})
+	s.AddMessage(&Message{Role: "tool", ToolID: "call-1", Content: "file.go"})
+
+	if err := s.PinMessage(3); err == nil {
+		t.Error("expected error for out of range index")
+	}
+	if err := s.PinMessage(1); err == nil {
+		t.Error("expected error when pinning a tool call")
+	}
+	if err := s.PinMessage(2); err == nil {
+		t.Error("expected error when pinning a tool result")
+	}
+
+	s.MarkSaved(time.Now())
+	if err := s.PinMessage(0); err != nil {
+		t.Fatalf("PinMessage failed: %v", err)
+	}
+	if !s.IsDirty() {
+		t.Error("expected pinning to mark the session dirty")
+	}
+	if err := s.UnpinMessage(0); err != nil {
+		t.Fatalf("UnpinMessage failed: %v", err)
+	}
+	if len(s.PinnedMessageIndices()) != 0 {
+		t.Error("expected no pinned messages after unpin")
+	}
+}
+
+func TestSaveSessionKeepsPinnedMessages(t *testing.T) {
+	tempDir := t.TempDir()
+	setSessionStorageEnv(t, tempDir)
+
+	storage, err := NewSessionStorage()
+	if err != nil {
+		t.Fatalf("Failed to create session storage: %v", err)
+	}
+
+	s := NewSession("test-pinned", tempDir)
+	s.AddMessage(&Message{Role: "user", Content: "Spec"})
+	s.AddMessage(&Message{Role: "assistant", Content: "Ok"})
+	if err := s.PinMessage(0); err != nil {
+		t.Fatalf("PinMessage failed: %v", err)
+	}
+
+	if err := storage.SaveSession(s, "Pinned Session"); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	loaded, err := storage.LoadSession(tempDir, s.ID)
+	if err != nil {
+		t.Fatalf("Failed to load saved session: %v", err)
+	}
+	if !loaded.Messages[0].Pinned || loaded.Messages[1].Pinned {
+		t.Errorf("expected only the first message to be pinned after reload")
+	}
+}
+
+func TestFilterSessions(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []SessionMetadata{
+		{ID: "a", Title: "Refactor parser", UpdatedAt: base},
+		{ID: "b", Title: "Fix login bug", UpdatedAt: base.Add(time.Hour)},
+		{ID: "c", Title: "parser tests", UpdatedAt: base.Add(2 * time.Hour)},
+		{ID: "d", Title: "Docs", UpdatedAt: base.Add(2 * time.Hour)},
+		{ID: "e", Title: "Release notes", UpdatedAt: base.Add(3 * time.Hour)},
+	}
+
+	ids := func(page []SessionMetadata) []string {
+		out := make([]string, len(page))
+		for i, meta := range page {
+			out[i] = meta.ID
+		}
+		return out
+	}
+
+	page, total := FilterSessions(sessions, ListOptions{TitleContains: "PARSER"})
+	if total != 2 || len(page) != 2 || page[0].ID != "c" || page[1].ID != "a" {
+		t.Errorf("title filter returned %v (total %d), want [c a]", ids(page), total)
+	}
+
+	page, total = FilterSessions(sessions, ListOptions{UpdatedAfter: base.Add(time.Hour), UpdatedBefore: base.Add(3 * time.Hour)})
+	if total != 3 || len(page) != 3 || page[0].ID != "c" || page[1].ID != "d" || page[2].ID != "b" {
+		t.Errorf("date range returned %v (total %d), want [c d b]", ids(page), total)
+	}
+
+	seen := make(map[string]bool)
+	for offset := 0; offset < len(sessions); offset += 2 {
+		page, total := FilterSessions(sessions, ListOptions{Limit: 2, Offset: offset})
+		if total != len(sessions) {
+			t.Errorf("expected total %d, got %d", len(sessions), total)
+		}
+		for _, meta := range page {
+			if seen[meta.ID] {
+				t.Errorf("session %s returned on more than one page", meta.ID)
+			}
+			seen[meta.ID] = true
+		}
+	}
+	if len(seen) != len(sessions) {
+		t.Errorf("pages covered %d sessions, want %d", len(seen), len(sessions))
+	}
+
+	if page, total := FilterSessions(sessions, ListOptions{Offset: 10}); len(page) != 0 || total != len(sessions) {
+		t.Errorf("offset past the end returned %v (total %d)", ids(page), total)
+	}
+}
+
+func TestSaveSessionKeepsReadOnlyMode(t *testing.T) {
+	tempDir := t.TempDir()
+	setSessionStorageEnv(t, tempDir)
+
+	storage, err := NewSessionStorage()
+	if err != nil {
+		t.Fatalf("Failed to create session storage: %v", err)
+	}
+
+	s := NewSession("test-read-only", tempDir)
+	s.AddMessage(&Message{Role: "user", Content: "Review this"})
+	s.SetReadOnly(true)
+
+	if err := storage.SaveSession(s, "Review"); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	loaded, err := storage.LoadSession(tempDir, s.ID)
+	if err != nil {
+		t.Fatalf("Failed to load saved session: %v", err)
+	}
+	if !loaded.IsReadOnly() {
+		t.Error("expected the session to stay read-only after reload")
+	}
+}
+
+func TestAddMessageTimestampsNonDecreasing(t *testing.T) {
+	s := NewSession("test", ".")
+	for i := 0; i < 50; i++ {
+		s.AddMessage(&Message{Role: "user", Content: "message"})
+	}
+
+	// A clock going backwards must not reorder messages
+	future := time.Now().Add(time.Hour)
+	s.Messages[len(s.Messages)-1].Timestamp = future
+	s.AddMessage(&Message{Role: "assistant", Content: "after clock skew"})
+
+	messages := s.GetMessages()
+	for i, msg := range messages {
+		if msg.Timestamp.IsZero() {
+			t.Fatalf("message %d has no timestamp", i)
+		}
+		if i > 0 && msg.Timestamp.Before(messages[i-1].Timestamp) {
+			t.Fatalf("message %d is older than its predecessor: %v < %v", i, msg.Timestamp, messages[i-1].Timestamp)
+		}
+	}
+	if last := messages[len(messages)-1]; !last.Timestamp.Equal(future) {
+		t.Errorf("expected the skewed message to take its predecessor's timestamp, got %v", last.Timestamp)
+	}
+}
+
+func TestCompactWithSummaryKeepsRepresentativeTimestamp(t *testing.T) {
+	s := NewSession("test", ".")
+	s.AddMessage(&Message{Role: "user", Content: "one"})
+	s.AddMessage(&Message{Role: "assistant", Content: "two"})
+	s.AddMessage(&Message{Role: "user", Content: "three"})
+
+	head := s.GetMessages()
+	oldest := head[0].Timestamp
+	time.Sleep(time.Millisecond)
+	if !s.CompactWithSummary(head[:2], "summary") {
+		t.Fatal("expected compaction to succeed")
+	}
+
+	messages := s.GetMessages()
+	if !messages[0].Timestamp.Equal(oldest) {
+		t.Errorf("expected the summary to carry the oldest compacted timestamp %v, got %v", oldest, messages[0].Timestamp)
+	}
+	if messages[1].Timestamp.Before(messages[0].Timestamp) {
+		t.Errorf("expected messages to stay ordered after compaction")
+	}
+}
+
+func TestListSessionsReportsLastMessageTime(t *testing.T) {
+	tempDir := t.TempDir()
+	setSessionStorageEnv(t, tempDir)
+
+	storage, err := NewSessionStorage()
+	if err != nil {
+		t.Fatalf("Failed to create session storage: %v", err)
+	}
+
+	s := NewSession("test-last-message", tempDir)
+	s.AddMessage(&Message{Role: "user", Content: "Hello"})
+	s.AddMessage(&Message{Role: "assistant", Content: "Hi"})
+	if err := storage.SaveSession(s, ""); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	sessions, err := storage.ListSessions(tempDir)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected one session, got %v (err %v)", sessions, err)
+	}
+	if want := s.GetMessages()[1].Timestamp; !sessions[0].LastMessageAt.Equal(want) {
+		t.Errorf("expected last message time %v, got %v", want, sessions[0].LastMessageAt)
+	}
+}
+
+func TestTrackFileReadNormalizesPaths(t *testing.T) {
+	workingDir := t.TempDir()
+	s := NewSession("test", workingDir)
+
+	s.TrackFileRead("./pkg/../main.go", "package main")
+	for _, path := range []string{"main.go", "./main.go", filepath.Join(workingDir, "main.go")} {
+		if !s.WasFileRead(path) {
+			t.Errorf("expected %q to count as read", path)
+		}
+	}
+	if s.WasFileRead("other.go") {
+		t.Error("expected an unread file not to count as read")
+	}
+
+	outside := filepath.Join(filepath.Dir(workingDir), "outside.go")
+	s.TrackFileModified(outside)
+	s.TrackFileModified(filepath.Join(workingDir, "main.go"))
+	modified := s.GetModifiedFiles()
+	sort.Strings(modified)
+	if len(modified) != 2 || modified[0] != outside || modified[1] != "main.go" {
+		t.Errorf("expected paths inside the working directory to be relative, got %v", modified)
+	}
+}
+
+func TestFromStoredSessionNormalizesTrackedPaths(t *testing.T) {
+	workingDir := t.TempDir()
+	storage := &SessionStorage{}
+	s := storage.FromStoredSession(&StoredSession{
+		ID:            "stored",
+		WorkingDir:    workingDir,
+		FilesRead:     map[string]string{"./main.go": "package main", filepath.Join(workingDir, "pkg", "util.go"): "package pkg"},
+		FilesModified: map[string]bool{filepath.Join(workingDir, "main.go"): true},
+	})
+
+	for _, path := range []string{"main.go", filepath.Join("pkg", "util.go")} {
+		if !s.WasFileRead(path) {
+			t.Errorf("expected %q stored in the old format to count as read", path)
+		}
+	}
+	if modified := s.GetModifiedFiles(); len(modified) != 1 || modified[0] != "main.go" {
+		t.Errorf("expected the modified path to be normalized, got %v", modified)
+	}
+}
+
+func TestAccumulateUsageCountsAlternativeFieldsOnce(t *testing.T) {
+	s := NewSession("test", ".")
+
+	// Both spellings of the same call, as some providers report them
+	s.AccumulateUsage(map[string]interface{}{"prompt_tokens": 100.0, "input_tokens": 100.0, "completion_tokens": 20.0, "output_tokens": 20.0, "cost": 0.5, "total_cost": 0.5})
+	// Only the alternative names and no total
+	s.AccumulateUsage(map[string]interface{}{"input_tokens": 30, "output_tokens": 5})
+
+	if s.TotalPromptTokens != 130 || s.TotalCompletionTokens != 25 {
+		t.Errorf("expected 130 prompt and 25 completion tokens, got %d and %d", s.TotalPromptTokens, s.TotalCompletionTokens)
+	}
+	if s.GetTotalTokens() != 155 {
+		t.Errorf("expected 155 total tokens, got %d", s.GetTotalTokens())
+	}
+	if s.GetTotalCost() != 0.5 {
+		t.Errorf("expected a cost of 0.5, got %v", s.GetTotalCost())
+	}
+}
+
+func TestSaveSessionKeepsUsageHistory(t *testing.T) {
+	tempDir := t.TempDir()
+	setSessionStorageEnv(t, tempDir)
+
+	storage, err := NewSessionStorage()
+	if err != nil {
+		t.Fatalf("Failed to create session storage: %v", err)
+	}
+
+	s := NewSession("test-usage", tempDir)
+	s.AddMessage(&Message{Role: "user", Content: "Hello"})
+	s.RecordPromptUsage(UsageRecord{PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200, Cost: 0.01})
+	s.RecordPromptUsage(UsageRecord{})
+	s.RecordPromptUsage(UsageRecord{PromptTokens: 3000, CompletionTokens: 500, TotalTokens: 3500, Cost: 0.03})
+
+	history := s.GetUsageHistory()
+	if len(history) != 2 {
+		t.Fatalf("expected prompts without usage to be skipped, got %+v", history)
+	}
+	if history[0].Timestamp.IsZero() {
+		t.Error("expected records to be timestamped")
+	}
+
+	if err := storage.SaveSession(s, ""); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	loaded, err := storage.LoadSession(tempDir, s.ID)
+	if err != nil {
+		t.Fatalf("Failed to load saved session: %v", err)
+	}
+	if len(loaded.GetUsageHistory()) != 2 {
+		t.Fatalf("expected the usage history to survive reload, got %+v", loaded.GetUsageHistory())
+	}
+	if loaded.GetTotalTokens() != 4700 || loaded.TotalPromptTokens != 4000 || loaded.TotalCompletionTokens != 700 {
+		t.Errorf("expected the counters to be restored from the history, got %+v", loaded.GetUsageStats())
+	}
+
+	// Further prompts add to the restored totals
+	loaded.RecordPromptUsage(UsageRecord{TotalTokens: 300})
+	if total := SumUsage(loaded.GetUsageHistory()); total.TotalTokens != 5000 {
+		t.Errorf("expected 5000 tokens over three prompts, got %d", total.TotalTokens)
+	}
+
+	sessions, err := storage.ListSessions(tempDir)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected one session, got %v (err %v)", sessions, err)
+	}
+	if sessions[0].TotalTokens != 4700 || sessions[0].TotalCost < 0.0399 || sessions[0].TotalCost > 0.0401 {
+		t.Errorf("expected the listing to report the saved totals, got %d tokens and $%v", sessions[0].TotalTokens, sessions[0].TotalCost)
+	}
+}
+
+func TestStoredSessionDropsSessionDomainApprovals(t *testing.T) {
+	sess := NewSession("domains", "/tmp")
+	sess.AuthorizeDomain("api.example.com")
+	sess.AuthorizeCommand("go test")
+
+	storage := &SessionStorage{}
+	restored := storage.FromStoredSession(storage.ToStoredSession(sess, ""))
+
+	if restored.IsDomainAuthorized("api.example.com") {
+		t.Error("domains approved for the session must not survive a save")
+	}
+	if !restored.IsCommandAuthorized("go test") {
+		t.Error("expected authorized commands to be restored")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -42,6 +43,7 @@ type StoredMessage struct {
 	ToolID            string
 	ToolName          string
 	Timestamp         time.Time
+	Pinned            bool
 	NativeFormat      interface{}
 	NativeProvider    string
 	NativeModelFamily string
@@ -75,15 +77,17 @@ type StoredSession struct {
 	FilesRead           map[string]string
 	FilesModified       map[string]bool
 	BackgroundJobs      map[string]*StoredBackgroundJob
-	AuthorizedDomains   map[string]bool
-	AuthorizedCommands  []string
+	AuthorizedCommands  []string // Domains approved for the session are not stored; they end with the process
 	PlanningActive      bool
 	PlanningObjective   string
+	ReadOnly            bool
 	LastSandboxExitCode int
 	LastSandboxStdout   string
 	LastSandboxStderr   string
 	CurrentProvider     string
 	CurrentModelFamily  string
+	SaveSummary         *SessionSummary `json:"save_summary,omitempty"`
+	UsageHistory        []UsageRecord   `json:"usage_history,omitempty"`
 
 	// Verification retry tracking
 	VerificationAttempt    int  `json:"verification_attempt,omitempty"`
@@ -104,6 +108,11 @@ type SessionMetadata struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	MessageCount int       `json:"message_count"`
+	// LastMessageAt is the timestamp of the newest message (zero if there are none)
+	LastMessageAt time.Time `json:"last_message_at,omitempty"`
+	// TotalTokens and TotalCost sum the usage of all recorded prompts
+	TotalTokens int     `json:"total_tokens,omitempty"`
+	TotalCost   float64 `json:"total_cost,omitempty"`
 }
 
 // SessionStorage manages session persistence
@@ -120,6 +129,32 @@ type SessionStorage struct {
 	activeSaves    int
 	maxSaves       int
 	configFunc     func() *config.AutoSaveConfig
+
+	// Session tracked by the running auto-save loop, flushed once more on stop
+	autoSaveSession *Session
+	autoSaveName    string
+
+	// newTicker creates the auto-save ticker; tests replace it with a fake clock
+	newTicker func(time.Duration) autoSaveTicker
+}
+
+// defaultAutoSaveInterval is used when the configured interval is not positive.
+const defaultAutoSaveInterval = 5 * time.Second
+
+// autoSaveTicker abstracts time.Ticker so the auto-save loop can be driven manually in tests.
+type autoSaveTicker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type timeTicker struct {
+	*time.Ticker
+}
+
+func (t timeTicker) C() <-chan time.Time { return t.Ticker.C }
+
+func newTimeTicker(d time.Duration) autoSaveTicker {
+	return timeTicker{time.NewTicker(d)}
 }
 
 // NewSessionStorage creates a new SessionStorage instance
@@ -345,6 +380,15 @@ func (s *SessionStorage) SaveSession(session *Session, name string) error {
 
 // LoadSession loads a session from disk
 func (s *SessionStorage) LoadSession(workingDir, sessionID string) (*Session, error) {
+	stored, err := s.loadStoredSession(workingDir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.FromStoredSession(stored), nil
+}
+
+// loadStoredSession decodes a saved session without converting it
+func (s *SessionStorage) loadStoredSession(workingDir, sessionID string) (*StoredSession, error) {
 	path := s.getSessionPath(workingDir, sessionID)
 
 	file, err := os.Open(path)
@@ -366,7 +410,119 @@ func (s *SessionStorage) LoadSession(workingDir, sessionID string) (*Session, er
 		return nil, fmt.Errorf("session version mismatch: expected %d, got %d", SessionStorageVersion, stored.Version)
 	}
 
-	return s.FromStoredSession(&stored), nil
+	return &stored, nil
+}
+
+// writeStoredSession atomically replaces the file of a stored session in
+// the storage of workingDir
+func (s *SessionStorage) writeStoredSession(workingDir string, stored *StoredSession) error {
+	workspaceDir := s.getWorkspaceDir(workingDir)
+	if err := os.MkdirAll(workspaceDir, 0755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(workspaceDir, stored.ID+".gob.tmp.*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tempPath := file.Name()
+
+	if err := gob.NewEncoder(file).Encode(stored); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Rename(tempPath, s.getSessionPath(workingDir, stored.ID)); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
+
+// RenameSession changes the title of a saved session. The ID is unchanged.
+// If the session is autosaved by this storage, later autosaves use the new
+// title as name.
+func (s *SessionStorage) RenameSession(workingDir, sessionID, title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+
+	s.mu.Lock()
+	if s.autoSaveSession != nil && s.autoSaveSession.ID == sessionID {
+		s.autoSaveName = title
+	}
+	s.mu.Unlock()
+
+	stored, err := s.loadStoredSession(workingDir, sessionID)
+	if err != nil {
+		return err
+	}
+
+	stored.Title = title
+	stored.Name = title
+	return s.writeStoredSession(workingDir, stored)
+}
+
+// DuplicateSession copies a saved session with all its messages to a fresh
+// ID and returns the copy. Without a title the copy is named after the
+// original.
+func (s *SessionStorage) DuplicateSession(workingDir, sessionID, title string) (*SessionMetadata, error) {
+	stored, err := s.loadStoredSession(workingDir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	newID := GenerateID()
+	for attempt := 0; s.sessionExists(workingDir, newID); attempt++ {
+		if attempt >= 10 {
+			return nil, fmt.Errorf("failed to generate a unique session ID")
+		}
+		newID = GenerateID()
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		original := stored.Title
+		if original == "" {
+			original = stored.Name
+		}
+		if original == "" {
+			original = sessionID
+		}
+		title = original + " (copy)"
+	}
+
+	now := time.Now()
+	stored.ID = newID
+	stored.Title = title
+	stored.Name = title
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	stored.LastSavedAt = now
+	if err := s.writeStoredSession(workingDir, stored); err != nil {
+		return nil, err
+	}
+
+	return &SessionMetadata{
+		ID:           stored.ID,
+		Name:         stored.Name,
+		Title:        stored.Title,
+		WorkingDir:   stored.WorkingDir,
+		CreatedAt:    stored.CreatedAt,
+		UpdatedAt:    stored.UpdatedAt,
+		MessageCount: len(stored.Messages),
+	}, nil
+}
+
+// sessionExists reports whether a session file exists for the ID
+func (s *SessionStorage) sessionExists(workingDir, sessionID string) bool {
+	_, err := os.Stat(s.getSessionPath(workingDir, sessionID))
+	return err == nil
 }
 
 // ListSessions returns metadata for all sessions in a workspace
@@ -410,20 +566,98 @@ func (s *SessionStorage) ListSessions(workingDir string) ([]SessionMetadata, err
 			continue // Skip incompatible versions
 		}
 
+		usage := SumUsage(stored.UsageHistory)
 		sessions = append(sessions, SessionMetadata{
-			ID:           stored.ID,
-			Name:         stored.Name,
-			Title:        stored.Title,
-			WorkingDir:   stored.WorkingDir,
-			CreatedAt:    stored.CreatedAt,
-			UpdatedAt:    stored.UpdatedAt,
-			MessageCount: len(stored.Messages),
+			ID:            stored.ID,
+			Name:          stored.Name,
+			Title:         stored.Title,
+			WorkingDir:    stored.WorkingDir,
+			CreatedAt:     stored.CreatedAt,
+			UpdatedAt:     stored.UpdatedAt,
+			MessageCount:  len(stored.Messages),
+			LastMessageAt: lastStoredMessageTime(stored.Messages),
+			TotalTokens:   usage.TotalTokens,
+			TotalCost:     usage.Cost,
 		})
 	}
 
 	return sessions, nil
 }
 
+// lastStoredMessageTime returns the timestamp of the newest stored message
+func lastStoredMessageTime(messages []*StoredMessage) time.Time {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i] != nil && !messages[i].Timestamp.IsZero() {
+			return messages[i].Timestamp
+		}
+	}
+	return time.Time{}
+}
+
+// ListOptions narrows and pages the result of ListSessionsWithOptions. Zero
+// values disable the corresponding filter.
+type ListOptions struct {
+	TitleContains string    // case-insensitive substring of the title
+	UpdatedAfter  time.Time // only sessions updated at or after this time
+	UpdatedBefore time.Time // only sessions updated before this time
+	Limit         int       // maximum number of sessions to return (0 = all)
+	Offset        int       // number of matching sessions to skip
+}
+
+// ListSessionsWithOptions lists the sessions of a workspace that match opts,
+// most recently updated first. It returns the requested page and the total
+// number of matching sessions.
+func (s *SessionStorage) ListSessionsWithOptions(workingDir string, opts ListOptions) ([]SessionMetadata, int, error) {
+	sessions, err := s.ListSessions(workingDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := FilterSessions(sessions, opts)
+	return page, total, nil
+}
+
+// FilterSessions applies opts to sessions. Matching sessions are ordered by
+// UpdatedAt (newest first) and then by ID, so pages are stable and do not
+// overlap as long as the underlying sessions do not change.
+func FilterSessions(sessions []SessionMetadata, opts ListOptions) ([]SessionMetadata, int) {
+	title := strings.ToLower(strings.TrimSpace(opts.TitleContains))
+
+	matched := make([]SessionMetadata, 0, len(sessions))
+	for _, meta := range sessions {
+		if title != "" && !strings.Contains(strings.ToLower(meta.Title), title) {
+			continue
+		}
+		if !opts.UpdatedAfter.IsZero() && meta.UpdatedAt.Before(opts.UpdatedAfter) {
+			continue
+		}
+		if !opts.UpdatedBefore.IsZero() && !meta.UpdatedAt.Before(opts.UpdatedBefore) {
+			continue
+		}
+		matched = append(matched, meta)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if !matched[i].UpdatedAt.Equal(matched[j].UpdatedAt) {
+			return matched[i].UpdatedAt.After(matched[j].UpdatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	total := len(matched)
+	offset := opts.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if opts.Limit > 0 && offset+opts.Limit < end {
+		end = offset + opts.Limit
+	}
+	return matched[offset:end], total
+}
+
 // GetMostRecentSession returns the most recently updated session in a workspace
 func (s *SessionStorage) GetMostRecentSession(workingDir string) (*Session, error) {
 	sessions, err := s.ListSessions(workingDir)
@@ -471,6 +705,7 @@ func (s *SessionStorage) ToStoredSession(session *Session, name string) *StoredS
 			ToolID:    msg.ToolID,
 			ToolName:  msg.ToolName,
 			Timestamp: msg.Timestamp,
+			Pinned:    msg.Pinned,
 			// Persist only unified message data to avoid gob-encoding provider-native types.
 			NativeFormat:      nil,
 			NativeProvider:    "",
@@ -510,15 +745,17 @@ func (s *SessionStorage) ToStoredSession(session *Session, name string) *StoredS
 		FilesRead:              session.FilesRead,
 		FilesModified:          session.FilesModified,
 		BackgroundJobs:         storedJobs,
-		AuthorizedDomains:      session.AuthorizedDomains,
 		AuthorizedCommands:     session.AuthorizedCommands,
 		PlanningActive:         session.PlanningActive,
 		PlanningObjective:      session.PlanningObjective,
+		ReadOnly:               session.ReadOnly,
 		LastSandboxExitCode:    session.LastSandboxExitCode,
 		LastSandboxStdout:      session.LastSandboxStdout,
 		LastSandboxStderr:      session.LastSandboxStderr,
 		CurrentProvider:        session.CurrentProvider,
 		CurrentModelFamily:     session.CurrentModelFamily,
+		SaveSummary:            session.SaveSummary,
+		UsageHistory:           append([]UsageRecord(nil), session.UsageHistory...),
 		VerificationAttempt:    session.VerificationAttempt,
 		VerificationInProgress: session.VerificationInProgress,
 		LastUserMessageCount:   session.LastUserMessageCount,
@@ -542,6 +779,7 @@ func (s *SessionStorage) FromStoredSession(stored *StoredSession) *Session {
 			ToolID:            storedMsg.ToolID,
 			ToolName:          storedMsg.ToolName,
 			Timestamp:         storedMsg.Timestamp,
+			Pinned:            storedMsg.Pinned,
 			NativeFormat:      storedMsg.NativeFormat,
 			NativeProvider:    storedMsg.NativeProvider,
 			NativeModelFamily: storedMsg.NativeModelFamily,
@@ -551,17 +789,32 @@ func (s *SessionStorage) FromStoredSession(stored *StoredSession) *Session {
 
 	// Copy other fields (they're shallow copies, which is fine)
 	session.Title = stored.Title
-	session.FilesRead = stored.FilesRead
-	session.FilesModified = stored.FilesModified
-	session.AuthorizedDomains = stored.AuthorizedDomains
+	// Older sessions stored the paths as the tools passed them
+	for path, content := range stored.FilesRead {
+		session.FilesRead[session.trackedPath(path)] = content
+	}
+	for path, modified := range stored.FilesModified {
+		session.FilesModified[session.trackedPath(path)] = modified
+	}
 	session.AuthorizedCommands = stored.AuthorizedCommands
 	session.PlanningActive = stored.PlanningActive
 	session.PlanningObjective = stored.PlanningObjective
+	session.ReadOnly = stored.ReadOnly
 	session.LastSandboxExitCode = stored.LastSandboxExitCode
 	session.LastSandboxStdout = stored.LastSandboxStdout
 	session.LastSandboxStderr = stored.LastSandboxStderr
 	session.CurrentProvider = stored.CurrentProvider
 	session.CurrentModelFamily = stored.CurrentModelFamily
+	session.SaveSummary = stored.SaveSummary
+	session.UsageHistory = stored.UsageHistory
+
+	// Usage counters are not stored; restore them from the history so the
+	// session totals cover the whole conversation
+	usage := SumUsage(stored.UsageHistory)
+	session.TotalPromptTokens = usage.PromptTokens
+	session.TotalCompletionTokens = usage.CompletionTokens
+	session.TotalTokens = usage.TotalTokens
+	session.TotalCost = usage.Cost
 	session.VerificationAttempt = stored.VerificationAttempt
 	session.VerificationInProgress = stored.VerificationInProgress
 	session.LastUserMessageCount = stored.LastUserMessageCount
@@ -606,19 +859,30 @@ func (s *SessionStorage) StartAutoSave(session *Session, name string) {
 	}
 
 	s.autoSaveActive = true
+	s.autoSaveSession = session
+	s.autoSaveName = name
 	s.wg.Add(1)
 
+	interval := s.saveInterval
+	if interval <= 0 {
+		interval = defaultAutoSaveInterval
+	}
+	newTicker := s.newTicker
+	if newTicker == nil {
+		newTicker = newTimeTicker
+	}
+	ticker := newTicker(interval)
+
 	stopChan := s.stopChan
 	go func() {
 		defer s.wg.Done()
-		ticker := time.NewTicker(s.saveInterval)
 		defer ticker.Stop()
 
 		logger.Info("Auto-save started for session %s", session.ID)
 
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
 				s.mu.RLock()
 				hasAvailableSlots := s.activeSaves < s.maxSaves
 				s.mu.RUnlock()
@@ -629,10 +893,15 @@ func (s *SessionStorage) StartAutoSave(session *Session, name string) {
 						defer s.wg.Done()
 						s.mu.Lock()
 						s.activeSaves++
+						// RenameSession may have changed the name since
+						saveName := name
+						if s.autoSaveSession == session {
+							saveName = s.autoSaveName
+						}
 						s.mu.Unlock()
 
 						logger.Debug("Auto-saving session %s", session.ID)
-						err := s.SaveSession(session, name)
+						err := s.SaveSession(session, saveName)
 						if err != nil {
 							logger.Error("Auto-save failed for session %s: %v", session.ID, err)
 						}
@@ -651,7 +920,9 @@ func (s *SessionStorage) StartAutoSave(session *Session, name string) {
 	}()
 }
 
-// StopAutoSave stops the automatic session saving process
+// StopAutoSave stops the automatic session saving process and flushes a final
+// save of the tracked session. The flush is skipped if nothing changed since
+// the last save.
 func (s *SessionStorage) StopAutoSave() {
 	s.mu.Lock()
 
@@ -664,6 +935,9 @@ func (s *SessionStorage) StopAutoSave() {
 	// Prepare a new stop channel so autosave can be restarted after stopping
 	s.stopChan = make(chan struct{})
 	s.autoSaveActive = false
+	session, name := s.autoSaveSession, s.autoSaveName
+	s.autoSaveSession = nil
+	s.autoSaveName = ""
 	s.mu.Unlock()
 
 	// Close outside the lock so in-flight saves can finish and decrement counters
@@ -671,6 +945,12 @@ func (s *SessionStorage) StopAutoSave() {
 		close(stopChan)
 	}
 	s.wg.Wait()
+
+	if session != nil {
+		if err := s.SaveSession(session, name); err != nil {
+			logger.Error("Final auto-save failed for session %s: %v", session.ID, err)
+		}
+	}
 }
 
 // RefreshAutoSaveConfig updates the auto-save configuration
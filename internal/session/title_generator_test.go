@@ -2,7 +2,9 @@ package session
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/codefionn/scriptschnell/internal/llm"
 )
@@ -157,3 +159,45 @@ func TestTitleGeneratorWithNilClient(t *testing.T) {
 		t.Errorf("expected fallback title 'Test prompt', got %q", title)
 	}
 }
+
+func TestGenerateSessionSummary(t *testing.T) {
+	sess := NewSession("test", ".")
+	sess.AddMessage(&Message{Role: "user", Content: "please fix the login bug"})
+	sess.AddMessage(&Message{Role: "assistant", Content: "Fixed the nil check in auth.go"})
+	sess.TrackFileRead("README.md", "")
+	sess.TrackFileModified("internal/auth.go")
+
+	generator := NewTitleGenerator(&MockLLMClient{
+		response: `{"title": "Fix login bug", "description": "Fixed a nil check in the auth handler."}`,
+	})
+	summary := generator.GenerateSummary(context.Background(), sess)
+	if !summary.Generated || summary.Title != "Fix login bug" || summary.Description == "" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(summary.KeyFiles) != 1 || summary.KeyFiles[0] != "internal/auth.go" {
+		t.Errorf("expected modified file as key file, got %v", summary.KeyFiles)
+	}
+
+	// Summarization failures fall back to the first prompt
+	fallback := NewTitleGenerator(&MockLLMClient{response: "not json"}).GenerateSummary(context.Background(), sess)
+	if fallback.Generated || fallback.Title != "Fix the login bug" {
+		t.Fatalf("expected first-prompt fallback, got %+v", fallback)
+	}
+}
+
+func TestGenerateSessionSummaryTruncatesTitleByRunes(t *testing.T) {
+	sess := NewSession("test", ".")
+	sess.AddMessage(&Message{Role: "user", Content: "übersetze die Oberfläche"})
+
+	longTitle := strings.Repeat("ä", 100)
+	generator := NewTitleGenerator(&MockLLMClient{
+		response: `{"title": "` + longTitle + `", "description": "Translated the UI."}`,
+	})
+	summary := generator.GenerateSummary(context.Background(), sess)
+	if !utf8.ValidString(summary.Title) {
+		t.Fatalf("title is not valid UTF-8: %q", summary.Title)
+	}
+	if want := strings.Repeat("ä", 77) + "..."; summary.Title != want {
+		t.Errorf("expected title truncated to 77 runes, got %q", summary.Title)
+	}
+}
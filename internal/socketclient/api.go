@@ -3,7 +3,12 @@ package socketclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -73,6 +78,32 @@ func (c *Client) AttachSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// TakeoverSession attaches to a session that is still attached to another
+// connection of this client (e.g. a stale one), which the server then drops
+func (c *Client) TakeoverSession(ctx context.Context, sessionID string) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if sessionID == "" {
+		return NewSocketError("INVALID_REQUEST", "Session ID is required", "")
+	}
+
+	msg := NewMessage("session_takeover", map[string]interface{}{
+		"session_id": sessionID,
+	})
+
+	_, err := c.SendRequest(msg)
+	if err != nil {
+		return err
+	}
+
+	// Update current session tracking
+	c.currentSessionID.Store(sessionID)
+
+	return nil
+}
+
 // DetachSession detaches from the current session
 func (c *Client) DetachSession(ctx context.Context) error {
 	if !c.IsConnected() {
@@ -119,6 +150,66 @@ func (c *Client) ListSessions(ctx context.Context, workspace string) ([]SessionI
 	return result.Sessions, nil
 }
 
+// SessionListOptions filters and pages the result of ListSessionsWithOptions.
+// Zero values disable the corresponding filter.
+type SessionListOptions struct {
+	Workspace string    // workspace to list (default: the client's workspace)
+	Title     string    // case-insensitive title substring
+	Since     time.Time // only sessions updated at or after this time
+	Until     time.Time // only sessions updated before this time
+	Limit     int       // page size (0 = all)
+	Offset    int       // number of matching sessions to skip
+}
+
+// SessionListPage is one page of a filtered session listing
+type SessionListPage struct {
+	Sessions []SessionInfo `json:"sessions"`
+	Total    int           `json:"total"`
+	Offset   int           `json:"offset"`
+	Limit    int           `json:"limit"`
+}
+
+// ListSessionsWithOptions lists the sessions matching opts, most recently
+// updated first. Total counts all matching sessions, for paging in the UI.
+func (c *Client) ListSessionsWithOptions(ctx context.Context, opts SessionListOptions) (*SessionListPage, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	data := map[string]interface{}{}
+	if opts.Workspace != "" {
+		data["workspace"] = opts.Workspace
+	}
+	if opts.Title != "" {
+		data["title"] = opts.Title
+	}
+	if !opts.Since.IsZero() {
+		data["since"] = opts.Since.Format(time.RFC3339)
+	}
+	if !opts.Until.IsZero() {
+		data["until"] = opts.Until.Format(time.RFC3339)
+	}
+	if opts.Limit > 0 {
+		data["limit"] = opts.Limit
+	}
+	if opts.Offset > 0 {
+		data["offset"] = opts.Offset
+	}
+
+	msg := NewMessage("session_list", data)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var page SessionListPage
+	if err := json.Unmarshal(resp.Data, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &page, nil
+}
+
 // DeleteSession deletes a session
 func (c *Client) DeleteSession(ctx context.Context, sessionID, workspace string) error {
 	if !c.IsConnected() {
@@ -151,6 +242,73 @@ func (c *Client) DeleteSession(ctx context.Context, sessionID, workspace string)
 	return nil
 }
 
+// RenameSession changes the title of a saved session. The session ID stays
+// the same.
+func (c *Client) RenameSession(ctx context.Context, sessionID, workspace, title string) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if sessionID == "" {
+		return NewSocketError("INVALID_REQUEST", "Session ID is required", "")
+	}
+	if title == "" {
+		return NewSocketError("INVALID_REQUEST", "Title is required", "")
+	}
+
+	data := map[string]interface{}{
+		"session_id": sessionID,
+		"title":      title,
+	}
+
+	if workspace != "" {
+		data["workspace"] = workspace
+	}
+
+	msg := NewMessage("session_rename", data)
+	_, err := c.SendRequest(msg)
+	return err
+}
+
+// DuplicateSession copies a saved session with all its messages to a new
+// session ID and returns the new ID. An empty title names the copy after
+// the original.
+func (c *Client) DuplicateSession(ctx context.Context, sessionID, workspace, title string) (string, error) {
+	if !c.IsConnected() {
+		return "", NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if sessionID == "" {
+		return "", NewSocketError("INVALID_REQUEST", "Session ID is required", "")
+	}
+
+	data := map[string]interface{}{
+		"session_id": sessionID,
+	}
+
+	if workspace != "" {
+		data["workspace"] = workspace
+	}
+	if title != "" {
+		data["title"] = title
+	}
+
+	msg := NewMessage("session_duplicate", data)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.SessionID, nil
+}
+
 // SaveSession saves the current session
 func (c *Client) SaveSession(ctx context.Context, name string) error {
 	if !c.IsConnected() {
@@ -231,6 +389,63 @@ func (c *Client) SendChat(ctx context.Context, content string, options map[strin
 	return nil
 }
 
+// SendChatWithAttachments sends a chat message whose prompt is preceded by
+// the content of the attached files. The server reads the files, so they
+// must be inside the workspace or one of its context directories.
+func (c *Client) SendChatWithAttachments(ctx context.Context, content string, attachments []Attachment, options map[string]interface{}) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if content == "" {
+		return NewSocketError("INVALID_REQUEST", "Content is required", "")
+	}
+
+	data := map[string]interface{}{
+		"content": content,
+	}
+	if len(attachments) > 0 {
+		data["attachments"] = attachments
+	}
+	if options != nil {
+		data["options"] = options
+	}
+
+	msg := NewMessage("chat_send", data)
+	_, err := c.SendRequest(msg)
+	return err
+}
+
+// SendChatWithModel sends a chat message generated with another model than
+// the session's. The session keeps its model for later messages. A model or
+// provider the server does not know fails with ErrUnknownModel.
+func (c *Client) SendChatWithModel(ctx context.Context, content string, override ModelOverride, options map[string]interface{}) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if content == "" {
+		return NewSocketError("INVALID_REQUEST", "Content is required", "")
+	}
+
+	data := map[string]interface{}{
+		"content": content,
+	}
+	if override.Model != "" {
+		data["model"] = override.Model
+	}
+	if override.Provider != "" {
+		data["provider"] = override.Provider
+	}
+	if options != nil {
+		data["options"] = options
+	}
+
+	msg := NewMessage("chat_send", data)
+	_, err := c.SendRequest(msg)
+	return err
+}
+
 // StopChat stops the current chat operation
 func (c *Client) StopChat(ctx context.Context) error {
 	if !c.IsConnected() {
@@ -246,6 +461,36 @@ func (c *Client) StopChat(ctx context.Context) error {
 	return nil
 }
 
+// PauseChat pauses the current generation before its next loop iteration
+func (c *Client) PauseChat(ctx context.Context) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	msg := NewMessage("pause", nil)
+	_, err := c.SendRequest(msg)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResumeChat resumes a paused generation
+func (c *Client) ResumeChat(ctx context.Context) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	msg := NewMessage("resume", nil)
+	_, err := c.SendRequest(msg)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ClearChat clears the current chat history
 func (c *Client) ClearChat(ctx context.Context) error {
 	if !c.IsConnected() {
@@ -261,6 +506,27 @@ func (c *Client) ClearChat(ctx context.Context) error {
 	return nil
 }
 
+// CompactChat summarizes the earlier context of the attached session right
+// away. It fails with a SESSION_BUSY error while a prompt is being processed.
+func (c *Client) CompactChat(ctx context.Context) (CompactionResult, error) {
+	if !c.IsConnected() {
+		return CompactionResult{}, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	msg := NewMessage("chat_compact", nil)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return CompactionResult{}, err
+	}
+
+	var result CompactionResult
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return CompactionResult{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, nil
+}
+
 // ListWorkspaces lists all workspaces
 func (c *Client) ListWorkspaces(ctx context.Context) ([]WorkspaceInfo, error) {
 	if !c.IsConnected() {
@@ -309,6 +575,149 @@ func (c *Client) SetWorkspace(ctx context.Context, workspace string) error {
 	return nil
 }
 
+// WorkspaceAudit returns the entries of a workspace audit log, oldest first
+func (c *Client) WorkspaceAudit(ctx context.Context, query AuditQuery) ([]AuditEntry, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	data := map[string]interface{}{}
+	if query.Workspace != "" {
+		data["workspace"] = query.Workspace
+	}
+	if !query.Since.IsZero() {
+		data["since"] = query.Since.UTC().Format(time.RFC3339Nano)
+	}
+	if !query.Until.IsZero() {
+		data["until"] = query.Until.UTC().Format(time.RFC3339Nano)
+	}
+	if query.Limit > 0 {
+		data["limit"] = query.Limit
+	}
+
+	resp, err := c.SendRequest(NewMessage("workspace_audit", data))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Entries []AuditEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Entries, nil
+}
+
+// AddContextDir adds a directory to the active workspace's context directories.
+// The directory must exist and be readable; relative paths are resolved against
+// the current workspace.
+func (c *Client) AddContextDir(ctx context.Context, dir string) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	absDir, err := c.resolveContextDir(dir)
+	if err != nil {
+		return NewSocketError("INVALID_REQUEST", "Invalid context directory", err.Error())
+	}
+
+	msg := NewMessage("context_dir_add", map[string]interface{}{
+		"dir": absDir,
+	})
+
+	_, err = c.SendRequest(msg)
+	return err
+}
+
+// RemoveContextDir removes a directory from the active workspace's context directories
+func (c *Client) RemoveContextDir(ctx context.Context, dir string) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if strings.TrimSpace(dir) == "" {
+		return NewSocketError("INVALID_REQUEST", "Directory is required", "")
+	}
+
+	msg := NewMessage("context_dir_remove", map[string]interface{}{
+		"dir": dir,
+	})
+
+	_, err := c.SendRequest(msg)
+	return err
+}
+
+// ListContextDirs lists the active workspace's context directories
+func (c *Client) ListContextDirs(ctx context.Context) ([]string, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	msg := NewMessage("context_dir_list", nil)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Workspace   string   `json:"workspace"`
+		ContextDirs []string `json:"context_dirs"`
+	}
+
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.ContextDirs, nil
+}
+
+// resolveContextDir makes dir absolute and checks that it is a readable directory
+func (c *Client) resolveContextDir(dir string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", errors.New("directory is required")
+	}
+
+	dir = expandPath(dir)
+	if !filepath.IsAbs(dir) {
+		base := c.GetCurrentWorkspace()
+		if base == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve relative path: %w", err)
+			}
+			base = wd
+		}
+		dir = filepath.Join(base, dir)
+	}
+	dir = filepath.Clean(dir)
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("directory does not exist: %s", dir)
+		}
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return "", fmt.Errorf("directory is not readable: %w", err)
+	}
+	_, err = f.Readdirnames(1)
+	_ = f.Close()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("directory is not readable: %w", err)
+	}
+
+	return dir, nil
+}
+
 // GetConfig gets configuration values
 func (c *Client) GetConfig(ctx context.Context, keys []string) (map[string]ConfigValue, error) {
 	if !c.IsConnected() {
@@ -334,26 +743,184 @@ func (c *Client) GetConfig(ctx context.Context, keys []string) (map[string]Confi
 	return result, nil
 }
 
-// SetConfig sets configuration values
-func (c *Client) SetConfig(ctx context.Context, values map[string]interface{}) error {
+// GetConfigSnapshot gets configuration values together with the config
+// version needed by SetConfig
+func (c *Client) GetConfigSnapshot(ctx context.Context, keys []string) (*ConfigSnapshot, error) {
 	if !c.IsConnected() {
-		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	data := map[string]interface{}{}
+	if len(keys) > 0 {
+		data["keys"] = keys
+	}
+
+	msg := NewMessage("config_get", data)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot ConfigSnapshot
+	if err := json.Unmarshal(resp.Data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// SetConfig sets configuration values if the config is still at version,
+// as read with GetConfigSnapshot, and returns the new version. If another
+// client changed the config in the meantime, it fails with
+// ErrVersionConflict and nothing is changed. The values apply to the running
+// server; persist also saves them to the config file.
+func (c *Client) SetConfig(ctx context.Context, version uint64, values map[string]interface{}, persist bool) (uint64, error) {
+	if !c.IsConnected() {
+		return 0, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
 	}
 
 	if len(values) == 0 {
-		return NewSocketError("INVALID_REQUEST", "Values are required", "")
+		return 0, NewSocketError("INVALID_REQUEST", "Values are required", "")
 	}
 
-	msg := NewMessage("config_set", map[string]interface{}{
-		"values": values,
-	})
+	data := map[string]interface{}{
+		"values":  values,
+		"version": version,
+	}
+	if persist {
+		data["persist"] = true
+	}
+	msg := NewMessage("config_set", data)
 
-	_, err := c.SendRequest(msg)
+	resp, err := c.SendRequest(msg)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	var result struct {
+		Version uint64 `json:"version"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Version, nil
+}
+
+// ListModels lists the configured providers and their models with the
+// current model selections. A non-empty provider only lists its models.
+func (c *Client) ListModels(ctx context.Context, provider string) (*ModelsList, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if err := c.checkCapability("models_list"); err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+	if provider != "" {
+		data["provider"] = provider
+	}
+
+	msg := NewMessage("models_list", data)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ModelsList
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// SetModel selects a model and returns the applied selection with the
+// resolved model, provider, role and scope. A model or provider the server
+// does not know fails with ErrUnknownModel.
+func (c *Client) SetModel(ctx context.Context, selection ModelSelection) (*ModelSelection, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if err := c.checkCapability("model_set"); err != nil {
+		return nil, err
+	}
+
+	if selection.Model == "" && selection.Provider == "" {
+		return nil, NewSocketError("INVALID_REQUEST", "Model or provider is required", "")
+	}
+
+	msg := NewMessage("model_set", selection)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ModelSelection
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Batch sends several requests in a single round trip and returns their
+// responses in request order. Failures of individual requests are reported
+// in the corresponding Response, not as an error of the whole batch.
+func (c *Client) Batch(ctx context.Context, reqs ...Request) ([]Response, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+
+	if len(reqs) == 0 {
+		return nil, NewSocketError("INVALID_REQUEST", "At least one request is required", "")
+	}
+
+	entries := make([]*Message, 0, len(reqs))
+	for _, req := range reqs {
+		if req.Type == "" {
+			return nil, NewSocketError("INVALID_REQUEST", "Request type is required", "")
+		}
+		if err := c.checkCapability(req.Type); err != nil {
+			return nil, err
+		}
+		entries = append(entries, NewMessage(req.Type, req.Data))
+	}
+
+	msg := NewMessage("batch", map[string]interface{}{
+		"requests": entries,
+	})
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Responses []Message `json:"responses"`
+	}
+
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Responses) != len(entries) {
+		return nil, fmt.Errorf("batch returned %d responses for %d requests", len(result.Responses), len(entries))
+	}
+
+	responses := make([]Response, len(result.Responses))
+	for i, r := range result.Responses {
+		responses[i] = Response{
+			Type:      r.Type,
+			RequestID: r.RequestID,
+			Data:      r.Data,
+		}
+		if r.Error != nil {
+			responses[i].Error = NewSocketError(r.Error.Code, r.Error.Message, r.Error.Details)
+		}
+	}
+
+	return responses, nil
 }
 
 // SendAuthorizationResponse sends an authorization response
@@ -384,6 +951,11 @@ func (c *Client) SendAuthorizationAck(authID string) error {
 		return NewSocketError("INVALID_REQUEST", "Auth ID is required", "")
 	}
 
+	// The acknowledgment is advisory; skip it for servers not handling it
+	if !c.HasCapability("authorization_ack") {
+		return nil
+	}
+
 	msg := NewMessage("authorization_ack", map[string]interface{}{
 		"auth_id": authID,
 	})
@@ -508,6 +1080,176 @@ func (c *Client) GetSessionInfo(ctx context.Context) (*SessionInfo, error) {
 	return nil, NewSocketError("SESSION_NOT_FOUND", "Current session not found", sessionID)
 }
 
+// SessionState reports whether the attached session is generating, how many
+// prompts are queued behind the current one and which model is in use
+func (c *Client) SessionState(ctx context.Context) (SessionState, error) {
+	if !c.IsConnected() {
+		return SessionState{}, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if c.GetCurrentSessionID() == "" {
+		return SessionState{}, NewSocketError("NO_SESSION", "No active session", "")
+	}
+
+	msg := NewMessage("session_state", nil)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return SessionState{}, err
+	}
+
+	var result SessionState
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return SessionState{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListJobs lists the background programs tracked by the attached session
+func (c *Client) ListJobs(ctx context.Context) ([]JobInfo, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if c.GetCurrentSessionID() == "" {
+		return nil, NewSocketError("NO_SESSION", "No active session", "")
+	}
+
+	msg := NewMessage("jobs_list", nil)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Jobs []JobInfo `json:"jobs"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Jobs, nil
+}
+
+// JobStatus returns the state of a background program including the last
+// outputLines lines of its output (0 = server default, <0 = no output)
+func (c *Client) JobStatus(ctx context.Context, jobID string, outputLines int) (*JobInfo, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if c.GetCurrentSessionID() == "" {
+		return nil, NewSocketError("NO_SESSION", "No active session", "")
+	}
+
+	data := map[string]interface{}{
+		"job_id": jobID,
+	}
+	if outputLines != 0 {
+		data["output_lines"] = outputLines
+	}
+
+	msg := NewMessage("job_status", data)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Job JobInfo `json:"job"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result.Job, nil
+}
+
+// StopJob sends SIGTERM or SIGKILL (empty = SIGTERM) to a background program
+func (c *Client) StopJob(ctx context.Context, jobID, signal string) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if c.GetCurrentSessionID() == "" {
+		return NewSocketError("NO_SESSION", "No active session", "")
+	}
+
+	data := map[string]interface{}{
+		"job_id": jobID,
+	}
+	if signal != "" {
+		data["signal"] = signal
+	}
+
+	msg := NewMessage("job_stop", data)
+	_, err := c.SendRequest(msg)
+	return err
+}
+
+// ListProcesses lists the running background processes of all sessions on
+// the server, including processes of sessions no client is attached to
+func (c *Client) ListProcesses(ctx context.Context) ([]ProcessInfo, error) {
+	if !c.IsConnected() {
+		return nil, NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if err := c.checkCapability("process_list"); err != nil {
+		return nil, err
+	}
+
+	msg := NewMessage("process_list", nil)
+	resp, err := c.SendRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Processes []ProcessInfo `json:"processes"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Processes, nil
+}
+
+// KillProcess sends SIGTERM or SIGKILL (empty = SIGTERM) to a background
+// process by PID. The server rejects PIDs it did not start with an
+// OPERATION_NOT_ALLOWED error.
+func (c *Client) KillProcess(ctx context.Context, pid int, signal string) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if err := c.checkCapability("process_kill"); err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"pid": pid,
+	}
+	if signal != "" {
+		data["signal"] = signal
+	}
+
+	msg := NewMessage("process_kill", data)
+	_, err := c.SendRequest(msg)
+	return err
+}
+
+// PinMessage pins (or, with pinned false, unpins) the message at index of the
+// current session so context compaction keeps it verbatim
+func (c *Client) PinMessage(ctx context.Context, index int, pinned bool) error {
+	if !c.IsConnected() {
+		return NewSocketError("NOT_CONNECTED", "Not connected to server", "")
+	}
+	if c.GetCurrentSessionID() == "" {
+		return NewSocketError("NO_SESSION", "No active session", "")
+	}
+
+	msg := NewMessage("message_pin", map[string]interface{}{
+		"index":  index,
+		"pinned": pinned,
+	})
+	_, err := c.SendRequest(msg)
+	return err
+}
+
 // WaitForCompletion waits for a chat operation to complete
 func (c *Client) WaitForCompletion(ctx context.Context, timeout time.Duration) error {
 	sessionID := c.GetCurrentSessionID()
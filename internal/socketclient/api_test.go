@@ -0,0 +1,818 @@
+package socketclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// contextDirServer emulates the server's context directory handling
+type contextDirServer struct {
+	mu   sync.Mutex
+	dirs []string
+}
+
+func (s *contextDirServer) handle(conn *stubConn, msg *Message) {
+	var data struct {
+		Dir string `json:"dir"`
+	}
+	_ = json.Unmarshal(msg.Data, &data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case "context_dir_add":
+		s.dirs = append(s.dirs, data.Dir)
+	case "context_dir_remove":
+		idx := -1
+		for i, dir := range s.dirs {
+			if dir == data.Dir {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "INVALID_REQUEST", Message: "Context directory not found", Details: data.Dir}
+			conn.send(resp)
+			return
+		}
+		s.dirs = append(s.dirs[:idx], s.dirs[idx+1:]...)
+	case "context_dir_list":
+	default:
+		return
+	}
+
+	conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+		"workspace":    "/workspace",
+		"context_dirs": append([]string{}, s.dirs...),
+	}))
+}
+
+func TestContextDirRoundTrip(t *testing.T) {
+	handler := &contextDirServer{}
+	server := newStubServer(t, handler.handle)
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	base := t.TempDir()
+	docs := filepath.Join(base, "docs")
+	include := filepath.Join(base, "include")
+	for _, dir := range []string{docs, include} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+
+	dirs, err := client.ListContextDirs(ctx)
+	if err != nil {
+		t.Fatalf("ListContextDirs failed: %v", err)
+	}
+	if len(dirs) != 0 {
+		t.Fatalf("expected no context dirs, got %v", dirs)
+	}
+
+	if err := client.AddContextDir(ctx, docs); err != nil {
+		t.Fatalf("AddContextDir failed: %v", err)
+	}
+
+	// Relative paths are resolved against the current workspace
+	client.currentWorkspace.Store(base)
+	if err := client.AddContextDir(ctx, "include"); err != nil {
+		t.Fatalf("AddContextDir with relative path failed: %v", err)
+	}
+
+	dirs, err = client.ListContextDirs(ctx)
+	if err != nil {
+		t.Fatalf("ListContextDirs failed: %v", err)
+	}
+	if len(dirs) != 2 || dirs[0] != docs || dirs[1] != include {
+		t.Fatalf("expected [%s %s], got %v", docs, include, dirs)
+	}
+
+	if err := client.RemoveContextDir(ctx, docs); err != nil {
+		t.Fatalf("RemoveContextDir failed: %v", err)
+	}
+
+	dirs, err = client.ListContextDirs(ctx)
+	if err != nil {
+		t.Fatalf("ListContextDirs failed: %v", err)
+	}
+	if len(dirs) != 1 || dirs[0] != include {
+		t.Fatalf("expected [%s], got %v", include, dirs)
+	}
+
+	// Removing an unknown directory surfaces the server error
+	err = client.RemoveContextDir(ctx, docs)
+	var socketErr *SocketError
+	if !errors.As(err, &socketErr) || socketErr.Code != "INVALID_REQUEST" {
+		t.Fatalf("expected INVALID_REQUEST, got %v", err)
+	}
+}
+
+func TestAddContextDirValidation(t *testing.T) {
+	handler := &contextDirServer{}
+	server := newStubServer(t, handler.handle)
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	base := t.TempDir()
+	file := filepath.Join(base, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	for _, dir := range []string{"", filepath.Join(base, "missing"), file} {
+		err := client.AddContextDir(ctx, dir)
+		var socketErr *SocketError
+		if !errors.As(err, &socketErr) || socketErr.Code != "INVALID_REQUEST" {
+			t.Errorf("expected INVALID_REQUEST for %q, got %v", dir, err)
+		}
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.dirs) != 0 {
+		t.Errorf("invalid directories must not reach the server, got %v", handler.dirs)
+	}
+}
+
+// handleBatchStub answers batches of config_get and session_list requests
+func handleBatchStub(conn *stubConn, msg *Message) {
+	if msg.Type != "batch" {
+		return
+	}
+
+	var data struct {
+		Requests []Message `json:"requests"`
+	}
+	_ = json.Unmarshal(msg.Data, &data)
+
+	responses := make([]*Message, 0, len(data.Requests))
+	for _, req := range data.Requests {
+		switch req.Type {
+		case "config_get":
+			responses = append(responses, NewMessageWithRequestID(req.Type, req.RequestID, map[string]interface{}{
+				"config": map[string]interface{}{"model": "test-model"},
+			}))
+		case "session_list":
+			responses = append(responses, NewMessageWithRequestID(req.Type, req.RequestID, map[string]interface{}{
+				"sessions": []map[string]interface{}{{"id": "s1"}, {"id": "s2"}},
+			}))
+		default:
+			resp := NewMessageWithRequestID("error", req.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "INTERNAL_ERROR", Message: "unknown message type: " + req.Type}
+			responses = append(responses, resp)
+		}
+	}
+
+	conn.send(NewMessageWithRequestID("batch", msg.RequestID, map[string]interface{}{
+		"responses": responses,
+	}))
+}
+
+func TestBatch(t *testing.T) {
+	server := newStubServer(t, handleBatchStub)
+	client := connectStubClient(t, server)
+
+	responses, err := client.Batch(context.Background(),
+		Request{Type: "config_get", Data: map[string]interface{}{"keys": []string{"model"}}},
+		Request{Type: "session_list"},
+		Request{Type: "bogus"},
+	)
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(responses))
+	}
+
+	var cfg struct {
+		Config map[string]string `json:"config"`
+	}
+	if responses[0].Type != "config_get" || responses[0].Error != nil {
+		t.Fatalf("unexpected config_get response: %+v", responses[0])
+	}
+	if err := json.Unmarshal(responses[0].Data, &cfg); err != nil || cfg.Config["model"] != "test-model" {
+		t.Fatalf("unexpected config_get data %s: %v", responses[0].Data, err)
+	}
+
+	var sessions struct {
+		Sessions []map[string]string `json:"sessions"`
+	}
+	if responses[1].Type != "session_list" || responses[1].Error != nil {
+		t.Fatalf("unexpected session_list response: %+v", responses[1])
+	}
+	if err := json.Unmarshal(responses[1].Data, &sessions); err != nil || len(sessions.Sessions) != 2 {
+		t.Fatalf("unexpected session_list data %s: %v", responses[1].Data, err)
+	}
+
+	// A failing entry does not fail the whole batch
+	if responses[2].Error == nil || responses[2].Error.Code != "INTERNAL_ERROR" {
+		t.Fatalf("expected per-entry error, got %+v", responses[2])
+	}
+
+	if _, err := client.Batch(context.Background()); err == nil {
+		t.Fatal("expected error for empty batch")
+	}
+}
+
+// generatingServer holds chat_send requests open to emulate an in-progress
+// generation and answers session_state from that
+type generatingServer struct {
+	mu      sync.Mutex
+	pending []string // request IDs of unanswered chat_send requests
+	conn    *stubConn
+}
+
+func (s *generatingServer) handle(conn *stubConn, msg *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case "chat_send":
+		s.conn = conn
+		s.pending = append(s.pending, msg.RequestID)
+	case "session_state":
+		queued := 0
+		if len(s.pending) > 1 {
+			queued = len(s.pending) - 1
+		}
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"generating":     len(s.pending) > 0,
+			"queued_prompts": queued,
+			"current_model":  "test-model",
+		}))
+	}
+}
+
+// finish completes the oldest pending chat_send
+func (s *generatingServer) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return
+	}
+	s.conn.send(NewMessageWithRequestID("chat_send", s.pending[0], map[string]interface{}{"status": "completed"}))
+	s.pending = s.pending[1:]
+}
+
+func (s *generatingServer) pendingCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+func TestSessionState(t *testing.T) {
+	handler := &generatingServer{}
+	server := newStubServer(t, handler.handle)
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	if _, err := client.SessionState(ctx); err == nil {
+		t.Fatal("expected error without an attached session")
+	}
+	client.currentSessionID.Store("session-1")
+
+	state, err := client.SessionState(ctx)
+	if err != nil {
+		t.Fatalf("SessionState failed: %v", err)
+	}
+	if state.Generating || state.QueuedPrompts != 0 || state.CurrentModel != "test-model" {
+		t.Fatalf("unexpected idle state: %+v", state)
+	}
+
+	done := make(chan error, 2)
+	for _, prompt := range []string{"first", "second"} {
+		go func(prompt string) { done <- client.SendChat(ctx, prompt, nil) }(prompt)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for handler.pendingCount() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for chat_send requests")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	state, err = client.SessionState(ctx)
+	if err != nil {
+		t.Fatalf("SessionState failed: %v", err)
+	}
+	if !state.Generating || state.QueuedPrompts != 1 {
+		t.Fatalf("expected in-progress generation with 1 queued prompt, got %+v", state)
+	}
+
+	handler.finish()
+	handler.finish()
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("SendChat failed: %v", err)
+		}
+	}
+
+	state, err = client.SessionState(ctx)
+	if err != nil {
+		t.Fatalf("SessionState failed: %v", err)
+	}
+	if state.Generating {
+		t.Fatalf("expected generation to be finished, got %+v", state)
+	}
+}
+
+// jobsServer emulates the server's background job messages
+type jobsServer struct {
+	mu      sync.Mutex
+	stopped map[string]string // job ID -> signal
+}
+
+func (s *jobsServer) handle(conn *stubConn, msg *Message) {
+	var data struct {
+		JobID       string `json:"job_id"`
+		Signal      string `json:"signal"`
+		OutputLines int    `json:"output_lines"`
+	}
+	_ = json.Unmarshal(msg.Data, &data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case "jobs_list":
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"session_id": "session-1",
+			"jobs": []map[string]interface{}{
+				{"job_id": "job-build", "command": "go build ./...", "pid": 1234, "state": "completed", "exit_code": 1},
+				{"job_id": "job-server", "command": "npm run dev", "pid": 5678, "state": "running"},
+			},
+		}))
+	case "job_status":
+		if data.JobID != "job-server" {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "INVALID_REQUEST", Message: "Job not found", Details: data.JobID}
+			conn.send(resp)
+			return
+		}
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"job": map[string]interface{}{
+				"job_id": "job-server",
+				"state":  "running",
+				"stdout": []string{fmt.Sprintf("last %d lines", data.OutputLines)},
+			},
+		}))
+	case "job_stop":
+		if s.stopped == nil {
+			s.stopped = make(map[string]string)
+		}
+		s.stopped[data.JobID] = data.Signal
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"job_id": data.JobID,
+			"signal": data.Signal,
+			"status": "signal_sent",
+		}))
+	}
+}
+
+func TestJobManagement(t *testing.T) {
+	handler := &jobsServer{}
+	server := newStubServer(t, handler.handle)
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	if _, err := client.ListJobs(ctx); err == nil {
+		t.Fatal("expected error without an attached session")
+	}
+	client.currentSessionID.Store("session-1")
+
+	jobs, err := client.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].State != JobStateCompleted || jobs[0].ExitCode != 1 || jobs[1].PID != 5678 || jobs[1].State != JobStateRunning {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+
+	job, err := client.JobStatus(ctx, "job-server", 10)
+	if err != nil {
+		t.Fatalf("JobStatus failed: %v", err)
+	}
+	if job.JobID != "job-server" || len(job.Stdout) != 1 || job.Stdout[0] != "last 10 lines" {
+		t.Fatalf("unexpected job status: %+v", job)
+	}
+
+	_, err = client.JobStatus(ctx, "missing", 0)
+	var socketErr *SocketError
+	if !errors.As(err, &socketErr) || socketErr.Code != "INVALID_REQUEST" {
+		t.Fatalf("expected INVALID_REQUEST for unknown job, got %v", err)
+	}
+
+	if err := client.StopJob(ctx, "job-server", "SIGKILL"); err != nil {
+		t.Fatalf("StopJob failed: %v", err)
+	}
+	handler.mu.Lock()
+	signal := handler.stopped["job-server"]
+	handler.mu.Unlock()
+	if signal != "SIGKILL" {
+		t.Fatalf("expected SIGKILL to be sent, got %q", signal)
+	}
+}
+
+func TestPinMessage(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		var data map[string]interface{}
+		_ = json.Unmarshal(msg.Data, &data)
+		mu.Lock()
+		received = append(received, data)
+		mu.Unlock()
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"index":  data["index"],
+			"pinned": data["pinned"],
+		}))
+	})
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	if err := client.PinMessage(ctx, 0, true); err == nil {
+		t.Fatal("expected error without an attached session")
+	}
+	client.currentSessionID.Store("session-1")
+
+	if err := client.PinMessage(ctx, 2, true); err != nil {
+		t.Fatalf("PinMessage failed: %v", err)
+	}
+	if err := client.PinMessage(ctx, 2, false); err != nil {
+		t.Fatalf("PinMessage(unpin) failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0]["index"] != float64(2) || received[0]["pinned"] != true || received[1]["pinned"] != false {
+		t.Fatalf("unexpected requests: %+v", received)
+	}
+}
+
+func TestListSessionsWithOptions(t *testing.T) {
+	var mu sync.Mutex
+	var received map[string]interface{}
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		var data map[string]interface{}
+		_ = json.Unmarshal(msg.Data, &data)
+		mu.Lock()
+		received = data
+		mu.Unlock()
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"sessions": []map[string]interface{}{{"id": "s3", "title": "parser tests"}},
+			"total":    5,
+			"offset":   data["offset"],
+			"limit":    data["limit"],
+		}))
+	})
+	client := connectStubClient(t, server)
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	page, err := client.ListSessionsWithOptions(context.Background(), SessionListOptions{
+		Title:  "parser",
+		Since:  since,
+		Limit:  1,
+		Offset: 2,
+	})
+	if err != nil {
+		t.Fatalf("ListSessionsWithOptions failed: %v", err)
+	}
+	if page.Total != 5 || page.Offset != 2 || page.Limit != 1 || len(page.Sessions) != 1 {
+		t.Errorf("unexpected page: %+v", page)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received["title"] != "parser" || received["since"] != "2024-01-01T00:00:00Z" || received["limit"] != float64(1) || received["offset"] != float64(2) {
+		t.Errorf("unexpected request: %+v", received)
+	}
+	if _, ok := received["until"]; ok {
+		t.Errorf("expected unset until to be omitted, got %+v", received)
+	}
+}
+
+func TestSendChatWithAttachments(t *testing.T) {
+	var mu sync.Mutex
+	var received map[string]interface{}
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		var data map[string]interface{}
+		_ = json.Unmarshal(msg.Data, &data)
+		mu.Lock()
+		received = data
+		mu.Unlock()
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{"status": "completed"}))
+	})
+	client := connectStubClient(t, server)
+
+	err := client.SendChatWithAttachments(context.Background(), "Explain this", []Attachment{
+		{Path: "main.go"},
+		{Path: "notes.txt", StartLine: 2, EndLine: 3},
+	}, nil)
+	if err != nil {
+		t.Fatalf("SendChatWithAttachments failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	attachments, _ := received["attachments"].([]interface{})
+	if received["content"] != "Explain this" || len(attachments) != 2 {
+		t.Fatalf("unexpected request: %+v", received)
+	}
+	ranged, _ := attachments[1].(map[string]interface{})
+	if ranged["path"] != "notes.txt" || ranged["start_line"] != float64(2) || ranged["end_line"] != float64(3) {
+		t.Errorf("unexpected ranged attachment: %+v", ranged)
+	}
+	if whole, _ := attachments[0].(map[string]interface{}); len(whole) != 1 || whole["path"] != "main.go" {
+		t.Errorf("unexpected whole-file attachment: %+v", whole)
+	}
+}
+
+func TestSendChatWithModel(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		var data map[string]interface{}
+		_ = json.Unmarshal(msg.Data, &data)
+		mu.Lock()
+		received = append(received, data)
+		mu.Unlock()
+		if data["model"] == "missing-model" {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "UNKNOWN_MODEL", Message: "Unknown model", Details: `unknown model "missing-model"`}
+			conn.send(resp)
+			return
+		}
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{"status": "completed"}))
+	})
+	client := connectStubClient(t, server)
+
+	if err := client.SendChatWithModel(context.Background(), "Review this", ModelOverride{Model: "gpt-4o", Provider: "openai"}, nil); err != nil {
+		t.Fatalf("SendChatWithModel failed: %v", err)
+	}
+	err := client.SendChatWithModel(context.Background(), "Review this", ModelOverride{Model: "missing-model"}, nil)
+	if !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0]["model"] != "gpt-4o" || received[0]["provider"] != "openai" {
+		t.Fatalf("unexpected requests: %+v", received)
+	}
+	if _, ok := received[1]["provider"]; ok {
+		t.Errorf("expected an unset provider to be omitted, got %+v", received[1])
+	}
+}
+
+// modelsServer keeps a model selection like the server's provider manager
+type modelsServer struct {
+	mu       sync.Mutex
+	selected string
+}
+
+func (s *modelsServer) handle(conn *stubConn, msg *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case "models_list":
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"providers": []string{"openai"},
+			"models": []map[string]interface{}{{
+				"id":             "gpt-4o",
+				"name":           "GPT-4o",
+				"provider":       "openai",
+				"context_window": 128000,
+				"capabilities":   map[string]interface{}{"known": true, "supports_tools": true},
+			}},
+			"selected": map[string]interface{}{"orchestration": s.selected},
+		}))
+	case "model_set":
+		var data ModelSelection
+		_ = json.Unmarshal(msg.Data, &data)
+		if data.Model != "gpt-4o" {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "UNKNOWN_MODEL", Message: "Unknown model", Details: data.Model}
+			conn.send(resp)
+			return
+		}
+		s.selected = data.Model
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"role": "orchestration", "model": data.Model, "provider": "openai", "scope": "global",
+		}))
+	}
+}
+
+func TestModelsRoundTrip(t *testing.T) {
+	ms := &modelsServer{}
+	client := connectStubClient(t, newStubServer(t, ms.handle))
+	ctx := context.Background()
+
+	applied, err := client.SetModel(ctx, ModelSelection{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if applied.Model != "gpt-4o" || applied.Provider != "openai" || applied.Role != "orchestration" || applied.Scope != "global" {
+		t.Errorf("unexpected selection: %+v", applied)
+	}
+
+	list, err := client.ListModels(ctx, "")
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(list.Models) != 1 || list.Models[0].ContextWindow != 128000 || !list.Models[0].Capabilities.SupportsTools {
+		t.Errorf("unexpected models: %+v", list.Models)
+	}
+	if list.Selected.Orchestration != "gpt-4o" {
+		t.Errorf("expected the selection to round-trip, got %+v", list.Selected)
+	}
+	if list.Session != nil {
+		t.Errorf("expected no session selection, got %+v", list.Session)
+	}
+
+	if _, err := client.SetModel(ctx, ModelSelection{Model: "missing-model"}); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+	if _, err := client.SetModel(ctx, ModelSelection{}); err == nil {
+		t.Error("expected an empty selection to be rejected")
+	}
+}
+
+// versionedConfigServer emulates the server's optimistic config versioning
+type versionedConfigServer struct {
+	mu          sync.Mutex
+	version     uint64
+	temperature float64
+}
+
+func (s *versionedConfigServer) handle(conn *stubConn, msg *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case "config_get":
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"config":  map[string]interface{}{"temperature": s.temperature},
+			"version": s.version,
+		}))
+	case "config_set":
+		var data struct {
+			Values  map[string]float64 `json:"values"`
+			Version uint64             `json:"version"`
+		}
+		_ = json.Unmarshal(msg.Data, &data)
+		if data.Version != s.version {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "VERSION_CONFLICT", Message: "Config was changed by another client"}
+			conn.send(resp)
+			return
+		}
+		s.temperature = data.Values["temperature"]
+		s.version++
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{"version": s.version, "status": "updated"}))
+	}
+}
+
+func TestSetConfigVersionConflict(t *testing.T) {
+	server := newStubServer(t, (&versionedConfigServer{version: 7}).handle)
+	first, second := connectStubClient(t, server), connectStubClient(t, server)
+	ctx := context.Background()
+
+	snapshot, err := first.GetConfigSnapshot(ctx, []string{"temperature"})
+	if err != nil {
+		t.Fatalf("GetConfigSnapshot failed: %v", err)
+	}
+	if snapshot.Version != 7 {
+		t.Fatalf("expected version 7, got %d", snapshot.Version)
+	}
+	stale, err := second.GetConfigSnapshot(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetConfigSnapshot failed: %v", err)
+	}
+
+	version, err := first.SetConfig(ctx, snapshot.Version, map[string]interface{}{"temperature": 0.2}, false)
+	if err != nil || version != 8 {
+		t.Fatalf("expected version 8, got %d, %v", version, err)
+	}
+
+	if _, err := second.SetConfig(ctx, stale.Version, map[string]interface{}{"temperature": 0.9}, false); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	fresh, err := second.GetConfigSnapshot(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetConfigSnapshot failed: %v", err)
+	}
+	if fresh.Config["temperature"] != 0.2 {
+		t.Errorf("expected the stale set to change nothing, got %v", fresh.Config["temperature"])
+	}
+	if _, err := second.SetConfig(ctx, fresh.Version, map[string]interface{}{"temperature": 0.9}, false); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+}
+
+func TestProcessManagement(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		killed = make(map[int]string)
+	)
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		var data struct {
+			PID    int    `json:"pid"`
+			Signal string `json:"signal"`
+		}
+		_ = json.Unmarshal(msg.Data, &data)
+
+		switch msg.Type {
+		case "process_list":
+			conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+				"processes": []map[string]interface{}{
+					{"pid": 4001, "job_id": "job-dev", "session_id": "session-1", "command": "npm run dev", "orphaned": true},
+				},
+			}))
+		case "process_kill":
+			if data.PID != 4001 {
+				resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+				resp.Error = &ErrorInfo{Code: "OPERATION_NOT_ALLOWED", Message: "Process was not started by this server"}
+				conn.send(resp)
+				return
+			}
+			mu.Lock()
+			killed[data.PID] = data.Signal
+			mu.Unlock()
+			conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+				"signal": data.Signal,
+				"status": "signal_sent",
+			}))
+		}
+	})
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	processes, err := client.ListProcesses(ctx)
+	if err != nil {
+		t.Fatalf("ListProcesses failed: %v", err)
+	}
+	if len(processes) != 1 || processes[0].PID != 4001 || processes[0].JobID != "job-dev" || !processes[0].Orphaned {
+		t.Fatalf("unexpected processes: %+v", processes)
+	}
+
+	var socketErr *SocketError
+	if err := client.KillProcess(ctx, 1, ""); !errors.As(err, &socketErr) || socketErr.Code != "OPERATION_NOT_ALLOWED" {
+		t.Fatalf("expected OPERATION_NOT_ALLOWED for an untracked pid, got %v", err)
+	}
+
+	if err := client.KillProcess(ctx, 4001, "SIGKILL"); err != nil {
+		t.Fatalf("KillProcess failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if killed[4001] != "SIGKILL" || len(killed) != 1 {
+		t.Fatalf("expected only SIGKILL to 4001, got %v", killed)
+	}
+}
+
+func TestCompactChat(t *testing.T) {
+	generating := true
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		if msg.Type != "chat_compact" {
+			return
+		}
+		if generating {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "SESSION_BUSY", Message: "Cannot compact while a prompt is being processed"}
+			conn.send(resp)
+			return
+		}
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+			"status":             "compacted",
+			"compacted_messages": 12,
+			"freed_percent":      64,
+		}))
+	})
+	client := connectStubClient(t, server)
+	ctx := context.Background()
+
+	var socketErr *SocketError
+	if _, err := client.CompactChat(ctx); !errors.As(err, &socketErr) || socketErr.Code != "SESSION_BUSY" {
+		t.Fatalf("expected a SESSION_BUSY error, got %v", err)
+	}
+
+	generating = false
+	result, err := client.CompactChat(ctx)
+	if err != nil {
+		t.Fatalf("CompactChat failed: %v", err)
+	}
+	if result.CompactedMessages != 12 || result.FreedPercent != 64 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
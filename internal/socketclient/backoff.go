@@ -0,0 +1,75 @@
+package socketclient
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ReconnectStrategy computes how long to wait before a reconnection attempt
+type ReconnectStrategy interface {
+	// Delay returns the wait before the given attempt, starting at 0 for the
+	// first attempt after the connection was lost
+	Delay(attempt int) time.Duration
+}
+
+// ExponentialBackoff is a ReconnectStrategy multiplying the delay after every
+// failed attempt. Jitter spreads the reconnects of many clients after a
+// shared server restarts instead of letting them all arrive at once.
+type ExponentialBackoff struct {
+	// InitialDelay is the delay before the first attempt
+	InitialDelay time.Duration
+	// Multiplier is applied to the delay after every attempt (values below 1 mean 2)
+	Multiplier float64
+	// MaxDelay caps the delay (0 = no cap)
+	MaxDelay time.Duration
+	// Jitter is the fraction of the delay, between 0 and 1, that is randomly
+	// subtracted. With 0.5 the delay lies between half and all of the curve.
+	Jitter float64
+
+	// random returns a number in [0, 1); nil uses math/rand
+	random func() float64
+}
+
+// Delay returns InitialDelay * Multiplier^attempt, capped at MaxDelay and
+// reduced by up to Jitter of its value
+func (b *ExponentialBackoff) Delay(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(b.InitialDelay) * math.Pow(multiplier, float64(attempt))
+	if b.MaxDelay > 0 && delay > float64(b.MaxDelay) {
+		delay = float64(b.MaxDelay)
+	}
+	if delay > math.MaxInt64 {
+		delay = math.MaxInt64
+	}
+
+	if jitter := min(max(b.Jitter, 0), 1); jitter > 0 {
+		random := b.random
+		if random == nil {
+			random = rand.Float64
+		}
+		delay -= delay * jitter * random()
+	}
+
+	return time.Duration(delay)
+}
+
+// reconnectStrategy returns the configured strategy or the default backoff,
+// doubling ReconnectDelay up to ReconnectMaxDelay without jitter
+func (c *Client) reconnectStrategy() ReconnectStrategy {
+	if c.config.ReconnectStrategy != nil {
+		return c.config.ReconnectStrategy
+	}
+	return &ExponentialBackoff{
+		InitialDelay: c.config.ReconnectDelay,
+		Multiplier:   2,
+		MaxDelay:     c.config.ReconnectMaxDelay,
+	}
+}
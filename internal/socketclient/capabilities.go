@@ -0,0 +1,78 @@
+package socketclient
+
+import "sort"
+
+// Protocol features a server may advertise besides the request types it handles
+const (
+	// CapabilityBatching is advertised by servers accepting batch requests
+	CapabilityBatching = "batching"
+	// CapabilityStreamingDeltas is advertised by servers streaming chat
+	// messages as chunks with a stream_id
+	CapabilityStreamingDeltas = "streaming_deltas"
+)
+
+// alwaysSupported are message types every server handles
+var alwaysSupported = map[string]bool{
+	"auth_request": true,
+	"ping":         true,
+	"pong":         true,
+	"close":        true,
+}
+
+// capabilitySet holds the capabilities advertised by the server
+type capabilitySet struct {
+	list []string
+	set  map[string]bool
+}
+
+// setCapabilities records the capabilities of the auth response. Servers
+// predating capability negotiation send none; everything is assumed to be
+// supported then.
+func (c *Client) setCapabilities(capabilities []string) {
+	if capabilities == nil {
+		c.capabilities.Store((*capabilitySet)(nil))
+		return
+	}
+
+	cs := &capabilitySet{
+		list: append([]string(nil), capabilities...),
+		set:  make(map[string]bool, len(capabilities)),
+	}
+	for _, capability := range capabilities {
+		cs.set[capability] = true
+	}
+	sort.Strings(cs.list)
+	c.capabilities.Store(cs)
+}
+
+func (c *Client) getCapabilities() *capabilitySet {
+	cs, _ := c.capabilities.Load().(*capabilitySet)
+	return cs
+}
+
+// ServerCapabilities returns the message types and protocol features the
+// server advertised when connecting, sorted. It returns nil if the server
+// predates capability negotiation.
+func (c *Client) ServerCapabilities() []string {
+	cs := c.getCapabilities()
+	if cs == nil {
+		return nil
+	}
+	return append([]string(nil), cs.list...)
+}
+
+// HasCapability reports whether the server advertised a message type or
+// feature. Servers predating capability negotiation are assumed to support
+// everything.
+func (c *Client) HasCapability(name string) bool {
+	cs := c.getCapabilities()
+	return cs == nil || alwaysSupported[name] || cs.set[name]
+}
+
+// checkCapability returns ErrUnsupported if the server did not advertise name
+func (c *Client) checkCapability(name string) error {
+	if c.HasCapability(name) {
+		return nil
+	}
+	return NewSocketError(ErrUnsupported.Code, ErrUnsupported.Message, name)
+}
@@ -64,6 +64,33 @@ func (e *SocketError) Error() string {
 	return msg
 }
 
+// Is reports whether target is a SocketError with the same code, so that
+// errors.Is(err, ErrSessionBusy) matches errors received from the server
+func (e *SocketError) Is(target error) bool {
+	t, ok := target.(*SocketError)
+	return ok && t.Code == e.Code
+}
+
+// ErrSessionBusy is returned when a session is still attached to another connection
+var ErrSessionBusy = NewSocketError("SESSION_BUSY", "Session is attached to another client", "")
+
+// ErrUnsupported is returned when calling a feature the server did not
+// advertise in its capabilities; the request is not sent
+var ErrUnsupported = NewSocketError("UNSUPPORTED", "Server does not support this feature", "")
+
+// ErrSessionEvicted is returned when attaching to a session the server evicted
+// from memory after being idle; reload it with LoadSession
+var ErrSessionEvicted = NewSocketError("SESSION_EVICTED", "Session was evicted after being idle", "")
+
+// ErrUnknownModel is returned when a chat message or model selection names a
+// model or provider the server has not configured
+var ErrUnknownModel = NewSocketError("UNKNOWN_MODEL", "Model is not configured", "")
+
+// ErrVersionConflict is returned by SetConfig when another client changed the
+// config since its version was read; read it again with GetConfigSnapshot and
+// retry
+var ErrVersionConflict = NewSocketError("VERSION_CONFLICT", "Config was changed by another client", "")
+
 // NewSocketError creates a new SocketError
 func NewSocketError(code, message, details string) *SocketError {
 	return &SocketError{
@@ -95,14 +122,30 @@ type Config struct {
 	ReconnectDelay time.Duration
 	// ReconnectMaxDelay is the maximum delay between reconnection attempts
 	ReconnectMaxDelay time.Duration
+	// ReconnectStrategy computes the delay before each reconnection attempt.
+	// nil doubles ReconnectDelay after every attempt up to ReconnectMaxDelay.
+	ReconnectStrategy ReconnectStrategy
 	// RequestTimeout is the default timeout for requests
 	RequestTimeout time.Duration
 	// ReadTimeout is the timeout for reading messages
 	ReadTimeout time.Duration
 	// WriteTimeout is the timeout for writing messages
 	WriteTimeout time.Duration
-	// PingInterval is the interval for sending ping messages
+	// PingInterval is the interval for sending ping messages (keepalive,
+	// 0 or less = disabled)
 	PingInterval time.Duration
+	// MaxMissedPongs is the number of consecutive unanswered pings after which
+	// the connection is considered half-open and dropped (0 = never)
+	MaxMissedPongs int
+	// MaxPendingRequests limits the number of requests awaiting a response (0 = unlimited)
+	MaxPendingRequests int
+	// BlockOnQueueFull makes requests wait for a free slot instead of failing with ErrQueueFull
+	BlockOnQueueFull bool
+	// EnableCompression offers gzip compression of large messages to the server
+	EnableCompression bool
+	// ReconnectTakeover reclaims a session that is still attached to the stale
+	// connection after reconnecting
+	ReconnectTakeover bool
 }
 
 // DefaultConfig returns a default configuration
@@ -121,6 +164,10 @@ func DefaultConfig() *Config {
 		ReadTimeout:          60 * time.Second,
 		WriteTimeout:         10 * time.Second,
 		PingInterval:         54 * time.Second,
+		MaxMissedPongs:       3,
+		MaxPendingRequests:   256,
+		BlockOnQueueFull:     true,
+		EnableCompression:    true,
 	}
 }
 
@@ -130,8 +177,12 @@ type Client struct {
 
 	// Connection
 	conn          net.Conn
+	connDone      chan struct{} // Closed when conn is lost
 	connMu        sync.RWMutex
 	state         atomic.Int32 // ConnectionState
+	compression   atomic.Bool  // Compression negotiated with the server
+	capabilities  atomic.Value // *capabilitySet advertised by the server, nil for older servers
+	closing       atomic.Bool  // Set once Disconnect or Close was called
 	connectCtx    context.Context
 	connectCancel context.CancelFunc
 	connectMu     sync.Mutex
@@ -139,13 +190,16 @@ type Client struct {
 	// Message I/O
 	outgoing chan *Message
 	incoming chan *Message
+	recorder atomic.Pointer[recorder] // Set by EnableRecording
 
 	// Request tracking
 	pendingRequests map[string]chan *Message
 	requestMu       sync.RWMutex
+	requestQueue    requestQueue
 
 	// Callbacks
 	chatMessageCallback    func(ChatMessage)
+	reasoningCallback      func(ReasoningMessage)
 	toolCallCallback       func(ToolCall)
 	toolResultCallback     func(ToolResult)
 	progressCallback       func(ProgressData)
@@ -155,11 +209,15 @@ type Client struct {
 	stateChangedCallback   func(ConnectionState, error)
 	reconnectingCallback   func(attempt int, maxAttempts int)
 	connectionLostCallback func(error)
+	serverClosingCallback  func(ServerClosing)
 
 	// Session tracking
 	currentSessionID atomic.Value // string
 	currentWorkspace atomic.Value // string
 
+	// Keepalive
+	missedPongs atomic.Int32 // Pings sent on the current connection without a pong
+
 	// Reconnection
 	reconnectAttempts int
 	reconnectMu       sync.Mutex
@@ -192,6 +250,10 @@ func NewClientWithConfig(config *Config) (*Client, error) {
 		doneCh:          make(chan struct{}),
 	}
 
+	// Bound pending requests
+	client.requestQueue.setLimit(config.MaxPendingRequests)
+	client.requestQueue.setBlock(config.BlockOnQueueFull)
+
 	// Set initial state
 	client.state.Store(int32(StateDisconnected))
 
@@ -227,6 +289,8 @@ func (c *Client) connect(ctx context.Context, isReconnect bool) error {
 
 	// Set connecting state
 	c.setState(StateConnecting)
+	c.compression.Store(false)
+	c.setCapabilities(nil)
 
 	// Store context for cancellation
 	c.connectMu.Lock()
@@ -246,20 +310,27 @@ func (c *Client) connect(ctx context.Context, isReconnect bool) error {
 	}
 
 	// Store connection
+	done := make(chan struct{})
 	c.connMu.Lock()
 	c.conn = conn
+	c.connDone = done
 	c.connMu.Unlock()
 
 	// Start message pumps
 	c.wg.Add(2)
-	go c.readPump()
-	go c.writePump()
+	go c.readPump(conn)
+	go c.writePump(conn, done)
 
 	// Send authentication request
+	capabilities := append([]string{}, c.config.Capabilities...)
+	if c.config.EnableCompression {
+		capabilities = append(capabilities, CapabilityCompression)
+	}
+
 	authReq := map[string]interface{}{
 		"client_type":  c.config.ClientType,
 		"version":      c.config.ClientVersion,
-		"capabilities": c.config.Capabilities,
+		"capabilities": capabilities,
 	}
 
 	if c.config.AuthToken != "" {
@@ -292,6 +363,7 @@ func (c *Client) connect(ctx context.Context, isReconnect bool) error {
 		ConnectionID       string   `json:"connection_id"`
 		ServerVersion      string   `json:"server_version"`
 		ServerCapabilities []string `json:"server_capabilities"`
+		Capabilities       []string `json:"capabilities"`
 	}
 
 	if err := json.Unmarshal(resp.Data, &authRespData); err != nil {
@@ -304,13 +376,26 @@ func (c *Client) connect(ctx context.Context, isReconnect bool) error {
 		return errors.New("authentication rejected by server")
 	}
 
+	// Compress large messages only if the server agreed
+	if c.config.EnableCompression {
+		for _, capability := range authRespData.ServerCapabilities {
+			if capability == CapabilityCompression {
+				c.compression.Store(true)
+				break
+			}
+		}
+	}
+
+	c.setCapabilities(authRespData.Capabilities)
+
 	// Set connected state
 	c.setState(StateConnected)
 	c.reconnectAttempts = 0
 
 	// Start ping ticker
+	c.missedPongs.Store(0)
 	c.wg.Add(1)
-	go c.pingPong()
+	go c.pingPong(conn, done)
 
 	return nil
 }
@@ -332,6 +417,9 @@ func (c *Client) setState(state ConnectionState) {
 
 // Disconnect disconnects from the socket server gracefully
 func (c *Client) Disconnect() error {
+	// The server closes the connection in response; don't treat it as loss
+	c.closing.Store(true)
+
 	// Send close message if connected
 	if c.getState() == StateConnected {
 		closeMsg := NewMessage("close", map[string]interface{}{
@@ -355,6 +443,7 @@ func (c *Client) Close() error {
 	}
 
 	// Set closed state
+	c.closing.Store(true)
 	c.setState(StateClosed)
 
 	// Cancel connection context
@@ -404,17 +493,9 @@ func (c *Client) GetState() ConnectionState {
 }
 
 // readPump reads messages from the connection
-func (c *Client) readPump() {
+func (c *Client) readPump(conn net.Conn) {
 	defer c.wg.Done()
 
-	c.connMu.RLock()
-	conn := c.conn
-	c.connMu.RUnlock()
-
-	if conn == nil {
-		return
-	}
-
 	reader := bufio.NewReader(conn)
 
 	for {
@@ -428,8 +509,12 @@ func (c *Client) readPump() {
 			// Read message (newline-delimited JSON)
 			line, err := reader.ReadString('\n')
 			if err != nil {
-				if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
-					c.handleConnectionError(err)
+				// net.ErrClosed means the connection was closed on our side
+				if !errors.Is(err, net.ErrClosed) {
+					if errors.Is(err, io.EOF) {
+						err = NewSocketError("CONNECTION_LOST", "Server closed the connection", "")
+					}
+					c.handleConnectionError(conn, err)
 				}
 				return
 			}
@@ -440,6 +525,7 @@ func (c *Client) readPump() {
 				// Send error message back to server
 				continue
 			}
+			c.recordFrame(DirectionReceived, msg)
 
 			// Route message
 			c.routeMessage(msg)
@@ -447,29 +533,39 @@ func (c *Client) readPump() {
 	}
 }
 
-// writePump writes messages to the connection
-func (c *Client) writePump() {
+// writePump writes messages to the connection until it is lost
+func (c *Client) writePump(conn net.Conn, done <-chan struct{}) {
 	defer c.wg.Done()
 
-	c.connMu.RLock()
-	conn := c.conn
-	c.connMu.RUnlock()
-
-	if conn == nil {
-		return
-	}
-
 	for {
 		select {
 		case <-c.stopCh:
 			return
+		case <-done:
+			return
 		case msg, ok := <-c.outgoing:
 			if !ok {
 				return
 			}
 
+			// Hand the message over to the pump of the next connection
+			select {
+			case <-done:
+				c.requeue(msg)
+				return
+			default:
+			}
+
 			// Set write deadline
 			_ = conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+			c.recordFrame(DirectionSent, msg)
+
+			// Compress large payloads if negotiated
+			if c.compression.Load() {
+				if compressed, err := compressMessage(msg); err == nil {
+					msg = compressed
+				}
+			}
 
 			// Serialize message
 			data, err := json.Marshal(msg)
@@ -479,7 +575,7 @@ func (c *Client) writePump() {
 
 			// Write message with newline
 			if _, err := conn.Write(append(data, '\n')); err != nil {
-				c.handleConnectionError(err)
+				c.handleConnectionError(conn, err)
 				return
 			}
 		}
@@ -491,7 +587,7 @@ func (c *Client) routeMessage(msg *Message) {
 	msgType, _ := msg.GetType()
 
 	// Debug logging for streaming messages
-	if msgType == "chat_message" || msgType == "tool_call" || msgType == "tool_result" || msgType == "progress" {
+	if msgType == "chat_message" || msgType == "reasoning" || msgType == "tool_call" || msgType == "tool_result" || msgType == "progress" {
 		logger.Debug("[SocketClient] Received %s message: request_id=%s", msgType, msg.RequestID)
 	}
 
@@ -514,6 +610,16 @@ func (c *Client) routeMessage(msg *Message) {
 			logger.Warn("[SocketClient] chatMessageCallback is nil, message dropped!")
 		}
 		return
+	case "reasoning":
+		if c.reasoningCallback != nil {
+			var reasoning ReasoningMessage
+			if err := json.Unmarshal(msg.Data, &reasoning); err == nil {
+				c.reasoningCallback(reasoning)
+			} else {
+				logger.Warn("[SocketClient] Failed to unmarshal reasoning: %v", err)
+			}
+		}
+		return
 	case "tool_call":
 		if c.toolCallCallback != nil {
 			var toolCall ToolCall
@@ -560,10 +666,18 @@ func (c *Client) routeMessage(msg *Message) {
 
 	// Route remaining message types to callbacks
 	switch msgType {
+	case "closing":
+		if c.serverClosingCallback != nil {
+			var closing ServerClosing
+			if err := json.Unmarshal(msg.Data, &closing); err == nil {
+				c.serverClosingCallback(closing)
+			}
+		}
 	case "closed":
 		c.handleServerClosed(msg)
 	case "pong":
-		// Handled by ping/pong ticker
+		// The server is alive
+		c.missedPongs.Store(0)
 	case "error":
 		// Error messages are typically responses to requests
 		if c.stateChangedCallback != nil && msg.Error != nil {
@@ -572,10 +686,16 @@ func (c *Client) routeMessage(msg *Message) {
 	}
 }
 
-// pingPong sends periodic ping messages
-func (c *Client) pingPong() {
+// pingPong sends periodic ping messages on conn until it is lost. If the
+// server leaves MaxMissedPongs pings in a row unanswered, the connection is
+// treated as half-open (the socket is open but the server is gone) and
+// dropped, which triggers the reconnect logic.
+func (c *Client) pingPong(conn net.Conn, done <-chan struct{}) {
 	defer c.wg.Done()
 
+	if c.config.PingInterval <= 0 {
+		return // Keepalive disabled
+	}
 	ticker := time.NewTicker(c.config.PingInterval)
 	defer ticker.Stop()
 
@@ -583,19 +703,63 @@ func (c *Client) pingPong() {
 		select {
 		case <-c.stopCh:
 			return
+		case <-done:
+			return
 		case <-ticker.C:
 			if c.getState() != StateConnected {
 				continue
 			}
 
+			if limit := c.config.MaxMissedPongs; limit > 0 && int(c.missedPongs.Load()) >= limit {
+				c.handleConnectionError(conn, NewSocketError("CONNECTION_LOST",
+					"Server stopped answering pings",
+					fmt.Sprintf("%d pings without a pong", limit)))
+				return
+			}
+			c.missedPongs.Add(1)
+
 			pingMsg := NewMessage("ping", nil)
-			c.outgoing <- pingMsg
+			select {
+			case c.outgoing <- pingMsg:
+			case <-done:
+				return
+			case <-c.stopCh:
+				return
+			}
 		}
 	}
 }
 
-// handleConnectionError handles connection errors
-func (c *Client) handleConnectionError(err error) {
+// requeue puts a message taken by the pump of a lost connection back into the queue
+func (c *Client) requeue(msg *Message) {
+	defer func() {
+		// outgoing is closed if the client was closed meanwhile
+		_ = recover()
+	}()
+
+	select {
+	case c.outgoing <- msg:
+	case <-c.stopCh:
+	}
+}
+
+// handleConnectionError handles errors of conn; errors of connections that
+// were already replaced are ignored
+func (c *Client) handleConnectionError(conn net.Conn, err error) {
+	c.connMu.Lock()
+	if c.conn != conn || conn == nil {
+		c.connMu.Unlock()
+		return
+	}
+	close(c.connDone)
+	_ = conn.Close()
+	c.conn = nil
+	c.connMu.Unlock()
+
+	if c.closing.Load() {
+		return
+	}
+
 	// Set disconnected state
 	oldState := c.getState()
 	c.setState(StateDisconnected)
@@ -621,11 +785,7 @@ func (c *Client) attemptReconnect() {
 		return
 	}
 
-	// Calculate delay with exponential backoff
-	delay := c.config.ReconnectDelay * time.Duration(1<<uint(c.reconnectAttempts))
-	if delay > c.config.ReconnectMaxDelay {
-		delay = c.config.ReconnectMaxDelay
-	}
+	delay := c.reconnectStrategy().Delay(c.reconnectAttempts)
 
 	// Notify callback
 	if c.reconnectingCallback != nil {
@@ -656,12 +816,7 @@ func (c *Client) attemptReconnect() {
 
 	// Reconnection successful, restore session
 	if sessionID != "" {
-		// Attempt to reattach to session
-		attachMsg := NewMessage("session_attach", map[string]interface{}{
-			"session_id": sessionID,
-		})
-		_, err = c.SendRequest(attachMsg)
-		if err != nil {
+		if err := c.reattachSession(sessionID); err != nil {
 			// Failed to reattach, but connection is alive
 			c.currentSessionID.Store("")
 		}
@@ -682,6 +837,29 @@ func (c *Client) attemptReconnect() {
 	c.reconnectAttempts = 0
 }
 
+// reattachSession reattaches to a session after reconnecting. If the server
+// still considers the session attached to the lost connection and takeover is
+// enabled, the session is reclaimed with session_takeover.
+func (c *Client) reattachSession(sessionID string) error {
+	attachMsg := NewMessage("session_attach", map[string]interface{}{
+		"session_id": sessionID,
+	})
+	_, err := c.SendRequest(attachMsg)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, ErrSessionBusy) || !c.config.ReconnectTakeover {
+		return err
+	}
+
+	takeoverMsg := NewMessage("session_takeover", map[string]interface{}{
+		"session_id": sessionID,
+	})
+	_, err = c.SendRequest(takeoverMsg)
+	return err
+}
+
 // handleServerClosed handles server-side close notification
 func (c *Client) handleServerClosed(msg *Message) {
 	var closedData struct {
@@ -700,7 +878,10 @@ func (c *Client) handleServerClosed(msg *Message) {
 	}
 
 	// Handle connection error
-	c.handleConnectionError(NewSocketError("SERVER_CLOSED", closedData.Reason, ""))
+	c.connMu.RLock()
+	conn := c.conn
+	c.connMu.RUnlock()
+	c.handleConnectionError(conn, NewSocketError("SERVER_CLOSED", closedData.Reason, ""))
 }
 
 // handleAuthorizationRequest handles an authorization request from the server
@@ -818,6 +999,12 @@ func (c *Client) SetChatMessageCallback(fn func(ChatMessage)) {
 	c.chatMessageCallback = fn
 }
 
+// SetReasoningCallback sets the callback for model reasoning. Reasoning is
+// dropped if no callback is set.
+func (c *Client) SetReasoningCallback(fn func(ReasoningMessage)) {
+	c.reasoningCallback = fn
+}
+
 // SetToolCallCallback sets the callback for tool calls
 func (c *Client) SetToolCallCallback(fn func(ToolCall)) {
 	c.toolCallCallback = fn
@@ -858,6 +1045,11 @@ func (c *Client) SetConnectionLostCallback(fn func(error)) {
 	c.connectionLostCallback = fn
 }
 
+// SetServerClosingCallback sets the callback for the server's shutdown notice
+func (c *Client) SetServerClosingCallback(fn func(ServerClosing)) {
+	c.serverClosingCallback = fn
+}
+
 // SetCompletionCallback sets the callback for completion notifications
 func (c *Client) SetCompletionCallback(fn func(requestID string, success bool, errorMsg string)) {
 	c.completionCallback = fn
@@ -873,6 +1065,24 @@ func (c *Client) SetMaxReconnectAttempts(attempts int) {
 	c.config.MaxReconnectAttempts = attempts
 }
 
+// SetReconnectTakeover enables taking over a session that is still attached to
+// the lost connection when reattaching after a reconnect
+func (c *Client) SetReconnectTakeover(enabled bool) {
+	c.config.ReconnectTakeover = enabled
+}
+
+// SetKeepaliveInterval sets the interval between pings. Zero or a negative
+// interval disables the keepalive. It takes effect on the next connection.
+func (c *Client) SetKeepaliveInterval(interval time.Duration) {
+	c.config.PingInterval = interval
+}
+
+// SetMaxMissedPongs sets how many consecutive pings may go unanswered before
+// the connection is considered dead and reconnected (0 = never)
+func (c *Client) SetMaxMissedPongs(n int) {
+	c.config.MaxMissedPongs = n
+}
+
 // SetReconnectDelay sets the initial delay between reconnection attempts
 func (c *Client) SetReconnectDelay(delay time.Duration) {
 	c.config.ReconnectDelay = delay
@@ -883,11 +1093,49 @@ func (c *Client) SetReconnectMaxDelay(delay time.Duration) {
 	c.config.ReconnectMaxDelay = delay
 }
 
+// SetReconnectStrategy sets the strategy computing the delay before each
+// reconnection attempt. nil restores the default exponential backoff based on
+// the reconnect delay and maximum delay.
+func (c *Client) SetReconnectStrategy(strategy ReconnectStrategy) {
+	c.config.ReconnectStrategy = strategy
+}
+
+// SetCompressionEnabled controls whether compression is offered to the server.
+// It takes effect on the next connection.
+func (c *Client) SetCompressionEnabled(enabled bool) {
+	c.config.EnableCompression = enabled
+}
+
+// IsCompressionActive returns true if compression was negotiated with the server
+func (c *Client) IsCompressionActive() bool {
+	return c.compression.Load()
+}
+
 // SetRequestTimeout sets the default timeout for requests
 func (c *Client) SetRequestTimeout(timeout time.Duration) {
 	c.config.RequestTimeout = timeout
 }
 
+// SetMaxPendingRequests sets the maximum number of requests awaiting a response (0 = unlimited)
+func (c *Client) SetMaxPendingRequests(n int) {
+	c.config.MaxPendingRequests = n
+	c.requestQueue.setLimit(n)
+}
+
+// SetBlockOnQueueFull controls whether requests block or fail with ErrQueueFull
+// when the maximum number of pending requests is reached
+func (c *Client) SetBlockOnQueueFull(block bool) {
+	c.config.BlockOnQueueFull = block
+	c.requestQueue.setBlock(block)
+}
+
+// PendingRequestCount returns the number of requests awaiting a response
+func (c *Client) PendingRequestCount() int {
+	c.requestMu.RLock()
+	defer c.requestMu.RUnlock()
+	return len(c.pendingRequests)
+}
+
 // GetReconnectAttempts returns the current number of reconnection attempts
 func (c *Client) GetReconnectAttempts() int {
 	return c.reconnectAttempts
@@ -0,0 +1,490 @@
+package socketclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubServer is a minimal socket server that authenticates clients and passes
+// every other message to a handler
+type stubServer struct {
+	t        *testing.T
+	path     string
+	listener net.Listener
+	handler  func(conn *stubConn, msg *Message)
+
+	// capabilities are advertised in the auth response
+	capabilities []string
+	// protocolCapabilities are the supported message types and features
+	// advertised in the auth response; nil mimics an older server
+	protocolCapabilities []string
+	// compressedFrames counts received frames carrying a compressed payload
+	compressedFrames atomic.Int32
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+// stubConn wraps a server-side connection with a serialized writer
+type stubConn struct {
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+func (sc *stubConn) send(msg *Message) {
+	data, _ := json.Marshal(msg)
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	_, _ = sc.conn.Write(append(data, '\n'))
+}
+
+func newStubServer(t *testing.T, handler func(conn *stubConn, msg *Message)) *stubServer {
+	t.Helper()
+	return startStubServer(t, &stubServer{handler: handler})
+}
+
+func startStubServer(t *testing.T, s *stubServer) *stubServer {
+	t.Helper()
+
+	// Keep the path short; unix socket paths are limited in length
+	dir, err := os.MkdirTemp("", "sc")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "s.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	s.t = t
+	s.path = path
+	s.listener = listener
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+func (s *stubServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		go s.handle(&stubConn{conn: conn})
+	}
+}
+
+func (s *stubServer) handle(sc *stubConn) {
+	reader := bufio.NewReader(sc.conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.Contains(line, `"compression":"gzip"`) {
+			s.compressedFrames.Add(1)
+		}
+
+		msg, err := ParseMessage(line)
+		if err != nil {
+			continue
+		}
+
+		if msg.Type == "auth_request" {
+			sc.send(NewMessageWithRequestID("auth_response", msg.RequestID, map[string]interface{}{
+				"success":             true,
+				"connection_id":       "conn-test",
+				"server_capabilities": s.capabilities,
+				"capabilities":        s.protocolCapabilities,
+			}))
+			continue
+		}
+
+		if s.handler != nil {
+			s.handler(sc, msg)
+		}
+	}
+}
+
+// dropConnections closes all client connections from the server side
+func (s *stubServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *stubServer) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+// connectStubClient connects a client without reconnection to the stub server.
+// Options can adjust the configuration before connecting.
+func connectStubClient(t *testing.T, s *stubServer, opts ...func(*Config)) *Client {
+	t.Helper()
+
+	config := DefaultConfig()
+	config.SocketPath = s.path
+	config.ReconnectEnabled = false
+	config.RequestTimeout = 5 * time.Second
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// waitFor polls cond until it holds or the deadline passes
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+// heldResponder answers requests only once they are released, in arrival order
+type heldResponder struct {
+	mu       sync.Mutex
+	held     []*Message
+	received []string
+	conn     *stubConn
+}
+
+func (h *heldResponder) handle(conn *stubConn, msg *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.conn = conn
+	h.held = append(h.held, msg)
+	var data struct {
+		Seq string `json:"seq"`
+	}
+	_ = json.Unmarshal(msg.Data, &data)
+	h.received = append(h.received, data.Seq)
+}
+
+func (h *heldResponder) heldCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.held)
+}
+
+func (h *heldResponder) releaseAll() {
+	h.mu.Lock()
+	held := h.held
+	h.held = nil
+	conn := h.conn
+	h.mu.Unlock()
+	for _, msg := range held {
+		conn.send(NewMessageWithRequestID("test_response", msg.RequestID, map[string]interface{}{}))
+	}
+}
+
+func (h *heldResponder) releaseOne() {
+	h.mu.Lock()
+	if len(h.held) == 0 {
+		h.mu.Unlock()
+		return
+	}
+	msg := h.held[0]
+	h.held = h.held[1:]
+	conn := h.conn
+	h.mu.Unlock()
+	conn.send(NewMessageWithRequestID("test_response", msg.RequestID, map[string]interface{}{}))
+}
+
+func (h *heldResponder) order() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.received...)
+}
+
+func TestSendRequestQueueFull(t *testing.T) {
+	responder := &heldResponder{}
+	server := newStubServer(t, responder.handle)
+	client := connectStubClient(t, server)
+
+	const depth = 3
+	client.SetMaxPendingRequests(depth)
+	client.SetBlockOnQueueFull(false)
+
+	errs := make(chan error, depth)
+	for i := 0; i < depth; i++ {
+		go func() {
+			_, err := client.SendRequest(NewMessage("test_request", nil))
+			errs <- err
+		}()
+	}
+
+	waitFor(t, func() bool { return client.PendingRequestCount() == depth }, "queue to fill")
+
+	// Every request beyond the depth must be rejected immediately
+	for i := 0; i < 5; i++ {
+		_, err := client.SendRequest(NewMessage("test_request", nil))
+		if !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("expected ErrQueueFull, got %v", err)
+		}
+		var socketErr *SocketError
+		if !errors.As(err, &socketErr) || socketErr.Code != "QUEUE_FULL" {
+			t.Fatalf("expected QUEUE_FULL socket error, got %v", err)
+		}
+	}
+
+	waitFor(t, func() bool { return responder.heldCount() == depth }, "server to receive requests")
+	responder.releaseAll()
+
+	for i := 0; i < depth; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if got := client.PendingRequestCount(); got != 0 {
+		t.Fatalf("expected no pending requests, got %d", got)
+	}
+
+	// Capacity is available again
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.SendRequest(NewMessage("test_request", nil))
+		done <- err
+	}()
+	waitFor(t, func() bool { return responder.heldCount() == 1 }, "follow-up request")
+	responder.releaseAll()
+	if err := <-done; err != nil {
+		t.Fatalf("follow-up request failed: %v", err)
+	}
+}
+
+func TestSendRequestQueueBlocksAndDrainsInOrder(t *testing.T) {
+	responder := &heldResponder{}
+	server := newStubServer(t, responder.handle)
+	client := connectStubClient(t, server)
+
+	const depth = 2
+	const total = 6
+	client.SetMaxPendingRequests(depth)
+	client.SetBlockOnQueueFull(true)
+
+	errs := make(chan error, total)
+	for i := 0; i < total; i++ {
+		seq := string(rune('a' + i))
+		go func() {
+			_, err := client.SendRequest(NewMessage("test_request", map[string]interface{}{"seq": seq}))
+			errs <- err
+		}()
+
+		// Start the next request only after this one is in flight or queued,
+		// so the expected order is well defined
+		want := i + 1
+		waitFor(t, func() bool {
+			return client.PendingRequestCount()+client.requestQueue.queued() == want
+		}, "request to be queued")
+	}
+
+	if got := client.PendingRequestCount(); got != depth {
+		t.Fatalf("expected %d pending requests, got %d", depth, got)
+	}
+	if got := client.requestQueue.queued(); got != total-depth {
+		t.Fatalf("expected %d queued requests, got %d", total-depth, got)
+	}
+
+	// Release requests one at a time so each freed slot admits exactly one
+	// queued request
+	for completed := 0; completed < total; {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			completed++
+		case <-time.After(10 * time.Millisecond):
+			if client.PendingRequestCount() > depth {
+				t.Fatalf("pending requests exceeded depth: %d", client.PendingRequestCount())
+			}
+			responder.releaseOne()
+		}
+	}
+
+	order := responder.order()
+	if len(order) != total {
+		t.Fatalf("expected %d requests at server, got %d", total, len(order))
+	}
+	for i, seq := range order {
+		if want := string(rune('a' + i)); seq != want {
+			t.Fatalf("requests drained out of order: %v", order)
+		}
+	}
+}
+
+func TestSendRequestQueueBlockedRequestAbortsOnClose(t *testing.T) {
+	responder := &heldResponder{}
+	server := newStubServer(t, responder.handle)
+	client := connectStubClient(t, server)
+
+	client.SetMaxPendingRequests(1)
+	client.SetBlockOnQueueFull(true)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.SendRequest(NewMessage("test_request", nil))
+		first <- err
+	}()
+	waitFor(t, func() bool { return client.PendingRequestCount() == 1 }, "first request")
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := client.SendRequest(NewMessage("test_request", nil))
+		second <- err
+	}()
+	waitFor(t, func() bool { return client.requestQueue.queued() == 1 }, "second request to be queued")
+
+	_ = client.Close()
+
+	var socketErr *SocketError
+	if err := <-second; !errors.As(err, &socketErr) || socketErr.Code != "CONNECTION_CLOSED" {
+		t.Fatalf("expected CONNECTION_CLOSED, got %v", err)
+	}
+	if got := client.requestQueue.queued(); got != 0 {
+		t.Fatalf("aborted request should leave the queue, %d still queued", got)
+	}
+	<-first
+}
+
+func TestClientRefusesUnadvertisedCapability(t *testing.T) {
+	var received atomic.Int32
+	server := startStubServer(t, &stubServer{
+		protocolCapabilities: []string{"config_get", "batch", CapabilityBatching},
+		handler: func(conn *stubConn, msg *Message) {
+			received.Add(1)
+			conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{}))
+		},
+	})
+	client := connectStubClient(t, server)
+
+	want := []string{"batch", "batching", "config_get"}
+	if got := client.ServerCapabilities(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected capabilities %v, got %v", want, got)
+	}
+	if !client.HasCapability("config_get") || client.HasCapability("context_dir_list") {
+		t.Fatalf("unexpected HasCapability results")
+	}
+
+	_, err := client.ListContextDirs(context.Background())
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	var socketErr *SocketError
+	if !errors.As(err, &socketErr) || socketErr.Details != "context_dir_list" {
+		t.Errorf("expected the message type in the error details, got %v", err)
+	}
+
+	_, err = client.Batch(context.Background(), Request{Type: "config_get"}, Request{Type: "context_dir_list"})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported for the batch, got %v", err)
+	}
+
+	// Advisory acknowledgments are skipped instead of failing
+	if err := client.SendAuthorizationAck("auth-1"); err != nil {
+		t.Fatalf("expected the ack to be skipped, got %v", err)
+	}
+
+	if got := received.Load(); got != 0 {
+		t.Fatalf("unsupported requests must not reach the server, got %d", got)
+	}
+
+	if _, err := client.GetConfig(context.Background(), nil); err != nil {
+		t.Fatalf("advertised request failed: %v", err)
+	}
+	if got := received.Load(); got != 1 {
+		t.Fatalf("expected the advertised request to reach the server, got %d", got)
+	}
+}
+
+func TestClientWithoutCapabilitiesAssumesSupport(t *testing.T) {
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{}))
+	})
+	client := connectStubClient(t, server)
+
+	if caps := client.ServerCapabilities(); caps != nil {
+		t.Fatalf("expected no capabilities from an older server, got %v", caps)
+	}
+	if _, err := client.ListContextDirs(context.Background()); err != nil {
+		t.Fatalf("expected request to be sent, got %v", err)
+	}
+}
+
+func TestClientRoutesReasoningSeparately(t *testing.T) {
+	server := newStubServer(t, func(conn *stubConn, msg *Message) {
+		if msg.Type != "chat_send" {
+			return
+		}
+		conn.send(NewMessage("reasoning", map[string]interface{}{"session_id": "s1", "content": "thinking"}))
+		conn.send(NewMessage("chat_message", map[string]interface{}{"role": "assistant", "content": "answer", "is_final": true}))
+		conn.send(NewMessageWithRequestID("chat_send", msg.RequestID, map[string]interface{}{"success": true}))
+	})
+	client := connectStubClient(t, server)
+
+	reasoning := make(chan ReasoningMessage, 1)
+	chat := make(chan ChatMessage, 1)
+	client.SetReasoningCallback(func(msg ReasoningMessage) { reasoning <- msg })
+	client.SetChatMessageCallback(func(msg ChatMessage) { chat <- msg })
+
+	if _, err := client.SendRequest(NewMessage("chat_send", map[string]interface{}{"content": "hi"})); err != nil {
+		t.Fatalf("chat_send failed: %v", err)
+	}
+
+	select {
+	case msg := <-reasoning:
+		if msg.Content != "thinking" || msg.SessionID != "s1" {
+			t.Fatalf("unexpected reasoning: %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reasoning")
+	}
+	select {
+	case msg := <-chat:
+		if msg.Content != "answer" {
+			t.Fatalf("expected only the answer in the chat message, got %q", msg.Content)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the chat message")
+	}
+}
@@ -0,0 +1,77 @@
+package socketclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+const (
+	// CapabilityCompression is advertised during authentication when the
+	// client can send and receive compressed message payloads
+	CapabilityCompression = "compression"
+
+	// CompressionGzip is the only supported compression algorithm
+	CompressionGzip = "gzip"
+
+	// CompressionThreshold is the minimum encoded size of a message's data
+	// before it is compressed
+	CompressionThreshold = 8 * 1024
+)
+
+// compressMessage returns a copy of msg with its data gzip-compressed and
+// base64-encoded into Payload if the data exceeds CompressionThreshold.
+// Smaller messages are returned unchanged.
+func compressMessage(msg *Message) (*Message, error) {
+	if len(msg.Data) < CompressionThreshold || msg.Compression != "" {
+		return msg, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(msg.Data); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+
+	compressed := *msg
+	compressed.Data = nil
+	compressed.Compression = CompressionGzip
+	compressed.Payload = base64.StdEncoding.EncodeToString(buf.Bytes())
+	return &compressed, nil
+}
+
+// decompressMessage restores the data of a compressed message in place
+func decompressMessage(msg *Message) error {
+	if msg.Compression == "" {
+		return nil
+	}
+	if msg.Compression != CompressionGzip {
+		return fmt.Errorf("unsupported compression: %s", msg.Compression)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("failed to decompress payload: %w", err)
+	}
+
+	msg.Data = data
+	msg.Compression = ""
+	msg.Payload = ""
+	return nil
+}
@@ -0,0 +1,92 @@
+package socketclient
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// echoHandler answers each request with its own data, compressing large responses
+func echoHandler(compress bool) func(conn *stubConn, msg *Message) {
+	return func(conn *stubConn, msg *Message) {
+		resp := NewMessageWithRequestID(msg.Type, msg.RequestID, nil)
+		resp.Data = msg.Data
+		if compress {
+			compressed, err := compressMessage(resp)
+			if err == nil {
+				resp = compressed
+			}
+		}
+		conn.send(resp)
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	server := startStubServer(t, &stubServer{
+		handler:      echoHandler(true),
+		capabilities: []string{CapabilityCompression},
+	})
+	client := connectStubClient(t, server)
+
+	if !client.IsCompressionActive() {
+		t.Fatal("expected compression to be negotiated")
+	}
+
+	content := strings.Repeat("large tool result line\n", 4096)
+	resp, err := client.SendRequest(NewMessage("echo", map[string]interface{}{"content": content}))
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+
+	var data struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Content != content {
+		t.Fatalf("payload corrupted in round trip (got %d bytes, want %d)", len(data.Content), len(content))
+	}
+	if resp.Compression != "" || resp.Payload != "" {
+		t.Error("expected compression fields to be cleared after decompression")
+	}
+	if got := server.compressedFrames.Load(); got != 1 {
+		t.Errorf("expected 1 compressed frame at server, got %d", got)
+	}
+
+	// Small messages stay uncompressed
+	if _, err := client.SendRequest(NewMessage("echo", map[string]interface{}{"content": "small"})); err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	if got := server.compressedFrames.Load(); got != 1 {
+		t.Errorf("small message should not be compressed, got %d compressed frames", got)
+	}
+}
+
+func TestCompressionNotNegotiated(t *testing.T) {
+	server := newStubServer(t, echoHandler(false))
+	client := connectStubClient(t, server)
+
+	if client.IsCompressionActive() {
+		t.Fatal("compression must not be used without server support")
+	}
+
+	content := strings.Repeat("x", 4*CompressionThreshold)
+	resp, err := client.SendRequest(NewMessage("echo", map[string]interface{}{"content": content}))
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	if !strings.Contains(string(resp.Data), content) {
+		t.Fatal("payload corrupted in round trip")
+	}
+	if got := server.compressedFrames.Load(); got != 0 {
+		t.Errorf("expected plaintext frames, got %d compressed", got)
+	}
+}
+
+func TestDecompressMessageRejectsUnknownAlgorithm(t *testing.T) {
+	msg := &Message{Type: "test", Compression: "zstd", Payload: "AAAA"}
+	if err := decompressMessage(msg); err == nil {
+		t.Fatal("expected error for unsupported compression")
+	}
+}
@@ -0,0 +1,116 @@
+package socketclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// ServerDescriptor describes a running socket server. Servers write it to the
+// discovery directory on start, so clients can find the socket without
+// knowing its path.
+type ServerDescriptor struct {
+	SocketPath      string    `json:"socket_path"`
+	PID             int       `json:"pid"`
+	ProtocolVersion string    `json:"protocol_version"`
+	Capabilities    []string  `json:"capabilities"`
+	StartedAt       time.Time `json:"started_at"`
+}
+
+// DefaultDiscoveryDir returns the per-user directory of server descriptors:
+// $XDG_RUNTIME_DIR/scriptschnell if set, otherwise scriptschnell/servers in
+// the user cache directory
+func DefaultDiscoveryDir() (string, error) {
+	if runtimeDir := strings.TrimSpace(os.Getenv("XDG_RUNTIME_DIR")); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "scriptschnell"), nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine discovery directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "scriptschnell", "servers"), nil
+}
+
+// WriteServerDescriptor writes the descriptor to dir as <pid>.json and returns
+// its path
+func WriteServerDescriptor(dir string, desc ServerDescriptor) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create discovery directory: %w", err)
+	}
+	data, err := json.MarshalIndent(desc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode server descriptor: %w", err)
+	}
+
+	path := filepath.Join(dir, strconv.Itoa(desc.PID)+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write server descriptor: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to write server descriptor: %w", err)
+	}
+	return path, nil
+}
+
+// Discover returns the running servers of the current user, newest first
+func Discover() ([]ServerDescriptor, error) {
+	dir, err := DefaultDiscoveryDir()
+	if err != nil {
+		return nil, err
+	}
+	return DiscoverIn(dir)
+}
+
+// DiscoverIn returns the servers described in dir, newest first. Stale
+// descriptors, whose process is gone or whose socket no longer exists, are
+// ignored.
+func DiscoverIn(dir string) ([]ServerDescriptor, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read discovery directory: %w", err)
+	}
+
+	var servers []ServerDescriptor
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Debug("Skipping server descriptor %s: %v", path, err)
+			continue
+		}
+		var desc ServerDescriptor
+		if err := json.Unmarshal(data, &desc); err != nil || desc.SocketPath == "" || desc.PID <= 0 {
+			logger.Debug("Skipping invalid server descriptor %s", path)
+			continue
+		}
+		if !processAlive(desc.PID) {
+			logger.Debug("Skipping stale server descriptor %s (process %d is gone)", path, desc.PID)
+			continue
+		}
+		if _, err := os.Stat(desc.SocketPath); err != nil {
+			logger.Debug("Skipping stale server descriptor %s: %v", path, err)
+			continue
+		}
+		servers = append(servers, desc)
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].StartedAt.After(servers[j].StartedAt)
+	})
+	return servers, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package socketclient
+
+import "os"
+
+// processAlive reports whether a process with the PID exists
+func processAlive(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
@@ -0,0 +1,112 @@
+package socketclient
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiscoverInFindsLiveServer(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(t.TempDir(), "live.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+
+	desc := ServerDescriptor{
+		SocketPath:      socketPath,
+		PID:             os.Getpid(),
+		ProtocolVersion: "1.0.0",
+		Capabilities:    []string{"sessions", "chat"},
+		StartedAt:       time.Now().UTC().Truncate(time.Second),
+	}
+	path, err := WriteServerDescriptor(dir, desc)
+	if err != nil {
+		t.Fatalf("WriteServerDescriptor failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected descriptor with mode 0600, got %v (err %v)", info, err)
+	}
+
+	servers, err := DiscoverIn(dir)
+	if err != nil {
+		t.Fatalf("DiscoverIn failed: %v", err)
+	}
+	if len(servers) != 1 {
+		t.Fatalf("expected 1 server, got %+v", servers)
+	}
+	got := servers[0]
+	if got.SocketPath != socketPath || got.PID != desc.PID || got.ProtocolVersion != "1.0.0" || len(got.Capabilities) != 2 || !got.StartedAt.Equal(desc.StartedAt) {
+		t.Errorf("unexpected descriptor %+v", got)
+	}
+}
+
+func TestDiscoverInIgnoresStaleDescriptors(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(t.TempDir(), "stale.sock")
+	if err := os.WriteFile(socketPath, nil, 0600); err != nil {
+		t.Fatalf("failed to create socket placeholder: %v", err)
+	}
+
+	// A process that has exited and been reaped
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run helper process: %v", err)
+	}
+	if _, err := WriteServerDescriptor(dir, ServerDescriptor{SocketPath: socketPath, PID: cmd.Process.Pid}); err != nil {
+		t.Fatalf("WriteServerDescriptor failed: %v", err)
+	}
+
+	// A live process whose socket is gone
+	if _, err := WriteServerDescriptor(dir, ServerDescriptor{SocketPath: filepath.Join(dir, "missing.sock"), PID: os.Getpid()}); err != nil {
+		t.Fatalf("WriteServerDescriptor failed: %v", err)
+	}
+
+	servers, err := DiscoverIn(dir)
+	if err != nil {
+		t.Fatalf("DiscoverIn failed: %v", err)
+	}
+	if len(servers) != 0 {
+		t.Fatalf("expected stale descriptors to be ignored, got %+v", servers)
+	}
+
+	if servers, err := DiscoverIn(filepath.Join(dir, "does-not-exist")); err != nil || len(servers) != 0 {
+		t.Errorf("expected no servers for a missing directory, got %+v (err %v)", servers, err)
+	}
+}
+
+func TestDiscoverUsesRuntimeDir(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	dir, err := DefaultDiscoveryDir()
+	if err != nil {
+		t.Fatalf("DefaultDiscoveryDir failed: %v", err)
+	}
+	if want := filepath.Join(runtimeDir, "scriptschnell"); dir != want {
+		t.Fatalf("expected discovery dir %s, got %s", want, dir)
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "live.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+	if _, err := WriteServerDescriptor(dir, ServerDescriptor{SocketPath: socketPath, PID: os.Getpid()}); err != nil {
+		t.Fatalf("WriteServerDescriptor failed: %v", err)
+	}
+
+	servers, err := Discover()
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(servers) != 1 || servers[0].SocketPath != socketPath {
+		t.Errorf("expected the server in the runtime dir, got %+v", servers)
+	}
+}
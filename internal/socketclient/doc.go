@@ -103,6 +103,17 @@
 //	    log.Fatal(err)
 //	}
 //
+// # Context Directories
+//
+// Context directories of the active workspace are persisted by the server:
+//
+//	// Directory must exist and be readable
+//	err = client.AddContextDir(ctx, "/usr/share/doc/python3")
+//
+//	dirs, err := client.ListContextDirs(ctx)
+//
+//	err = client.RemoveContextDir(ctx, "/usr/share/doc/python3")
+//
 // # Message Protocol
 //
 // The client implements the full message protocol defined in docs/unix-socket-protocol.md:
@@ -114,8 +125,10 @@
 //   - Question dialogs (question_request, question_response)
 //   - Progress updates (progress)
 //   - Configuration (config_get, config_set)
-//   - Workspace management (workspace_list, workspace_set)
-//   - Session persistence (session_save, session_load)
+//   - Workspace management (workspace_list, workspace_set, workspace_audit)
+//   - Context directories (context_dir_add, context_dir_remove, context_dir_list)
+//   - Session persistence (session_save, session_load, session_rename, session_duplicate)
+//   - Request batching (batch)
 //   - Connection lifecycle (ping, pong, close, closed)
 //
 // # Authorization Flow
@@ -204,6 +217,54 @@
 //   - Restores session attachment if previously attached
 //   - Re-subscribes to message callbacks
 //
+// If the server still considers the session attached to the lost connection,
+// reattaching fails with ErrSessionBusy. Opt in to reclaiming it:
+//
+//	client.SetReconnectTakeover(true)
+//
+// # Batching
+//
+// Several requests can be sent in a single round trip. Responses are returned
+// in request order and failures are reported per entry:
+//
+//	responses, err := client.Batch(ctx,
+//	    socketclient.Request{Type: "config_get"},
+//	    socketclient.Request{Type: "session_list", Data: map[string]interface{}{"workspace": dir}},
+//	)
+//	for _, resp := range responses {
+//	    if resp.Error != nil {
+//	        fmt.Printf("%s failed: %v\n", resp.Type, resp.Error)
+//	    }
+//	}
+//
+// # Compression
+//
+// Large messages (more than 8 KiB of data) are gzip-compressed if the server
+// supports it. Compression is negotiated during authentication and falls back
+// to plaintext otherwise:
+//
+//	// Disable before connecting, e.g. for local-only sockets
+//	client.SetCompressionEnabled(false)
+//
+//	// Check whether compression is in use
+//	fmt.Println(client.IsCompressionActive())
+//
+// # Request Queue
+//
+// The number of requests awaiting a response is bounded to protect against
+// unbounded memory growth when the server is slow:
+//
+//	// Allow at most 16 requests in flight (0 = unlimited)
+//	client.SetMaxPendingRequests(16)
+//
+//	// Fail with ErrQueueFull instead of waiting for a free slot
+//	client.SetBlockOnQueueFull(false)
+//
+//	fmt.Printf("Pending: %d\n", client.PendingRequestCount())
+//
+// Blocked requests are admitted in the order they were issued and give up
+// after the request timeout or when the client is closed.
+//
 // # Graceful Shutdown
 //
 // Always disconnect the client when done:
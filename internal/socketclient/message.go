@@ -14,6 +14,11 @@ type Message struct {
 	Data      json.RawMessage `json:"data,omitempty"`
 	Timestamp string          `json:"timestamp,omitempty"`
 	Error     *ErrorInfo      `json:"error,omitempty"`
+
+	// Compression names the algorithm used for Payload (empty = uncompressed)
+	Compression string `json:"compression,omitempty"`
+	// Payload holds the base64-encoded compressed data if Compression is set
+	Payload string `json:"payload,omitempty"`
 }
 
 // ErrorInfo contains error details
@@ -81,16 +86,30 @@ func ParseMessage(data string) (*Message, error) {
 	if err := json.Unmarshal([]byte(data), &msg); err != nil {
 		return nil, err
 	}
+	if err := decompressMessage(&msg); err != nil {
+		return nil, err
+	}
 	return &msg, nil
 }
 
 // SendRequest sends a request and waits for a response
 func (c *Client) SendRequest(msg *Message) (*Message, error) {
+	// Refuse features the server did not advertise before sending
+	if err := c.checkCapability(msg.Type); err != nil {
+		return nil, err
+	}
+
 	// Generate request ID if not provided
 	if msg.RequestID == "" {
 		msg.RequestID = uuid.New().String()
 	}
 
+	// Reserve a slot in the request queue
+	if err := c.requestQueue.acquire(c.stopCh, c.config.RequestTimeout); err != nil {
+		return nil, err
+	}
+	defer c.requestQueue.release()
+
 	// Create response channel
 	respCh := make(chan *Message, 1)
 
@@ -102,9 +121,12 @@ func (c *Client) SendRequest(msg *Message) (*Message, error) {
 	// Cleanup function
 	defer func() {
 		c.requestMu.Lock()
-		delete(c.pendingRequests, msg.RequestID)
+		// Close() may already have closed and dropped the channel
+		if _, ok := c.pendingRequests[msg.RequestID]; ok {
+			delete(c.pendingRequests, msg.RequestID)
+			close(respCh)
+		}
 		c.requestMu.Unlock()
-		close(respCh)
 	}()
 
 	// Send message
@@ -118,7 +140,10 @@ func (c *Client) SendRequest(msg *Message) (*Message, error) {
 
 	// Wait for response
 	select {
-	case resp := <-respCh:
+	case resp, ok := <-respCh:
+		if !ok {
+			return nil, NewSocketError("CONNECTION_CLOSED", "Client is closed", "")
+		}
 		// Check for error response
 		if resp.Error != nil {
 			return nil, NewSocketError(resp.Error.Code, resp.Error.Message, resp.Error.Details)
@@ -133,6 +158,10 @@ func (c *Client) SendRequest(msg *Message) (*Message, error) {
 
 // SendMessage sends a message without waiting for a response
 func (c *Client) SendMessage(msg *Message) error {
+	if err := c.checkCapability(msg.Type); err != nil {
+		return err
+	}
+
 	// Generate request ID if not provided
 	if msg.RequestID == "" {
 		msg.RequestID = uuid.New().String()
@@ -160,6 +189,14 @@ type ChatMessage struct {
 	Timestamp  time.Time `json:"timestamp"`
 }
 
+// ReasoningMessage carries model reasoning ("thinking"), sent separately
+// from the chat content
+type ReasoningMessage struct {
+	SessionID string    `json:"session_id,omitempty"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // ToolCall represents a tool call notification
 type ToolCall struct {
 	SessionID   string                 `json:"session_id,omitempty"`
@@ -180,6 +217,13 @@ type ToolResult struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// ServerClosing is sent when the server starts draining before shutdown
+type ServerClosing struct {
+	Reason               string `json:"reason"`
+	ActiveGenerations    int    `json:"active_generations"`
+	EstimatedWaitSeconds int    `json:"estimated_wait_seconds"`
+}
+
 // ProgressData represents a progress update
 type ProgressData struct {
 	SessionID         string `json:"session_id,omitempty"`
@@ -229,6 +273,72 @@ type QuestionResponse struct {
 	Answers    map[string]string `json:"answers,omitempty"`
 }
 
+// Attachment is a file whose content the server includes as context before
+// the prompt of a chat message
+type Attachment struct {
+	Path      string `json:"path"`                 // relative to the workspace or absolute
+	StartLine int    `json:"start_line,omitempty"` // first line to include (1-based, 0 = from the start)
+	EndLine   int    `json:"end_line,omitempty"`   // last line to include (0 = to the end)
+}
+
+// ModelOverride selects the model of a single chat message. With only
+// Provider set, the server uses the provider's default model.
+type ModelOverride struct {
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// ModelCapabilities describes what a model supports. Known is false if the
+// provider reported no metadata; the other flags are unknown then.
+type ModelCapabilities struct {
+	Known             bool `json:"known"`
+	SupportsTools     bool `json:"supports_tools"`
+	SupportsVision    bool `json:"supports_vision"`
+	SupportsStreaming bool `json:"supports_streaming"`
+	SupportsJSONMode  bool `json:"supports_json_mode"`
+	MaxOutputTokens   int  `json:"max_output_tokens,omitempty"`
+}
+
+// ModelInfo represents a model of a configured provider
+type ModelInfo struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Provider        string            `json:"provider"`
+	Description     string            `json:"description,omitempty"`
+	ContextWindow   int               `json:"context_window,omitempty"`
+	MaxOutputTokens int               `json:"max_output_tokens,omitempty"`
+	Capabilities    ModelCapabilities `json:"capabilities"`
+}
+
+// SelectedModels are the models used per role; empty values are unset
+type SelectedModels struct {
+	Orchestration string `json:"orchestration,omitempty"`
+	Summarize     string `json:"summarize,omitempty"`
+	Planning      string `json:"planning,omitempty"`
+	Safety        string `json:"safety,omitempty"`
+}
+
+// ModelsList is the result of ListModels
+type ModelsList struct {
+	Providers []string       `json:"providers"`
+	Models    []ModelInfo    `json:"models"`
+	Selected  SelectedModels `json:"selected"`
+	// Session holds the overrides of the attached session, nil if detached.
+	// Empty values use the global selection.
+	Session *SelectedModels `json:"session,omitempty"`
+}
+
+// ModelSelection selects the model of a role. With only Provider set, the
+// server uses the provider's default model. Role defaults to
+// "orchestration"; Scope defaults to "session" when attached and supported
+// by the role, "global" otherwise.
+type ModelSelection struct {
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+}
+
 // SessionInfo represents session information
 type SessionInfo struct {
 	SessionID      string           `json:"session_id"`
@@ -237,6 +347,7 @@ type SessionInfo struct {
 	CreatedAt      string           `json:"created_at"`
 	UpdatedAt      string           `json:"updated_at"`
 	MessageCount   int              `json:"message_count"`
+	LastMessageAt  string           `json:"last_message_at,omitempty"` // RFC3339 timestamp of the newest message
 	Status         string           `json:"status"`
 	TotalTokens    int64            `json:"total_tokens,omitempty"`
 	CachedTokens   int64            `json:"cached_tokens,omitempty"`
@@ -244,6 +355,55 @@ type SessionInfo struct {
 	MessageHistory []MessageHistory `json:"message_history,omitempty"`
 }
 
+// SessionState represents the generation state of the attached session
+type SessionState struct {
+	Generating    bool   `json:"generating"`
+	QueuedPrompts int    `json:"queued_prompts"`
+	CurrentModel  string `json:"current_model"`
+}
+
+// CompactionResult reports a compaction requested with CompactChat
+type CompactionResult struct {
+	CompactedMessages int `json:"compacted_messages"` // Messages replaced by the summary
+	FreedPercent      int `json:"freed_percent"`      // Share of the context freed
+}
+
+// Background job states reported in JobInfo
+const (
+	JobStateRunning   = "running"
+	JobStateStopping  = "stopping"
+	JobStateCompleted = "completed"
+)
+
+// JobInfo describes a background program tracked by the attached session
+type JobInfo struct {
+	JobID         string   `json:"job_id"`
+	Command       string   `json:"command"`
+	Type          string   `json:"type,omitempty"`
+	WorkingDir    string   `json:"working_dir,omitempty"`
+	PID           int      `json:"pid,omitempty"`
+	State         string   `json:"state"`
+	ExitCode      int      `json:"exit_code"`
+	StartedAt     string   `json:"started_at,omitempty"`
+	StopRequested bool     `json:"stop_requested,omitempty"`
+	LastSignal    string   `json:"last_signal,omitempty"`
+	Stdout        []string `json:"stdout,omitempty"`
+	Stderr        []string `json:"stderr,omitempty"`
+}
+
+// ProcessInfo describes a running process of a background job. Orphaned
+// processes belong to sessions no client is attached to.
+type ProcessInfo struct {
+	PID            int    `json:"pid"`
+	ProcessGroupID int    `json:"process_group_id,omitempty"`
+	JobID          string `json:"job_id"`
+	SessionID      string `json:"session_id,omitempty"`
+	Command        string `json:"command"`
+	WorkingDir     string `json:"working_dir,omitempty"`
+	StartedAt      string `json:"started_at,omitempty"`
+	Orphaned       bool   `json:"orphaned"`
+}
+
 // MessageHistory represents a message in session history
 type MessageHistory struct {
 	Role      string    `json:"role"`
@@ -271,8 +431,49 @@ type WorkspaceInfo struct {
 	CommandsApproved map[string]bool `json:"commands_approved"`
 }
 
+// AuditEntry is an event in a workspace audit log. Detail values are
+// redacted by the server.
+type AuditEntry struct {
+	Time      time.Time         `json:"time"`
+	Workspace string            `json:"workspace"`
+	SessionID string            `json:"session_id,omitempty"`
+	Type      string            `json:"type"` // session_created, tool_executed, file_written or domain_approved
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// AuditQuery selects workspace audit entries. Zero times leave the range open
+// and an empty workspace selects the connection's workspace.
+type AuditQuery struct {
+	Workspace string
+	Since     time.Time
+	Until     time.Time
+	Limit     int // most recent entries to return (0 = server maximum)
+}
+
 // ConfigValue represents a configuration value
 type ConfigValue struct {
 	Value interface{} `json:"value"`
 	Type  string      `json:"type"`
 }
+
+// ConfigSnapshot holds config values with the config version they were read
+// at; the version is required to change the config with SetConfig
+type ConfigSnapshot struct {
+	Config  map[string]interface{} `json:"config"`
+	Version uint64                 `json:"version"`
+}
+
+// Request represents a single request in a batch
+type Request struct {
+	Type string
+	Data map[string]interface{}
+}
+
+// Response represents the result of a single batched request
+type Response struct {
+	Type      string
+	RequestID string
+	Data      json.RawMessage
+	// Error is set if this request failed; other requests in the batch are unaffected
+	Error *SocketError
+}
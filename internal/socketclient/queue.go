@@ -0,0 +1,110 @@
+package socketclient
+
+import (
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by SendRequest when the maximum number of pending
+// requests is reached and the client is not configured to block.
+var ErrQueueFull = NewSocketError("QUEUE_FULL", "Too many pending requests", "")
+
+// requestQueue bounds the number of requests awaiting a response.
+// Callers that have to wait are admitted in FIFO order.
+type requestQueue struct {
+	mu       sync.Mutex
+	limit    int // 0 means unbounded
+	block    bool
+	inFlight int
+	waiters  []chan struct{}
+}
+
+// setLimit changes the maximum number of in-flight requests and admits
+// waiters that fit under the new limit
+func (q *requestQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if limit < 0 {
+		limit = 0
+	}
+	q.limit = limit
+	for len(q.waiters) > 0 && (q.limit == 0 || q.inFlight < q.limit) {
+		q.inFlight++
+		close(q.waiters[0])
+		q.waiters = q.waiters[1:]
+	}
+}
+
+// setBlock controls whether acquire blocks or fails when the queue is full
+func (q *requestQueue) setBlock(block bool) {
+	q.mu.Lock()
+	q.block = block
+	q.mu.Unlock()
+}
+
+// acquire reserves a slot for a request. It returns ErrQueueFull if the queue
+// is saturated and blocking is disabled, otherwise it waits for a slot until
+// stopCh is closed or the timeout expires.
+func (q *requestQueue) acquire(stopCh <-chan struct{}, timeout time.Duration) error {
+	q.mu.Lock()
+	if q.limit == 0 || (q.inFlight < q.limit && len(q.waiters) == 0) {
+		q.inFlight++
+		q.mu.Unlock()
+		return nil
+	}
+	if !q.block {
+		q.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+	q.mu.Unlock()
+
+	var err error
+	select {
+	case <-ready:
+		return nil
+	case <-stopCh:
+		err = NewSocketError("CONNECTION_CLOSED", "Client is closed", "")
+	case <-time.After(timeout):
+		err = NewSocketError("TIMEOUT", "Timed out waiting for a free request slot", "")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, w := range q.waiters {
+		if w == ready {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return err
+		}
+	}
+
+	// The slot was handed over while we gave up; pass it on
+	q.releaseLocked()
+	return err
+}
+
+// release frees a slot, handing it to the oldest waiter if there is one
+func (q *requestQueue) release() {
+	q.mu.Lock()
+	q.releaseLocked()
+	q.mu.Unlock()
+}
+
+func (q *requestQueue) releaseLocked() {
+	if len(q.waiters) > 0 && (q.limit == 0 || q.inFlight <= q.limit) {
+		close(q.waiters[0])
+		q.waiters = q.waiters[1:]
+		return
+	}
+	q.inFlight--
+}
+
+// queued returns the number of callers waiting for a slot
+func (q *requestQueue) queued() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters)
+}
@@ -0,0 +1,276 @@
+package socketclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sessionOwnerServer tracks exclusive session ownership per connection and
+// keeps sessions attached when a connection drops, like a server that has not
+// yet noticed the loss
+type sessionOwnerServer struct {
+	mu        sync.Mutex
+	owners    map[string]*stubConn
+	takeovers int
+}
+
+func (s *sessionOwnerServer) handle(conn *stubConn, msg *Message) {
+	var data struct {
+		SessionID string `json:"session_id"`
+	}
+	_ = json.Unmarshal(msg.Data, &data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case "session_attach":
+		if owner, ok := s.owners[data.SessionID]; ok && owner != conn {
+			resp := NewMessageWithRequestID("error", msg.RequestID, nil)
+			resp.Error = &ErrorInfo{Code: "SESSION_BUSY", Message: "Session is attached to another client"}
+			conn.send(resp)
+			return
+		}
+		s.owners[data.SessionID] = conn
+	case "session_takeover":
+		s.owners[data.SessionID] = conn
+		s.takeovers++
+	default:
+		return
+	}
+
+	conn.send(NewMessageWithRequestID(msg.Type, msg.RequestID, map[string]interface{}{
+		"session_id": data.SessionID,
+		"status":     "attached",
+	}))
+}
+
+func (s *sessionOwnerServer) state(sessionID string) (*stubConn, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.owners[sessionID], s.takeovers
+}
+
+func withFastReconnect(takeover bool) func(*Config) {
+	return func(config *Config) {
+		config.ReconnectEnabled = true
+		config.ReconnectDelay = 10 * time.Millisecond
+		config.ReconnectMaxDelay = 10 * time.Millisecond
+		config.ReconnectTakeover = takeover
+	}
+}
+
+func TestReconnectTakesOverBusySession(t *testing.T) {
+	handler := &sessionOwnerServer{owners: make(map[string]*stubConn)}
+	server := newStubServer(t, handler.handle)
+
+	client := connectStubClient(t, server, withFastReconnect(true))
+
+	if err := client.AttachSession(context.Background(), "s1"); err != nil {
+		t.Fatalf("AttachSession failed: %v", err)
+	}
+	staleOwner, _ := handler.state("s1")
+
+	// Drop the connection; the server still considers s1 attached to it
+	server.dropConnections()
+
+	waitFor(t, func() bool {
+		_, takeovers := handler.state("s1")
+		return takeovers == 1
+	}, "session takeover")
+
+	owner, _ := handler.state("s1")
+	if owner == staleOwner {
+		t.Fatal("session still owned by the stale connection")
+	}
+	waitFor(t, func() bool { return client.GetCurrentSessionID() == "s1" }, "session to be kept")
+}
+
+func TestReconnectWithoutTakeoverDropsBusySession(t *testing.T) {
+	handler := &sessionOwnerServer{owners: make(map[string]*stubConn)}
+	server := newStubServer(t, handler.handle)
+	client := connectStubClient(t, server, withFastReconnect(false))
+
+	if err := client.AttachSession(context.Background(), "s1"); err != nil {
+		t.Fatalf("AttachSession failed: %v", err)
+	}
+
+	server.dropConnections()
+
+	waitFor(t, func() bool { return client.GetCurrentSessionID() == "" }, "session to be released")
+
+	if _, takeovers := handler.state("s1"); takeovers != 0 {
+		t.Fatalf("expected no takeover, got %d", takeovers)
+	}
+	if !client.IsConnected() {
+		t.Fatal("expected client to be reconnected")
+	}
+}
+
+// pongServer answers pings until it is silenced, like a server that hangs
+// while its socket stays open
+type pongServer struct {
+	mu       sync.Mutex
+	silenced *stubConn // Connection whose pings are no longer answered
+	pings    map[*stubConn]int
+}
+
+func (s *pongServer) handle(conn *stubConn, msg *Message) {
+	if msg.Type != "ping" {
+		return
+	}
+
+	s.mu.Lock()
+	s.pings[conn]++
+	silent := conn == s.silenced
+	s.mu.Unlock()
+
+	if !silent {
+		conn.send(NewMessage("pong", nil))
+	}
+}
+
+// silence stops answering the pings of the connection that has sent some and
+// returns the number of pings it has sent so far
+func (s *pongServer) silence() (*stubConn, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.pings {
+		s.silenced = conn
+	}
+	return s.silenced, s.pings[s.silenced]
+}
+
+func (s *pongServer) connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pings)
+}
+
+func TestKeepaliveDetectsHalfOpenConnection(t *testing.T) {
+	handler := &pongServer{pings: make(map[*stubConn]int)}
+	server := newStubServer(t, handler.handle)
+
+	var lost atomic.Value
+	client := connectStubClient(t, server, withFastReconnect(false), func(config *Config) {
+		config.PingInterval = 20 * time.Millisecond
+		config.MaxMissedPongs = 2
+	})
+	client.SetConnectionLostCallback(func(err error) { lost.Store(err) })
+
+	// Answered pings keep the connection up
+	waitFor(t, func() bool { return handler.connections() == 1 }, "first ping")
+	time.Sleep(150 * time.Millisecond)
+	if err := lost.Load(); err != nil {
+		t.Fatalf("connection dropped although pings were answered: %v", err)
+	}
+
+	// The server stops answering while the socket stays open
+	silenced, answered := handler.silence()
+	waitFor(t, func() bool { return lost.Load() != nil }, "half-open detection")
+
+	var sockErr *SocketError
+	if err, _ := lost.Load().(error); !errors.As(err, &sockErr) || sockErr.Code != "CONNECTION_LOST" {
+		t.Fatalf("expected CONNECTION_LOST, got %v", lost.Load())
+	}
+
+	handler.mu.Lock()
+	unanswered := handler.pings[silenced] - answered
+	handler.mu.Unlock()
+	if unanswered > 2 {
+		t.Fatalf("expected detection after 2 missed pongs, %d pings went unanswered", unanswered)
+	}
+
+	waitFor(t, func() bool { return client.IsConnected() && handler.connections() == 2 }, "reconnect after half-open detection")
+}
+
+func TestKeepaliveDisabledForNonPositiveInterval(t *testing.T) {
+	handler := &pongServer{pings: make(map[*stubConn]int)}
+	server := newStubServer(t, handler.handle)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		client := connectStubClient(t, server, func(config *Config) {
+			config.PingInterval = interval
+		})
+		time.Sleep(50 * time.Millisecond)
+		if !client.IsConnected() {
+			t.Fatalf("interval %v: expected the client to stay connected", interval)
+		}
+		if handler.connections() != 0 {
+			t.Fatalf("interval %v: expected no pings, got pings on %d connections", interval, handler.connections())
+		}
+	}
+}
+
+func TestExponentialBackoffFollowsCurve(t *testing.T) {
+	backoff := &ExponentialBackoff{
+		InitialDelay: 100 * time.Millisecond,
+		Multiplier:   3,
+		MaxDelay:     2 * time.Second,
+	}
+	want := []time.Duration{
+		100 * time.Millisecond,
+		300 * time.Millisecond,
+		900 * time.Millisecond,
+		2 * time.Second,
+		2 * time.Second,
+	}
+	for attempt, expected := range want {
+		if got := backoff.Delay(attempt); got != expected {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, expected, got)
+		}
+	}
+	if got := backoff.Delay(1000); got != 2*time.Second {
+		t.Errorf("expected large attempts to stay capped, got %v", got)
+	}
+
+	// Jitter subtracts up to the configured fraction of the curve
+	backoff.Jitter = 0.25
+	backoff.random = func() float64 { return 0 }
+	if got := backoff.Delay(2); got != 900*time.Millisecond {
+		t.Errorf("expected no reduction for random 0, got %v", got)
+	}
+	backoff.random = func() float64 { return 1 }
+	if got := backoff.Delay(2); got != 675*time.Millisecond {
+		t.Errorf("expected the full reduction for random 1, got %v", got)
+	}
+
+	backoff.random = nil
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 200; i++ {
+		for attempt, curve := range want {
+			got := backoff.Delay(attempt)
+			if got < curve*3/4 || got > curve {
+				t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt, got, curve*3/4, curve)
+			}
+			if attempt == 4 {
+				seen[got] = true
+			}
+		}
+	}
+	if len(seen) < 2 {
+		t.Error("expected jitter to spread the delays")
+	}
+}
+
+func TestDefaultReconnectStrategyDoublesDelay(t *testing.T) {
+	client := &Client{config: &Config{ReconnectDelay: time.Second, ReconnectMaxDelay: 5 * time.Second}}
+
+	strategy := client.reconnectStrategy()
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := strategy.Delay(attempt); got != expected {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, expected, got)
+		}
+	}
+
+	custom := &ExponentialBackoff{InitialDelay: time.Millisecond}
+	client.SetReconnectStrategy(custom)
+	if client.reconnectStrategy() != custom {
+		t.Error("expected the configured strategy to be used")
+	}
+}
@@ -0,0 +1,208 @@
+package socketclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Directions of recorded frames
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// redactedValue replaces secrets in recordings
+const redactedValue = "[REDACTED]"
+
+// redactedFields are data fields removed from recorded messages
+var redactedFields = []string{"token", "auth_token", "password"}
+
+// RecordedFrame is a protocol message as written to a recording, one JSON
+// object per line. Messages are recorded uncompressed.
+type RecordedFrame struct {
+	Direction string    `json:"direction"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   *Message  `json:"message"`
+}
+
+// recorder appends frames to a writer
+type recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// record writes a frame with secrets redacted; errors are ignored so a
+// broken recording never affects the connection
+func (r *recorder) record(direction string, msg *Message) {
+	line, err := json.Marshal(RecordedFrame{
+		Direction: direction,
+		Timestamp: time.Now().UTC(),
+		Message:   redactMessage(msg),
+	})
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.w.Write(append(line, '\n'))
+}
+
+// redactMessage returns a copy of msg without auth tokens and passwords
+func redactMessage(msg *Message) *Message {
+	if len(msg.Data) == 0 {
+		return msg
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return msg
+	}
+
+	redacted := false
+	for _, field := range redactedFields {
+		if _, ok := data[field]; ok {
+			data[field] = redactedValue
+			redacted = true
+		}
+	}
+	if !redacted {
+		return msg
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return msg
+	}
+	clone := *msg
+	clone.Data = raw
+	return &clone
+}
+
+// EnableRecording appends every message sent or received from now on to w,
+// with direction and timestamp. Auth tokens are redacted. Passing nil stops
+// recording. Writes to w are serialized.
+func (c *Client) EnableRecording(w io.Writer) {
+	if w == nil {
+		c.recorder.Store(nil)
+		return
+	}
+	c.recorder.Store(&recorder{w: w})
+}
+
+// recordFrame records msg if recording is enabled
+func (c *Client) recordFrame(direction string, msg *Message) {
+	if r := c.recorder.Load(); r != nil {
+		r.record(direction, msg)
+	}
+}
+
+// ReadRecording parses a recording written by EnableRecording
+func ReadRecording(r io.Reader) ([]RecordedFrame, error) {
+	var frames []RecordedFrame
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var frame RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, fmt.Errorf("invalid frame on line %d: %w", lineNo, err)
+		}
+		if frame.Message == nil {
+			return nil, fmt.Errorf("frame on line %d has no message", lineNo)
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return frames, nil
+}
+
+// ReplayClient feeds the received messages of a recording to the callbacks
+// of a client without a server, e.g. to reproduce frontend bugs offline.
+// Callbacks are registered with the usual Set*Callback methods; messages the
+// client would send in reply are discarded.
+type ReplayClient struct {
+	*Client
+
+	frames   []RecordedFrame
+	realtime bool
+}
+
+// NewReplayClient creates a replay client for a recording
+func NewReplayClient(r io.Reader) (*ReplayClient, error) {
+	frames, err := ReadRecording(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+	config.SocketPath = "replay"
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayClient{Client: client, frames: frames}, nil
+}
+
+// Frames returns the frames of the recording, including sent ones
+func (r *ReplayClient) Frames() []RecordedFrame {
+	return r.frames
+}
+
+// SetRealtime makes Replay wait the recorded time between messages
+func (r *ReplayClient) SetRealtime(realtime bool) {
+	r.realtime = realtime
+}
+
+// Replay dispatches the received messages of the recording in order. It
+// returns early with the context's error if ctx is done.
+func (r *ReplayClient) Replay(ctx context.Context) error {
+	var last time.Time
+	for _, frame := range r.frames {
+		if frame.Direction != DirectionReceived {
+			continue
+		}
+
+		if r.realtime && !last.IsZero() {
+			if wait := frame.Timestamp.Sub(last); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		last = frame.Timestamp
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msg := *frame.Message
+		r.routeMessage(&msg)
+		r.discardReplies()
+	}
+	return nil
+}
+
+// discardReplies drops the messages queued in reply to replayed messages
+func (r *ReplayClient) discardReplies() {
+	for {
+		select {
+		case <-r.outgoing:
+		default:
+			return
+		}
+	}
+}
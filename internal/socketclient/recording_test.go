@@ -0,0 +1,129 @@
+package socketclient
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordingReplaysIntoCallbacks(t *testing.T) {
+	s := newStubServer(t, func(conn *stubConn, msg *Message) {
+		if msg.Type != "chat_send" {
+			return
+		}
+		conn.send(NewMessage("tool_call", map[string]interface{}{
+			"tool_name": "read_file", "tool_id": "tool-1", "parameters": map[string]interface{}{"path": "go.mod"},
+		}))
+		conn.send(NewMessage("chat_message", map[string]interface{}{
+			"role": "assistant", "content": "hello", "is_final": true,
+		}))
+		conn.send(NewMessageWithRequestID("chat_send", msg.RequestID, map[string]interface{}{"success": true}))
+	})
+
+	config := DefaultConfig()
+	config.SocketPath = s.path
+	config.ReconnectEnabled = false
+	config.RequestTimeout = 5 * time.Second
+	config.AuthToken = "secret-token"
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var recording bytes.Buffer
+	client.EnableRecording(&recording)
+
+	received := make(chan ChatMessage, 1)
+	client.SetChatMessageCallback(func(msg ChatMessage) { received <- msg })
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if _, err := client.SendRequest(NewMessage("chat_send", map[string]interface{}{"content": "hi"})); err != nil {
+		t.Fatalf("chat_send failed: %v", err)
+	}
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the chat message")
+	}
+	_ = client.Close()
+
+	if strings.Contains(recording.String(), "secret-token") {
+		t.Fatalf("expected the auth token to be redacted:\n%s", recording.String())
+	}
+
+	replay, err := NewReplayClient(&recording)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+
+	var directions []string
+	for _, frame := range replay.Frames() {
+		directions = append(directions, frame.Direction+":"+frame.Message.Type)
+		if frame.Timestamp.IsZero() {
+			t.Errorf("expected a timestamp on %s", frame.Message.Type)
+		}
+	}
+	want := "sent:auth_request received:auth_response sent:chat_send received:tool_call received:chat_message received:chat_send"
+	if got := strings.Join(directions, " "); got != want {
+		t.Fatalf("unexpected frames:\n got %s\nwant %s", got, want)
+	}
+
+	var mu sync.Mutex
+	var events []string
+	replay.SetToolCallCallback(func(call ToolCall) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, "tool_call:"+call.ToolName)
+	})
+	replay.SetChatMessageCallback(func(msg ChatMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, "chat_message:"+msg.Content)
+	})
+	if err := replay.Replay(context.Background()); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(events, " "); got != "tool_call:read_file chat_message:hello" {
+		t.Errorf("unexpected replayed events: %s", got)
+	}
+}
+
+func TestReplayStopsWhenContextIsDone(t *testing.T) {
+	recording := strings.Join([]string{
+		`{"direction":"received","timestamp":"2026-01-01T00:00:00Z","message":{"type":"progress","data":{"message":"one"}}}`,
+		`{"direction":"received","timestamp":"2026-01-01T01:00:00Z","message":{"type":"progress","data":{"message":"two"}}}`,
+	}, "\n")
+	replay, err := NewReplayClient(strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+	replay.SetRealtime(true)
+
+	var seen []string
+	replay.SetProgressCallback(func(p ProgressData) { seen = append(seen, p.Message) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := replay.Replay(ctx); err == nil {
+		t.Fatal("expected the replay to stop at the deadline")
+	}
+	if len(seen) != 1 || seen[0] != "one" {
+		t.Errorf("expected only the first message before the recorded gap, got %v", seen)
+	}
+}
+
+func TestReadRecordingRejectsInvalidFrames(t *testing.T) {
+	if _, err := ReadRecording(strings.NewReader("not json\n")); err == nil {
+		t.Error("expected an invalid frame to be rejected")
+	}
+	if _, err := ReadRecording(strings.NewReader(`{"direction":"sent"}`)); err == nil {
+		t.Error("expected a frame without a message to be rejected")
+	}
+}
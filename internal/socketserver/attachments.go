@@ -0,0 +1,159 @@
+package socketserver
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxAttachments is the maximum number of attachments of a single chat_send
+	maxAttachments = 20
+	// maxAttachmentBytes is the maximum size of an attached file
+	maxAttachmentBytes = 256 * 1024
+)
+
+// buildAttachmentPrompt prepends the content of the attached files to the
+// prompt of a chat_send. Files must be inside the workspace, a context
+// directory or a landlock path of the workspace. Whole files are tracked as
+// read in the session, so they can be edited without calling read_file first.
+func (c *Client) buildAttachmentPrompt(sessionID, content string, attachments []Attachment) (string, error) {
+	if len(attachments) == 0 {
+		return content, nil
+	}
+
+	workspace := c.GetWorkspace()
+	if workspace == "" {
+		return "", fmt.Errorf("working directory not specified")
+	}
+
+	roots := []string{workspace}
+	if c.cfg != nil {
+		roots = append(roots, c.cfg.GetContextDirectories(workspace)...)
+	}
+	if c.workspaceManager != nil {
+		if ws, ok := c.workspaceManager.GetWorkspaceByPath(workspace); ok {
+			roots = append(roots, ws.LandlockRead...)
+			roots = append(roots, ws.LandlockWrite...)
+		}
+	}
+
+	prompt, fullFiles, err := formatAttachments(workspace, roots, attachments)
+	if err != nil {
+		return "", err
+	}
+
+	if c.sessionManager != nil {
+		if sess, ok := c.sessionManager.GetSession(sessionID); ok && sess != nil {
+			for path, data := range fullFiles {
+				sess.TrackFileRead(path, data)
+			}
+		}
+	}
+
+	return prompt + content, nil
+}
+
+// formatAttachments reads the attachments and renders them as delimited
+// blocks. It also returns the content of the files attached without a line
+// range, keyed by the path the client used.
+func formatAttachments(workspace string, roots []string, attachments []Attachment) (string, map[string]string, error) {
+	if len(attachments) > maxAttachments {
+		return "", nil, fmt.Errorf("at most %d attachments are allowed", maxAttachments)
+	}
+
+	var sb strings.Builder
+	fullFiles := make(map[string]string)
+	for _, att := range attachments {
+		if strings.TrimSpace(att.Path) == "" {
+			return "", nil, fmt.Errorf("attachment path is required")
+		}
+		path := filepath.Clean(strings.TrimSpace(att.Path))
+		if att.StartLine < 0 || att.EndLine < 0 || (att.EndLine > 0 && att.EndLine < att.StartLine) {
+			return "", nil, fmt.Errorf("%s: invalid line range %d-%d", path, att.StartLine, att.EndLine)
+		}
+
+		resolved, err := resolveAttachmentPath(workspace, path, roots)
+		if err != nil {
+			return "", nil, err
+		}
+
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if info.IsDir() {
+			return "", nil, fmt.Errorf("%s is a directory", path)
+		}
+		if info.Size() > maxAttachmentBytes {
+			return "", nil, fmt.Errorf("%s is larger than %d bytes", path, maxAttachmentBytes)
+		}
+
+		data, err := os.ReadFile(resolved)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return "", nil, fmt.Errorf("%s is a binary file", path)
+		}
+
+		text := string(data)
+		ranged := att.StartLine > 0 || att.EndLine > 0
+		if ranged {
+			lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+			start := att.StartLine
+			if start == 0 {
+				start = 1
+			}
+			end := att.EndLine
+			if end == 0 || end > len(lines) {
+				end = len(lines)
+			}
+			if start > len(lines) {
+				return "", nil, fmt.Errorf("%s: start line %d is past the end of the file (%d lines)", path, start, len(lines))
+			}
+			text = strings.Join(lines[start-1:end], "\n")
+			fmt.Fprintf(&sb, "<attachment path=%q lines=\"%d-%d\">\n", path, start, end)
+		} else {
+			fullFiles[path] = text
+			fmt.Fprintf(&sb, "<attachment path=%q>\n", path)
+		}
+
+		sb.WriteString(text)
+		if !strings.HasSuffix(text, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("</attachment>\n\n")
+	}
+
+	return sb.String(), fullFiles, nil
+}
+
+// resolveAttachmentPath returns the absolute path of an attachment after
+// checking (with symlinks resolved) that it lies within one of roots
+func resolveAttachmentPath(workspace, path string, roots []string) (string, error) {
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(workspace, abs)
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolvedRoot
+		}
+		rel, err := filepath.Rel(root, real)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return real, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside the workspace and its context directories", path)
+}
@@ -0,0 +1,90 @@
+package socketserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func TestBuildAttachmentPrompt(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	workingDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workingDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workingDir, "notes.txt"), []byte("one\ntwo\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionID, sess, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	c := NewClient("test-client", nil, NewHub(), sm, nil, nil, nil, nil, cfg, nil)
+	c.SetSession(sessionID, workingDir)
+
+	prompt, err := c.buildAttachmentPrompt(sessionID, "Explain this", []Attachment{
+		{Path: "main.go"},
+		{Path: "notes.txt", StartLine: 2, EndLine: 3},
+	})
+	if err != nil {
+		t.Fatalf("buildAttachmentPrompt failed: %v", err)
+	}
+
+	want := "<attachment path=\"main.go\">\npackage main\n\nfunc main() {}\n</attachment>\n\n" +
+		"<attachment path=\"notes.txt\" lines=\"2-3\">\ntwo\nthree\n</attachment>\n\n" +
+		"Explain this"
+	if prompt != want {
+		t.Errorf("prompt = %q, want %q", prompt, want)
+	}
+	if !sess.WasFileRead("main.go") {
+		t.Error("expected the whole-file attachment to be tracked as read")
+	}
+	if sess.WasFileRead("notes.txt") {
+		t.Error("expected the ranged attachment not to be tracked as read")
+	}
+
+	if prompt, err := c.buildAttachmentPrompt(sessionID, "No files", nil); err != nil || prompt != "No files" {
+		t.Errorf("expected the content unchanged without attachments, got %q, %v", prompt, err)
+	}
+
+	for _, tt := range []struct {
+		name       string
+		attachment Attachment
+		wantErr    string
+	}{
+		{"outside workspace", Attachment{Path: outside}, "outside the workspace"},
+		{"parent traversal", Attachment{Path: "../" + filepath.Base(filepath.Dir(outside))}, "outside the workspace"},
+		{"missing file", Attachment{Path: "missing.go"}, "missing.go"},
+		{"inverted range", Attachment{Path: "notes.txt", StartLine: 3, EndLine: 2}, "invalid line range"},
+		{"range past end", Attachment{Path: "notes.txt", StartLine: 10}, "past the end"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.buildAttachmentPrompt(sessionID, "x", []Attachment{tt.attachment})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package socketserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/secretdetect"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// Audit event types
+const (
+	AuditEventSessionCreated = "session_created"
+	AuditEventToolExecuted   = "tool_executed"
+	AuditEventFileWritten    = "file_written"
+	AuditEventDomainApproved = "domain_approved"
+)
+
+// maxAuditValueLength caps detail values such as tool parameters
+const maxAuditValueLength = 2000
+
+// AuditEntry is a record in a workspace audit log
+type AuditEntry struct {
+	Time      time.Time         `json:"time"`
+	Workspace string            `json:"workspace"`
+	SessionID string            `json:"session_id,omitempty"`
+	Type      string            `json:"type"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// AuditLog is an append-only log of key events (sessions created, tools
+// executed, files written, domains approved) with one JSONL file per
+// workspace. Detail values are redacted before they are written. A file
+// exceeding the size cap is rotated to a single ".1" backup.
+//
+// All methods are no-ops on a nil AuditLog, so callers don't need to check
+// whether auditing is enabled.
+type AuditLog struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	redactor *secretdetect.Redactor
+}
+
+// NewAuditLog creates an audit log writing to dir, rotating files larger than
+// maxBytes
+func NewAuditLog(dir string, maxBytes int64) *AuditLog {
+	return &AuditLog{
+		dir:      dir,
+		maxBytes: maxBytes,
+		redactor: secretdetect.NewRedactor(""),
+	}
+}
+
+// DefaultAuditLogDir returns the directory holding the workspace audit logs,
+// next to the session storage
+func DefaultAuditLogDir() (string, error) {
+	sessionsDir, err := session.GetSessionStorageDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(sessionsDir), "audit"), nil
+}
+
+// Record appends an event to the audit log of the workspace at workingDir.
+// Failures are logged, auditing never fails the audited operation.
+func (a *AuditLog) Record(workingDir, sessionID, eventType string, details map[string]string) {
+	if a == nil || workingDir == "" {
+		return
+	}
+
+	entry := AuditEntry{
+		Time:      time.Now().UTC(),
+		Workspace: normalizeAuditWorkspace(workingDir),
+		SessionID: sessionID,
+		Type:      eventType,
+	}
+	if len(details) > 0 {
+		entry.Details = make(map[string]string, len(details))
+		for key, value := range details {
+			entry.Details[key] = a.redact(value)
+		}
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Warn("Failed to encode audit entry: %v", err)
+		return
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.append(a.path(entry.Workspace), line); err != nil {
+		logger.Warn("Failed to write audit entry for %s: %v", entry.Workspace, err)
+	}
+}
+
+// redact removes secrets from a detail value and caps its length
+func (a *AuditLog) redact(value string) string {
+	if a.redactor != nil {
+		value = a.redactor.Redact(value)
+	}
+	if len(value) > maxAuditValueLength {
+		value = value[:maxAuditValueLength] + "... (truncated)"
+	}
+	return value
+}
+
+// append writes line to path, rotating the file first if it would exceed the
+// size cap. Callers hold a.mu.
+func (a *AuditLog) append(path string, line []byte) error {
+	if err := os.MkdirAll(a.dir, 0700); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(path); err == nil && a.maxBytes > 0 && info.Size()+int64(len(line)) > a.maxBytes {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Query returns the audit entries of the workspace at workingDir recorded
+// within [since, until], oldest first. Zero times leave the range open. A
+// positive limit keeps only the most recent entries.
+func (a *AuditLog) Query(workingDir string, since, until time.Time, limit int) ([]AuditEntry, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	path := a.path(normalizeAuditWorkspace(workingDir))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var entries []AuditEntry
+	for _, file := range []string{path + ".1", path} {
+		fileEntries, err := readAuditFile(file, since, until)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// readAuditFile reads the entries of an audit file within the time range.
+// Missing files have no entries and malformed lines are skipped.
+func readAuditFile(path string, since, until time.Time) ([]AuditEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if !since.IsZero() && entry.Time.Before(since) {
+			continue
+		}
+		if !until.IsZero() && entry.Time.After(until) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (a *AuditLog) path(workspace string) string {
+	return filepath.Join(a.dir, generateWorkspaceID(workspace)+".jsonl")
+}
+
+// normalizeAuditWorkspace makes workspace paths absolute, matching the
+// workspace IDs of the workspace manager
+func normalizeAuditWorkspace(workingDir string) string {
+	if absPath, err := filepath.Abs(workingDir); err == nil {
+		return absPath
+	}
+	return filepath.Clean(workingDir)
+}
+
+// auditFileWritePath returns the path a tool call writes to, or "" if the
+// tool doesn't write files. Tools that may write several files report the
+// glob or directory they write below.
+func auditFileWritePath(toolName string, params map[string]interface{}) string {
+	switch toolName {
+	case tools.ToolNameCreateFile, tools.ToolNameReplaceFile, tools.ToolNameEditFile:
+		return tools.GetStringParam(params, "path", "")
+	case tools.ToolNameReplaceInFiles:
+		if tools.GetBoolParam(params, "preview", false) {
+			return ""
+		}
+		return filepath.Join(tools.GetStringParam(params, "path", "."), tools.GetStringParam(params, "files", ""))
+	case tools.ToolNameGoSandbox:
+		// Sandbox programs can write files anywhere below their working directory
+		return tools.GetStringParam(params, "working_dir", ".")
+	case tools.ToolNameMemory:
+		if tools.GetStringParam(params, "operation", "") != "append" {
+			return ""
+		}
+		return llm.ProjectMemoryFileName
+	case tools.ToolNameFetchDocs:
+		return tools.FetchDocsTargetDir(config.DefaultDocsDir(), params)
+	default:
+		return ""
+	}
+}
+
+// auditApprovedDomain returns the network domain an authorization request is
+// about, or "" for other authorizations
+func auditApprovedDomain(toolName string, params map[string]interface{}) string {
+	if domain := tools.GetStringParam(params, "domain", ""); domain != "" {
+		return domain
+	}
+	if toolName == tools.ToolNameWebFetch {
+		if parsed, err := url.Parse(tools.GetStringParam(params, "url", "")); err == nil {
+			return parsed.Hostname()
+		}
+	}
+	return ""
+}
+
+// encodeAuditParameters renders tool parameters for an audit entry
+func encodeAuditParameters(params map[string]interface{}) string {
+	if len(params) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Sprintf("%v", params)
+	}
+	return string(data)
+}
@@ -0,0 +1,216 @@
+package socketserver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+const auditTestSecret = "sk-abcdefghijklmnopqrstuvwxyz123456"
+
+func newAuditTestBroker(t *testing.T, workingDir string) (*MessageBroker, *AuditLog) {
+	t.Helper()
+	auditLog := NewAuditLog(t.TempDir(), 0)
+	mb := &MessageBroker{
+		session:      session.NewSession("sess-audit", workingDir),
+		pendingAuths: make(map[string]*pendingAuthorization),
+	}
+	mb.SetAuditLog(auditLog)
+	return mb, auditLog
+}
+
+func auditEntriesOfType(entries []AuditEntry, eventType string) []AuditEntry {
+	var matching []AuditEntry
+	for _, entry := range entries {
+		if entry.Type == eventType {
+			matching = append(matching, entry)
+		}
+	}
+	return matching
+}
+
+func assertNoAuditSecret(t *testing.T, entries []AuditEntry) {
+	t.Helper()
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("failed to encode entries: %v", err)
+	}
+	if strings.Contains(string(data), auditTestSecret) {
+		t.Errorf("secret leaked into the audit log: %s", data)
+	}
+}
+
+func TestAuditLogRecordsFileWrite(t *testing.T) {
+	workingDir := t.TempDir()
+	mb, auditLog := newAuditTestBroker(t, workingDir)
+
+	mb.auditToolCall(tools.ToolNameCreateFile, "call-1", map[string]interface{}{
+		"path":    "config.env",
+		"content": "OPENAI_API_KEY=" + auditTestSecret,
+	})
+	mb.auditToolResult(tools.ToolNameCreateFile, "call-1", "")
+
+	// Failed writes are executed tools, but no file was written
+	mb.auditToolCall(tools.ToolNameEditFile, "call-2", map[string]interface{}{"path": "missing.go"})
+	mb.auditToolResult(tools.ToolNameEditFile, "call-2", "file not found")
+
+	entries, err := auditLog.Query(workingDir, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	if executed := auditEntriesOfType(entries, AuditEventToolExecuted); len(executed) != 2 {
+		t.Fatalf("expected 2 tool executions, got %+v", entries)
+	}
+	written := auditEntriesOfType(entries, AuditEventFileWritten)
+	if len(written) != 1 {
+		t.Fatalf("expected 1 file write, got %+v", entries)
+	}
+	if written[0].Details["path"] != "config.env" || written[0].SessionID != "sess-audit" {
+		t.Errorf("unexpected file write entry %+v", written[0])
+	}
+
+	params := auditEntriesOfType(entries, AuditEventToolExecuted)[0].Details["parameters"]
+	if !strings.Contains(params, "[REDACTED]") {
+		t.Errorf("expected redacted tool parameters, got %q", params)
+	}
+	assertNoAuditSecret(t, entries)
+}
+
+func TestAuditFileWritePathCoversWritingTools(t *testing.T) {
+	for _, tt := range []struct {
+		tool   string
+		params map[string]interface{}
+		want   string
+	}{
+		{tools.ToolNameReplaceFile, map[string]interface{}{"path": "main.go"}, "main.go"},
+		{tools.ToolNameReplaceInFiles, map[string]interface{}{"files": "*.go", "path": "internal"}, filepath.Join("internal", "*.go")},
+		{tools.ToolNameReplaceInFiles, map[string]interface{}{"files": "*.go", "preview": true}, ""},
+		{tools.ToolNameGoSandbox, map[string]interface{}{"code": "package main"}, "."},
+		{tools.ToolNameMemory, map[string]interface{}{"operation": "append", "content": "note"}, llm.ProjectMemoryFileName},
+		{tools.ToolNameMemory, map[string]interface{}{"operation": "read"}, ""},
+		{tools.ToolNameFetchDocs, map[string]interface{}{"url": "https://example.com/guide"}, filepath.Join(config.DefaultDocsDir(), "example.com")},
+		{tools.ToolNameReadFile, map[string]interface{}{"path": "main.go"}, ""},
+	} {
+		if got := auditFileWritePath(tt.tool, tt.params); got != tt.want {
+			t.Errorf("auditFileWritePath(%s, %v) = %q, want %q", tt.tool, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestAuditLogRecordsDomainApproval(t *testing.T) {
+	workingDir := t.TempDir()
+	mb, auditLog := newAuditTestBroker(t, workingDir)
+
+	addPending := func(authID string, params map[string]interface{}) {
+		mb.pendingAuths[authID] = &pendingAuthorization{
+			toolName:   tools.ToolNameWebFetch,
+			parameters: params,
+			reason:     "fetch with key " + auditTestSecret,
+			response:   make(chan bool, 1),
+		}
+	}
+	addPending("auth-1", map[string]interface{}{"url": "https://api.example.com/v1?key=" + auditTestSecret})
+	addPending("auth-2", map[string]interface{}{"url": "https://denied.example.com/"})
+
+	if err := mb.HandleAuthorizationResponse("auth-1", true); err != nil {
+		t.Fatalf("approve failed: %v", err)
+	}
+	if err := mb.HandleAuthorizationResponse("auth-2", false); err != nil {
+		t.Fatalf("deny failed: %v", err)
+	}
+
+	entries, err := auditLog.Query(workingDir, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	approved := auditEntriesOfType(entries, AuditEventDomainApproved)
+	if len(approved) != 1 {
+		t.Fatalf("expected only the approved domain to be recorded, got %+v", entries)
+	}
+	if approved[0].Details["domain"] != "api.example.com" {
+		t.Errorf("unexpected domain approval entry %+v", approved[0])
+	}
+	if !strings.Contains(approved[0].Details["reason"], "[REDACTED]") {
+		t.Errorf("expected a redacted reason, got %q", approved[0].Details["reason"])
+	}
+	assertNoAuditSecret(t, entries)
+}
+
+func TestAuditLogQueryTimeRangeAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	workingDir := t.TempDir()
+	auditLog := NewAuditLog(dir, 400)
+
+	for i := 0; i < 10; i++ {
+		auditLog.Record(workingDir, "sess", AuditEventToolExecuted, map[string]string{"tool": "shell"})
+	}
+
+	path := auditLog.path(normalizeAuditWorkspace(workingDir))
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("missing audit log: %v", err)
+	}
+	if info.Size() > 400 {
+		t.Errorf("expected the audit log to be capped at 400 bytes, got %d", info.Size())
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected a rotated audit log: %v", err)
+	}
+
+	entries, err := auditLog.Query(workingDir, time.Time{}, time.Time{}, 2)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected the 2 most recent entries, got %d (err %v)", len(entries), err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if entries, _ := auditLog.Query(workingDir, future, time.Time{}, 0); len(entries) != 0 {
+		t.Errorf("expected no entries after %v, got %d", future, len(entries))
+	}
+	if entries, _ := auditLog.Query(workingDir, time.Time{}, future, 0); len(entries) == 0 {
+		t.Error("expected entries before the end of the range")
+	}
+}
+
+func TestWorkspaceAuditQuery(t *testing.T) {
+	workingDir := t.TempDir()
+	auditLog := NewAuditLog(t.TempDir(), 0)
+	auditLog.Record(workingDir, "sess-1", AuditEventSessionCreated, map[string]string{"client_id": "c1"})
+
+	c := NewClient("test-client", nil, NewHub(), nil, nil, nil, nil, nil, config.DefaultConfig(), nil)
+	c.auditLog = auditLog
+	c.setAuthenticated(true)
+	c.SetWorkspace(workingDir)
+
+	send := func(msg *BaseMessage) *BaseMessage {
+		t.Helper()
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		return <-c.send
+	}
+
+	resp := send(NewRequest(MessageTypeWorkspaceAudit, "audit-1", map[string]interface{}{
+		"since": time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}))
+	if resp.Type != MessageTypeWorkspaceAudit {
+		t.Fatalf("expected workspace_audit response, got %s: %+v", resp.Type, resp.Error)
+	}
+	entries, _ := resp.Data["entries"].([]AuditEntry)
+	if len(entries) != 1 || entries[0].Type != AuditEventSessionCreated {
+		t.Errorf("expected the session_created entry, got %+v", resp.Data["entries"])
+	}
+
+	resp = send(NewRequest(MessageTypeWorkspaceAudit, "audit-2", map[string]interface{}{"until": "yesterday"}))
+	if resp.Type != MessageTypeError {
+		t.Errorf("expected an error for an invalid time, got %s", resp.Type)
+	}
+}
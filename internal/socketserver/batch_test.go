@@ -0,0 +1,85 @@
+package socketserver
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func newBatchTestClient(t *testing.T) *Client {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.AddContextDirectory("/workspace", "/docs")
+
+	c := NewClient("test-client", nil, NewHub(), nil, nil, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+	c.SetWorkspace("/workspace")
+	return c
+}
+
+func TestHandleBatchPerEntryResults(t *testing.T) {
+	c := newBatchTestClient(t)
+
+	batch := NewRequest(MessageTypeBatch, "batch-1", map[string]interface{}{
+		"requests": []interface{}{
+			map[string]interface{}{"type": MessageTypeConfigGet, "request_id": "sub-config"},
+			map[string]interface{}{"type": MessageTypeSessionList},
+			map[string]interface{}{"type": MessageTypeContextDirList, "request_id": "sub-dirs"},
+			map[string]interface{}{"type": MessageTypeBatch},
+		},
+	})
+
+	if err := c.handleMessage(batch); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+
+	if len(c.send) != 1 {
+		t.Fatalf("expected exactly one message to be sent, got %d", len(c.send))
+	}
+	resp := <-c.send
+	if resp.Type != MessageTypeBatch || resp.RequestID != "batch-1" {
+		t.Fatalf("unexpected response %s/%s", resp.Type, resp.RequestID)
+	}
+
+	var data BatchResponse
+	if err := parseData(resp.Data, &data); err != nil {
+		t.Fatalf("failed to parse batch response: %v", err)
+	}
+	if len(data.Responses) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(data.Responses))
+	}
+
+	// No broker: config_get fails without failing the batch
+	if r := data.Responses[0]; r.RequestID != "sub-config" || r.Error == nil {
+		t.Errorf("expected error entry for config_get, got %+v", r)
+	}
+
+	// Missing request IDs are derived from the batch ID
+	if r := data.Responses[1]; r.RequestID != "batch-1/1" || r.Error == nil {
+		t.Errorf("expected error entry for session_list, got %+v", r)
+	}
+
+	if r := data.Responses[2]; r.RequestID != "sub-dirs" || r.Error != nil || r.Type != MessageTypeContextDirList {
+		t.Errorf("expected successful context_dir_list entry, got %+v", r)
+	} else if dirs, ok := r.Data["context_dirs"].([]interface{}); !ok || len(dirs) != 1 || dirs[0] != "/docs" {
+		t.Errorf("unexpected context dirs: %v", r.Data["context_dirs"])
+	}
+
+	// Nested batches are rejected
+	if r := data.Responses[3]; r.Error == nil || r.Error.Code != ErrorCodeOperationNotAllowed {
+		t.Errorf("expected nested batch to be rejected, got %+v", r)
+	}
+}
+
+func TestHandleBatchRejectsEmpty(t *testing.T) {
+	c := newBatchTestClient(t)
+
+	if err := c.handleMessage(NewRequest(MessageTypeBatch, "batch-2", map[string]interface{}{})); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+
+	resp := <-c.send
+	if resp.Error == nil || resp.Error.Code != ErrorCodeInvalidRequest {
+		t.Fatalf("expected INVALID_REQUEST, got %+v", resp)
+	}
+}
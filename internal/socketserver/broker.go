@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/codefionn/scriptschnell/internal/actor"
@@ -48,6 +49,9 @@ type MessageBroker struct {
 	sessionStorage     *session.SessionStorage
 	requireSandboxAuth bool
 
+	// Prompts currently inside ProcessUserMessage (the first one generates, the rest wait)
+	activePrompts atomic.Int32
+
 	pendingAuthMu sync.Mutex
 	pendingAuths  map[string]*pendingAuthorization // authID -> pending auth
 	authCounter   int
@@ -55,6 +59,10 @@ type MessageBroker struct {
 	pendingQuestionMu sync.Mutex
 	pendingQuestions  map[string]*pendingQuestion // questionID -> pending question
 	questionCounter   int
+
+	auditLog      *AuditLog
+	auditMu       sync.Mutex
+	pendingWrites map[string]string // toolID -> path of file-writing tool calls awaiting their result
 }
 
 // NewMessageBroker creates a new message broker
@@ -81,6 +89,12 @@ func (mb *MessageBroker) SetDependencies(providerMgr *provider.Manager, secretsP
 	mb.cfg = cfg
 }
 
+// SetAuditLog sets the workspace audit log receiving tool executions, file
+// writes and domain approvals
+func (mb *MessageBroker) SetAuditLog(auditLog *AuditLog) {
+	mb.auditLog = auditLog
+}
+
 // InitializeSession initializes a new session with the given configuration.
 // If a session and orchestrator already exist (e.g. after loading a saved session), this is a no-op.
 func (mb *MessageBroker) InitializeSession(
@@ -158,6 +172,9 @@ func (mb *MessageBroker) ProcessUserMessage(ctx context.Context, message string,
 		return fmt.Errorf("session not initialized")
 	}
 
+	mb.activePrompts.Add(1)
+	defer mb.activePrompts.Add(-1)
+
 	// Publish user message to event bus - this will be routed to all clients subscribed to this session
 	userMsgData := map[string]interface{}{
 		"role":       "user",
@@ -169,9 +186,9 @@ func (mb *MessageBroker) ProcessUserMessage(ctx context.Context, message string,
 	actor.PublishEvent(actor.EventTypeMessage, "broker", mb.session.ID, userMsgData)
 
 	// Create auth callback - sends request to client and waits for response
-	authCallback := func(toolName string, params map[string]interface{}, reason string) (bool, error) {
+	authCallback := orchestrator.ApprovalOnly(func(toolName string, params map[string]interface{}, reason string) (bool, error) {
 		return mb.handleAuthorization(ctx, toolName, params, reason, requestID)
-	}
+	})
 
 	// Create question callback for planning agent
 	questionCallback := func(question string) (string, error) {
@@ -201,6 +218,7 @@ func (mb *MessageBroker) ProcessUserMessage(ctx context.Context, message string,
 			"session_id":  mb.session.ID,
 		}
 		actor.PublishEvent(actor.EventTypeToolCall, "broker", mb.session.ID, toolCallData)
+		mb.auditToolCall(toolName, toolID, parameters)
 		return nil
 	}
 
@@ -215,10 +233,16 @@ func (mb *MessageBroker) ProcessUserMessage(ctx context.Context, message string,
 		if result != "" {
 			data["result"] = result
 		}
+		if mb.orchestrator != nil {
+			if metadata := mb.orchestrator.ToolResultMetadata(toolID); metadata != nil && metadata.Diff != nil {
+				data["diff_summary"] = metadata.Diff
+			}
+		}
 
 		// Publish tool result to event bus
 		data["session_id"] = mb.session.ID
 		actor.PublishEvent(actor.EventTypeToolResult, "broker", mb.session.ID, data)
+		mb.auditToolResult(toolName, toolID, errorMsg)
 		return nil
 	}
 
@@ -270,12 +294,10 @@ func (mb *MessageBroker) handleProgress(msg progress.Update, requestID string) e
 	if msg.Reasoning != "" {
 		logger.Debug("Progress (reasoning): %s", msg.Reasoning)
 		reasoningData := map[string]interface{}{
-			"role":       "assistant",
 			"content":    msg.Reasoning,
-			"reasoning":  true,
 			"session_id": mb.session.ID,
 		}
-		actor.PublishEvent(actor.EventTypeMessage, "broker", mb.session.ID, reasoningData)
+		actor.PublishEvent(actor.EventTypeReasoning, "broker", mb.session.ID, reasoningData)
 		return nil
 	}
 
@@ -305,14 +327,53 @@ func (mb *MessageBroker) handleProgress(msg progress.Update, requestID string) e
 	return nil
 }
 
-// Stop stops the current session operations
+// Stop stops the current session operations on behalf of the user
 func (mb *MessageBroker) Stop() error {
+	return mb.StopWithCause(orchestrator.ErrCancelledByUser)
+}
+
+// StopWithCause stops the current session operations, recording why
+func (mb *MessageBroker) StopWithCause(cause error) error {
 	if mb.orchestrator != nil {
-		mb.orchestrator.Stop()
+		mb.orchestrator.StopWithCause(cause)
 	}
 	return nil
 }
 
+// Pause suspends the current generation before its next loop iteration
+func (mb *MessageBroker) Pause() error {
+	if mb.orchestrator == nil {
+		return fmt.Errorf("session not initialized")
+	}
+	return mb.orchestrator.Pause()
+}
+
+// Resume continues a paused generation
+func (mb *MessageBroker) Resume() error {
+	if mb.orchestrator == nil {
+		return fmt.Errorf("session not initialized")
+	}
+	mb.orchestrator.Resume()
+	return nil
+}
+
+// GenerationState reports whether a prompt is being processed, how many
+// further prompts are waiting behind it and the current orchestration model
+func (mb *MessageBroker) GenerationState() (generating bool, queued int, model string) {
+	active := int(mb.activePrompts.Load())
+	if active > 1 {
+		queued = active - 1
+	}
+
+	if mb.orchestrator != nil {
+		model = mb.orchestrator.GetCurrentModel()
+	} else if mb.providerMgr != nil {
+		model = mb.providerMgr.GetOrchestrationModel()
+	}
+
+	return active > 0, queued, model
+}
+
 // Close cleans up resources
 func (mb *MessageBroker) Close() error {
 	if mb.orchestrator != nil {
@@ -480,10 +541,9 @@ func (mb *MessageBroker) handleAuthorization(ctx context.Context, toolName strin
 // HandleAuthorizationResponse handles a response from the client for an authorization request
 func (mb *MessageBroker) HandleAuthorizationResponse(authID string, approved bool) error {
 	mb.pendingAuthMu.Lock()
-	defer mb.pendingAuthMu.Unlock()
-
 	auth, ok := mb.pendingAuths[authID]
 	if !ok {
+		mb.pendingAuthMu.Unlock()
 		return fmt.Errorf("no pending authorization with ID %s", authID)
 	}
 
@@ -494,10 +554,65 @@ func (mb *MessageBroker) HandleAuthorizationResponse(authID string, approved boo
 	default:
 		logger.Warn("Authorization response channel full for %s", authID)
 	}
+	mb.pendingAuthMu.Unlock()
 
+	if approved {
+		if domain := auditApprovedDomain(auth.toolName, auth.parameters); domain != "" {
+			mb.audit(AuditEventDomainApproved, map[string]string{
+				"domain": domain,
+				"tool":   auth.toolName,
+				"reason": auth.reason,
+			})
+		}
+	}
 	return nil
 }
 
+// audit records an event for the broker's session in the workspace audit log
+func (mb *MessageBroker) audit(eventType string, details map[string]string) {
+	if mb.auditLog == nil || mb.session == nil {
+		return
+	}
+	mb.auditLog.Record(mb.session.GetWorkingDir(), mb.session.ID, eventType, details)
+}
+
+// auditToolCall records a tool execution and remembers the target of file
+// writes until their result arrives
+func (mb *MessageBroker) auditToolCall(toolName, toolID string, parameters map[string]interface{}) {
+	if mb.auditLog == nil {
+		return
+	}
+	mb.audit(AuditEventToolExecuted, map[string]string{
+		"tool":       toolName,
+		"tool_id":    toolID,
+		"parameters": encodeAuditParameters(parameters),
+	})
+	if path := auditFileWritePath(toolName, parameters); path != "" {
+		mb.auditMu.Lock()
+		if mb.pendingWrites == nil {
+			mb.pendingWrites = make(map[string]string)
+		}
+		mb.pendingWrites[toolID] = path
+		mb.auditMu.Unlock()
+	}
+}
+
+// auditToolResult records a file write once the writing tool succeeded
+func (mb *MessageBroker) auditToolResult(toolName, toolID, errorMsg string) {
+	mb.auditMu.Lock()
+	path, ok := mb.pendingWrites[toolID]
+	delete(mb.pendingWrites, toolID)
+	mb.auditMu.Unlock()
+
+	if !ok || errorMsg != "" {
+		return
+	}
+	mb.audit(AuditEventFileWritten, map[string]string{
+		"tool": toolName,
+		"path": path,
+	})
+}
+
 // HandleAuthorizationAck handles an ack from the client confirming the authorization dialog was displayed
 func (mb *MessageBroker) HandleAuthorizationAck(authID string) error {
 	mb.pendingAuthMu.Lock()
@@ -0,0 +1,41 @@
+package socketserver
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func TestChatCompactRejectedDuringGeneration(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	broker := NewMessageBroker()
+	c := NewClient("test-client", nil, NewHub(), nil, nil, broker, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+
+	request := func() *BaseMessage {
+		t.Helper()
+		if err := c.handleMessage(NewRequest(MessageTypeChatCompact, "req", nil)); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		return <-c.send
+	}
+
+	if resp := request(); resp.Error == nil || resp.Error.Code != ErrorCodeInvalidRequest {
+		t.Fatalf("expected %s without a session, got %+v", ErrorCodeInvalidRequest, resp)
+	}
+
+	c.SetSession("session-1", t.TempDir())
+	broker.activePrompts.Add(1)
+	if resp := request(); resp.Error == nil || resp.Error.Code != ErrorCodeSessionBusy {
+		t.Fatalf("expected %s during generation, got %+v", ErrorCodeSessionBusy, resp)
+	}
+
+	broker.activePrompts.Add(-1)
+	if resp := request(); resp.Error == nil || resp.Error.Code != ErrorCodeOperationNotAllowed {
+		t.Fatalf("expected %s without a loaded session, got %+v", ErrorCodeOperationNotAllowed, resp)
+	}
+}
@@ -10,14 +10,21 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/config"
 	"github.com/codefionn/scriptschnell/internal/consts"
+	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/mcp"
+	"github.com/codefionn/scriptschnell/internal/orchestrator"
 	"github.com/codefionn/scriptschnell/internal/provider"
+	"github.com/codefionn/scriptschnell/internal/sandbox"
 	"github.com/codefionn/scriptschnell/internal/securemem"
 	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
 )
 
 // Client represents a connected socket client
@@ -48,9 +55,17 @@ type Client struct {
 	// Authentication state
 	authenticated bool
 	clientType    string
+	authToken     string
+
+	// Whether large outgoing messages are compressed (negotiated during auth)
+	compression atomic.Bool
+
+	// Limits messages per second read from the connection (nil = unlimited)
+	rateLimiter *messageRateLimiter
 
 	// Control
 	mu       sync.Mutex
+	writeMu  sync.Mutex // Serializes writes to conn
 	closed   bool
 	stopOnce sync.Once
 	stopChan chan struct{}
@@ -61,10 +76,25 @@ type Client struct {
 	// Event bridge for actor events
 	eventBridge *EventBridge
 
+	// Workspace audit log (nil = disabled)
+	auditLog *AuditLog
+
+	// Responses captured for sub-requests of a running batch (request ID -> response)
+	batchMu      sync.Mutex
+	batchCapture map[string]*BaseMessage
+
+	// Cancels the compaction started by chat_compact (nil = none running)
+	compactMu     sync.Mutex
+	compactCancel context.CancelFunc
+
 	// Dependencies
 	providerMgr     *provider.Manager
 	secretsPassword *securemem.String
 	cfg             *config.Config
+
+	// newSummarizeClient creates the client used for session summaries
+	// (nil = create one from the provider manager; replaced in tests)
+	newSummarizeClient func(sessionID string) (llm.Client, error)
 }
 
 // NewClient creates a new client instance
@@ -84,6 +114,7 @@ func NewClient(id string, conn net.Conn, hub *Hub, sessionMgr *SessionManager, w
 		messages:         make([]BaseMessage, 0),
 		stopChan:         make(chan struct{}),
 		authenticated:    false,
+		rateLimiter:      newMessageRateLimiter(cfg.Socket.GetMaxMessagesPerSecond()),
 	}
 }
 
@@ -146,6 +177,20 @@ func (c *Client) Stop() {
 	})
 }
 
+// requestContext returns a context for work a request started outside the
+// read loop. It is cancelled when the client disconnects.
+func (c *Client) requestContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-c.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 // Close is an alias for Stop
 func (c *Client) Close() {
 	c.Stop()
@@ -170,7 +215,15 @@ func (c *Client) readPump() {
 			}
 
 			// Read until newline
-			line, err := reader.ReadString('\n')
+			raw, err := readMessageLine(reader, c.maxMessageBytes())
+			if errors.Is(err, errMessageTooLarge) {
+				logger.Warn("Client %s sent a message larger than %d bytes", c.ID, c.maxMessageBytes())
+				details := fmt.Sprintf("limit is %d bytes", c.maxMessageBytes())
+				if c.rejectOverLimit("", ErrorCodeMessageTooLarge, "Message too large", details) {
+					return
+				}
+				continue
+			}
 			if err != nil {
 				if errors.Is(err, io.EOF) {
 					logger.Info("Client %s disconnected (EOF)", c.ID)
@@ -183,16 +236,41 @@ func (c *Client) readPump() {
 			}
 
 			// Trim whitespace and skip empty lines
-			line = strings.TrimSpace(line)
+			line := strings.TrimSpace(string(raw))
 			if line == "" {
 				continue
 			}
 
 			// Parse message
 			var msg BaseMessage
-			if err := json.Unmarshal([]byte(line), &msg); err != nil {
-				logger.Error("Failed to parse message from client %s: %v", c.ID, err)
-				c.SendError("", ErrorCodeInvalidRequest, "Invalid JSON format", err.Error())
+			parseErr := json.Unmarshal([]byte(line), &msg)
+
+			// Throttle clients flooding the server, including with invalid messages
+			if !c.rateLimiter.allow() {
+				logger.Warn("Client %s exceeded the message rate limit", c.ID)
+				details := fmt.Sprintf("limit is %.0f messages per second", c.rateLimiter.rate)
+				if c.rejectOverLimit(msg.RequestID, ErrorCodeRateLimited, "Too many messages", details) {
+					return
+				}
+				continue
+			}
+
+			if parseErr != nil {
+				logger.Error("Failed to parse message from client %s: %v", c.ID, parseErr)
+				c.SendError("", ErrorCodeInvalidRequest, "Invalid JSON format", parseErr.Error())
+				continue
+			}
+
+			// Decompress payload if the client compressed it
+			if err := decompressMessage(&msg, c.maxMessageBytes()); err != nil {
+				logger.Error("Failed to decompress message from client %s: %v", c.ID, err)
+				if errors.Is(err, errMessageTooLarge) {
+					if c.rejectOverLimit(msg.RequestID, ErrorCodeMessageTooLarge, "Message too large", err.Error()) {
+						return
+					}
+					continue
+				}
+				c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid compressed payload", err.Error())
 				continue
 			}
 
@@ -229,26 +307,45 @@ func (c *Client) writePump() {
 				return
 			}
 
-			// Set write deadline
-			if err := c.conn.SetWriteDeadline(time.Now().Add(consts.Timeout10)); err != nil {
-				logger.Error("Failed to set write deadline for client %s: %v", c.ID, err)
+			if err := c.writeMessage(message); err != nil {
+				logger.Error("Failed to write message to client %s: %v", c.ID, err)
 				return
 			}
+		}
+	}
+}
 
-			// Marshal message to JSON
-			data, err := json.Marshal(message)
-			if err != nil {
-				logger.Error("Failed to marshal message for client %s: %v", c.ID, err)
-				continue
-			}
+// writeMessage compresses (if negotiated), marshals and writes one message.
+// Marshal errors drop the message and are only logged.
+func (c *Client) writeMessage(message *BaseMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 
-			// Write with newline delimiter
-			if _, err := fmt.Fprintf(c.conn, "%s\n", data); err != nil {
-				logger.Error("Failed to write message to client %s: %v", c.ID, err)
-				return
-			}
+	// Set write deadline
+	if err := c.conn.SetWriteDeadline(time.Now().Add(consts.Timeout10)); err != nil {
+		return fmt.Errorf("failed to set write deadline: %w", err)
+	}
+
+	// Compress large payloads if negotiated
+	if c.compression.Load() {
+		compressed, err := compressMessage(message)
+		if err != nil {
+			logger.Warn("Failed to compress message for client %s: %v", c.ID, err)
+		} else {
+			message = compressed
 		}
 	}
+
+	// Marshal message to JSON
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Failed to marshal message for client %s: %v", c.ID, err)
+		return nil
+	}
+
+	// Write with newline delimiter
+	_, err = fmt.Fprintf(c.conn, "%s\n", data)
+	return err
 }
 
 // handleMessage dispatches incoming messages to appropriate handlers
@@ -283,12 +380,24 @@ func (c *Client) handleMessage(msg *BaseMessage) error {
 	case MessageTypeSessionDetach:
 		return c.handleSessionDetach(msg)
 
+	case MessageTypeSessionTakeover:
+		return c.handleSessionTakeover(msg)
+
 	case MessageTypeSessionList:
 		return c.handleSessionList(msg)
 
 	case MessageTypeSessionDelete:
 		return c.handleSessionDelete(msg)
 
+	case MessageTypeSessionRename:
+		return c.handleSessionRename(msg)
+
+	case MessageTypeSessionDuplicate:
+		return c.handleSessionDuplicate(msg)
+
+	case MessageTypeSessionState:
+		return c.handleSessionState(msg)
+
 	case MessageTypeChatSend:
 		return c.handleChatSend(msg)
 
@@ -298,18 +407,69 @@ func (c *Client) handleMessage(msg *BaseMessage) error {
 	case MessageTypeChatClear:
 		return c.handleChatClear(msg)
 
+	case MessageTypeChatCompact:
+		return c.handleChatCompact(msg)
+
+	case MessageTypePause:
+		return c.handlePause(msg)
+
+	case MessageTypeResume:
+		return c.handleResume(msg)
+
+	case MessageTypeChatDequeue:
+		return c.handleChatDequeue(msg)
+
+	case MessageTypeChatQueueClear:
+		return c.handleChatQueueClear(msg)
+
+	case MessageTypeJobsList:
+		return c.handleJobsList(msg)
+
+	case MessageTypeJobStatus:
+		return c.handleJobStatus(msg)
+
+	case MessageTypeJobStop:
+		return c.handleJobStop(msg)
+
+	case MessageTypeProcessList:
+		return c.handleProcessList(msg)
+
+	case MessageTypeProcessKill:
+		return c.handleProcessKill(msg)
+
+	case MessageTypeMessagePin:
+		return c.handleMessagePin(msg)
+
 	case MessageTypeConfigGet:
 		return c.handleConfigGet(msg)
 
 	case MessageTypeConfigSet:
 		return c.handleConfigSet(msg)
 
+	case MessageTypeModelsList:
+		return c.handleModelsList(msg)
+
+	case MessageTypeModelSet:
+		return c.handleModelSet(msg)
+
 	case MessageTypeWorkspaceList:
 		return c.handleWorkspaceList(msg)
 
 	case MessageTypeWorkspaceSet:
 		return c.handleWorkspaceSet(msg)
 
+	case MessageTypeWorkspaceAudit:
+		return c.handleWorkspaceAudit(msg)
+
+	case MessageTypeContextDirAdd:
+		return c.handleContextDirAdd(msg)
+
+	case MessageTypeContextDirRemove:
+		return c.handleContextDirRemove(msg)
+
+	case MessageTypeContextDirList:
+		return c.handleContextDirList(msg)
+
 	case MessageTypeSessionSave:
 		return c.handleSessionSave(msg)
 
@@ -322,6 +482,9 @@ func (c *Client) handleMessage(msg *BaseMessage) error {
 	case MessageTypeQuestionResponse:
 		return c.handleQuestionResponse(msg)
 
+	case MessageTypeBatch:
+		return c.handleBatch(msg)
+
 	default:
 		return fmt.Errorf("unknown message type: %s", msg.Type)
 	}
@@ -329,6 +492,10 @@ func (c *Client) handleMessage(msg *BaseMessage) error {
 
 // Send sends a message to the client
 func (c *Client) Send(msg *BaseMessage) {
+	if c.captureBatchResponse(msg) {
+		return
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -344,8 +511,31 @@ func (c *Client) Send(msg *BaseMessage) {
 	}
 }
 
+// countGeneration starts counting a generation for the metrics endpoint. The
+// returned function reports it with the tokens the orchestrator accumulated
+// in the session in the meantime.
+func (c *Client) countGeneration() func() {
+	if c.hub == nil || c.broker == nil {
+		return func() {}
+	}
+	tokensBefore := 0
+	if sess := c.broker.GetSession(); sess != nil {
+		tokensBefore = sess.GetTotalTokens()
+	}
+	return func() {
+		used := 0
+		if sess := c.broker.GetSession(); sess != nil {
+			used = sess.GetTotalTokens() - tokensBefore
+		}
+		c.hub.Metrics().GenerationFinished(used)
+	}
+}
+
 // SendError sends an error message to the client
 func (c *Client) SendError(requestID string, code string, message string, details string) {
+	if c.hub != nil {
+		c.hub.Metrics().ErrorSent(code)
+	}
 	c.Send(NewError(requestID, code, message, details))
 }
 
@@ -494,6 +684,63 @@ func (c *Client) SetWorkspace(workspace string) {
 	c.Workspace = workspace
 }
 
+// applyWorkspaceLandlock applies the landlock paths approved for the client's
+// workspace to the broker's orchestrator. Without Landlock support the
+// authorizer keeps gating access outside the workspace.
+func (c *Client) applyWorkspaceLandlock() {
+	if c.workspaceManager == nil || c.broker == nil {
+		return
+	}
+	orch := c.broker.GetOrchestrator()
+	if orch == nil {
+		return
+	}
+
+	ws, ok := c.workspaceManager.GetWorkspaceByPath(c.GetWorkspace())
+	if !ok {
+		return
+	}
+
+	cfg, err := c.workspaceManager.GetLandlockConfig(ws.ID)
+	if err == nil {
+		err = orch.ApplyWorkspaceLandlock(cfg)
+	}
+	if errors.Is(err, sandbox.ErrUnsupported) {
+		logger.Debug("Landlock unsupported for workspace %s, falling back to authorizer", ws.ID)
+	} else if err != nil {
+		logger.Warn("Failed to apply landlock permissions for workspace %s: %v", ws.ID, err)
+	}
+}
+
+// applySessionModels points the broker's orchestrator at the models the
+// session was created with, falling back to the global selection
+func (c *Client) applySessionModels(sessionID string) {
+	if c.sessionManager == nil || c.broker == nil {
+		return
+	}
+	orch := c.broker.GetOrchestrator()
+	if orch == nil {
+		return
+	}
+
+	orchestrationModel, summarizeModel := c.sessionManager.GetSessionModels(sessionID)
+	if err := orch.SetModelOverrides(orchestrationModel, summarizeModel); err != nil {
+		logger.Warn("Failed to apply models for session %s: %v", sessionID, err)
+	}
+}
+
+// resolvePromptModel validates the model override of a chat_send. An empty
+// result keeps the session's model.
+func (c *Client) resolvePromptModel(modelID, providerName string) (string, error) {
+	if modelID == "" && providerName == "" {
+		return "", nil
+	}
+	if c.providerMgr == nil {
+		return "", fmt.Errorf("provider manager not initialized")
+	}
+	return c.providerMgr.ResolveModel(modelID, providerName)
+}
+
 // handleAuthRequest authenticates a client connection
 func (c *Client) handleAuthRequest(msg *BaseMessage) error {
 	// Parse request data
@@ -508,10 +755,13 @@ func (c *Client) handleAuthRequest(msg *BaseMessage) error {
 	if c.clientType == "" {
 		c.clientType = "unknown"
 	}
+	c.authToken = data.Token
 
-	// Determine authentication method (if required)
-	// For now, we accept all clients - authentication is handled by file permissions
-	// In the future, token-based or peer credential validation can be added here
+	// Besides file permissions, restrict peers by UID/GID
+	if err := c.checkPeer(); err != nil {
+		c.rejectPeer(msg.RequestID, err)
+		return nil
+	}
 
 	// Mark client as authenticated
 	c.setAuthenticated(true)
@@ -519,12 +769,22 @@ func (c *Client) handleAuthRequest(msg *BaseMessage) error {
 	// Generate connection info
 	connectionID := c.ID
 
+	// Enable compression only if both sides support it
+	capabilities := append([]string(nil), serverCapabilities...)
+	compression := c.cfg != nil && c.cfg.Socket.EnableCompression && hasCapability(data.Capabilities, CapabilityCompression)
+	if compression {
+		capabilities = append(capabilities, CapabilityCompression)
+		c.compression.Store(true)
+	}
+
 	// Send successful auth response
 	c.SendResponse(MessageTypeAuthResponse, msg.RequestID, map[string]interface{}{
 		"success":             true,
 		"connection_id":       connectionID,
-		"server_version":      "1.0.0",
-		"server_capabilities": []string{"sessions", "workspaces", "chat", "progress", "authorization", "questions"},
+		"server_version":      ProtocolVersion,
+		"server_capabilities": capabilities,
+		"capabilities":        protocolCapabilities(compression),
+		"tool_schema_version": mcp.ToolSchemaVersion,
 	})
 
 	logger.Info("Frontend connected: client=%s type=%s addr=%s", c.ID, c.clientType, c.conn.RemoteAddr())
@@ -565,6 +825,7 @@ func (c *Client) handleSessionCreate(msg *BaseMessage) error {
 	}
 
 	// Resolve workspace and update tracking
+	var orchestrationModel, summarizeModel string
 	if c.workspaceManager != nil {
 		ctx := context.Background()
 		ws, err := c.workspaceManager.ResolveWorkspace(ctx, workingDir)
@@ -573,6 +834,7 @@ func (c *Client) handleSessionCreate(msg *BaseMessage) error {
 			return nil
 		}
 		c.workspaceManager.UpdateWorkspaceSessionCount(ws.ID, 1)
+		orchestrationModel, summarizeModel, _ = c.workspaceManager.GetWorkspaceModelDefaults(ws.ID)
 	}
 
 	// Create new session
@@ -604,6 +866,15 @@ func (c *Client) handleSessionCreate(msg *BaseMessage) error {
 		return nil
 	}
 
+	// Sessions use the workspace's default models, if any
+	c.sessionManager.SetSessionModels(sessionID, orchestrationModel, summarizeModel)
+
+	// Read-only sessions only get read/search tools and every write is denied
+	readOnly, _ := data.Options["read_only"].(bool)
+	if readOnly {
+		sess.SetReadOnly(true)
+	}
+
 	// Register client with event bridge for this session
 	if c.eventBridge != nil {
 		c.eventBridge.RegisterSessionClient(sessionID, c)
@@ -613,12 +884,27 @@ func (c *Client) handleSessionCreate(msg *BaseMessage) error {
 	c.SetSession(sessionID, workingDir)
 	c.SetWorkspace(workingDir)
 
+	auditDetails := map[string]string{"client_id": c.ID}
+	if readOnly {
+		auditDetails["read_only"] = "true"
+	}
+	c.auditLog.Record(workingDir, sessionID, AuditEventSessionCreated, auditDetails)
+
 	// Send response
 	responseData := map[string]interface{}{
 		"session_id":  sessionID,
 		"working_dir": workingDir,
 		"created_at":  sess.CreatedAt.Format(time.RFC3339),
 	}
+	if orchestrationModel != "" {
+		responseData["orchestration_model"] = orchestrationModel
+	}
+	if summarizeModel != "" {
+		responseData["summarize_model"] = summarizeModel
+	}
+	if readOnly {
+		responseData["read_only"] = true
+	}
 	c.SendResponse(MessageTypeSessionCreate, msg.RequestID, responseData)
 
 	logger.Info("Client %s created session %s", c.ID, sessionID)
@@ -644,6 +930,14 @@ func (c *Client) handleSessionAttach(msg *BaseMessage) error {
 
 	// Attach client to session
 	if err := c.sessionManager.AttachClient(c.ID, data.SessionID); err != nil {
+		if errors.Is(err, ErrSessionBusy) {
+			c.SendError(msg.RequestID, ErrorCodeSessionBusy, "Session is attached to another client", err.Error())
+			return nil
+		}
+		if evicted, ok := c.sessionManager.EvictedSessionInfo(data.SessionID); ok {
+			c.sendSessionEvicted(msg.RequestID, evicted)
+			return nil
+		}
 		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to attach to session", err.Error())
 		return nil
 	}
@@ -668,6 +962,78 @@ func (c *Client) handleSessionAttach(msg *BaseMessage) error {
 	return nil
 }
 
+func (c *Client) handleSessionTakeover(msg *BaseMessage) error {
+	if c.sessionManager == nil {
+		return fmt.Errorf("session manager not initialized")
+	}
+
+	// Parse request data
+	var data SessionTakeoverRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid session takeover request", err.Error())
+		return nil
+	}
+
+	if data.SessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Session ID is required", "")
+		return nil
+	}
+
+	// Only a client with the same identity as the current owner may take over
+	owner, ownerConnected := c.sessionOwnerClient(data.SessionID)
+	if ownerConnected && (owner.clientType != c.clientType || owner.authToken != c.authToken) {
+		c.SendError(msg.RequestID, ErrorCodeAuthFailed, "Session takeover denied", "client identity does not match session owner")
+		return nil
+	}
+
+	previousOwner, err := c.sessionManager.TakeoverSession(c.ID, data.SessionID)
+	if err != nil {
+		if evicted, ok := c.sessionManager.EvictedSessionInfo(data.SessionID); ok {
+			c.sendSessionEvicted(msg.RequestID, evicted)
+			return nil
+		}
+		c.SendError(msg.RequestID, ErrorCodeSessionNotFound, "Failed to take over session", err.Error())
+		return nil
+	}
+
+	// Drop the stale connection
+	if ownerConnected && owner != c {
+		if c.eventBridge != nil {
+			c.eventBridge.UnregisterClient(owner)
+		}
+		owner.SetSession("", "")
+		go owner.Close()
+	}
+
+	// Register client with event bridge for this session
+	if c.eventBridge != nil {
+		c.eventBridge.RegisterSessionClient(data.SessionID, c)
+	}
+
+	if sessInfo, exists := c.sessionManager.GetSessionInfo(data.SessionID); exists {
+		c.SetSession(data.SessionID, sessInfo.WorkingDir)
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeSessionTakeover, msg.RequestID, map[string]interface{}{
+		"session_id":     data.SessionID,
+		"previous_owner": previousOwner,
+		"status":         "attached",
+	})
+
+	logger.Info("Client %s took over session %s from %s", c.ID, data.SessionID, previousOwner)
+	return nil
+}
+
+// sessionOwnerClient returns the connected client that owns a session
+func (c *Client) sessionOwnerClient(sessionID string) (*Client, bool) {
+	ownerID, ok := c.sessionManager.GetSessionOwner(sessionID)
+	if !ok || ownerID == "" || c.hub == nil {
+		return nil, false
+	}
+	return c.hub.GetClient(ownerID)
+}
+
 func (c *Client) handleSessionDetach(msg *BaseMessage) error {
 	if c.sessionManager == nil {
 		return fmt.Errorf("session manager not initialized")
@@ -722,8 +1088,32 @@ func (c *Client) handleSessionList(msg *BaseMessage) error {
 		return nil
 	}
 
+	if data.Limit < 0 || data.Offset < 0 {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Limit and offset must not be negative", "")
+		return nil
+	}
+
+	opts := session.ListOptions{
+		TitleContains: data.Title,
+		Limit:         data.Limit,
+		Offset:        data.Offset,
+	}
+	var err error
+	if data.Since != "" {
+		if opts.UpdatedAfter, err = time.Parse(time.RFC3339, data.Since); err != nil {
+			c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid since timestamp", err.Error())
+			return nil
+		}
+	}
+	if data.Until != "" {
+		if opts.UpdatedBefore, err = time.Parse(time.RFC3339, data.Until); err != nil {
+			c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid until timestamp", err.Error())
+			return nil
+		}
+	}
+
 	// List sessions
-	sessions, err := c.sessionManager.ListSessions(workingDir)
+	sessions, total, err := c.sessionManager.ListSessionsWithOptions(workingDir, opts)
 	if err != nil {
 		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to list sessions", err.Error())
 		return nil
@@ -732,19 +1122,28 @@ func (c *Client) handleSessionList(msg *BaseMessage) error {
 	// Convert to response format
 	sessionList := make([]SessionInfoResponse, 0, len(sessions))
 	for _, s := range sessions {
-		sessionList = append(sessionList, SessionInfoResponse{
+		info := SessionInfoResponse{
 			ID:           s.ID,
 			Title:        s.Title,
 			WorkingDir:   s.WorkingDir,
 			CreatedAt:    s.CreatedAt.Format(time.RFC3339),
 			UpdatedAt:    s.UpdatedAt.Format(time.RFC3339),
 			MessageCount: s.MessageCount,
-		})
+			TotalTokens:  s.TotalTokens,
+			TotalCost:    s.TotalCost,
+		}
+		if !s.LastMessageAt.IsZero() {
+			info.LastMessageAt = s.LastMessageAt.Format(time.RFC3339Nano)
+		}
+		sessionList = append(sessionList, info)
 	}
 
 	// Send response
 	c.SendResponse(MessageTypeSessionList, msg.RequestID, map[string]interface{}{
 		"sessions": sessionList,
+		"total":    total,
+		"offset":   data.Offset,
+		"limit":    data.Limit,
 	})
 
 	return nil
@@ -793,98 +1192,626 @@ func (c *Client) handleSessionDelete(msg *BaseMessage) error {
 	return nil
 }
 
-func (c *Client) handleChatSend(msg *BaseMessage) error {
-	if c.broker == nil {
-		return fmt.Errorf("broker not initialized")
+// handleSessionRename changes the title of a saved session; its ID stays
+func (c *Client) handleSessionRename(msg *BaseMessage) error {
+	if c.sessionManager == nil {
+		return fmt.Errorf("session manager not initialized")
 	}
 
-	// Check if client has a session
-	sessionID := c.GetSession()
-	if sessionID == "" {
-		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+	var data SessionRenameRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid session rename request", err.Error())
 		return nil
 	}
 
-	// Parse request data
-	var data ChatSendRequest
-	if err := parseData(msg.Data, &data); err != nil {
-		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid chat send request", err.Error())
+	if data.SessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Session ID is required", "")
 		return nil
 	}
-
-	if data.Content == "" {
-		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Content is required", "")
+	if strings.TrimSpace(data.Title) == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Title is required", "")
 		return nil
 	}
 
-	// Register this client with the event bridge for this session
-	// This ensures the client receives events published by actors/broker for this session
-	if c.eventBridge != nil {
-		logger.Debug("[Client %s] Registering with event bridge for session %s", c.ID, sessionID)
-		c.eventBridge.RegisterSessionClient(sessionID, c)
-	} else {
-		logger.Warn("[Client %s] Event bridge is nil!", c.ID)
+	workingDir := data.Workspace
+	if workingDir == "" {
+		workingDir = c.Workspace
 	}
 
-	// Initialize session if needed - pass the event publisher so broker can publish events
-	existingSession, _ := c.sessionManager.GetSession(sessionID)
-	if err := c.broker.InitializeSession(c.cfg, c.providerMgr, c.secretsPassword, existingSession); err != nil {
-		logger.Error("Error initializing session for client %s: %v", c.ID, err)
-		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to initialize session", err.Error())
+	if workingDir == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Working directory not specified", "")
 		return nil
 	}
 
-	// Process message through broker
-	ctx := context.Background()
-	if err := c.broker.ProcessUserMessage(ctx, data.Content, msg.RequestID); err != nil {
-		logger.Error("Error processing user message for client %s: %v", c.ID, err)
-		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to process message", err.Error())
+	// The orchestrator of the broker's current session autosaves it through
+	// its own storage actor
+	var autoSaveRef *actor.ActorRef
+	if c.broker != nil {
+		if sess := c.broker.GetSession(); sess != nil && sess.ID == data.SessionID {
+			if orch := c.broker.GetOrchestrator(); orch != nil {
+				autoSaveRef = orch.SessionStorageRef()
+			}
+		}
+	}
+
+	if err := c.sessionManager.RenameSession(workingDir, data.SessionID, data.Title, autoSaveRef); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to rename session", err.Error())
 		return nil
 	}
 
-	// Send completion response
-	c.SendResponse(MessageTypeChatSend, msg.RequestID, map[string]interface{}{
-		"status": "completed",
+	c.SendResponse(MessageTypeSessionRename, msg.RequestID, map[string]interface{}{
+		"session_id": data.SessionID,
+		"title":      strings.TrimSpace(data.Title),
+		"status":     "renamed",
 	})
 
+	logger.Info("Client %s renamed session %s", c.ID, data.SessionID)
 	return nil
 }
 
-func (c *Client) handleChatStop(msg *BaseMessage) error {
-	if c.broker == nil {
-		return fmt.Errorf("broker not initialized")
+// handleSessionDuplicate copies a saved session to a new ID
+func (c *Client) handleSessionDuplicate(msg *BaseMessage) error {
+	if c.sessionManager == nil {
+		return fmt.Errorf("session manager not initialized")
 	}
 
-	// Stop the broker's current operation
-	if err := c.broker.Stop(); err != nil {
-		logger.Error("Error stopping broker for client %s: %v", c.ID, err)
-		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to stop operation", err.Error())
+	var data SessionDuplicateRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid session duplicate request", err.Error())
 		return nil
 	}
 
-	// Send response
-	c.SendResponse(MessageTypeChatStop, msg.RequestID, map[string]interface{}{
-		"status": "stopped",
+	if data.SessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Session ID is required", "")
+		return nil
+	}
+
+	workingDir := data.Workspace
+	if workingDir == "" {
+		workingDir = c.Workspace
+	}
+
+	if workingDir == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Working directory not specified", "")
+		return nil
+	}
+
+	duplicate, err := c.sessionManager.DuplicateSession(workingDir, data.SessionID, data.Title)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to duplicate session", err.Error())
+		return nil
+	}
+
+	c.SendResponse(MessageTypeSessionDuplicate, msg.RequestID, map[string]interface{}{
+		"session_id":        duplicate.ID,
+		"source_session_id": data.SessionID,
+		"title":             duplicate.Title,
+		"message_count":     duplicate.MessageCount,
+		"status":            "duplicated",
 	})
 
-	logger.Info("Client %s stopped chat operation", c.ID)
+	logger.Info("Client %s duplicated session %s as %s", c.ID, data.SessionID, duplicate.ID)
 	return nil
 }
 
-func (c *Client) handleChatClear(msg *BaseMessage) error {
-	if c.broker == nil {
-		return fmt.Errorf("broker not initialized")
-	}
-
+// handleSessionState reports whether the attached session is generating and
+// how many prompts are queued, so frontends don't have to infer it from events
+func (c *Client) handleSessionState(msg *BaseMessage) error {
 	sessionID := c.GetSession()
 	if sessionID == "" {
 		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
 		return nil
 	}
 
-	// Get the current session and clear its message history
-	if c.sessionManager == nil {
-		return fmt.Errorf("session manager not initialized")
+	state := SessionStateResponse{SessionID: sessionID}
+	if c.broker != nil {
+		state.Generating, state.QueuedPrompts, state.CurrentModel = c.broker.GenerationState()
+	} else if c.providerMgr != nil {
+		state.CurrentModel = c.providerMgr.GetOrchestrationModel()
+	}
+
+	// The session's prompt queue also covers generations started by an earlier connection
+	if c.sessionManager != nil {
+		generating, queued := c.sessionManager.PromptQueueState(sessionID)
+		state.Generating = state.Generating || generating
+		state.QueuedPrompts = len(queued)
+	}
+
+	c.SendResponse(MessageTypeSessionState, msg.RequestID, map[string]interface{}{
+		"session_id":     state.SessionID,
+		"generating":     state.Generating,
+		"queued_prompts": state.QueuedPrompts,
+		"current_model":  state.CurrentModel,
+	})
+	return nil
+}
+
+// attachedSession returns the session the client is attached to, sending an
+// error response if there is none
+func (c *Client) attachedSession(requestID string) (string, *session.Session, bool) {
+	sessionID := c.GetSession()
+	if sessionID == "" || c.sessionManager == nil {
+		c.SendError(requestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+		return "", nil, false
+	}
+
+	sess, exists := c.sessionManager.GetSession(sessionID)
+	if !exists {
+		c.SendError(requestID, ErrorCodeSessionNotFound, "Session not found", sessionID)
+		return "", nil, false
+	}
+	return sessionID, sess, true
+}
+
+// handleJobsList lists the background programs tracked by the attached session
+func (c *Client) handleJobsList(msg *BaseMessage) error {
+	sessionID, sess, ok := c.attachedSession(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	c.SendResponse(MessageTypeJobsList, msg.RequestID, map[string]interface{}{
+		"session_id": sessionID,
+		"jobs":       listSessionJobs(sess),
+	})
+	return nil
+}
+
+// handleJobStatus reports the state and recent output of a background program
+func (c *Client) handleJobStatus(msg *BaseMessage) error {
+	var data JobStatusRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid job status request", err.Error())
+		return nil
+	}
+	if data.JobID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Job ID is required", "")
+		return nil
+	}
+
+	sessionID, sess, ok := c.attachedSession(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	job, exists := sess.GetBackgroundJob(data.JobID)
+	if !exists {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Job not found", data.JobID)
+		return nil
+	}
+
+	outputLines := data.OutputLines
+	if outputLines == 0 {
+		outputLines = defaultJobOutputLines
+	}
+
+	c.SendResponse(MessageTypeJobStatus, msg.RequestID, map[string]interface{}{
+		"session_id": sessionID,
+		"job":        jobInfoFromSession(job, outputLines),
+	})
+	return nil
+}
+
+// handleJobStop sends SIGTERM or SIGKILL to a background program
+func (c *Client) handleJobStop(msg *BaseMessage) error {
+	var data JobStopRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid job stop request", err.Error())
+		return nil
+	}
+	if data.JobID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Job ID is required", "")
+		return nil
+	}
+
+	signal := data.Signal
+	if signal == "" {
+		signal = "SIGTERM"
+	}
+	if signal != "SIGTERM" && signal != "SIGKILL" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Unsupported signal", "signal must be SIGTERM or SIGKILL")
+		return nil
+	}
+
+	sessionID, sess, ok := c.attachedSession(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	job, exists := sess.GetBackgroundJob(data.JobID)
+	if !exists {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Job not found", data.JobID)
+		return nil
+	}
+	if tools.BackgroundJobCompleted(job) {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Job already completed", data.JobID)
+		return nil
+	}
+
+	// Prefer the shell actor that started the job, which also signals the
+	// job's process group
+	var err error
+	stopped := false
+	if c.broker != nil && c.broker.GetSession() == sess {
+		if orch := c.broker.GetOrchestrator(); orch != nil {
+			if err = orch.StopBackgroundJob(context.Background(), data.JobID, signal); err == nil {
+				stopped = true
+			}
+		}
+	}
+	if !stopped {
+		_, err = tools.SignalBackgroundJob(job, signal)
+	}
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to stop job", err.Error())
+		return nil
+	}
+	markJobStopRequested(job, signal)
+
+	c.SendResponse(MessageTypeJobStop, msg.RequestID, map[string]interface{}{
+		"session_id": sessionID,
+		"job_id":     data.JobID,
+		"signal":     signal,
+		"status":     "signal_sent",
+	})
+
+	logger.Info("Client %s sent %s to job %s in session %s", c.ID, signal, data.JobID, sessionID)
+	return nil
+}
+
+// handleMessagePin pins or unpins a message of the attached session, so
+// context compaction keeps it verbatim
+func (c *Client) handleMessagePin(msg *BaseMessage) error {
+	var data MessagePinRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid message pin request", err.Error())
+		return nil
+	}
+
+	sessionID, sess, ok := c.attachedSession(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	pinned := data.Pinned == nil || *data.Pinned
+	var err error
+	if pinned {
+		err = sess.PinMessage(data.Index)
+	} else {
+		err = sess.UnpinMessage(data.Index)
+	}
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Failed to update message pin", err.Error())
+		return nil
+	}
+
+	c.SendResponse(MessageTypeMessagePin, msg.RequestID, map[string]interface{}{
+		"session_id":     sessionID,
+		"index":          data.Index,
+		"pinned":         pinned,
+		"pinned_indices": sess.PinnedMessageIndices(),
+	})
+	return nil
+}
+
+func (c *Client) handleChatSend(msg *BaseMessage) error {
+	if c.broker == nil {
+		return fmt.Errorf("broker not initialized")
+	}
+
+	// Check if client has a session
+	sessionID := c.GetSession()
+	if sessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+		return nil
+	}
+
+	// Parse request data
+	var data ChatSendRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid chat send request", err.Error())
+		return nil
+	}
+
+	if data.Content == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Content is required", "")
+		return nil
+	}
+
+	model, err := c.resolvePromptModel(data.Model, data.Provider)
+	if err != nil {
+		var unknown *provider.UnknownModelError
+		if errors.As(err, &unknown) {
+			c.SendError(msg.RequestID, ErrorCodeUnknownModel, "Unknown model", err.Error())
+		} else {
+			c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to resolve model", err.Error())
+		}
+		return nil
+	}
+
+	content, err := c.buildAttachmentPrompt(sessionID, data.Content, data.Attachments)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid attachment", err.Error())
+		return nil
+	}
+
+	// Register this client with the event bridge for this session
+	// This ensures the client receives events published by actors/broker for this session
+	if c.eventBridge != nil {
+		logger.Debug("[Client %s] Registering with event bridge for session %s", c.ID, sessionID)
+		c.eventBridge.RegisterSessionClient(sessionID, c)
+	} else {
+		logger.Warn("[Client %s] Event bridge is nil!", c.ID)
+	}
+
+	// Queue the prompt if the session is already generating; the chat_send
+	// response is sent once the prompt has been processed
+	prompt := QueuedPrompt{
+		RequestID: msg.RequestID,
+		ClientID:  c.ID,
+		Content:   content,
+		Model:     model,
+		QueuedAt:  time.Now(),
+	}
+	position, start, err := c.sessionManager.EnqueuePrompt(sessionID, prompt)
+	if errors.Is(err, ErrServerDraining) {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Server is shutting down", "no new prompts are accepted while running generations finish")
+		return nil
+	}
+	if errors.Is(err, ErrPromptQueueFull) {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Prompt queue is full",
+			fmt.Sprintf("at most %d prompts can be queued while generating", c.sessionManager.maxQueuedPrompts()))
+		return nil
+	}
+	if !start {
+		publishQueueProgress(sessionID, fmt.Sprintf("Prompt queued (position %d)", position))
+		logger.Info("Client %s queued prompt %s for session %s at position %d", c.ID, msg.RequestID, sessionID, position)
+		return nil
+	}
+
+	// Process outside the read loop so the connection stays responsive
+	// (chat_stop, session_state, queue management) while generating
+	go c.sessionManager.runPromptQueue(sessionID, prompt, func(p QueuedPrompt) error {
+		if p.RequestID != prompt.RequestID {
+			publishQueueProgress(sessionID, "Processing queued prompt")
+		}
+		return c.promptClient(sessionID, p).processPrompt(sessionID, p)
+	}, c.completePrompt)
+
+	return nil
+}
+
+// promptClient returns the client whose broker runs a queued prompt: the
+// client that queued it, or c if that client has disconnected or moved to
+// another session
+func (c *Client) promptClient(sessionID string, prompt QueuedPrompt) *Client {
+	if prompt.ClientID == c.ID || c.hub == nil {
+		return c
+	}
+	owner, ok := c.hub.GetClient(prompt.ClientID)
+	if !ok || owner.broker == nil || owner.GetSession() != sessionID {
+		return c
+	}
+	return owner
+}
+
+// processPrompt runs a single prompt of the session through the broker
+func (c *Client) processPrompt(sessionID string, prompt QueuedPrompt) error {
+	// Initialize session if needed - pass the event publisher so broker can publish events
+	existingSession, _ := c.sessionManager.GetSession(sessionID)
+	if err := c.broker.InitializeSession(c.cfg, c.providerMgr, c.secretsPassword, existingSession); err != nil {
+		logger.Error("Error initializing session for client %s: %v", c.ID, err)
+		return fmt.Errorf("failed to initialize session: %w", err)
+	}
+	c.applyWorkspaceLandlock()
+	c.applySessionModels(sessionID)
+
+	// A per-prompt model replaces the session's model for this generation only
+	if prompt.Model != "" {
+		if orch := c.broker.GetOrchestrator(); orch != nil {
+			restore, err := orch.UseModelForPrompt(prompt.Model)
+			if err != nil {
+				return fmt.Errorf("failed to use model %s: %w", prompt.Model, err)
+			}
+			defer restore()
+		}
+	}
+
+	// Count the tokens the generation uses for the metrics endpoint
+	defer c.countGeneration()()
+
+	// Process message through broker
+	ctx := context.Background()
+	if err := c.broker.ProcessUserMessage(ctx, prompt.Content, prompt.RequestID); err != nil {
+		logger.Error("Error processing user message for client %s: %v", c.ID, err)
+		return fmt.Errorf("failed to process message: %w", err)
+	}
+	return nil
+}
+
+// completePrompt answers the chat_send of a processed prompt. The requesting
+// connection may have been replaced by a reconnect, so it is looked up by ID.
+func (c *Client) completePrompt(prompt QueuedPrompt, err error) {
+	target := c
+	if prompt.ClientID != c.ID {
+		if c.hub == nil {
+			return
+		}
+		other, ok := c.hub.GetClient(prompt.ClientID)
+		if !ok {
+			return
+		}
+		target = other
+	}
+
+	if cause := orchestrator.CancelCause(err); cause != nil {
+		target.SendError(prompt.RequestID, ErrorCodeCancelled, "Generation cancelled", cause.Error())
+		return
+	}
+	if orchestrator.IsTimeout(err) {
+		target.SendError(prompt.RequestID, ErrorCodeTimeout, "Generation timed out", err.Error())
+		return
+	}
+	if err != nil {
+		target.SendError(prompt.RequestID, ErrorCodeInternalError, "Failed to process message", err.Error())
+		return
+	}
+	target.SendResponse(MessageTypeChatSend, prompt.RequestID, map[string]interface{}{
+		"status": "completed",
+	})
+}
+
+// publishQueueProgress notifies every client attached to the session about the prompt queue
+func publishQueueProgress(sessionID, message string) {
+	actor.PublishEvent(actor.EventTypeProgress, "prompt_queue", sessionID, map[string]interface{}{
+		"message":    message,
+		"session_id": sessionID,
+	})
+}
+
+// handleChatDequeue removes a queued prompt (by request_id, or the most recent one)
+func (c *Client) handleChatDequeue(msg *BaseMessage) error {
+	if c.sessionManager == nil {
+		return fmt.Errorf("session manager not initialized")
+	}
+
+	sessionID := c.GetSession()
+	if sessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+		return nil
+	}
+
+	var data ChatDequeueRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid chat dequeue request", err.Error())
+		return nil
+	}
+
+	removed, ok := c.sessionManager.DequeuePrompt(sessionID, data.RequestID)
+	if !ok {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Queued prompt not found", data.RequestID)
+		return nil
+	}
+	c.cancelQueuedPrompt(removed)
+
+	_, queued := c.sessionManager.PromptQueueState(sessionID)
+	c.SendResponse(MessageTypeChatDequeue, msg.RequestID, map[string]interface{}{
+		"request_id":     removed.RequestID,
+		"content":        removed.Content,
+		"queued_prompts": len(queued),
+	})
+	return nil
+}
+
+// handleChatQueueClear drops all queued prompts of the session
+func (c *Client) handleChatQueueClear(msg *BaseMessage) error {
+	if c.sessionManager == nil {
+		return fmt.Errorf("session manager not initialized")
+	}
+
+	sessionID := c.GetSession()
+	if sessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+		return nil
+	}
+
+	cleared := c.sessionManager.ClearPromptQueue(sessionID)
+	for _, prompt := range cleared {
+		c.cancelQueuedPrompt(prompt)
+	}
+
+	c.SendResponse(MessageTypeChatQueueClear, msg.RequestID, map[string]interface{}{
+		"cleared": len(cleared),
+	})
+	return nil
+}
+
+// cancelQueuedPrompt answers the pending chat_send of a prompt removed from the queue
+func (c *Client) cancelQueuedPrompt(prompt QueuedPrompt) {
+	target, ok := c, prompt.ClientID == c.ID
+	if !ok && c.hub != nil {
+		target, ok = c.hub.GetClient(prompt.ClientID)
+	}
+	if ok {
+		target.SendResponse(MessageTypeChatSend, prompt.RequestID, map[string]interface{}{
+			"status": "dequeued",
+		})
+	}
+}
+
+func (c *Client) handleChatStop(msg *BaseMessage) error {
+	if c.broker == nil {
+		return fmt.Errorf("broker not initialized")
+	}
+
+	c.cancelCompaction()
+
+	// Stop the broker's current operation
+	if err := c.broker.Stop(); err != nil {
+		logger.Error("Error stopping broker for client %s: %v", c.ID, err)
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to stop operation", err.Error())
+		return nil
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeChatStop, msg.RequestID, map[string]interface{}{
+		"status": "stopped",
+	})
+
+	logger.Info("Client %s stopped chat operation", c.ID)
+	return nil
+}
+
+func (c *Client) handlePause(msg *BaseMessage) error {
+	if c.broker == nil {
+		return fmt.Errorf("broker not initialized")
+	}
+
+	if err := c.broker.Pause(); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Failed to pause operation", err.Error())
+		return nil
+	}
+
+	c.SendResponse(MessageTypePause, msg.RequestID, map[string]interface{}{
+		"status": "paused",
+	})
+
+	logger.Info("Client %s paused chat operation", c.ID)
+	return nil
+}
+
+func (c *Client) handleResume(msg *BaseMessage) error {
+	if c.broker == nil {
+		return fmt.Errorf("broker not initialized")
+	}
+
+	if err := c.broker.Resume(); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Failed to resume operation", err.Error())
+		return nil
+	}
+
+	c.SendResponse(MessageTypeResume, msg.RequestID, map[string]interface{}{
+		"status": "resumed",
+	})
+
+	logger.Info("Client %s resumed chat operation", c.ID)
+	return nil
+}
+
+func (c *Client) handleChatClear(msg *BaseMessage) error {
+	if c.broker == nil {
+		return fmt.Errorf("broker not initialized")
+	}
+
+	sessionID := c.GetSession()
+	if sessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+		return nil
+	}
+
+	// Get the current session and clear its message history
+	if c.sessionManager == nil {
+		return fmt.Errorf("session manager not initialized")
 	}
 
 	sess, ok := c.sessionManager.GetSession(sessionID)
@@ -893,35 +1820,130 @@ func (c *Client) handleChatClear(msg *BaseMessage) error {
 		return nil
 	}
 
-	// Get messages to preserve system messages
-	messages := sess.GetMessages()
-	systemMessages := make([]*session.Message, 0)
-	for _, msg := range messages {
-		if msg.Role == "system" {
-			systemMessages = append(systemMessages, msg)
-		}
+	// Get messages to preserve system messages
+	messages := sess.GetMessages()
+	systemMessages := make([]*session.Message, 0)
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			systemMessages = append(systemMessages, msg)
+		}
+	}
+
+	// Clear all session data
+	sess.Clear()
+
+	// Re-add system messages
+	for _, msg := range systemMessages {
+		sess.AddMessage(msg)
+	}
+
+	// Mark session as dirty for auto-save
+	if c.sessionManager != nil {
+		c.sessionManager.MarkSessionDirty(sessionID)
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeChatClear, msg.RequestID, map[string]interface{}{
+		"status": "cleared",
+	})
+
+	logger.Info("Client %s cleared chat history for session %s", c.ID, sessionID)
+	return nil
+}
+
+// handleChatCompact summarizes the earlier context of the attached session
+// right away instead of waiting for auto-compaction near the context limit
+func (c *Client) handleChatCompact(msg *BaseMessage) error {
+	if c.broker == nil {
+		return fmt.Errorf("broker not initialized")
+	}
+
+	sessionID := c.GetSession()
+	if sessionID == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+		return nil
+	}
+
+	generating, _, _ := c.broker.GenerationState()
+	if !generating && c.sessionManager != nil {
+		generating, _ = c.sessionManager.PromptQueueState(sessionID)
+	}
+	if generating {
+		c.SendError(msg.RequestID, ErrorCodeSessionBusy, "Cannot compact while a prompt is being processed", "")
+		return nil
 	}
 
-	// Clear all session data
-	sess.Clear()
+	orch := c.broker.GetOrchestrator()
+	if sess := c.broker.GetSession(); orch == nil || sess == nil || sess.ID != sessionID {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Session is not loaded", "Send a prompt first")
+		return nil
+	}
 
-	// Re-add system messages
-	for _, msg := range systemMessages {
-		sess.AddMessage(msg)
+	ctx, cancel := c.requestContext()
+	c.compactMu.Lock()
+	if c.compactCancel != nil {
+		c.compactMu.Unlock()
+		cancel()
+		c.SendError(msg.RequestID, ErrorCodeSessionBusy, "A compaction is already running", "")
+		return nil
+	}
+	c.compactCancel = cancel
+	c.compactMu.Unlock()
+
+	go c.compactSession(ctx, msg.RequestID, sessionID, orch)
+	return nil
+}
+
+// compactSession runs a compaction requested with chat_compact and sends the
+// response once it finished. chat_stop and disconnecting cancel it.
+func (c *Client) compactSession(ctx context.Context, requestID, sessionID string, orch *orchestrator.Orchestrator) {
+	defer func() {
+		c.compactMu.Lock()
+		cancel := c.compactCancel
+		c.compactCancel = nil
+		c.compactMu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+	}()
+
+	result, err := orch.CompactNow(ctx, nil, nil)
+	switch {
+	case errors.Is(err, orchestrator.ErrCompactionBusy):
+		c.SendError(requestID, ErrorCodeSessionBusy, "Cannot compact while a prompt is being processed", err.Error())
+		return
+	case errors.Is(err, orchestrator.ErrNothingToCompact):
+		c.SendError(requestID, ErrorCodeOperationNotAllowed, "Nothing to compact", err.Error())
+		return
+	case errors.Is(err, context.Canceled):
+		c.SendError(requestID, ErrorCodeOperationNotAllowed, "Compaction cancelled", err.Error())
+		return
+	case err != nil:
+		c.SendError(requestID, ErrorCodeInternalError, "Failed to compact context", err.Error())
+		return
 	}
 
-	// Mark session as dirty for auto-save
 	if c.sessionManager != nil {
 		c.sessionManager.MarkSessionDirty(sessionID)
 	}
 
-	// Send response
-	c.SendResponse(MessageTypeChatClear, msg.RequestID, map[string]interface{}{
-		"status": "cleared",
+	c.SendResponse(MessageTypeChatCompact, requestID, map[string]interface{}{
+		"status":             "compacted",
+		"compacted_messages": result.CompactedMessages,
+		"freed_percent":      result.FreedPercent,
 	})
 
-	logger.Info("Client %s cleared chat history for session %s", c.ID, sessionID)
-	return nil
+	logger.Info("Client %s compacted session %s (%d messages, freed ~%d%%)", c.ID, sessionID, result.CompactedMessages, result.FreedPercent)
+}
+
+// cancelCompaction stops a compaction started with chat_compact
+func (c *Client) cancelCompaction() {
+	c.compactMu.Lock()
+	cancel := c.compactCancel
+	c.compactMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
 }
 
 func (c *Client) handleConfigGet(msg *BaseMessage) error {
@@ -995,9 +2017,10 @@ func (c *Client) handleConfigGet(msg *BaseMessage) error {
 		}
 	}
 
-	// Send response
+	// Send response; the version is required by config_set
 	c.SendResponse(MessageTypeConfigGet, msg.RequestID, map[string]interface{}{
-		"config": result,
+		"config":  result,
+		"version": cfg.Version(),
 	})
 
 	return nil
@@ -1023,40 +2046,60 @@ func (c *Client) handleConfigSet(msg *BaseMessage) error {
 		return nil
 	}
 
-	// Update allowed config values (scoped to session level)
-	updated := make(map[string]interface{})
+	if data.Version == nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Config version is required", "Read it with config_get")
+		return nil
+	}
 
-	for key, value := range data.Values {
-		switch key {
-		case "model":
-			// Model selection is handled by provider manager, not stored in config
-			// This is not modifiable via config set
-			logger.Warn("Client %s attempted to set model via config (not supported)", c.ID)
-		case "temperature":
-			if temp, ok := value.(float64); ok && temp > 0 {
-				cfg.Temperature = temp
-				updated["temperature"] = temp
-			}
-		case "max_tokens":
-			if tokens, ok := value.(float64); ok && tokens > 0 {
-				cfg.MaxTokens = int(tokens)
-				updated["max_tokens"] = int(tokens)
-			}
-		case "auto_save":
-			if autoSaveMap, ok := value.(map[string]interface{}); ok {
-				if enabled, ok := autoSaveMap["enabled"].(bool); ok {
-					cfg.AutoSave.Enabled = enabled
-					updated["auto_save_enabled"] = enabled
+	// Update allowed config values, unless another client changed the config
+	// since this client read it. The config file is only written on request.
+	configPath := ""
+	if data.Persist {
+		configPath = config.GetConfigPath()
+	}
+	updated := make(map[string]interface{})
+	version, err := cfg.UpdateVersioned(configPath, *data.Version, func(cfg *config.Config) {
+		for key, value := range data.Values {
+			switch key {
+			case "model":
+				// Model selection is handled by provider manager, not stored in config
+				// This is not modifiable via config set
+				logger.Warn("Client %s attempted to set model via config (not supported)", c.ID)
+			case "temperature":
+				if temp, ok := value.(float64); ok && temp > 0 {
+					cfg.Temperature = temp
+					updated["temperature"] = temp
 				}
-				if interval, ok := autoSaveMap["save_interval_seconds"].(float64); ok && interval > 0 {
-					cfg.AutoSave.SaveIntervalSeconds = int(interval)
-					updated["auto_save_interval"] = interval
+			case "max_tokens":
+				if tokens, ok := value.(float64); ok && tokens > 0 {
+					cfg.MaxTokens = int(tokens)
+					updated["max_tokens"] = int(tokens)
 				}
+			case "auto_save":
+				if autoSaveMap, ok := value.(map[string]interface{}); ok {
+					if enabled, ok := autoSaveMap["enabled"].(bool); ok {
+						cfg.AutoSave.Enabled = enabled
+						updated["auto_save_enabled"] = enabled
+					}
+					if interval, ok := autoSaveMap["save_interval_seconds"].(float64); ok && interval > 0 {
+						cfg.AutoSave.SaveIntervalSeconds = int(interval)
+						updated["auto_save_interval"] = interval
+					}
+				}
+			default:
+				// Unmodifiable config value, skip
+				logger.Warn("Client %s attempted to set unmodifiable config key: %s", c.ID, key)
 			}
-		default:
-			// Unmodifiable config value, skip
-			logger.Warn("Client %s attempted to set unmodifiable config key: %s", c.ID, key)
 		}
+	})
+	if errors.Is(err, config.ErrVersionConflict) {
+		c.SendError(msg.RequestID, ErrorCodeVersionConflict, "Config was changed by another client",
+			fmt.Sprintf("expected version %d, current version %d; read it again with config_get and retry", *data.Version, version))
+		return nil
+	}
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to save config", err.Error())
+		return nil
 	}
 
 	// The orchestrator will pick up the new config values on next generation
@@ -1064,8 +2107,10 @@ func (c *Client) handleConfigSet(msg *BaseMessage) error {
 
 	// Send response
 	c.SendResponse(MessageTypeConfigSet, msg.RequestID, map[string]interface{}{
-		"updated": updated,
-		"status":  "updated",
+		"updated":   updated,
+		"version":   version,
+		"persisted": data.Persist,
+		"status":    "updated",
 	})
 
 	logger.Info("Client %s updated config: %v", c.ID, updated)
@@ -1109,6 +2154,9 @@ func (c *Client) handleWorkspaceList(msg *BaseMessage) error {
 			LandlockWrite:    ws.LandlockWrite,
 			DomainsApproved:  domainsApproved,
 			CommandsApproved: commandsApproved,
+
+			OrchestrationModel: ws.OrchestrationModel,
+			SummarizeModel:     ws.SummarizeModel,
 		})
 	}
 
@@ -1145,22 +2193,276 @@ func (c *Client) handleWorkspaceSet(msg *BaseMessage) error {
 		return nil
 	}
 
+	// The working directory of a live orchestrator cannot change mid-generation
+	var orch *orchestrator.Orchestrator
+	if c.broker != nil {
+		orch = c.broker.GetOrchestrator()
+	}
+	if orch != nil && orch.GetWorkingDir() != ws.Path {
+		if generating, _, _ := c.broker.GenerationState(); generating {
+			c.SendError(msg.RequestID, ErrorCodeSessionBusy, "Cannot change the workspace while a prompt is being processed", "")
+			return nil
+		}
+	}
+
+	// Update model defaults for new sessions in this workspace
+	orchestrationModel, summarizeModel, err := c.workspaceManager.GetWorkspaceModelDefaults(ws.ID)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeWorkspaceInvalid, "Invalid workspace", err.Error())
+		return nil
+	}
+	if data.OrchestrationModel != nil || data.SummarizeModel != nil {
+		if data.OrchestrationModel != nil {
+			orchestrationModel = *data.OrchestrationModel
+		}
+		if data.SummarizeModel != nil {
+			summarizeModel = *data.SummarizeModel
+		}
+		for _, modelID := range []string{orchestrationModel, summarizeModel} {
+			if modelID == "" || c.providerMgr == nil {
+				continue
+			}
+			if _, ok := c.providerMgr.GetModel(modelID); !ok {
+				c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Unknown model", modelID)
+				return nil
+			}
+		}
+		if err := c.workspaceManager.SetWorkspaceModelDefaults(ws.ID, orchestrationModel, summarizeModel); err != nil {
+			c.SendError(msg.RequestID, ErrorCodeWorkspaceInvalid, "Failed to set workspace models", err.Error())
+			return nil
+		}
+	}
+
+	// Move the session's orchestrator to the new working directory
+	if orch != nil {
+		if err := orch.SetWorkingDir(ctx, ws.Path); err != nil {
+			c.SendError(msg.RequestID, ErrorCodeSessionBusy, "Failed to change the working directory", err.Error())
+			return nil
+		}
+	}
+
 	// Update client workspace
 	c.SetWorkspace(data.Workspace)
 	c.workspaceManager.UpdateWorkspaceAccess(ws.ID)
+	c.applyWorkspaceLandlock()
 
 	// Send response
 	c.SendResponse(MessageTypeWorkspaceSet, msg.RequestID, map[string]interface{}{
-		"workspace_id": ws.ID,
-		"path":         ws.Path,
-		"name":         ws.Name,
-		"status":       "set",
+		"workspace_id":        ws.ID,
+		"path":                ws.Path,
+		"name":                ws.Name,
+		"status":              "set",
+		"orchestration_model": orchestrationModel,
+		"summarize_model":     summarizeModel,
 	})
 
 	logger.Info("Client %s set workspace to %s (%s)", c.ID, ws.Name, ws.Path)
 	return nil
 }
 
+func (c *Client) handleWorkspaceAudit(msg *BaseMessage) error {
+	if c.auditLog == nil {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Workspace audit log is disabled", "")
+		return nil
+	}
+
+	// Parse request data
+	var data WorkspaceAuditRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid workspace audit request", err.Error())
+		return nil
+	}
+
+	workspace := data.Workspace
+	if workspace == "" {
+		workspace = c.GetWorkspace()
+	}
+	if workspace == "" {
+		c.SendError(msg.RequestID, ErrorCodeWorkspaceInvalid, "No workspace set", "Pass a workspace or use workspace_set first")
+		return nil
+	}
+
+	var since, until time.Time
+	for _, bound := range []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{{"since", data.Since, &since}, {"until", data.Until, &until}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid "+bound.name+" time", err.Error())
+			return nil
+		}
+		*bound.dst = t
+	}
+
+	limit := data.Limit
+	if limit <= 0 || limit > MaxWorkspaceAuditEntries {
+		limit = MaxWorkspaceAuditEntries
+	}
+
+	entries, err := c.auditLog.Query(workspace, since, until, limit)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to read workspace audit log", err.Error())
+		return nil
+	}
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeWorkspaceAudit, msg.RequestID, map[string]interface{}{
+		"workspace": normalizeAuditWorkspace(workspace),
+		"entries":   entries,
+	})
+	return nil
+}
+
+// contextDirWorkspace returns the active workspace for context directory
+// operations, sending an error response if it is not available
+func (c *Client) contextDirWorkspace(requestID string) (string, bool) {
+	if c.cfg == nil {
+		c.SendError(requestID, ErrorCodeInternalError, "Config not available", "")
+		return "", false
+	}
+
+	workspace := c.GetWorkspace()
+	if workspace == "" {
+		c.SendError(requestID, ErrorCodeWorkspaceInvalid, "No workspace set", "Use workspace_set or session_create first")
+		return "", false
+	}
+
+	return workspace, true
+}
+
+// syncContextDirs persists the config and mirrors the context directories
+// into the workspace registry
+func (c *Client) syncContextDirs(workspace string) ([]string, error) {
+	if err := c.cfg.Save(config.GetConfigPath()); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	dirs := c.cfg.GetContextDirectories(workspace)
+	if c.workspaceManager != nil {
+		if ws, ok := c.workspaceManager.GetWorkspaceByPath(workspace); ok {
+			if err := c.workspaceManager.SetWorkspaceContextDirs(ws.ID, dirs); err != nil {
+				logger.Warn("Failed to update workspace context dirs: %v", err)
+			}
+		}
+	}
+
+	return dirs, nil
+}
+
+func (c *Client) handleContextDirAdd(msg *BaseMessage) error {
+	// Parse request data
+	var data ContextDirRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid context dir add request", err.Error())
+		return nil
+	}
+
+	if strings.TrimSpace(data.Dir) == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Directory path is required", "")
+		return nil
+	}
+
+	workspace, ok := c.contextDirWorkspace(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	dir, err := resolveContextDir(workspace, data.Dir)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid context directory", err.Error())
+		return nil
+	}
+
+	c.cfg.AddContextDirectory(workspace, dir)
+	dirs, err := c.syncContextDirs(workspace)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to persist context directories", err.Error())
+		return nil
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeContextDirAdd, msg.RequestID, map[string]interface{}{
+		"workspace":    workspace,
+		"dir":          dir,
+		"context_dirs": dirs,
+		"status":       "added",
+	})
+
+	logger.Info("Client %s added context directory %s to workspace %s", c.ID, dir, workspace)
+	return nil
+}
+
+func (c *Client) handleContextDirRemove(msg *BaseMessage) error {
+	// Parse request data
+	var data ContextDirRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid context dir remove request", err.Error())
+		return nil
+	}
+
+	if strings.TrimSpace(data.Dir) == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Directory path is required", "")
+		return nil
+	}
+
+	workspace, ok := c.contextDirWorkspace(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	// Accept both the stored form and the form a client would resolve it to
+	removed := c.cfg.RemoveContextDirectory(workspace, data.Dir)
+	if !removed {
+		if dir, err := absContextDir(workspace, data.Dir); err == nil {
+			removed = c.cfg.RemoveContextDirectory(workspace, dir)
+		}
+	}
+	if !removed {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Context directory not found", data.Dir)
+		return nil
+	}
+
+	dirs, err := c.syncContextDirs(workspace)
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to persist context directories", err.Error())
+		return nil
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeContextDirRemove, msg.RequestID, map[string]interface{}{
+		"workspace":    workspace,
+		"dir":          data.Dir,
+		"context_dirs": dirs,
+		"status":       "removed",
+	})
+
+	logger.Info("Client %s removed context directory %s from workspace %s", c.ID, data.Dir, workspace)
+	return nil
+}
+
+func (c *Client) handleContextDirList(msg *BaseMessage) error {
+	workspace, ok := c.contextDirWorkspace(msg.RequestID)
+	if !ok {
+		return nil
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeContextDirList, msg.RequestID, map[string]interface{}{
+		"workspace":    workspace,
+		"context_dirs": c.cfg.GetContextDirectories(workspace),
+	})
+
+	return nil
+}
+
 func (c *Client) handleSessionSave(msg *BaseMessage) error {
 	if c.sessionManager == nil {
 		return fmt.Errorf("session manager not initialized")
@@ -1179,29 +2481,84 @@ func (c *Client) handleSessionSave(msg *BaseMessage) error {
 		return nil
 	}
 
+	// Summarizing calls the LLM, so save outside the read loop and respond
+	// once done; the connection stays responsive in the meantime
+	go c.saveSession(msg.RequestID, sessionID, data.Name)
+	return nil
+}
+
+// saveSession summarizes and saves a session and sends the session_save
+// response
+func (c *Client) saveSession(requestID, sessionID, name string) {
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	// Summarize the session so clients can label it; persisted with the save
+	var summary *session.SessionSummary
+	if sess, exists := c.sessionManager.GetSession(sessionID); exists {
+		summary = c.generateSessionSummary(ctx, sessionID, sess)
+		sess.SetSaveSummary(summary)
+		if name == "" && sess.GetTitle() == "" {
+			c.sessionManager.UpdateSessionTitle(sessionID, summary.Title)
+		}
+	}
+
 	// Save session (name is optional)
-	if err := c.sessionManager.SaveSession(sessionID, data.Name); err != nil {
-		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to save session", err.Error())
-		return nil
+	if err := c.sessionManager.SaveSession(sessionID, name); err != nil {
+		c.SendError(requestID, ErrorCodeInternalError, "Failed to save session", err.Error())
+		return
 	}
 
 	// Get session info for response
+	responseData := map[string]interface{}{
+		"session_id": sessionID,
+		"status":     "saved",
+	}
 	if sessInfo, exists := c.sessionManager.GetSessionInfo(sessionID); exists {
-		c.SendResponse(MessageTypeSessionSave, msg.RequestID, map[string]interface{}{
-			"session_id": sessionID,
-			"title":      sessInfo.Title,
-			"saved_at":   time.Now().Format(time.RFC3339),
-			"status":     "saved",
-		})
-	} else {
-		c.SendResponse(MessageTypeSessionSave, msg.RequestID, map[string]interface{}{
-			"session_id": sessionID,
-			"status":     "saved",
-		})
+		responseData["title"] = sessInfo.Title
+		responseData["saved_at"] = time.Now().Format(time.RFC3339)
 	}
+	if summary != nil {
+		responseData["session_summary"] = summary
+	}
+	c.SendResponse(MessageTypeSessionSave, requestID, responseData)
 
 	logger.Info("Client %s saved session %s", c.ID, sessionID)
-	return nil
+}
+
+// generateSessionSummary summarizes a session with the summarize model of
+// the session (or the global one). Without a usable model the summary falls
+// back to the first prompt.
+func (c *Client) generateSessionSummary(ctx context.Context, sessionID string, sess *session.Session) *session.SessionSummary {
+	newClient := c.newSummarizeClient
+	if newClient == nil {
+		newClient = c.createSummarizeClient
+	}
+
+	client, err := newClient(sessionID)
+	if err != nil {
+		logger.Debug("No summarize client for session %s summary: %v", sessionID, err)
+		client = nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, consts.Timeout60)
+	defer cancel()
+	return session.NewTitleGenerator(client).GenerateSummary(ctx, sess)
+}
+
+// createSummarizeClient creates a client for the summarize model of a session
+func (c *Client) createSummarizeClient(sessionID string) (llm.Client, error) {
+	if c.providerMgr == nil {
+		return nil, fmt.Errorf("provider manager not initialized")
+	}
+
+	orchestrationModel, summarizeModel := c.sessionManager.GetSessionModels(sessionID)
+	for _, modelID := range []string{summarizeModel, c.providerMgr.GetSummarizeModel(), orchestrationModel, c.providerMgr.GetOrchestrationModel()} {
+		if modelID != "" {
+			return c.providerMgr.CreateClient(modelID)
+		}
+	}
+	return nil, fmt.Errorf("no summarize model configured")
 }
 
 func (c *Client) handleSessionLoad(msg *BaseMessage) error {
@@ -1275,6 +2632,9 @@ func (c *Client) handleSessionLoad(msg *BaseMessage) error {
 		if msg.ToolName != "" {
 			msgData["tool_name"] = msg.ToolName
 		}
+		if msg.Pinned {
+			msgData["pinned"] = true
+		}
 		messageList = append(messageList, msgData)
 	}
 
@@ -1357,6 +2717,95 @@ func (c *Client) handleQuestionResponse(msg *BaseMessage) error {
 	return nil
 }
 
+// handleBatch executes the sub-requests of a batch in order and replies with
+// all their responses at once
+func (c *Client) handleBatch(msg *BaseMessage) error {
+	// Parse request data
+	var data BatchRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid batch request", err.Error())
+		return nil
+	}
+
+	if len(data.Requests) == 0 {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Batch must contain at least one request", "")
+		return nil
+	}
+	if len(data.Requests) > MaxBatchRequests {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Too many requests in batch", fmt.Sprintf("maximum is %d", MaxBatchRequests))
+		return nil
+	}
+
+	// Execute sub-requests in order, failures are reported per entry
+	responses := make([]BaseMessage, 0, len(data.Requests))
+	for i := range data.Requests {
+		sub := data.Requests[i]
+		if sub.RequestID == "" {
+			sub.RequestID = fmt.Sprintf("%s/%d", msg.RequestID, i)
+		}
+		responses = append(responses, c.executeBatchEntry(&sub))
+	}
+
+	// Send response
+	c.SendResponse(MessageTypeBatch, msg.RequestID, map[string]interface{}{
+		"responses": responses,
+	})
+
+	logger.Debug("Client %s executed batch of %d requests", c.ID, len(responses))
+	return nil
+}
+
+// executeBatchEntry runs a single sub-request of a batch and returns its response
+func (c *Client) executeBatchEntry(sub *BaseMessage) BaseMessage {
+	switch sub.Type {
+	case "":
+		return *NewError(sub.RequestID, ErrorCodeInvalidRequest, "Message type is required", "")
+	case MessageTypeAuthRequest, MessageTypeBatch, MessageTypeClose:
+		return *NewError(sub.RequestID, ErrorCodeOperationNotAllowed, "Message type not allowed in batch", sub.Type)
+	}
+
+	c.batchMu.Lock()
+	if c.batchCapture == nil {
+		c.batchCapture = make(map[string]*BaseMessage)
+	}
+	c.batchCapture[sub.RequestID] = nil
+	c.batchMu.Unlock()
+
+	err := c.handleMessage(sub)
+
+	c.batchMu.Lock()
+	resp := c.batchCapture[sub.RequestID]
+	delete(c.batchCapture, sub.RequestID)
+	c.batchMu.Unlock()
+
+	if err != nil {
+		return *NewError(sub.RequestID, ErrorCodeInternalError, "Failed to handle message", err.Error())
+	}
+	if resp == nil {
+		// No immediate response; anything sent later arrives as a regular message
+		return *NewResponse(sub.Type, sub.RequestID, nil)
+	}
+	return *resp
+}
+
+// captureBatchResponse stores the first response to a running batch
+// sub-request instead of sending it. It reports whether msg was captured.
+func (c *Client) captureBatchResponse(msg *BaseMessage) bool {
+	if msg.RequestID == "" {
+		return false
+	}
+
+	c.batchMu.Lock()
+	defer c.batchMu.Unlock()
+
+	captured, pending := c.batchCapture[msg.RequestID]
+	if !pending || captured != nil {
+		return false
+	}
+	c.batchCapture[msg.RequestID] = msg
+	return true
+}
+
 // parseData is a helper to parse message data into a struct
 func parseData(data map[string]interface{}, v interface{}) error {
 	// Use json.Marshal/Unmarshal for robust parsing
@@ -0,0 +1,110 @@
+package socketserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// CapabilityCompression is advertised in auth_request/auth_response when
+	// a peer can send and receive compressed message payloads
+	CapabilityCompression = "compression"
+
+	// CompressionGzip is the only supported compression algorithm
+	CompressionGzip = "gzip"
+
+	// CompressionThreshold is the minimum encoded size of a message's data
+	// before it is compressed
+	CompressionThreshold = 8 * 1024
+)
+
+// compressMessage returns a copy of msg with its data gzip-compressed and
+// base64-encoded into Payload if the data exceeds CompressionThreshold.
+// Smaller messages are returned unchanged.
+func compressMessage(msg *BaseMessage) (*BaseMessage, error) {
+	if msg.Data == nil || msg.Compression != "" {
+		return msg, nil
+	}
+
+	raw, err := json.Marshal(msg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+	if len(raw) < CompressionThreshold {
+		return msg, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+
+	compressed := *msg
+	compressed.Data = nil
+	compressed.Compression = CompressionGzip
+	compressed.Payload = base64.StdEncoding.EncodeToString(buf.Bytes())
+	return &compressed, nil
+}
+
+// decompressMessage restores the data of a compressed message in place.
+// Payloads inflating to more than maxBytes (0 = unlimited) are rejected with
+// errMessageTooLarge.
+func decompressMessage(msg *BaseMessage, maxBytes int) error {
+	if msg.Compression == "" {
+		return nil
+	}
+	if msg.Compression != CompressionGzip {
+		return fmt.Errorf("unsupported compression: %s", msg.Compression)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	defer zr.Close()
+
+	var src io.Reader = zr
+	if maxBytes > 0 {
+		src = io.LimitReader(zr, int64(maxBytes)+1)
+	}
+	raw, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	if maxBytes > 0 && len(raw) > maxBytes {
+		return fmt.Errorf("decompressed payload: %w", errMessageTooLarge)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	msg.Data = data
+	msg.Compression = ""
+	msg.Payload = ""
+	return nil
+}
+
+// hasCapability reports whether capabilities contains capability
+func hasCapability(capabilities []string, capability string) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package socketserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompressMessageRoundTrip(t *testing.T) {
+	content := strings.Repeat("file contents\n", 2048)
+	msg := NewResponse(MessageTypeToolResult, "req-1", map[string]interface{}{
+		"result": content,
+	})
+
+	compressed, err := compressMessage(msg)
+	if err != nil {
+		t.Fatalf("compressMessage failed: %v", err)
+	}
+	if compressed.Compression != CompressionGzip || compressed.Data != nil {
+		t.Fatalf("expected compressed message, got compression=%q", compressed.Compression)
+	}
+	if len(compressed.Payload) >= len(content) {
+		t.Errorf("expected payload to shrink, got %d bytes for %d bytes of content", len(compressed.Payload), len(content))
+	}
+	if msg.Data == nil {
+		t.Fatal("original message must not be modified")
+	}
+
+	if err := decompressMessage(compressed, 0); err != nil {
+		t.Fatalf("decompressMessage failed: %v", err)
+	}
+	if compressed.Data["result"] != content {
+		t.Fatal("payload corrupted in round trip")
+	}
+	if compressed.Compression != "" || compressed.Payload != "" {
+		t.Error("expected compression fields to be cleared")
+	}
+}
+
+func TestCompressMessageBelowThreshold(t *testing.T) {
+	msg := NewResponse(MessageTypePong, "req-1", map[string]interface{}{"status": "ok"})
+
+	compressed, err := compressMessage(msg)
+	if err != nil {
+		t.Fatalf("compressMessage failed: %v", err)
+	}
+	if compressed != msg {
+		t.Error("small messages should be returned unchanged")
+	}
+}
+
+func TestDecompressMessageInvalidPayload(t *testing.T) {
+	msg := &BaseMessage{Type: "test", Compression: CompressionGzip, Payload: "not base64!"}
+	if err := decompressMessage(msg, 0); err == nil {
+		t.Fatal("expected error for invalid payload")
+	}
+}
@@ -0,0 +1,86 @@
+package socketserver
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func TestConfigSetRejectsStaleVersion(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_STATE_HOME", homeDir)
+
+	cfg := config.DefaultConfig()
+	newClient := func(id string) *Client {
+		broker := NewMessageBroker()
+		broker.cfg = cfg
+		broker.initialized = true
+		c := NewClient(id, nil, NewHub(), nil, nil, broker, nil, nil, cfg, nil)
+		c.setAuthenticated(true)
+		return c
+	}
+	request := func(c *Client, msgType string, data map[string]interface{}) *BaseMessage {
+		t.Helper()
+		if err := c.handleMessage(NewRequest(msgType, "req", data)); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		return <-c.send
+	}
+	first, second := newClient("client-1"), newClient("client-2")
+
+	// Both clients read the same version
+	firstVersion := request(first, MessageTypeConfigGet, nil).Data["version"]
+	secondVersion := request(second, MessageTypeConfigGet, nil).Data["version"]
+	if firstVersion != secondVersion {
+		t.Fatalf("expected the same version, got %v and %v", firstVersion, secondVersion)
+	}
+
+	resp := request(first, MessageTypeConfigSet, map[string]interface{}{
+		"version": firstVersion,
+		"values":  map[string]interface{}{"temperature": 0.2},
+	})
+	if resp.Type != MessageTypeConfigSet {
+		t.Fatalf("expected the first set to succeed, got %s: %+v", resp.Type, resp.Error)
+	}
+	newVersion := resp.Data["version"]
+
+	resp = request(second, MessageTypeConfigSet, map[string]interface{}{
+		"version": secondVersion,
+		"values":  map[string]interface{}{"temperature": 0.8},
+	})
+	if resp.Type != MessageTypeError || resp.Error == nil || resp.Error.Code != ErrorCodeVersionConflict {
+		t.Fatalf("expected a version conflict for the stale set, got %s: %+v", resp.Type, resp.Error)
+	}
+	if cfg.Temperature != 0.2 {
+		t.Fatalf("expected the stale set to change nothing, temperature is %v", cfg.Temperature)
+	}
+	if _, err := os.Stat(config.GetConfigPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected config_set without persist not to write the config file, got %v", err)
+	}
+
+	// After refetching, the second client's set goes through
+	refetched := request(second, MessageTypeConfigGet, nil).Data["version"]
+	if refetched != newVersion {
+		t.Fatalf("expected config_get to return version %v, got %v", newVersion, refetched)
+	}
+	resp = request(second, MessageTypeConfigSet, map[string]interface{}{
+		"version": refetched,
+		"values":  map[string]interface{}{"temperature": 0.8},
+		"persist": true,
+	})
+	if resp.Type != MessageTypeConfigSet || cfg.Temperature != 0.8 {
+		t.Fatalf("expected the retried set to succeed, got %s: %+v", resp.Type, resp.Error)
+	}
+	saved, err := config.Load(config.GetConfigPath())
+	if err != nil || saved.Temperature != 0.8 {
+		t.Fatalf("expected the persisted set to save the config file, got %v", err)
+	}
+
+	if resp := request(first, MessageTypeConfigSet, map[string]interface{}{
+		"values": map[string]interface{}{"temperature": 0.5},
+	}); resp.Type != MessageTypeError || resp.Error.Code != ErrorCodeInvalidRequest {
+		t.Fatalf("expected a set without version to be rejected, got %s", resp.Type)
+	}
+}
@@ -0,0 +1,71 @@
+package socketserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// absContextDir expands ~ and resolves dir relative to the workspace
+func absContextDir(workspace, dir string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if strings.HasPrefix(dir, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workspace, dir)
+	}
+
+	return filepath.Clean(dir), nil
+}
+
+// resolveContextDir resolves dir to an absolute path and verifies that it is
+// a readable directory that may be used as a context directory
+func resolveContextDir(workspace, dir string) (string, error) {
+	absDir, err := absContextDir(workspace, dir)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(absDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("directory does not exist: %s", absDir)
+		}
+		return "", fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("path is not a directory: %s", absDir)
+	}
+
+	// Make sure the directory can actually be listed
+	f, err := os.Open(absDir)
+	if err != nil {
+		return "", fmt.Errorf("directory is not readable: %w", err)
+	}
+	_, err = f.Readdirnames(1)
+	_ = f.Close()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("directory is not readable: %w", err)
+	}
+
+	isHomeDir, err := tools.IsHomeDirectory(absDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to validate directory: %w", err)
+	}
+	if isHomeDir {
+		return "", fmt.Errorf("cannot add home directory as context directory; add a subdirectory instead")
+	}
+
+	return absDir, nil
+}
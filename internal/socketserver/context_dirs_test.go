@@ -0,0 +1,47 @@
+package socketserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveContextDir(t *testing.T) {
+	workspace := t.TempDir()
+	docs := filepath.Join(workspace, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	file := filepath.Join(workspace, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	// Relative paths resolve against the workspace
+	dir, err := resolveContextDir(workspace, "docs")
+	if err != nil {
+		t.Fatalf("expected relative dir to resolve, got %v", err)
+	}
+	if dir != docs {
+		t.Errorf("expected %s, got %s", docs, dir)
+	}
+
+	// Absolute paths are kept
+	dir, err = resolveContextDir(workspace, docs+"/")
+	if err != nil {
+		t.Fatalf("expected absolute dir to resolve, got %v", err)
+	}
+	if dir != docs {
+		t.Errorf("expected %s, got %s", docs, dir)
+	}
+
+	if _, err := resolveContextDir(workspace, "missing"); err == nil {
+		t.Error("expected error for missing directory")
+	}
+	if _, err := resolveContextDir(workspace, file); err == nil {
+		t.Error("expected error for regular file")
+	}
+	if _, err := resolveContextDir(workspace, "~"); err == nil {
+		t.Error("expected error for home directory")
+	}
+}
@@ -0,0 +1,50 @@
+package socketserver
+
+import (
+	"os"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/socketclient"
+)
+
+// writeDescriptor publishes the socket path, PID, protocol version and
+// capabilities in the discovery directory (see socketclient.Discover).
+// Failures are logged, since clients can still connect by path.
+func (s *Server) writeDescriptor(socketPath string) {
+	dir, err := socketclient.DefaultDiscoveryDir()
+	if err != nil {
+		logger.Warn("Not writing server descriptor: %v", err)
+		return
+	}
+
+	capabilities := append([]string(nil), serverCapabilities...)
+	if s.cfg.Socket.EnableCompression {
+		capabilities = append(capabilities, CapabilityCompression)
+	}
+
+	path, err := socketclient.WriteServerDescriptor(dir, socketclient.ServerDescriptor{
+		SocketPath:      socketPath,
+		PID:             os.Getpid(),
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    capabilities,
+		StartedAt:       time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Warn("Failed to write server descriptor: %v", err)
+		return
+	}
+	s.descriptorPath = path
+	logger.Debug("Server descriptor written to %s", path)
+}
+
+// removeDescriptor deletes the descriptor written on start
+func (s *Server) removeDescriptor() {
+	if s.descriptorPath == "" {
+		return
+	}
+	if err := os.Remove(s.descriptorPath); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to remove server descriptor %s: %v", s.descriptorPath, err)
+	}
+	s.descriptorPath = ""
+}
@@ -0,0 +1,46 @@
+package socketserver
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/socketclient"
+)
+
+func TestServerDescriptorWrittenAndRemoved(t *testing.T) {
+	// Descriptors go to the runtime dir, never the real one in tests
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	socketPath := filepath.Join(t.TempDir(), "test.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+
+	s := &Server{cfg: config.DefaultConfig()}
+	s.writeDescriptor(socketPath)
+	if s.descriptorPath == "" {
+		t.Fatal("expected the descriptor to be written")
+	}
+
+	servers, err := socketclient.Discover()
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(servers) != 1 || servers[0].SocketPath != socketPath || servers[0].PID != os.Getpid() || servers[0].ProtocolVersion != ProtocolVersion {
+		t.Fatalf("expected the server to be discovered, got %+v", servers)
+	}
+	if !slices.Contains(servers[0].Capabilities, serverCapabilities[0]) {
+		t.Errorf("expected the server capabilities, got %v", servers[0].Capabilities)
+	}
+
+	s.removeDescriptor()
+	if servers, err := socketclient.Discover(); err != nil || len(servers) != 0 {
+		t.Errorf("expected no servers after removing the descriptor, got %+v (err %v)", servers, err)
+	}
+}
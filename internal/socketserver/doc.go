@@ -28,7 +28,7 @@
 //   - Progress updates (progress)
 //   - Configuration (config_get, config_set)
 //   - Workspace management (workspace_list, workspace_set)
-//   - Session persistence (session_save, session_load)
+//   - Session persistence (session_save, session_load, session_rename, session_duplicate)
 //   - Connection lifecycle (ping, pong, close, closed)
 //
 // # Session Management
@@ -45,7 +45,11 @@
 //   - File permission-based (recommended): Uses Unix socket file permissions
 //   - Token-based: Pre-shared token sent in auth_request
 //   - Challenge-response: HMAC-SHA256 with nonce
-//   - Peer credentials: SO_PEERCRED to validate UID/GID
+//   - Peer credentials: SO_PEERCRED checked against allowed_uids/allowed_gids
+//     (defaulting to the server's own user); on by default unless
+//     disable_peer_check is set, and always on with auth_method "peercred" or
+//     a non-empty allowlist; rejected peers get PEER_NOT_ALLOWED and are
+//     disconnected
 //
 // Usage
 //
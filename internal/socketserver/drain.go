@@ -0,0 +1,141 @@
+package socketserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// ErrServerDraining is returned for prompts submitted while the server shuts down
+var ErrServerDraining = errors.New("server is shutting down")
+
+// drainPollInterval is how often the drain phase checks for finished generations
+const drainPollInterval = 50 * time.Millisecond
+
+// BeginDrain stops the session manager from starting prompts. Running
+// generations continue; prompts still queued behind them are dropped.
+func (sm *SessionManager) BeginDrain() {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+	sm.draining = true
+}
+
+// IsDraining reports whether BeginDrain was called
+func (sm *SessionManager) IsDraining() bool {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+	return sm.draining
+}
+
+// ActiveGenerations returns the number of sessions currently generating
+func (sm *SessionManager) ActiveGenerations() int {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	active := 0
+	for _, q := range sm.promptQueues {
+		if q.generating {
+			active++
+		}
+	}
+	return active
+}
+
+// abortQueueIfDraining ends a session's prompt queue once the server drains
+// and returns the prompts that will not run anymore
+func (sm *SessionManager) abortQueueIfDraining(sessionID string) ([]QueuedPrompt, bool) {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	if !sm.draining {
+		return nil, false
+	}
+	q := sm.queueFor(sessionID)
+	dropped := q.prompts
+	q.prompts = nil
+	q.generating = false
+	return dropped, true
+}
+
+// WaitForGenerations blocks until no session is generating or ctx is done
+func (sm *SessionManager) WaitForGenerations(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if sm.ActiveGenerations() == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopWithTimeout shuts the server down gracefully: it stops accepting
+// connections, tells attached clients that the server is closing, waits for
+// running generations until ctx is done and then force-closes like Stop.
+// It returns an error if generations were still running at the deadline.
+func (s *Server) StopWithTimeout(ctx context.Context) error {
+	s.closeListener()
+	s.sessionManager.BeginDrain()
+
+	active := s.sessionManager.ActiveGenerations()
+	s.notifyClosing(ctx, active)
+
+	var drainErr error
+	if active > 0 {
+		logger.Info("Draining socket server: waiting for %d active generation(s)", active)
+		if err := s.sessionManager.WaitForGenerations(ctx); err != nil {
+			remaining := s.sessionManager.ActiveGenerations()
+			logger.Warn("Drain deadline reached with %d active generation(s), forcing shutdown", remaining)
+			drainErr = fmt.Errorf("drain interrupted with %d active generation(s): %w", remaining, err)
+		} else {
+			logger.Info("All generations finished, shutting down")
+		}
+	}
+
+	if err := s.Stop(); err != nil {
+		return err
+	}
+	return drainErr
+}
+
+// notifyClosing sends a closing notice with the expected wait to all clients
+func (s *Server) notifyClosing(ctx context.Context, activeGenerations int) {
+	data := map[string]interface{}{
+		"reason":             "server shutting down",
+		"active_generations": activeGenerations,
+	}
+	if deadline, ok := ctx.Deadline(); ok && activeGenerations > 0 {
+		wait := time.Until(deadline)
+		if wait < 0 {
+			wait = 0
+		}
+		data["estimated_wait_seconds"] = int(wait.Round(time.Second) / time.Second)
+	} else {
+		data["estimated_wait_seconds"] = 0
+	}
+
+	msg := NewMessage(MessageTypeClosing, data)
+	for _, client := range s.hub.clientList() {
+		client.Send(msg)
+	}
+}
+
+// closeListener stops accepting connections; safe to call more than once
+func (s *Server) closeListener() {
+	s.listenerOnce.Do(func() {
+		if s.listener == nil {
+			return
+		}
+		if err := s.listener.Close(); err != nil {
+			logger.Error("Error closing socket listener: %v", err)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package socketserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+// newDrainTestServer builds a server with a real listener but without the
+// accept loop, so the drain phase can be observed from the outside
+func newDrainTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "drain.sock")
+	cfg := config.DefaultConfig()
+	cfg.Socket.Path = socketPath
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	return &Server{
+		cfg:            cfg,
+		hub:            NewHub(),
+		sessionManager: &SessionManager{cfg: cfg},
+		listener:       listener,
+		clients:        make(map[string]*Client),
+		stopChan:       make(chan struct{}),
+	}, socketPath
+}
+
+// startMockGeneration runs a prompt through the session's queue that blocks until release is closed
+func startMockGeneration(t *testing.T, sm *SessionManager, sessionID string, release <-chan struct{}, completed *atomic.Bool) {
+	t.Helper()
+
+	prompt := QueuedPrompt{RequestID: "long-run", ClientID: "client-1", Content: "long task"}
+	if _, start, err := sm.EnqueuePrompt(sessionID, prompt); err != nil || !start {
+		t.Fatalf("expected generation to start, start=%v err=%v", start, err)
+	}
+	go sm.runPromptQueue(sessionID, prompt, func(QueuedPrompt) error {
+		<-release
+		completed.Store(true)
+		return nil
+	}, nil)
+}
+
+func TestStopWithTimeoutWaitsForActiveGeneration(t *testing.T) {
+	srv, socketPath := newDrainTestServer(t)
+
+	client := &Client{ID: "client-1", send: make(chan *BaseMessage, 4)}
+	srv.hub.mu.Lock()
+	srv.hub.clients[client] = true
+	srv.hub.mu.Unlock()
+
+	release := make(chan struct{})
+	var completed atomic.Bool
+	startMockGeneration(t, srv.sessionManager, "sess", release, &completed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- srv.StopWithTimeout(ctx)
+	}()
+
+	// Attached clients are told how long the shutdown may take
+	select {
+	case msg := <-client.send:
+		if msg.Type != MessageTypeClosing {
+			t.Fatalf("expected %q notice, got %q", MessageTypeClosing, msg.Type)
+		}
+		if msg.Data["active_generations"] != 1 {
+			t.Fatalf("expected 1 active generation in notice, got %v", msg.Data["active_generations"])
+		}
+		if wait, ok := msg.Data["estimated_wait_seconds"].(int); !ok || wait <= 0 || wait > 5 {
+			t.Fatalf("unexpected estimated wait: %v", msg.Data["estimated_wait_seconds"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for closing notice")
+	}
+
+	// Remove the fake client so the final force-close doesn't touch it
+	srv.hub.mu.Lock()
+	delete(srv.hub.clients, client)
+	srv.hub.mu.Unlock()
+
+	// While draining no connections or prompts are accepted
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		_ = conn.Close()
+		t.Fatal("expected new connections to be refused while draining")
+	}
+	if _, _, err := srv.sessionManager.EnqueuePrompt("other", QueuedPrompt{RequestID: "late"}); !errors.Is(err, ErrServerDraining) {
+		t.Fatalf("expected ErrServerDraining for new prompt, got %v", err)
+	}
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("server stopped before the generation finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("expected clean drain, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not stop after the generation finished")
+	}
+	if !completed.Load() {
+		t.Fatal("expected generation to complete before shutdown")
+	}
+	if srv.IsRunning() {
+		t.Fatal("expected server to be stopped")
+	}
+}
+
+func TestStopWithTimeoutForcesShutdownAtDeadline(t *testing.T) {
+	srv, _ := newDrainTestServer(t)
+
+	release := make(chan struct{})
+	defer close(release)
+	var completed atomic.Bool
+	startMockGeneration(t, srv.sessionManager, "sess", release, &completed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	err := srv.StopWithTimeout(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if completed.Load() {
+		t.Fatal("generation should still be running when the deadline forces shutdown")
+	}
+}
+
+func TestDrainDropsQueuedPrompts(t *testing.T) {
+	sm := newPromptQueueTestManager(10)
+
+	first := QueuedPrompt{RequestID: "req-1"}
+	queued := QueuedPrompt{RequestID: "req-2"}
+	if _, start, err := sm.EnqueuePrompt("sess", first); err != nil || !start {
+		t.Fatalf("expected first prompt to start, start=%v err=%v", start, err)
+	}
+	if _, _, err := sm.EnqueuePrompt("sess", queued); err != nil {
+		t.Fatalf("failed to queue prompt: %v", err)
+	}
+
+	results := make(map[string]error)
+	sm.runPromptQueue("sess", first, func(QueuedPrompt) error {
+		sm.BeginDrain()
+		return nil
+	}, func(p QueuedPrompt, err error) {
+		results[p.RequestID] = err
+	})
+
+	if err, ok := results["req-1"]; !ok || err != nil {
+		t.Fatalf("expected running prompt to complete, got %v (present=%v)", err, ok)
+	}
+	if err := results["req-2"]; !errors.Is(err, ErrServerDraining) {
+		t.Fatalf("expected queued prompt to be dropped with ErrServerDraining, got %v", err)
+	}
+	if active := sm.ActiveGenerations(); active != 0 {
+		t.Fatalf("expected no active generations after drain, got %d", active)
+	}
+}
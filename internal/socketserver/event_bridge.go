@@ -174,6 +174,8 @@ func (eb *EventBridge) convertEventToMessage(event actor.Event) *BaseMessage {
 		return eb.convertProgressEvent(event)
 	case actor.EventTypeMessage:
 		return eb.convertMessageEvent(event)
+	case actor.EventTypeReasoning:
+		return eb.convertReasoningEvent(event)
 	case actor.EventTypeToolCall:
 		return eb.convertToolCallEvent(event)
 	case actor.EventTypeToolResult:
@@ -220,6 +222,20 @@ func (eb *EventBridge) convertMessageEvent(event actor.Event) *BaseMessage {
 	return NewMessage(MessageTypeChatMessage, data)
 }
 
+func (eb *EventBridge) convertReasoningEvent(event actor.Event) *BaseMessage {
+	data := event.Data
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	// Ensure session_id is set
+	if event.SessionID != "" {
+		data["session_id"] = event.SessionID
+	}
+
+	return NewMessage(MessageTypeReasoning, data)
+}
+
 func (eb *EventBridge) convertToolCallEvent(event actor.Event) *BaseMessage {
 	data := event.Data
 	if data == nil {
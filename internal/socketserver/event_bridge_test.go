@@ -153,6 +153,16 @@ func TestEventBridge_ConvertEventToMessage(t *testing.T) {
 			},
 			wantType: MessageTypeChatMessage,
 		},
+		{
+			name: "reasoning event",
+			event: actor.Event{
+				Type:      actor.EventTypeReasoning,
+				Source:    "test",
+				SessionID: "session-1",
+				Data:      map[string]interface{}{"content": "thinking"},
+			},
+			wantType: MessageTypeReasoning,
+		},
 		{
 			name: "tool call event",
 			event: actor.Event{
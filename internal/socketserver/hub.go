@@ -20,6 +20,9 @@ type Hub struct {
 
 	// Session registry for tracking which client owns which session
 	sessions map[string]*Client // sessionID -> client
+
+	// Counters exposed by the metrics endpoint
+	metrics *Metrics
 }
 
 // NewHub creates a new hub
@@ -30,9 +33,15 @@ func NewHub() *Hub {
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		sessions:   make(map[string]*Client),
+		metrics:    NewMetrics(),
 	}
 }
 
+// Metrics returns the counters of the hub's server
+func (h *Hub) Metrics() *Metrics {
+	return h.metrics
+}
+
 // Run starts the hub's event loop
 func (h *Hub) Run() {
 	logger.Info("Socket hub started")
@@ -134,6 +143,19 @@ func (h *Hub) GetSessionOwner(sessionID string) (*Client, bool) {
 	return client, ok
 }
 
+// GetClient returns the connected client with the given ID
+func (h *Hub) GetClient(clientID string) (*Client, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if client.ID == clientID {
+			return client, true
+		}
+	}
+	return nil, false
+}
+
 // GetClientCount returns the number of connected clients
 func (h *Hub) GetClientCount() int {
 	h.mu.RLock()
@@ -172,15 +194,20 @@ func (h *Hub) ListSessions() []SessionInfo {
 func (h *Hub) Shutdown() {
 	logger.Info("Shutting down hub, closing all connections")
 
+	// Close all clients
+	for _, client := range h.clientList() {
+		client.Close()
+	}
+}
+
+// clientList returns a snapshot of the registered clients
+func (h *Hub) clientList() []*Client {
 	h.mu.RLock()
+	defer h.mu.RUnlock()
+
 	clients := make([]*Client, 0, len(h.clients))
 	for client := range h.clients {
 		clients = append(clients, client)
 	}
-	h.mu.RUnlock()
-
-	// Close all clients
-	for _, client := range clients {
-		client.Close()
-	}
+	return clients
 }
@@ -0,0 +1,214 @@
+package socketserver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// ErrSessionEvicted is returned when attaching to a session that was evicted
+// from memory after being idle; it has to be reloaded with session_load
+var ErrSessionEvicted = errors.New("session was evicted after being idle")
+
+// maxIdleCheckInterval bounds how long an idle session outlives its timeout
+const maxIdleCheckInterval = time.Minute
+
+// evictedRecordTTL is how long an eviction is remembered; afterwards requests
+// for the session get the regular not-found error
+const evictedRecordTTL = 24 * time.Hour
+
+// EvictedSession records a session that was dropped from memory
+type EvictedSession struct {
+	ID         string
+	WorkingDir string
+	EvictedAt  time.Time
+	Persisted  bool // Saved to storage before eviction
+}
+
+// clock returns the current time; tests replace now with a fake clock
+func (sm *SessionManager) clock() time.Time {
+	if sm.now != nil {
+		return sm.now()
+	}
+	return time.Now()
+}
+
+// touch records activity on a session. The caller must hold mu.
+func (sm *SessionManager) touch(info *SessionInternalInfo) {
+	now := sm.clock()
+	info.UpdatedAt = now
+	info.LastActivity = now
+}
+
+// touchSession records activity on a session by ID
+func (sm *SessionManager) touchSession(sessionID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if info, exists := sm.sessions[sessionID]; exists {
+		sm.touch(info)
+	}
+}
+
+// idleTimeout returns the configured idle timeout (0 = never evict)
+func (sm *SessionManager) idleTimeout() time.Duration {
+	if sm.cfg == nil {
+		return 0
+	}
+	return sm.cfg.Socket.GetSessionIdleTimeout()
+}
+
+// startIdleEviction starts the background eviction of idle sessions
+func (sm *SessionManager) startIdleEviction() {
+	timeout := sm.idleTimeout()
+	if timeout <= 0 {
+		return
+	}
+
+	interval := timeout / 2
+	if interval > maxIdleCheckInterval {
+		interval = maxIdleCheckInterval
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	sm.evictionStop = make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sm.EvictIdleSessions()
+			case <-sm.evictionStop:
+				return
+			}
+		}
+	}()
+
+	logger.Debug("Idle session eviction started with timeout %v", timeout)
+}
+
+// stopIdleEviction stops the background eviction; safe to call more than once
+func (sm *SessionManager) stopIdleEviction() {
+	sm.evictionOnce.Do(func() {
+		if sm.evictionStop != nil {
+			close(sm.evictionStop)
+		}
+	})
+}
+
+// EvictIdleSessions drops detached sessions without activity for longer than
+// the idle timeout from memory. With auto-save enabled a session is saved
+// first and kept if saving fails. Attached and generating sessions are kept.
+// It returns the IDs of the evicted sessions.
+func (sm *SessionManager) EvictIdleSessions() []string {
+	timeout := sm.idleTimeout()
+	if timeout <= 0 {
+		return nil
+	}
+
+	now := sm.clock()
+	var candidates []string
+	sm.mu.Lock()
+	sm.pruneEvictedLocked(now)
+	for id, info := range sm.sessions {
+		if info.OwnerClientID == "" && now.Sub(info.LastActivity) >= timeout {
+			candidates = append(candidates, id)
+		}
+	}
+	sm.mu.Unlock()
+
+	var evicted []string
+	for _, id := range candidates {
+		if generating, _ := sm.PromptQueueState(id); generating {
+			continue
+		}
+		if sm.evictSession(id, now, timeout) {
+			evicted = append(evicted, id)
+		}
+	}
+	return evicted
+}
+
+// evictSession persists (if auto-save is enabled) and removes one session
+func (sm *SessionManager) evictSession(sessionID string, now time.Time, timeout time.Duration) bool {
+	persisted := false
+	if sm.cfg.AutoSave.Enabled {
+		if err := sm.SaveSession(sessionID, ""); err != nil {
+			logger.Warn("Keeping idle session %s in memory, save failed: %v", sessionID, err)
+			return false
+		}
+		persisted = true
+	}
+
+	sm.mu.Lock()
+	info, exists := sm.sessions[sessionID]
+	// Re-check: a client may have attached or the session was used meanwhile
+	if !exists || info.OwnerClientID != "" || now.Sub(info.LastActivity) < timeout {
+		sm.mu.Unlock()
+		return false
+	}
+	delete(sm.sessions, sessionID)
+	if sm.evicted == nil {
+		sm.evicted = make(map[string]EvictedSession)
+	}
+	sm.evicted[sessionID] = EvictedSession{
+		ID:         sessionID,
+		WorkingDir: info.WorkingDir,
+		EvictedAt:  now,
+		Persisted:  persisted,
+	}
+	sm.mu.Unlock()
+
+	sm.objectsMu.Lock()
+	delete(sm.sessionObjects, sessionID)
+	sm.objectsMu.Unlock()
+
+	sm.removePromptQueue(sessionID)
+
+	logger.Info("Evicted idle session %s (persisted=%v)", sessionID, persisted)
+	return true
+}
+
+// pruneEvictedLocked forgets evictions older than evictedRecordTTL. The
+// caller must hold mu.
+func (sm *SessionManager) pruneEvictedLocked(now time.Time) {
+	for id, evicted := range sm.evicted {
+		if now.Sub(evicted.EvictedAt) >= evictedRecordTTL {
+			delete(sm.evicted, id)
+		}
+	}
+}
+
+// EvictedSessionInfo returns the eviction record of a session
+func (sm *SessionManager) EvictedSessionInfo(sessionID string) (EvictedSession, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	evicted, ok := sm.evicted[sessionID]
+	return evicted, ok
+}
+
+// sendSessionEvicted answers a request for an evicted session with a
+// session_evicted notice telling the client to reload it from storage
+func (c *Client) sendSessionEvicted(requestID string, evicted EvictedSession) {
+	details := "reload it from storage with session_load"
+	if !evicted.Persisted {
+		details = "the session was not saved because auto-save is disabled"
+	}
+
+	msg := NewResponse(MessageTypeSessionEvicted, requestID, map[string]interface{}{
+		"session_id":  evicted.ID,
+		"working_dir": evicted.WorkingDir,
+		"evicted_at":  evicted.EvictedAt.UTC().Format(time.RFC3339),
+		"persisted":   evicted.Persisted,
+	})
+	msg.Error = &ErrorInfo{
+		Code:    ErrorCodeSessionEvicted,
+		Message: ErrSessionEvicted.Error(),
+		Details: details,
+	}
+	c.Send(msg)
+}
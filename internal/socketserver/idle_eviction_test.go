@@ -0,0 +1,200 @@
+package socketserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// newIdleTestManager returns a session manager with isolated storage and a
+// fake clock that the test advances manually
+func newIdleTestManager(t *testing.T, timeout time.Duration) (*SessionManager, *time.Time) {
+	t.Helper()
+
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	cfg.Socket.SessionIdleTimeoutSecs = int(timeout / time.Second)
+
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return clock }
+	return sm, &clock
+}
+
+func TestIdleSessionEviction(t *testing.T) {
+	sm, clock := newIdleTestManager(t, 10*time.Minute)
+	workingDir := t.TempDir()
+
+	attachedID, _, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	if err := sm.AttachClient("client-1", attachedID); err != nil {
+		t.Fatalf("failed to attach: %v", err)
+	}
+
+	detachedID, detached, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	detached.AddMessage(&session.Message{Role: "user", Content: "hello"})
+
+	*clock = clock.Add(5 * time.Minute)
+	if evicted := sm.EvictIdleSessions(); len(evicted) != 0 {
+		t.Fatalf("expected no eviction before the timeout, got %v", evicted)
+	}
+
+	*clock = clock.Add(6 * time.Minute)
+	evicted := sm.EvictIdleSessions()
+	if len(evicted) != 1 || evicted[0] != detachedID {
+		t.Fatalf("expected only %s to be evicted, got %v", detachedID, evicted)
+	}
+
+	if _, ok := sm.GetSession(attachedID); !ok {
+		t.Fatal("attached session must stay in memory")
+	}
+	if _, ok := sm.GetSession(detachedID); ok {
+		t.Fatal("evicted session must be dropped from memory")
+	}
+
+	// The session was persisted before eviction
+	stored, err := sm.ListSessions(workingDir)
+	if err != nil {
+		t.Fatalf("failed to list sessions: %v", err)
+	}
+	found := false
+	for _, meta := range stored {
+		if meta.ID == detachedID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected evicted session %s in storage, got %+v", detachedID, stored)
+	}
+
+	// Reattaching yields a session_evicted notice
+	if err := sm.AttachClient("client-2", detachedID); !errors.Is(err, ErrSessionEvicted) {
+		t.Fatalf("expected ErrSessionEvicted, got %v", err)
+	}
+	client := &Client{ID: "client-2", sessionManager: sm, send: make(chan *BaseMessage, 4)}
+	req := NewRequest(MessageTypeSessionAttach, "attach-1", map[string]interface{}{"session_id": detachedID})
+	if err := client.handleSessionAttach(req); err != nil {
+		t.Fatalf("handleSessionAttach returned error: %v", err)
+	}
+	select {
+	case resp := <-client.send:
+		if resp.Type != MessageTypeSessionEvicted || resp.RequestID != "attach-1" {
+			t.Fatalf("expected %s response, got %+v", MessageTypeSessionEvicted, resp)
+		}
+		if resp.Error == nil || resp.Error.Code != ErrorCodeSessionEvicted {
+			t.Fatalf("expected %s error, got %+v", ErrorCodeSessionEvicted, resp.Error)
+		}
+		if resp.Data["persisted"] != true || resp.Data["session_id"] != detachedID {
+			t.Fatalf("unexpected notice data: %+v", resp.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no response to attach")
+	}
+
+	// Loading from storage brings the session back
+	if _, err := sm.LoadSession(workingDir, detachedID); err != nil {
+		t.Fatalf("failed to reload evicted session: %v", err)
+	}
+	if err := sm.AttachClient("client-2", detachedID); err != nil {
+		t.Fatalf("expected reloaded session to be attachable, got %v", err)
+	}
+}
+
+func TestIdleEvictionKeepsActiveSessions(t *testing.T) {
+	sm, clock := newIdleTestManager(t, 10*time.Minute)
+
+	sessionID, _, err := sm.CreateSession(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	// A generation keeps a detached session alive ...
+	if _, start, err := sm.EnqueuePrompt(sessionID, QueuedPrompt{RequestID: "req-1"}); err != nil || !start {
+		t.Fatalf("expected generation to start, start=%v err=%v", start, err)
+	}
+	*clock = clock.Add(time.Hour)
+	if evicted := sm.EvictIdleSessions(); len(evicted) != 0 {
+		t.Fatalf("generating session must not be evicted, got %v", evicted)
+	}
+
+	// ... and its idle timeout starts when the generation ends
+	sm.runPromptQueue(sessionID, QueuedPrompt{RequestID: "req-1"}, func(QueuedPrompt) error { return nil }, nil)
+	*clock = clock.Add(9 * time.Minute)
+	if evicted := sm.EvictIdleSessions(); len(evicted) != 0 {
+		t.Fatalf("recently active session must not be evicted, got %v", evicted)
+	}
+
+	*clock = clock.Add(2 * time.Minute)
+	if evicted := sm.EvictIdleSessions(); len(evicted) != 1 {
+		t.Fatalf("expected idle session to be evicted, got %v", evicted)
+	}
+}
+
+func TestIdleEvictionDisabled(t *testing.T) {
+	sm, clock := newIdleTestManager(t, 0)
+
+	if _, _, err := sm.CreateSession(t.TempDir()); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	*clock = clock.Add(24 * time.Hour)
+	if evicted := sm.EvictIdleSessions(); len(evicted) != 0 {
+		t.Fatalf("expected no eviction with timeout 0, got %v", evicted)
+	}
+}
+
+func TestEvictionRecordsAreForgotten(t *testing.T) {
+	sm, clock := newIdleTestManager(t, 10*time.Minute)
+	workingDir := t.TempDir()
+
+	deletedID, _, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	expiredID, _, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	*clock = clock.Add(11 * time.Minute)
+	if evicted := sm.EvictIdleSessions(); len(evicted) != 2 {
+		t.Fatalf("expected both sessions to be evicted, got %v", evicted)
+	}
+
+	// Deleting a session drops its eviction record
+	if err := sm.DeleteSession(workingDir, deletedID); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if _, ok := sm.EvictedSessionInfo(deletedID); ok {
+		t.Fatal("deleted session must not keep its eviction record")
+	}
+	if _, ok := sm.EvictedSessionInfo(expiredID); !ok {
+		t.Fatal("expected eviction record before it expires")
+	}
+
+	// Old records are pruned on the next eviction pass
+	*clock = clock.Add(evictedRecordTTL)
+	sm.EvictIdleSessions()
+	if _, ok := sm.EvictedSessionInfo(expiredID); ok {
+		t.Fatal("expected eviction record to expire")
+	}
+}
@@ -0,0 +1,82 @@
+package socketserver
+
+import (
+	"sort"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// defaultJobOutputLines is the number of output lines returned by job_status
+// when the request does not specify a limit
+const defaultJobOutputLines = 50
+
+// jobInfoFromSession converts a tracked job to its API representation. With
+// outputLines > 0 the last lines of stdout and stderr are included.
+func jobInfoFromSession(job *session.BackgroundJob, outputLines int) JobInfo {
+	completed := tools.BackgroundJobCompleted(job)
+
+	job.Mu.RLock()
+	defer job.Mu.RUnlock()
+
+	info := JobInfo{
+		JobID:         job.ID,
+		Command:       job.Command,
+		Type:          job.Type,
+		WorkingDir:    job.WorkingDir,
+		PID:           job.PID,
+		StopRequested: job.StopRequested,
+		LastSignal:    job.LastSignal,
+	}
+	if !job.StartTime.IsZero() {
+		info.StartedAt = job.StartTime.Format(time.RFC3339)
+	}
+
+	switch {
+	case completed:
+		info.State = JobStateCompleted
+		info.ExitCode = job.ExitCode
+	case job.StopRequested:
+		info.State = JobStateStopping
+	default:
+		info.State = JobStateRunning
+	}
+
+	if outputLines > 0 {
+		info.Stdout = tailLines(job.Stdout, outputLines)
+		info.Stderr = tailLines(job.Stderr, outputLines)
+	}
+	return info
+}
+
+// listSessionJobs returns the jobs tracked by a session, oldest first
+func listSessionJobs(sess *session.Session) []JobInfo {
+	jobs := sess.ListBackgroundJobs()
+	infos := make([]JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		infos = append(infos, jobInfoFromSession(job, 0))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].StartedAt != infos[j].StartedAt {
+			return infos[i].StartedAt < infos[j].StartedAt
+		}
+		return infos[i].JobID < infos[j].JobID
+	})
+	return infos
+}
+
+// markJobStopRequested records that a stop signal was sent to a job
+func markJobStopRequested(job *session.BackgroundJob, signal string) {
+	job.Mu.Lock()
+	defer job.Mu.Unlock()
+	job.StopRequested = true
+	job.LastSignal = signal
+}
+
+func tailLines(lines []string, n int) []string {
+	if len(lines) <= n {
+		return append([]string(nil), lines...)
+	}
+	return append([]string(nil), lines[len(lines)-n:]...)
+}
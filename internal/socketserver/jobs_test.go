@@ -0,0 +1,228 @@
+package socketserver
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// newJobsTestClient returns a client attached to a session that tracks a
+// finished build job and a running dev server
+func newJobsTestClient(t *testing.T) (*Client, *exec.Cmd) {
+	t.Helper()
+
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	workingDir := t.TempDir()
+	sessionID, sess, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sess.AddBackgroundJob(&session.BackgroundJob{
+		ID:        "job-build",
+		Command:   "go build ./...",
+		PID:       1234,
+		Type:      "shell",
+		StartTime: started,
+		Completed: true,
+		ExitCode:  1,
+		Stdout:    []string{"compiling", "linking"},
+		Stderr:    []string{"main.go:3: undefined: foo"},
+	})
+
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not available")
+	}
+	cmd := exec.Command(sleepPath, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start sleep: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-done
+	})
+	sess.AddBackgroundJob(&session.BackgroundJob{
+		ID:        "job-server",
+		Command:   "npm run dev",
+		PID:       cmd.Process.Pid,
+		Process:   cmd.Process,
+		Type:      "shell",
+		StartTime: started.Add(time.Minute),
+		Done:      done,
+	})
+
+	c := NewClient("test-client", nil, NewHub(), sm, nil, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+	c.SetSession(sessionID, workingDir)
+	return c, cmd
+}
+
+func sendJobRequest(t *testing.T, c *Client, msg *BaseMessage) *BaseMessage {
+	t.Helper()
+	if err := c.handleMessage(msg); err != nil {
+		t.Fatalf("handleMessage(%s) failed: %v", msg.Type, err)
+	}
+	return <-c.send
+}
+
+func TestJobsList(t *testing.T) {
+	c, cmd := newJobsTestClient(t)
+
+	resp := sendJobRequest(t, c, NewRequest(MessageTypeJobsList, "list-1", nil))
+	if resp.Type != MessageTypeJobsList {
+		t.Fatalf("expected jobs_list response, got %s: %+v", resp.Type, resp.Data)
+	}
+	jobs, ok := resp.Data["jobs"].([]JobInfo)
+	if !ok || len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %+v", resp.Data["jobs"])
+	}
+
+	if jobs[0].JobID != "job-build" || jobs[0].State != JobStateCompleted || jobs[0].ExitCode != 1 || jobs[0].PID != 1234 {
+		t.Errorf("unexpected build job: %+v", jobs[0])
+	}
+	if len(jobs[0].Stdout) != 0 {
+		t.Errorf("jobs_list should not include output, got %v", jobs[0].Stdout)
+	}
+	if jobs[1].JobID != "job-server" || jobs[1].State != JobStateRunning || jobs[1].PID != cmd.Process.Pid || jobs[1].Command != "npm run dev" {
+		t.Errorf("unexpected server job: %+v", jobs[1])
+	}
+}
+
+func TestJobStatus(t *testing.T) {
+	c, _ := newJobsTestClient(t)
+
+	resp := sendJobRequest(t, c, NewRequest(MessageTypeJobStatus, "status-1", map[string]interface{}{
+		"job_id":       "job-build",
+		"output_lines": 1,
+	}))
+	job, ok := resp.Data["job"].(JobInfo)
+	if !ok {
+		t.Fatalf("expected job in response, got %s: %+v", resp.Type, resp.Data)
+	}
+	if job.State != JobStateCompleted || len(job.Stdout) != 1 || job.Stdout[0] != "linking" || len(job.Stderr) != 1 {
+		t.Errorf("unexpected job status: %+v", job)
+	}
+
+	resp = sendJobRequest(t, c, NewRequest(MessageTypeJobStatus, "status-2", map[string]interface{}{"job_id": "missing"}))
+	if resp.Type != MessageTypeError {
+		t.Errorf("expected error for unknown job, got %s", resp.Type)
+	}
+}
+
+func TestJobStop(t *testing.T) {
+	c, _ := newJobsTestClient(t)
+
+	resp := sendJobRequest(t, c, NewRequest(MessageTypeJobStop, "stop-1", map[string]interface{}{"job_id": "job-build"}))
+	if resp.Type != MessageTypeError || resp.Error == nil || resp.Error.Code != ErrorCodeOperationNotAllowed {
+		t.Errorf("expected completed job to be rejected, got %s: %+v", resp.Type, resp.Error)
+	}
+
+	resp = sendJobRequest(t, c, NewRequest(MessageTypeJobStop, "stop-2", map[string]interface{}{"job_id": "job-server", "signal": "SIGHUP"}))
+	if resp.Type != MessageTypeError {
+		t.Errorf("expected unsupported signal to be rejected, got %s", resp.Type)
+	}
+
+	resp = sendJobRequest(t, c, NewRequest(MessageTypeJobStop, "stop-3", map[string]interface{}{"job_id": "job-server", "signal": "SIGKILL"}))
+	if resp.Type != MessageTypeJobStop || resp.Data["signal"] != "SIGKILL" {
+		t.Fatalf("expected job_stop response, got %s: %+v %+v", resp.Type, resp.Data, resp.Error)
+	}
+
+	sess, _ := c.sessionManager.GetSession(c.GetSession())
+	job, _ := sess.GetBackgroundJob("job-server")
+	select {
+	case <-job.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the job process to exit after SIGKILL")
+	}
+
+	resp = sendJobRequest(t, c, NewRequest(MessageTypeJobStatus, "status-3", map[string]interface{}{"job_id": "job-server"}))
+	if info, _ := resp.Data["job"].(JobInfo); info.State != JobStateCompleted || info.LastSignal != "SIGKILL" {
+		t.Errorf("expected stopped job to be completed, got %+v", info)
+	}
+}
+
+func TestProcessListAndKill(t *testing.T) {
+	c, cmd := newJobsTestClient(t)
+
+	// A client that is not attached to any session, as after a crash
+	other := NewClient("other-client", nil, NewHub(), c.sessionManager, nil, nil, nil, nil, c.cfg, nil)
+	other.setAuthenticated(true)
+
+	resp := sendJobRequest(t, other, NewRequest(MessageTypeProcessList, "list-1", nil))
+	processes, ok := resp.Data["processes"].([]ProcessInfo)
+	if !ok || len(processes) != 1 {
+		t.Fatalf("expected 1 running process, got %s: %+v", resp.Type, resp.Data)
+	}
+	if processes[0].PID != cmd.Process.Pid || processes[0].JobID != "job-server" || processes[0].SessionID != c.GetSession() || !processes[0].Orphaned {
+		t.Errorf("unexpected process: %+v", processes[0])
+	}
+
+	// A process this server did not start as a job
+	untracked := exec.Command(cmd.Path, "30")
+	if err := untracked.Start(); err != nil {
+		t.Fatalf("failed to start sleep: %v", err)
+	}
+	untrackedDone := make(chan struct{})
+	go func() {
+		_ = untracked.Wait()
+		close(untrackedDone)
+	}()
+	t.Cleanup(func() {
+		_ = untracked.Process.Kill()
+		<-untrackedDone
+	})
+
+	for _, pid := range []int{untracked.Process.Pid, 1234} {
+		resp = sendJobRequest(t, other, NewRequest(MessageTypeProcessKill, "kill-1", map[string]interface{}{"pid": pid, "signal": "SIGKILL"}))
+		if resp.Error == nil || resp.Error.Code != ErrorCodeOperationNotAllowed {
+			t.Errorf("expected pid %d to be rejected, got %s: %+v", pid, resp.Type, resp.Error)
+		}
+	}
+	select {
+	case <-untrackedDone:
+		t.Fatal("untracked process must not be terminated")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	resp = sendJobRequest(t, other, NewRequest(MessageTypeProcessKill, "kill-2", map[string]interface{}{"pid": cmd.Process.Pid}))
+	if resp.Type != MessageTypeProcessKill || resp.Data["signal"] != "SIGTERM" {
+		t.Fatalf("expected process_kill response, got %s: %+v %+v", resp.Type, resp.Data, resp.Error)
+	}
+
+	sess, _ := c.sessionManager.GetSession(c.GetSession())
+	job, _ := sess.GetBackgroundJob("job-server")
+	select {
+	case <-job.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the job process to exit after SIGTERM")
+	}
+
+	resp = sendJobRequest(t, other, NewRequest(MessageTypeProcessList, "list-2", nil))
+	if processes, _ := resp.Data["processes"].([]ProcessInfo); len(processes) != 0 {
+		t.Errorf("expected no running processes, got %+v", processes)
+	}
+}
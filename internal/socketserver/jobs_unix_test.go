@@ -0,0 +1,60 @@
+//go:build !windows
+
+package socketserver
+
+import (
+	"io"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestJobStopSignalsProcessGroup(t *testing.T) {
+	c, _ := newJobsTestClient(t)
+
+	// The backgrounded sleep inherits stdout, so reading it only ends once
+	// the whole process group is gone
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("failed to create stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start sh: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	})
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, stdout)
+		_ = cmd.Wait()
+		close(done)
+	}()
+
+	sess, _ := c.sessionManager.GetSession(c.GetSession())
+	sess.AddBackgroundJob(&session.BackgroundJob{
+		ID:             "job-group",
+		Command:        "npm run watch",
+		PID:            cmd.Process.Pid,
+		ProcessGroupID: cmd.Process.Pid,
+		Process:        cmd.Process,
+		Type:           "shell",
+		StartTime:      time.Now(),
+		Done:           done,
+	})
+
+	resp := sendJobRequest(t, c, NewRequest(MessageTypeJobStop, "stop-group", map[string]interface{}{"job_id": "job-group", "signal": "SIGKILL"}))
+	if resp.Type != MessageTypeJobStop {
+		t.Fatalf("expected job_stop response, got %s: %+v", resp.Type, resp.Error)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the children of the job to be stopped with its process group")
+	}
+}
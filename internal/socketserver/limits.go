@@ -0,0 +1,122 @@
+package socketserver
+
+import (
+	"bufio"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// errMessageTooLarge is returned when a client message exceeds the size limit
+var errMessageTooLarge = errors.New("message exceeds size limit")
+
+// readMessageLine reads one newline-delimited message. Lines longer than
+// maxBytes (0 = unlimited) are consumed up to their newline without being
+// buffered and errMessageTooLarge is returned, so the reader stays in sync.
+func readMessageLine(r *bufio.Reader, maxBytes int) ([]byte, error) {
+	var line []byte
+	tooLarge := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLarge {
+			if maxBytes > 0 && len(line)+len(chunk) > maxBytes+1 {
+				// +1 leaves room for the delimiter
+				tooLarge = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+
+		switch {
+		case err == nil:
+			if tooLarge {
+				return nil, errMessageTooLarge
+			}
+			return line, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		default:
+			return nil, err
+		}
+	}
+}
+
+// messageRateLimiter is a token bucket limiting how many messages a single
+// connection may send per second; bursts up to one second's worth pass.
+type messageRateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// newMessageRateLimiter returns a limiter for perSecond messages, or nil if
+// perSecond is 0 (unlimited)
+func newMessageRateLimiter(perSecond int) *messageRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &messageRateLimiter{
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+		now:    time.Now,
+	}
+}
+
+// allow takes a token if one is available. A nil limiter allows everything.
+func (l *messageRateLimiter) allow() bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.rate {
+			l.tokens = l.rate
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// maxMessageBytes returns the configured message size limit (0 = unlimited)
+func (c *Client) maxMessageBytes() int {
+	if c.cfg == nil {
+		return 0
+	}
+	return c.cfg.Socket.GetMaxMessageBytes()
+}
+
+// disconnectOnLimit reports whether limit violations close the connection
+func (c *Client) disconnectOnLimit() bool {
+	return c.cfg != nil && c.cfg.Socket.DisconnectOnLimit
+}
+
+// rejectOverLimit answers a message that violated a limit. It returns true if
+// the connection must be closed, in which case the error was already written.
+func (c *Client) rejectOverLimit(requestID, code, message, details string) bool {
+	errMsg := NewError(requestID, code, message, details)
+	if !c.disconnectOnLimit() {
+		c.Send(errMsg)
+		return false
+	}
+
+	// Write synchronously so the client sees the reason before the close
+	if err := c.writeMessage(errMsg); err != nil {
+		logger.Warn("Failed to send limit error to client %s: %v", c.ID, err)
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package socketserver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+// limitTestConn is the client side of a connection to a started server client
+type limitTestConn struct {
+	t         *testing.T
+	conn      net.Conn
+	responses chan *BaseMessage
+}
+
+// startLimitTestClient runs an authenticated server client on one end of a
+// pipe and returns the other end
+func startLimitTestClient(t *testing.T, configure func(*config.SocketConfig), prepare func(*Client)) *limitTestConn {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	if configure != nil {
+		configure(&cfg.Socket)
+	}
+
+	hub := NewHub()
+	go hub.Run()
+
+	serverConn, clientConn := net.Pipe()
+	client := NewClient("limit-client", serverConn, hub, nil, nil, nil, nil, nil, cfg, nil)
+	client.setAuthenticated(true)
+	if prepare != nil {
+		prepare(client)
+	}
+	client.Start()
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+		client.Stop()
+	})
+
+	tc := &limitTestConn{t: t, conn: clientConn, responses: make(chan *BaseMessage, 64)}
+	go func() {
+		defer close(tc.responses)
+		scanner := bufio.NewScanner(clientConn)
+		for scanner.Scan() {
+			var msg BaseMessage
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err == nil {
+				tc.responses <- &msg
+			}
+		}
+	}()
+	return tc
+}
+
+func (tc *limitTestConn) write(line string) {
+	tc.t.Helper()
+	if _, err := fmt.Fprintf(tc.conn, "%s\n", line); err != nil {
+		tc.t.Fatalf("failed to write: %v", err)
+	}
+}
+
+// next returns the next response; nil means the connection was closed
+func (tc *limitTestConn) next() *BaseMessage {
+	tc.t.Helper()
+	select {
+	case msg := <-tc.responses:
+		return msg
+	case <-time.After(2 * time.Second):
+		tc.t.Fatal("timed out waiting for a response")
+		return nil
+	}
+}
+
+const pingLine = `{"type":"ping","request_id":"ping"}`
+
+func TestOversizedMessageIsRejected(t *testing.T) {
+	tc := startLimitTestClient(t, func(s *config.SocketConfig) {
+		s.MaxMessageBytes = 1024
+	}, nil)
+
+	tc.write(`{"type":"ping","data":{"padding":"` + strings.Repeat("x", 8192) + `"}}`)
+	resp := tc.next()
+	if resp == nil || resp.Error == nil || resp.Error.Code != ErrorCodeMessageTooLarge {
+		t.Fatalf("expected %s error, got %+v", ErrorCodeMessageTooLarge, resp)
+	}
+
+	// The connection stays usable after the oversized frame was skipped
+	tc.write(pingLine)
+	if resp := tc.next(); resp == nil || resp.Type != MessageTypePong {
+		t.Fatalf("expected pong after rejected message, got %+v", resp)
+	}
+}
+
+func TestOversizedMessageDisconnects(t *testing.T) {
+	tc := startLimitTestClient(t, func(s *config.SocketConfig) {
+		s.MaxMessageBytes = 1024
+		s.DisconnectOnLimit = true
+	}, nil)
+
+	tc.write(strings.Repeat("x", 4096))
+	resp := tc.next()
+	if resp == nil || resp.Error == nil || resp.Error.Code != ErrorCodeMessageTooLarge {
+		t.Fatalf("expected %s error before disconnect, got %+v", ErrorCodeMessageTooLarge, resp)
+	}
+	if resp := tc.next(); resp != nil {
+		t.Fatalf("expected connection to be closed, got %+v", resp)
+	}
+}
+
+func TestMessageBurstIsThrottled(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tc := startLimitTestClient(t, func(s *config.SocketConfig) {
+		s.MaxMessagesPerSecond = 5
+	}, func(c *Client) {
+		c.rateLimiter.now = func() time.Time { return clock }
+	})
+
+	const burst = 12
+	for i := 0; i < burst; i++ {
+		tc.write(pingLine)
+	}
+
+	pongs, limited := 0, 0
+	for i := 0; i < burst; i++ {
+		resp := tc.next()
+		switch {
+		case resp == nil:
+			t.Fatal("connection closed unexpectedly")
+		case resp.Type == MessageTypePong:
+			pongs++
+		case resp.Error != nil && resp.Error.Code == ErrorCodeRateLimited:
+			if resp.RequestID != "ping" {
+				t.Fatalf("expected rate limit error for request 'ping', got %q", resp.RequestID)
+			}
+			limited++
+		default:
+			t.Fatalf("unexpected response %+v", resp)
+		}
+	}
+	if pongs != 5 || limited != burst-5 {
+		t.Fatalf("expected 5 pongs and %d rate limit errors, got %d and %d", burst-5, pongs, limited)
+	}
+}
+
+func TestMessageRateLimiterRefills(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newMessageRateLimiter(2)
+	limiter.now = func() time.Time { return clock }
+
+	if !limiter.allow() || !limiter.allow() {
+		t.Fatal("expected burst of 2 to pass")
+	}
+	if limiter.allow() {
+		t.Fatal("expected third message to be throttled")
+	}
+
+	clock = clock.Add(500 * time.Millisecond)
+	if !limiter.allow() {
+		t.Fatal("expected a token after half a second")
+	}
+	if limiter.allow() {
+		t.Fatal("expected only one token to be refilled")
+	}
+
+	if newMessageRateLimiter(0) != nil || !(*messageRateLimiter)(nil).allow() {
+		t.Fatal("expected a zero rate to disable the limiter")
+	}
+}
+
+func TestDecompressMessageSizeLimit(t *testing.T) {
+	raw, err := json.Marshal(map[string]interface{}{"content": strings.Repeat("a", 64*1024)})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(raw)
+	_ = zw.Close()
+
+	msg := &BaseMessage{Type: "chat_send", Compression: CompressionGzip, Payload: base64.StdEncoding.EncodeToString(buf.Bytes())}
+	if err := decompressMessage(msg, 4096); !errors.Is(err, errMessageTooLarge) {
+		t.Fatalf("expected errMessageTooLarge for inflated payload, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package socketserver
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestMessagePin(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	workingDir := t.TempDir()
+	sessionID, sess, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	sess.AddMessage(&session.Message{Role: "user", Content: "Spec: keep the API stable"})
+	sess.AddMessage(&session.Message{Role: "assistant", Content: "Noted"})
+
+	c := NewClient("test-client", nil, NewHub(), sm, nil, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+	c.SetSession(sessionID, workingDir)
+
+	send := func(msg *BaseMessage) *BaseMessage {
+		t.Helper()
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		return <-c.send
+	}
+
+	resp := send(NewRequest(MessageTypeMessagePin, "pin-1", map[string]interface{}{"index": 0}))
+	if resp.Type != MessageTypeMessagePin || resp.Data["pinned"] != true {
+		t.Fatalf("expected message_pin response, got %s: %+v %+v", resp.Type, resp.Data, resp.Error)
+	}
+	if indices, _ := resp.Data["pinned_indices"].([]int); len(indices) != 1 || indices[0] != 0 {
+		t.Errorf("expected pinned_indices [0], got %v", resp.Data["pinned_indices"])
+	}
+	if !sess.GetMessages()[0].Pinned {
+		t.Error("expected the session message to be pinned")
+	}
+
+	resp = send(NewRequest(MessageTypeMessagePin, "pin-2", map[string]interface{}{"index": 5}))
+	if resp.Type != MessageTypeError {
+		t.Errorf("expected error for out of range index, got %s", resp.Type)
+	}
+
+	resp = send(NewRequest(MessageTypeMessagePin, "pin-3", map[string]interface{}{"index": 0, "pinned": false}))
+	if resp.Type != MessageTypeMessagePin || resp.Data["pinned"] != false {
+		t.Fatalf("expected unpin response, got %s: %+v", resp.Type, resp.Data)
+	}
+	if sess.GetMessages()[0].Pinned {
+		t.Error("expected the session message to be unpinned")
+	}
+}
@@ -1,6 +1,10 @@
 package socketserver
 
-import "time"
+import (
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
 
 // Message type constants
 const (
@@ -16,12 +20,37 @@ const (
 	MessageTypeSessionList           = "session_list"
 	MessageTypeSessionListResponse   = "session_list_response"
 	MessageTypeSessionDelete         = "session_delete"
+	MessageTypeSessionRename         = "session_rename"
+	MessageTypeSessionDuplicate      = "session_duplicate"
+	MessageTypeSessionTakeover       = "session_takeover"
+	MessageTypeSessionState          = "session_state"
+	MessageTypeSessionEvicted        = "session_evicted" // Session was dropped from memory after being idle
 
 	// Chat & Generation
 	MessageTypeChatSend    = "chat_send"
 	MessageTypeChatStop    = "chat_stop"
 	MessageTypeChatClear   = "chat_clear"
+	MessageTypeChatCompact = "chat_compact" // Summarize earlier context right away
 	MessageTypeChatMessage = "chat_message"
+	MessageTypeReasoning   = "reasoning" // Reasoning/thinking of the model, sent apart from chat_message
+	MessageTypePause       = "pause"
+	MessageTypeResume      = "resume"
+
+	// Prompt Queue
+	MessageTypeChatDequeue    = "chat_dequeue"
+	MessageTypeChatQueueClear = "chat_queue_clear"
+
+	// Background Jobs
+	MessageTypeJobsList  = "jobs_list"
+	MessageTypeJobStatus = "job_status"
+	MessageTypeJobStop   = "job_stop"
+
+	// Background Processes (of all sessions)
+	MessageTypeProcessList = "process_list"
+	MessageTypeProcessKill = "process_kill"
+
+	// Message Pinning
+	MessageTypeMessagePin = "message_pin"
 
 	// Tool Interactions
 	MessageTypeToolCall    = "tool_call"
@@ -44,26 +73,40 @@ const (
 	MessageTypeConfigGet = "config_get"
 	MessageTypeConfigSet = "config_set"
 
+	// Models
+	MessageTypeModelsList = "models_list"
+	MessageTypeModelSet   = "model_set"
+
 	// Workspace Management
 	MessageTypeWorkspaceList         = "workspace_list"
 	MessageTypeWorkspaceListResponse = "workspace_list_response"
 	MessageTypeWorkspaceSet          = "workspace_set"
+	MessageTypeWorkspaceAudit        = "workspace_audit"
+
+	// Context Directories
+	MessageTypeContextDirAdd    = "context_dir_add"
+	MessageTypeContextDirRemove = "context_dir_remove"
+	MessageTypeContextDirList   = "context_dir_list"
 
 	// Session Persistence
 	MessageTypeSessionSave = "session_save"
 	MessageTypeSessionLoad = "session_load"
 
 	// Connection Lifecycle
-	MessageTypePing   = "ping"
-	MessageTypePong   = "pong"
-	MessageTypeClose  = "close"
-	MessageTypeClosed = "closed"
+	MessageTypePing    = "ping"
+	MessageTypePong    = "pong"
+	MessageTypeClose   = "close"
+	MessageTypeClosed  = "closed"
+	MessageTypeClosing = "closing" // Server is draining before shutdown
 
 	// Error
 	MessageTypeError = "error"
 
 	// Flow Control
 	MessageTypeFlowControl = "flow_control"
+
+	// Batching
+	MessageTypeBatch = "batch"
 )
 
 // BaseMessage represents the base structure for all socket messages
@@ -73,6 +116,11 @@ type BaseMessage struct {
 	Data      map[string]interface{} `json:"data"`
 	Timestamp string                 `json:"timestamp,omitempty"`
 	Error     *ErrorInfo             `json:"error,omitempty"`
+
+	// Compression names the algorithm used for Payload (empty = uncompressed)
+	Compression string `json:"compression,omitempty"`
+	// Payload holds the base64-encoded compressed data if Compression is set
+	Payload string `json:"payload,omitempty"`
 }
 
 // ErrorInfo contains error details
@@ -133,12 +181,56 @@ type AuthRequest struct {
 	Token        string   `json:"token,omitempty"`
 }
 
+// ProtocolVersion is the version of the socket protocol spoken by the server
+const ProtocolVersion = "1.0.0"
+
+// serverCapabilities are supported on every connection; compression is
+// negotiated per connection
+var serverCapabilities = []string{"sessions", "workspaces", "chat", "progress", "authorization", "questions"}
+
+// Protocol features advertised in the capabilities of auth_response besides
+// the supported request types
+const (
+	CapabilityBatching        = "batching"         // batch requests
+	CapabilityStreamingDeltas = "streaming_deltas" // chat_message chunks with stream_id
+)
+
+// supportedRequestTypes are the client-to-server message types handled by the server
+var supportedRequestTypes = []string{
+	MessageTypeAuthRequest, MessageTypePing, MessageTypePong, MessageTypeClose,
+	MessageTypeSessionCreate, MessageTypeSessionAttach, MessageTypeSessionDetach,
+	MessageTypeSessionTakeover, MessageTypeSessionList, MessageTypeSessionDelete,
+	MessageTypeSessionRename, MessageTypeSessionDuplicate,
+	MessageTypeSessionState, MessageTypeSessionSave, MessageTypeSessionLoad,
+	MessageTypeChatSend, MessageTypeChatStop, MessageTypeChatClear, MessageTypeChatCompact,
+	MessageTypePause, MessageTypeResume, MessageTypeChatDequeue, MessageTypeChatQueueClear,
+	MessageTypeJobsList, MessageTypeJobStatus, MessageTypeJobStop, MessageTypeProcessList, MessageTypeProcessKill, MessageTypeMessagePin,
+	MessageTypeConfigGet, MessageTypeConfigSet, MessageTypeModelsList, MessageTypeModelSet, MessageTypeWorkspaceList, MessageTypeWorkspaceSet,
+	MessageTypeWorkspaceAudit, MessageTypeContextDirAdd, MessageTypeContextDirRemove, MessageTypeContextDirList,
+	MessageTypeAuthorizationResponse, MessageTypeQuestionResponse, MessageTypeBatch,
+}
+
+// protocolCapabilities lists the supported request types and protocol
+// features; compression is only included if it was negotiated
+func protocolCapabilities(compression bool) []string {
+	capabilities := append([]string(nil), supportedRequestTypes...)
+	capabilities = append(capabilities, CapabilityBatching, CapabilityStreamingDeltas)
+	if compression {
+		capabilities = append(capabilities, CapabilityCompression)
+	}
+	return capabilities
+}
+
 // AuthResponse data for authentication response
 type AuthResponse struct {
 	Success            bool     `json:"success"`
 	ConnectionID       string   `json:"connection_id"`
 	ServerVersion      string   `json:"server_version"`
 	ServerCapabilities []string `json:"server_capabilities"`
+	// Capabilities lists the supported request types and protocol features
+	Capabilities []string `json:"capabilities"`
+	// ToolSchemaVersion changes whenever the names of generated MCP tools do
+	ToolSchemaVersion int `json:"tool_schema_version"`
 }
 
 // SessionCreateRequest data for session creation
@@ -162,9 +254,19 @@ type SessionAttachRequest struct {
 	SessionID string `json:"session_id"`
 }
 
+// SessionTakeoverRequest data for reclaiming a session from a stale connection
+type SessionTakeoverRequest struct {
+	SessionID string `json:"session_id"`
+}
+
 // SessionListRequest data for listing sessions
 type SessionListRequest struct {
 	Workspace string `json:"workspace,omitempty"`
+	Title     string `json:"title,omitempty"`  // case-insensitive title substring
+	Since     string `json:"since,omitempty"`  // RFC3339, updated at or after
+	Until     string `json:"until,omitempty"`  // RFC3339, updated before
+	Limit     int    `json:"limit,omitempty"`  // page size (0 = all)
+	Offset    int    `json:"offset,omitempty"` // matching sessions to skip
 }
 
 // SessionInfo represents session information in list response
@@ -186,6 +288,11 @@ type SessionInfoResponse struct {
 	CreatedAt    string `json:"created_at"`
 	UpdatedAt    string `json:"updated_at"`
 	MessageCount int    `json:"message_count"`
+	// LastMessageAt is the RFC3339 timestamp of the newest message
+	LastMessageAt string `json:"last_message_at,omitempty"`
+	// TotalTokens and TotalCost sum the usage of all prompts of the session
+	TotalTokens int     `json:"total_tokens,omitempty"`
+	TotalCost   float64 `json:"total_cost,omitempty"`
 }
 
 // SessionListResponse data for session list response
@@ -193,6 +300,68 @@ type SessionListResponse struct {
 	Sessions []SessionInfo `json:"sessions"`
 }
 
+// SessionStateResponse data describing the generation state of a session
+type SessionStateResponse struct {
+	SessionID     string `json:"session_id"`
+	Generating    bool   `json:"generating"`
+	QueuedPrompts int    `json:"queued_prompts"`
+	CurrentModel  string `json:"current_model"`
+}
+
+// Background job states reported in JobInfo
+const (
+	JobStateRunning   = "running"
+	JobStateStopping  = "stopping"
+	JobStateCompleted = "completed"
+)
+
+// JobInfo describes a background program tracked by a session
+type JobInfo struct {
+	JobID         string   `json:"job_id"`
+	Command       string   `json:"command"`
+	Type          string   `json:"type,omitempty"`
+	WorkingDir    string   `json:"working_dir,omitempty"`
+	PID           int      `json:"pid,omitempty"`
+	State         string   `json:"state"`
+	ExitCode      int      `json:"exit_code"`
+	StartedAt     string   `json:"started_at,omitempty"`
+	StopRequested bool     `json:"stop_requested,omitempty"`
+	LastSignal    string   `json:"last_signal,omitempty"`
+	Stdout        []string `json:"stdout,omitempty"`
+	Stderr        []string `json:"stderr,omitempty"`
+}
+
+// JobStatusRequest data for querying a background job
+type JobStatusRequest struct {
+	JobID       string `json:"job_id"`
+	OutputLines int    `json:"output_lines,omitempty"` // Last lines of output to include (default 50, <0 = none)
+}
+
+// JobStopRequest data for stopping a background job
+type JobStopRequest struct {
+	JobID  string `json:"job_id"`
+	Signal string `json:"signal,omitempty"` // SIGTERM (default) or SIGKILL
+}
+
+// ProcessInfo describes a running background process started by a session of
+// the server
+type ProcessInfo struct {
+	tools.BackgroundProcess
+	Orphaned bool `json:"orphaned"` // No client is attached to the session
+}
+
+// ProcessKillRequest data for terminating a background process
+type ProcessKillRequest struct {
+	PID    int    `json:"pid"`
+	Signal string `json:"signal,omitempty"` // SIGTERM (default) or SIGKILL
+}
+
+// MessagePinRequest data for pinning or unpinning a session message
+type MessagePinRequest struct {
+	Index  int   `json:"index"`            // Index of the message in the session
+	Pinned *bool `json:"pinned,omitempty"` // false unpins (default: true)
+}
+
 // SessionDeleteRequest data for deleting a session
 type SessionDeleteRequest struct {
 	SessionID string `json:"session_id"`
@@ -201,9 +370,25 @@ type SessionDeleteRequest struct {
 
 // ChatSendRequest data for sending chat messages
 type ChatSendRequest struct {
-	Content string                 `json:"content"`
-	Prompt  string                 `json:"prompt,omitempty"` // Alias for content
-	Options map[string]interface{} `json:"options,omitempty"`
+	Content     string                 `json:"content"`
+	Prompt      string                 `json:"prompt,omitempty"` // Alias for content
+	Options     map[string]interface{} `json:"options,omitempty"`
+	Attachments []Attachment           `json:"attachments,omitempty"`
+	Model       string                 `json:"model,omitempty"`    // Model for this generation only
+	Provider    string                 `json:"provider,omitempty"` // Provider of the model, or its default model if no model is given
+}
+
+// Attachment is a file whose content is included as context before the
+// prompt of a chat_send
+type Attachment struct {
+	Path      string `json:"path"`                 // relative to the workspace or absolute
+	StartLine int    `json:"start_line,omitempty"` // first line to include (1-based, 0 = from the start)
+	EndLine   int    `json:"end_line,omitempty"`   // last line to include (0 = to the end)
+}
+
+// ChatDequeueRequest data for removing a queued prompt
+type ChatDequeueRequest struct {
+	RequestID string `json:"request_id,omitempty"` // Request ID of the queued chat_send (empty = most recent)
 }
 
 // ChatMessage data for streaming chat messages
@@ -213,7 +398,13 @@ type ChatMessage struct {
 	StreamID   string `json:"stream_id,omitempty"`
 	ChunkIndex int    `json:"chunk_index,omitempty"`
 	IsFinal    bool   `json:"is_final,omitempty"`
-	Reasoning  bool   `json:"reasoning,omitempty"` // Extended thinking content
+}
+
+// ReasoningMessage data for the reasoning/thinking content of a model response.
+// It is never part of chat_message content, so frontends can render it apart,
+// e.g. collapsed.
+type ReasoningMessage struct {
+	Content string `json:"content"`
 }
 
 // ToolCallRequest data for tool call notification
@@ -307,7 +498,22 @@ type ConfigGetRequest struct {
 
 // ConfigSetRequest data for setting configuration
 type ConfigSetRequest struct {
-	Values map[string]interface{} `json:"values"`
+	Values  map[string]interface{} `json:"values"`
+	Version *uint64                `json:"version"`           // Config version from config_get; rejected with VERSION_CONFLICT if the config changed since
+	Persist bool                   `json:"persist,omitempty"` // Also save the config file (default: running server only)
+}
+
+// ModelsListRequest data for listing the available models
+type ModelsListRequest struct {
+	Provider string `json:"provider,omitempty"` // only list models of this provider
+}
+
+// ModelSetRequest data for selecting a model
+type ModelSetRequest struct {
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"` // picks the provider's default model if model is empty
+	Role     string `json:"role,omitempty"`     // "orchestration" (default), "summarize", "planning" or "safety"
+	Scope    string `json:"scope,omitempty"`    // "session" (default when attached) or "global"
 }
 
 // WorkspaceInfo represents workspace information (for API responses)
@@ -327,6 +533,9 @@ type WorkspaceInfo struct {
 	LandlockWrite    []string        `json:"landlock_write"`
 	DomainsApproved  map[string]bool `json:"domains_approved"`
 	CommandsApproved map[string]bool `json:"commands_approved"`
+
+	OrchestrationModel string `json:"orchestration_model,omitempty"`
+	SummarizeModel     string `json:"summarize_model,omitempty"`
 }
 
 // WorkspaceListResponse data for workspace list response
@@ -334,9 +543,26 @@ type WorkspaceListResponse struct {
 	Workspaces []WorkspaceInfo `json:"workspaces"`
 }
 
-// WorkspaceSetRequest data for setting workspace
+// WorkspaceSetRequest data for setting workspace. The optional model fields
+// set the workspace's default models for new sessions; an empty string resets
+// a default to the global selection.
 type WorkspaceSetRequest struct {
-	Workspace string `json:"workspace"`
+	Workspace          string  `json:"workspace"`
+	OrchestrationModel *string `json:"orchestration_model,omitempty"`
+	SummarizeModel     *string `json:"summarize_model,omitempty"`
+}
+
+// MaxWorkspaceAuditEntries is the most audit entries returned per query
+const MaxWorkspaceAuditEntries = 1000
+
+// WorkspaceAuditRequest data for querying a workspace audit log. Since and
+// Until are RFC 3339 times bounding the entries; empty values leave the range
+// open. Without a workspace the connection's workspace is used.
+type WorkspaceAuditRequest struct {
+	Workspace string `json:"workspace,omitempty"`
+	Since     string `json:"since,omitempty"`
+	Until     string `json:"until,omitempty"`
+	Limit     int    `json:"limit,omitempty"` // most recent entries to return (0 = MaxWorkspaceAuditEntries)
 }
 
 // WorkspaceCreateRequest data for creating a workspace (e.g., git worktree)
@@ -352,11 +578,30 @@ type WorkspaceCreateResponse struct {
 	IsWorktree  bool   `json:"is_worktree"`
 }
 
+// ContextDirRequest data for adding or removing a context directory
+type ContextDirRequest struct {
+	Dir string `json:"dir"`
+}
+
 // SessionSaveRequest data for saving session
 type SessionSaveRequest struct {
 	Name string `json:"name"`
 }
 
+// SessionRenameRequest data for renaming a saved session
+type SessionRenameRequest struct {
+	SessionID string `json:"session_id"`
+	Workspace string `json:"workspace,omitempty"`
+	Title     string `json:"title"`
+}
+
+// SessionDuplicateRequest data for copying a saved session to a new ID
+type SessionDuplicateRequest struct {
+	SessionID string `json:"session_id"`
+	Workspace string `json:"workspace,omitempty"`
+	Title     string `json:"title,omitempty"` // Title of the copy (empty = "<original> (copy)")
+}
+
 // SessionLoadRequest data for loading session
 type SessionLoadRequest struct {
 	SessionID string `json:"session_id"`
@@ -380,17 +625,38 @@ type FlowControlData struct {
 	Pause bool `json:"pause"`
 }
 
+// BatchRequest data for executing several requests in one round trip
+type BatchRequest struct {
+	Requests []BaseMessage `json:"requests"`
+}
+
+// BatchResponse data for batch response; entries are in request order
+type BatchResponse struct {
+	Responses []BaseMessage `json:"responses"`
+}
+
+// MaxBatchRequests is the maximum number of sub-requests in a single batch
+const MaxBatchRequests = 64
+
 // Error codes
 const (
 	ErrorCodeAuthFailed            = "AUTH_FAILED"
 	ErrorCodeAuthRequired          = "AUTH_REQUIRED"
+	ErrorCodePeerNotAllowed        = "PEER_NOT_ALLOWED"
 	ErrorCodeInvalidRequest        = "INVALID_REQUEST"
 	ErrorCodeSessionNotFound       = "SESSION_NOT_FOUND"
 	ErrorCodeSessionExists         = "SESSION_EXISTS"
+	ErrorCodeSessionBusy           = "SESSION_BUSY"
+	ErrorCodeSessionEvicted        = "SESSION_EVICTED"
 	ErrorCodeWorkspaceInvalid      = "WORKSPACE_INVALID"
 	ErrorCodeWorkspaceAccessDenied = "WORKSPACE_ACCESS_DENIED"
 	ErrorCodeOperationNotAllowed   = "OPERATION_NOT_ALLOWED"
 	ErrorCodeInternalError         = "INTERNAL_ERROR"
 	ErrorCodeTimeout               = "TIMEOUT"
 	ErrorCodeNotImplemented        = "NOT_IMPLEMENTED"
+	ErrorCodeMessageTooLarge       = "MESSAGE_TOO_LARGE"
+	ErrorCodeRateLimited           = "RATE_LIMITED"
+	ErrorCodeUnknownModel          = "UNKNOWN_MODEL"
+	ErrorCodeVersionConflict       = "VERSION_CONFLICT"
+	ErrorCodeCancelled             = "CANCELLED" // Generation was cancelled; details carry the cause
 )
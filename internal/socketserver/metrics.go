@@ -0,0 +1,173 @@
+package socketserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// Metrics holds the counters of a socket server. Gauges such as the number
+// of connections are read from the hub and managers when scraped.
+type Metrics struct {
+	connections atomic.Int64 // Connections accepted since start
+	generations atomic.Int64 // Prompts processed since start
+	tokens      atomic.Int64 // Tokens used by processed prompts
+
+	errorsMu sync.Mutex
+	errors   map[string]int64 // error code -> errors sent to clients
+}
+
+// NewMetrics creates empty metrics
+func NewMetrics() *Metrics {
+	return &Metrics{errors: make(map[string]int64)}
+}
+
+// ConnectionAccepted counts an accepted connection
+func (m *Metrics) ConnectionAccepted() {
+	m.connections.Add(1)
+}
+
+// GenerationFinished counts a processed prompt and the tokens it used
+func (m *Metrics) GenerationFinished(tokens int) {
+	m.generations.Add(1)
+	if tokens > 0 {
+		m.tokens.Add(int64(tokens))
+	}
+}
+
+// ErrorSent counts an error response sent to a client
+func (m *Metrics) ErrorSent(code string) {
+	m.errorsMu.Lock()
+	defer m.errorsMu.Unlock()
+	m.errors[code]++
+}
+
+// errorCounts returns a copy of the error counters
+func (m *Metrics) errorCounts() map[string]int64 {
+	m.errorsMu.Lock()
+	defer m.errorsMu.Unlock()
+
+	counts := make(map[string]int64, len(m.errors))
+	for code, n := range m.errors {
+		counts[code] = n
+	}
+	return counts
+}
+
+// metricsTimeout bounds reads and writes of metrics requests
+const metricsTimeout = 10 * time.Second
+
+// writeMetrics writes the server metrics in the Prometheus text format
+func (s *Server) writeMetrics(w io.Writer) {
+	m := s.hub.Metrics()
+
+	gauge := func(name, help string, value int) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+	}
+	counter := func(name, help string, value int64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+	}
+
+	gauge("scriptschnell_connections", "Connected socket clients.", s.hub.GetClientCount())
+	gauge("scriptschnell_sessions", "Sessions loaded by the server.", len(s.sessionManager.ListActiveSessions()))
+	gauge("scriptschnell_workspaces", "Registered workspaces.", s.workspaceManager.GetWorkspaceCount())
+	gauge("scriptschnell_generations_in_flight", "Sessions currently generating.", s.sessionManager.ActiveGenerations())
+	counter("scriptschnell_connections_total", "Connections accepted since start.", m.connections.Load())
+	counter("scriptschnell_generations_total", "Prompts processed since start.", m.generations.Load())
+	counter("scriptschnell_tokens_total", "Tokens used by processed prompts.", m.tokens.Load())
+
+	errors := m.errorCounts()
+	codes := make([]string, 0, len(errors))
+	for code := range errors {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	fmt.Fprint(w, "# HELP scriptschnell_errors_total Error responses sent to clients by code.\n# TYPE scriptschnell_errors_total counter\n")
+	for _, code := range codes {
+		fmt.Fprintf(w, "scriptschnell_errors_total{code=%q} %d\n", code, errors[code])
+	}
+}
+
+// startMetrics serves the metrics endpoint when a metrics address is
+// configured. The endpoint has no authentication, so only loopback addresses
+// are accepted.
+func (s *Server) startMetrics() error {
+	addr := s.cfg.Socket.MetricsAddr
+	if addr == "" {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid metrics address %q: %w", addr, err)
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return fmt.Errorf("metrics address %q is not a loopback address", addr)
+		}
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.writeMetrics(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.IsRunning() {
+			http.Error(w, "stopped", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	s.metricsServer = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  metricsTimeout,
+		WriteTimeout: metricsTimeout,
+	}
+	s.metricsListener = listener
+
+	go func() {
+		if err := s.metricsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics endpoint stopped: %v", err)
+		}
+	}()
+
+	logger.Info("Metrics endpoint listening on http://%s/metrics", listener.Addr())
+	return nil
+}
+
+// stopMetrics shuts the metrics endpoint down
+func (s *Server) stopMetrics() {
+	if s.metricsServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.metricsServer.Shutdown(ctx); err != nil {
+		logger.Warn("Failed to stop metrics endpoint: %v", err)
+	}
+}
+
+// MetricsAddr returns the address the metrics endpoint listens on, or an
+// empty string if it is disabled
+func (s *Server) MetricsAddr() string {
+	if s.metricsListener == nil {
+		return ""
+	}
+	return s.metricsListener.Addr().String()
+}
@@ -0,0 +1,141 @@
+package socketserver
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestMetricsEndpointReportsActivity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	cfg.Socket.Path = filepath.Join(t.TempDir(), "test.sock")
+	cfg.Socket.MetricsAddr = "127.0.0.1:0"
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	addr := server.MetricsAddr()
+	if addr == "" {
+		t.Fatal("expected the metrics endpoint to be started")
+	}
+
+	// An invalid message is answered with an error
+	conn, err := net.Dial("unix", cfg.Socket.Path)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("not json\n")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+		t.Fatalf("failed to read the error response: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+	metrics := string(body)
+
+	for _, name := range []string{
+		"scriptschnell_connections 1",
+		"scriptschnell_connections_total 1",
+		"scriptschnell_sessions 0",
+		"scriptschnell_workspaces ",
+		"scriptschnell_generations_in_flight 0",
+		"scriptschnell_generations_total 0",
+		"scriptschnell_tokens_total 0",
+		"scriptschnell_errors_total{code=",
+	} {
+		if !strings.Contains(metrics, name) {
+			t.Errorf("expected %q in metrics:\n%s", name, metrics)
+		}
+	}
+}
+
+func TestMetricsEndpointRequiresLoopbackAddress(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"127.0.0.1:0": true,
+		"[::1]:0":     true,
+		"localhost:0": true,
+		"0.0.0.0:0":   false,
+		":0":          false,
+		"127.0.0.1":   false,
+	} {
+		cfg := config.DefaultConfig()
+		cfg.Socket.MetricsAddr = addr
+		server := &Server{cfg: cfg, hub: NewHub()}
+		err := server.startMetrics()
+		if (err == nil) != ok {
+			t.Errorf("%s: unexpected result %v", addr, err)
+		}
+		server.stopMetrics()
+	}
+}
+
+func TestStartFailsWhenMetricsEndpointFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	cfg.Socket.Path = filepath.Join(t.TempDir(), "test.sock")
+	cfg.Socket.MetricsAddr = "0.0.0.0:0"
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.Stop()
+
+	if err := server.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "metrics endpoint") {
+		t.Fatalf("expected the start to fail, got %v", err)
+	}
+	if _, err := os.Stat(cfg.Socket.Path); !os.IsNotExist(err) {
+		t.Errorf("expected no socket to be created, got %v", err)
+	}
+}
+
+func TestCountGenerationReportsAccumulatedTokens(t *testing.T) {
+	hub := NewHub()
+	broker := NewMessageBroker()
+	broker.session = session.NewSession("session-1", t.TempDir())
+	c := NewClient("test-client", nil, hub, nil, nil, broker, nil, nil, config.DefaultConfig(), nil)
+
+	finished := c.countGeneration()
+	// The orchestrator accumulates the usage of every response in the session
+	broker.session.AccumulateUsage(map[string]interface{}{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120})
+	finished()
+
+	if got := hub.Metrics().generations.Load(); got != 1 {
+		t.Errorf("expected 1 generation, got %d", got)
+	}
+	if got := hub.Metrics().tokens.Load(); got != 120 {
+		t.Errorf("expected 120 tokens, got %d", got)
+	}
+}
@@ -0,0 +1,206 @@
+package socketserver
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/codefionn/scriptschnell/internal/provider"
+)
+
+// Model roles a client can select a model for
+const (
+	modelRoleOrchestration = "orchestration"
+	modelRoleSummarize     = "summarize"
+	modelRolePlanning      = "planning"
+	modelRoleSafety        = "safety"
+)
+
+// Scopes of a model selection
+const (
+	modelScopeSession = "session"
+	modelScopeGlobal  = "global"
+)
+
+// modelListEntry converts a model to its models_list representation
+func modelListEntry(model *provider.Model, caps provider.Capabilities) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                model.ID,
+		"name":              model.Name,
+		"provider":          model.Provider,
+		"description":       model.Description,
+		"context_window":    model.ContextWindow,
+		"max_output_tokens": model.MaxOutputTokens,
+		"capabilities":      caps,
+	}
+}
+
+// handleModelsList lists the configured providers, their models and the
+// current model selections
+func (c *Client) handleModelsList(msg *BaseMessage) error {
+	var data ModelsListRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid models list request", err.Error())
+		return nil
+	}
+
+	if c.providerMgr == nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Provider manager not initialized", "")
+		return nil
+	}
+
+	if data.Provider != "" {
+		if _, ok := c.providerMgr.GetProvider(data.Provider); !ok {
+			c.SendError(msg.RequestID, ErrorCodeUnknownModel, "Unknown provider", data.Provider)
+			return nil
+		}
+	}
+
+	providers := make([]string, 0)
+	for _, p := range c.providerMgr.ListProviders() {
+		providers = append(providers, p.Name)
+	}
+	sort.Strings(providers)
+
+	models := make([]map[string]interface{}, 0)
+	for _, model := range c.providerMgr.ListAllModels() {
+		if data.Provider != "" && model.Provider != data.Provider {
+			continue
+		}
+		caps, err := c.providerMgr.GetModelCapabilities(model.ID)
+		if err != nil {
+			continue
+		}
+		models = append(models, modelListEntry(model, caps))
+	}
+	sort.Slice(models, func(i, j int) bool {
+		pi, pj := models[i]["provider"].(string), models[j]["provider"].(string)
+		if pi != pj {
+			return pi < pj
+		}
+		return models[i]["id"].(string) < models[j]["id"].(string)
+	})
+
+	response := map[string]interface{}{
+		"providers": providers,
+		"models":    models,
+		"selected": map[string]interface{}{
+			modelRoleOrchestration: c.providerMgr.GetOrchestrationModel(),
+			modelRoleSummarize:     c.providerMgr.GetSummarizeModel(),
+			modelRolePlanning:      c.providerMgr.GetPlanningModel(),
+			modelRoleSafety:        c.providerMgr.GetSafetyModel(),
+		},
+	}
+
+	// Session overrides; empty values use the global selection
+	if sessionID := c.GetSession(); sessionID != "" && c.sessionManager != nil {
+		orchestrationModel, summarizeModel := c.sessionManager.GetSessionModels(sessionID)
+		response["session"] = map[string]interface{}{
+			"session_id":           sessionID,
+			modelRoleOrchestration: orchestrationModel,
+			modelRoleSummarize:     summarizeModel,
+		}
+	}
+
+	c.SendResponse(MessageTypeModelsList, msg.RequestID, response)
+	return nil
+}
+
+// handleModelSet selects the model of a role, either for the attached session
+// or globally. Session selections take effect with the next prompt.
+func (c *Client) handleModelSet(msg *BaseMessage) error {
+	var data ModelSetRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid model set request", err.Error())
+		return nil
+	}
+
+	if c.providerMgr == nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Provider manager not initialized", "")
+		return nil
+	}
+
+	if data.Model == "" && data.Provider == "" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Model or provider is required", "")
+		return nil
+	}
+
+	role := data.Role
+	if role == "" {
+		role = modelRoleOrchestration
+	}
+	switch role {
+	case modelRoleOrchestration, modelRoleSummarize, modelRolePlanning, modelRoleSafety:
+	default:
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid model role", role)
+		return nil
+	}
+
+	sessionID := c.GetSession()
+	scope := data.Scope
+	if scope == "" {
+		scope = modelScopeGlobal
+		if sessionID != "" && (role == modelRoleOrchestration || role == modelRoleSummarize) {
+			scope = modelScopeSession
+		}
+	}
+	switch scope {
+	case modelScopeGlobal:
+	case modelScopeSession:
+		if sessionID == "" || c.sessionManager == nil {
+			c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Not attached to a session", "")
+			return nil
+		}
+		if role != modelRoleOrchestration && role != modelRoleSummarize {
+			c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Only orchestration and summarize models can be set per session", role)
+			return nil
+		}
+	default:
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid model scope", scope)
+		return nil
+	}
+
+	modelID, err := c.providerMgr.ResolveModel(data.Model, data.Provider)
+	if err != nil {
+		var unknown *provider.UnknownModelError
+		if errors.As(err, &unknown) {
+			c.SendError(msg.RequestID, ErrorCodeUnknownModel, "Unknown model", err.Error())
+		} else {
+			c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to resolve model", err.Error())
+		}
+		return nil
+	}
+
+	if scope == modelScopeSession {
+		orchestrationModel, summarizeModel := c.sessionManager.GetSessionModels(sessionID)
+		if role == modelRoleOrchestration {
+			orchestrationModel = modelID
+		} else {
+			summarizeModel = modelID
+		}
+		c.sessionManager.SetSessionModels(sessionID, orchestrationModel, summarizeModel)
+	} else {
+		setters := map[string]func(string) error{
+			modelRoleOrchestration: c.providerMgr.SetOrchestrationModel,
+			modelRoleSummarize:     c.providerMgr.SetSummarizeModel,
+			modelRolePlanning:      c.providerMgr.SetPlanningModel,
+			modelRoleSafety:        c.providerMgr.SetSafetyModel,
+		}
+		if err := setters[role](modelID); err != nil {
+			c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to set model", err.Error())
+			return nil
+		}
+	}
+
+	providerName := data.Provider
+	if model, ok := c.providerMgr.GetModel(modelID); ok {
+		providerName = model.Provider
+	}
+
+	c.SendResponse(MessageTypeModelSet, msg.RequestID, map[string]interface{}{
+		"role":     role,
+		"model":    modelID,
+		"provider": providerName,
+		"scope":    scope,
+	})
+	return nil
+}
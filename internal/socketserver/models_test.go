@@ -0,0 +1,117 @@
+package socketserver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/provider"
+)
+
+func newModelsTestClient(t *testing.T) (*Client, *SessionManager, string) {
+	t.Helper()
+
+	c, sm, sessionID := newSaveTestClient(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	mgr, err := provider.NewManager(filepath.Join(t.TempDir(), "providers.json"), "")
+	if err != nil {
+		t.Fatalf("failed to create provider manager: %v", err)
+	}
+	err = mgr.AddProvider("openai", "sk-test", []*provider.Model{
+		{ID: "gpt-4o", Name: "GPT-4o", Provider: "openai", ContextWindow: 128000,
+			Capabilities: &provider.Capabilities{Known: true, SupportsTools: true, SupportsVision: true}},
+		{ID: "gpt-4o-mini", Name: "GPT-4o mini", Provider: "openai", ContextWindow: 128000},
+	})
+	if err != nil {
+		t.Fatalf("failed to add provider: %v", err)
+	}
+	if err := mgr.SetOrchestrationModel("gpt-4o"); err != nil {
+		t.Fatalf("failed to select model: %v", err)
+	}
+	c.providerMgr = mgr
+	return c, sm, sessionID
+}
+
+func sendModelsRequest(t *testing.T, c *Client, msgType string, data map[string]interface{}) *BaseMessage {
+	t.Helper()
+	if err := c.handleMessage(NewRequest(msgType, "models-1", data)); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	return <-c.send
+}
+
+func TestModelsListIncludesCapabilitiesAndSelections(t *testing.T) {
+	c, _, sessionID := newModelsTestClient(t)
+
+	resp := sendModelsRequest(t, c, MessageTypeModelsList, map[string]interface{}{})
+	if resp.Type != MessageTypeModelsList {
+		t.Fatalf("expected models_list response, got %s: %+v", resp.Type, resp.Data)
+	}
+
+	models := resp.Data["models"].([]map[string]interface{})
+	if len(models) != 2 || models[0]["id"] != "gpt-4o" || models[0]["context_window"] != 128000 {
+		t.Fatalf("unexpected models: %+v", models)
+	}
+	if caps := models[0]["capabilities"].(provider.Capabilities); !caps.Known || !caps.SupportsVision {
+		t.Errorf("expected the listed capabilities, got %+v", caps)
+	}
+	if caps := models[1]["capabilities"].(provider.Capabilities); caps.Known {
+		t.Errorf("expected unknown capabilities without metadata, got %+v", caps)
+	}
+
+	selected := resp.Data["selected"].(map[string]interface{})
+	if selected["orchestration"] != "gpt-4o" {
+		t.Errorf("expected the global selection, got %+v", selected)
+	}
+	session := resp.Data["session"].(map[string]interface{})
+	if session["session_id"] != sessionID || session["orchestration"] != "" {
+		t.Errorf("expected an attached session without overrides, got %+v", session)
+	}
+
+	resp = sendModelsRequest(t, c, MessageTypeModelsList, map[string]interface{}{"provider": "missing"})
+	if resp.Error == nil || resp.Error.Code != ErrorCodeUnknownModel {
+		t.Errorf("expected an unknown provider error, got %+v", resp)
+	}
+}
+
+func TestModelSetRoundTrip(t *testing.T) {
+	c, sm, sessionID := newModelsTestClient(t)
+
+	// Attached clients select the session's model by default
+	resp := sendModelsRequest(t, c, MessageTypeModelSet, map[string]interface{}{"model": "gpt-4o-mini"})
+	if resp.Type != MessageTypeModelSet || resp.Data["scope"] != modelScopeSession || resp.Data["provider"] != "openai" {
+		t.Fatalf("unexpected model_set response: %s %+v", resp.Type, resp.Data)
+	}
+	if orchestration, _ := sm.GetSessionModels(sessionID); orchestration != "gpt-4o-mini" {
+		t.Errorf("expected the session model to be set, got %q", orchestration)
+	}
+	if global := c.providerMgr.GetOrchestrationModel(); global != "gpt-4o" {
+		t.Errorf("expected the global model to be kept, got %q", global)
+	}
+
+	resp = sendModelsRequest(t, c, MessageTypeModelsList, map[string]interface{}{})
+	if session := resp.Data["session"].(map[string]interface{}); session["orchestration"] != "gpt-4o-mini" {
+		t.Errorf("expected the session selection to be listed, got %+v", session)
+	}
+
+	// Global selections go to the provider manager
+	resp = sendModelsRequest(t, c, MessageTypeModelSet, map[string]interface{}{
+		"model": "gpt-4o-mini", "role": "planning", "scope": "global",
+	})
+	if resp.Type != MessageTypeModelSet {
+		t.Fatalf("unexpected model_set response: %s %+v", resp.Type, resp.Error)
+	}
+	if planning := c.providerMgr.GetPlanningModel(); planning != "gpt-4o-mini" {
+		t.Errorf("expected the planning model to be set, got %q", planning)
+	}
+
+	resp = sendModelsRequest(t, c, MessageTypeModelSet, map[string]interface{}{"model": "missing-model"})
+	if resp.Error == nil || resp.Error.Code != ErrorCodeUnknownModel {
+		t.Errorf("expected an unknown model error, got %+v", resp)
+	}
+	resp = sendModelsRequest(t, c, MessageTypeModelSet, map[string]interface{}{
+		"model": "gpt-4o", "role": "safety", "scope": "session",
+	})
+	if resp.Error == nil || resp.Error.Code != ErrorCodeInvalidRequest {
+		t.Errorf("expected per-session safety models to be rejected, got %+v", resp)
+	}
+}
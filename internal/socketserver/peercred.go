@@ -0,0 +1,98 @@
+package socketserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// errPeerCredUnsupported is returned where SO_PEERCRED is not available
+var errPeerCredUnsupported = errors.New("peer credentials are not supported on this platform")
+
+// PeerCredentials identifies the process on the other end of a Unix socket
+type PeerCredentials struct {
+	PID int
+	UID int
+	GID int
+}
+
+// lookupPeerCredentials reads the peer credentials of a connection; replaced in tests
+var lookupPeerCredentials = peerCredentials
+
+// peerCheckRequired reports whether peer credentials were asked for
+// explicitly: with the "peercred" auth method or any configured allowlist
+func peerCheckRequired(socketCfg config.SocketConfig) bool {
+	return socketCfg.AuthMethod == "peercred" || len(socketCfg.AllowedUIDs) > 0 || len(socketCfg.AllowedGIDs) > 0
+}
+
+// peerCheckEnabled reports whether connections are restricted by peer
+// credentials. The check is on by default and can only be turned off with
+// disable_peer_check if it is not required explicitly.
+func peerCheckEnabled(cfg *config.Config) bool {
+	if cfg == nil {
+		return true
+	}
+	return !cfg.Socket.DisablePeerCheck || peerCheckRequired(cfg.Socket)
+}
+
+// authorizePeer checks peer credentials against the allowlists. A peer is
+// accepted if its UID is in AllowedUIDs (the server's own user when unset)
+// or its GID is in AllowedGIDs.
+func authorizePeer(socketCfg config.SocketConfig, creds PeerCredentials) error {
+	allowedUIDs := socketCfg.AllowedUIDs
+	if len(allowedUIDs) == 0 {
+		allowedUIDs = []int{os.Getuid()}
+	}
+	for _, uid := range allowedUIDs {
+		if creds.UID == uid {
+			return nil
+		}
+	}
+	for _, gid := range socketCfg.AllowedGIDs {
+		if creds.GID == gid {
+			return nil
+		}
+	}
+	return fmt.Errorf("peer uid %d (gid %d) is not allowed", creds.UID, creds.GID)
+}
+
+// checkPeer validates the credentials of the client's connection if the
+// configuration asks for it
+func (c *Client) checkPeer() error {
+	if !peerCheckEnabled(c.cfg) {
+		return nil
+	}
+	var socketCfg config.SocketConfig
+	if c.cfg != nil {
+		socketCfg = c.cfg.Socket
+	}
+	creds, err := lookupPeerCredentials(c.conn)
+	if errors.Is(err, errPeerCredUnsupported) && !peerCheckRequired(socketCfg) {
+		// The default check relies on file permissions where SO_PEERCRED is missing
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read peer credentials: %w", err)
+	}
+	return authorizePeer(socketCfg, creds)
+}
+
+// rejectPeer tells a peer why it was rejected and closes its connection
+func (c *Client) rejectPeer(requestID string, err error) {
+	logger.Warn("Rejecting client %s: %v", c.ID, err)
+	// Write synchronously so the client sees the reason before the close
+	if writeErr := c.writeMessage(NewError(requestID, ErrorCodePeerNotAllowed, "Peer not allowed", err.Error())); writeErr != nil {
+		logger.Warn("Failed to send peer rejection to client %s: %v", c.ID, writeErr)
+	}
+	c.Stop()
+}
+
+// unixConn returns the Unix socket behind conn, if any
+func unixConn(conn net.Conn) (*net.UnixConn, bool) {
+	uc, ok := conn.(*net.UnixConn)
+	return uc, ok
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package socketserver
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// peerCredentials reads SO_PEERCRED of a Unix socket connection
+func peerCredentials(conn net.Conn) (PeerCredentials, error) {
+	uc, ok := unixConn(conn)
+	if !ok {
+		return PeerCredentials{}, fmt.Errorf("connection is not a Unix socket")
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return PeerCredentials{}, err
+	}
+
+	var (
+		ucred   *syscall.Ucred
+		sockErr error
+	)
+	if err := raw.Control(func(fd uintptr) {
+		ucred, sockErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return PeerCredentials{}, err
+	}
+	if sockErr != nil {
+		return PeerCredentials{}, sockErr
+	}
+	return PeerCredentials{PID: int(ucred.Pid), UID: int(ucred.Uid), GID: int(ucred.Gid)}, nil
+}
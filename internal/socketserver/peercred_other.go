@@ -0,0 +1,10 @@
+//go:build !linux
+
+package socketserver
+
+import "net"
+
+// peerCredentials is not implemented outside Linux
+func peerCredentials(conn net.Conn) (PeerCredentials, error) {
+	return PeerCredentials{}, errPeerCredUnsupported
+}
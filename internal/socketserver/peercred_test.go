@@ -0,0 +1,233 @@
+package socketserver
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/mcp"
+)
+
+// peerTestClient is a client together with the other end of its connection
+type peerTestClient struct {
+	*Client
+	peer net.Conn
+}
+
+// newPeerTestClient returns a client whose peer credentials are simulated
+func newPeerTestClient(t *testing.T, socketCfg func(*config.SocketConfig), creds PeerCredentials) *peerTestClient {
+	t.Helper()
+
+	previous := lookupPeerCredentials
+	lookupPeerCredentials = func(net.Conn) (PeerCredentials, error) {
+		return creds, nil
+	}
+	t.Cleanup(func() { lookupPeerCredentials = previous })
+
+	cfg := config.DefaultConfig()
+	if socketCfg != nil {
+		socketCfg(&cfg.Socket)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+	})
+
+	hub := NewHub()
+	go hub.Run()
+	t.Cleanup(hub.Shutdown)
+
+	client := &Client{
+		ID:       "peer-client",
+		conn:     serverConn,
+		cfg:      cfg,
+		hub:      hub,
+		send:     make(chan *BaseMessage, 4),
+		stopChan: make(chan struct{}),
+	}
+	return &peerTestClient{Client: client, peer: clientConn}
+}
+
+// authenticate sends an auth request and returns the response, which is
+// queued for accepted peers and written directly to rejected ones
+func authenticate(t *testing.T, client *peerTestClient) *BaseMessage {
+	t.Helper()
+
+	written := make(chan *BaseMessage, 1)
+	go func() {
+		var msg BaseMessage
+		if err := json.NewDecoder(client.peer).Decode(&msg); err == nil {
+			written <- &msg
+		}
+	}()
+
+	req := NewRequest(MessageTypeAuthRequest, "auth-1", map[string]interface{}{"client_type": "test"})
+	if err := client.handleAuthRequest(req); err != nil {
+		t.Fatalf("handleAuthRequest returned error: %v", err)
+	}
+	select {
+	case resp := <-written:
+		return resp
+	case resp, ok := <-client.send:
+		if ok {
+			return resp
+		}
+		select {
+		case resp := <-written:
+			return resp
+		case <-time.After(time.Second):
+		}
+	case <-time.After(time.Second):
+	}
+	t.Fatal("no auth response sent")
+	return nil
+}
+
+func TestPeerCredAllowedUID(t *testing.T) {
+	client := newPeerTestClient(t, func(s *config.SocketConfig) {
+		s.AuthMethod = "peercred"
+		s.AllowedUIDs = []int{1000, 1001}
+	}, PeerCredentials{PID: 42, UID: 1001, GID: 1001})
+
+	resp := authenticate(t, client)
+	if resp.Error != nil {
+		t.Fatalf("expected allowed peer to authenticate, got error %+v", resp.Error)
+	}
+	if resp.Data["tool_schema_version"] != mcp.ToolSchemaVersion {
+		t.Errorf("expected tool_schema_version %d, got %v", mcp.ToolSchemaVersion, resp.Data["tool_schema_version"])
+	}
+	if !client.Authenticated() {
+		t.Fatal("expected client to be authenticated")
+	}
+}
+
+func TestPeerCredDisallowedUID(t *testing.T) {
+	client := newPeerTestClient(t, func(s *config.SocketConfig) {
+		s.AuthMethod = "peercred"
+		s.AllowedUIDs = []int{1000}
+	}, PeerCredentials{PID: 42, UID: 2000, GID: 2000})
+
+	resp := authenticate(t, client)
+	if resp.Error == nil || resp.Error.Code != ErrorCodePeerNotAllowed {
+		t.Fatalf("expected %s error, got %+v", ErrorCodePeerNotAllowed, resp.Error)
+	}
+	if client.Authenticated() {
+		t.Fatal("rejected peer must not be authenticated")
+	}
+	select {
+	case <-client.stopChan:
+	default:
+		t.Fatal("expected the connection of a rejected peer to be closed")
+	}
+}
+
+func TestPeerCredAllowedByGID(t *testing.T) {
+	client := newPeerTestClient(t, func(s *config.SocketConfig) {
+		s.AllowedUIDs = []int{1000}
+		s.AllowedGIDs = []int{3000}
+	}, PeerCredentials{UID: 2000, GID: 3000})
+
+	if resp := authenticate(t, client); resp.Error != nil {
+		t.Fatalf("expected peer in allowed group to authenticate, got %+v", resp.Error)
+	}
+}
+
+func TestPeerCredDefaultsToCurrentUser(t *testing.T) {
+	uid := os.Getuid()
+	if err := authorizePeer(config.SocketConfig{AuthMethod: "peercred"}, PeerCredentials{UID: uid}); err != nil {
+		t.Fatalf("expected current user to be allowed by default: %v", err)
+	}
+	if err := authorizePeer(config.SocketConfig{AuthMethod: "peercred"}, PeerCredentials{UID: uid + 1}); err == nil {
+		t.Fatal("expected other users to be rejected by default")
+	}
+}
+
+func TestPeerCredCheckEnabledByDefault(t *testing.T) {
+	client := newPeerTestClient(t, nil, PeerCredentials{UID: os.Getuid() + 1})
+	if resp := authenticate(t, client); resp.Error == nil || resp.Error.Code != ErrorCodePeerNotAllowed {
+		t.Fatalf("expected other users to be rejected by default, got %+v", resp.Error)
+	}
+
+	client = newPeerTestClient(t, nil, PeerCredentials{UID: os.Getuid()})
+	if resp := authenticate(t, client); resp.Error != nil {
+		t.Fatalf("expected the server's own user to be allowed, got %+v", resp.Error)
+	}
+}
+
+func TestPeerCredCheckOptOut(t *testing.T) {
+	client := newPeerTestClient(t, func(s *config.SocketConfig) {
+		s.DisablePeerCheck = true
+	}, PeerCredentials{UID: os.Getuid() + 1})
+	if resp := authenticate(t, client); resp.Error != nil {
+		t.Fatalf("expected no peer check when disabled, got %+v", resp.Error)
+	}
+
+	// An explicit allowlist still applies
+	client = newPeerTestClient(t, func(s *config.SocketConfig) {
+		s.DisablePeerCheck = true
+		s.AllowedUIDs = []int{1000}
+	}, PeerCredentials{UID: 2000})
+	if resp := authenticate(t, client); resp.Error == nil {
+		t.Fatal("expected the allowlist to be enforced despite disable_peer_check")
+	}
+}
+
+func TestPeerCredUnsupportedOnlyRejectsWhenRequired(t *testing.T) {
+	previous := lookupPeerCredentials
+	t.Cleanup(func() { lookupPeerCredentials = previous })
+
+	client := newPeerTestClient(t, nil, PeerCredentials{})
+	lookupPeerCredentials = func(net.Conn) (PeerCredentials, error) {
+		return PeerCredentials{}, errPeerCredUnsupported
+	}
+	if resp := authenticate(t, client); resp.Error != nil {
+		t.Fatalf("expected the default check to fall back to file permissions, got %+v", resp.Error)
+	}
+
+	client = newPeerTestClient(t, func(s *config.SocketConfig) { s.AuthMethod = "peercred" }, PeerCredentials{})
+	lookupPeerCredentials = func(net.Conn) (PeerCredentials, error) {
+		return PeerCredentials{}, errPeerCredUnsupported
+	}
+	if resp := authenticate(t, client); resp.Error == nil {
+		t.Fatal("expected auth_method peercred to fail without peer credentials")
+	}
+}
+
+func TestPeerCredentialsOfUnixSocket(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_PEERCRED is only read on Linux")
+	}
+
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "peer.sock"))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	dialed, err := net.Dial("unix", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer dialed.Close()
+
+	accepted, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("failed to accept: %v", err)
+	}
+	defer accepted.Close()
+
+	creds, err := peerCredentials(accepted)
+	if err != nil {
+		t.Fatalf("failed to read peer credentials: %v", err)
+	}
+	if creds.UID != os.Getuid() || creds.PID != os.Getpid() {
+		t.Fatalf("expected own uid %d and pid %d, got %+v", os.Getuid(), os.Getpid(), creds)
+	}
+}
@@ -0,0 +1,83 @@
+package socketserver
+
+import (
+	"errors"
+
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// processTable returns the background processes of all sessions in memory
+func (c *Client) processTable() tools.ProcessTable {
+	return tools.NewSessionProcessTable(c.sessionManager.LoadedSessions)
+}
+
+// processInfo adds whether a client is attached to the process's session
+func (c *Client) processInfo(process tools.BackgroundProcess) ProcessInfo {
+	owner, _ := c.sessionManager.GetSessionOwner(process.SessionID)
+	return ProcessInfo{BackgroundProcess: process, Orphaned: owner == ""}
+}
+
+// handleProcessList lists the running background processes of all sessions,
+// so processes of crashed clients can be found again
+func (c *Client) handleProcessList(msg *BaseMessage) error {
+	if c.sessionManager == nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Session manager not initialized", "")
+		return nil
+	}
+
+	processes := c.processTable().ListProcesses()
+	infos := make([]ProcessInfo, 0, len(processes))
+	for _, process := range processes {
+		infos = append(infos, c.processInfo(process))
+	}
+
+	c.SendResponse(MessageTypeProcessList, msg.RequestID, map[string]interface{}{
+		"processes": infos,
+	})
+	return nil
+}
+
+// handleProcessKill terminates a background process by PID. Only processes of
+// background jobs started by this server can be terminated.
+func (c *Client) handleProcessKill(msg *BaseMessage) error {
+	var data ProcessKillRequest
+	if err := parseData(msg.Data, &data); err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Invalid process kill request", err.Error())
+		return nil
+	}
+	if data.PID <= 0 {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "PID is required", "")
+		return nil
+	}
+
+	signal := data.Signal
+	if signal == "" {
+		signal = "SIGTERM"
+	}
+	if signal != "SIGTERM" && signal != "SIGKILL" {
+		c.SendError(msg.RequestID, ErrorCodeInvalidRequest, "Unsupported signal", "signal must be SIGTERM or SIGKILL")
+		return nil
+	}
+
+	if c.sessionManager == nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Session manager not initialized", "")
+		return nil
+	}
+
+	process, err := c.processTable().TerminateProcess(data.PID, signal)
+	if errors.Is(err, tools.ErrProcessNotOwned) {
+		c.SendError(msg.RequestID, ErrorCodeOperationNotAllowed, "Process was not started by this server", err.Error())
+		return nil
+	}
+	if err != nil {
+		c.SendError(msg.RequestID, ErrorCodeInternalError, "Failed to terminate process", err.Error())
+		return nil
+	}
+
+	c.SendResponse(MessageTypeProcessKill, msg.RequestID, map[string]interface{}{
+		"process": c.processInfo(*process),
+		"signal":  signal,
+		"status":  "signal_sent",
+	})
+	return nil
+}
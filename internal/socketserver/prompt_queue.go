@@ -0,0 +1,190 @@
+package socketserver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// ErrPromptQueueFull is returned when a session already has the maximum number of queued prompts
+var ErrPromptQueueFull = errors.New("prompt queue is full")
+
+// DefaultMaxQueuedPrompts is used when the socket config does not set a limit
+const DefaultMaxQueuedPrompts = 10
+
+// QueuedPrompt is a chat_send that waits for the session's current generation to finish
+type QueuedPrompt struct {
+	RequestID string    `json:"request_id"`
+	ClientID  string    `json:"client_id"`
+	Content   string    `json:"content"`
+	Model     string    `json:"model,omitempty"` // Per-prompt model override
+	QueuedAt  time.Time `json:"queued_at"`
+}
+
+// promptQueue tracks whether a session is generating and which prompts wait behind it.
+// It lives in the session manager so queued prompts survive client reconnects.
+type promptQueue struct {
+	generating bool
+	prompts    []QueuedPrompt
+}
+
+// maxQueuedPrompts returns the configured per-session queue capacity
+func (sm *SessionManager) maxQueuedPrompts() int {
+	if sm.cfg != nil && sm.cfg.Socket.MaxQueuedPrompts > 0 {
+		return sm.cfg.Socket.MaxQueuedPrompts
+	}
+	return DefaultMaxQueuedPrompts
+}
+
+// queueFor returns the prompt queue of a session, creating it if needed.
+// The caller must hold queueMu.
+func (sm *SessionManager) queueFor(sessionID string) *promptQueue {
+	if sm.promptQueues == nil {
+		sm.promptQueues = make(map[string]*promptQueue)
+	}
+	q, ok := sm.promptQueues[sessionID]
+	if !ok {
+		q = &promptQueue{}
+		sm.promptQueues[sessionID] = q
+	}
+	return q
+}
+
+// EnqueuePrompt submits a prompt for a session. If the session is idle it is
+// marked as generating and start is true: the caller must run the prompt and
+// then drain the queue with NextPrompt. Otherwise the prompt is queued and its
+// 1-based position is returned.
+func (sm *SessionManager) EnqueuePrompt(sessionID string, prompt QueuedPrompt) (position int, start bool, err error) {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	if sm.draining {
+		return 0, false, ErrServerDraining
+	}
+
+	q := sm.queueFor(sessionID)
+	if !q.generating {
+		q.generating = true
+		return 0, true, nil
+	}
+
+	if len(q.prompts) >= sm.maxQueuedPrompts() {
+		return 0, false, ErrPromptQueueFull
+	}
+
+	if prompt.QueuedAt.IsZero() {
+		prompt.QueuedAt = time.Now()
+	}
+	q.prompts = append(q.prompts, prompt)
+	return len(q.prompts), false, nil
+}
+
+// NextPrompt pops the next queued prompt of a session. When the queue is empty
+// the session is marked idle and false is returned.
+func (sm *SessionManager) NextPrompt(sessionID string) (QueuedPrompt, bool) {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	q := sm.queueFor(sessionID)
+	if len(q.prompts) == 0 {
+		q.generating = false
+		return QueuedPrompt{}, false
+	}
+
+	next := q.prompts[0]
+	q.prompts = q.prompts[1:]
+	return next, true
+}
+
+// DequeuePrompt removes a queued prompt by request ID, or the most recently
+// queued prompt if requestID is empty
+func (sm *SessionManager) DequeuePrompt(sessionID, requestID string) (QueuedPrompt, bool) {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	q := sm.queueFor(sessionID)
+	idx := -1
+	if requestID == "" {
+		idx = len(q.prompts) - 1
+	} else {
+		for i, prompt := range q.prompts {
+			if prompt.RequestID == requestID {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		return QueuedPrompt{}, false
+	}
+
+	removed := q.prompts[idx]
+	q.prompts = append(q.prompts[:idx], q.prompts[idx+1:]...)
+	return removed, true
+}
+
+// ClearPromptQueue drops all queued prompts of a session and returns them.
+// A running generation is not affected.
+func (sm *SessionManager) ClearPromptQueue(sessionID string) []QueuedPrompt {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	q := sm.queueFor(sessionID)
+	cleared := q.prompts
+	q.prompts = nil
+	return cleared
+}
+
+// PromptQueueState reports whether a session is generating and returns a copy of its queued prompts
+func (sm *SessionManager) PromptQueueState(sessionID string) (generating bool, queued []QueuedPrompt) {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+
+	q, ok := sm.promptQueues[sessionID]
+	if !ok {
+		return false, nil
+	}
+	return q.generating, append([]QueuedPrompt(nil), q.prompts...)
+}
+
+// removePromptQueue forgets the queue of a deleted session
+func (sm *SessionManager) removePromptQueue(sessionID string) {
+	sm.queueMu.Lock()
+	defer sm.queueMu.Unlock()
+	delete(sm.promptQueues, sessionID)
+}
+
+// runPromptQueue runs first and then every prompt queued for the session in
+// FIFO order until the queue is empty. process executes a prompt; done is
+// called after each prompt with its result.
+func (sm *SessionManager) runPromptQueue(sessionID string, first QueuedPrompt, process func(QueuedPrompt) error, done func(QueuedPrompt, error)) {
+	// The idle timeout of a detached session starts when its generation ends
+	defer sm.touchSession(sessionID)
+
+	prompt := first
+	for {
+		err := process(prompt)
+		if err != nil {
+			logger.Warn("Queued prompt %s for session %s failed: %v", prompt.RequestID, sessionID, err)
+		}
+		if done != nil {
+			done(prompt, err)
+		}
+
+		if dropped, draining := sm.abortQueueIfDraining(sessionID); draining {
+			for _, p := range dropped {
+				if done != nil {
+					done(p, ErrServerDraining)
+				}
+			}
+			return
+		}
+
+		next, ok := sm.NextPrompt(sessionID)
+		if !ok {
+			return
+		}
+		prompt = next
+	}
+}
@@ -0,0 +1,185 @@
+package socketserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/orchestrator"
+)
+
+func newPromptQueueTestManager(maxQueued int) *SessionManager {
+	cfg := config.DefaultConfig()
+	cfg.Socket.MaxQueuedPrompts = maxQueued
+	return &SessionManager{cfg: cfg}
+}
+
+// TestPromptQueueProcessesBackToBackChatsInOrder sends two prompts back-to-back
+// and verifies the second waits for the first and both run in order
+func TestPromptQueueProcessesBackToBackChatsInOrder(t *testing.T) {
+	sm := newPromptQueueTestManager(10)
+
+	first := QueuedPrompt{RequestID: "req-1", ClientID: "client-1", Content: "first"}
+	second := QueuedPrompt{RequestID: "req-2", ClientID: "client-1", Content: "second"}
+
+	if _, start, err := sm.EnqueuePrompt("sess", first); err != nil || !start {
+		t.Fatalf("expected first prompt to start immediately, start=%v err=%v", start, err)
+	}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	finished := make(chan struct{})
+
+	var (
+		mu        sync.Mutex
+		processed []string
+		completed []string
+	)
+	process := func(p QueuedPrompt) error {
+		if p.RequestID == first.RequestID {
+			close(started)
+			<-release
+		}
+		mu.Lock()
+		processed = append(processed, p.Content)
+		mu.Unlock()
+		return nil
+	}
+	done := func(p QueuedPrompt, err error) {
+		if err != nil {
+			t.Errorf("prompt %s failed: %v", p.RequestID, err)
+		}
+		mu.Lock()
+		completed = append(completed, p.RequestID)
+		mu.Unlock()
+	}
+
+	go func() {
+		defer close(finished)
+		sm.runPromptQueue("sess", first, process, done)
+	}()
+	<-started
+
+	position, start, err := sm.EnqueuePrompt("sess", second)
+	if err != nil || start || position != 1 {
+		t.Fatalf("expected second prompt to be queued at position 1, got position=%d start=%v err=%v", position, start, err)
+	}
+	if generating, queued := sm.PromptQueueState("sess"); !generating || len(queued) != 1 {
+		t.Fatalf("expected generating session with 1 queued prompt, got generating=%v queued=%d", generating, len(queued))
+	}
+
+	close(release)
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("prompt queue did not drain")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(processed) != 2 || processed[0] != "first" || processed[1] != "second" {
+		t.Fatalf("expected prompts processed in order [first second], got %v", processed)
+	}
+	if len(completed) != 2 || completed[0] != "req-1" || completed[1] != "req-2" {
+		t.Fatalf("expected completions [req-1 req-2], got %v", completed)
+	}
+	if generating, queued := sm.PromptQueueState("sess"); generating || len(queued) != 0 {
+		t.Fatalf("expected idle session after draining, got generating=%v queued=%d", generating, len(queued))
+	}
+}
+
+func TestPromptQueueBoundedAndManageable(t *testing.T) {
+	sm := newPromptQueueTestManager(2)
+
+	if _, start, _ := sm.EnqueuePrompt("sess", QueuedPrompt{RequestID: "running"}); !start {
+		t.Fatal("expected first prompt to start")
+	}
+	for _, id := range []string{"a", "b"} {
+		if _, _, err := sm.EnqueuePrompt("sess", QueuedPrompt{RequestID: id}); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	if _, _, err := sm.EnqueuePrompt("sess", QueuedPrompt{RequestID: "c"}); !errors.Is(err, ErrPromptQueueFull) {
+		t.Fatalf("expected ErrPromptQueueFull, got %v", err)
+	}
+
+	removed, ok := sm.DequeuePrompt("sess", "a")
+	if !ok || removed.RequestID != "a" {
+		t.Fatalf("expected to dequeue a, got %+v ok=%v", removed, ok)
+	}
+	if _, ok := sm.DequeuePrompt("sess", "missing"); ok {
+		t.Fatal("expected dequeue of unknown request to fail")
+	}
+
+	if _, _, err := sm.EnqueuePrompt("sess", QueuedPrompt{RequestID: "c"}); err != nil {
+		t.Fatalf("expected room after dequeue, got %v", err)
+	}
+	if cleared := sm.ClearPromptQueue("sess"); len(cleared) != 2 {
+		t.Fatalf("expected 2 cleared prompts, got %d", len(cleared))
+	}
+
+	// Clearing keeps the running generation
+	if generating, queued := sm.PromptQueueState("sess"); !generating || len(queued) != 0 {
+		t.Fatalf("expected generating session with empty queue, got generating=%v queued=%d", generating, len(queued))
+	}
+}
+
+// TestCompletePromptReportsCancelCause verifies that a cancelled prompt is
+// answered with CANCELLED and the cause instead of an internal error
+func TestCompletePromptReportsCancelCause(t *testing.T) {
+	c := NewClient("client-1", nil, NewHub(), nil, nil, NewMessageBroker(), nil, nil, config.DefaultConfig(), nil)
+	prompt := QueuedPrompt{RequestID: "req-1", ClientID: "client-1"}
+
+	c.completePrompt(prompt, fmt.Errorf("%w: %w", context.Canceled, orchestrator.ErrCancelledByUser))
+	resp := <-c.send
+	if resp.Error == nil || resp.Error.Code != ErrorCodeCancelled {
+		t.Fatalf("expected %s, got %+v", ErrorCodeCancelled, resp)
+	}
+	if resp.Error.Details != orchestrator.ErrCancelledByUser.Error() {
+		t.Fatalf("expected cause %q in details, got %q", orchestrator.ErrCancelledByUser.Error(), resp.Error.Details)
+	}
+
+	c.completePrompt(prompt, fmt.Errorf("request failed: %w", context.DeadlineExceeded))
+	if resp := <-c.send; resp.Error == nil || resp.Error.Code != ErrorCodeTimeout {
+		t.Fatalf("expected %s for a timeout, got %+v", ErrorCodeTimeout, resp)
+	}
+
+	c.completePrompt(prompt, errors.New("provider failed"))
+	if resp := <-c.send; resp.Error == nil || resp.Error.Code != ErrorCodeInternalError {
+		t.Fatalf("expected %s for other errors, got %+v", ErrorCodeInternalError, resp)
+	}
+}
+
+// TestPromptClientUsesQueueingClient verifies that a queued prompt runs on
+// the broker of the client that queued it, not of the client running the queue
+func TestPromptClientUsesQueueingClient(t *testing.T) {
+	hub := NewHub()
+	cfg := config.DefaultConfig()
+	runner := NewClient("runner", nil, hub, nil, nil, NewMessageBroker(), nil, nil, cfg, nil)
+	owner := NewClient("owner", nil, hub, nil, nil, NewMessageBroker(), nil, nil, cfg, nil)
+	runner.SetSession("sess", "")
+	owner.SetSession("sess", "")
+	hub.registerClient(runner)
+	hub.registerClient(owner)
+
+	prompt := QueuedPrompt{RequestID: "req-1", ClientID: "owner"}
+	if got := runner.promptClient("sess", prompt); got != owner {
+		t.Fatalf("expected the prompt to run on the owner's broker, got client %s", got.ID)
+	}
+
+	// An owner that moved to another session or disconnected leaves the
+	// prompt to the running client
+	owner.SetSession("other", "")
+	if got := runner.promptClient("sess", prompt); got != runner {
+		t.Fatalf("expected the runner for an owner in another session, got client %s", got.ID)
+	}
+	hub.unregisterClient(owner)
+	owner.SetSession("sess", "")
+	if got := runner.promptClient("sess", prompt); got != runner {
+		t.Fatalf("expected the runner for a disconnected owner, got client %s", got.ID)
+	}
+}
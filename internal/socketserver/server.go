@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/config"
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/orchestrator"
 	"github.com/codefionn/scriptschnell/internal/provider"
 	"github.com/codefionn/scriptschnell/internal/securemem"
 )
@@ -23,6 +26,12 @@ type Server struct {
 	workspaceManager *WorkspaceManager
 	listener         net.Listener
 	eventBridge      *EventBridge
+	auditLog         *AuditLog // nil when the audit log is disabled
+	descriptorPath   string    // Discovery descriptor written on start
+
+	// Metrics endpoint (nil when not configured)
+	metricsServer   *http.Server
+	metricsListener net.Listener
 
 	// Dependencies (set via SetDependencies)
 	providerMgr     *provider.Manager
@@ -35,10 +44,11 @@ type Server struct {
 	maxConns  int
 
 	// Control
-	mu       sync.Mutex
-	running  bool
-	stopChan chan struct{}
-	stopOnce sync.Once
+	mu           sync.Mutex
+	running      bool
+	stopChan     chan struct{}
+	stopOnce     sync.Once
+	listenerOnce sync.Once
 
 	// Connection ID counter
 	connIDCounter int
@@ -72,11 +82,21 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workspace manager: %w", err)
 	}
+	workspaceMgr.SetWorkspaceTemplates(cfg.GetWorkspaceTemplate, newWorkspaceSetupAuthorizer(cfg), actor.NewShellActor("workspace_setup", nil))
 	server.workspaceManager = workspaceMgr
 
 	// Create event bridge to connect actor events to socket clients
 	server.eventBridge = NewEventBridge(server.hub)
 
+	// Create the workspace audit log
+	if maxBytes := cfg.Socket.GetAuditLogMaxBytes(); maxBytes > 0 {
+		if dir, err := DefaultAuditLogDir(); err != nil {
+			logger.Warn("Workspace audit log disabled: %v", err)
+		} else {
+			server.auditLog = NewAuditLog(dir, maxBytes)
+		}
+	}
+
 	return server, nil
 }
 
@@ -102,14 +122,22 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to prepare socket path: %w", err)
 	}
 
+	// An explicitly configured metrics endpoint that cannot be served fails
+	// the start instead of leaving monitoring silently blind
+	if err := s.startMetrics(); err != nil {
+		return fmt.Errorf("failed to start metrics endpoint: %w", err)
+	}
+
 	// Remove existing socket file if it exists
 	if err := os.Remove(absPath); err != nil && !os.IsNotExist(err) {
+		s.stopMetrics()
 		return fmt.Errorf("failed to remove existing socket file: %w", err)
 	}
 
 	// Create listener
 	listener, err := net.Listen("unix", absPath)
 	if err != nil {
+		s.stopMetrics()
 		return fmt.Errorf("failed to listen on Unix socket %s: %w", absPath, err)
 	}
 	s.listener = listener
@@ -132,12 +160,16 @@ func (s *Server) Start(ctx context.Context) error {
 	// Start connection accept loop
 	go s.acceptLoop(ctx)
 
+	// Let clients that don't know the socket path find the server
+	s.writeDescriptor(absPath)
+
 	logger.Info("Unix socket server started on %s (max connections: %d)", absPath, s.maxConns)
 
 	return nil
 }
 
-// Stop stops the Unix socket server
+// Stop stops the Unix socket server immediately, cancelling running
+// generations. Use StopWithTimeout to let them finish first.
 func (s *Server) Stop() error {
 	s.stopOnce.Do(func() {
 		logger.Info("Stopping Unix socket server...")
@@ -145,6 +177,9 @@ func (s *Server) Stop() error {
 		// Signal all goroutines to stop
 		close(s.stopChan)
 
+		// Cancel running generations
+		s.stopGenerations(orchestrator.ErrCancelledByShutdown)
+
 		// Shutdown session manager
 		if s.sessionManager != nil {
 			s.sessionManager.Shutdown()
@@ -158,16 +193,15 @@ func (s *Server) Stop() error {
 		// Shutdown hub
 		s.hub.Shutdown()
 
-		// Close listener
-		if s.listener != nil {
-			if err := s.listener.Close(); err != nil {
-				logger.Error("Error closing socket listener: %v", err)
-			}
-		}
+		// Close listener (already closed if StopWithTimeout drained first)
+		s.closeListener()
+		s.stopMetrics()
 
 		// Wait a bit for connections to close gracefully
 		time.Sleep(100 * time.Millisecond)
 
+		s.removeDescriptor()
+
 		// Clean up socket file
 		socketPath := s.cfg.Socket.GetSocketPath()
 		absPath, err := filepath.Abs(socketPath)
@@ -259,10 +293,13 @@ func (s *Server) acceptLoop(ctx context.Context) {
 			clientID := s.generateConnectionID()
 			broker := NewMessageBroker()
 			broker.SetDependencies(s.providerMgr, s.secretsPassword, s.cfg)
+			broker.SetAuditLog(s.auditLog)
 			client := NewClient(clientID, conn, s.hub, s.sessionManager, s.workspaceManager, broker, s.providerMgr, s.secretsPassword, s.cfg, s.eventBridge)
+			client.auditLog = s.auditLog
 
 			// Track client
 			s.trackClient(clientID, client)
+			s.hub.Metrics().ConnectionAccepted()
 
 			// Start client
 			client.Start()
@@ -288,6 +325,25 @@ func (s *Server) trackClient(clientID string, client *Client) {
 	s.connCount++
 }
 
+// stopGenerations stops the generations of all connected clients with cause
+func (s *Server) stopGenerations(cause error) {
+	s.connMu.RLock()
+	clients := make([]*Client, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, client)
+	}
+	s.connMu.RUnlock()
+
+	for _, client := range clients {
+		if client.broker == nil {
+			continue
+		}
+		if generating, _, _ := client.broker.GenerationState(); generating {
+			_ = client.broker.StopWithCause(cause)
+		}
+	}
+}
+
 // untrackClient removes a client from tracking
 //
 //nolint:unused // Reserved for future use
@@ -368,4 +424,9 @@ func (s *Server) GetHub() *Hub {
 func (s *Server) SetDependencies(providerMgr *provider.Manager, secretsPassword *securemem.String) {
 	s.providerMgr = providerMgr
 	s.secretsPassword = secretsPassword
+	// Mask the configured API keys in the audit log as well; called before
+	// the server accepts connections
+	if s.auditLog != nil && providerMgr != nil {
+		s.auditLog.redactor = providerMgr.Redactor()
+	}
 }
@@ -0,0 +1,100 @@
+package socketserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestSessionListFiltersAndPages(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	workingDir := t.TempDir()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	titles := []string{"Refactor parser", "Fix login bug", "Parser tests", "Docs", "Release notes"}
+	for i, title := range titles {
+		sess := session.NewSession(session.GenerateID(), workingDir)
+		sess.AddMessage(&session.Message{Role: "user", Content: title})
+		sess.SetTitle(title)
+		sess.UpdatedAt = base.Add(time.Duration(i) * time.Hour)
+		if err := sm.storage.SaveSession(sess, title); err != nil {
+			t.Fatalf("failed to save session: %v", err)
+		}
+	}
+
+	c := NewClient("test-client", nil, NewHub(), sm, nil, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+	c.Workspace = workingDir
+
+	list := func(data map[string]interface{}) *BaseMessage {
+		t.Helper()
+		if err := c.handleMessage(NewRequest(MessageTypeSessionList, "list", data)); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		return <-c.send
+	}
+	sessionsOf := func(resp *BaseMessage) []SessionInfoResponse {
+		t.Helper()
+		if resp.Type != MessageTypeSessionList {
+			t.Fatalf("expected session_list response, got %s: %+v", resp.Type, resp.Error)
+		}
+		return resp.Data["sessions"].([]SessionInfoResponse)
+	}
+
+	resp := list(map[string]interface{}{"title": "parser"})
+	if got := sessionsOf(resp); len(got) != 2 || got[0].Title != "Parser tests" || got[1].Title != "Refactor parser" || resp.Data["total"] != 2 {
+		t.Errorf("title filter returned %+v (total %v)", got, resp.Data["total"])
+	}
+	for _, info := range sessionsOf(resp) {
+		if _, err := time.Parse(time.RFC3339Nano, info.LastMessageAt); err != nil {
+			t.Errorf("expected last_message_at for %q, got %q", info.Title, info.LastMessageAt)
+		}
+	}
+
+	resp = list(map[string]interface{}{
+		"since": base.Add(time.Hour).Format(time.RFC3339),
+		"until": base.Add(3 * time.Hour).Format(time.RFC3339),
+	})
+	if got := sessionsOf(resp); len(got) != 2 || got[0].Title != "Parser tests" || got[1].Title != "Fix login bug" {
+		t.Errorf("date range returned %+v", got)
+	}
+
+	seen := make(map[string]bool)
+	for offset := 0; offset < len(titles); offset += 2 {
+		resp := list(map[string]interface{}{"limit": 2, "offset": offset})
+		if resp.Data["total"] != len(titles) {
+			t.Errorf("expected total %d, got %v", len(titles), resp.Data["total"])
+		}
+		for _, info := range sessionsOf(resp) {
+			if seen[info.ID] {
+				t.Errorf("session %s returned on more than one page", info.ID)
+			}
+			seen[info.ID] = true
+		}
+	}
+	if len(seen) != len(titles) {
+		t.Errorf("pages covered %d sessions, want %d", len(seen), len(titles))
+	}
+
+	if resp := list(map[string]interface{}{"since": "yesterday"}); resp.Type != MessageTypeError {
+		t.Errorf("expected error for invalid since, got %s", resp.Type)
+	}
+	if resp := list(map[string]interface{}{"limit": -1}); resp.Type != MessageTypeError {
+		t.Errorf("expected error for negative limit, got %s", resp.Type)
+	}
+}
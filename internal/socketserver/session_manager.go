@@ -1,15 +1,24 @@
 package socketserver
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/consts"
 	"github.com/codefionn/scriptschnell/internal/logger"
 	"github.com/codefionn/scriptschnell/internal/session"
 )
 
+// ErrSessionBusy is returned when a session is attached to another client
+var ErrSessionBusy = errors.New("session is attached to another client")
+
 // SessionInternalInfo contains metadata about an active session (internal use)
 type SessionInternalInfo struct {
 	ID            string
@@ -20,6 +29,11 @@ type SessionInternalInfo struct {
 	OwnerClientID string // ID of the client that owns this session
 	MessageCount  int
 	Dirty         bool
+	LastActivity  time.Time // Last client or generation activity, drives idle eviction
+
+	// Models chosen at creation from the workspace defaults (empty = global)
+	OrchestrationModel string
+	SummarizeModel     string
 }
 
 // SessionManager manages the lifecycle of sessions over the Unix socket
@@ -36,6 +50,11 @@ type SessionManager struct {
 	clientSessions map[string]string
 	clientMu       sync.RWMutex
 
+	// Prompt queues: sessionID -> prompts waiting for the current generation
+	promptQueues map[string]*promptQueue
+	queueMu      sync.Mutex
+	draining     bool // Set by BeginDrain; no new prompts are started
+
 	// Session storage
 	storage *session.SessionStorage
 
@@ -44,6 +63,12 @@ type SessionManager struct {
 	autoSaveStop   chan struct{}
 	autoSaveActive bool
 
+	// Idle eviction: sessionID -> record of sessions dropped from memory
+	evicted      map[string]EvictedSession
+	evictionStop chan struct{}
+	evictionOnce sync.Once
+	now          func() time.Time // nil = time.Now
+
 	// Configuration reference
 	cfg *config.Config
 }
@@ -61,6 +86,8 @@ func NewSessionManager(cfg *config.Config) (*SessionManager, error) {
 		sessions:       make(map[string]*SessionInternalInfo),
 		sessionObjects: make(map[string]*session.Session),
 		clientSessions: make(map[string]string),
+		promptQueues:   make(map[string]*promptQueue),
+		evicted:        make(map[string]EvictedSession),
 		storage:        storage,
 		cfg:            cfg,
 		autoSaveStop:   make(chan struct{}),
@@ -70,6 +97,7 @@ func NewSessionManager(cfg *config.Config) (*SessionManager, error) {
 	if cfg.AutoSave.Enabled {
 		sm.startAutoSave()
 	}
+	sm.startIdleEviction()
 
 	logger.Info("Session manager initialized with auto-save=%v", cfg.AutoSave.Enabled)
 	return sm, nil
@@ -142,8 +170,9 @@ func (sm *SessionManager) saveDirtySessions() {
 
 // Shutdown gracefully shuts down the session manager
 func (sm *SessionManager) Shutdown() {
-	// Stop auto-save
+	// Stop auto-save and idle eviction
 	sm.stopAutoSave()
+	sm.stopIdleEviction()
 
 	// Save all dirty sessions before shutdown
 	sm.saveDirtySessions()
@@ -156,7 +185,7 @@ func (sm *SessionManager) CreateSession(workingDir string) (string, *session.Ses
 	sessionID := session.GenerateID()
 	sess := session.NewSession(sessionID, workingDir)
 
-	now := time.Now()
+	now := sm.clock()
 
 	sm.mu.Lock()
 	sm.sessions[sessionID] = &SessionInternalInfo{
@@ -167,6 +196,7 @@ func (sm *SessionManager) CreateSession(workingDir string) (string, *session.Ses
 		UpdatedAt:    now,
 		MessageCount: 0,
 		Dirty:        true, // New sessions are dirty
+		LastActivity: now,
 	}
 	sm.mu.Unlock()
 
@@ -205,7 +235,9 @@ func (sm *SessionManager) LoadSession(workingDir, sessionID string) (*session.Se
 		UpdatedAt:    sess.UpdatedAt,
 		MessageCount: len(sess.GetMessages()),
 		Dirty:        false, // Just loaded from storage
+		LastActivity: sm.clock(),
 	}
+	delete(sm.evicted, sessionID)
 	sm.mu.Unlock()
 
 	sm.objectsMu.Lock()
@@ -247,6 +279,65 @@ func (sm *SessionManager) SaveSession(sessionID, name string) error {
 	return nil
 }
 
+// RenameSession changes the title of a session without changing its ID. A
+// loaded session is renamed in memory too; if it was never saved, the title
+// is stored with its next save. autoSaveRef is the storage actor autosaving
+// the session, if any: the rename goes through it so its autosaves keep the
+// new title.
+func (sm *SessionManager) RenameSession(workingDir, sessionID, title string, autoSaveRef *actor.ActorRef) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+
+	sm.objectsMu.RLock()
+	_, loaded := sm.sessionObjects[sessionID]
+	sm.objectsMu.RUnlock()
+
+	var err error
+	if autoSaveRef != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), consts.Timeout10)
+		err = actor.RenameSessionViaActor(ctx, autoSaveRef, workingDir, sessionID, title)
+		cancel()
+	} else {
+		err = sm.storage.RenameSession(workingDir, sessionID, title)
+	}
+	if err != nil {
+		if !loaded || !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rename session: %w", err)
+		}
+	}
+	if loaded {
+		sm.UpdateSessionTitle(sessionID, title)
+	}
+
+	logger.Info("Renamed session %s to %q", sessionID, title)
+	return nil
+}
+
+// DuplicateSession copies a saved session with all its messages to a new
+// ID. Unsaved messages of a loaded session are saved first so the copy is
+// complete. The copy is not loaded.
+func (sm *SessionManager) DuplicateSession(workingDir, sessionID, title string) (*session.SessionMetadata, error) {
+	sm.objectsMu.RLock()
+	_, loaded := sm.sessionObjects[sessionID]
+	sm.objectsMu.RUnlock()
+
+	if loaded {
+		if err := sm.SaveSession(sessionID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata, err := sm.storage.DuplicateSession(workingDir, sessionID, title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to duplicate session: %w", err)
+	}
+
+	logger.Info("Duplicated session %s as %s", sessionID, metadata.ID)
+	return metadata, nil
+}
+
 // DeleteSession deletes a session from storage and registry
 func (sm *SessionManager) DeleteSession(workingDir, sessionID string) error {
 	// Check if session has an owner
@@ -270,12 +361,15 @@ func (sm *SessionManager) DeleteSession(workingDir, sessionID string) error {
 	// Remove from registry
 	sm.mu.Lock()
 	delete(sm.sessions, sessionID)
+	delete(sm.evicted, sessionID)
 	sm.mu.Unlock()
 
 	sm.objectsMu.Lock()
 	delete(sm.sessionObjects, sessionID)
 	sm.objectsMu.Unlock()
 
+	sm.removePromptQueue(sessionID)
+
 	logger.Info("Deleted session %s", sessionID)
 	return nil
 }
@@ -285,6 +379,12 @@ func (sm *SessionManager) ListSessions(workingDir string) ([]session.SessionMeta
 	return sm.storage.ListSessions(workingDir)
 }
 
+// ListSessionsWithOptions lists one page of the stored sessions of a
+// workspace that match opts and returns the total number of matches
+func (sm *SessionManager) ListSessionsWithOptions(workingDir string, opts session.ListOptions) ([]session.SessionMetadata, int, error) {
+	return sm.storage.ListSessionsWithOptions(workingDir, opts)
+}
+
 // GetSession retrieves a session object by ID
 func (sm *SessionManager) GetSession(sessionID string) (*session.Session, bool) {
 	sm.objectsMu.RLock()
@@ -293,6 +393,18 @@ func (sm *SessionManager) GetSession(sessionID string) (*session.Session, bool)
 	return sess, exists
 }
 
+// LoadedSessions returns all session objects currently held in memory
+func (sm *SessionManager) LoadedSessions() []*session.Session {
+	sm.objectsMu.RLock()
+	defer sm.objectsMu.RUnlock()
+
+	sessions := make([]*session.Session, 0, len(sm.sessionObjects))
+	for _, sess := range sm.sessionObjects {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
+
 // GetSessionInfo retrieves session metadata by ID
 func (sm *SessionManager) GetSessionInfo(sessionID string) (*SessionInternalInfo, bool) {
 	sm.mu.RLock()
@@ -309,12 +421,15 @@ func (sm *SessionManager) AttachClient(clientID, sessionID string) error {
 	// Check if session exists
 	info, exists := sm.sessions[sessionID]
 	if !exists {
+		if _, wasEvicted := sm.evicted[sessionID]; wasEvicted {
+			return fmt.Errorf("%w: session %s", ErrSessionEvicted, sessionID)
+		}
 		return fmt.Errorf("session %s not found", sessionID)
 	}
 
 	// Check if session already has an owner
 	if info.OwnerClientID != "" && info.OwnerClientID != clientID {
-		return fmt.Errorf("session %s is already owned by client %s", sessionID, info.OwnerClientID)
+		return fmt.Errorf("%w: session %s is already owned by client %s", ErrSessionBusy, sessionID, info.OwnerClientID)
 	}
 
 	// Update client session mapping
@@ -331,12 +446,50 @@ func (sm *SessionManager) AttachClient(clientID, sessionID string) error {
 
 	// Update session owner
 	info.OwnerClientID = clientID
-	info.UpdatedAt = time.Now()
+	sm.touch(info)
 
 	logger.Info("Client %s attached to session %s", clientID, sessionID)
 	return nil
 }
 
+// TakeoverSession transfers ownership of a session to a client, detaching the
+// previous owner. It returns the ID of the previous owner (empty if none).
+func (sm *SessionManager) TakeoverSession(clientID, sessionID string) (string, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	info, exists := sm.sessions[sessionID]
+	if !exists {
+		if _, wasEvicted := sm.evicted[sessionID]; wasEvicted {
+			return "", fmt.Errorf("%w: session %s", ErrSessionEvicted, sessionID)
+		}
+		return "", fmt.Errorf("session %s not found", sessionID)
+	}
+
+	previousOwner := info.OwnerClientID
+
+	sm.clientMu.Lock()
+	if previousOwner != "" && previousOwner != clientID {
+		if ownerSessionID, ok := sm.clientSessions[previousOwner]; ok && ownerSessionID == sessionID {
+			delete(sm.clientSessions, previousOwner)
+		}
+	}
+	oldSessionID, hasOldSession := sm.clientSessions[clientID]
+	if hasOldSession && oldSessionID != sessionID {
+		if oldInfo, ok := sm.sessions[oldSessionID]; ok && oldInfo.OwnerClientID == clientID {
+			oldInfo.OwnerClientID = ""
+		}
+	}
+	sm.clientSessions[clientID] = sessionID
+	sm.clientMu.Unlock()
+
+	info.OwnerClientID = clientID
+	sm.touch(info)
+
+	logger.Info("Client %s took over session %s from client %s", clientID, sessionID, previousOwner)
+	return previousOwner, nil
+}
+
 // DetachClient detaches a client from its session
 func (sm *SessionManager) DetachClient(clientID string) {
 	sm.mu.Lock()
@@ -353,7 +506,7 @@ func (sm *SessionManager) DetachClient(clientID string) {
 		if info, exists := sm.sessions[sessionID]; exists {
 			if info.OwnerClientID == clientID {
 				info.OwnerClientID = ""
-				info.UpdatedAt = time.Now()
+				sm.touch(info)
 			}
 		}
 		logger.Info("Client %s detached from session %s", clientID, sessionID)
@@ -398,7 +551,7 @@ func (sm *SessionManager) MarkSessionDirty(sessionID string) {
 
 	if info, exists := sm.sessions[sessionID]; exists {
 		info.Dirty = true
-		info.UpdatedAt = time.Now()
+		sm.touch(info)
 	}
 }
 
@@ -409,8 +562,31 @@ func (sm *SessionManager) UpdateSessionMessageCount(sessionID string, count int)
 
 	if info, exists := sm.sessions[sessionID]; exists {
 		info.MessageCount = count
-		info.UpdatedAt = time.Now()
+		sm.touch(info)
+	}
+}
+
+// SetSessionModels sets the orchestration and summarize models of a session
+func (sm *SessionManager) SetSessionModels(sessionID, orchestrationModel, summarizeModel string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if info, exists := sm.sessions[sessionID]; exists {
+		info.OrchestrationModel = orchestrationModel
+		info.SummarizeModel = summarizeModel
+	}
+}
+
+// GetSessionModels returns the orchestration and summarize models of a
+// session; empty values use the global provider selection
+func (sm *SessionManager) GetSessionModels(sessionID string) (orchestrationModel, summarizeModel string) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if info, exists := sm.sessions[sessionID]; exists {
+		return info.OrchestrationModel, info.SummarizeModel
 	}
+	return "", ""
 }
 
 // UpdateSessionTitle updates the title for a session
@@ -420,7 +596,7 @@ func (sm *SessionManager) UpdateSessionTitle(sessionID, title string) {
 
 	if info, exists := sm.sessions[sessionID]; exists {
 		info.Title = title
-		info.UpdatedAt = time.Now()
+		sm.touch(info)
 	}
 
 	sm.objectsMu.Lock()
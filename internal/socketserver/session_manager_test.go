@@ -1,6 +1,7 @@
 package socketserver
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -57,3 +58,45 @@ func TestSessionInfo(t *testing.T) {
 func TestHelperParseData(t *testing.T) {
 	t.Skip("parseData helper test to be implemented")
 }
+
+// TestSessionTakeover verifies a busy session can be reclaimed by another client
+func TestSessionTakeover(t *testing.T) {
+	sm := &SessionManager{
+		sessions:       map[string]*SessionInternalInfo{"s1": {ID: "s1"}},
+		clientSessions: make(map[string]string),
+	}
+
+	if err := sm.AttachClient("stale", "s1"); err != nil {
+		t.Fatalf("AttachClient failed: %v", err)
+	}
+
+	err := sm.AttachClient("fresh", "s1")
+	if !errors.Is(err, ErrSessionBusy) {
+		t.Fatalf("Expected ErrSessionBusy, got %v", err)
+	}
+
+	previous, err := sm.TakeoverSession("fresh", "s1")
+	if err != nil {
+		t.Fatalf("TakeoverSession failed: %v", err)
+	}
+	if previous != "stale" {
+		t.Errorf("Expected previous owner 'stale', got '%s'", previous)
+	}
+
+	if owner, _ := sm.GetSessionOwner("s1"); owner != "fresh" {
+		t.Errorf("Expected owner 'fresh', got '%s'", owner)
+	}
+	if _, ok := sm.GetClientSessionID("stale"); ok {
+		t.Error("Stale client should no longer be mapped to the session")
+	}
+
+	// Detaching the stale client afterwards must not release the session
+	sm.DetachClient("stale")
+	if owner, _ := sm.GetSessionOwner("s1"); owner != "fresh" {
+		t.Errorf("Expected owner 'fresh' after stale detach, got '%s'", owner)
+	}
+
+	if _, err := sm.TakeoverSession("fresh", "missing"); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
@@ -0,0 +1,95 @@
+package socketserver
+
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestSessionRenameAndDuplicate(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	workingDir := t.TempDir()
+	sess := session.NewSession(session.GenerateID(), workingDir)
+	sess.AddMessage(&session.Message{Role: "user", Content: "first"})
+	sess.AddMessage(&session.Message{Role: "assistant", Content: "second"})
+	sess.SetTitle("Original")
+	if err := sm.storage.SaveSession(sess, "Original"); err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+
+	c := NewClient("test-client", nil, NewHub(), sm, nil, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+	c.Workspace = workingDir
+
+	request := func(msgType string, data map[string]interface{}) *BaseMessage {
+		t.Helper()
+		if err := c.handleMessage(NewRequest(msgType, "req", data)); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		resp := <-c.send
+		if resp.Type != msgType {
+			t.Fatalf("expected %s response, got %s: %+v", msgType, resp.Type, resp.Error)
+		}
+		return resp
+	}
+
+	resp := request(MessageTypeSessionRename, map[string]interface{}{"session_id": sess.ID, "title": "Renamed"})
+	if resp.Data["session_id"] != sess.ID {
+		t.Errorf("rename must keep the ID, got %v", resp.Data["session_id"])
+	}
+	renamed, err := sm.storage.LoadSession(workingDir, sess.ID)
+	if err != nil {
+		t.Fatalf("failed to load renamed session: %v", err)
+	}
+	if renamed.Title != "Renamed" || len(renamed.GetMessages()) != 2 {
+		t.Errorf("unexpected renamed session: title %q, %d messages", renamed.Title, len(renamed.GetMessages()))
+	}
+
+	resp = request(MessageTypeSessionDuplicate, map[string]interface{}{"session_id": sess.ID})
+	copyID, _ := resp.Data["session_id"].(string)
+	if copyID == "" || copyID == sess.ID {
+		t.Fatalf("duplicate must get a fresh ID, got %q", copyID)
+	}
+	if resp.Data["title"] != "Renamed (copy)" {
+		t.Errorf("unexpected title of the copy: %v", resp.Data["title"])
+	}
+
+	duplicate, err := sm.storage.LoadSession(workingDir, copyID)
+	if err != nil {
+		t.Fatalf("failed to load duplicate: %v", err)
+	}
+	messages := duplicate.GetMessages()
+	if duplicate.ID != copyID || len(messages) != 2 || messages[0].Content != "first" || messages[1].Content != "second" {
+		t.Errorf("expected all messages under the new ID, got ID %s with %d messages", duplicate.ID, len(messages))
+	}
+
+	sessions, err := sm.storage.ListSessions(workingDir)
+	if err != nil {
+		t.Fatalf("failed to list sessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("expected the original and the copy, got %d sessions", len(sessions))
+	}
+
+	if err := c.handleMessage(NewRequest(MessageTypeSessionRename, "req", map[string]interface{}{"session_id": sess.ID, "title": " "})); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	if resp := <-c.send; resp.Type != MessageTypeError {
+		t.Errorf("expected an error for an empty title, got %s", resp.Type)
+	}
+}
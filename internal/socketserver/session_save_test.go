@@ -0,0 +1,149 @@
+package socketserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// stubSummarizeClient answers every completion with a fixed response
+type stubSummarizeClient struct {
+	response string
+}
+
+func (s *stubSummarizeClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return s.response, nil
+}
+
+func (s *stubSummarizeClient) CompleteWithRequest(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	return &llm.CompletionResponse{Content: s.response}, nil
+}
+
+func (s *stubSummarizeClient) Stream(ctx context.Context, req *llm.CompletionRequest, callback func(chunk string) error) error {
+	return callback(s.response)
+}
+
+func (s *stubSummarizeClient) GetModelName() string                    { return "stub-summarize" }
+func (s *stubSummarizeClient) GetLastResponseID() string               { return "" }
+func (s *stubSummarizeClient) SetPreviousResponseID(responseID string) {}
+
+func newSaveTestClient(t *testing.T) (*Client, *SessionManager, string) {
+	t.Helper()
+
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+
+	workingDir := t.TempDir()
+	sessionID, sess, err := sm.CreateSession(workingDir)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	sess.AddMessage(&session.Message{Role: "user", Content: "refactor the config loader"})
+	sess.TrackFileModified("internal/config/loader.go")
+
+	c := NewClient("test-client", nil, NewHub(), sm, nil, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+	c.SetSession(sessionID, workingDir)
+	return c, sm, sessionID
+}
+
+func saveSession(t *testing.T, c *Client) map[string]interface{} {
+	t.Helper()
+	if err := c.handleMessage(NewRequest(MessageTypeSessionSave, "save-1", map[string]interface{}{})); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	resp := <-c.send
+	if resp.Type != MessageTypeSessionSave {
+		t.Fatalf("expected session_save response, got %s: %+v", resp.Type, resp.Data)
+	}
+	return resp.Data
+}
+
+func TestSessionSaveReturnsSessionSummary(t *testing.T) {
+	c, sm, sessionID := newSaveTestClient(t)
+	c.newSummarizeClient = func(string) (llm.Client, error) {
+		return &stubSummarizeClient{response: `{"title": "Refactor config loader", "description": "Split the loader into smaller functions."}`}, nil
+	}
+
+	data := saveSession(t, c)
+	summary, ok := data["session_summary"].(*session.SessionSummary)
+	if !ok {
+		t.Fatalf("expected session_summary in response, got %T", data["session_summary"])
+	}
+	if summary.Title != "Refactor config loader" || !summary.Generated {
+		t.Errorf("expected generated title, got %+v", summary)
+	}
+	if len(summary.KeyFiles) != 1 || summary.KeyFiles[0] != "internal/config/loader.go" {
+		t.Errorf("unexpected key files: %v", summary.KeyFiles)
+	}
+	if data["title"] != "Refactor config loader" {
+		t.Errorf("expected untitled session to take the summary title, got %v", data["title"])
+	}
+
+	// The summary is persisted alongside the session
+	info, _ := sm.GetSessionInfo(sessionID)
+	stored, err := sm.storage.LoadSession(info.WorkingDir, sessionID)
+	if err != nil {
+		t.Fatalf("failed to load saved session: %v", err)
+	}
+	if got := stored.GetSaveSummary(); got == nil || got.Title != "Refactor config loader" {
+		t.Errorf("expected persisted summary, got %+v", got)
+	}
+}
+
+func TestSessionSaveSummaryFallsBackToFirstPrompt(t *testing.T) {
+	c, _, _ := newSaveTestClient(t)
+	c.newSummarizeClient = func(string) (llm.Client, error) {
+		return nil, errors.New("no model configured")
+	}
+
+	data := saveSession(t, c)
+	summary, ok := data["session_summary"].(*session.SessionSummary)
+	if !ok {
+		t.Fatalf("expected session_summary in response, got %T", data["session_summary"])
+	}
+	if summary.Title != "Refactor the config loader" || summary.Generated {
+		t.Errorf("expected first-prompt fallback title, got %+v", summary)
+	}
+}
+
+func TestSessionSaveDoesNotBlockReadLoop(t *testing.T) {
+	c, _, _ := newSaveTestClient(t)
+	release := make(chan struct{})
+	c.newSummarizeClient = func(string) (llm.Client, error) {
+		<-release
+		return &stubSummarizeClient{response: `{"title": "Slow summary", "description": "Took a while."}`}, nil
+	}
+
+	// The handler returns while the summary is still being generated
+	if err := c.handleMessage(NewRequest(MessageTypeSessionSave, "save-1", map[string]interface{}{})); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	select {
+	case resp := <-c.send:
+		t.Fatalf("expected no response before the summary is done, got %s", resp.Type)
+	default:
+	}
+
+	close(release)
+	resp := <-c.send
+	if resp.Type != MessageTypeSessionSave || resp.Data["title"] != "Slow summary" {
+		t.Fatalf("expected the asynchronous session_save response, got %s: %+v", resp.Type, resp.Data)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/sandbox"
+	"github.com/codefionn/scriptschnell/internal/tools"
 	"github.com/codefionn/scriptschnell/internal/vcs"
 )
 
@@ -31,6 +33,11 @@ type WorkspaceInternalInfo struct {
 	LandlockWrite    []string        `json:"landlock_write"`    // Landlock read-write paths
 	DomainsApproved  map[string]bool `json:"domains_approved"`  // Approved network domains
 	CommandsApproved map[string]bool `json:"commands_approved"` // Approved command prefixes
+
+	OrchestrationModel string `json:"orchestration_model,omitempty"` // Default orchestration model for new sessions (empty = global)
+	SummarizeModel     string `json:"summarize_model,omitempty"`     // Default summarize model for new sessions (empty = global)
+
+	Template *WorkspaceTemplateResult `json:"template,omitempty"` // How the workspace template was applied (worktrees created with a template only)
 }
 
 // WorkspaceManager manages workspace lifecycle and state
@@ -42,6 +49,11 @@ type WorkspaceManager struct {
 
 	// Path to workspace ID mapping (for quick lookup)
 	pathToID map[string]string // working dir -> workspace ID
+
+	// Workspace templates for new worktrees (see SetWorkspaceTemplates)
+	templateLookup  WorkspaceTemplateLookup
+	setupAuthorizer tools.Authorizer
+	setupExecutor   tools.ShellExecutor
 }
 
 // NewWorkspaceManager creates a new workspace manager
@@ -146,6 +158,10 @@ func (wm *WorkspaceManager) CreateWorktree(ctx context.Context, baseWorkingDir,
 	// Create git VCS instance
 	git := vcs.NewGit(baseWorkingDir)
 
+	// Look up the template of the base repository
+	repoRoot, _ := git.RepositoryRoot(ctx, baseWorkingDir)
+	template, authorizer, executor := wm.workspaceTemplate(repoRoot)
+
 	// Create the worktree
 	worktreePath, err := git.CreateWorktree(ctx, sessionName)
 	if err != nil {
@@ -160,9 +176,21 @@ func (wm *WorkspaceManager) CreateWorktree(ctx context.Context, baseWorkingDir,
 		return nil, fmt.Errorf("failed to register worktree as workspace: %w", err)
 	}
 
+	// Bootstrap the worktree from the template
+	var templateResult *WorkspaceTemplateResult
+	if template != nil {
+		templateResult = applyWorkspaceTemplate(ctx, template, authorizer, executor, repoRoot, worktreePath)
+		if templateResult.SetupError != "" || len(templateResult.CopyErrors) > 0 {
+			logger.Warn("Workspace template for %s applied with errors: %s %v", worktreePath, templateResult.SetupError, templateResult.CopyErrors)
+		}
+	}
+
 	// Mark as worktree
+	wm.mu.Lock()
 	ws.IsWorktree = true
 	ws.WorktreeName = sessionName
+	ws.Template = templateResult
+	wm.mu.Unlock()
 
 	logger.Info("Created git worktree: %s at %s", sessionName, worktreePath)
 
@@ -247,6 +275,28 @@ func (wm *WorkspaceManager) SetWorkspaceLandlockPermissions(workspaceID string,
 	return nil
 }
 
+// GetLandlockConfig returns the landlock read/write paths approved for a
+// workspace as a sandbox configuration. On platforms without Landlock it
+// returns sandbox.ErrUnsupported so callers fall back to the authorizer.
+func (wm *WorkspaceManager) GetLandlockConfig(workspaceID string) (*sandbox.SandboxConfig, error) {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	ws, exists := wm.workspaces[workspaceID]
+	if !exists {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+
+	if !sandbox.Supported() {
+		return nil, sandbox.ErrUnsupported
+	}
+
+	return &sandbox.SandboxConfig{
+		AdditionalReadOnlyPaths:  append([]string(nil), ws.LandlockRead...),
+		AdditionalReadWritePaths: append([]string(nil), ws.LandlockWrite...),
+	}, nil
+}
+
 // ApproveDomainForWorkspace approves a network domain for a workspace
 func (wm *WorkspaceManager) ApproveDomainForWorkspace(workspaceID, domain string) error {
 	wm.mu.Lock()
@@ -281,6 +331,36 @@ func (wm *WorkspaceManager) ApproveCommandForWorkspace(workspaceID, command stri
 	return nil
 }
 
+// SetWorkspaceModelDefaults sets the models used by sessions created in a
+// workspace. Empty model IDs keep the global provider selection.
+func (wm *WorkspaceManager) SetWorkspaceModelDefaults(workspaceID, orchestrationModel, summarizeModel string) error {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	ws, exists := wm.workspaces[workspaceID]
+	if !exists {
+		return fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+
+	ws.OrchestrationModel = orchestrationModel
+	ws.SummarizeModel = summarizeModel
+	return nil
+}
+
+// GetWorkspaceModelDefaults returns the default orchestration and summarize
+// models of a workspace
+func (wm *WorkspaceManager) GetWorkspaceModelDefaults(workspaceID string) (orchestrationModel, summarizeModel string, err error) {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	ws, exists := wm.workspaces[workspaceID]
+	if !exists {
+		return "", "", fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+
+	return ws.OrchestrationModel, ws.SummarizeModel, nil
+}
+
 // createWorkspaceInfo creates workspace info from a working directory
 func (wm *WorkspaceManager) createWorkspaceInfo(ctx context.Context, workingDir, workspaceID string) (*WorkspaceInternalInfo, error) {
 	now := time.Now()
@@ -40,6 +40,10 @@
 // - Each worktree gets its own branch: session/{session-name}
 // - Worktrees are automatically tracked and cleaned up when unused
 //
+// A workspace template of the base repository (config workspace_templates)
+// copies untracked files and runs a setup command in new worktrees. How it
+// was applied is recorded in WorkspaceInternalInfo.Template.
+//
 // Protocol Messages:
 //
 // The socket protocol supports the following workspace-related messages:
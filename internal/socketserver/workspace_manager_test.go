@@ -2,10 +2,13 @@ package socketserver
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/sandbox"
 )
 
 func TestWorkspaceManager(t *testing.T) {
@@ -207,3 +210,114 @@ func TestWorkspaceSetters(t *testing.T) {
 		t.Error("Command should be approved")
 	}
 }
+
+func TestWorkspaceGetLandlockConfig(t *testing.T) {
+	wm, err := NewWorkspaceManager()
+	if err != nil {
+		t.Fatalf("Failed to create workspace manager: %v", err)
+	}
+
+	ws, err := wm.ResolveWorkspace(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve workspace: %v", err)
+	}
+
+	if err := wm.SetWorkspaceLandlockPermissions(ws.ID, []string{"/tmp/read1"}, []string{"/tmp/write1", "/tmp/write2"}); err != nil {
+		t.Fatalf("Failed to set landlock permissions: %v", err)
+	}
+
+	cfg, err := wm.GetLandlockConfig(ws.ID)
+	if !sandbox.Supported() {
+		if !errors.Is(err, sandbox.ErrUnsupported) {
+			t.Fatalf("Expected ErrUnsupported without landlock, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Failed to get landlock config: %v", err)
+	}
+	if len(cfg.AdditionalReadOnlyPaths) != 1 || cfg.AdditionalReadOnlyPaths[0] != "/tmp/read1" {
+		t.Errorf("Unexpected read-only paths: %v", cfg.AdditionalReadOnlyPaths)
+	}
+	if len(cfg.AdditionalReadWritePaths) != 2 || cfg.AdditionalReadWritePaths[1] != "/tmp/write2" {
+		t.Errorf("Unexpected read-write paths: %v", cfg.AdditionalReadWritePaths)
+	}
+
+	if _, err := wm.GetLandlockConfig("missing"); err == nil {
+		t.Error("Expected error for unknown workspace")
+	}
+}
+
+func TestSessionCreateUsesWorkspaceModelDefaults(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("HOME", stateDir)
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	cfg := config.DefaultConfig()
+	cfg.AutoSave.SaveIntervalSeconds = 3600
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create session manager: %v", err)
+	}
+	t.Cleanup(func() {
+		sm.stopAutoSave()
+		sm.stopIdleEviction()
+	})
+	wm, err := NewWorkspaceManager()
+	if err != nil {
+		t.Fatalf("Failed to create workspace manager: %v", err)
+	}
+
+	c := NewClient("test-client", nil, NewHub(), sm, wm, nil, nil, nil, cfg, nil)
+	c.setAuthenticated(true)
+
+	send := func(msg *BaseMessage) map[string]interface{} {
+		t.Helper()
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage(%s) failed: %v", msg.Type, err)
+		}
+		resp := <-c.send
+		if resp.Type == MessageTypeError {
+			t.Fatalf("%s failed: %+v", msg.Type, resp.Data)
+		}
+		return resp.Data
+	}
+
+	workspaces := []struct {
+		dir, orchestration, summarize string
+	}{
+		{t.TempDir(), "model-a", "summary-a"},
+		{t.TempDir(), "model-b", ""},
+	}
+	for i, ws := range workspaces {
+		send(NewRequest(MessageTypeWorkspaceSet, fmt.Sprintf("set-%d", i), map[string]interface{}{
+			"workspace":           ws.dir,
+			"orchestration_model": ws.orchestration,
+			"summarize_model":     ws.summarize,
+		}))
+	}
+
+	for i, ws := range workspaces {
+		data := send(NewRequest(MessageTypeSessionCreate, fmt.Sprintf("create-%d", i), map[string]interface{}{
+			"working_dir": ws.dir,
+		}))
+		sessionID, _ := data["session_id"].(string)
+		if data["orchestration_model"] != ws.orchestration {
+			t.Errorf("session %d: expected orchestration model %q in response, got %v", i, ws.orchestration, data["orchestration_model"])
+		}
+
+		orchestration, summarize := sm.GetSessionModels(sessionID)
+		if orchestration != ws.orchestration || summarize != ws.summarize {
+			t.Errorf("session %d: expected models %q/%q, got %q/%q", i, ws.orchestration, ws.summarize, orchestration, summarize)
+		}
+	}
+
+	// Sessions outside a configured workspace keep the global selection
+	data := send(NewRequest(MessageTypeSessionCreate, "create-plain", map[string]interface{}{
+		"working_dir": t.TempDir(),
+	}))
+	sessionID, _ := data["session_id"].(string)
+	if orchestration, summarize := sm.GetSessionModels(sessionID); orchestration != "" || summarize != "" {
+		t.Errorf("expected no model overrides, got %q/%q", orchestration, summarize)
+	}
+}
@@ -0,0 +1,231 @@
+package socketserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// setupErrorOutputLimit caps the command output kept in a setup error
+const setupErrorOutputLimit = 500
+
+// WorkspaceTemplateResult records how a workspace template was applied to a
+// new worktree
+type WorkspaceTemplateResult struct {
+	AppliedAt     time.Time `json:"applied_at"`
+	CopiedFiles   []string  `json:"copied_files,omitempty"`  // Template paths copied from the base repository
+	MissingFiles  []string  `json:"missing_files,omitempty"` // Template paths that do not exist in the base repository
+	CopyErrors    []string  `json:"copy_errors,omitempty"`   // Template paths that could not be copied, with the reason
+	SetupCommand  string    `json:"setup_command,omitempty"`
+	SetupRan      bool      `json:"setup_ran"`             // Whether the setup command was executed
+	SetupExitCode int       `json:"setup_exit_code"`       // Exit code of the setup command (if it ran)
+	SetupError    string    `json:"setup_error,omitempty"` // Why the setup command failed or was not run
+}
+
+// WorkspaceTemplateLookup returns the template for new worktrees of the
+// repository at repoRoot, or nil if there is none
+type WorkspaceTemplateLookup func(repoRoot string) *config.WorkspaceTemplate
+
+// SetWorkspaceTemplates enables workspace templates for worktrees created by
+// CreateWorktree. Setup commands are authorized as "shell" tool calls by
+// authorizer and run by executor; without either, setup commands are skipped.
+func (wm *WorkspaceManager) SetWorkspaceTemplates(lookup WorkspaceTemplateLookup, authorizer tools.Authorizer, executor tools.ShellExecutor) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	wm.templateLookup = lookup
+	wm.setupAuthorizer = authorizer
+	wm.setupExecutor = executor
+}
+
+// workspaceTemplate returns the template for repoRoot and the dependencies
+// to apply it
+func (wm *WorkspaceManager) workspaceTemplate(repoRoot string) (*config.WorkspaceTemplate, tools.Authorizer, tools.ShellExecutor) {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	if wm.templateLookup == nil || repoRoot == "" {
+		return nil, nil, nil
+	}
+	return wm.templateLookup(repoRoot), wm.setupAuthorizer, wm.setupExecutor
+}
+
+// applyWorkspaceTemplate copies the template files from repoRoot into the
+// worktree and runs the setup command there. Failures are recorded in the
+// result instead of failing the worktree creation.
+func applyWorkspaceTemplate(ctx context.Context, template *config.WorkspaceTemplate, authorizer tools.Authorizer, executor tools.ShellExecutor, repoRoot, worktreePath string) *WorkspaceTemplateResult {
+	result := &WorkspaceTemplateResult{AppliedAt: time.Now()}
+
+	for _, file := range template.Files {
+		rel := filepath.Clean(file)
+		if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			result.CopyErrors = append(result.CopyErrors, fmt.Sprintf("%s: path must be relative to the repository root", file))
+			continue
+		}
+
+		err := copyTemplatePath(filepath.Join(repoRoot, rel), filepath.Join(worktreePath, rel))
+		switch {
+		case os.IsNotExist(err):
+			result.MissingFiles = append(result.MissingFiles, rel)
+		case err != nil:
+			result.CopyErrors = append(result.CopyErrors, fmt.Sprintf("%s: %v", rel, err))
+		default:
+			result.CopiedFiles = append(result.CopiedFiles, rel)
+		}
+	}
+
+	command := strings.TrimSpace(template.SetupCommand)
+	if command == "" {
+		return result
+	}
+	result.SetupCommand = command
+
+	if authorizer == nil || executor == nil {
+		result.SetupError = "no shell executor configured for setup commands"
+		return result
+	}
+
+	decision, err := authorizer.Authorize(ctx, tools.ToolNameShell, map[string]interface{}{"command": command})
+	if err != nil {
+		result.SetupError = fmt.Sprintf("authorization failed: %v", err)
+		return result
+	}
+	if decision == nil || !decision.Allowed {
+		reason := "not authorized"
+		if decision != nil && decision.Reason != "" {
+			reason = decision.Reason
+		}
+		result.SetupError = fmt.Sprintf("setup command not run: %s", reason)
+		return result
+	}
+
+	_, stderr, exitCode, err := executor.ExecuteCommand(ctx, []string{"sh", "-c", command}, worktreePath, template.GetSetupTimeout(), "")
+	result.SetupRan = true
+	result.SetupExitCode = exitCode
+	switch {
+	case err != nil:
+		result.SetupError = err.Error()
+	case exitCode != 0:
+		result.SetupError = fmt.Sprintf("setup command exited with code %d", exitCode)
+		if output := strings.TrimSpace(stderr); output != "" {
+			if len(output) > setupErrorOutputLimit {
+				output = output[len(output)-setupErrorOutputLimit:]
+			}
+			result.SetupError += ": " + output
+		}
+	}
+	return result
+}
+
+// copyTemplatePath copies a file or directory tree, keeping file modes.
+// Existing files in the worktree are overwritten.
+func copyTemplatePath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return copyTemplateFile(src, dst, info)
+	}
+
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		return copyTemplateFile(path, target, info)
+	})
+}
+
+// copyTemplateFile copies a regular file or recreates a symlink
+func copyTemplateFile(src, dst string, info fs.FileInfo) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		_ = os.Remove(dst)
+		return os.Symlink(link, dst)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file")
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// workspaceSetupAuthorizer decides setup commands with the shell rules of
+// tools.AuthorizationActor and the command prefixes permanently authorized in
+// the config. Commands that would need approval are not run, since there is
+// no user to ask while a worktree is created.
+type workspaceSetupAuthorizer struct {
+	cfg *config.Config
+}
+
+// newWorkspaceSetupAuthorizer creates the authorizer used by the server
+func newWorkspaceSetupAuthorizer(cfg *config.Config) *workspaceSetupAuthorizer {
+	return &workspaceSetupAuthorizer{cfg: cfg}
+}
+
+// Authorize implements tools.Authorizer for shell commands
+func (a *workspaceSetupAuthorizer) Authorize(ctx context.Context, toolName string, params map[string]interface{}) (*tools.AuthorizationDecision, error) {
+	command := strings.TrimSpace(tools.GetStringParam(params, "command", ""))
+	if toolName != tools.ToolNameShell || command == "" {
+		return &tools.AuthorizationDecision{Allowed: false, Reason: "only shell commands can be authorized"}, nil
+	}
+
+	// A fresh actor sees the prefixes authorized since the server started
+	authActor := tools.NewAuthorizationActor("workspace_setup_authorization", nil, nil, nil, &tools.AuthorizationOptions{
+		AllowedCommands: a.cfg.AuthorizedCommandPrefixes(),
+	})
+	decision, err := authActor.Authorize(ctx, toolName, map[string]interface{}{"command": command})
+	if err != nil || decision == nil || decision.Allowed {
+		return decision, err
+	}
+
+	logger.Info("Workspace template: setup command %q is not authorized", command)
+	if decision.RequiresUserInput {
+		decision.Reason = fmt.Sprintf("%s (add it to authorized_commands)", decision.Reason)
+		decision.SuggestedCommandPrefix = command
+	}
+	return decision, nil
+}
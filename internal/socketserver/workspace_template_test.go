@@ -0,0 +1,195 @@
+package socketserver
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/tools"
+)
+
+// setupTemplateRepo creates a git repository with a commit and untracked
+// files a workspace template copies
+func setupTemplateRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	files := map[string]string{
+		"README.md":           "readme\n",
+		".gitignore":          ".env\nlocal/\n",
+		".env":                "TOKEN=local\n",
+		"local/settings.json": `{"debug":true}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(repoDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+		{"add", "README.md", ".gitignore"},
+		{"commit", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	return repoDir
+}
+
+type templateTestAuthorizer struct {
+	allowed bool
+}
+
+func (a *templateTestAuthorizer) Authorize(ctx context.Context, toolName string, params map[string]interface{}) (*tools.AuthorizationDecision, error) {
+	return &tools.AuthorizationDecision{Allowed: a.allowed, Reason: "denied by test"}, nil
+}
+
+type templateTestExecutor struct {
+	mu    sync.Mutex
+	calls []string // working dir and command of each call
+}
+
+func (e *templateTestExecutor) ExecuteCommand(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string) (string, string, int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, workingDir+": "+strings.Join(args, " "))
+	return "", "", 0, nil
+}
+
+func TestCreateWorktreeAppliesWorkspaceTemplate(t *testing.T) {
+	repoDir := setupTemplateRepo(t)
+	wm, err := NewWorkspaceManager()
+	if err != nil {
+		t.Fatalf("Failed to create workspace manager: %v", err)
+	}
+
+	cfg := &config.Config{WorkspaceTemplates: map[string]*config.WorkspaceTemplate{
+		repoDir: {Files: []string{".env", "local", "missing.txt"}, SetupCommand: "npm install"},
+	}}
+	executor := &templateTestExecutor{}
+	wm.SetWorkspaceTemplates(cfg.GetWorkspaceTemplate, &templateTestAuthorizer{allowed: true}, executor)
+
+	ws, err := wm.CreateWorktree(context.Background(), repoDir, "feature")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+
+	for name, want := range map[string]string{".env": "TOKEN=local\n", "local/settings.json": `{"debug":true}`} {
+		got, err := os.ReadFile(filepath.Join(ws.Path, name))
+		if err != nil || string(got) != want {
+			t.Errorf("expected %s to be copied into the worktree, got %q (%v)", name, got, err)
+		}
+	}
+
+	if len(executor.calls) != 1 || executor.calls[0] != ws.Path+": sh -c npm install" {
+		t.Fatalf("expected the setup command to run once in the worktree, got %v", executor.calls)
+	}
+
+	result := ws.Template
+	if result == nil {
+		t.Fatal("expected the template application to be recorded")
+	}
+	if strings.Join(result.CopiedFiles, ",") != ".env,local" || strings.Join(result.MissingFiles, ",") != "missing.txt" {
+		t.Errorf("unexpected copied %v and missing %v files", result.CopiedFiles, result.MissingFiles)
+	}
+	if !result.SetupRan || result.SetupError != "" || result.SetupCommand != "npm install" {
+		t.Errorf("expected a successful setup run to be recorded, got %+v", result)
+	}
+	if stored, ok := wm.GetWorkspace(ws.ID); !ok || stored.Template != result || !stored.IsWorktree {
+		t.Errorf("expected the workspace metadata to carry the template result")
+	}
+}
+
+func TestCreateWorktreeSkipsUnauthorizedSetupCommand(t *testing.T) {
+	repoDir := setupTemplateRepo(t)
+	wm, err := NewWorkspaceManager()
+	if err != nil {
+		t.Fatalf("Failed to create workspace manager: %v", err)
+	}
+
+	cfg := &config.Config{WorkspaceTemplates: map[string]*config.WorkspaceTemplate{
+		repoDir: {Files: []string{".env", "../outside"}, SetupCommand: "make setup"},
+	}}
+	executor := &templateTestExecutor{}
+	wm.SetWorkspaceTemplates(cfg.GetWorkspaceTemplate, newWorkspaceSetupAuthorizer(cfg), executor)
+
+	ws, err := wm.CreateWorktree(context.Background(), repoDir, "denied")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+
+	if len(executor.calls) != 0 {
+		t.Errorf("expected the unauthorized setup command not to run, got %v", executor.calls)
+	}
+	if ws.Template == nil || ws.Template.SetupRan || !strings.Contains(ws.Template.SetupError, "authorized_commands") {
+		t.Errorf("expected the skipped setup to be recorded, got %+v", ws.Template)
+	}
+	if ws.Template != nil && (len(ws.Template.CopyErrors) != 1 || !strings.HasPrefix(ws.Template.CopyErrors[0], "../outside")) {
+		t.Errorf("expected paths outside the repository to be rejected, got %v", ws.Template.CopyErrors)
+	}
+
+	// Authorized command prefixes let the setup run
+	cfg.AuthorizeCommand("make")
+	ws, err = wm.CreateWorktree(context.Background(), repoDir, "allowed")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if len(executor.calls) != 1 || ws.Template == nil || !ws.Template.SetupRan {
+		t.Errorf("expected the authorized setup command to run once, got %v", executor.calls)
+	}
+}
+
+func TestCreateWorktreeWithoutTemplate(t *testing.T) {
+	repoDir := setupTemplateRepo(t)
+	wm, err := NewWorkspaceManager()
+	if err != nil {
+		t.Fatalf("Failed to create workspace manager: %v", err)
+	}
+	executor := &templateTestExecutor{}
+	wm.SetWorkspaceTemplates((&config.Config{}).GetWorkspaceTemplate, &templateTestAuthorizer{allowed: true}, executor)
+
+	ws, err := wm.CreateWorktree(context.Background(), repoDir, "plain")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if ws.Template != nil || len(executor.calls) != 0 {
+		t.Errorf("expected no template to be applied, got %+v and %v", ws.Template, executor.calls)
+	}
+	if _, err := os.Stat(filepath.Join(ws.Path, ".env")); !os.IsNotExist(err) {
+		t.Errorf("expected untracked files not to be copied without a template")
+	}
+}
+
+func TestWorkspaceSetupAuthorizerUsesShellRules(t *testing.T) {
+	authorizer := newWorkspaceSetupAuthorizer(&config.Config{})
+
+	// Commands the authorization actor considers safe need no config entry
+	decision, err := authorizer.Authorize(context.Background(), tools.ToolNameShell, map[string]interface{}{"command": "go vet ./..."})
+	if err != nil || decision == nil || !decision.Allowed {
+		t.Errorf("expected go vet to be allowed, got %+v (err %v)", decision, err)
+	}
+
+	decision, err = authorizer.Authorize(context.Background(), tools.ToolNameShell, map[string]interface{}{"command": "go vet ./... && rm -rf build"})
+	if err != nil || decision == nil || decision.Allowed || decision.SuggestedCommandPrefix == "" {
+		t.Errorf("expected a chained command to need authorization, got %+v (err %v)", decision, err)
+	}
+}
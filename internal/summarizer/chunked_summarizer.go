@@ -50,6 +50,7 @@ type SummarizeOptions struct {
 	MaxBytes         int    // Override default MaxSummaryBytes
 	Timeout          time.Duration
 	ProgressCallback func(status string)
+	PercentCallback  func(percent int) // Share of the model calls done, called after each call
 }
 
 // Summarize summarizes content with automatic chunking if needed
@@ -85,7 +86,11 @@ func (cs *ChunkedSummarizer) Summarize(ctx context.Context, content string, opts
 		if opts.ProgressCallback != nil {
 			opts.ProgressCallback("Summarizing content directly")
 		}
-		return cs.summarizeDirectly(ctx, content, opts, maxBytes)
+		result, err := cs.summarizeDirectly(ctx, content, opts, maxBytes)
+		if err == nil && opts.PercentCallback != nil {
+			opts.PercentCallback(100)
+		}
+		return result, err
 	}
 
 	// Otherwise, use chunked summarization
@@ -151,6 +156,15 @@ func (cs *ChunkedSummarizer) summarizeWithChunking(ctx context.Context, content
 
 		partialSummaries = append(partialSummaries, result.Summary)
 		totalTokens += result.TotalTokens
+
+		// Combining the partial summaries is one more call
+		if opts.PercentCallback != nil {
+			calls := len(chunks) + 1
+			if len(chunks) == 1 {
+				calls = 1
+			}
+			opts.PercentCallback((i + 1) * 100 / calls)
+		}
 	}
 
 	// If single chunk, return as-is
@@ -175,6 +189,9 @@ func (cs *ChunkedSummarizer) summarizeWithChunking(ctx context.Context, content
 	defer cancel()
 
 	finalSummary, err := cs.client.Complete(ctx, finalPrompt)
+	if opts.PercentCallback != nil {
+		opts.PercentCallback(100)
+	}
 	if err != nil {
 		logger.Debug("summarizer: final combination failed, returning combined partials: %v", err)
 		// Fallback: return combined partial summaries
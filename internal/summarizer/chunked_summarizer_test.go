@@ -296,3 +296,44 @@ func TestChunkedSummarizer_ContextInPrompt(t *testing.T) {
 		t.Error("expected base prompt to be included")
 	}
 }
+
+func TestChunkedSummarizer_PercentCallback(t *testing.T) {
+	mockClient := &MockClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "Summary", nil
+		},
+	}
+
+	summarizer := NewChunkedSummarizer(mockClient)
+	summarizer.ChunkThresholdPercent = 0.05 // Very low to force chunking
+
+	var percents []int
+	_, err := summarizer.Summarize(context.Background(), strings.Repeat("This is more content. ", 5000), SummarizeOptions{
+		BasePrompt:      "Summarize",
+		PercentCallback: func(percent int) { percents = append(percents, percent) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// One update per chunk and one for combining them
+	if len(percents) < 3 || percents[len(percents)-1] != 100 {
+		t.Fatalf("expected increasing percentages ending at 100, got %v", percents)
+	}
+	for i := 1; i < len(percents); i++ {
+		if percents[i] <= percents[i-1] {
+			t.Fatalf("expected increasing percentages, got %v", percents)
+		}
+	}
+
+	percents = nil
+	if _, err := summarizer.Summarize(context.Background(), "short", SummarizeOptions{
+		BasePrompt:      "Summarize",
+		PercentCallback: func(percent int) { percents = append(percents, percent) },
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(percents) != 1 || percents[0] != 100 {
+		t.Errorf("expected a direct summary to report 100%%, got %v", percents)
+	}
+}
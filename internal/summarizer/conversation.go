@@ -0,0 +1,78 @@
+package summarizer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// FormatConversation renders messages as "Role: content" blocks for summarization
+func FormatConversation(messages []*session.Message) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		sb.WriteString(RoleLabel(msg))
+		sb.WriteString(": ")
+		sb.WriteString(strings.TrimSpace(msg.Content))
+		sb.WriteString("\n---\n")
+	}
+	return sb.String()
+}
+
+// FallbackConversationSummary condenses every message to a short bullet point.
+// It is used when no model summary is available.
+func FallbackConversationSummary(messages []*session.Message) string {
+	if len(messages) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Key points retained:\n")
+	for _, msg := range messages {
+		sb.WriteString("- ")
+		sb.WriteString(RoleLabel(msg))
+		sb.WriteString(": ")
+		sb.WriteString(CondenseContent(msg.Content, 200))
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// CondenseContent collapses whitespace and truncates content to limit runes
+func CondenseContent(content string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+
+	collapsed := strings.Join(strings.Fields(content), " ")
+	if collapsed == "" {
+		return "(no content)"
+	}
+
+	runes := []rune(collapsed)
+	if len(runes) <= limit {
+		return collapsed
+	}
+
+	if limit <= 3 {
+		return string(runes[:limit])
+	}
+
+	return string(runes[:limit-3]) + "..."
+}
+
+// RoleLabel returns the capitalized role of a message, including the tool name
+func RoleLabel(msg *session.Message) string {
+	role := strings.TrimSpace(msg.Role)
+	if role == "" {
+		role = "unknown"
+	}
+	runes := []rune(role)
+	runes[0] = unicode.ToUpper(runes[0])
+	label := string(runes)
+	if msg.ToolName != "" {
+		label += " (" + msg.ToolName + ")"
+	}
+	return label
+}
@@ -0,0 +1,216 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/consts"
+	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+var (
+	// ErrUnavailable is returned when no summarization client is configured
+	ErrUnavailable = errors.New("summarization client not configured")
+	// ErrTimeout is returned when the summarization model does not answer in time
+	ErrTimeout = errors.New("summarization timed out")
+	// ErrEmptySummary is returned when the model answers with an empty summary
+	ErrEmptySummary = errors.New("summarization returned an empty summary")
+)
+
+const (
+	// DefaultMaxInputBytes caps the text sent for a single text summary
+	DefaultMaxInputBytes = 256 * 1024
+	// DefaultMaxFallbackBytes caps the raw text returned by FallbackText
+	DefaultMaxFallbackBytes = 32 * 1024
+)
+
+// Summarizer is the shared summarization service. Tools and the orchestrator
+// use it instead of calling the summarize client directly, so prompts,
+// timeouts and fallbacks are tuned in one place.
+type Summarizer struct {
+	client llm.Client
+
+	// Configuration
+	Timeout          time.Duration // Per model call (default: 60s)
+	MaxInputBytes    int           // Max bytes of text for SummarizeText
+	MaxFallbackBytes int           // Max bytes of raw text returned by FallbackText
+}
+
+// NewSummarizer creates a summarization service; client may be nil, in which
+// case every summary fails with ErrUnavailable or uses its fallback
+func NewSummarizer(client llm.Client) *Summarizer {
+	return &Summarizer{
+		client:           client,
+		Timeout:          consts.Timeout60,
+		MaxInputBytes:    DefaultMaxInputBytes,
+		MaxFallbackBytes: DefaultMaxFallbackBytes,
+	}
+}
+
+// Available reports whether a summarization model is configured
+func (s *Summarizer) Available() bool {
+	return s != nil && s.client != nil
+}
+
+// Client returns the underlying summarize client (may be nil)
+func (s *Summarizer) Client() llm.Client {
+	if s == nil {
+		return nil
+	}
+	return s.client
+}
+
+func (s *Summarizer) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return consts.Timeout60
+}
+
+// TextRequest describes a text to summarize
+type TextRequest struct {
+	Instruction string // What the summary should focus on
+	Context     string // Where the text comes from, e.g. "File: main.go"
+	Label       string // Heading for the text in the prompt (default: "Content")
+	Text        string
+}
+
+// SummarizeText summarizes text following instruction
+func (s *Summarizer) SummarizeText(ctx context.Context, instruction, text string) (string, error) {
+	return s.Summarize(ctx, TextRequest{Instruction: instruction, Text: text})
+}
+
+// Summarize summarizes a text request. Text beyond MaxInputBytes is
+// truncated. Errors wrap ErrUnavailable, ErrTimeout or ErrEmptySummary where
+// applicable; callers choose their own fallback (see FallbackText).
+func (s *Summarizer) Summarize(ctx context.Context, req TextRequest) (string, error) {
+	if !s.Available() {
+		return "", ErrUnavailable
+	}
+
+	limit := s.MaxInputBytes
+	if limit <= 0 {
+		limit = DefaultMaxInputBytes
+	}
+	content, truncated := truncateStringToBytes(req.Text, limit)
+	if truncated {
+		content += fmt.Sprintf("\n\n[Content truncated to %d bytes for summarization]", limit)
+	}
+	req.Text = content
+
+	timeout := s.timeout()
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	summary, err := s.client.Complete(callCtx, buildTextPrompt(req))
+	if err != nil {
+		// Only report a timeout if our deadline hit, not the caller's
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return "", fmt.Errorf("%w after %v", ErrTimeout, timeout)
+		}
+		return "", fmt.Errorf("summarization failed: %w", err)
+	}
+
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", ErrEmptySummary
+	}
+	return summary, nil
+}
+
+// FallbackText returns text (truncated to MaxFallbackBytes) for callers that
+// hand back raw content when summarization fails
+func (s *Summarizer) FallbackText(text string) string {
+	limit := DefaultMaxFallbackBytes
+	if s != nil && s.MaxFallbackBytes > 0 {
+		limit = s.MaxFallbackBytes
+	}
+	fallback, truncated := truncateStringToBytes(text, limit)
+	if truncated {
+		fallback += fmt.Sprintf("\n\n[Output truncated to %d bytes]", limit)
+	}
+	return fallback
+}
+
+func buildTextPrompt(req TextRequest) string {
+	label := req.Label
+	if label == "" {
+		label = "Content"
+	}
+
+	var sb strings.Builder
+	if req.Context != "" {
+		sb.WriteString(strings.TrimSpace(req.Context))
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(strings.TrimSpace(req.Instruction))
+	fmt.Fprintf(&sb, "\n\n%s:\n", label)
+	sb.WriteString(req.Text)
+	sb.WriteString("\n\nBe concise and direct. If the information isn't in the text, say so clearly.")
+	return sb.String()
+}
+
+// ConversationOptions configures SummarizeConversation
+type ConversationOptions struct {
+	Instruction string // The summarization goal (e.g. a compaction prompt)
+	MaxBytes    int    // Max bytes of conversation per model call (0 = default)
+	Progress    func(status string)
+	Percent     func(percent int) // Share of the summarization done, see SummarizeOptions.PercentCallback
+}
+
+// ConversationSummary is the result of SummarizeConversation
+type ConversationSummary struct {
+	Summary     string
+	ChunksUsed  int
+	TotalTokens int
+	Fallback    bool  // Summary was built without the model
+	Err         error // Why the fallback was used
+}
+
+// SummarizeConversation summarizes messages with automatic chunking. It
+// always returns a summary: if the model is unavailable, fails or answers
+// with nothing, a condensed bullet list of the messages is used instead.
+func (s *Summarizer) SummarizeConversation(ctx context.Context, messages []*session.Message, opts ConversationOptions) ConversationSummary {
+	if !s.Available() {
+		return conversationFallback(messages, ErrUnavailable)
+	}
+
+	chunked := NewChunkedSummarizer(s.client)
+	result, err := chunked.Summarize(ctx, FormatConversation(messages), SummarizeOptions{
+		BasePrompt:       opts.Instruction,
+		MaxBytes:         opts.MaxBytes,
+		Timeout:          s.timeout(),
+		ProgressCallback: opts.Progress,
+		PercentCallback:  opts.Percent,
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			err = fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
+		return conversationFallback(messages, err)
+	}
+
+	summary := strings.TrimSpace(result.Summary)
+	if summary == "" {
+		return conversationFallback(messages, ErrEmptySummary)
+	}
+	return ConversationSummary{
+		Summary:     summary,
+		ChunksUsed:  result.ChunksUsed,
+		TotalTokens: result.TotalTokens,
+	}
+}
+
+func conversationFallback(messages []*session.Message, err error) ConversationSummary {
+	logger.Debug("summarizer: using fallback conversation summary: %v", err)
+	return ConversationSummary{
+		Summary:  FallbackConversationSummary(messages),
+		Fallback: true,
+		Err:      err,
+	}
+}
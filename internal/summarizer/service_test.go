@@ -0,0 +1,197 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestSummarizer_SummarizeText(t *testing.T) {
+	var gotPrompt string
+	svc := NewSummarizer(&MockClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			gotPrompt = prompt
+			return "  short summary \n", nil
+		},
+	})
+
+	summary, err := svc.Summarize(context.Background(), TextRequest{
+		Instruction: "Find the main function",
+		Context:     "File: main.go",
+		Label:       "File content",
+		Text:        "package main\nfunc main() {}",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "short summary" {
+		t.Errorf("expected trimmed summary, got %q", summary)
+	}
+	for _, want := range []string{"File: main.go", "Find the main function", "File content:\npackage main"} {
+		if !strings.Contains(gotPrompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, gotPrompt)
+		}
+	}
+}
+
+func TestSummarizer_TruncatesInput(t *testing.T) {
+	var gotPrompt string
+	svc := NewSummarizer(&MockClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			gotPrompt = prompt
+			return "ok", nil
+		},
+	})
+	svc.MaxInputBytes = 100
+
+	if _, err := svc.SummarizeText(context.Background(), "summarize", strings.Repeat("q", 1000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(gotPrompt, "q") > 100 {
+		t.Errorf("expected input to be truncated to 100 bytes, prompt has %d bytes", len(gotPrompt))
+	}
+	if !strings.Contains(gotPrompt, "[Content truncated to 100 bytes for summarization]") {
+		t.Errorf("expected truncation notice in prompt:\n%s", gotPrompt)
+	}
+}
+
+func TestSummarizer_Timeout(t *testing.T) {
+	svc := NewSummarizer(&MockClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	})
+	svc.Timeout = 20 * time.Millisecond
+
+	_, err := svc.SummarizeText(context.Background(), "summarize", "some text")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+
+	// A cancelled caller context is not reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = svc.SummarizeText(ctx, "summarize", "some text")
+	if err == nil || errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+}
+
+func TestSummarizer_EmptySummary(t *testing.T) {
+	svc := NewSummarizer(&MockClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "   ", nil
+		},
+	})
+
+	if _, err := svc.SummarizeText(context.Background(), "summarize", "text"); !errors.Is(err, ErrEmptySummary) {
+		t.Fatalf("expected ErrEmptySummary, got %v", err)
+	}
+}
+
+func TestSummarizer_Unavailable(t *testing.T) {
+	for name, svc := range map[string]*Summarizer{"nil service": nil, "nil client": NewSummarizer(nil)} {
+		t.Run(name, func(t *testing.T) {
+			if svc.Available() {
+				t.Fatal("expected service to be unavailable")
+			}
+			if _, err := svc.SummarizeText(context.Background(), "summarize", "text"); !errors.Is(err, ErrUnavailable) {
+				t.Fatalf("expected ErrUnavailable, got %v", err)
+			}
+
+			messages := []*session.Message{{Role: "user", Content: "fix the build"}}
+			result := svc.SummarizeConversation(context.Background(), messages, ConversationOptions{Instruction: "summarize"})
+			if !result.Fallback || !errors.Is(result.Err, ErrUnavailable) {
+				t.Fatalf("expected fallback with ErrUnavailable, got %+v", result)
+			}
+			if !strings.Contains(result.Summary, "User: fix the build") {
+				t.Errorf("expected fallback summary to contain the message, got %q", result.Summary)
+			}
+		})
+	}
+}
+
+func TestSummarizer_SummarizeConversation(t *testing.T) {
+	messages := []*session.Message{
+		{Role: "user", Content: "Please refactor the parser"},
+		{Role: "assistant", Content: "Done, parser refactored"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var gotPrompt string
+		svc := NewSummarizer(&MockClient{
+			CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+				gotPrompt = prompt
+				return "Parser was refactored", nil
+			},
+		})
+
+		result := svc.SummarizeConversation(context.Background(), messages, ConversationOptions{Instruction: "Summarize the conversation"})
+		if result.Fallback || result.Err != nil {
+			t.Fatalf("unexpected fallback: %+v", result)
+		}
+		if result.Summary != "Parser was refactored" {
+			t.Errorf("unexpected summary %q", result.Summary)
+		}
+		if !strings.Contains(gotPrompt, "User: Please refactor the parser") {
+			t.Errorf("expected formatted conversation in prompt:\n%s", gotPrompt)
+		}
+	})
+
+	t.Run("failure falls back", func(t *testing.T) {
+		svc := NewSummarizer(&MockClient{
+			CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+				return "", errors.New("model overloaded")
+			},
+		})
+
+		result := svc.SummarizeConversation(context.Background(), messages, ConversationOptions{Instruction: "Summarize"})
+		if !result.Fallback || result.Err == nil {
+			t.Fatalf("expected fallback with error, got %+v", result)
+		}
+		if result.Summary != FallbackConversationSummary(messages) {
+			t.Errorf("expected fallback summary, got %q", result.Summary)
+		}
+	})
+
+	t.Run("timeout falls back", func(t *testing.T) {
+		svc := NewSummarizer(&MockClient{
+			CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		})
+		svc.Timeout = 20 * time.Millisecond
+
+		result := svc.SummarizeConversation(context.Background(), messages, ConversationOptions{Instruction: "Summarize"})
+		if !result.Fallback || !errors.Is(result.Err, ErrTimeout) {
+			t.Fatalf("expected fallback with ErrTimeout, got %+v", result)
+		}
+		if !strings.HasPrefix(result.Summary, "Key points retained:") {
+			t.Errorf("expected fallback summary, got %q", result.Summary)
+		}
+	})
+}
+
+func TestSummarizer_FallbackText(t *testing.T) {
+	svc := NewSummarizer(nil)
+	svc.MaxFallbackBytes = 10
+
+	if got := svc.FallbackText("short"); got != "short" {
+		t.Errorf("expected short text unchanged, got %q", got)
+	}
+	got := svc.FallbackText(strings.Repeat("a", 50))
+	if !strings.HasPrefix(got, strings.Repeat("a", 10)+"\n\n[Output truncated to 10 bytes]") {
+		t.Errorf("expected truncated fallback, got %q", got)
+	}
+
+	var nilSvc *Summarizer
+	if got := nilSvc.FallbackText("text"); got != "text" {
+		t.Errorf("expected nil service to return text, got %q", got)
+	}
+}
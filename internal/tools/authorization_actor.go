@@ -35,8 +35,11 @@ type AuthorizationOptions struct {
 	AllowedDirs         []string
 	AllowedFiles        []string
 	AllowedDomains      []string
-	AllowedCommands     []string // Command prefixes that are pre-authorized
-	RequireSandboxAuth  bool     // Require authorization for every go_sandbox and shell call
+	AllowedCommands     []string             // Command prefixes that are pre-authorized
+	RequireSandboxAuth  bool                 // Require authorization for every go_sandbox and shell call
+	DryRun              bool                 // Mutating calls are simulated; decisions say so in their reason
+	ReadOnly            bool                 // Only read-only tools are allowed; every write is denied
+	Policy              *AuthorizationPolicy // Rules deciding calls before any other check or prompt (nil = none)
 }
 
 // AuthorizationActor handles policy decisions for tool calls in a centralized manner.
@@ -357,9 +360,56 @@ func (c *AuthorizationActorClient) Authorize(ctx context.Context, toolName strin
 	}
 }
 
-// authorize evaluates tool-specific authorization policies.
+// Authorize implements Authorizer for callers that use the actor directly,
+// without an actor system
+func (a *AuthorizationActor) Authorize(ctx context.Context, toolName string, params map[string]interface{}) (*AuthorizationDecision, error) {
+	return a.authorize(ctx, toolName, params)
+}
+
+// authorize evaluates tool-specific authorization policies. In read-only mode
+// every call that could change something is denied, even with
+// DangerouslyAllowAll. In dry-run mode decisions for mutating calls note that
+// the call will only be simulated.
 func (a *AuthorizationActor) authorize(ctx context.Context, toolName string, params map[string]interface{}) (*AuthorizationDecision, error) {
+	if a.readOnly() {
+		if denial := readOnlyDenial(toolName, params); denial != nil {
+			logger.Info("Authorization: denied %s in read-only mode", toolName)
+			return denial, nil
+		}
+	}
+
+	decision, err := a.authorizeTool(ctx, toolName, params)
+	if err != nil || decision == nil || !a.options.DryRun || !IsMutatingToolCall(toolName, params) {
+		return decision, err
+	}
+	if !decision.Allowed && !decision.RequiresUserInput {
+		return decision, nil // Denied calls are not simulated either
+	}
+
+	const dryRunReason = "Dry run: this call will be simulated and nothing will be changed"
+	if decision.Reason == "" {
+		decision.Reason = dryRunReason
+	} else {
+		decision.Reason = dryRunReason + ". " + decision.Reason
+	}
+	return decision, nil
+}
+
+// readOnly reports whether the actor or its session is in read-only mode
+func (a *AuthorizationActor) readOnly() bool {
+	return a.options.ReadOnly || (a.session != nil && a.session.IsReadOnly())
+}
+
+// authorizeTool applies the policy of a single tool
+func (a *AuthorizationActor) authorizeTool(ctx context.Context, toolName string, params map[string]interface{}) (*AuthorizationDecision, error) {
 	logger.Debug("AuthorizationActor: authorize called for tool=%s, requireSandboxAuth=%v", toolName, a.requireSandboxAuth)
+
+	// Policy rules are explicit, so they win over the coarse options
+	if decision := a.options.Policy.Evaluate(a.workingDir, toolName, params); decision != nil {
+		logger.Info("Authorization: %s decided by policy (allowed=%v)", toolName, decision.Allowed)
+		return decision, nil
+	}
+
 	if a.options.DangerouslyAllowAll {
 		return &AuthorizationDecision{Allowed: true}, nil
 	}
@@ -389,18 +439,24 @@ func (a *AuthorizationActor) authorize(ctx context.Context, toolName string, par
 		return a.authorizeWriteFileDiff(ctx, params)
 	case ToolNameReplaceFile:
 		return a.authorizeReplaceFile(ctx, params)
+	case ToolNameReplaceInFiles:
+		return a.authorizeReplaceInFiles(params)
 	case ToolNameCreateFile:
 		return a.authorizeCreateFile(ctx, params)
 	case ToolNameGoSandboxDomain:
 		return a.authorizeSandboxDomain(ctx, params)
 	case ToolNameWebFetch:
 		return a.authorizeWebFetch(ctx, params)
+	case ToolNameFetchDocs:
+		return a.authorizeFetchDocs(ctx, params)
 	case ToolNameShell:
 		return a.authorizeShell(ctx, params)
 	case ToolNameCommand:
 		return a.authorizeShell(ctx, params)
 	case ToolNameAddContextDirectory:
 		return a.authorizeAddContextDirectory(ctx, params)
+	case ToolNameMemory:
+		return a.authorizeMemory(params)
 	default:
 		return &AuthorizationDecision{Allowed: true}, nil
 	}
@@ -473,6 +529,40 @@ func (a *AuthorizationActor) authorizeReplaceFile(ctx context.Context, params ma
 	return &AuthorizationDecision{Allowed: true}, nil
 }
 
+// authorizeReplaceInFiles always asks the user before a bulk replacement is
+// applied; previews do not change anything and are allowed.
+func (a *AuthorizationActor) authorizeReplaceInFiles(params map[string]interface{}) (*AuthorizationDecision, error) {
+	if GetBoolParam(params, "preview", false) {
+		return &AuthorizationDecision{Allowed: true}, nil
+	}
+
+	kind := "text"
+	if GetBoolParam(params, "regex", false) {
+		kind = "regex"
+	}
+	return &AuthorizationDecision{
+		Allowed: false,
+		Reason: fmt.Sprintf("Replace %s %q with %q in all files matching %s in %s",
+			kind, GetStringParam(params, "search", ""), GetStringParam(params, "replacement", ""), GetStringParam(params, "files", ""),
+			GetStringParam(params, "path", ".")),
+		RequiresUserInput: true,
+	}, nil
+}
+
+// authorizeMemory asks the user before a note is appended to the project
+// memory file, unless the file is pre-authorized. Reading and searching notes
+// is always allowed.
+func (a *AuthorizationActor) authorizeMemory(params map[string]interface{}) (*AuthorizationDecision, error) {
+	if GetStringParam(params, "operation", "") != "append" || a.isPathPreauthorized(llm.ProjectMemoryFileName) {
+		return &AuthorizationDecision{Allowed: true}, nil
+	}
+	return &AuthorizationDecision{
+		Allowed:           false,
+		Reason:            fmt.Sprintf("Append a note to %s:\n\n%s", llm.ProjectMemoryFileName, strings.TrimSpace(GetStringParam(params, "content", ""))),
+		RequiresUserInput: true,
+	}, nil
+}
+
 // authorizeCreateFile ensures new files are created safely.
 func (a *AuthorizationActor) authorizeCreateFile(ctx context.Context, params map[string]interface{}) (*AuthorizationDecision, error) {
 	path := GetStringParam(params, "path", "")
@@ -542,6 +632,15 @@ func (a *AuthorizationActor) authorizeWebFetch(ctx context.Context, params map[s
 	return a.authorizeSandboxDomain(ctx, map[string]interface{}{"domain": domain})
 }
 
+// authorizeFetchDocs requires the domain of the documentation to be authorized
+func (a *AuthorizationActor) authorizeFetchDocs(ctx context.Context, params map[string]interface{}) (*AuthorizationDecision, error) {
+	reqURL, err := fetchDocsURL(params)
+	if err != nil {
+		return &AuthorizationDecision{Allowed: false, Reason: err.Error()}, nil
+	}
+	return a.authorizeWebFetch(ctx, map[string]interface{}{"url": reqURL.String()})
+}
+
 // authorizeShell checks if a shell command is safe to execute
 func (a *AuthorizationActor) authorizeShell(ctx context.Context, params map[string]interface{}) (*AuthorizationDecision, error) {
 	command := GetStringParam(params, "command", "")
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Actions of authorization policy rules
+const (
+	PolicyActionAllow = "allow"
+	PolicyActionDeny  = "deny"
+)
+
+// AuthorizationPolicy is a list of rules deciding tool calls without asking
+// the user. Rules are evaluated in order and the first matching rule wins;
+// calls no rule matches go through the usual authorization, which prompts
+// the user or, in non-interactive runs, denies what is not pre-authorized.
+type AuthorizationPolicy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule allows or denies tool calls. Every condition that is set must
+// match; a rule only naming a tool matches every call of that tool.
+type PolicyRule struct {
+	Action  string `json:"action"`            // "allow" or "deny"
+	Tool    string `json:"tool,omitempty"`    // Tool name ("" or "*" = any tool)
+	Path    string `json:"path,omitempty"`    // Glob of the file path; "dir/**" matches everything below dir. Relative to the working directory
+	Command string `json:"command,omitempty"` // Prefix of shell commands
+	Domain  string `json:"domain,omitempty"`  // Network domain, "*.example.com" also matches subdomains
+	Reason  string `json:"reason,omitempty"`  // Shown to the model when the rule denies a call
+}
+
+// LoadAuthorizationPolicy reads a JSON policy file
+func LoadAuthorizationPolicy(path string) (*AuthorizationPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization policy: %w", err)
+	}
+
+	var policy AuthorizationPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse authorization policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authorization policy %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// Validate checks the actions and patterns of all rules
+func (p *AuthorizationPolicy) Validate() error {
+	for idx, rule := range p.Rules {
+		if rule.Action != PolicyActionAllow && rule.Action != PolicyActionDeny {
+			return fmt.Errorf("rule %d: action must be %q or %q, got %q", idx+1, PolicyActionAllow, PolicyActionDeny, rule.Action)
+		}
+		if rule.Tool == "" && rule.Path == "" && rule.Command == "" && rule.Domain == "" {
+			return fmt.Errorf("rule %d: needs a tool, path, command or domain (use tool \"*\" to match every call)", idx+1)
+		}
+		if _, err := filepath.Match(strings.TrimSuffix(rule.Path, "/**"), ""); err != nil {
+			return fmt.Errorf("rule %d: invalid path pattern %q: %w", idx+1, rule.Path, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the decision of the first rule matching the call, or nil
+// if no rule matches. Relative paths are resolved against workingDir.
+func (p *AuthorizationPolicy) Evaluate(workingDir, toolName string, params map[string]interface{}) *AuthorizationDecision {
+	if p == nil {
+		return nil
+	}
+
+	for _, rule := range p.Rules {
+		if !rule.matches(workingDir, toolName, params) {
+			continue
+		}
+		if rule.Action == PolicyActionAllow {
+			return &AuthorizationDecision{Allowed: true}
+		}
+		reason := rule.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("%s is denied by the authorization policy", toolName)
+		}
+		return &AuthorizationDecision{Allowed: false, Reason: reason}
+	}
+	return nil
+}
+
+// matches reports whether all conditions of the rule match the call
+func (r *PolicyRule) matches(workingDir, toolName string, params map[string]interface{}) bool {
+	if r.Tool != "" && r.Tool != "*" && r.Tool != toolName {
+		return false
+	}
+
+	if r.Path != "" {
+		path := GetStringParam(params, "path", GetStringParam(params, "file_path", ""))
+		if path == "" || !matchPolicyPath(resolvePolicyPath(workingDir, r.Path), resolvePolicyPath(workingDir, path)) {
+			return false
+		}
+	}
+
+	if r.Command != "" {
+		if toolName != ToolNameShell && toolName != ToolNameCommand {
+			return false
+		}
+		if !strings.HasPrefix(strings.TrimSpace(GetStringParam(params, "command", "")), r.Command) {
+			return false
+		}
+	}
+
+	if r.Domain != "" {
+		domain := policyCallDomain(params)
+		pattern := normalizeAuthorizationDomain(r.Domain)
+		if domain == "" || (domain != pattern && !matchesWildcardDomain(pattern, domain)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolvePolicyPath makes path absolute against workingDir
+func resolvePolicyPath(workingDir, path string) string {
+	if !filepath.IsAbs(path) && workingDir != "" {
+		path = filepath.Join(workingDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// matchPolicyPath matches a path against a glob; a trailing "/**" matches
+// the directory and everything below it
+func matchPolicyPath(pattern, path string) bool {
+	if dir, ok := strings.CutSuffix(pattern, string(filepath.Separator)+"**"); ok {
+		if matched, _ := filepath.Match(dir, path); matched {
+			return true
+		}
+		for parent := filepath.Dir(path); parent != path; path, parent = parent, filepath.Dir(parent) {
+			if matched, _ := filepath.Match(dir, parent); matched {
+				return true
+			}
+		}
+		return false
+	}
+	matched, _ := filepath.Match(pattern, path)
+	return matched
+}
+
+// policyCallDomain returns the network domain a call accesses, from its
+// domain parameter or the host of its url parameter
+func policyCallDomain(params map[string]interface{}) string {
+	if domain := GetStringParam(params, "domain", ""); domain != "" {
+		return normalizeAuthorizationDomain(domain)
+	}
+	if rawURL := GetStringParam(params, "url", ""); rawURL != "" {
+		if parsed, err := url.Parse(rawURL); err == nil {
+			return normalizeAuthorizationDomain(parsed.Hostname())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/actor"
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+var testPolicy = &AuthorizationPolicy{Rules: []PolicyRule{
+	{Action: PolicyActionDeny, Tool: ToolNameShell, Command: "git push", Reason: "pushing is not allowed in CI"},
+	{Action: PolicyActionAllow, Tool: ToolNameShell, Command: "make "},
+	{Action: PolicyActionAllow, Tool: ToolNameCreateFile, Path: "gen/**"},
+	{Action: PolicyActionDeny, Path: "secrets/*"},
+	{Action: PolicyActionAllow, Domain: "*.golang.org"},
+}}
+
+// policyOutcome authorizes a call like the orchestrator does: decisions that
+// need user input go to handler, or count as a prompt if handler is nil
+func policyOutcome(t *testing.T, authActor *AuthorizationActor, handler *actor.NonInteractiveHandler, toolName string, params map[string]interface{}) string {
+	t.Helper()
+
+	decision, err := authActor.authorize(context.Background(), toolName, params)
+	if err != nil {
+		t.Fatalf("authorize returned error: %v", err)
+	}
+	switch {
+	case decision.Allowed:
+		return "allow"
+	case !decision.RequiresUserInput:
+		return "deny: " + decision.Reason
+	case handler == nil:
+		return "prompt"
+	}
+
+	resp, err := handler.HandleInteraction(context.Background(), &actor.UserInteractionRequest{
+		InteractionType: actor.InteractionTypeAuthorization,
+		Payload:         &actor.AuthorizationPayload{ToolName: toolName, Parameters: params, Reason: decision.Reason},
+	})
+	if err != nil {
+		t.Fatalf("HandleInteraction returned error: %v", err)
+	}
+	if resp.Approved {
+		return "allow"
+	}
+	return "deny: non-interactive"
+}
+
+func TestAuthorizationPolicyDecidesWithoutPrompting(t *testing.T) {
+	workingDir := t.TempDir()
+	mockFS := fs.NewMockFS()
+	if err := mockFS.WriteFile(context.Background(), filepath.Join(workingDir, "secrets", "key.txt"), []byte("key")); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	for _, mode := range []struct {
+		name    string
+		handler *actor.NonInteractiveHandler
+		noMatch string
+	}{
+		{name: "interactive", noMatch: "prompt"},
+		{name: "non-interactive", handler: actor.NewNonInteractiveHandler(nil), noMatch: "deny: non-interactive"},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			sess := session.NewSession("test", workingDir)
+			authActor := NewAuthorizationActor("auth", mockFS, sess, nil, &AuthorizationOptions{Policy: testPolicy})
+
+			cases := []struct {
+				tool   string
+				params map[string]interface{}
+				want   string
+			}{
+				// Allow rules
+				{ToolNameShell, map[string]interface{}{"command": "make test"}, "allow"},
+				{ToolNameCreateFile, map[string]interface{}{"path": "gen/api/types.go"}, "allow"},
+				{ToolNameWebFetch, map[string]interface{}{"url": "https://pkg.golang.org/x"}, "allow"},
+				// Deny rules, also for tools allowed by default
+				{ToolNameShell, map[string]interface{}{"command": "git push origin main"}, "deny: pushing is not allowed in CI"},
+				{ToolNameReadFile, map[string]interface{}{"path": filepath.Join(workingDir, "secrets", "key.txt")}, "deny: read_file is denied by the authorization policy"},
+				// No rule matches
+				{ToolNameShell, map[string]interface{}{"command": "curl example.com"}, mode.noMatch},
+				{ToolNameCreateFile, map[string]interface{}{"path": "src/main.go"}, "allow"}, // New files need no approval
+				{ToolNameWebFetch, map[string]interface{}{"url": "https://example.com"}, mode.noMatch},
+			}
+			for _, tc := range cases {
+				if got := policyOutcome(t, authActor, mode.handler, tc.tool, tc.params); got != tc.want {
+					t.Errorf("%s %v: expected %q, got %q", tc.tool, tc.params, tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizationPolicyDenyWinsOverAllowAll(t *testing.T) {
+	sess := session.NewSession("test", t.TempDir())
+	authActor := NewAuthorizationActor("auth", fs.NewMockFS(), sess, nil, &AuthorizationOptions{DangerouslyAllowAll: true, Policy: testPolicy})
+
+	if got := policyOutcome(t, authActor, nil, ToolNameShell, map[string]interface{}{"command": "git push"}); !strings.HasPrefix(got, "deny") {
+		t.Errorf("expected the deny rule to apply with DangerouslyAllowAll, got %q", got)
+	}
+	if got := policyOutcome(t, authActor, nil, ToolNameShell, map[string]interface{}{"command": "rm -rf build"}); got != "allow" {
+		t.Errorf("expected unmatched calls to be allowed with DangerouslyAllowAll, got %q", got)
+	}
+}
+
+func TestLoadAuthorizationPolicy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write policy: %v", err)
+		}
+		return path
+	}
+
+	policy, err := LoadAuthorizationPolicy(write("ok.json", `{"rules":[{"action":"allow","tool":"shell","command":"go test"}]}`))
+	if err != nil {
+		t.Fatalf("expected a valid policy to load: %v", err)
+	}
+	if decision := policy.Evaluate("", ToolNameShell, map[string]interface{}{"command": "go test ./..."}); decision == nil || !decision.Allowed {
+		t.Errorf("expected the loaded rule to allow the call, got %+v", decision)
+	}
+
+	for name, content := range map[string]string{
+		"action.json":  `{"rules":[{"action":"maybe","tool":"shell"}]}`,
+		"empty.json":   `{"rules":[{"action":"deny"}]}`,
+		"pattern.json": `{"rules":[{"action":"deny","path":"[a-"}]}`,
+		"syntax.json":  `{"rules":[`,
+	} {
+		if _, err := LoadAuthorizationPolicy(write(name, content)); err == nil {
+			t.Errorf("%s: expected the policy to be rejected", name)
+		}
+	}
+	if _, err := LoadAuthorizationPolicy(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected a missing policy file to be rejected")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	internalfs "github.com/codefionn/scriptschnell/internal/fs"
@@ -36,12 +35,10 @@ func (afs *AuthorizedFS) isPathAllowed(path string, requireRead bool) error {
 		return nil
 	}
 
-	// Clean and normalize the path
-	cleanPath := filepath.Clean(path)
-
-	// If this is a write operation, check read-before-write rule
+	// If this is a write operation, check read-before-write rule. The session
+	// normalizes the path, so reads by the main tools count as well.
 	if requireRead {
-		if !afs.session.WasFileRead(cleanPath) {
+		if !afs.session.WasFileRead(path) {
 			return fmt.Errorf("access denied: file %s was not read in this session (read-before-write rule)", path)
 		}
 	}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestAuthorizedFSAllowsWriteAfterMainToolRead(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workingDir, "src"), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workingDir, "src", "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	filesystem := fs.NewCachedFS(workingDir, time.Minute, 100)
+	sess := session.NewSession("test", workingDir)
+
+	// The main tool reads the file under a different spelling of the path
+	if result := NewReadFileTool(filesystem, sess).Execute(ctx, map[string]interface{}{"path": "./src/main.go"}); result.Error != "" {
+		t.Fatalf("read_file failed: %s", result.Error)
+	}
+
+	sandboxFS := NewAuthorizedFS(filesystem, sess, workingDir)
+	if err := sandboxFS.WriteFile(ctx, "src/main.go", []byte("package main\n\nfunc main() {}\n")); err != nil {
+		t.Fatalf("expected the sandbox write to be allowed after the read, got %v", err)
+	}
+
+	// An absolute path inside the working directory names the same file
+	if !sess.WasFileRead(filepath.Join(workingDir, "src", "main.go")) {
+		t.Error("expected the absolute path to count as read")
+	}
+	if modified := sess.GetModifiedFiles(); len(modified) != 1 || modified[0] != filepath.Join("src", "main.go") {
+		t.Errorf("expected one modified file src/main.go, got %v", modified)
+	}
+}
+
+func TestAuthorizedFSBlocksWriteWithoutRead(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workingDir, "config.yaml"), []byte("key: value\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	filesystem := fs.NewCachedFS(workingDir, time.Minute, 100)
+	sess := session.NewSession("test", workingDir)
+
+	err := NewAuthorizedFS(filesystem, sess, sess.WorkingDir).WriteFile(ctx, "config.yaml", []byte("key: other\n"))
+	if err == nil || !strings.Contains(err.Error(), "read-before-write") {
+		t.Fatalf("expected the unread file to be blocked, got %v", err)
+	}
+	if data, _ := filesystem.ReadFile(ctx, "config.yaml"); string(data) != "key: value\n" {
+		t.Errorf("expected the file to be unchanged, got %q", data)
+	}
+
+	// A file the sandbox read may then be changed by the diff tools
+	if _, err := NewAuthorizedFS(filesystem, sess, sess.WorkingDir).ReadFile(ctx, "./config.yaml"); err != nil {
+		t.Fatalf("sandbox read failed: %v", err)
+	}
+	if !sess.WasFileRead("config.yaml") {
+		t.Error("expected the sandbox read to be visible to the main tools")
+	}
+}
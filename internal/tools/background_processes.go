@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// ErrProcessNotOwned is returned when terminating a PID that does not belong
+// to a running background job started by this server
+var ErrProcessNotOwned = errors.New("process was not started by a background job of this server")
+
+// BackgroundProcess is a running process of a background job
+type BackgroundProcess struct {
+	PID            int       `json:"pid"`
+	ProcessGroupID int       `json:"process_group_id,omitempty"`
+	JobID          string    `json:"job_id"`
+	SessionID      string    `json:"session_id,omitempty"`
+	Command        string    `json:"command"`
+	WorkingDir     string    `json:"working_dir,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// ProcessTable lists the processes of running background jobs and terminates
+// them. Only processes the table tracks can be terminated, never arbitrary PIDs.
+type ProcessTable interface {
+	ListProcesses() []BackgroundProcess
+	TerminateProcess(pid int, signal string) (*BackgroundProcess, error)
+}
+
+// SessionProcessTable is the ProcessTable of the background jobs tracked by
+// sessions
+type SessionProcessTable struct {
+	sessions func() []*session.Session
+	signal   func(job *session.BackgroundJob, sig syscall.Signal, signalName string) error
+}
+
+// NewSessionProcessTable creates a process table over the sessions returned by
+// sessions, which is called on every lookup so newly loaded sessions are seen
+func NewSessionProcessTable(sessions func() []*session.Session) *SessionProcessTable {
+	return &SessionProcessTable{
+		sessions: sessions,
+		signal:   sendSignalToBackgroundJob,
+	}
+}
+
+// ListProcesses returns the processes of running jobs, oldest first
+func (t *SessionProcessTable) ListProcesses() []BackgroundProcess {
+	var processes []BackgroundProcess
+	t.eachRunningJob(func(sess *session.Session, job *session.BackgroundJob) bool {
+		processes = append(processes, backgroundProcessFromJob(sess, job))
+		return true
+	})
+
+	sort.Slice(processes, func(i, j int) bool {
+		if !processes[i].StartedAt.Equal(processes[j].StartedAt) {
+			return processes[i].StartedAt.Before(processes[j].StartedAt)
+		}
+		return processes[i].PID < processes[j].PID
+	})
+	return processes
+}
+
+// TerminateProcess sends SIGTERM (default) or SIGKILL to the process of a
+// running job. PIDs of other processes are rejected with ErrProcessNotOwned.
+func (t *SessionProcessTable) TerminateProcess(pid int, signal string) (*BackgroundProcess, error) {
+	sig, signalName, err := parseStopSignal(signal)
+	if err != nil {
+		return nil, err
+	}
+	if pid <= 0 {
+		return nil, fmt.Errorf("invalid pid: %d", pid)
+	}
+
+	var (
+		owner *session.Session
+		match *session.BackgroundJob
+	)
+	t.eachRunningJob(func(sess *session.Session, job *session.BackgroundJob) bool {
+		job.Mu.RLock()
+		jobPID := job.PID
+		job.Mu.RUnlock()
+		if jobPID == pid {
+			owner, match = sess, job
+			return false
+		}
+		return true
+	})
+	if match == nil {
+		return nil, fmt.Errorf("%w: %d", ErrProcessNotOwned, pid)
+	}
+
+	if err := t.signal(match, sig, signalName); err != nil {
+		return nil, err
+	}
+
+	match.Mu.Lock()
+	match.StopRequested = true
+	match.LastSignal = signalName
+	match.Mu.Unlock()
+
+	logger.Info("background processes: sent %s to pid %d (job %s)", signalName, pid, match.ID)
+	process := backgroundProcessFromJob(owner, match)
+	return &process, nil
+}
+
+// eachRunningJob calls fn for every job with a process that has not completed,
+// until fn returns false
+func (t *SessionProcessTable) eachRunningJob(fn func(sess *session.Session, job *session.BackgroundJob) bool) {
+	if t.sessions == nil {
+		return
+	}
+	for _, sess := range t.sessions() {
+		if sess == nil {
+			continue
+		}
+		for _, job := range sess.ListBackgroundJobs() {
+			job.Mu.RLock()
+			pid := job.PID
+			job.Mu.RUnlock()
+			if pid <= 0 || backgroundJobCompleted(job) {
+				continue
+			}
+			if !fn(sess, job) {
+				return
+			}
+		}
+	}
+}
+
+// backgroundJobCompleted reports whether a job has finished. Jobs started by
+// the shell actor only signal completion through their Done channel.
+func backgroundJobCompleted(job *session.BackgroundJob) bool {
+	job.Mu.RLock()
+	completed := job.Completed
+	done := job.Done
+	job.Mu.RUnlock()
+
+	if completed {
+		return true
+	}
+	if done == nil {
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+func backgroundProcessFromJob(sess *session.Session, job *session.BackgroundJob) BackgroundProcess {
+	job.Mu.RLock()
+	defer job.Mu.RUnlock()
+	return BackgroundProcess{
+		PID:            job.PID,
+		ProcessGroupID: job.ProcessGroupID,
+		JobID:          job.ID,
+		SessionID:      sess.ID,
+		Command:        job.Command,
+		WorkingDir:     job.WorkingDir,
+		StartedAt:      job.StartTime,
+	}
+}
+
+// parseStopSignal parses the signal names accepted for stopping jobs
+func parseStopSignal(input string) (syscall.Signal, string, error) {
+	switch strings.ToUpper(strings.TrimSpace(input)) {
+	case "", "TERM", "SIGTERM":
+		return syscall.SIGTERM, "SIGTERM", nil
+	case "KILL", "SIGKILL":
+		return syscall.SIGKILL, "SIGKILL", nil
+	default:
+		return 0, "", fmt.Errorf("unsupported signal: %s", input)
+	}
+}
+
+// BackgroundProcessesToolSpec is the static specification for the
+// background_processes tool
+type BackgroundProcessesToolSpec struct{}
+
+func (s *BackgroundProcessesToolSpec) Name() string {
+	return ToolNameBackgroundProcesses
+}
+
+func (s *BackgroundProcessesToolSpec) Description() string {
+	return `List the running processes of background jobs with their PIDs and commands, or terminate one by PID.
+Use it to clean up leftover dev servers or watchers. Only processes started by background jobs can be terminated.`
+}
+
+func (s *BackgroundProcessesToolSpec) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: 'list' or 'kill'",
+				"enum":        []string{"list", "kill"},
+			},
+			"pid": map[string]interface{}{
+				"type":        "integer",
+				"description": "PID of the process to terminate (required for 'kill')",
+			},
+			"signal": map[string]interface{}{
+				"type":        "string",
+				"description": "Signal to send (SIGTERM or SIGKILL). Defaults to SIGTERM.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// BackgroundProcessesTool is the executor with runtime dependencies
+type BackgroundProcessesTool struct {
+	table ProcessTable
+}
+
+func NewBackgroundProcessesTool(table ProcessTable) *BackgroundProcessesTool {
+	return &BackgroundProcessesTool{table: table}
+}
+
+// Legacy interface implementation for backward compatibility
+func (t *BackgroundProcessesTool) Name() string { return ToolNameBackgroundProcesses }
+func (t *BackgroundProcessesTool) Description() string {
+	return (&BackgroundProcessesToolSpec{}).Description()
+}
+func (t *BackgroundProcessesTool) Parameters() map[string]interface{} {
+	return (&BackgroundProcessesToolSpec{}).Parameters()
+}
+
+func (t *BackgroundProcessesTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
+	switch action := GetStringParam(params, "action", ""); action {
+	case "list":
+		processes := t.table.ListProcesses()
+		return &ToolResult{Result: map[string]interface{}{
+			"processes": processes,
+			"count":     len(processes),
+		}}
+	case "kill":
+		pid := GetIntParam(params, "pid", 0)
+		if pid <= 0 {
+			return &ToolResult{Error: "pid is required for kill"}
+		}
+		_, signalName, err := parseStopSignal(GetStringParam(params, "signal", ""))
+		if err != nil {
+			return &ToolResult{Error: err.Error()}
+		}
+		process, err := t.table.TerminateProcess(pid, signalName)
+		if err != nil {
+			return &ToolResult{Error: err.Error()}
+		}
+		return &ToolResult{Result: map[string]interface{}{
+			"pid":     process.PID,
+			"job_id":  process.JobID,
+			"command": process.Command,
+			"signal":  signalName,
+			"message": fmt.Sprintf("Signal %s sent to process %d.", signalName, process.PID),
+		}}
+	case "":
+		return &ToolResult{Error: "action is required"}
+	default:
+		return &ToolResult{Error: fmt.Sprintf("unknown action %q (expected list or kill)", action)}
+	}
+}
+
+// NewBackgroundProcessesToolFactory creates a factory for BackgroundProcessesTool
+func NewBackgroundProcessesToolFactory(table ProcessTable) ToolFactory {
+	return func(reg *Registry) ToolExecutor {
+		return NewBackgroundProcessesTool(table)
+	}
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+// stubProcessTable is a ProcessTable over a fixed list of processes that
+// records terminations instead of sending signals
+type stubProcessTable struct {
+	processes  []BackgroundProcess
+	terminated map[int]string
+}
+
+func (s *stubProcessTable) ListProcesses() []BackgroundProcess {
+	return s.processes
+}
+
+func (s *stubProcessTable) TerminateProcess(pid int, signal string) (*BackgroundProcess, error) {
+	for _, process := range s.processes {
+		if process.PID == pid {
+			s.terminated[pid] = signal
+			return &process, nil
+		}
+	}
+	return nil, ErrProcessNotOwned
+}
+
+func TestBackgroundProcessesTool_ListAndKill(t *testing.T) {
+	table := &stubProcessTable{
+		processes: []BackgroundProcess{
+			{PID: 101, JobID: "job_1", Command: "npm run dev"},
+			{PID: 102, JobID: "job_2", Command: "go run ./cmd/server"},
+		},
+		terminated: make(map[int]string),
+	}
+	tool := NewBackgroundProcessesTool(table)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{"action": "list"})
+	if result.Error != "" {
+		t.Fatalf("list failed: %s", result.Error)
+	}
+	data := result.Result.(map[string]interface{})
+	if data["count"] != 2 {
+		t.Fatalf("expected 2 processes, got %v", data["count"])
+	}
+
+	result = tool.Execute(context.Background(), map[string]interface{}{"action": "kill", "pid": float64(102), "signal": "kill"})
+	if result.Error != "" {
+		t.Fatalf("kill failed: %s", result.Error)
+	}
+	if table.terminated[102] != "SIGKILL" {
+		t.Fatalf("expected SIGKILL to be sent to 102, got %v", table.terminated)
+	}
+
+	result = tool.Execute(context.Background(), map[string]interface{}{"action": "kill", "pid": float64(999)})
+	if result.Error == "" {
+		t.Fatal("expected kill of an unknown pid to fail")
+	}
+	if _, ok := table.terminated[999]; ok {
+		t.Fatal("unknown pid must not be terminated")
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"action": "kill"},
+		{"action": "kill", "pid": float64(101), "signal": "SIGHUP"},
+		{"action": "restart"},
+		{},
+	} {
+		if result := tool.Execute(context.Background(), params); result.Error == "" {
+			t.Errorf("expected %v to fail", params)
+		}
+	}
+	if _, ok := table.terminated[101]; ok {
+		t.Fatal("unsupported signal must not terminate the process")
+	}
+}
+
+func TestSessionProcessTable_OnlyTargetsOwnedJobs(t *testing.T) {
+	first := session.NewSession("first", t.TempDir())
+	second := session.NewSession("second", t.TempDir())
+
+	start := time.Now()
+	first.AddBackgroundJob(&session.BackgroundJob{ID: "dev", Command: "npm run dev", PID: 4001, StartTime: start})
+	first.AddBackgroundJob(&session.BackgroundJob{ID: "done", Command: "make", PID: 4002, StartTime: start, Completed: true})
+	first.AddBackgroundJob(&session.BackgroundJob{ID: "nopid", Command: "sleep 1", StartTime: start})
+	finished := make(chan struct{})
+	close(finished)
+	second.AddBackgroundJob(&session.BackgroundJob{ID: "actor-done", Command: "true", PID: 4003, StartTime: start, Done: finished})
+	second.AddBackgroundJob(&session.BackgroundJob{ID: "watch", Command: "go test -watch", PID: 4004, StartTime: start.Add(time.Second), Done: make(chan struct{})})
+
+	table := NewSessionProcessTable(func() []*session.Session { return []*session.Session{first, second} })
+	signalled := make(map[string]string)
+	table.signal = func(job *session.BackgroundJob, _ syscall.Signal, signalName string) error {
+		signalled[job.ID] = signalName
+		return nil
+	}
+
+	processes := table.ListProcesses()
+	if len(processes) != 2 {
+		t.Fatalf("expected 2 running processes, got %+v", processes)
+	}
+	if processes[0].PID != 4001 || processes[0].SessionID != "first" || processes[1].PID != 4004 || processes[1].SessionID != "second" {
+		t.Fatalf("unexpected processes: %+v", processes)
+	}
+
+	// Untracked PIDs and PIDs of finished jobs are never signalled
+	for _, pid := range []int{1, 4002, 4003, 99999} {
+		if _, err := table.TerminateProcess(pid, "SIGKILL"); !errors.Is(err, ErrProcessNotOwned) {
+			t.Errorf("expected ErrProcessNotOwned for pid %d, got %v", pid, err)
+		}
+	}
+	if len(signalled) != 0 {
+		t.Fatalf("expected no signals, got %v", signalled)
+	}
+
+	process, err := table.TerminateProcess(4004, "")
+	if err != nil {
+		t.Fatalf("TerminateProcess failed: %v", err)
+	}
+	if process.JobID != "watch" || signalled["watch"] != "SIGTERM" || len(signalled) != 1 {
+		t.Fatalf("expected SIGTERM for job watch only, got %+v %v", process, signalled)
+	}
+
+	job, _ := second.GetBackgroundJob("watch")
+	if !job.StopRequested || job.LastSignal != "SIGTERM" {
+		t.Errorf("expected job to be marked as stopping, got stop_requested=%v signal=%q", job.StopRequested, job.LastSignal)
+	}
+}
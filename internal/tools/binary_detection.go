@@ -1,8 +1,12 @@
 package tools
 
 import (
+	"bytes"
+	"fmt"
+	"net/http"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 )
 
 // Known binary extensions where reading raw bytes is not useful to the LLM.
@@ -18,27 +22,101 @@ var binaryExtensions = map[string]struct{}{
 	".wasm":  {},
 }
 
+const (
+	// binarySniffLen is the number of leading bytes inspected by hasBinaryContent
+	binarySniffLen = 8192
+	// maxSuspiciousByteRatio is the share of invalid UTF-8 and control bytes
+	// above which content is treated as binary. Text with a few stray bytes in
+	// a legacy encoding stays well below it.
+	maxSuspiciousByteRatio = 0.3
+)
+
 func isBinaryExtension(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	_, ok := binaryExtensions[ext]
 	return ok
 }
 
-// hasBinaryContent uses a simple heuristic (presence of NUL bytes in the first 512 bytes)
-// to determine if the data is likely binary.
+// hasBinaryContent reports whether the data is likely binary: it contains a
+// NUL byte in the first 512 bytes, or too many of its leading bytes are
+// invalid UTF-8 or control characters.
 func hasBinaryContent(data []byte) bool {
 	checkLen := len(data)
 	if checkLen > 512 {
 		checkLen = 512
 	}
-	for i := 0; i < checkLen; i++ {
-		if data[i] == 0 {
-			return true
+	if bytes.IndexByte(data[:checkLen], 0) >= 0 {
+		return true
+	}
+
+	sample := data
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if len(sample) == 0 {
+		return false
+	}
+
+	suspicious := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		if r == utf8.RuneError && size == 1 {
+			// A rune cut off by the sample limit is not suspicious
+			if len(sample) < len(data) && len(sample)-i < utf8.UTFMax && !utf8.FullRune(sample[i:]) {
+				break
+			}
+			suspicious++
+		} else if r < 0x20 && r != '\n' && r != '\r' && r != '\t' && r != '\f' && r != '\b' && r != 0x1b {
+			suspicious++
 		}
+		i += size
 	}
-	return false
+	return float64(suspicious)/float64(len(sample)) > maxSuspiciousByteRatio
 }
 
 func isLikelyBinaryFile(path string, data []byte) bool {
 	return isBinaryExtension(path) || hasBinaryContent(data)
 }
+
+// detectBinaryType returns a short description of the format of binary data
+func detectBinaryType(path string, data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return "ELF executable"
+	case bytes.HasPrefix(data, []byte("\x00asm")):
+		return "WebAssembly module"
+	case bytes.HasPrefix(data, []byte("MZ")):
+		return "Windows executable"
+	case bytes.HasPrefix(data, []byte{0xcf, 0xfa, 0xed, 0xfe}), bytes.HasPrefix(data, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xca, 0xfe, 0xba, 0xbe}):
+		return "Mach-O binary"
+	case bytes.HasPrefix(data, []byte("!<arch>\n")):
+		return "static library archive"
+	}
+
+	if contentType := http.DetectContentType(data); contentType != "application/octet-stream" && !strings.HasPrefix(contentType, "text/") {
+		return contentType
+	}
+	if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); ext != "" {
+		return ext + " file"
+	}
+	return "unknown"
+}
+
+// binaryFileResult is returned by the read tools instead of the raw content
+// of a binary file, which would only confuse the model
+func binaryFileResult(path string, data []byte) *ToolResult {
+	fileType := detectBinaryType(path, data)
+	notice := fmt.Sprintf("binary file, %d bytes, type %s: %s. Its content is not shown; to inspect the bytes, use %s with ReadFile and encode them with encoding/base64 or encoding/hex.",
+		len(data), fileType, path, ToolNameGoSandbox)
+	return &ToolResult{
+		Result: map[string]interface{}{
+			"path":    path,
+			"binary":  true,
+			"size":    len(data),
+			"type":    fileType,
+			"message": notice,
+		},
+		UIResult: notice,
+	}
+}
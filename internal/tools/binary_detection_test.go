@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestHasBinaryContent(t *testing.T) {
+	random := make([]byte, 256)
+	for i := range random {
+		random[i] = byte(0x80 + i%0x7f)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"ascii", []byte("package main\n\nfunc main() {}\n"), false},
+		{"utf-8", []byte("// Grüße, 世界 🌍\nfmt.Println(\"ok\")\n"), false},
+		{"stray latin-1 bytes", []byte("name = M\xfcller\ncity = K\xf6ln\ncomment = plain ascii text around the stray bytes\n"), false},
+		{"nul byte", []byte("abc\x00def"), true},
+		{"invalid utf-8", random, true},
+		{"control characters", []byte("\x01\x02\x03\x04\x05\x06\x07\x0e\x0f\x10ab"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasBinaryContent(tt.data); got != tt.want {
+				t.Errorf("hasBinaryContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasBinaryContentIgnoresRuneCutAtSampleLimit(t *testing.T) {
+	data := []byte(strings.Repeat("世", binarySniffLen/3+1))
+	if hasBinaryContent(data) {
+		t.Error("expected multi-byte text crossing the sample limit to be text")
+	}
+}
+
+func TestDetectBinaryType(t *testing.T) {
+	tests := []struct {
+		path string
+		data []byte
+		want string
+	}{
+		{"lib.so", []byte{0x7f, 'E', 'L', 'F', 0x02, 0x01}, "ELF executable"},
+		{"mod.wasm", []byte("\x00asm\x01\x00\x00\x00"), "WebAssembly module"},
+		{"logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png"},
+		{"data.bin", []byte{0x00, 0x01, 0x02}, "bin file"},
+		{"blob", []byte{0x00, 0x01, 0x02}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := detectBinaryType(tt.path, tt.data); got != tt.want {
+			t.Errorf("detectBinaryType(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReadToolsReturnBinaryNotice(t *testing.T) {
+	mockFS := fs.NewMockFS()
+	ctx := context.Background()
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x10")
+	_ = mockFS.WriteFile(ctx, "logo.png", png)
+	_ = mockFS.WriteFile(ctx, "latin1.txt", []byte("name = M\xfcller\nok\n"))
+
+	executors := map[string]ToolExecutor{
+		"read_file":          NewReadFileTool(mockFS, session.NewSession("test", ".")),
+		"read_file_numbered": NewReadFileNumberedExecutor(mockFS, session.NewSession("test", ".")),
+	}
+	for name, executor := range executors {
+		t.Run(name, func(t *testing.T) {
+			for _, params := range []map[string]interface{}{
+				{"path": "logo.png"},
+				{"path": "logo.png", "sections": []interface{}{map[string]interface{}{"from_line": 1, "to_line": 2}}},
+			} {
+				result := executor.Execute(ctx, params)
+				resultMap, ok := result.Result.(map[string]interface{})
+				if result.Error != "" || !ok || resultMap["binary"] != true {
+					t.Fatalf("expected binary notice, got %+v", result)
+				}
+				if resultMap["size"] != len(png) || resultMap["type"] != "image/png" {
+					t.Errorf("unexpected binary details: %v", resultMap)
+				}
+				if !strings.Contains(result.UIResult.(string), "binary file, 20 bytes, type image/png") {
+					t.Errorf("unexpected notice: %v", result.UIResult)
+				}
+			}
+
+			result := executor.Execute(ctx, map[string]interface{}{"path": "latin1.txt"})
+			resultMap, ok := result.Result.(map[string]interface{})
+			if result.Error != "" || !ok || resultMap["binary"] != nil {
+				t.Fatalf("expected text with stray high bytes to be read, got %+v", result)
+			}
+			if content, _ := resultMap["content"].(string); !strings.Contains(content, "ok") {
+				t.Errorf("expected file content, got %q", content)
+			}
+		})
+	}
+}
@@ -119,14 +119,16 @@ func (t *CreateFileTool) Execute(ctx context.Context, params map[string]interfac
 	}
 
 	// Generate UI result with validation warning if present
-	uiResult := generateGitDiff(path, "", content)
+	diffText := generateGitDiff(path, "", content)
+	uiResult := diffText
 	if validationWarning != "" {
 		uiResult = fmt.Sprintf("%s\n\n⚠️  **Syntax Validation**\n%s", uiResult, validationWarning)
 	}
 
 	return &ToolResult{
-		Result:   resultMap,
-		UIResult: uiResult,
+		Result:            resultMap,
+		UIResult:          uiResult,
+		ExecutionMetadata: diffMetadata(diffText),
 	}
 }
 
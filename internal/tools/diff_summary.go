@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+// DiffFileSummary counts the changes of one file in a unified diff
+type DiffFileSummary struct {
+	OldPath   string `json:"old_path,omitempty"` // empty for created files
+	NewPath   string `json:"new_path,omitempty"` // empty for deleted files
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Hunks     int    `json:"hunks"`
+	Created   bool   `json:"created,omitempty"`
+	Deleted   bool   `json:"deleted,omitempty"`
+	Renamed   bool   `json:"renamed,omitempty"`
+}
+
+// Path returns the path of the file after the change, or before it if the
+// file was deleted
+func (f DiffFileSummary) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// DiffSummary is the structured form of a write tool's unified diff, so
+// consumers don't have to parse the diff text again
+type DiffSummary struct {
+	FilesChanged int               `json:"files_changed"`
+	Additions    int               `json:"additions"`
+	Deletions    int               `json:"deletions"`
+	Hunks        int               `json:"hunks"`
+	Files        []DiffFileSummary `json:"files,omitempty"`
+}
+
+// SummarizeDiff counts the files, hunks, added and deleted lines of a
+// (multi-file) unified diff
+func SummarizeDiff(diffText string) (*DiffSummary, error) {
+	fileDiffs, err := diff.ParseMultiFileDiff([]byte(diffText))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diff: %w", err)
+	}
+
+	summary := &DiffSummary{}
+	for _, fd := range fileDiffs {
+		if fd == nil {
+			continue
+		}
+		file := DiffFileSummary{
+			OldPath: diffSummaryPath(fd.OrigName),
+			NewPath: diffSummaryPath(fd.NewName),
+			Hunks:   len(fd.Hunks),
+		}
+		file.Created = file.OldPath == "" && file.NewPath != ""
+		file.Deleted = file.NewPath == "" && file.OldPath != ""
+		file.Renamed = !file.Created && !file.Deleted && file.OldPath != file.NewPath
+		for _, header := range fd.Extended {
+			if strings.HasPrefix(header, "rename from ") {
+				file.Renamed = true
+			}
+		}
+
+		for _, hunk := range fd.Hunks {
+			for _, line := range bytes.Split(hunk.Body, []byte("\n")) {
+				if len(line) == 0 {
+					continue
+				}
+				switch line[0] {
+				case '+':
+					file.Additions++
+				case '-':
+					file.Deletions++
+				}
+			}
+		}
+
+		summary.FilesChanged++
+		summary.Additions += file.Additions
+		summary.Deletions += file.Deletions
+		summary.Hunks += file.Hunks
+		summary.Files = append(summary.Files, file)
+	}
+	return summary, nil
+}
+
+// diffSummaryPath strips the a/ and b/ prefixes of a diff file name; the
+// /dev/null of created and deleted files becomes empty
+func diffSummaryPath(name string) string {
+	name = strings.Trim(strings.TrimSpace(name), "\"")
+	if name == "" || name == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		return name[2:]
+	}
+	return name
+}
+
+// diffMetadata returns the execution metadata of a write tool result whose
+// UI result is diffText. A diff that cannot be parsed yields nil.
+func diffMetadata(diffText string) *ExecutionMetadata {
+	summary, err := SummarizeDiff(diffText)
+	if err != nil || summary.FilesChanged == 0 {
+		return nil
+	}
+	return &ExecutionMetadata{ToolType: "file_write", Diff: summary}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+const summaryTestDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,5 @@
+ package main
+-import "fmt"
++import (
++	"fmt"
++)
+ func main() {}
+@@ -10,3 +11,3 @@ func helper() {
+-	return 1
++	return 2
+ }
+diff --git a/old_name.go b/new_name.go
+similarity index 90%
+rename from old_name.go
+rename to new_name.go
+index 3333333..4444444 100644
+--- a/old_name.go
++++ b/new_name.go
+@@ -1,2 +1,2 @@
+ package main
+-var x = 1
++var x = 2
+diff --git a/pure_old.go b/pure_new.go
+similarity index 100%
+rename from pure_old.go
+rename to pure_new.go
+diff --git a/added.go b/added.go
+new file mode 100644
+--- /dev/null
++++ b/added.go
+@@ -0,0 +1,2 @@
++package main
++// ---- not a header
+diff --git a/removed.go b/removed.go
+deleted file mode 100644
+--- a/removed.go
++++ /dev/null
+@@ -1,1 +0,0 @@
+-package main
+`
+
+func TestSummarizeDiff(t *testing.T) {
+	summary, err := SummarizeDiff(summaryTestDiff)
+	if err != nil {
+		t.Fatalf("SummarizeDiff failed: %v", err)
+	}
+
+	if summary.FilesChanged != 5 || summary.Additions != 7 || summary.Deletions != 4 || summary.Hunks != 5 {
+		t.Errorf("unexpected totals: %+v", summary)
+	}
+
+	want := []DiffFileSummary{
+		{OldPath: "main.go", NewPath: "main.go", Additions: 4, Deletions: 2, Hunks: 2},
+		{OldPath: "old_name.go", NewPath: "new_name.go", Additions: 1, Deletions: 1, Hunks: 1, Renamed: true},
+		{OldPath: "pure_old.go", NewPath: "pure_new.go", Renamed: true},
+		{NewPath: "added.go", Additions: 2, Hunks: 1, Created: true},
+		{OldPath: "removed.go", Deletions: 1, Hunks: 1, Deleted: true},
+	}
+	if len(summary.Files) != len(want) {
+		t.Fatalf("expected %d files, got %+v", len(want), summary.Files)
+	}
+	for i, file := range summary.Files {
+		if file != want[i] {
+			t.Errorf("file %d: expected %+v, got %+v", i, want[i], file)
+		}
+	}
+	if path := summary.Files[4].Path(); path != "removed.go" {
+		t.Errorf("expected a deleted file to report its old path, got %q", path)
+	}
+}
+
+func TestDiffMetadataWithoutDiff(t *testing.T) {
+	if metadata := diffMetadata("not a diff"); metadata != nil {
+		t.Errorf("expected no metadata without file diffs, got %+v", metadata)
+	}
+}
+
+func TestWriteToolsReportDiffMetadata(t *testing.T) {
+	mockFS := fs.NewMockFS()
+	sess := session.NewSession("test", "/workspace")
+
+	result := NewCreateFileTool(mockFS, sess).Execute(context.Background(), map[string]interface{}{
+		"path":    "notes.txt",
+		"content": "one\ntwo\nthree",
+	})
+	if result.Error != "" {
+		t.Fatalf("create_file failed: %s", result.Error)
+	}
+	if result.ExecutionMetadata == nil || result.ExecutionMetadata.Diff == nil {
+		t.Fatalf("expected diff metadata, got %+v", result.ExecutionMetadata)
+	}
+	diff := result.ExecutionMetadata.Diff
+	if diff.FilesChanged != 1 || diff.Additions != 3 || diff.Deletions != 0 || !diff.Files[0].Created {
+		t.Errorf("unexpected diff summary for a created file: %+v", diff)
+	}
+}
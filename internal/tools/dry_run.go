@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dryRunNotice is appended to simulated results so the model does not assume
+// the change is visible on disk afterwards
+const dryRunNotice = "Dry run: nothing was changed. Continue as if the change had been applied, but do not expect to see it when reading files."
+
+// IsMutatingToolCall reports whether a tool call may change the filesystem or
+// start processes. In dry-run mode such calls are simulated instead of
+// executed; read-only calls run normally.
+func IsMutatingToolCall(toolName string, params map[string]interface{}) bool {
+	switch toolName {
+	case ToolNameCreateFile, ToolNameReplaceFile, ToolNameEditFile, ToolNameGoSandbox, ToolNameStopProgram, ToolNameFetchDocs:
+		return true
+	case ToolNameShell, ToolNameCommand:
+		return !isLikelyReadOnlyCommand(GetStringParam(params, "command", ""))
+	case ToolNameMemory:
+		return GetStringParam(params, "operation", "") == "append"
+	case ToolNameReplaceInFiles:
+		return !GetBoolParam(params, "preview", false)
+	case ToolNameBackgroundProcesses:
+		return GetStringParam(params, "action", "") == "kill"
+	default:
+		// MCP tools run arbitrary commands and services whose side effects
+		// are unknown
+		return strings.HasPrefix(toolName, "mcp_")
+	}
+}
+
+// isLikelyReadOnlyCommand reports whether a shell command only inspects the
+// workspace. Anything with chaining, redirection or substitution is treated as
+// having side effects.
+func isLikelyReadOnlyCommand(command string) bool {
+	trimmed := strings.TrimSpace(command)
+	if trimmed == "" || strings.ContainsAny(trimmed, "|;&><`") || strings.Contains(trimmed, "$(") {
+		return false
+	}
+
+	fields := strings.Fields(trimmed)
+	switch fields[0] {
+	case "ls", "cat", "head", "tail", "wc", "pwd", "grep", "rg", "tree", "stat", "file", "which", "du", "df", "diff":
+		return true
+	case "find":
+		for _, field := range fields[1:] {
+			switch field {
+			case "-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprintf", "-fls":
+				return false
+			}
+		}
+		return true
+	case "git":
+		if len(fields) < 2 {
+			return false
+		}
+		switch fields[1] {
+		case "status", "log", "diff", "show", "blame", "ls-files", "rev-parse":
+			return true
+		}
+		return false
+	case "go":
+		return len(fields) >= 2 && (fields[1] == "vet" || fields[1] == "list" || fields[1] == "version" || fields[1] == "env")
+	default:
+		return false
+	}
+}
+
+// SimulateToolCall returns the dry-run result of a mutating tool call,
+// describing what the call would have done
+func SimulateToolCall(call *ToolCall) *ToolResult {
+	params := call.Parameters
+	path := GetStringParam(params, "path", "")
+	result := map[string]interface{}{
+		"dry_run": true,
+		"note":    dryRunNotice,
+	}
+
+	var summary string
+	var uiResult interface{}
+	switch call.Name {
+	case ToolNameCreateFile, ToolNameReplaceFile:
+		content := GetStringParam(params, "content", "")
+		verb := "create"
+		if call.Name == ToolNameReplaceFile {
+			verb = "replace the content of"
+		}
+		result["path"] = path
+		result["bytes"] = len(content)
+		result["lines"] = countLines(content)
+		summary = fmt.Sprintf("Would %s %s (%d bytes)", verb, path, len(content))
+		if call.Name == ToolNameCreateFile {
+			uiResult = fmt.Sprintf("[dry run] %s\n\n%s", summary, generateGitDiff(path, "", content))
+		}
+	case ToolNameEditFile:
+		result["path"] = path
+		for _, key := range []string{"diff", "edits", "operations", "old_string", "new_string"} {
+			if value, ok := params[key]; ok {
+				result[key] = value
+			}
+		}
+		summary = fmt.Sprintf("Would edit %s", path)
+	case ToolNameShell, ToolNameCommand:
+		command := GetStringParam(params, "command", "")
+		result["command"] = command
+		summary = fmt.Sprintf("Would run: %s", command)
+	case ToolNameGoSandbox:
+		code := GetStringParam(params, "code", "")
+		result["code_bytes"] = len(code)
+		summary = fmt.Sprintf("Would run a go_sandbox program (%d bytes of code)", len(code))
+	case ToolNameReplaceInFiles:
+		for _, key := range []string{"search", "replacement", "files", "regex"} {
+			if value, ok := params[key]; ok {
+				result[key] = value
+			}
+		}
+		summary = fmt.Sprintf("Would replace %q with %q in files matching %s",
+			GetStringParam(params, "search", ""), GetStringParam(params, "replacement", ""), GetStringParam(params, "files", ""))
+	case ToolNameMemory:
+		content := GetStringParam(params, "content", "")
+		result["content"] = content
+		summary = fmt.Sprintf("Would append a note (%d bytes) to the project memory", len(content))
+	case ToolNameStopProgram:
+		jobID := GetStringParam(params, "job_id", "")
+		result["job_id"] = jobID
+		summary = fmt.Sprintf("Would stop background job %s", jobID)
+	case ToolNameFetchDocs:
+		source := GetStringParam(params, "url", "")
+		if source == "" {
+			source = GetStringParam(params, "package", "")
+		}
+		for _, key := range []string{"url", "package", "ecosystem", "name"} {
+			if value, ok := params[key]; ok {
+				result[key] = value
+			}
+		}
+		summary = fmt.Sprintf("Would download the documentation of %s into the documentation context directory", source)
+	case ToolNameBackgroundProcesses:
+		pid := GetIntParam(params, "pid", 0)
+		result["pid"] = pid
+		summary = fmt.Sprintf("Would terminate background process %d", pid)
+	default:
+		summary = fmt.Sprintf("Would call %s", call.Name)
+	}
+
+	result["summary"] = summary
+	if uiResult == nil {
+		uiResult = "[dry run] " + summary
+	}
+	return &ToolResult{
+		ID:       call.ID,
+		Result:   result,
+		UIResult: uiResult,
+	}
+}
+
+// countLines returns the number of lines in content
+func countLines(content string) int {
+	if content == "" {
+		return 0
+	}
+	lines := strings.Count(content, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		lines++
+	}
+	return lines
+}
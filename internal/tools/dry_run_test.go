@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestDryRunCreateFileDoesNotWrite(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filesystem := fs.NewCachedFS(dir, time.Second, 10)
+	sess := session.NewSession("test", dir)
+
+	registry := NewRegistry(nil)
+	registry.SetDryRun(true)
+	registry.Register(NewCreateFileTool(filesystem, sess))
+
+	result := registry.Execute(ctx, &ToolCall{
+		ID:   "call-1",
+		Name: ToolNameCreateFile,
+		Parameters: map[string]interface{}{
+			"path":    "hello.txt",
+			"content": "Hello\nworld\n",
+		},
+	})
+	if result.Error != "" {
+		t.Fatalf("expected simulated success, got error: %s", result.Error)
+	}
+	if result.ID != "call-1" {
+		t.Errorf("expected result ID call-1, got %q", result.ID)
+	}
+
+	resultMap, ok := result.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map result, got %T", result.Result)
+	}
+	if resultMap["dry_run"] != true || resultMap["path"] != "hello.txt" || resultMap["bytes"] != 12 || resultMap["lines"] != 2 {
+		t.Errorf("unexpected dry-run result: %+v", resultMap)
+	}
+	if ui, _ := result.UIResult.(string); !strings.Contains(ui, "+Hello") {
+		t.Errorf("expected UI result to show the intended content, got %q", result.UIResult)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "hello.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file on disk in dry-run mode, stat error: %v", err)
+	}
+	if len(sess.GetModifiedFiles()) != 0 {
+		t.Errorf("expected no tracked file modifications, got %v", sess.GetModifiedFiles())
+	}
+}
+
+func TestDryRunReadToolsExecute(t *testing.T) {
+	ctx := context.Background()
+	mockFS := fs.NewMockFS()
+	if err := mockFS.WriteFile(ctx, "main.go", []byte("package main\n")); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	registry := NewRegistry(nil)
+	registry.SetDryRun(true)
+	registry.Register(NewReadFileTool(mockFS, session.NewSession("test", ".")))
+
+	result := registry.Execute(ctx, &ToolCall{
+		ID:         "call-1",
+		Name:       ToolNameReadFile,
+		Parameters: map[string]interface{}{"path": "main.go"},
+	})
+	if result.Error != "" {
+		t.Fatalf("read_file failed in dry-run mode: %s", result.Error)
+	}
+	if resultMap, ok := result.Result.(map[string]interface{}); ok && resultMap["dry_run"] == true {
+		t.Fatal("read_file must not be simulated")
+	}
+}
+
+func TestIsMutatingToolCall(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   string
+		params map[string]interface{}
+		want   bool
+	}{
+		{"create file", ToolNameCreateFile, map[string]interface{}{"path": "a.txt"}, true},
+		{"edit file", ToolNameEditFile, map[string]interface{}{"path": "a.txt"}, true},
+		{"sandbox", ToolNameGoSandbox, map[string]interface{}{"code": "package main"}, true},
+		{"read file", ToolNameReadFile, map[string]interface{}{"path": "a.txt"}, false},
+		{"search", ToolNameSearchFiles, nil, false},
+		{"shell ls", ToolNameShell, map[string]interface{}{"command": "ls -la"}, false},
+		{"shell git status", ToolNameShell, map[string]interface{}{"command": "git status"}, false},
+		{"shell rm", ToolNameShell, map[string]interface{}{"command": "rm -rf build"}, true},
+		{"shell redirect", ToolNameShell, map[string]interface{}{"command": "cat a > b"}, true},
+		{"shell find delete", ToolNameShell, map[string]interface{}{"command": "find . -name '*.o' -delete"}, true},
+		{"shell git commit", ToolNameShell, map[string]interface{}{"command": "git commit -m x"}, true},
+		{"fetch docs", ToolNameFetchDocs, map[string]interface{}{"package": "github.com/spf13/cobra"}, true},
+		{"mcp tool", "mcp_deploy_run", map[string]interface{}{"target": "prod"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMutatingToolCall(tt.tool, tt.params); got != tt.want {
+				t.Errorf("IsMutatingToolCall(%s, %v) = %v, want %v", tt.tool, tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizationNotesDryRun(t *testing.T) {
+	ctx := context.Background()
+	actor := NewAuthorizationActor("auth", fs.NewMockFS(), session.NewSession("test", "."), nil, &AuthorizationOptions{DryRun: true})
+
+	decision, err := actor.authorize(ctx, ToolNameCreateFile, map[string]interface{}{"path": "new.txt"})
+	if err != nil {
+		t.Fatalf("authorize returned error: %v", err)
+	}
+	if !decision.Allowed || !strings.Contains(decision.Reason, "Dry run") {
+		t.Fatalf("expected allowed decision noting dry run, got %+v", decision)
+	}
+
+	decision, err = actor.authorize(ctx, ToolNameReadFile, map[string]interface{}{"path": "new.txt"})
+	if err != nil {
+		t.Fatalf("authorize returned error: %v", err)
+	}
+	if strings.Contains(decision.Reason, "Dry run") {
+		t.Fatalf("read-only calls should not mention dry run, got %q", decision.Reason)
+	}
+}
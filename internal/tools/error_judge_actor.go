@@ -10,6 +10,7 @@ import (
 	"github.com/codefionn/scriptschnell/internal/consts"
 	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/prompttemplate"
 )
 
 // MIN_SLEEP_SECONDS is the minimum sleep duration for retries
@@ -40,8 +41,9 @@ func (m *ErrorJudgeMessage) Type() string {
 
 // ErrorJudgeActor analyzes LLM errors and decides whether to retry
 type ErrorJudgeActor struct {
-	id        string
-	llmClient llm.Client
+	id             string
+	llmClient      llm.Client
+	promptTemplate string
 }
 
 // NewErrorJudgeActor creates a new error judge actor
@@ -52,6 +54,12 @@ func NewErrorJudgeActor(id string, llmClient llm.Client) *ErrorJudgeActor {
 	}
 }
 
+// SetPromptTemplate sets the text/template of the judge prompt; an empty
+// template selects the built-in prompt
+func (a *ErrorJudgeActor) SetPromptTemplate(text string) {
+	a.promptTemplate = text
+}
+
 // ID implements actor.Actor interface
 func (a *ErrorJudgeActor) ID() string {
 	return a.id
@@ -122,33 +130,18 @@ func (a *ErrorJudgeActor) llmJudge(ctx context.Context, msg *ErrorJudgeMessage)
 }
 
 func (a *ErrorJudgeActor) buildErrorJudgePrompt(msg *ErrorJudgeMessage) string {
-	var sb strings.Builder
-
-	sb.WriteString("You are an error recovery judge for an LLM-powered application.\n")
-	sb.WriteString("Analyze the error and decide whether to retry the request or halt.\n\n")
-
-	sb.WriteString("Your response must be in this exact format:\n")
-	sb.WriteString("DECISION: RETRY or HALT\n")
-	sb.WriteString("SLEEP_SECONDS: <number>\n")
-	sb.WriteString("TRIGGER_COMPACTION: YES or NO\n")
-	sb.WriteString("REASON: <brief explanation>\n\n")
-
-	sb.WriteString("Guidelines:\n")
-	sb.WriteString("- Rate limit errors: RETRY with exponential backoff (5s, 15s, 30s, 60s, 120, 240s, 360s)\n")
-	sb.WriteString("- Temporary service errors (500, 503, timeout): RETRY with moderate delays (2s, 5s, 10s, 20s, 30s, 240s, 360s)\n")
-	sb.WriteString("- Network errors: RETRY with short delays (1s, 3s, 5s)\n")
-	sb.WriteString("- Token/context limit errors (context_length_exceeded, max tokens, prompt too long, input too long): RETRY with TRIGGER_COMPACTION=YES\n")
-	sb.WriteString("- Authentication errors: HALT (invalid credentials)\n")
-	sb.WriteString("- Invalid request/parameter errors: HALT (bad input)\n")
-	sb.WriteString("- Unknown errors after 3+ attempts: HALT (prevent infinite loops)\n\n")
-
-	fmt.Fprintf(&sb, "Current attempt: %d of %d\n", msg.AttemptNumber, msg.MaxAttempts)
-	fmt.Fprintf(&sb, "Model: %s\n", msg.ModelID)
-	fmt.Fprintf(&sb, "Error: %v\n\n", msg.Error)
-
-	sb.WriteString("Analyze this error and provide your decision in the exact format above.")
-
-	return sb.String()
+	data := prompttemplate.ErrorJudgeData{
+		Attempt:     msg.AttemptNumber,
+		MaxAttempts: msg.MaxAttempts,
+		Model:       msg.ModelID,
+		Error:       fmt.Sprint(msg.Error),
+	}
+	prompt, err := prompttemplate.RenderErrorJudge(a.promptTemplate, data)
+	if err != nil {
+		logger.Warn("Error judge template failed, using default: %v", err)
+		prompt, _ = prompttemplate.RenderErrorJudge("", data)
+	}
+	return prompt
 }
 
 func (a *ErrorJudgeActor) parseDecision(response string, msg *ErrorJudgeMessage) (ErrorJudgeDecision, error) {
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -530,3 +531,23 @@ func TestErrorJudgeActor_Receive_InvalidMessage(t *testing.T) {
 		t.Error("Expected error for invalid message type")
 	}
 }
+
+func TestBuildErrorJudgePrompt_CustomTemplate(t *testing.T) {
+	actor := NewErrorJudgeActor("test", nil)
+	msg := &ErrorJudgeMessage{
+		Error:         errors.New("429 Too Many Requests"),
+		AttemptNumber: 2,
+		MaxAttempts:   5,
+		ModelID:       "gpt-4o",
+	}
+
+	if prompt := actor.buildErrorJudgePrompt(msg); !strings.Contains(prompt, "Current attempt: 2 of 5") || !strings.Contains(prompt, "Error: 429 Too Many Requests") {
+		t.Fatalf("unexpected default prompt: %q", prompt)
+	}
+
+	actor.SetPromptTemplate("Model {{.Model}} failed ({{.Attempt}}/{{.MaxAttempts}}): {{.Error}}")
+	want := "Model gpt-4o failed (2/5): 429 Too Many Requests"
+	if prompt := actor.buildErrorJudgePrompt(msg); prompt != want {
+		t.Fatalf("expected %q, got %q", want, prompt)
+	}
+}
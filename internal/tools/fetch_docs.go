@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/consts"
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/htmlconv"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+const (
+	fetchDocsMaxBytes    = 2 << 20 // 2 MiB per document
+	fetchDocsMaxNameLen  = 100
+	fetchDocsDefaultName = "index"
+)
+
+// docsPackageURLs maps package ecosystems to their documentation sites
+var docsPackageURLs = map[string]string{
+	"go":     "https://pkg.go.dev/%s",
+	"npm":    "https://www.npmjs.com/package/%s",
+	"pypi":   "https://pypi.org/project/%s/",
+	"crates": "https://docs.rs/%s/latest/",
+}
+
+// FetchDocsToolSpec is the static specification for the fetch_docs tool
+type FetchDocsToolSpec struct{}
+
+func (s *FetchDocsToolSpec) Name() string {
+	return ToolNameFetchDocs
+}
+
+func (s *FetchDocsToolSpec) Description() string {
+	return "Download documentation from a URL or for a package (Go, npm, PyPI, crates) into the documentation context directory, converting HTML to Markdown. The directory is added to the workspace's context directories, so the saved file can then be found with search_context_files and read with read_context_file. The domain must be authorized."
+}
+
+func (s *FetchDocsToolSpec) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"url": map[string]interface{}{
+				"type":        "string",
+				"description": "URL of the documentation page (http or https). Either url or package is required.",
+			},
+			"package": map[string]interface{}{
+				"type":        "string",
+				"description": "Package name whose documentation to fetch, e.g. 'github.com/spf13/cobra' or 'requests'",
+			},
+			"ecosystem": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"go", "npm", "pypi", "crates"},
+				"description": "Package ecosystem for package (default: go)",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional subdirectory to store the documentation in (default: the package name or the URL's host)",
+			},
+		},
+	}
+}
+
+// FetchDocsTool downloads documentation into a context directory
+type FetchDocsTool struct {
+	client     *http.Client
+	fs         fs.FileSystem
+	config     *config.Config
+	session    *session.Session
+	authorizer Authorizer
+	docsDir    string
+}
+
+// NewFetchDocsTool constructs a FetchDocsTool storing documents below docsDir
+func NewFetchDocsTool(client *http.Client, filesystem fs.FileSystem, cfg *config.Config, sess *session.Session, authorizer Authorizer, docsDir string) *FetchDocsTool {
+	if client == nil {
+		client = &http.Client{Timeout: consts.Timeout30}
+	}
+	return &FetchDocsTool{
+		client:     client,
+		fs:         filesystem,
+		config:     cfg,
+		session:    sess,
+		authorizer: authorizer,
+		docsDir:    docsDir,
+	}
+}
+
+// NewFetchDocsToolFactory creates a factory for FetchDocsTool
+func NewFetchDocsToolFactory(client *http.Client, filesystem fs.FileSystem, cfg *config.Config, sess *session.Session, authorizer Authorizer, docsDir string) ToolFactory {
+	return func(reg *Registry) ToolExecutor {
+		return NewFetchDocsTool(client, filesystem, cfg, sess, authorizer, docsDir)
+	}
+}
+
+func (t *FetchDocsTool) Name() string        { return ToolNameFetchDocs }
+func (t *FetchDocsTool) Description() string { return (&FetchDocsToolSpec{}).Description() }
+func (t *FetchDocsTool) Parameters() map[string]interface{} {
+	return (&FetchDocsToolSpec{}).Parameters()
+}
+
+func (t *FetchDocsTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
+	reqURL, err := fetchDocsURL(params)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
+	if t.authorizer != nil {
+		decision, authErr := t.authorizer.Authorize(ctx, ToolNameFetchDocs, map[string]interface{}{
+			"url": reqURL.String(),
+		})
+		if authErr != nil {
+			return &ToolResult{Error: fmt.Sprintf("authorization error: %v", authErr)}
+		}
+		if decision != nil && !decision.Allowed {
+			if decision.RequiresUserInput {
+				return &ToolResult{
+					RequiresUserInput: true,
+					AuthReason:        decision.Reason,
+				}
+			}
+			return &ToolResult{Error: decision.Reason}
+		}
+	}
+
+	if t.docsDir == "" {
+		return &ToolResult{Error: "no documentation directory configured"}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return &ToolResult{Error: fmt.Sprintf("failed to build request: %v", err)}
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return &ToolResult{Error: fmt.Sprintf("request failed: %v", err)}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &ToolResult{Error: fmt.Sprintf("GET %s returned status %d", reqURL, resp.StatusCode)}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, fetchDocsMaxBytes+1))
+	if err != nil {
+		return &ToolResult{Error: fmt.Sprintf("failed to read response: %v", err)}
+	}
+	if len(data) > fetchDocsMaxBytes {
+		return &ToolResult{Error: fmt.Sprintf("documentation exceeds the size limit of %d bytes", fetchDocsMaxBytes)}
+	}
+	if hasBinaryContent(data) {
+		return &ToolResult{Error: fmt.Sprintf("%s is not a text document", reqURL)}
+	}
+
+	content := string(data)
+	converted := false
+	if markdown, ok := htmlconv.ConvertIfHTML(content); ok {
+		content = markdown
+		converted = true
+	}
+
+	dir := fetchDocsTargetDir(t.docsDir, params, reqURL)
+	target := filepath.Join(dir, fetchDocsFileName(reqURL, converted))
+	if err := t.fs.MkdirAll(ctx, dir, 0o755); err != nil {
+		return &ToolResult{Error: fmt.Sprintf("failed to create %s: %v", dir, err)}
+	}
+	if err := t.fs.WriteFile(ctx, target, []byte(content)); err != nil {
+		return &ToolResult{Error: fmt.Sprintf("failed to write %s: %v", target, err)}
+	}
+
+	// Register the documentation directory for this workspace
+	workspace := t.session.WorkingDir
+	if workspace == "" {
+		workspace = "."
+	}
+	registered := false
+	if t.config != nil {
+		registered = true
+		for _, existing := range t.config.GetContextDirectories(workspace) {
+			if existing == t.docsDir {
+				registered = false
+				break
+			}
+		}
+		t.config.AddContextDirectory(workspace, t.docsDir)
+	}
+
+	var result strings.Builder
+	result.WriteString("## Documentation Saved\n\n")
+	fmt.Fprintf(&result, "**Source:** %s\n", reqURL)
+	fmt.Fprintf(&result, "**File:** `%s` (%d bytes)\n", target, len(content))
+	if registered {
+		fmt.Fprintf(&result, "\nAdded `%s` to the context directories.", t.docsDir)
+	}
+	result.WriteString("\nUse search_context_files and read_context_file to look up the documentation.")
+
+	return &ToolResult{
+		Result:   result.String(),
+		UIResult: fmt.Sprintf("GET %s → %s", reqURL, target),
+	}
+}
+
+// FetchDocsTargetDir returns the directory below docsDir a fetch_docs call
+// saves its document to, or "" if the call has no valid source
+func FetchDocsTargetDir(docsDir string, params map[string]interface{}) string {
+	reqURL, err := fetchDocsURL(params)
+	if err != nil {
+		return ""
+	}
+	return fetchDocsTargetDir(docsDir, params, reqURL)
+}
+
+func fetchDocsTargetDir(docsDir string, params map[string]interface{}, reqURL *url.URL) string {
+	return filepath.Join(docsDir, sanitizeDocsName(fetchDocsDirName(params, reqURL)))
+}
+
+// fetchDocsURL returns the documentation URL of a fetch_docs call
+func fetchDocsURL(params map[string]interface{}) (*url.URL, error) {
+	if rawURL := strings.TrimSpace(GetStringParam(params, "url", "")); rawURL != "" {
+		parsed, err := normalizeFetchURL(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+		return parsed, nil
+	}
+
+	pkg := strings.TrimSpace(GetStringParam(params, "package", ""))
+	if pkg == "" {
+		return nil, fmt.Errorf("url or package is required")
+	}
+	ecosystem := strings.ToLower(strings.TrimSpace(GetStringParam(params, "ecosystem", "go")))
+	pattern, ok := docsPackageURLs[ecosystem]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ecosystem %q (use go, npm, pypi or crates)", ecosystem)
+	}
+	return normalizeFetchURL(fmt.Sprintf(pattern, pkg))
+}
+
+// fetchDocsDirName returns the unsanitized subdirectory of a fetch_docs call
+func fetchDocsDirName(params map[string]interface{}, reqURL *url.URL) string {
+	if name := strings.TrimSpace(GetStringParam(params, "name", "")); name != "" {
+		return name
+	}
+	if pkg := strings.TrimSpace(GetStringParam(params, "package", "")); pkg != "" && GetStringParam(params, "url", "") == "" {
+		return pkg
+	}
+	return reqURL.Hostname()
+}
+
+// fetchDocsFileName derives the file name of a document from its URL path.
+// Converted HTML is stored as Markdown; other documents keep a plain
+// extension or get .txt.
+func fetchDocsFileName(reqURL *url.URL, converted bool) string {
+	name := strings.Trim(reqURL.Path, "/")
+	ext := path.Ext(name)
+	if converted || safeDocsExt.MatchString(ext) {
+		name = strings.TrimSuffix(name, ext)
+	}
+	switch {
+	case converted:
+		ext = ".md"
+	case !safeDocsExt.MatchString(ext):
+		ext = ".txt"
+	}
+	return sanitizeDocsName(name) + strings.ToLower(ext)
+}
+
+var (
+	unsafeDocsNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	safeDocsExt         = regexp.MustCompile(`^\.[A-Za-z0-9]{1,10}$`)
+)
+
+// sanitizeDocsName turns a URL or package name into a single safe path
+// component: separators and other special characters become dashes, leading
+// dots are removed and the length is capped
+func sanitizeDocsName(name string) string {
+	name = unsafeDocsNameChars.ReplaceAllString(name, "-")
+	name = strings.Trim(name, ".-")
+	if len(name) > fetchDocsMaxNameLen {
+		name = strings.TrimRight(name[:fetchDocsMaxNameLen], ".-")
+	}
+	if name == "" {
+		return fetchDocsDefaultName
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+type denyingAuthorizer struct{}
+
+func (d *denyingAuthorizer) Authorize(ctx context.Context, toolName string, params map[string]interface{}) (*AuthorizationDecision, error) {
+	return &AuthorizationDecision{Allowed: false, Reason: "domain not authorized", RequiresUserInput: true}, nil
+}
+
+func newDocsServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/guide/intro.html", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><head><title>Intro</title></head><body><main><h1>Getting Started</h1><p>Call Parse to read a config.</p></main></body></html>"))
+	})
+	mux.HandleFunc("/huge.txt", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(strings.Repeat("a", fetchDocsMaxBytes+1)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newFetchDocsTestTool(t *testing.T, authorizer Authorizer) (*FetchDocsTool, *config.Config, string) {
+	t.Helper()
+	docsDir := filepath.Join(t.TempDir(), "docs")
+	cfg := config.DefaultConfig()
+	sess := session.NewSession("test", "/workspace")
+	filesystem := fs.NewCachedFS(t.TempDir(), time.Second, 10)
+	return NewFetchDocsTool(nil, filesystem, cfg, sess, authorizer, docsDir), cfg, docsDir
+}
+
+func TestFetchDocsToolSavesIntoContextDir(t *testing.T) {
+	server, _ := newDocsServer(t)
+	tool, cfg, docsDir := newFetchDocsTestTool(t, &mockAuthorizer{})
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"url":  server.URL + "/guide/intro.html",
+		"name": "parser",
+	})
+	if result.Error != "" {
+		t.Fatalf("fetch_docs failed: %s", result.Error)
+	}
+
+	target := filepath.Join(docsDir, "parser", "guide-intro.md")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("expected the documentation at %s: %v", target, err)
+	}
+	if !strings.Contains(string(data), "Getting Started") || strings.Contains(string(data), "<h1>") {
+		t.Errorf("expected the HTML to be converted to Markdown, got %q", data)
+	}
+
+	dirs := cfg.GetContextDirectories("/workspace")
+	if len(dirs) != 1 || dirs[0] != docsDir {
+		t.Fatalf("expected %s to be registered as context directory, got %v", docsDir, dirs)
+	}
+
+	search := NewSearchContextFilesTool(tool.fs, cfg, tool.session)
+	found := search.Execute(context.Background(), map[string]interface{}{"pattern": "**/*.md"})
+	if text, _ := found.Result.(string); !strings.Contains(text, "guide-intro.md") {
+		t.Errorf("expected search_context_files to find the documentation, got %+v", found)
+	}
+}
+
+func TestFetchDocsToolRejectsOversizedDocuments(t *testing.T) {
+	server, _ := newDocsServer(t)
+	tool, _, docsDir := newFetchDocsTestTool(t, &mockAuthorizer{})
+
+	result := tool.Execute(context.Background(), map[string]interface{}{"url": server.URL + "/huge.txt"})
+	if !strings.Contains(result.Error, "size limit") {
+		t.Fatalf("expected a size limit error, got %+v", result)
+	}
+	if _, err := os.Stat(docsDir); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be written, stat returned %v", err)
+	}
+}
+
+func TestFetchDocsToolRequiresAuthorization(t *testing.T) {
+	server, requests := newDocsServer(t)
+	tool, cfg, _ := newFetchDocsTestTool(t, &denyingAuthorizer{})
+
+	result := tool.Execute(context.Background(), map[string]interface{}{"url": server.URL + "/guide/intro.html"})
+	if !result.RequiresUserInput {
+		t.Fatalf("expected the fetch to require authorization, got %+v", result)
+	}
+	if atomic.LoadInt32(requests) != 0 {
+		t.Errorf("expected no request before authorization, got %d", atomic.LoadInt32(requests))
+	}
+	if dirs := cfg.GetContextDirectories("/workspace"); len(dirs) != 0 {
+		t.Errorf("expected no context directory, got %v", dirs)
+	}
+}
+
+func TestFetchDocsNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		url    string
+		dir    string
+	}{
+		{"go package", map[string]interface{}{"package": "github.com/spf13/cobra"}, "https://pkg.go.dev/github.com/spf13/cobra", "github.com-spf13-cobra"},
+		{"pypi package", map[string]interface{}{"package": "requests", "ecosystem": "pypi"}, "https://pypi.org/project/requests/", "requests"},
+		{"url host", map[string]interface{}{"url": "https://docs.example.com/api"}, "https://docs.example.com/api", "docs.example.com"},
+		{"path traversal", map[string]interface{}{"url": "https://example.com/", "name": "../../etc"}, "https://example.com/", "etc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqURL, err := fetchDocsURL(tt.params)
+			if err != nil {
+				t.Fatalf("fetchDocsURL failed: %v", err)
+			}
+			if reqURL.String() != tt.url {
+				t.Errorf("url = %s, want %s", reqURL, tt.url)
+			}
+			if dir := sanitizeDocsName(fetchDocsDirName(tt.params, reqURL)); dir != tt.dir {
+				t.Errorf("dir = %q, want %q", dir, tt.dir)
+			}
+		})
+	}
+
+	if _, err := fetchDocsURL(map[string]interface{}{"package": "x", "ecosystem": "cpan"}); err == nil {
+		t.Error("expected an unsupported ecosystem to be rejected")
+	}
+	if name := sanitizeDocsName(strings.Repeat("x", 300)); len(name) != fetchDocsMaxNameLen {
+		t.Errorf("expected names to be capped at %d characters, got %d", fetchDocsMaxNameLen, len(name))
+	}
+}
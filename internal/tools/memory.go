@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+const (
+	// maxMemoryEntryBytes caps a single appended note
+	maxMemoryEntryBytes = 4 * 1024
+	// maxMemorySearchResults caps the notes returned by a search
+	maxMemorySearchResults = 20
+)
+
+// memoryFileMu serializes appends to memory files within this process
+var memoryFileMu sync.Mutex
+
+// MemoryToolSpec is the static specification for the memory tool
+type MemoryToolSpec struct{}
+
+func (s *MemoryToolSpec) Name() string {
+	return ToolNameMemory
+}
+
+func (s *MemoryToolSpec) Description() string {
+	return fmt.Sprintf(`Persistent project notes shared across sessions, stored in %s.
+Use it for decisions, conventions and gotchas that future sessions should know; not for conversation history or temporary state.
+Operations:
+- read: return all notes
+- append: add a note (requires "content")
+- search: return notes containing "query" (case-insensitive)`, llm.ProjectMemoryFileName)
+}
+
+func (s *MemoryToolSpec) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"read", "append", "search"},
+				"description": "The memory operation to perform",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Note to append (markdown, for append)",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to search for (for search)",
+			},
+		},
+		"required": []string{"operation"},
+	}
+}
+
+// MemoryTool reads and appends notes in the workspace's memory file
+type MemoryTool struct {
+	fs         fs.FileSystem
+	workingDir string
+	now        func() time.Time
+}
+
+func NewMemoryTool(filesystem fs.FileSystem, workingDir string) *MemoryTool {
+	return &MemoryTool{
+		fs:         filesystem,
+		workingDir: workingDir,
+		now:        time.Now,
+	}
+}
+
+// Legacy interface implementation for backward compatibility
+func (t *MemoryTool) Name() string        { return ToolNameMemory }
+func (t *MemoryTool) Description() string { return (&MemoryToolSpec{}).Description() }
+func (t *MemoryTool) Parameters() map[string]interface{} {
+	return (&MemoryToolSpec{}).Parameters()
+}
+
+func (t *MemoryTool) memoryPath() string {
+	return filepath.Join(t.workingDir, llm.ProjectMemoryFileName)
+}
+
+func (t *MemoryTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
+	switch operation := GetStringParam(params, "operation", ""); operation {
+	case "read":
+		return t.read(ctx)
+	case "append":
+		return t.append(ctx, GetStringParam(params, "content", ""))
+	case "search":
+		return t.search(ctx, GetStringParam(params, "query", ""))
+	case "":
+		return &ToolResult{Error: "operation is required (read, append or search)"}
+	default:
+		return &ToolResult{Error: fmt.Sprintf("unknown operation %q (expected read, append or search)", operation)}
+	}
+}
+
+// load returns the memory file content, or "" if there are no notes yet
+func (t *MemoryTool) load(ctx context.Context) (string, error) {
+	exists, err := t.fs.Exists(ctx, t.memoryPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to check memory file: %w", err)
+	}
+	if !exists {
+		return "", nil
+	}
+
+	data, err := t.fs.ReadFile(ctx, t.memoryPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to read memory file: %w", err)
+	}
+	return string(data), nil
+}
+
+func (t *MemoryTool) read(ctx context.Context) *ToolResult {
+	memory, err := t.load(ctx)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+	if strings.TrimSpace(memory) == "" {
+		return &ToolResult{Result: "No project notes saved yet."}
+	}
+	return &ToolResult{Result: memory}
+}
+
+func (t *MemoryTool) append(ctx context.Context, content string) *ToolResult {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return &ToolResult{Error: "content is required for append"}
+	}
+	if len(content) > maxMemoryEntryBytes {
+		return &ToolResult{Error: fmt.Sprintf("note is too long (%d bytes, max %d); keep notes short", len(content), maxMemoryEntryBytes)}
+	}
+
+	memoryFileMu.Lock()
+	defer memoryFileMu.Unlock()
+
+	memory, err := t.load(ctx)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
+	if memory == "" {
+		memory = "# Project Memory\n"
+	}
+	if !strings.HasSuffix(memory, "\n") {
+		memory += "\n"
+	}
+	memory += fmt.Sprintf("\n## %s\n\n%s\n", t.now().Format("2006-01-02 15:04"), content)
+
+	if err := t.fs.MkdirAll(ctx, filepath.Dir(t.memoryPath()), 0o755); err != nil {
+		return &ToolResult{Error: fmt.Sprintf("failed to create memory directory: %v", err)}
+	}
+	if err := t.fs.WriteFile(ctx, t.memoryPath(), []byte(memory)); err != nil {
+		return &ToolResult{Error: fmt.Sprintf("failed to write memory file: %v", err)}
+	}
+
+	return &ToolResult{Result: fmt.Sprintf("Note saved to %s", llm.ProjectMemoryFileName)}
+}
+
+func (t *MemoryTool) search(ctx context.Context, query string) *ToolResult {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return &ToolResult{Error: "query is required for search"}
+	}
+
+	memory, err := t.load(ctx)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
+	needle := strings.ToLower(query)
+	var matches []string
+	for _, note := range splitMemoryNotes(memory) {
+		if strings.Contains(strings.ToLower(note), needle) {
+			matches = append(matches, note)
+		}
+	}
+
+	if len(matches) == 0 {
+		return &ToolResult{Result: fmt.Sprintf("No notes matching %q.", query)}
+	}
+
+	truncated := len(matches) > maxMemorySearchResults
+	if truncated {
+		matches = matches[len(matches)-maxMemorySearchResults:]
+	}
+	result := strings.Join(matches, "\n\n")
+	if truncated {
+		result = fmt.Sprintf("[Showing the %d most recent matching notes]\n\n%s", maxMemorySearchResults, result)
+	}
+	return &ToolResult{Result: result}
+}
+
+// splitMemoryNotes splits the memory file into notes, one per "## " section.
+// Text before the first section (other than the title) counts as a note.
+func splitMemoryNotes(memory string) []string {
+	var notes []string
+	var current strings.Builder
+	flush := func() {
+		if note := strings.TrimSpace(current.String()); note != "" {
+			notes = append(notes, note)
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.Split(memory, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			flush()
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+	return notes
+}
+
+// NewMemoryToolFactory creates a factory for MemoryTool
+func NewMemoryToolFactory(filesystem fs.FileSystem, workingDir string) ToolFactory {
+	return func(reg *Registry) ToolExecutor {
+		return NewMemoryTool(filesystem, workingDir)
+	}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/llm"
+)
+
+func TestMemoryToolAppendReadRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	mockFS := fs.NewMockFS()
+	tool := NewMemoryTool(mockFS, "/workspace")
+	tool.now = func() time.Time { return time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC) }
+
+	result := tool.Execute(ctx, map[string]interface{}{"operation": "read"})
+	if result.Error != "" || result.Result != "No project notes saved yet." {
+		t.Fatalf("expected empty memory, got %+v", result)
+	}
+
+	for _, note := range []string{"Use sqlc for queries, not an ORM.", "Integration tests need DOCKER_HOST set."} {
+		result = tool.Execute(ctx, map[string]interface{}{"operation": "append", "content": note})
+		if result.Error != "" {
+			t.Fatalf("append failed: %s", result.Error)
+		}
+	}
+
+	data, err := mockFS.ReadFile(ctx, filepath.Join("/workspace", llm.ProjectMemoryFileName))
+	if err != nil {
+		t.Fatalf("memory file not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Project Memory\n") || strings.Count(string(data), "## 2024-03-01 09:30") != 2 {
+		t.Errorf("unexpected memory file:\n%s", data)
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"operation": "read"})
+	content, _ := result.Result.(string)
+	if !strings.Contains(content, "Use sqlc for queries") || !strings.Contains(content, "DOCKER_HOST") {
+		t.Fatalf("expected both notes, got %q", content)
+	}
+
+	// A new tool instance (e.g. a later session) sees the same notes
+	result = NewMemoryTool(mockFS, "/workspace").Execute(ctx, map[string]interface{}{"operation": "search", "query": "docker_host"})
+	content, _ = result.Result.(string)
+	if !strings.Contains(content, "DOCKER_HOST") || strings.Contains(content, "sqlc") {
+		t.Fatalf("expected only the matching note, got %q", content)
+	}
+}
+
+func TestMemoryToolValidation(t *testing.T) {
+	ctx := context.Background()
+	tool := NewMemoryTool(fs.NewMockFS(), "/workspace")
+
+	tests := []map[string]interface{}{
+		{},
+		{"operation": "delete"},
+		{"operation": "append"},
+		{"operation": "append", "content": strings.Repeat("x", maxMemoryEntryBytes+1)},
+		{"operation": "search"},
+	}
+	for _, params := range tests {
+		if result := tool.Execute(ctx, params); result.Error == "" {
+			t.Errorf("expected error for %v", params)
+		}
+	}
+}
+
+func TestAuthorizeMemoryAppendRequiresApproval(t *testing.T) {
+	authorizer := NewAuthorizationActor("test", fs.NewMockFS(), nil, nil, nil)
+
+	decision, err := authorizer.authorizeTool(context.Background(), ToolNameMemory, map[string]interface{}{"operation": "append", "content": "Use tabs"})
+	if err != nil {
+		t.Fatalf("authorize failed: %v", err)
+	}
+	if decision.Allowed || !decision.RequiresUserInput || !strings.Contains(decision.Reason, "Use tabs") {
+		t.Errorf("expected appending a note to require approval, got %+v", decision)
+	}
+
+	for _, operation := range []string{"read", "search"} {
+		decision, _ = authorizer.authorizeTool(context.Background(), ToolNameMemory, map[string]interface{}{"operation": operation, "query": "tabs"})
+		if !decision.Allowed {
+			t.Errorf("expected %s to be allowed, got %+v", operation, decision)
+		}
+	}
+
+	authorizer = NewAuthorizationActor("test", fs.NewMockFS(), nil, nil, &AuthorizationOptions{AllowedDirs: []string{filepath.Dir(llm.ProjectMemoryFileName)}})
+	decision, _ = authorizer.authorizeTool(context.Background(), ToolNameMemory, map[string]interface{}{"operation": "append", "content": "Use tabs"})
+	if !decision.Allowed {
+		t.Errorf("expected a pre-authorized memory directory to allow appending, got %+v", decision)
+	}
+}
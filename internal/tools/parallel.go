@@ -7,17 +7,27 @@ import (
 	"sync"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/fs"
 	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/session"
 )
 
 type ParallelTool struct {
 	registry *Registry
+	fs       fs.FileSystem    // Restores files of failed transactional batches
+	session  *session.Session // Tracks the files restored by a rollback
 }
 
 func NewParallelTool(registry *Registry) *ParallelTool {
 	return &ParallelTool{registry: registry}
 }
 
+// NewParallelToolWithFS creates a parallel tool that supports transactional
+// batches of file edits
+func NewParallelToolWithFS(registry *Registry, filesystem fs.FileSystem, sess *session.Session) *ParallelTool {
+	return &ParallelTool{registry: registry, fs: filesystem, session: sess}
+}
+
 func (t *ParallelTool) Name() string {
 	return ToolNameParallel
 }
@@ -30,7 +40,10 @@ func (t *ParallelTool) Description() string {
 - Mix operations (combine read_file and search operations in one parallel call)
 - Edit multiple files at once
 - Investigate different parts of the codebase simultaneously with codebase investigator
-Each tool runs independently and results are collected when all complete.`
+Each tool runs independently and results are collected when all complete.
+The result includes a summary of which calls succeeded and which failed. Set transactional to true
+for batches of file edits that must be applied completely or not at all: if any call fails, the
+changes of the successful calls are rolled back.`
 }
 
 func (t *ParallelTool) Parameters() map[string]interface{} {
@@ -56,27 +69,31 @@ func (t *ParallelTool) Parameters() map[string]interface{} {
 					"required": []string{"name"},
 				},
 			},
+			"transactional": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Roll back the file changes of all calls if any call fails (default: false). Only file edits (create_file, replace_file, edit_file) and read-only tools are allowed in a transactional batch.",
+			},
 		},
 		"required": []string{"tool_calls"},
 	}
 }
 
 func (t *ParallelTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
+	return t.ExecuteWithCallbacks(ctx, params, nil, nil, nil)
+}
+
+// ExecuteWithCallbacks implements the callback-aware execution interface
+func (t *ParallelTool) ExecuteWithCallbacks(ctx context.Context, params map[string]interface{}, progressCb progress.Callback, toolCallCb func(string, string, map[string]interface{}) error, toolResultCb func(string, string, string, string) error) *ToolResult {
 	if t.registry == nil {
 		return &ToolResult{Error: "parallel tool registry is not configured"}
 	}
 
-	rawCalls, ok := params["tool_calls"]
-	if !ok {
-		return &ToolResult{Error: "tool_calls is required"}
+	parsedCalls, err := parseParallelCalls(params)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
 	}
 
-	callSlice, ok := rawCalls.([]interface{})
-	if !ok {
-		return &ToolResult{Error: "tool_calls must be an array"}
-	}
-
-	if len(callSlice) == 0 {
+	if len(parsedCalls) == 0 {
 		return &ToolResult{
 			Result: map[string]interface{}{
 				"results":     []map[string]interface{}{},
@@ -85,38 +102,37 @@ func (t *ParallelTool) Execute(ctx context.Context, params map[string]interface{
 		}
 	}
 
-	type callSpec struct {
-		index  int
-		name   string
-		params map[string]interface{}
-	}
-
-	parsedCalls := make([]callSpec, 0, len(callSlice))
-	for i, raw := range callSlice {
-		callMap, ok := raw.(map[string]interface{})
-		if !ok {
-			return &ToolResult{Error: fmt.Sprintf("tool_calls[%d] must be an object", i)}
+	transactional := GetBoolParam(params, "transactional", false)
+	var snapshot *batchSnapshot
+	if transactional {
+		if snapshot, err = t.snapshotBatch(ctx, parsedCalls); err != nil {
+			return &ToolResult{Error: err.Error()}
 		}
+	}
 
-		nameVal, ok := callMap["name"].(string)
-		if !ok || nameVal == "" {
-			return &ToolResult{Error: fmt.Sprintf("tool_calls[%d].name must be a non-empty string", i)}
+	// Send initial progress message showing all tools being executed
+	sendStream := func(msg string) {
+		if err := progress.Dispatch(progressCb, progress.Update{
+			Message: msg,
+			Mode:    progress.ReportNoStatus,
+		}); err != nil {
+			// Ignore progress dispatch errors to avoid interrupting parallel execution
+			return
 		}
+	}
 
-		paramsVal := map[string]interface{}{}
-		if rawParams, exists := callMap["parameters"]; exists && rawParams != nil {
-			castParams, ok := rawParams.(map[string]interface{})
-			if !ok {
-				return &ToolResult{Error: fmt.Sprintf("tool_calls[%d].parameters must be an object", i)}
+	// Show which tools are being executed in parallel
+	if progressCb != nil {
+		toolNames := make([]string, len(parsedCalls))
+		for i, call := range parsedCalls {
+			details := extractParallelToolDetails(call.name, call.params)
+			if details != "" {
+				toolNames[i] = fmt.Sprintf("%s(%s)", call.name, details)
+			} else {
+				toolNames[i] = call.name
 			}
-			paramsVal = castParams
 		}
-
-		parsedCalls = append(parsedCalls, callSpec{
-			index:  i,
-			name:   nameVal,
-			params: paramsVal,
-		})
+		sendStream(fmt.Sprintf("→ **parallel_tools** [%d]: %s\n", len(toolNames), joinToolNames(toolNames)))
 	}
 
 	totalStart := time.Now()
@@ -126,7 +142,7 @@ func (t *ParallelTool) Execute(ctx context.Context, params map[string]interface{
 
 	for _, call := range parsedCalls {
 		wg.Add(1)
-		go func(call callSpec) {
+		go func(call parallelCall) {
 			defer wg.Done()
 			start := time.Now()
 
@@ -170,151 +186,65 @@ func (t *ParallelTool) Execute(ctx context.Context, params map[string]interface{
 
 	elapsed := time.Since(totalStart).Milliseconds()
 
+	var rolledBack []string
+	if snapshot != nil && countFailedCalls(results) > 0 {
+		rolledBack = snapshot.restore(ctx, toolCallCb, toolResultCb)
+	}
+
 	return &ToolResult{Result: map[string]interface{}{
 		"results":     results,
+		"summary":     summarizeBatch(parsedCalls, results, transactional, rolledBack),
 		"duration_ms": elapsed,
 	}}
 }
 
-// ExecuteWithCallbacks implements the callback-aware execution interface
-func (t *ParallelTool) ExecuteWithCallbacks(ctx context.Context, params map[string]interface{}, progressCb progress.Callback, toolCallCb func(string, string, map[string]interface{}) error, toolResultCb func(string, string, string, string) error) *ToolResult {
-	if t.registry == nil {
-		return &ToolResult{Error: "parallel tool registry is not configured"}
-	}
+// parallelCall is one parsed entry of tool_calls
+type parallelCall struct {
+	index  int
+	name   string
+	params map[string]interface{}
+}
 
+// parseParallelCalls validates and parses the tool_calls parameter
+func parseParallelCalls(params map[string]interface{}) ([]parallelCall, error) {
 	rawCalls, ok := params["tool_calls"]
 	if !ok {
-		return &ToolResult{Error: "tool_calls is required"}
+		return nil, fmt.Errorf("tool_calls is required")
 	}
 
 	callSlice, ok := rawCalls.([]interface{})
 	if !ok {
-		return &ToolResult{Error: "tool_calls must be an array"}
+		return nil, fmt.Errorf("tool_calls must be an array")
 	}
 
-	if len(callSlice) == 0 {
-		return &ToolResult{
-			Result: map[string]interface{}{
-				"results":     []map[string]interface{}{},
-				"duration_ms": int64(0),
-			},
-		}
-	}
-
-	type callSpec struct {
-		index  int
-		name   string
-		params map[string]interface{}
-	}
-
-	parsedCalls := make([]callSpec, 0, len(callSlice))
+	parsedCalls := make([]parallelCall, 0, len(callSlice))
 	for i, raw := range callSlice {
 		callMap, ok := raw.(map[string]interface{})
 		if !ok {
-			return &ToolResult{Error: fmt.Sprintf("tool_calls[%d] must be an object", i)}
+			return nil, fmt.Errorf("tool_calls[%d] must be an object", i)
 		}
 
 		nameVal, ok := callMap["name"].(string)
 		if !ok || nameVal == "" {
-			return &ToolResult{Error: fmt.Sprintf("tool_calls[%d].name must be a non-empty string", i)}
+			return nil, fmt.Errorf("tool_calls[%d].name must be a non-empty string", i)
 		}
 
 		paramsVal := map[string]interface{}{}
 		if rawParams, exists := callMap["parameters"]; exists && rawParams != nil {
 			castParams, ok := rawParams.(map[string]interface{})
 			if !ok {
-				return &ToolResult{Error: fmt.Sprintf("tool_calls[%d].parameters must be an object", i)}
+				return nil, fmt.Errorf("tool_calls[%d].parameters must be an object", i)
 			}
 			paramsVal = castParams
 		}
 
-		parsedCalls = append(parsedCalls, callSpec{
+		parsedCalls = append(parsedCalls, parallelCall{
 			index:  i,
 			name:   nameVal,
 			params: paramsVal,
 		})
 	}
-
-	// Send initial progress message showing all tools being executed
-	sendStream := func(msg string) {
-		if err := progress.Dispatch(progressCb, progress.Update{
-			Message: msg,
-			Mode:    progress.ReportNoStatus,
-		}); err != nil {
-			// Ignore progress dispatch errors to avoid interrupting parallel execution
-			return
-		}
-	}
-
-	// Show which tools are being executed in parallel
-	toolNames := make([]string, len(parsedCalls))
-	for i, call := range parsedCalls {
-		details := extractParallelToolDetails(call.name, call.params)
-		if details != "" {
-			toolNames[i] = fmt.Sprintf("%s(%s)", call.name, details)
-		} else {
-			toolNames[i] = call.name
-		}
-	}
-	if len(toolNames) > 0 {
-		sendStream(fmt.Sprintf("→ **parallel_tools** [%d]: %s\n", len(toolNames), joinToolNames(toolNames)))
-	}
-
-	totalStart := time.Now()
-	results := make([]map[string]interface{}, len(parsedCalls))
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	for _, call := range parsedCalls {
-		wg.Add(1)
-		go func(call callSpec) {
-			defer wg.Done()
-			start := time.Now()
-
-			result := map[string]interface{}{
-				"index": call.index,
-				"tool":  call.name,
-			}
-
-			select {
-			case <-ctx.Done():
-				result["error"] = ctx.Err().Error()
-				result["duration_ms"] = time.Since(start).Milliseconds()
-				mu.Lock()
-				results[call.index] = result
-				mu.Unlock()
-				return
-			default:
-			}
-
-			toolResult := t.registry.Execute(ctx, &ToolCall{
-				ID:         fmt.Sprintf("parallel_%d", call.index),
-				Name:       call.name,
-				Parameters: call.params,
-			})
-
-			if toolResult.Error != "" {
-				result["error"] = toolResult.Error
-			} else {
-				result["result"] = toolResult.Result
-			}
-
-			result["duration_ms"] = time.Since(start).Milliseconds()
-
-			mu.Lock()
-			results[call.index] = result
-			mu.Unlock()
-		}(call)
-	}
-
-	wg.Wait()
-
-	elapsed := time.Since(totalStart).Milliseconds()
-
-	return &ToolResult{Result: map[string]interface{}{
-		"results":     results,
-		"duration_ms": elapsed,
-	}}
+	return parsedCalls, nil
 }
 
 // extractParallelToolDetails extracts concise details from tool parameters for parallel execution display
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// rollbackableTools are the tools whose changes a transactional batch can
+// undo: they only write the file named by their path parameter
+var rollbackableTools = map[string]bool{
+	ToolNameCreateFile:  true,
+	ToolNameReplaceFile: true,
+	ToolNameEditFile:    true,
+}
+
+// fileSnapshot is the content of a file before a transactional batch ran
+type fileSnapshot struct {
+	toolName string // First tool of the batch writing the file
+	existed  bool
+	data     []byte
+}
+
+// batchSnapshot holds the files a transactional batch may change
+type batchSnapshot struct {
+	t     *ParallelTool
+	files map[string]fileSnapshot
+	order []string
+}
+
+// snapshotBatch records the files the calls will write. It fails if a call
+// has side effects that cannot be rolled back.
+func (t *ParallelTool) snapshotBatch(ctx context.Context, calls []parallelCall) (*batchSnapshot, error) {
+	if t.fs == nil {
+		return nil, fmt.Errorf("transactional batches are not supported: file system is not configured")
+	}
+
+	snapshot := &batchSnapshot{t: t, files: make(map[string]fileSnapshot)}
+	for _, call := range calls {
+		if !IsMutatingToolCall(call.name, call.params) {
+			continue
+		}
+		if !rollbackableTools[call.name] {
+			return nil, fmt.Errorf("tool_calls[%d]: %s cannot be rolled back; a transactional batch may only contain file edits (create_file, replace_file, edit_file) and read-only tools", call.index, call.name)
+		}
+
+		path := GetStringParam(call.params, "path", "")
+		if path == "" {
+			return nil, fmt.Errorf("tool_calls[%d].parameters.path is required", call.index)
+		}
+		if _, ok := snapshot.files[path]; ok {
+			continue
+		}
+
+		exists, err := t.fs.Exists(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", path, err)
+		}
+		file := fileSnapshot{toolName: call.name, existed: exists}
+		if exists {
+			if file.data, err = t.fs.ReadFile(ctx, path); err != nil {
+				return nil, fmt.Errorf("failed to snapshot %s: %w", path, err)
+			}
+		}
+		snapshot.files[path] = file
+		snapshot.order = append(snapshot.order, path)
+	}
+	return snapshot, nil
+}
+
+// restore puts every snapshotted file back into its previous state and
+// returns the paths it restored. Like the writes of the batch, the rollback
+// holds the registry's write lock and updates the session's file tracking.
+// Each restored file is reported to the callbacks as a write of the tool that
+// changed it, so it shows up in the UI and the workspace audit log.
+func (s *batchSnapshot) restore(ctx context.Context, toolCallCb func(string, string, map[string]interface{}) error, toolResultCb func(string, string, string, string) error) []string {
+	// The batch may already be cancelled; the rollback must still happen
+	ctx = context.WithoutCancel(ctx)
+
+	if s.t.registry != nil {
+		s.t.registry.writeMu.Lock()
+		defer s.t.registry.writeMu.Unlock()
+	}
+
+	var restored []string
+	for idx, path := range s.order {
+		file := s.files[path]
+		changed, err := s.restoreFile(ctx, path, file)
+		if err != nil {
+			logger.Warn("parallel_tools: failed to roll back %s: %v", path, err)
+		} else if changed {
+			restored = append(restored, path)
+		} else {
+			continue
+		}
+
+		toolID := fmt.Sprintf("parallel_rollback_%d", idx)
+		if toolCallCb != nil {
+			if cbErr := toolCallCb(file.toolName, toolID, map[string]interface{}{"path": path, "rollback": true}); cbErr != nil {
+				logger.Debug("parallel_tools: tool call callback failed: %v", cbErr)
+			}
+		}
+		if toolResultCb != nil {
+			result, errorMsg := fmt.Sprintf("Rolled back %s", path), ""
+			if err != nil {
+				result, errorMsg = "", fmt.Sprintf("failed to roll back %s: %v", path, err)
+			}
+			if cbErr := toolResultCb(file.toolName, toolID, result, errorMsg); cbErr != nil {
+				logger.Debug("parallel_tools: tool result callback failed: %v", cbErr)
+			}
+		}
+	}
+	return restored
+}
+
+// restoreFile puts a single file back into its snapshotted state. It reports
+// whether anything had to be changed.
+func (s *batchSnapshot) restoreFile(ctx context.Context, path string, file fileSnapshot) (bool, error) {
+	if file.existed {
+		if err := s.t.fs.WriteFile(ctx, path, file.data); err != nil {
+			return false, err
+		}
+		if s.t.session != nil {
+			s.t.session.TrackFileModified(path)
+			s.t.session.TrackFileRead(path, string(file.data))
+		}
+		return true, nil
+	}
+
+	exists, err := s.t.fs.Exists(ctx, path)
+	if err != nil || !exists {
+		return false, err
+	}
+	if err := s.t.fs.Delete(ctx, path); err != nil {
+		return false, err
+	}
+	if s.t.session != nil {
+		s.t.session.TrackFileModified(path)
+	}
+	return true, nil
+}
+
+// countFailedCalls returns the number of results carrying an error
+func countFailedCalls(results []map[string]interface{}) int {
+	failed := 0
+	for _, result := range results {
+		if _, ok := result["error"]; ok {
+			failed++
+		}
+	}
+	return failed
+}
+
+// summarizeBatch consolidates the results of a batch, so the model can tell
+// at a glance what was applied and how to recover from partial failures
+func summarizeBatch(calls []parallelCall, results []map[string]interface{}, transactional bool, rolledBack []string) map[string]interface{} {
+	var (
+		succeeded []string
+		failures  []map[string]interface{}
+		applied   []string // successful calls that changed something
+	)
+	for i, call := range calls {
+		label := batchCallLabel(call)
+		if errMsg, ok := results[i]["error"]; ok {
+			failures = append(failures, map[string]interface{}{
+				"index": call.index,
+				"tool":  call.name,
+				"call":  label,
+				"error": errMsg,
+			})
+			continue
+		}
+		succeeded = append(succeeded, label)
+		if IsMutatingToolCall(call.name, call.params) {
+			applied = append(applied, label)
+		}
+	}
+
+	summary := map[string]interface{}{
+		"total":         len(calls),
+		"succeeded":     len(succeeded),
+		"failed":        len(failures),
+		"transactional": transactional,
+	}
+	if len(failures) == 0 {
+		summary["message"] = fmt.Sprintf("All %d calls succeeded", len(calls))
+		return summary
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "%d of %d calls succeeded", len(succeeded), len(calls))
+	for _, failure := range failures {
+		fmt.Fprintf(&message, "; %s failed: %v", failure["call"], failure["error"])
+	}
+	summary["message"] = message.String()
+	summary["failures"] = failures
+
+	switch {
+	case transactional:
+		summary["rolled_back"] = rolledBack
+		summary["suggestion"] = "No changes of this batch were kept: the files changed by the successful calls were rolled back. Fix the failed calls and retry the whole batch."
+	case len(applied) > 0:
+		summary["applied"] = applied
+		summary["suggestion"] = fmt.Sprintf("The changes of the successful calls were applied (%s). Retry only the failed calls, or revert the applied changes if the batch must succeed as a whole.", strings.Join(applied, ", "))
+	default:
+		summary["suggestion"] = "Nothing was changed. Retry only the failed calls."
+	}
+	return summary
+}
+
+// batchCallLabel describes a call by tool name and target
+func batchCallLabel(call parallelCall) string {
+	for _, key := range []string{"path", "command", "pattern"} {
+		if value := GetStringParam(call.params, key, ""); value != "" {
+			return fmt.Sprintf("%s(%s)", call.name, value)
+		}
+	}
+	return fmt.Sprintf("%s#%d", call.name, call.index)
+}
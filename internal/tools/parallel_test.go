@@ -583,3 +583,171 @@ func (t *NoParamTestTool) Parameters() map[string]interface{} {
 func (t *NoParamTestTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
 	return &ToolResult{Result: fmt.Sprintf("executed with %d params", len(params))}
 }
+
+func newBatchTestTool(t *testing.T) (*ParallelTool, *fs.MockFS) {
+	t.Helper()
+	mockFS := fs.NewMockFS()
+	sess := session.NewSession("test-session", ".")
+	registry := NewRegistry(nil)
+	registry.Register(NewReadFileTool(mockFS, sess))
+	registry.Register(NewCreateFileTool(mockFS, sess))
+	registry.Register(NewReplaceFileTool(mockFS, sess))
+
+	_ = mockFS.WriteFile(context.Background(), "exists.txt", []byte("original"))
+	sess.TrackFileRead("exists.txt", "original")
+	return NewParallelToolWithFS(registry, mockFS, sess), mockFS
+}
+
+func batchCall(name string, params map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": name, "parameters": params}
+}
+
+func TestParallelTool_BatchSummaryPartialFailure(t *testing.T) {
+	tool, mockFS := newBatchTestTool(t)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"tool_calls": []interface{}{
+			batchCall(ToolNameCreateFile, map[string]interface{}{"path": "a.txt", "content": "a"}),
+			batchCall(ToolNameCreateFile, map[string]interface{}{"path": "exists.txt", "content": "b"}),
+			batchCall(ToolNameReadFile, map[string]interface{}{"path": "exists.txt"}),
+			batchCall(ToolNameReadFile, map[string]interface{}{"path": "missing.txt"}),
+		},
+	})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+
+	summary := result.Result.(map[string]interface{})["summary"].(map[string]interface{})
+	if summary["total"] != 4 || summary["succeeded"] != 2 || summary["failed"] != 2 || summary["transactional"] != false {
+		t.Errorf("unexpected counts: %+v", summary)
+	}
+
+	message := summary["message"].(string)
+	for _, want := range []string{"2 of 4 calls succeeded", "create_file(exists.txt) failed: file already exists", "read_file(missing.txt) failed"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("expected message to contain %q, got %q", want, message)
+		}
+	}
+
+	failures := summary["failures"].([]map[string]interface{})
+	if len(failures) != 2 || failures[0]["index"] != 1 || failures[1]["index"] != 3 {
+		t.Errorf("unexpected failures: %+v", failures)
+	}
+	if applied := summary["applied"].([]string); len(applied) != 1 || applied[0] != "create_file(a.txt)" {
+		t.Errorf("expected only the created file to be reported as applied, got %v", applied)
+	}
+	if !strings.Contains(summary["suggestion"].(string), "Retry only the failed calls") {
+		t.Errorf("unexpected suggestion %q", summary["suggestion"])
+	}
+
+	// Without a transaction the successful change stays
+	if exists, _ := mockFS.Exists(context.Background(), "a.txt"); !exists {
+		t.Error("expected a.txt to be kept")
+	}
+}
+
+func TestParallelTool_BatchSummaryAllSucceeded(t *testing.T) {
+	tool, _ := newBatchTestTool(t)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"tool_calls": []interface{}{
+			batchCall(ToolNameReadFile, map[string]interface{}{"path": "exists.txt"}),
+		},
+	})
+
+	summary := result.Result.(map[string]interface{})["summary"].(map[string]interface{})
+	if summary["failed"] != 0 || summary["message"] != "All 1 calls succeeded" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if _, ok := summary["suggestion"]; ok {
+		t.Error("expected no suggestion without failures")
+	}
+}
+
+func TestParallelTool_TransactionalRollsBack(t *testing.T) {
+	tool, mockFS := newBatchTestTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]interface{}{
+		"transactional": true,
+		"tool_calls": []interface{}{
+			batchCall(ToolNameCreateFile, map[string]interface{}{"path": "new.txt", "content": "new"}),
+			batchCall(ToolNameReplaceFile, map[string]interface{}{"path": "exists.txt", "content": "replaced"}),
+			batchCall(ToolNameCreateFile, map[string]interface{}{"path": "exists.txt", "content": "clash"}),
+		},
+	})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+
+	summary := result.Result.(map[string]interface{})["summary"].(map[string]interface{})
+	if summary["succeeded"] != 2 || summary["failed"] != 1 || summary["transactional"] != true {
+		t.Errorf("unexpected counts: %+v", summary)
+	}
+	if rolledBack := summary["rolled_back"].([]string); len(rolledBack) != 2 {
+		t.Errorf("expected both files to be rolled back, got %v", rolledBack)
+	}
+	if !strings.Contains(summary["suggestion"].(string), "rolled back") {
+		t.Errorf("unexpected suggestion %q", summary["suggestion"])
+	}
+
+	if exists, _ := mockFS.Exists(ctx, "new.txt"); exists {
+		t.Error("expected the created file to be removed")
+	}
+	if data, _ := mockFS.ReadFile(ctx, "exists.txt"); string(data) != "original" {
+		t.Errorf("expected the replaced file to be restored, got %q", data)
+	}
+}
+
+func TestParallelTool_RollbackIsReportedAndTracked(t *testing.T) {
+	tool, mockFS := newBatchTestTool(t)
+	ctx := context.Background()
+
+	var reported []string
+	toolCallCb := func(toolName, toolID string, params map[string]interface{}) error {
+		reported = append(reported, fmt.Sprintf("call %s %s", toolName, params["path"]))
+		return nil
+	}
+	toolResultCb := func(toolName, toolID, result, errorMsg string) error {
+		reported = append(reported, fmt.Sprintf("result %s %s%s", toolName, result, errorMsg))
+		return nil
+	}
+
+	result := tool.ExecuteWithCallbacks(ctx, map[string]interface{}{
+		"transactional": true,
+		"tool_calls": []interface{}{
+			batchCall(ToolNameReplaceFile, map[string]interface{}{"path": "exists.txt", "content": "replaced"}),
+			batchCall(ToolNameReadFile, map[string]interface{}{"path": "missing.txt"}),
+		},
+	}, nil, toolCallCb, toolResultCb)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+
+	want := []string{"call replace_file exists.txt", "result replace_file Rolled back exists.txt"}
+	if strings.Join(reported, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected the rollback to be reported as %v, got %v", want, reported)
+	}
+	if data, _ := mockFS.ReadFile(ctx, "exists.txt"); string(data) != "original" {
+		t.Errorf("expected the replaced file to be restored, got %q", data)
+	}
+	// The restored content is what the session last saw of the file
+	if read := tool.session.FilesRead["exists.txt"]; read != "original" {
+		t.Errorf("expected the restored content to be tracked as read, got %q", read)
+	}
+}
+
+func TestParallelTool_TransactionalRejectsIrreversibleCalls(t *testing.T) {
+	tool, _ := newBatchTestTool(t)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"transactional": true,
+		"tool_calls": []interface{}{
+			batchCall(ToolNameCreateFile, map[string]interface{}{"path": "new.txt", "content": "new"}),
+			batchCall(ToolNameShell, map[string]interface{}{"command": "rm -rf build"}),
+		},
+	})
+	if !strings.Contains(result.Error, "cannot be rolled back") {
+		t.Errorf("expected the shell call to be rejected, got %+v", result)
+	}
+}
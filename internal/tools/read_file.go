@@ -105,7 +105,7 @@ func (t *ReadFileTool) Execute(ctx context.Context, params map[string]interface{
 		return &ToolResult{Error: fmt.Sprintf("error reading file: %v", err)}
 	}
 	if isLikelyBinaryFile(path, data) {
-		return &ToolResult{Error: fmt.Sprintf("cannot read binary file: %s", path)}
+		return binaryFileResult(path, data)
 	}
 	content := string(data)
 
@@ -179,7 +179,7 @@ func (t *ReadFileTool) executeMultiSection(ctx context.Context, path string, sec
 		return &ToolResult{Error: fmt.Sprintf("error reading file: %v", err)}
 	}
 	if isLikelyBinaryFile(path, fileData) {
-		return &ToolResult{Error: fmt.Sprintf("cannot read binary file: %s", path)}
+		return binaryFileResult(path, fileData)
 	}
 	totalFileLines := strings.Count(string(fileData), "\n") + 1
 
@@ -64,7 +64,7 @@ func (e *ReadFileNumberedExecutor) Execute(ctx context.Context, params map[strin
 		return &ToolResult{Error: fmt.Sprintf("error reading file: %v", err)}
 	}
 	if isLikelyBinaryFile(path, data) {
-		return &ToolResult{Error: fmt.Sprintf("cannot read binary file: %s", path)}
+		return binaryFileResult(path, data)
 	}
 	rawContent := string(data)
 	totalLineCount := strings.Count(rawContent, "\n") + 1
@@ -124,7 +124,7 @@ func (e *ReadFileNumberedExecutor) executeMultiSection(ctx context.Context, path
 		return &ToolResult{Error: fmt.Sprintf("error reading file: %v", err)}
 	}
 	if isLikelyBinaryFile(path, fileData) {
-		return &ToolResult{Error: fmt.Sprintf("cannot read binary file: %s", path)}
+		return binaryFileResult(path, fileData)
 	}
 	totalFileLines := strings.Count(string(fileData), "\n") + 1
 
@@ -67,12 +67,19 @@ func TestReadFileNumberedExecutor_RejectsBinaryFile(t *testing.T) {
 		"path": "binary.exe",
 	})
 
-	if result.Error == "" {
-		t.Fatal("expected error for binary file")
+	if result.Error != "" {
+		t.Fatalf("expected a binary file notice, got error: %s", result.Error)
 	}
 
-	if !strings.Contains(result.Error, "binary") {
-		t.Errorf("expected binary error, got: %s", result.Error)
+	resultMap, ok := result.Result.(map[string]interface{})
+	if !ok || resultMap["binary"] != true {
+		t.Fatalf("expected binary result, got: %v", result.Result)
+	}
+	if _, hasContent := resultMap["content"]; hasContent {
+		t.Error("binary file content should not be returned")
+	}
+	if msg, _ := resultMap["message"].(string); !strings.Contains(msg, "binary file") || !strings.Contains(msg, ToolNameGoSandbox) {
+		t.Errorf("unexpected binary notice: %s", msg)
 	}
 }
 
@@ -0,0 +1,50 @@
+package tools
+
+import "fmt"
+
+// readOnlyTools are the tools available in read-only mode: they read,
+// search and summarize, but never change files or run programs
+var readOnlyTools = map[string]struct{}{
+	ToolNameReadFile:             {},
+	ToolNameReadFileSummarized:   {},
+	ToolNameSearchFiles:          {},
+	ToolNameSearchFileContent:    {},
+	ToolNameCodebaseInvestigator: {},
+	ToolNameValidateSyntax:       {},
+	ToolNameLs:                   {},
+	ToolNameSearchContextFiles:   {},
+	ToolNameGrepContextFiles:     {},
+	ToolNameReadContextFile:      {},
+	ToolNameWebSearch:            {},
+	ToolNameWebFetch:             {},
+	ToolNameToolSummarize:        {},
+	ToolNameTodo:                 {},
+	ToolNameMemory:               {},
+	ToolNameStatusProgram:        {},
+	ToolNameWaitProgram:          {},
+	ToolNameWaitForFile:          {},
+	ToolNameParallel:             {},
+	"planning_agent":             {},
+	"task_summary":               {},
+}
+
+// IsReadOnlyTool reports whether a tool is registered in read-only mode.
+// Unknown tools, including MCP tools, are excluded because their side effects
+// cannot be known.
+func IsReadOnlyTool(toolName string) bool {
+	_, ok := readOnlyTools[toolName]
+	return ok
+}
+
+// readOnlyDenial returns the decision for a tool call in read-only mode, or
+// nil if the call is allowed
+func readOnlyDenial(toolName string, params map[string]interface{}) *AuthorizationDecision {
+	if IsReadOnlyTool(toolName) && !IsMutatingToolCall(toolName, params) {
+		return nil
+	}
+	return &AuthorizationDecision{
+		Allowed: false,
+		Reason: fmt.Sprintf("Read-only mode: %s is not allowed because this session must not change files or run programs. "+
+			"Use the read and search tools and describe the changes you would make instead.", toolName),
+	}
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestIsReadOnlyTool(t *testing.T) {
+	for _, name := range []string{ToolNameReadFile, ToolNameSearchFiles, ToolNameSearchFileContent, ToolNameReadFileSummarized, ToolNameWebFetch} {
+		if !IsReadOnlyTool(name) {
+			t.Errorf("expected %s to be available in read-only mode", name)
+		}
+	}
+	for _, name := range []string{ToolNameCreateFile, ToolNameEditFile, ToolNameReplaceFile, ToolNameReplaceInFiles, ToolNameShell, ToolNameGoSandbox, ToolNameStopProgram, "mcp_github_create_issue"} {
+		if IsReadOnlyTool(name) {
+			t.Errorf("expected %s to be unavailable in read-only mode", name)
+		}
+	}
+}
+
+func TestAuthorizationDeniesWritesInReadOnlyMode(t *testing.T) {
+	ctx := context.Background()
+	actor := NewAuthorizationActor("auth", fs.NewMockFS(), session.NewSession("test", "."), nil,
+		&AuthorizationOptions{ReadOnly: true, DangerouslyAllowAll: true})
+
+	for _, call := range []struct {
+		tool   string
+		params map[string]interface{}
+	}{
+		{ToolNameCreateFile, map[string]interface{}{"path": "new.txt", "content": "x"}},
+		{ToolNameEditFile, map[string]interface{}{"path": "main.go"}},
+		{ToolNameShell, map[string]interface{}{"command": "ls"}},
+		{ToolNameGoSandbox, map[string]interface{}{"code": "package main"}},
+		{ToolNameMemory, map[string]interface{}{"operation": "append", "content": "note"}},
+	} {
+		decision, err := actor.authorize(ctx, call.tool, call.params)
+		if err != nil {
+			t.Fatalf("authorize(%s) returned error: %v", call.tool, err)
+		}
+		if decision.Allowed || decision.RequiresUserInput || !strings.Contains(decision.Reason, "Read-only mode") {
+			t.Errorf("expected %s to be denied in read-only mode, got %+v", call.tool, decision)
+		}
+	}
+
+	for _, call := range []struct {
+		tool   string
+		params map[string]interface{}
+	}{
+		{ToolNameReadFile, map[string]interface{}{"path": "main.go"}},
+		{ToolNameMemory, map[string]interface{}{"operation": "read"}},
+	} {
+		decision, err := actor.authorize(ctx, call.tool, call.params)
+		if err != nil || !decision.Allowed {
+			t.Errorf("expected %s to be allowed in read-only mode, got %+v, %v", call.tool, decision, err)
+		}
+	}
+}
+
+func TestAuthorizationFollowsSessionReadOnlyMode(t *testing.T) {
+	ctx := context.Background()
+	sess := session.NewSession("test", ".")
+	actor := NewAuthorizationActor("auth", fs.NewMockFS(), sess, nil, &AuthorizationOptions{DangerouslyAllowAll: true})
+	params := map[string]interface{}{"path": "new.txt", "content": "x"}
+
+	if decision, _ := actor.authorize(ctx, ToolNameCreateFile, params); !decision.Allowed {
+		t.Fatalf("expected create_file to be allowed before read-only mode, got %+v", decision)
+	}
+
+	sess.SetReadOnly(true)
+	if decision, _ := actor.authorize(ctx, ToolNameCreateFile, params); decision.Allowed {
+		t.Fatalf("expected create_file to be denied once the session is read-only, got %+v", decision)
+	}
+}
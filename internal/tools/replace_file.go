@@ -146,14 +146,16 @@ func (t *ReplaceFileTool) Execute(ctx context.Context, params map[string]interfa
 	}
 
 	// Generate UI result with validation warning if present
-	uiResult := generateGitDiff(path, oldContent, content)
+	diffText := generateGitDiff(path, oldContent, content)
+	uiResult := diffText
 	if validationWarning != "" {
 		uiResult = fmt.Sprintf("%s\n\n⚠️  **Syntax Validation**\n%s", uiResult, validationWarning)
 	}
 
 	return &ToolResult{
-		Result:   resultMap,
-		UIResult: uiResult,
+		Result:            resultMap,
+		UIResult:          uiResult,
+		ExecutionMetadata: diffMetadata(diffText),
 	}
 }
 
@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/vcs"
+)
+
+const (
+	// defaultReplaceInFilesMaxFiles is the default cap on files changed by one call
+	defaultReplaceInFilesMaxFiles = 50
+	// maxReplaceInFilesMaxFiles is the upper bound for the max_files parameter
+	maxReplaceInFilesMaxFiles = 200
+)
+
+// ReplaceInFilesToolSpec is the static specification for the replace_in_files tool
+type ReplaceInFilesToolSpec struct{}
+
+func (s *ReplaceInFilesToolSpec) Name() string {
+	return ToolNameReplaceInFiles
+}
+
+func (s *ReplaceInFilesToolSpec) Description() string {
+	return `Replace a literal string or regular expression in all files matching a glob (e.g. to rename a symbol across the codebase).
+Call with preview=true first to see the number of matches per file; applying the replacement requires user approval.
+All matched files are changed together or not at all. Paths ignored by .gitignore are skipped.`
+}
+
+func (s *ReplaceInFilesToolSpec) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"search": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to search for (a Go regular expression if regex is true)",
+			},
+			"replacement": map[string]interface{}{
+				"type":        "string",
+				"description": "Replacement text. With regex, $1 or ${name} refer to capture groups.",
+			},
+			"files": map[string]interface{}{
+				"type":        "string",
+				"description": "Glob of files to change (e.g. '*.go', 'internal/**/*.ts')",
+			},
+			"regex": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Treat search as a regular expression (default: false)",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Base directory (relative to working directory, default: '.')",
+			},
+			"max_files": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Refuse to change more files than this (default: %d, max: %d)", defaultReplaceInFilesMaxFiles, maxReplaceInFilesMaxFiles),
+			},
+			"preview": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only report matches per file without changing anything (default: false)",
+			},
+		},
+		"required": []string{"search", "replacement", "files"},
+	}
+}
+
+// ReplaceInFilesTool is the executor with runtime dependencies
+type ReplaceInFilesTool struct {
+	fs          fs.FileSystem
+	session     *session.Session
+	ignoredDirs []string
+	vcs         vcs.VCS // decides which paths are ignored (nil = only ignoredDirs)
+	workingDir  string
+}
+
+func NewReplaceInFilesTool(filesystem fs.FileSystem, sess *session.Session) *ReplaceInFilesTool {
+	return &ReplaceInFilesTool{
+		fs:          filesystem,
+		session:     sess,
+		ignoredDirs: config.DefaultIgnoredDirs,
+	}
+}
+
+// SetIgnoredDirs sets the directory names that are always skipped
+func (t *ReplaceInFilesTool) SetIgnoredDirs(dirs []string) {
+	t.ignoredDirs = dirs
+}
+
+// SetVCS skips the paths ignored by repo. Relative paths are resolved against
+// workingDir.
+func (t *ReplaceInFilesTool) SetVCS(repo vcs.VCS, workingDir string) {
+	t.vcs = repo
+	t.workingDir = workingDir
+}
+
+// Legacy interface implementation for backward compatibility
+func (t *ReplaceInFilesTool) Name() string        { return ToolNameReplaceInFiles }
+func (t *ReplaceInFilesTool) Description() string { return (&ReplaceInFilesToolSpec{}).Description() }
+func (t *ReplaceInFilesTool) Parameters() map[string]interface{} {
+	return (&ReplaceInFilesToolSpec{}).Parameters()
+}
+
+// fileReplacement is the planned change of a single file
+type fileReplacement struct {
+	path       string
+	matches    int
+	oldContent string
+	newContent string
+}
+
+func (t *ReplaceInFilesTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
+	search := GetStringParam(params, "search", "")
+	if search == "" {
+		return &ToolResult{Error: "search is required"}
+	}
+	replacement, ok := params["replacement"].(string)
+	if !ok {
+		return &ToolResult{Error: "replacement is required"}
+	}
+	filesGlob := GetStringParam(params, "files", "")
+	if filesGlob == "" {
+		return &ToolResult{Error: "files is required"}
+	}
+	if t.fs == nil {
+		return &ToolResult{Error: "file system is not configured"}
+	}
+
+	maxFiles := GetIntParam(params, "max_files", defaultReplaceInFilesMaxFiles)
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	if maxFiles > maxReplaceInFilesMaxFiles {
+		maxFiles = maxReplaceInFilesMaxFiles
+	}
+
+	isRegex := GetBoolParam(params, "regex", false)
+	var re *regexp.Regexp
+	if isRegex {
+		var err error
+		re, err = regexp.Compile(search)
+		if err != nil {
+			return &ToolResult{Error: fmt.Sprintf("invalid search regex: %v", err)}
+		}
+	} else {
+		re = regexp.MustCompile(regexp.QuoteMeta(search))
+	}
+
+	plan, err := t.plan(ctx, GetStringParam(params, "path", "."), filesGlob, re, replacement, isRegex, maxFiles)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
+	totalMatches := 0
+	matchesPerFile := make(map[string]int, len(plan))
+	for _, change := range plan {
+		totalMatches += change.matches
+		matchesPerFile[change.path] = change.matches
+	}
+
+	if len(plan) == 0 {
+		return &ToolResult{Result: map[string]interface{}{
+			"files_changed": 0,
+			"matches":       0,
+			"message":       fmt.Sprintf("No matches for %q in files matching %s", search, filesGlob),
+		}}
+	}
+
+	if GetBoolParam(params, "preview", false) {
+		return &ToolResult{
+			Result: map[string]interface{}{
+				"preview":          true,
+				"files":            len(plan),
+				"matches":          totalMatches,
+				"matches_per_file": matchesPerFile,
+			},
+			UIResult: formatReplacePreview(plan, totalMatches),
+		}
+	}
+
+	if err := t.apply(ctx, plan); err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
+	var diff strings.Builder
+	for _, change := range plan {
+		diff.WriteString(generateGitDiff(change.path, change.oldContent, change.newContent))
+	}
+
+	logger.Info("replace_in_files: replaced %d match(es) in %d file(s)", totalMatches, len(plan))
+	return &ToolResult{
+		Result: map[string]interface{}{
+			"files_changed":    len(plan),
+			"matches":          totalMatches,
+			"matches_per_file": matchesPerFile,
+			"diff":             diff.String(),
+		},
+		UIResult:          diff.String(),
+		ExecutionMetadata: diffMetadata(diff.String()),
+	}
+}
+
+// plan computes the new content of every file matching filesGlob that contains re
+func (t *ReplaceInFilesTool) plan(ctx context.Context, basePath, filesGlob string, re *regexp.Regexp, replacement string, isRegex bool, maxFiles int) ([]fileReplacement, error) {
+	search := NewSearchFilesTool(t.fs)
+	search.SetIgnoredDirs(t.ignoredDirs)
+	search.SetVCS(t.vcs, t.workingDir)
+	// Ask for one more file than allowed to detect when the cap is exceeded
+	paths, err := search.searchFiles(ctx, basePath, filesGlob, re, maxFiles+1, false)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+	if len(paths) > maxFiles {
+		return nil, fmt.Errorf("more than %d files match; narrow the files glob or raise max_files", maxFiles)
+	}
+
+	plan := make([]fileReplacement, 0, len(paths))
+	for _, path := range paths {
+		data, err := t.fs.ReadFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		oldContent := string(data)
+
+		matches := len(re.FindAllStringIndex(oldContent, -1))
+		if matches == 0 {
+			continue
+		}
+
+		var newContent string
+		if isRegex {
+			newContent = re.ReplaceAllString(oldContent, replacement)
+		} else {
+			newContent = re.ReplaceAllLiteralString(oldContent, replacement)
+		}
+		if newContent == oldContent {
+			continue
+		}
+
+		plan = append(plan, fileReplacement{
+			path:       path,
+			matches:    matches,
+			oldContent: oldContent,
+			newContent: newContent,
+		})
+	}
+	return plan, nil
+}
+
+// apply writes all planned changes. If a write fails, files already written
+// are restored so the workspace is left unchanged.
+func (t *ReplaceInFilesTool) apply(ctx context.Context, plan []fileReplacement) error {
+	for i, change := range plan {
+		if err := t.fs.WriteFile(ctx, change.path, []byte(change.newContent)); err != nil {
+			for _, done := range plan[:i] {
+				if restoreErr := t.fs.WriteFile(ctx, done.path, []byte(done.oldContent)); restoreErr != nil {
+					logger.Error("replace_in_files: failed to restore %s: %v", done.path, restoreErr)
+				}
+			}
+			return fmt.Errorf("error writing %s: %v (no files were changed)", change.path, err)
+		}
+	}
+
+	if t.session != nil {
+		for _, change := range plan {
+			t.session.TrackFileModified(change.path)
+			t.session.TrackFileRead(change.path, change.newContent)
+		}
+	}
+	return nil
+}
+
+func formatReplacePreview(plan []fileReplacement, totalMatches int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d match(es) in %d file(s):\n", totalMatches, len(plan))
+	for _, change := range plan {
+		fmt.Fprintf(&sb, "- %s (%d)\n", change.path, change.matches)
+	}
+	return sb.String()
+}
+
+// NewReplaceInFilesToolFactory creates a factory for ReplaceInFilesTool
+func NewReplaceInFilesToolFactory(filesystem fs.FileSystem, sess *session.Session, ignoredDirs []string, repo vcs.VCS, workingDir string) ToolFactory {
+	return func(reg *Registry) ToolExecutor {
+		tool := NewReplaceInFilesTool(filesystem, sess)
+		if ignoredDirs != nil {
+			tool.SetIgnoredDirs(ignoredDirs)
+		}
+		tool.SetVCS(repo, workingDir)
+		return tool
+	}
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func newReplaceInFilesFixture(t *testing.T) (*ReplaceInFilesTool, *fs.MockFS, *session.Session) {
+	t.Helper()
+
+	mockFS := fs.NewMockFS()
+	files := map[string]string{
+		"main.go":                "package main\n\nfunc main() {\n\toldName()\n\toldName()\n}\n",
+		"internal/util/util.go":  "package util\n\n// oldName does things\nfunc oldName() {}\n",
+		"internal/util/other.go": "package util\n\nfunc unrelated() {}\n",
+		"generated/gen.go":       "package generated\n\nvar _ = oldName\n",
+		"README.md":              "Call oldName() to start.\n",
+	}
+	for path, content := range files {
+		if err := mockFS.WriteFile(context.Background(), path, []byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	sess := session.NewSession("test", ".")
+	tool := NewReplaceInFilesTool(mockFS, sess)
+	tool.SetVCS(ignoringVCS("/repo/generated"), "/repo")
+	return tool, mockFS, sess
+}
+
+func readMockFile(t *testing.T, mockFS *fs.MockFS, path string) string {
+	t.Helper()
+	data, err := mockFS.ReadFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestReplaceInFilesLiteral(t *testing.T) {
+	tool, mockFS, sess := newReplaceInFilesFixture(t)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"search":      "oldName(",
+		"replacement": "newName(",
+		"files":       "*.go",
+	})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+
+	resMap := result.Result.(map[string]interface{})
+	if resMap["files_changed"] != 2 || resMap["matches"] != 3 {
+		t.Fatalf("expected 3 matches in 2 files, got %+v", resMap)
+	}
+
+	if got := readMockFile(t, mockFS, "main.go"); strings.Contains(got, "oldName") || strings.Count(got, "newName()") != 2 {
+		t.Errorf("main.go not updated: %q", got)
+	}
+	// "oldName does things" has no "(" and is left alone
+	if got := readMockFile(t, mockFS, "internal/util/util.go"); !strings.Contains(got, "// oldName does things") || !strings.Contains(got, "func newName() {}") {
+		t.Errorf("util.go not updated as expected: %q", got)
+	}
+	if got := readMockFile(t, mockFS, "generated/gen.go"); !strings.Contains(got, "oldName") {
+		t.Errorf("gitignored file should be untouched: %q", got)
+	}
+	if got := readMockFile(t, mockFS, "README.md"); !strings.Contains(got, "oldName()") {
+		t.Errorf("files outside the glob should be untouched: %q", got)
+	}
+
+	diff, _ := resMap["diff"].(string)
+	if !strings.Contains(diff, "--- a/main.go") || !strings.Contains(diff, "+++ b/internal/util/util.go") || !strings.Contains(diff, "+\tnewName()") {
+		t.Errorf("expected a unified diff of all changes, got:\n%s", diff)
+	}
+	if !sess.WasFileRead("main.go") || len(sess.GetModifiedFiles()) != 2 {
+		t.Errorf("expected changed files to be tracked in the session, got %v", sess.GetModifiedFiles())
+	}
+}
+
+func TestReplaceInFilesRegex(t *testing.T) {
+	tool, mockFS, _ := newReplaceInFilesFixture(t)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"search":      `\boldName\b`,
+		"replacement": "${0}V2",
+		"files":       "**/*",
+		"regex":       true,
+	})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+
+	resMap := result.Result.(map[string]interface{})
+	perFile := resMap["matches_per_file"].(map[string]int)
+	want := map[string]int{"main.go": 2, "internal/util/util.go": 2, "README.md": 1}
+	if len(perFile) != len(want) {
+		t.Fatalf("expected matches in %v, got %v", want, perFile)
+	}
+	for path, count := range want {
+		if perFile[path] != count {
+			t.Errorf("expected %d matches in %s, got %d", count, path, perFile[path])
+		}
+	}
+	if got := readMockFile(t, mockFS, "internal/util/util.go"); !strings.Contains(got, "// oldNameV2 does things") || !strings.Contains(got, "func oldNameV2() {}") {
+		t.Errorf("regex replacement not applied: %q", got)
+	}
+}
+
+func TestReplaceInFilesPreviewAndLimits(t *testing.T) {
+	tool, mockFS, _ := newReplaceInFilesFixture(t)
+	before := readMockFile(t, mockFS, "main.go")
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"search":      "oldName",
+		"replacement": "newName",
+		"files":       "*.go",
+		"preview":     true,
+	})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if resMap := result.Result.(map[string]interface{}); resMap["matches"] != 4 || resMap["files"] != 2 {
+		t.Errorf("unexpected preview: %+v", resMap)
+	}
+	if got := readMockFile(t, mockFS, "main.go"); got != before {
+		t.Errorf("preview must not change files")
+	}
+
+	result = tool.Execute(context.Background(), map[string]interface{}{
+		"search":      "oldName",
+		"replacement": "newName",
+		"files":       "*",
+		"max_files":   2,
+	})
+	if !strings.Contains(result.Error, "more than 2 files match") {
+		t.Errorf("expected max_files error, got %+v", result)
+	}
+	if got := readMockFile(t, mockFS, "main.go"); got != before {
+		t.Errorf("no file should change when the cap is exceeded")
+	}
+
+	result = tool.Execute(context.Background(), map[string]interface{}{
+		"search":      "(",
+		"replacement": "x",
+		"files":       "*.go",
+		"regex":       true,
+	})
+	if !strings.Contains(result.Error, "invalid search regex") {
+		t.Errorf("expected regex error, got %+v", result)
+	}
+}
+
+func TestAuthorizeReplaceInFilesRequiresApproval(t *testing.T) {
+	authorizer := NewAuthorizationActor("test", fs.NewMockFS(), nil, nil, nil)
+	params := map[string]interface{}{"search": "a", "replacement": "b", "files": "*.go", "path": "internal/api"}
+
+	decision, err := authorizer.authorizeTool(context.Background(), ToolNameReplaceInFiles, params)
+	if err != nil {
+		t.Fatalf("authorize failed: %v", err)
+	}
+	if decision.Allowed || !decision.RequiresUserInput {
+		t.Errorf("expected bulk replacement to require approval, got %+v", decision)
+	}
+	if !strings.HasSuffix(decision.Reason, "matching *.go in internal/api") {
+		t.Errorf("expected the reason to name the base directory, got %q", decision.Reason)
+	}
+
+	params["preview"] = true
+	decision, _ = authorizer.authorizeTool(context.Background(), ToolNameReplaceInFiles, params)
+	if !decision.Allowed {
+		t.Errorf("expected preview to be allowed, got %+v", decision)
+	}
+}
@@ -25,6 +25,32 @@ type ShellExecutor interface {
 	ExecuteCommand(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string) (stdout string, stderr string, exitCode int, err error)
 }
 
+// ShellEnvExecutor is an optional extension of ShellExecutor for executors that
+// can set extra environment variables for a single command
+type ShellEnvExecutor interface {
+	ShellExecutor
+	// ExecuteCommandWithEnv executes a command with env added to its environment
+	ExecuteCommandWithEnv(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string, env map[string]string) (stdout string, stderr string, exitCode int, err error)
+}
+
+// ShellStreamExecutor is an optional extension of ShellExecutor for executors
+// that can report output while a command is still running
+type ShellStreamExecutor interface {
+	ShellExecutor
+	// ExecuteCommandStreaming executes a command, passing output chunks to
+	// onOutput as they arrive, and returns the full output once it completes
+	ExecuteCommandStreaming(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string, env map[string]string, onOutput actor.ShellOutputFunc) (stdout string, stderr string, exitCode int, err error)
+}
+
+// ShellStdinFileExecutor is an optional extension of ShellExecutor for
+// executors that can pass an open file to a command as stdin
+type ShellStdinFileExecutor interface {
+	ShellExecutor
+	// ExecuteCommandWithStdinFile executes a command reading stdin from
+	// stdinFile, passing output chunks to onOutput if it is not nil
+	ExecuteCommandWithStdinFile(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdinFile *os.File, env map[string]string, onOutput actor.ShellOutputFunc) (stdout string, stderr string, exitCode int, err error)
+}
+
 // Note: The actor.ShellActor interface already matches this signature perfectly,
 // so we can use it directly as a ShellExecutor without needing an adapter
 
@@ -36,9 +62,11 @@ type SandboxTool struct {
 	session             *session.Session
 	authorizer          Authorizer
 	tinygoManager       *TinyGoManager
+	tinygoErr           error // Why the TinyGo manager could not be initialized
 	summarizeClient     llm.Client
 	shellExecutor       ShellExecutor
 	progressCb          progress.Callback
+	outputRedactor      *secretdetect.Redactor // masks secrets in streamed command output
 	detector            secretdetect.Detector
 	featureFlags        FeatureFlagsProvider                // Interface to check feature flags
 	compactor           *OutputCompactor                    // Output compaction handler
@@ -48,31 +76,37 @@ type SandboxTool struct {
 	authConfig          AuthorizationPersistenceConfig      // Config for persisting authorized commands/domains
 	parentCtx           context.Context                     // Parent context without sandbox timeout, used for user interaction
 	deadline            ExecDeadline                        // Pausable execution deadline, paused during user interaction
+	maxTimeout          int                                 // Upper bound for per-call timeouts in seconds (0 = MaxToolTimeoutSeconds)
+	modules             []sandboxModule                     // Third-party modules programs may import (nil = DefaultSandboxModules)
 }
 
-func NewSandboxTool(workingDir, tempDir string) *SandboxTool {
+// tinyGoInstallHint tells users how to make TinyGo available to the sandbox
+const tinyGoInstallHint = "install TinyGo (https://tinygo.org/getting-started/install/) and make sure `tinygo` is on PATH"
+
+// newSandboxTinyGoManager creates the TinyGo manager used for WASI compilation.
+// A failure is logged and reported by Available when the tool is called.
+func newSandboxTinyGoManager() (*TinyGoManager, error) {
 	tinygoMgr, err := NewTinyGoManager()
 	if err != nil {
-		// Log error - TinyGo is required for WASI compilation
-		fmt.Fprintf(os.Stderr, "Error: Failed to initialize TinyGo manager: %v\n", err)
-		fmt.Fprintf(os.Stderr, "The %s tool will not be available until this is resolved.\n", ToolNameGoSandbox)
+		logger.Warn("sandbox: failed to initialize TinyGo manager, %s will be unavailable: %v", ToolNameGoSandbox, err)
 	}
+	return tinygoMgr, err
+}
+
+func NewSandboxTool(workingDir, tempDir string) *SandboxTool {
+	tinygoMgr, err := newSandboxTinyGoManager()
 
 	return &SandboxTool{
 		workingDir:    workingDir,
 		tempDir:       tempDir,
 		tinygoManager: tinygoMgr,
+		tinygoErr:     err,
 	}
 }
 
 // NewSandboxToolWithFS creates a sandbox with filesystem and session support
 func NewSandboxToolWithFS(workingDir, tempDir string, filesystem fs.FileSystem, sess *session.Session, shellExecutor ShellExecutor) *SandboxTool {
-	tinygoMgr, err := NewTinyGoManager()
-	if err != nil {
-		// Log error - TinyGo is required for WASI compilation
-		fmt.Fprintf(os.Stderr, "Error: Failed to initialize TinyGo manager: %v\n", err)
-		fmt.Fprintf(os.Stderr, "The %s tool will not be available until this is resolved.\n", ToolNameGoSandbox)
-	}
+	tinygoMgr, err := newSandboxTinyGoManager()
 
 	return &SandboxTool{
 		workingDir:    workingDir,
@@ -80,6 +114,7 @@ func NewSandboxToolWithFS(workingDir, tempDir string, filesystem fs.FileSystem,
 		filesystem:    filesystem,
 		session:       sess,
 		tinygoManager: tinygoMgr,
+		tinygoErr:     err,
 		shellExecutor: shellExecutor,
 	}
 }
@@ -109,11 +144,46 @@ func (t *SandboxTool) SetProgressCallback(cb progress.Callback) {
 	t.progressCb = cb
 }
 
+// SetOutputRedactor sets the redactor applied to command output streamed as
+// status. Pass nil to disable.
+func (t *SandboxTool) SetOutputRedactor(redactor *secretdetect.Redactor) {
+	t.outputRedactor = redactor
+}
+
 // SetShellExecutor sets the shell executor for command execution
 func (t *SandboxTool) SetShellExecutor(executor ShellExecutor) {
 	t.shellExecutor = executor
 }
 
+// SetMaxTimeout sets the upper bound for the per-call timeout in seconds.
+// Values outside 1..MaxToolTimeoutSeconds fall back to MaxToolTimeoutSeconds.
+func (t *SandboxTool) SetMaxTimeout(seconds int) {
+	if seconds <= 0 || seconds > MaxToolTimeoutSeconds {
+		seconds = MaxToolTimeoutSeconds
+	}
+	t.maxTimeout = seconds
+}
+
+// resolveTimeout returns the execution timeout in seconds for a call.
+// timeout_seconds takes precedence over the legacy timeout parameter and the
+// result is clamped to 1..maxTimeout.
+func (t *SandboxTool) resolveTimeout(params map[string]interface{}) int {
+	maxTimeout := t.maxTimeout
+	if maxTimeout <= 0 {
+		maxTimeout = MaxToolTimeoutSeconds
+	}
+
+	timeout := GetIntParam(params, "timeout", DefaultSandboxTimeoutSeconds)
+	timeout = GetIntParam(params, "timeout_seconds", timeout)
+	if timeout <= 0 {
+		timeout = DefaultSandboxTimeoutSeconds
+	}
+	if timeout > maxTimeout {
+		timeout = maxTimeout
+	}
+	return timeout
+}
+
 // SetCompactionConfig sets the output compaction configuration
 func (t *SandboxTool) SetCompactionConfig(compactionConfig config.SandboxOutputCompactionConfig) {
 	t.compactor = NewOutputCompactor(compactionConfig, t.contextWindow)
@@ -260,6 +330,18 @@ func (t *SandboxTool) Move(src, dst string) error {
 	return t.filesystem.Move(context.Background(), src, dst)
 }
 
+// Available reports whether the sandbox can compile programs. Otherwise it
+// returns an error that explains how to make TinyGo available.
+func (t *SandboxTool) Available() error {
+	if t.tinygoManager != nil {
+		return nil
+	}
+	if t.tinygoErr != nil {
+		return fmt.Errorf("%s unavailable: TinyGo not installed (%v); %s", ToolNameGoSandbox, t.tinygoErr, tinyGoInstallHint)
+	}
+	return fmt.Errorf("%s unavailable: TinyGo not installed; %s", ToolNameGoSandbox, tinyGoInstallHint)
+}
+
 // GetTinyGoManager returns the TinyGo manager instance (can be nil)
 func (t *SandboxTool) GetTinyGoManager() *TinyGoManager {
 	return t.tinygoManager
@@ -273,6 +355,7 @@ func (t *SandboxTool) Description() string {
 	var b strings.Builder
 	b.WriteString("Execute Go code in a strongly sandboxed WebAssembly environment. ")
 	b.WriteString("Basic standard library packages available (don't use the `os`, `ioutil, `net`, `exec` package instead use methods provided below). Timeout enforced.\n\n")
+	fmt.Fprintf(&b, "Besides the standard library, only these approved third-party modules can be imported: %s; other imports are rejected.\n\n", describeSandboxModules(t.allowedModules()))
 	b.WriteString("Every program **must** declare `package main`, define `func main()`, and print results (e.g., via `fmt.Println`) so the orchestrator receives the output.\n\n")
 	b.WriteString("**Description Field**: Use the `description` parameter to provide a human-readable explanation of what your sandbox code does. This description will be displayed in the TUI and Web UI to explain what you're doing to the user.\n\n")
 	b.WriteString("Try to reduce the output of shell programs by e.g. only searching and outputting errors.\n\n")
@@ -321,10 +404,14 @@ func (t *SandboxTool) Description() string {
 	b.WriteString("     }\n")
 	b.WriteString("     ```\n\n")
 
-	b.WriteString("2. ExecuteCommand(command []string, stdin string) (stdout string, stderr string, exitCode int)\n")
+	b.WriteString("2. ExecuteCommand(command []string, stdin string, opts ...CommandOptions) (stdout string, stderr string, exitCode int)\n")
 	b.WriteString("   - Execute shell commands with optional stdin input\n")
 	b.WriteString("   - Requires command authorization\n")
 	b.WriteString("   - Pass empty string for stdin if not needed\n")
+	b.WriteString("   - Optional CommandOptions{WorkingDir: \"sub/dir\", Env: map[string]string{\"KEY\": \"value\"}} runs the command in a\n")
+	b.WriteString("     directory relative to the workspace (must stay inside it) with extra environment variables\n")
+	b.WriteString("   - ExecuteCommandWithStdinFile(command []string, stdinPath string, opts ...CommandOptions) pipes a workspace file\n")
+	b.WriteString("     into stdin without loading it into the sandbox; prefer it for large inputs\n")
 	b.WriteString("   - Examples:\n")
 	b.WriteString("     ```go\n")
 	b.WriteString("     package main\n\n")
@@ -334,10 +421,14 @@ func (t *SandboxTool) Description() string {
 	b.WriteString("         fmt.Printf(\"ls output: %s, stderr: %s, exit: %d\\n\", out, err, code)\n\n")
 	b.WriteString("         out, err, code = ExecuteCommand([]string{\"grep\", \"pattern\"}, \"line1\\nline2\\npattern here\\n\")\n")
 	b.WriteString("         fmt.Printf(\"grep output: %s, stderr: %s, exit: %d\\n\", out, err, code)\n\n")
+	b.WriteString("         out, err, code = ExecuteCommandWithStdinFile([]string{\"grep\", \"ERROR\"}, \"logs/server.log\")\n")
+	b.WriteString("         fmt.Printf(\"grep output: %s, stderr: %s, exit: %d\\n\", out, err, code)\n\n")
 	b.WriteString("         out, err, code = ExecuteCommand([]string{\"go\", \"build\", \"./cmd/statcode-ai\"}, \"\")\n")
 	b.WriteString("         fmt.Printf(\"go build output: %s, stderr: %s, exit: %d\\n\", out, err, code)\n\n")
 	b.WriteString("         _, err, code = ExecuteCommand([]string{\"mkdir\", \"-p\", \"tmp/build/cache\"}, \"\")\n")
-	b.WriteString("         fmt.Printf(\"mkdir stderr: %s, exit: %d\\n\", err, code)\n")
+	b.WriteString("         fmt.Printf(\"mkdir stderr: %s, exit: %d\\n\", err, code)\n\n")
+	b.WriteString("         out, err, code = ExecuteCommand([]string{\"go\", \"test\", \"./...\"}, \"\", CommandOptions{WorkingDir: \"services/api\", Env: map[string]string{\"CGO_ENABLED\": \"0\"}})\n")
+	b.WriteString("         fmt.Printf(\"go test output: %s, stderr: %s, exit: %d\\n\", out, err, code)\n")
 	b.WriteString("     }\n")
 	b.WriteString("     ```\n\n")
 
@@ -648,6 +739,11 @@ func (t *SandboxTool) Description() string {
 }
 
 func (t *SandboxTool) Parameters() map[string]interface{} {
+	maxTimeout := t.maxTimeout
+	if maxTimeout <= 0 {
+		maxTimeout = MaxToolTimeoutSeconds
+	}
+
 	return map[string]interface{}{
 		"type": "object",
 		"properties": map[string]interface{}{
@@ -655,9 +751,13 @@ func (t *SandboxTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "Go code to execute. Must include package main and func main()",
 			},
+			"timeout_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Timeout in seconds for this execution (default %d, max %d). Increase it for testsuites or compute intensive tasks, lower it for quick scripts.", DefaultSandboxTimeoutSeconds, maxTimeout),
+			},
 			"timeout": map[string]interface{}{
 				"type":        "integer",
-				"description": "Timeout in seconds (default 30, max 3600). When running testsuites or compute intensive tasks, consider increasing the timeout.",
+				"description": "Deprecated alias for timeout_seconds.",
 			},
 			"working_dir": map[string]interface{}{
 				"type":        "string",
@@ -690,12 +790,13 @@ func (t *SandboxTool) Execute(ctx context.Context, params map[string]interface{}
 		return &ToolResult{Error: "code is required"}
 	}
 
+	if err := t.Available(); err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
 	logger.Debug("go_sandbox code:\n%s", code)
 
-	timeout := GetIntParam(params, "timeout", 30)
-	if timeout > 3600 {
-		timeout = 3600
-	}
+	timeout := t.resolveTimeout(params)
 
 	// Get libraries if specified
 	var libraries []string
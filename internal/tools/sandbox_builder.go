@@ -137,7 +137,7 @@ type SandboxBuilder struct {
 //	A new SandboxBuilder instance ready for configuration
 func NewSandboxBuilder() *SandboxBuilder {
 	return &SandboxBuilder{
-		timeout:    30, // Default timeout
+		timeout:    DefaultSandboxTimeoutSeconds,
 		libraries:  make([]string, 0),
 		workingDir: ".",
 		tempDir:    "/tmp",
@@ -294,8 +294,16 @@ func (b *SandboxBuilder) AddLibraries(libraries ...string) *SandboxBuilder {
 	return b
 }
 
+// DefaultSandboxTimeoutSeconds is the execution timeout used when none is given
+const DefaultSandboxTimeoutSeconds = 30
+
+// MaxTimeoutSeconds is the hard upper bound for sandbox execution timeouts
 const MaxTimeoutSeconds = 600
 
+// MaxToolTimeoutSeconds is the upper bound for the per-call timeout of
+// go_sandbox tool executions
+const MaxToolTimeoutSeconds = 3600
+
 // SetTimeout sets the execution timeout in seconds.
 //
 // The timeout applies to the total execution time including:
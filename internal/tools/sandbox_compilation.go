@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -30,6 +31,12 @@ func (t *SandboxTool) executeInternal(ctx context.Context, builder *SandboxBuild
 	timeout := builder.timeout
 	libraries := builder.libraries
 
+	// Only the standard library and allowlisted modules may be imported
+	usedModules, err := checkSandboxImports(code, t.allowedModules())
+	if err != nil {
+		return sandboxModuleCompileError(err), nil
+	}
+
 	// Create temporary directory for sandbox
 	sandboxDir := filepath.Join(t.tempDir, fmt.Sprintf("sandbox_%d", time.Now().UnixNano()))
 	if err := os.MkdirAll(sandboxDir, 0755); err != nil {
@@ -54,12 +61,17 @@ func (t *SandboxTool) executeInternal(ctx context.Context, builder *SandboxBuild
 	if err := os.WriteFile(mainFile, []byte(wrappedCode), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write code file: %w", err)
 	}
+	if len(usedModules) > 0 {
+		if err := writeSandboxGoMod(sandboxDir, usedModules); err != nil {
+			return nil, fmt.Errorf("failed to write go.mod: %w", err)
+		}
+	}
 
 	// Get TinyGo binary path (downloads if necessary)
 	// TinyGo is REQUIRED for wasip2 support - standard Go only supports wasip1
 	// Use a separate context with longer timeout for downloading TinyGo (~50MB)
-	if t.tinygoManager == nil {
-		return nil, fmt.Errorf("TinyGo manager not initialized - cannot compile WASM code")
+	if err := t.Available(); err != nil {
+		return nil, err
 	}
 
 	// Use parent context for TinyGo download (not the execution timeout)
@@ -68,7 +80,7 @@ func (t *SandboxTool) executeInternal(ctx context.Context, builder *SandboxBuild
 	tinyGoBinary, err := t.tinygoManager.GetTinyGoBinary(downloadCtx)
 	downloadCancel()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get TinyGo binary (required for WASI P2 compilation): %w", err)
+		return nil, fmt.Errorf("%s unavailable: TinyGo could not be found or downloaded (%w); %s", ToolNameGoSandbox, err, tinyGoInstallHint)
 	}
 
 	// Create cancellable context for compilation and execution.
@@ -145,6 +157,15 @@ func (t *SandboxTool) compileWithTinyGo(ctx context.Context, tinyGoBinary string
 	// don't fail TinyGo's version check.
 	env := t.buildSandboxEnv()
 	env = sandboxReplaceOrAppendEnv(env, "GOTOOLCHAIN", "go1.25.1+auto")
+	// Programs importing allowlisted modules come with a go.mod and a go.sum
+	// pinning their checksums; resolve the modules into the shared module
+	// cache next to TinyGo without adding unpinned modules
+	if _, err := os.Stat(filepath.Join(sandboxDir, "go.mod")); err == nil {
+		env = sandboxReplaceOrAppendEnv(env, "GOFLAGS", "-mod=readonly")
+		if t.tinygoManager != nil {
+			env = sandboxReplaceOrAppendEnv(env, "GOMODCACHE", t.tinygoManager.ModuleCacheDir())
+		}
+	}
 	buildCmd.Env = env
 
 	buildOutput, err := buildCmd.CombinedOutput()
@@ -164,16 +185,20 @@ func (t *SandboxTool) compileWithTinyGo(ctx context.Context, tinyGoBinary string
 
 // executeDirectCommand executes a command directly without using the shell executor
 // This is a fallback when no shell executor is configured
-func (t *SandboxTool) executeDirectCommand(ctx context.Context, commandArgs []string, stdinData []byte) (string, string, int) {
+func (t *SandboxTool) executeDirectCommand(ctx context.Context, commandArgs []string, stdin io.Reader, workingDir string, extraEnv map[string]string) (string, string, int) {
 	cmdCtx, cancel := context.WithTimeout(ctx, consts.Timeout30)
 	defer cancel()
 
 	cmd := exec.CommandContext(cmdCtx, commandArgs[0], commandArgs[1:]...)
+	cmd.Dir = workingDir
 	cmd.Env = t.buildSandboxEnv()
+	for key, value := range extraEnv {
+		cmd.Env = sandboxReplaceOrAppendEnv(cmd.Env, key, value)
+	}
 
 	// Set stdin if provided
-	if len(stdinData) > 0 {
-		cmd.Stdin = bytes.NewReader(stdinData)
+	if stdin != nil {
+		cmd.Stdin = stdin
 	}
 
 	// Capture stdout and stderr
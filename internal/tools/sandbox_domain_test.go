@@ -1,7 +1,14 @@
 package tools
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
 )
 
 func TestExtractDomainsFromCode(t *testing.T) {
@@ -198,3 +205,40 @@ func main() {
 		})
 	}
 }
+
+func TestRememberApprovedDomainSessionOnly(t *testing.T) {
+	ctx := context.Background()
+	sess := session.NewSession("test", t.TempDir())
+	cfg := config.DefaultConfig()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	tool := NewSandboxToolWithFS(sess.WorkingDir, "", fs.NewMockFS(), sess, nil)
+	tool.SetAuthorizationPersistence(cfg, configPath)
+	authorizer := NewAuthorizationActor("auth", fs.NewMockFS(), sess, nil, nil)
+
+	decision, err := authorizer.authorizeSandboxDomain(ctx, map[string]interface{}{"domain": "api.example.com"})
+	if err != nil || decision.Allowed {
+		t.Fatalf("expected unapproved domain to be denied, got %+v (err %v)", decision, err)
+	}
+
+	tool.rememberApprovedDomain("api.example.com", true)
+
+	decision, err = authorizer.authorizeSandboxDomain(ctx, map[string]interface{}{"domain": "api.example.com"})
+	if err != nil || !decision.Allowed {
+		t.Fatalf("expected session-approved domain to be allowed, got %+v (err %v)", decision, err)
+	}
+	if cfg.IsDomainAuthorized("api.example.com") {
+		t.Error("session-only approval must not be added to the configuration")
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Errorf("session-only approval must not save the configuration, stat err: %v", err)
+	}
+
+	tool.rememberApprovedDomain("docs.example.com", false)
+	if !cfg.IsDomainAuthorized("docs.example.com") {
+		t.Error("permanent approval should be added to the configuration")
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		t.Errorf("permanent approval should save the configuration: %v", err)
+	}
+}
@@ -1,11 +1,14 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/tetratelabs/wazero"
@@ -29,9 +32,9 @@ func (t *SandboxTool) registerFetchHostFunction(envBuilder wazero.HostModuleBuil
 
 // registerShellHostFunction registers the shell/execute command host function
 func (t *SandboxTool) registerShellHostFunction(envBuilder wazero.HostModuleBuilder, adapter *wasiAuthorizerAdapter, tracker *sandboxCallTracker) {
-	// shell(command_json_ptr, command_json_len, stdin_ptr, stdin_len, stdout_ptr, stdout_cap, stderr_ptr, stderr_cap) -> exit_code
+	// shell(command_json_ptr, command_json_len, stdin_ptr, stdin_len, options_json_ptr, options_json_len, stdout_ptr, stdout_cap, stderr_ptr, stderr_cap) -> exit_code
 	envBuilder.NewFunctionBuilder().
-		WithFunc(func(ctx context.Context, m api.Module, cmdPtr, cmdLen, stdinPtr, stdinLen, stdoutPtr, stdoutCap, stderrPtr, stderrCap uint32) int32 {
+		WithFunc(func(ctx context.Context, m api.Module, cmdPtr, cmdLen, stdinPtr, stdinLen, optsPtr, optsLen, stdoutPtr, stdoutCap, stderrPtr, stderrCap uint32) int32 {
 			// writeStderr writes an error message to the stderr WASM buffer
 			writeStderr := func(memory api.Memory, msg string) {
 				msgBytes := []byte(msg)
@@ -59,7 +62,6 @@ func (t *SandboxTool) registerShellHostFunction(envBuilder wazero.HostModuleBuil
 				writeStderr(memory, "Error: command must include at least one argument")
 				return -1
 			}
-			commandDisplay := strings.Join(commandArgs, " ")
 
 			// Read stdin from WASM memory (if provided)
 			var stdinData []byte
@@ -71,12 +73,49 @@ func (t *SandboxTool) registerShellHostFunction(envBuilder wazero.HostModuleBuil
 				}
 			}
 
+			// Read command options from WASM memory (if provided)
+			var opts sandboxCommandOptions
+			if optsLen > 0 {
+				optsBytes, ok := memory.Read(optsPtr, optsLen)
+				if !ok {
+					writeStderr(memory, "Error: failed to read command options from memory")
+					return -1
+				}
+				if err := json.Unmarshal(optsBytes, &opts); err != nil {
+					writeStderr(memory, fmt.Sprintf("Error: failed to parse command options JSON: %v", err))
+					return -1
+				}
+			}
+			if err := opts.validate(); err != nil {
+				writeStderr(memory, fmt.Sprintf("Error: %v", err))
+				return -1
+			}
+			workingDir, err := t.resolveCommandWorkingDir(opts.WorkingDir)
+			if err != nil {
+				writeStderr(memory, fmt.Sprintf("Error: %v", err))
+				return -1
+			}
+			var stdinPath string
+			if opts.StdinFile != "" {
+				if len(stdinData) > 0 {
+					writeStderr(memory, "Error: stdin and a stdin file cannot be combined")
+					return -1
+				}
+				stdinPath, err = t.resolveCommandStdinFile(opts.StdinFile)
+				if err != nil {
+					writeStderr(memory, fmt.Sprintf("Error: %v", err))
+					return -1
+				}
+				if err := authorizeCommandStdinFile(ctx, adapter, tracker, stdinPath); err != nil {
+					writeStderr(memory, fmt.Sprintf("Error: %v", err))
+					return -1
+				}
+			}
+
 			// Check authorization for command
+			authParams, commandDisplay := describeSandboxCommand(commandArgs, workingDir, opts)
 			if adapter != nil && adapter.authorizer != nil {
-				decision, err := adapter.Authorize(ctx, ToolNameCommand, map[string]interface{}{
-					"command":      commandDisplay,
-					"command_args": commandArgs,
-				})
+				decision, err := adapter.Authorize(ctx, ToolNameCommand, authParams)
 				if err != nil {
 					writeStderr(memory, fmt.Sprintf("Error: command authorization failed: %v", err))
 					return -1
@@ -99,10 +138,7 @@ func (t *SandboxTool) registerShellHostFunction(envBuilder wazero.HostModuleBuil
 						resp, err := uiClient.RequestAuthorization(
 							t.interactionCtx(ctx),
 							ToolNameCommand,
-							map[string]interface{}{
-								"command":      commandDisplay,
-								"command_args": commandArgs,
-							},
+							authParams,
 							decision.Reason,
 							decision.SuggestedCommandPrefix,
 							tabID,
@@ -159,22 +195,18 @@ func (t *SandboxTool) registerShellHostFunction(envBuilder wazero.HostModuleBuil
 
 			tracker.record("shell", commandDisplay)
 
-			// Execute the command using the shell executor
 			var stdoutStr, stderrStr string
 			var exitCode int
-
-			if t.shellExecutor != nil {
-				// Use the shell executor (actor-based) with direct argv execution
-				var err error
-				stdoutStr, stderrStr, exitCode, err = t.shellExecutor.ExecuteCommand(ctx, commandArgs, "", consts.Timeout30, string(stdinData))
-				// When the executor returns an error (e.g. command not found, timeout)
-				// and stderr is empty, include the error message so the caller gets context
-				if err != nil && stderrStr == "" {
-					stderrStr = fmt.Sprintf("Error: %v", err)
+			if stdinPath != "" {
+				stdinFile, err := os.Open(stdinPath)
+				if err != nil {
+					writeStderr(memory, fmt.Sprintf("Error: failed to open stdin file %s: %v", opts.StdinFile, err))
+					return -1
 				}
+				stdoutStr, stderrStr, exitCode = t.runSandboxCommandWithStdinFile(ctx, commandArgs, stdinFile, workingDir, opts.Env)
+				stdinFile.Close()
 			} else {
-				// Fallback to direct execution if no executor is set
-				stdoutStr, stderrStr, exitCode = t.executeDirectCommand(ctx, commandArgs, stdinData)
+				stdoutStr, stderrStr, exitCode = t.runSandboxCommand(ctx, commandArgs, stdinData, workingDir, opts.Env)
 			}
 
 			// Record activity after command execution completes
@@ -206,6 +238,242 @@ func (t *SandboxTool) registerShellHostFunction(envBuilder wazero.HostModuleBuil
 		Export(ToolNameCommand)
 }
 
+// sandboxCommandOptions are the optional per-call settings of ExecuteCommand
+type sandboxCommandOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	StdinFile  string            `json:"stdin_file,omitempty"` // Workspace file streamed to stdin instead of stdin from memory
+}
+
+// validate rejects environment variable names that cannot be passed to a process
+func (o sandboxCommandOptions) validate() error {
+	for key := range o.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+	return nil
+}
+
+// describeSandboxCommand returns the authorization parameters of a command and
+// the description shown to the user. Environment variables, the stdin file and
+// a non-default working directory change what a command does, so they are part
+// of both; the environment is prefixed so command prefix approvals like "go"
+// do not silently cover "GOFLAGS=... go".
+func describeSandboxCommand(commandArgs []string, workingDir string, opts sandboxCommandOptions) (map[string]interface{}, string) {
+	params := map[string]interface{}{
+		"command_args": commandArgs,
+	}
+
+	var display strings.Builder
+	if len(opts.Env) > 0 {
+		keys := make([]string, 0, len(opts.Env))
+		for key := range opts.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&display, "%s=%s ", key, opts.Env[key])
+		}
+		params["env"] = opts.Env
+	}
+	display.WriteString(strings.Join(commandArgs, " "))
+	if opts.StdinFile != "" {
+		fmt.Fprintf(&display, " < %s", opts.StdinFile)
+		params["stdin_file"] = opts.StdinFile
+	}
+	if opts.WorkingDir != "" {
+		fmt.Fprintf(&display, " (in %s)", workingDir)
+		params["working_dir"] = workingDir
+	}
+
+	params["command"] = display.String()
+	return params, display.String()
+}
+
+// commandWorkspaceRoot returns the absolute workspace root that command paths
+// are resolved against
+func (t *SandboxTool) commandWorkspaceRoot() (string, error) {
+	root := t.workingDir
+	if root == "" && t.session != nil {
+		root = t.session.WorkingDir
+	}
+	if root == "" {
+		root = "."
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	return rootAbs, nil
+}
+
+// isWithinRoot reports whether the absolute path lies inside root
+func isWithinRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolveCommandWorkingDir resolves a command working directory relative to the
+// sandbox working directory and rejects paths outside of it, also after
+// resolving symlinks. An empty dir is returned unchanged so the executor keeps
+// its default.
+func (t *SandboxTool) resolveCommandWorkingDir(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", nil
+	}
+
+	rootAbs, err := t.commandWorkspaceRoot()
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(rootAbs, dir)
+	}
+	dirAbs := filepath.Clean(dir)
+
+	if !isWithinRoot(rootAbs, dirAbs) {
+		return "", fmt.Errorf("working directory %s is outside of the workspace (%s)", dirAbs, rootAbs)
+	}
+
+	resolved, err := filepath.EvalSymlinks(dirAbs)
+	if err != nil {
+		return "", fmt.Errorf("working directory %s does not exist", dirAbs)
+	}
+	if rootResolved, err := filepath.EvalSymlinks(rootAbs); err == nil && !isWithinRoot(rootResolved, resolved) {
+		return "", fmt.Errorf("working directory %s is outside of the workspace (%s)", dirAbs, rootAbs)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("working directory %s does not exist", dirAbs)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working directory %s is not a directory", dirAbs)
+	}
+
+	return resolved, nil
+}
+
+// resolveCommandStdinFile resolves a workspace file to pass as stdin of a
+// command. The path is relative to the workspace root and must stay inside it,
+// also after resolving symlinks. The file is opened on the host and handed to
+// the process, so its content is never copied into memory.
+func (t *SandboxTool) resolveCommandStdinFile(path string) (string, error) {
+	rootAbs, err := t.commandWorkspaceRoot()
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(rootAbs, path)
+	}
+	pathAbs := filepath.Clean(path)
+	if !isWithinRoot(rootAbs, pathAbs) {
+		return "", fmt.Errorf("stdin file %s is outside of the workspace (%s)", pathAbs, rootAbs)
+	}
+
+	resolved, err := filepath.EvalSymlinks(pathAbs)
+	if err != nil {
+		return "", fmt.Errorf("stdin file %s does not exist", pathAbs)
+	}
+	if rootResolved, err := filepath.EvalSymlinks(rootAbs); err == nil && !isWithinRoot(rootResolved, resolved) {
+		return "", fmt.Errorf("stdin file %s is outside of the workspace (%s)", pathAbs, rootAbs)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("stdin file %s does not exist", pathAbs)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("stdin file %s is a directory", pathAbs)
+	}
+	return resolved, nil
+}
+
+// authorizeCommandStdinFile checks that the stdin file may be read, as if the
+// code had called read_file, and records the read
+func authorizeCommandStdinFile(ctx context.Context, adapter *wasiAuthorizerAdapter, tracker *sandboxCallTracker, path string) error {
+	if adapter != nil && adapter.authorizer != nil {
+		decision, err := adapter.Authorize(ctx, ToolNameReadFile, map[string]interface{}{"path": path})
+		if err != nil {
+			return fmt.Errorf("stdin file authorization failed: %w", err)
+		}
+		if decision == nil || !decision.Allowed {
+			reason := "not authorized"
+			if decision != nil && decision.Reason != "" {
+				reason = decision.Reason
+			}
+			return fmt.Errorf("reading stdin file %s was denied: %s", path, reason)
+		}
+	}
+	tracker.record("read_file", path)
+	return nil
+}
+
+// runSandboxCommand executes a command for the sandbox, using the shell executor
+// if one is configured
+func (t *SandboxTool) runSandboxCommand(ctx context.Context, commandArgs []string, stdinData []byte, workingDir string, env map[string]string) (string, string, int) {
+	if t.shellExecutor == nil {
+		// Fallback to direct execution if no executor is set
+		var stdin io.Reader
+		if len(stdinData) > 0 {
+			stdin = bytes.NewReader(stdinData)
+		}
+		return t.executeDirectCommand(ctx, commandArgs, stdin, workingDir, env)
+	}
+
+	var stdoutStr, stderrStr string
+	var exitCode int
+	var err error
+	if streamer, ok := t.shellExecutor.(ShellStreamExecutor); ok && t.progressCb != nil {
+		// Show the command's output as status while it runs
+		stdoutStr, stderrStr, exitCode, err = streamer.ExecuteCommandStreaming(ctx, commandArgs, workingDir, consts.Timeout30, string(stdinData), env, ShellOutputStatus(t.progressCb, t.outputRedactor))
+	} else if len(env) > 0 {
+		envExecutor, ok := t.shellExecutor.(ShellEnvExecutor)
+		if !ok {
+			return "", "Error: environment variables are not supported by the shell executor", -1
+		}
+		stdoutStr, stderrStr, exitCode, err = envExecutor.ExecuteCommandWithEnv(ctx, commandArgs, workingDir, consts.Timeout30, string(stdinData), env)
+	} else {
+		// Use the shell executor (actor-based) with direct argv execution
+		stdoutStr, stderrStr, exitCode, err = t.shellExecutor.ExecuteCommand(ctx, commandArgs, workingDir, consts.Timeout30, string(stdinData))
+	}
+	// When the executor returns an error (e.g. command not found, timeout)
+	// and stderr is empty, include the error message so the caller gets context
+	if err != nil && stderrStr == "" {
+		stderrStr = fmt.Sprintf("Error: %v", err)
+	}
+	return stdoutStr, stderrStr, exitCode
+}
+
+// runSandboxCommandWithStdinFile executes a command for the sandbox with an
+// open file as stdin. Executors that cannot take a file are not given its
+// content instead, so the command fails with an error.
+func (t *SandboxTool) runSandboxCommandWithStdinFile(ctx context.Context, commandArgs []string, stdinFile *os.File, workingDir string, env map[string]string) (string, string, int) {
+	if t.shellExecutor == nil {
+		return t.executeDirectCommand(ctx, commandArgs, stdinFile, workingDir, env)
+	}
+
+	fileExecutor, ok := t.shellExecutor.(ShellStdinFileExecutor)
+	if !ok {
+		return "", "Error: stdin files are not supported by the shell executor", -1
+	}
+	var onOutput actor.ShellOutputFunc
+	if t.progressCb != nil {
+		onOutput = ShellOutputStatus(t.progressCb, t.outputRedactor)
+	}
+	stdoutStr, stderrStr, exitCode, err := fileExecutor.ExecuteCommandWithStdinFile(ctx, commandArgs, workingDir, consts.Timeout30, stdinFile, env, onOutput)
+	if err != nil && stderrStr == "" {
+		stderrStr = fmt.Sprintf("Error: %v", err)
+	}
+	return stdoutStr, stderrStr, exitCode
+}
+
 // registerSummarizeHostFunction registers the summarize host function
 func (t *SandboxTool) registerSummarizeHostFunction(envBuilder wazero.HostModuleBuilder, tracker *sandboxCallTracker) {
 	// summarize(prompt_ptr, prompt_len, text_ptr, text_len, result_ptr, result_cap) -> status_code
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// DefaultSandboxModules are the vetted third-party modules go_sandbox programs
+// may import when no allowlist is configured. Entries are
+// "path@version h1:<module hash> h1:<go.mod hash>", with the hashes of the
+// module's go.sum lines.
+var DefaultSandboxModules = []string{
+	"gopkg.in/yaml.v3@v3.0.1 h1:fxVm/GzAzEWqLHuvctI91KS9hhNmmWOoWu0XTYJS7CA= h1:K4uyk7z7BCEPqu6E+C64Yfv1cQ7kz7rIZviUmN+EgEM=",
+	"github.com/BurntSushi/toml@v1.4.0 h1:kuoIxZQy2WRRk1pttg9asf+WVv6tWQuBNVmK8+nqPr0= h1:ukJfTF/6rtPPRCnwkur4qwRxa8vTRFBF0uk2lLoLwho=",
+}
+
+// sandboxModule is a third-party module go_sandbox programs may import, pinned
+// to the checksums of its go.sum lines
+type sandboxModule struct {
+	Path     string
+	Version  string
+	Sum      string // Hash of the module contents
+	GoModSum string // Hash of the module's go.mod
+}
+
+// parseSandboxModule parses a "path@version h1:<module hash> h1:<go.mod hash>"
+// allowlist entry
+func parseSandboxModule(entry string) (sandboxModule, error) {
+	fields := strings.Fields(entry)
+	if len(fields) != 3 {
+		return sandboxModule{}, fmt.Errorf("invalid sandbox module %q: expected path@version h1:<module hash> h1:<go.mod hash>", entry)
+	}
+	at := strings.LastIndex(fields[0], "@")
+	if at <= 0 || at == len(fields[0])-1 {
+		return sandboxModule{}, fmt.Errorf("invalid sandbox module %q: expected path@version", entry)
+	}
+	mod := sandboxModule{Path: fields[0][:at], Version: fields[0][at+1:], Sum: fields[1], GoModSum: fields[2]}
+	if !strings.Contains(strings.SplitN(mod.Path, "/", 2)[0], ".") {
+		return sandboxModule{}, fmt.Errorf("invalid sandbox module %q: module paths start with a domain", entry)
+	}
+	if !strings.HasPrefix(mod.Version, "v") {
+		return sandboxModule{}, fmt.Errorf("invalid sandbox module %q: versions start with v", entry)
+	}
+	if !strings.HasPrefix(mod.Sum, "h1:") || !strings.HasPrefix(mod.GoModSum, "h1:") {
+		return sandboxModule{}, fmt.Errorf("invalid sandbox module %q: checksums start with h1:", entry)
+	}
+	return mod, nil
+}
+
+// SetAllowedModules sets the third-party modules programs may import, in the
+// format of DefaultSandboxModules. An empty list selects DefaultSandboxModules.
+func (t *SandboxTool) SetAllowedModules(entries []string) {
+	if len(entries) == 0 {
+		t.modules = nil
+		return
+	}
+	t.modules = parseSandboxModules(entries)
+}
+
+// allowedModules returns the configured module allowlist
+func (t *SandboxTool) allowedModules() []sandboxModule {
+	if t.modules == nil {
+		return parseSandboxModules(DefaultSandboxModules)
+	}
+	return t.modules
+}
+
+// parseSandboxModules parses allowlist entries; invalid entries are skipped
+// with a warning
+func parseSandboxModules(entries []string) []sandboxModule {
+	modules := make([]sandboxModule, 0, len(entries))
+	for _, entry := range entries {
+		mod, err := parseSandboxModule(entry)
+		if err != nil {
+			logger.Warn("sandbox: %v", err)
+			continue
+		}
+		modules = append(modules, mod)
+	}
+	return modules
+}
+
+// isStdlibImport reports whether an import path belongs to the standard
+// library, whose paths have no domain in their first element
+func isStdlibImport(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+// checkSandboxImports verifies that code only imports the standard library and
+// allowlisted modules. It returns the allowlisted modules the code uses, or an
+// error naming the rejected import. Code that does not parse is left to the
+// compiler to report.
+func checkSandboxImports(code string, allowed []sandboxModule) ([]sandboxModule, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", code, parser.ImportsOnly)
+	if err != nil {
+		return nil, nil
+	}
+
+	var used []sandboxModule
+	seen := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || isStdlibImport(path) {
+			continue
+		}
+
+		mod, ok := findSandboxModule(path, allowed)
+		if !ok {
+			return nil, fmt.Errorf("import %q is not allowed in %s: only the standard library and these approved modules can be imported: %s",
+				path, ToolNameGoSandbox, describeSandboxModules(allowed))
+		}
+		if !seen[mod.Path] {
+			seen[mod.Path] = true
+			used = append(used, mod)
+		}
+	}
+	return used, nil
+}
+
+// findSandboxModule returns the allowlisted module providing the import path
+func findSandboxModule(path string, allowed []sandboxModule) (sandboxModule, bool) {
+	for _, mod := range allowed {
+		if path == mod.Path || strings.HasPrefix(path, mod.Path+"/") {
+			return mod, true
+		}
+	}
+	return sandboxModule{}, false
+}
+
+func describeSandboxModules(modules []sandboxModule) string {
+	if len(modules) == 0 {
+		return "(none)"
+	}
+	paths := make([]string, len(modules))
+	for i, mod := range modules {
+		paths[i] = mod.Path
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, ", ")
+}
+
+// writeSandboxGoMod writes a go.mod requiring the used modules and a go.sum
+// pinning their checksums, so the Go toolchain invoked by TinyGo fetches them
+// into the shared module cache and rejects modified downloads
+func writeSandboxGoMod(dir string, used []sandboxModule) error {
+	var mod, sum strings.Builder
+	mod.WriteString("module sandbox\n\ngo 1.22\n\nrequire (\n")
+	for _, m := range used {
+		fmt.Fprintf(&mod, "\t%s %s\n", m.Path, m.Version)
+		fmt.Fprintf(&sum, "%s %s %s\n%s %s/go.mod %s\n", m.Path, m.Version, m.Sum, m.Path, m.Version, m.GoModSum)
+	}
+	mod.WriteString(")\n")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod.String()), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "go.sum"), []byte(sum.String()), 0644)
+}
+
+// sandboxModuleCompileError is returned as the result of a program importing
+// a package outside the allowlist, in the shape of a compilation failure
+func sandboxModuleCompileError(err error) map[string]interface{} {
+	return map[string]interface{}{
+		"stdout":    err.Error(),
+		"exit_code": 1,
+		"timeout":   false,
+		"error":     "compilation failed",
+	}
+}
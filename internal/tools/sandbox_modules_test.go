@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+const sandboxYAMLProgram = `package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	var doc map[string]int
+	if err := yaml.Unmarshal([]byte("answer: 42"), &doc); err != nil {
+		panic(err)
+	}
+	fmt.Println(strings.Repeat("=", 3), doc["answer"])
+}
+`
+
+const sandboxDisallowedProgram = `package main
+
+import (
+	"fmt"
+
+	"github.com/evil/exfiltrate/client"
+)
+
+func main() {
+	total := 0
+	for i := 0; i < 3; i++ {
+		total += client.Send(i)
+	}
+	fmt.Println(total)
+}
+`
+
+func TestCheckSandboxImports(t *testing.T) {
+	allowed := parseSandboxModules(DefaultSandboxModules)
+
+	used, err := checkSandboxImports(sandboxYAMLProgram, allowed)
+	if err != nil {
+		t.Fatalf("expected the allowlisted import to pass, got %v", err)
+	}
+	if len(used) != 1 || used[0].Path != "gopkg.in/yaml.v3" || used[0].Version != "v3.0.1" {
+		t.Errorf("expected gopkg.in/yaml.v3 to be used, got %+v", used)
+	}
+
+	_, err = checkSandboxImports(sandboxDisallowedProgram, allowed)
+	if err == nil {
+		t.Fatal("expected the disallowed import to be rejected")
+	}
+	for _, want := range []string{`"github.com/evil/exfiltrate/client"`, "not allowed", "gopkg.in/yaml.v3"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to mention %s, got %q", want, err)
+		}
+	}
+
+	// A module path prefix must match at a path element boundary
+	if _, err := checkSandboxImports(`package main; import "gopkg.in/yaml.v3x"`, allowed); err == nil {
+		t.Error("expected a lookalike module path to be rejected")
+	}
+}
+
+func TestParseSandboxModules(t *testing.T) {
+	const sums = " h1:module= h1:gomod="
+	modules := parseSandboxModules([]string{
+		"github.com/google/uuid@v1.6.0" + sums,
+		"github.com/google/uuid@v1.6.0",
+		"github.com/google/uuid@v1.6.0 module= gomod=",
+		"no-version" + sums,
+		"fmt@v1.0.0" + sums,
+		"example.com/x@latest" + sums,
+	})
+	if len(modules) != 1 || modules[0].Path != "github.com/google/uuid" || modules[0].Sum != "h1:module=" || modules[0].GoModSum != "h1:gomod=" {
+		t.Errorf("expected only the valid entry, got %+v", modules)
+	}
+	if defaults := parseSandboxModules(DefaultSandboxModules); len(defaults) != len(DefaultSandboxModules) {
+		t.Errorf("expected all default modules to be pinned, got %+v", defaults)
+	}
+}
+
+func TestWriteSandboxGoMod(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeSandboxGoMod(dir, []sandboxModule{{Path: "gopkg.in/yaml.v3", Version: "v3.0.1", Sum: "h1:module=", GoModSum: "h1:gomod="}}); err != nil {
+		t.Fatalf("writeSandboxGoMod failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatalf("failed to read go.mod: %v", err)
+	}
+	if !strings.Contains(string(data), "\tgopkg.in/yaml.v3 v3.0.1\n") {
+		t.Errorf("expected the module requirement, got:\n%s", data)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		t.Fatalf("failed to read go.sum: %v", err)
+	}
+	if want := "gopkg.in/yaml.v3 v3.0.1 h1:module=\ngopkg.in/yaml.v3 v3.0.1/go.mod h1:gomod=\n"; string(data) != want {
+		t.Errorf("expected the pinned checksums, got:\n%s", data)
+	}
+}
+
+func TestSandboxRejectsDisallowedImportBeforeCompiling(t *testing.T) {
+	workingDir := t.TempDir()
+	sess := session.NewSession("test", workingDir)
+	sandboxTool := NewSandboxToolWithFS(workingDir, t.TempDir(), nil, sess, nil)
+
+	res := sandboxTool.Execute(context.Background(), map[string]interface{}{"code": sandboxDisallowedProgram})
+	if res.Error != "" {
+		t.Fatalf("expected a compilation failure result, got error %q", res.Error)
+	}
+	resMap, ok := res.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map result, got %T", res.Result)
+	}
+	if resMap["error"] != "compilation failed" || resMap["exit_code"] != 1 {
+		t.Errorf("expected a compilation failure, got %+v", resMap)
+	}
+	if stdout, _ := resMap["stdout"].(string); !strings.Contains(stdout, "is not allowed") {
+		t.Errorf("expected a clear message, got %q", stdout)
+	}
+
+	// Restricting the allowlist rejects modules of the default set
+	sandboxTool.SetAllowedModules(DefaultSandboxModules[1:])
+	res = sandboxTool.Execute(context.Background(), map[string]interface{}{"code": sandboxYAMLProgram})
+	if resMap, ok := res.Result.(map[string]interface{}); !ok || !strings.Contains(resMap["stdout"].(string), `"gopkg.in/yaml.v3" is not allowed`) {
+		t.Errorf("expected yaml to be rejected by the custom allowlist, got %+v", res)
+	}
+	if desc := sandboxTool.Description(); !strings.Contains(desc, "imported: github.com/BurntSushi/toml;") {
+		t.Errorf("expected the description to list the configured modules, got:\n%s", desc)
+	}
+}
+
+func TestIntegration_SandboxAllowlistedModule(t *testing.T) {
+	if !*runIntegrationTests {
+		t.Skip("Skipping WASM integration test - run with: go test -run Integration ./internal/tools -integration")
+	}
+	skipIfTinyGoUnavailable(t)
+	limitSandboxConcurrency(t)
+
+	workingDir := t.TempDir()
+	sess := session.NewSession("test", workingDir)
+	sandboxTool := NewSandboxToolWithFS(workingDir, t.TempDir(), nil, sess, nil)
+
+	res := sandboxTool.Execute(context.Background(), map[string]interface{}{"code": sandboxYAMLProgram, "timeout": 120})
+	if res.Error != "" {
+		t.Fatalf("sandbox execute failed: %s", res.Error)
+	}
+	resMap := res.Result.(map[string]interface{})
+	if stdout, _ := resMap["stdout"].(string); !strings.Contains(stdout, "=== 42") {
+		t.Fatalf("expected the yaml program to run, got %+v", resMap)
+	}
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestSandboxWriteFileAfterMainToolRead(t *testing.T) {
+	if !*runIntegrationTests {
+		t.Skip("Skipping WASM integration test - run with: go test -run Integration ./internal/tools -integration")
+	}
+
+	ctx := context.Background()
+	mockFS := fs.NewMockFS()
+	sess := session.NewSession("test-session", "/tmp")
+	if err := mockFS.WriteFile(ctx, "notes.txt", []byte("old")); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if result := NewReadFileTool(mockFS, sess).Execute(ctx, map[string]interface{}{"path": "./notes.txt"}); result.Error != "" {
+		t.Fatalf("read_file failed: %s", result.Error)
+	}
+
+	sandbox := NewSandboxToolWithFS("/tmp", "/tmp", mockFS, sess, nil)
+	code := `
+package main
+
+import "fmt"
+
+func main() {
+	if err := WriteFile("notes.txt", false, "new"); err != "" {
+		fmt.Printf("write failed: %s\n", err)
+		return
+	}
+	fmt.Println("written")
+}
+`
+	result := sandbox.Execute(ctx, map[string]interface{}{"code": code, "timeout": 30})
+	if result.Error != "" {
+		t.Fatalf("Sandbox execution failed: %s", result.Error)
+	}
+
+	if data, _ := mockFS.ReadFile(ctx, "notes.txt"); string(data) != "new" {
+		t.Errorf("expected the sandbox write to be allowed after the main tool read, got %q (result %v)", data, result.Result)
+	}
+}
+
+func TestSandboxWriteFileWithoutRead(t *testing.T) {
+	if !*runIntegrationTests {
+		t.Skip("Skipping WASM integration test - run with: go test -run Integration ./internal/tools -integration")
+	}
+
+	ctx := context.Background()
+	mockFS := fs.NewMockFS()
+	sess := session.NewSession("test-session", "/tmp")
+	if err := mockFS.WriteFile(ctx, "notes.txt", []byte("old")); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	sandbox := NewSandboxToolWithFS("/tmp", "/tmp", mockFS, sess, nil)
+	code := `
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(WriteFile("notes.txt", false, "new"))
+}
+`
+	result := sandbox.Execute(ctx, map[string]interface{}{"code": code, "timeout": 30})
+	if result.Error != "" {
+		t.Fatalf("Sandbox execution failed: %s", result.Error)
+	}
+
+	if data, _ := mockFS.ReadFile(ctx, "notes.txt"); string(data) != "old" {
+		t.Errorf("expected the unread file to be unchanged, got %q", data)
+	}
+	if output, _ := result.Result.(string); output != "" && !strings.Contains(output, "was not read") {
+		t.Errorf("expected a read-before-write error, got %q", output)
+	}
+}
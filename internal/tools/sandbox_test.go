@@ -17,7 +17,13 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
@@ -394,6 +400,126 @@ func main() {
 	}
 }
 
+func TestSandboxTool_ResolveTimeout(t *testing.T) {
+	tool := NewSandboxTool("/tmp", "/tmp")
+
+	tests := []struct {
+		name       string
+		maxTimeout int
+		params     map[string]interface{}
+		want       int
+	}{
+		{"default", 0, map[string]interface{}{}, DefaultSandboxTimeoutSeconds},
+		{"timeout_seconds", 0, map[string]interface{}{"timeout_seconds": float64(120)}, 120},
+		{"legacy timeout", 0, map[string]interface{}{"timeout": float64(45)}, 45},
+		{"timeout_seconds wins", 0, map[string]interface{}{"timeout": float64(45), "timeout_seconds": float64(5)}, 5},
+		{"clamped to built-in max", 0, map[string]interface{}{"timeout_seconds": float64(7200)}, MaxToolTimeoutSeconds},
+		{"clamped to configured max", 60, map[string]interface{}{"timeout_seconds": float64(120)}, 60},
+		{"default clamped to configured max", 10, map[string]interface{}{}, 10},
+		{"non-positive uses default", 0, map[string]interface{}{"timeout_seconds": float64(0)}, DefaultSandboxTimeoutSeconds},
+		{"configured max above built-in max", 5000, map[string]interface{}{"timeout_seconds": float64(5000)}, MaxToolTimeoutSeconds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool.SetMaxTimeout(tt.maxTimeout)
+			if got := tool.resolveTimeout(tt.params); got != tt.want {
+				t.Errorf("resolveTimeout(%v) = %d, want %d", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSandboxTool_TimeoutMetadata(t *testing.T) {
+	tool := NewSandboxTool("/tmp", "/tmp")
+
+	metadata := tool.buildSandboxMetadata(time.Now(), "sleep", 5, -1, "", "Execution timeout", true, nil)
+	if metadata.ErrorType != "timeout" {
+		t.Errorf("expected error type 'timeout', got %q", metadata.ErrorType)
+	}
+	if !metadata.WasTimedOut || metadata.TimeoutSeconds != 5 {
+		t.Errorf("expected timed out metadata with 5s timeout, got %+v", metadata)
+	}
+
+	metadata = tool.buildSandboxMetadata(time.Now(), "ok", 5, 0, "done", "", false, nil)
+	if metadata.ErrorType != "" {
+		t.Errorf("expected no error type, got %q", metadata.ErrorType)
+	}
+}
+
+// sleepingSandboxProgram sleeps without producing output, so the adaptive
+// deadline has no activity to extend the timeout with
+const sleepingSandboxProgram = `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	time.Sleep(5 * time.Second)
+	fmt.Println("finished")
+}
+`
+
+func TestIntegration_SandboxTool_Execute_RaisedTimeout(t *testing.T) {
+	if !*runIntegrationTests {
+		t.Skip("Skipping WASM integration test - run with: go test -run Integration ./internal/tools -integration")
+	}
+
+	tool := NewSandboxTool("/tmp", t.TempDir())
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"code":            sleepingSandboxProgram,
+		"timeout_seconds": 120,
+	})
+	if result.Error != "" {
+		t.Fatalf("execution failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	if timedOut, _ := resultMap["timeout"].(bool); timedOut {
+		t.Fatal("expected program to finish within the raised timeout")
+	}
+	if stdout, _ := resultMap["stdout"].(string); !strings.Contains(stdout, "finished") {
+		t.Errorf("expected program output, got %q", stdout)
+	}
+	if result.ExecutionMetadata == nil || result.ExecutionMetadata.TimeoutSeconds != 120 {
+		t.Errorf("expected metadata to record the 120s timeout, got %+v", result.ExecutionMetadata)
+	}
+}
+
+func TestIntegration_SandboxTool_Execute_LoweredTimeout(t *testing.T) {
+	if !*runIntegrationTests {
+		t.Skip("Skipping WASM integration test - run with: go test -run Integration ./internal/tools -integration")
+	}
+
+	tool := NewSandboxTool("/tmp", t.TempDir())
+
+	// Warm up the TinyGo build cache so compilation does not eat the budget
+	if warm := tool.Execute(context.Background(), map[string]interface{}{
+		"code": "package main\n\nfunc main() {}\n",
+	}); warm.Error != "" {
+		t.Fatalf("warm-up execution failed: %s", warm.Error)
+	}
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"code":            sleepingSandboxProgram,
+		"timeout_seconds": 2,
+	})
+	if result.Error != "" {
+		t.Fatalf("execution failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	if timedOut, _ := resultMap["timeout"].(bool); !timedOut {
+		t.Fatalf("expected program to exceed the lowered timeout, got %v", resultMap)
+	}
+	if result.ExecutionMetadata == nil || result.ExecutionMetadata.ErrorType != "timeout" {
+		t.Errorf("expected error type 'timeout' in metadata, got %+v", result.ExecutionMetadata)
+	}
+}
+
 func TestIntegration_SandboxTool_Execute_SandboxIsolation(t *testing.T) {
 	if !*runIntegrationTests {
 		t.Skip("Skipping WASM integration test - run with: go test -run Integration ./internal/tools -integration")
@@ -839,3 +965,319 @@ func TestExecDeadline_ConcurrentStopNoPanic(t *testing.T) {
 
 	wg.Wait()
 }
+
+// recordingShellExecutor records the working directory and environment of the
+// last command instead of running it
+type recordingShellExecutor struct {
+	args       []string
+	workingDir string
+	env        map[string]string
+}
+
+func (e *recordingShellExecutor) ExecuteCommand(ctx context.Context, args []string, workingDir string, timeout time.Duration, stdin string) (string, string, int, error) {
+	return e.ExecuteCommandWithEnv(ctx, args, workingDir, timeout, stdin, nil)
+}
+
+func (e *recordingShellExecutor) ExecuteCommandWithEnv(_ context.Context, args []string, workingDir string, _ time.Duration, _ string, env map[string]string) (string, string, int, error) {
+	e.args = args
+	e.workingDir = workingDir
+	e.env = env
+	return "ok", "", 0, nil
+}
+
+// plainShellExecutor only implements ShellExecutor
+type plainShellExecutor struct{}
+
+func (plainShellExecutor) ExecuteCommand(context.Context, []string, string, time.Duration, string) (string, string, int, error) {
+	return "ok", "", 0, nil
+}
+
+func TestSandboxTool_ResolveCommandWorkingDir(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	sub := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	symlinks := true
+	if err := os.Symlink(t.TempDir(), filepath.Join(root, "escape")); err != nil {
+		symlinks = false
+	} else if err := os.Symlink(sub, filepath.Join(root, "api")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	tool := NewSandboxTool(root, t.TempDir())
+
+	valid := map[string]string{
+		"":                         "",
+		".":                        root,
+		"services/api":             sub,
+		sub:                        sub,
+		"services/../services/api": sub,
+	}
+	for dir, want := range valid {
+		got, err := tool.resolveCommandWorkingDir(dir)
+		if err != nil {
+			t.Errorf("resolveCommandWorkingDir(%q) failed: %v", dir, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("resolveCommandWorkingDir(%q) = %q, want %q", dir, got, want)
+		}
+	}
+
+	invalid := []string{
+		"..",
+		"../outside",
+		"services/../../outside",
+		filepath.Dir(root),
+		"missing",
+		"file.txt",
+	}
+	if symlinks {
+		if got, err := tool.resolveCommandWorkingDir("api"); err != nil || got != sub {
+			t.Errorf("resolveCommandWorkingDir(%q) = %q, %v, want %q", "api", got, err, sub)
+		}
+		invalid = append(invalid, "escape")
+	}
+	for _, dir := range invalid {
+		if _, err := tool.resolveCommandWorkingDir(dir); err == nil {
+			t.Errorf("expected resolveCommandWorkingDir(%q) to fail", dir)
+		}
+	}
+}
+
+func TestSandboxTool_RunSandboxCommand_WorkingDirAndEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	sub := filepath.Join(root, "build")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	tool := NewSandboxTool(root, t.TempDir())
+	workingDir, err := tool.resolveCommandWorkingDir("build")
+	if err != nil {
+		t.Fatalf("failed to resolve working dir: %v", err)
+	}
+
+	// Direct execution without a shell executor
+	stdout, stderr, exitCode := tool.runSandboxCommand(context.Background(),
+		[]string{"sh", "-c", `pwd; echo "$SANDBOX_TEST_VAR"`}, nil, workingDir,
+		map[string]string{"SANDBOX_TEST_VAR": "custom"})
+	if exitCode != 0 {
+		t.Fatalf("command failed with exit %d: %s", exitCode, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || lines[0] != sub || lines[1] != "custom" {
+		t.Fatalf("expected cwd %q and env value, got %q", sub, stdout)
+	}
+
+	// Options are passed through to the shell executor
+	executor := &recordingShellExecutor{}
+	tool.SetShellExecutor(executor)
+	if _, stderr, exitCode := tool.runSandboxCommand(context.Background(), []string{"make"}, nil, workingDir, map[string]string{"CC": "clang"}); exitCode != 0 {
+		t.Fatalf("command failed with exit %d: %s", exitCode, stderr)
+	}
+	if executor.workingDir != sub || executor.env["CC"] != "clang" {
+		t.Errorf("expected executor to receive dir %q and env, got %q %v", sub, executor.workingDir, executor.env)
+	}
+
+	// Executors without env support reject env instead of silently dropping it
+	tool.SetShellExecutor(plainShellExecutor{})
+	if _, stderr, exitCode := tool.runSandboxCommand(context.Background(), []string{"make"}, nil, workingDir, map[string]string{"CC": "clang"}); exitCode != -1 || !strings.Contains(stderr, "not supported") {
+		t.Errorf("expected env to be rejected, got exit %d, stderr %q", exitCode, stderr)
+	}
+}
+
+func TestSandboxCommandOptions_Validate(t *testing.T) {
+	valid := sandboxCommandOptions{Env: map[string]string{"GOFLAGS": "-mod=mod"}}
+	if err := valid.validate(); err != nil {
+		t.Errorf("expected valid options, got %v", err)
+	}
+
+	for _, key := range []string{"", "A=B"} {
+		opts := sandboxCommandOptions{Env: map[string]string{key: "x"}}
+		if err := opts.validate(); err == nil {
+			t.Errorf("expected env name %q to be rejected", key)
+		}
+	}
+}
+
+func TestDescribeSandboxCommand_IncludesOptions(t *testing.T) {
+	params, display := describeSandboxCommand([]string{"go", "test"}, "/work/pkg", sandboxCommandOptions{
+		WorkingDir: "pkg",
+		Env:        map[string]string{"GOFLAGS": "-mod=mod", "CGO_ENABLED": "0"},
+		StdinFile:  "input.txt",
+	})
+
+	want := "CGO_ENABLED=0 GOFLAGS=-mod=mod go test < input.txt (in /work/pkg)"
+	if display != want {
+		t.Errorf("display = %q, want %q", display, want)
+	}
+	if params["command"] != want {
+		t.Errorf("authorization command = %q, want %q", params["command"], want)
+	}
+	if params["working_dir"] != "/work/pkg" || params["stdin_file"] != "input.txt" || params["env"] == nil {
+		t.Errorf("expected working_dir, stdin_file and env in the authorization request, got %v", params)
+	}
+
+	params, display = describeSandboxCommand([]string{"ls", "-la"}, "/work", sandboxCommandOptions{})
+	if display != "ls -la" || params["command"] != "ls -la" {
+		t.Errorf("expected the plain command without options, got %q (%v)", display, params)
+	}
+	if _, ok := params["working_dir"]; ok {
+		t.Errorf("expected no working_dir for the default directory, got %v", params)
+	}
+}
+
+func TestSandboxTool_ResolveCommandStdinFile(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "data"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "data", "input.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret\n"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tool := NewSandboxTool(root, t.TempDir())
+
+	want := filepath.Join(root, "data", "input.txt")
+	for _, path := range []string{"data/input.txt", want, "data/../data/input.txt"} {
+		got, err := tool.resolveCommandStdinFile(path)
+		if err != nil {
+			t.Errorf("resolveCommandStdinFile(%q) failed: %v", path, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("resolveCommandStdinFile(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	for _, path := range []string{"../secret.txt", outside, "escape.txt", "data", "missing.txt"} {
+		if _, err := tool.resolveCommandStdinFile(path); err == nil {
+			t.Errorf("expected resolveCommandStdinFile(%q) to fail", path)
+		}
+	}
+}
+
+func TestAuthorizeCommandStdinFile(t *testing.T) {
+	tracker := newSandboxCallTracker()
+	denying := &wasiAuthorizerAdapter{authorizer: &denyingAuthorizer{}}
+	if err := authorizeCommandStdinFile(context.Background(), denying, tracker, "/work/.env"); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected the read to be denied, got %v", err)
+	}
+	if len(tracker.calls) != 0 {
+		t.Fatalf("denied reads must not be recorded, got %+v", tracker.calls)
+	}
+
+	allowing := &wasiAuthorizerAdapter{authorizer: &stubAuthorizer{}}
+	if err := authorizeCommandStdinFile(context.Background(), allowing, tracker, "/work/input.txt"); err != nil {
+		t.Fatalf("expected the read to be allowed, got %v", err)
+	}
+	if len(tracker.calls) != 1 || tracker.calls[0].Name != "read_file" || tracker.calls[0].Detail != "/work/input.txt" {
+		t.Fatalf("expected the read to be recorded, got %+v", tracker.calls)
+	}
+}
+
+func TestSandboxTool_RunSandboxCommand_StdinFileMatchesInMemoryStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires grep")
+	}
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("grep not available")
+	}
+
+	root := t.TempDir()
+	var fixture strings.Builder
+	for i := 0; i < 100000; i++ {
+		level := "INFO"
+		if i%7 == 0 {
+			level = "ERROR"
+		}
+		fmt.Fprintf(&fixture, "%06d %s request handled\n", i, level)
+	}
+	if err := os.WriteFile(filepath.Join(root, "server.log"), []byte(fixture.String()), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	tool := NewSandboxTool(root, t.TempDir())
+	command := []string{"grep", "-c", "ERROR"}
+
+	wantStdout, wantStderr, wantExit := tool.runSandboxCommand(context.Background(), command, []byte(fixture.String()), "", nil)
+	if wantExit != 0 {
+		t.Fatalf("in-memory command failed with exit %d: %s", wantExit, wantStderr)
+	}
+
+	stdinPath, err := tool.resolveCommandStdinFile("server.log")
+	if err != nil {
+		t.Fatalf("failed to resolve stdin file: %v", err)
+	}
+	stdinFile, err := os.Open(stdinPath)
+	if err != nil {
+		t.Fatalf("failed to open stdin file: %v", err)
+	}
+	defer stdinFile.Close()
+	stdout, stderr, exitCode := tool.runSandboxCommandWithStdinFile(context.Background(), command, stdinFile, "", nil)
+	if exitCode != wantExit || stdout != wantStdout || stderr != wantStderr {
+		t.Fatalf("stdin file output (%q, %q, %d) differs from in-memory output (%q, %q, %d)", stdout, stderr, exitCode, wantStdout, wantStderr, wantExit)
+	}
+	if strings.TrimSpace(stdout) != "14286" {
+		t.Fatalf("expected 14286 matching lines, got %q", stdout)
+	}
+}
+
+func TestSandboxTool_UnavailableWithoutTinyGo(t *testing.T) {
+	tool := &SandboxTool{
+		workingDir: t.TempDir(),
+		tempDir:    t.TempDir(),
+		tinygoErr:  errors.New("failed to determine cache directory"),
+	}
+
+	err := tool.Available()
+	if err == nil {
+		t.Fatal("expected sandbox to be unavailable without a TinyGo manager")
+	}
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"code": "package main\n\nfunc main() {}\n",
+	})
+	if result.Error != err.Error() {
+		t.Fatalf("expected friendly error %q, got %q", err.Error(), result.Error)
+	}
+	for _, want := range []string{"go_sandbox unavailable", "TinyGo not installed", "install TinyGo", "cache directory"} {
+		if !strings.Contains(result.Error, want) {
+			t.Errorf("expected error to contain %q, got %q", want, result.Error)
+		}
+	}
+
+	// Background executions fail the same way instead of starting a job
+	result = tool.Execute(context.Background(), map[string]interface{}{
+		"code":       "package main\n\nfunc main() {}\n",
+		"background": true,
+	})
+	if !strings.Contains(result.Error, "go_sandbox unavailable") {
+		t.Errorf("expected friendly error for background execution, got %q", result.Error)
+	}
+}
@@ -94,6 +94,9 @@ func (t *SandboxTool) buildSandboxMetadata(startTime time.Time, commandSummary s
 		WasBackgrounded: false,
 		ToolType:        ToolNameGoSandbox,
 	}
+	if timedOut {
+		metadata.ErrorType = "timeout"
+	}
 
 	if tracker != nil {
 		if details := tracker.metadataDetails(); details != nil {
@@ -306,6 +306,22 @@ func (t *SandboxTool) executeWASM(ctx context.Context, wasmBytes []byte, sandbox
 	}, metadata), nil
 }
 
+// rememberApprovedDomain records a domain the user approved. It is always
+// authorized for the session; unless sessionOnly is set, it is also saved to
+// the configuration.
+func (t *SandboxTool) rememberApprovedDomain(domain string, sessionOnly bool) {
+	if t.session != nil {
+		t.session.AuthorizeDomain(domain)
+	}
+	if sessionOnly || t.authConfig.Config == nil || t.authConfig.Config.IsDomainAuthorized(domain) {
+		return
+	}
+	t.authConfig.Config.AuthorizeDomain(domain)
+	if err := t.authConfig.Config.Save(t.authConfig.ConfigPath); err != nil {
+		logger.Warn("Failed to persist authorized domain %q: %v", domain, err)
+	}
+}
+
 // executeFetch performs an HTTP request from WASM with authorization.
 func (t *SandboxTool) executeFetch(ctx context.Context, adapter *wasiAuthorizerAdapter, tracker *sandboxCallTracker, m api.Module, methodPtr, methodLen, urlPtr, urlLen, bodyPtr, bodyLen, responsePtr, responseCap uint32) uint32 {
 	// Read method from WASM memory
@@ -370,16 +386,7 @@ func (t *SandboxTool) executeFetch(ctx context.Context, adapter *wasiAuthorizerA
 				if respErr != nil || resp == nil || resp.TimedOut || resp.Cancelled || !resp.Approved {
 					return 403 // Forbidden - user denied or error
 				}
-				// User approved — persist the authorization
-				if t.session != nil {
-					t.session.AuthorizeDomain(parsedURL.Host)
-				}
-				if t.authConfig.Config != nil && !t.authConfig.Config.IsDomainAuthorized(parsedURL.Host) {
-					t.authConfig.Config.AuthorizeDomain(parsedURL.Host)
-					if saveErr := t.authConfig.Config.Save(t.authConfig.ConfigPath); saveErr != nil {
-						logger.Warn("Failed to persist authorized domain %q: %v", parsedURL.Host, saveErr)
-					}
-				}
+				t.rememberApprovedDomain(parsedURL.Host, resp.SessionOnly)
 			} else {
 				return 403 // Forbidden - no approval mechanism available
 			}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/codefionn/scriptschnell/internal/config"
 	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/vcs"
 )
 
 // SearchFilesToolSpec is the static specification for the search_files tool
@@ -18,7 +21,7 @@ func (s *SearchFilesToolSpec) Name() string {
 }
 
 func (s *SearchFilesToolSpec) Description() string {
-	return "Search for files by name pattern (glob) and optionally filter by content. Returns list of matching file paths. Use this to find files in the project before reading them."
+	return "Search for files by name pattern (glob) and optionally filter by content. Returns list of matching file paths. Use this to find files in the project before reading them. Paths ignored by .gitignore and dependency directories (e.g. node_modules, vendor) are skipped unless include_ignored is set."
 }
 
 func (s *SearchFilesToolSpec) Parameters() map[string]interface{} {
@@ -41,6 +44,10 @@ func (s *SearchFilesToolSpec) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "Base directory to search in (relative to working directory, default: '.')",
 			},
+			"include_ignored": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also search paths ignored by .gitignore and always-ignored directories (default: false)",
+			},
 		},
 		"required": []string{"pattern"},
 	}
@@ -48,15 +55,31 @@ func (s *SearchFilesToolSpec) Parameters() map[string]interface{} {
 
 // SearchFilesTool is the executor with runtime dependencies
 type SearchFilesTool struct {
-	fs fs.FileSystem
+	fs          fs.FileSystem
+	ignoredDirs []string // directory names skipped in addition to paths the VCS ignores
+	vcs         vcs.VCS  // decides which paths are ignored (nil = only ignoredDirs)
+	workingDir  string   // directory relative paths are resolved against for the VCS
 }
 
 func NewSearchFilesTool(filesystem fs.FileSystem) *SearchFilesTool {
 	return &SearchFilesTool{
-		fs: filesystem,
+		fs:          filesystem,
+		ignoredDirs: config.DefaultIgnoredDirs,
 	}
 }
 
+// SetIgnoredDirs sets the directory names that are always skipped
+func (t *SearchFilesTool) SetIgnoredDirs(dirs []string) {
+	t.ignoredDirs = dirs
+}
+
+// SetVCS skips the paths ignored by repo. Relative paths are resolved against
+// workingDir.
+func (t *SearchFilesTool) SetVCS(repo vcs.VCS, workingDir string) {
+	t.vcs = repo
+	t.workingDir = workingDir
+}
+
 // Legacy interface implementation for backward compatibility
 func (t *SearchFilesTool) Name() string        { return ToolNameSearchFiles }
 func (t *SearchFilesTool) Description() string { return (&SearchFilesToolSpec{}).Description() }
@@ -73,6 +96,7 @@ func (t *SearchFilesTool) Execute(ctx context.Context, params map[string]interfa
 	contentRegex := GetStringParam(params, "content_regex", "")
 	maxResults := GetIntParam(params, "max_results", 50)
 	basePath := GetStringParam(params, "path", ".")
+	includeIgnored := GetBoolParam(params, "include_ignored", false)
 
 	// Validate max results
 	if maxResults > 500 {
@@ -93,7 +117,7 @@ func (t *SearchFilesTool) Execute(ctx context.Context, params map[string]interfa
 	}
 
 	// Find matching files
-	matches, err := t.searchFiles(ctx, basePath, pattern, contentRe, maxResults)
+	matches, err := t.searchFiles(ctx, basePath, pattern, contentRe, maxResults, includeIgnored)
 	if err != nil {
 		return &ToolResult{Error: fmt.Sprintf("search failed: %v", err)}
 	}
@@ -129,7 +153,7 @@ func (t *SearchFilesTool) Execute(ctx context.Context, params map[string]interfa
 	return &ToolResult{Result: result.String()}
 }
 
-func (t *SearchFilesTool) searchFiles(ctx context.Context, basePath, pattern string, contentRe *regexp.Regexp, maxResults int) ([]string, error) {
+func (t *SearchFilesTool) searchFiles(ctx context.Context, basePath, pattern string, contentRe *regexp.Regexp, maxResults int, includeIgnored bool) ([]string, error) {
 	var matches []string
 
 	// Determine if this is a simple pattern or a complex one
@@ -137,7 +161,7 @@ func (t *SearchFilesTool) searchFiles(ctx context.Context, basePath, pattern str
 	hasSlash := strings.Contains(pattern, "/")
 
 	// Walk the directory tree
-	err := t.walkDir(ctx, basePath, func(path string, info *fs.FileInfo) error {
+	err := t.walkDir(ctx, basePath, !includeIgnored, func(path string, info *fs.FileInfo) error {
 		// Check if we've hit the max results
 		if len(matches) >= maxResults {
 			return fmt.Errorf("max_results_reached")
@@ -192,7 +216,9 @@ func (t *SearchFilesTool) searchFiles(ctx context.Context, basePath, pattern str
 	return matches, nil
 }
 
-func (t *SearchFilesTool) walkDir(ctx context.Context, dir string, fn func(path string, info *fs.FileInfo) error) error {
+// walkDir calls fn for every file below dir, skipping ignored paths if
+// skipIgnored is set
+func (t *SearchFilesTool) walkDir(ctx context.Context, dir string, skipIgnored bool, fn func(path string, info *fs.FileInfo) error) error {
 	// Check if directory exists
 	exists, err := t.fs.Exists(ctx, dir)
 	if err != nil {
@@ -219,11 +245,19 @@ func (t *SearchFilesTool) walkDir(ctx context.Context, dir string, fn func(path
 		return err
 	}
 
+	var ignored map[string]bool
+	if skipIgnored {
+		ignored = t.ignoredEntries(ctx, entries)
+	}
+
 	// Process each entry
 	for _, entry := range entries {
+		if ignored[entry.Path] {
+			continue
+		}
 		if entry.IsDir {
 			// Recursively walk subdirectories
-			if err := t.walkDir(ctx, entry.Path, fn); err != nil {
+			if err := t.walkDir(ctx, entry.Path, skipIgnored, fn); err != nil {
 				return err
 			}
 		} else {
@@ -281,9 +315,58 @@ func (t *SearchFilesTool) matchGlobPattern(path, pattern string) (bool, error) {
 	return re.MatchString(path), nil
 }
 
+// ignoredEntries returns the paths of a directory listing that are
+// always-ignored directories or ignored by the VCS. The VCS is asked once per
+// listing when it supports batch lookups, so nothing is cached beyond the
+// current search.
+func (t *SearchFilesTool) ignoredEntries(ctx context.Context, entries []*fs.FileInfo) map[string]bool {
+	ignored := make(map[string]bool)
+	pathByAbs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir && slices.Contains(t.ignoredDirs, filepath.Base(entry.Path)) {
+			ignored[entry.Path] = true
+			continue
+		}
+		abs := entry.Path
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(t.workingDir, abs)
+		}
+		pathByAbs[abs] = entry.Path
+	}
+	if t.vcs == nil || len(pathByAbs) == 0 {
+		return ignored
+	}
+
+	if batch, ok := t.vcs.(vcs.BatchIgnoreChecker); ok {
+		absPaths := make([]string, 0, len(pathByAbs))
+		for abs := range pathByAbs {
+			absPaths = append(absPaths, abs)
+		}
+		ignoredAbs, err := batch.IgnoredPaths(ctx, absPaths)
+		if err == nil {
+			for abs := range ignoredAbs {
+				ignored[pathByAbs[abs]] = true
+			}
+			return ignored
+		}
+	}
+
+	for abs, path := range pathByAbs {
+		if isIgnored, _ := t.vcs.IsIgnored(ctx, abs); isIgnored {
+			ignored[path] = true
+		}
+	}
+	return ignored
+}
+
 // NewSearchFilesToolFactory creates a factory for SearchFilesTool
-func NewSearchFilesToolFactory(filesystem fs.FileSystem) ToolFactory {
+func NewSearchFilesToolFactory(filesystem fs.FileSystem, ignoredDirs []string, repo vcs.VCS, workingDir string) ToolFactory {
 	return func(reg *Registry) ToolExecutor {
-		return NewSearchFilesTool(filesystem)
+		tool := NewSearchFilesTool(filesystem)
+		if ignoredDirs != nil {
+			tool.SetIgnoredDirs(ignoredDirs)
+		}
+		tool.SetVCS(repo, workingDir)
+		return tool
 	}
 }
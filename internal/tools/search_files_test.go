@@ -3,10 +3,12 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/codefionn/scriptschnell/internal/fs"
+	"github.com/codefionn/scriptschnell/internal/vcs"
 )
 
 func TestSearchFilesTool(t *testing.T) {
@@ -192,6 +194,125 @@ func TestSearchFilesToolGlobPattern(t *testing.T) {
 	}
 }
 
+// ignoringVCS returns a VCS ignoring exactly the given absolute paths
+func ignoringVCS(paths ...string) *vcs.MockVCS {
+	return &vcs.MockVCS{
+		IsIgnoredFunc: func(ctx context.Context, absPath string) (bool, error) {
+			return slices.Contains(paths, absPath), nil
+		},
+	}
+}
+
+func TestSearchFilesToolSkipsIgnoredPaths(t *testing.T) {
+	mockFS := fs.NewMockFS()
+
+	write := func(path, contents string) {
+		if err := mockFS.WriteFile(context.Background(), path, []byte(contents)); err != nil {
+			t.Fatalf("failed to write file %s: %v", path, err)
+		}
+	}
+
+	write("main.go", "package main")
+	write("types.gen.go", "package main")
+	write("dist/bundle.go", "package dist")
+	write("node_modules/pkg/index.go", "package pkg")
+	write("internal/app/app.go", "package app")
+	write("internal/app/fixtures/sample.go", "package fixtures")
+
+	tool := NewSearchFilesTool(mockFS)
+	tool.SetVCS(ignoringVCS("/repo/types.gen.go", "/repo/dist", "/repo/internal/app/fixtures"), "/repo")
+
+	search := func(params map[string]interface{}) []string {
+		t.Helper()
+		result := tool.Execute(context.Background(), params)
+		if result.Error != "" {
+			t.Fatalf("unexpected error: %s", result.Error)
+		}
+		return extractMarkdownMatches(result.Result.(string))
+	}
+
+	matches := search(map[string]interface{}{"pattern": "*.go"})
+	if len(matches) != 2 || !containsString(matches, "main.go") || !containsString(matches, "internal/app/app.go") {
+		t.Errorf("expected only main.go and app.go, got %v", matches)
+	}
+
+	matches = search(map[string]interface{}{"pattern": "*.go", "path": "internal/app"})
+	if len(matches) != 1 || matches[0] != "internal/app/app.go" {
+		t.Errorf("expected only app.go below internal/app, got %v", matches)
+	}
+
+	matches = search(map[string]interface{}{"pattern": "*.go", "include_ignored": true})
+	if len(matches) != 6 {
+		t.Errorf("expected all 6 go files with include_ignored, got %v", matches)
+	}
+
+	tool.SetIgnoredDirs([]string{"internal"})
+	matches = search(map[string]interface{}{"pattern": "*.go"})
+	if containsString(matches, "internal/app/app.go") || !containsString(matches, "node_modules/pkg/index.go") {
+		t.Errorf("expected configured ignored dirs to replace the defaults, got %v", matches)
+	}
+}
+
+// batchIgnoringVCS ignores the given absolute paths and counts how often
+// it is asked
+type batchIgnoringVCS struct {
+	*vcs.MockVCS
+	paths []string
+	calls int
+}
+
+func (b *batchIgnoringVCS) IgnoredPaths(ctx context.Context, absPaths []string) (map[string]bool, error) {
+	b.calls++
+	ignored := make(map[string]bool)
+	for _, p := range absPaths {
+		if slices.Contains(b.paths, p) {
+			ignored[p] = true
+		}
+	}
+	return ignored, nil
+}
+
+func TestSearchFilesToolBatchesIgnoreLookups(t *testing.T) {
+	mockFS := fs.NewMockFS()
+	for _, path := range []string{"main.go", "types.gen.go", "dist/bundle.go", "internal/app/app.go", "internal/app/util.go"} {
+		if err := mockFS.WriteFile(context.Background(), path, []byte("package x")); err != nil {
+			t.Fatalf("failed to write file %s: %v", path, err)
+		}
+	}
+
+	repo := &batchIgnoringVCS{
+		MockVCS: &vcs.MockVCS{
+			IsIgnoredFunc: func(ctx context.Context, absPath string) (bool, error) {
+				t.Errorf("unexpected single lookup for %s", absPath)
+				return false, nil
+			},
+		},
+		paths: []string{"/repo/types.gen.go", "/repo/dist"},
+	}
+	tool := NewSearchFilesTool(mockFS)
+	tool.SetVCS(repo, "/repo")
+
+	result := tool.Execute(context.Background(), map[string]interface{}{"pattern": "*.go"})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	matches := extractMarkdownMatches(result.Result.(string))
+	if len(matches) != 3 || containsString(matches, "types.gen.go") || containsString(matches, "dist/bundle.go") {
+		t.Errorf("expected ignored paths to be skipped, got %v", matches)
+	}
+	// One lookup each for the root, internal and internal/app listings
+	if repo.calls != 3 {
+		t.Errorf("expected one ignore lookup per directory, got %d", repo.calls)
+	}
+
+	// A new search asks again instead of reusing earlier answers
+	repo.paths = nil
+	tool.Execute(context.Background(), map[string]interface{}{"pattern": "*.go"})
+	if repo.calls != 7 {
+		t.Errorf("expected a fresh lookup per directory on the next search, got %d calls", repo.calls)
+	}
+}
+
 func extractMarkdownMatches(markdown string) []string {
 	var matches []string
 	for _, line := range strings.Split(markdown, "\n") {
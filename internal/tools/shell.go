@@ -16,13 +16,19 @@ import (
 	"sync"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
+	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/logger"
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/secretdetect"
 	"github.com/codefionn/scriptschnell/internal/session"
 )
 
 type shellBackgroundKey struct{}
 
+type shellOutputKey struct{}
+
 const shellBackgroundMessage = "Command started in background. Use 'status_program' to stream progress, 'wait_program' to block until completion, or 'stop_program' to terminate."
 
 // ShellToolSpec is the static specification for the shell tool
@@ -126,6 +132,7 @@ func (t *ShellTool) Execute(ctx context.Context, params map[string]interface{})
 func (t *ShellTool) executeForeground(ctx context.Context, cmdStr, workingDir string, timeoutSecs int) *ToolResult {
 	bgChan := backgroundChanFromContext(ctx)
 	runner := newShellCommandRunner(t, cmdStr, workingDir, timeoutSecs, bgChan)
+	runner.output.onOutput = shellOutputFuncFromContext(ctx)
 	return runner.run(ctx)
 }
 
@@ -580,6 +587,112 @@ func backgroundChanFromContext(ctx context.Context) chan struct{} {
 	return nil
 }
 
+// ContextWithShellOutput returns a context that streams the output of
+// foreground shell commands to fn while they run. Streaming stops once a
+// command is moved to the background.
+func ContextWithShellOutput(ctx context.Context, fn actor.ShellOutputFunc) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, shellOutputKey{}, fn)
+}
+
+const (
+	// shellStatusTailLines is how many output lines the status shows
+	shellStatusTailLines = 3
+	// shellStatusMaxPending caps an unterminated output line kept for the
+	// status
+	shellStatusMaxPending = 4096
+)
+
+// ShellOutputStatus returns a ShellOutputFunc that reports the last few output
+// lines of a running command as an ephemeral status update. The tail is
+// redacted with redactor (nil = no redaction) before it is dispatched.
+func ShellOutputStatus(cb progress.Callback, redactor *secretdetect.Redactor) actor.ShellOutputFunc {
+	if cb == nil {
+		return nil
+	}
+	tail := &shellStatusTail{}
+	return func(chunk string, isStderr bool) {
+		status := tail.add(chunk)
+		if status == "" {
+			return
+		}
+		if redactor != nil {
+			status = redactor.Redact(status)
+		}
+		if err := progress.Dispatch(cb, progress.Update{
+			Message:   status,
+			Mode:      progress.ReportJustStatus,
+			Ephemeral: true,
+		}); err != nil {
+			logger.Debug("shell: output callback error: %v", err)
+		}
+	}
+}
+
+// shellStatusTail keeps the last non-empty output lines of a command across
+// chunks, so lines split over several writes are shown whole
+type shellStatusTail struct {
+	mu      sync.Mutex
+	lines   []string
+	pending string
+}
+
+// add appends chunk and returns the current tail, or "" if there is no
+// output to show yet
+func (t *shellStatusTail) add(chunk string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending += chunk
+	lines, remainder := splitLines(t.pending)
+	t.pending = truncateStatusPending(remainder)
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			t.lines = append(t.lines, line)
+		}
+	}
+	if len(t.lines) > shellStatusTailLines {
+		t.lines = append([]string(nil), t.lines[len(t.lines)-shellStatusTailLines:]...)
+	}
+
+	tail := t.lines
+	if pending := strings.TrimSpace(t.pending); pending != "" {
+		tail = append(append([]string(nil), tail...), pending)
+		if len(tail) > shellStatusTailLines {
+			tail = tail[len(tail)-shellStatusTailLines:]
+		}
+	}
+	return strings.Join(tail, "\n")
+}
+
+// truncateStatusPending keeps the end of an overly long unterminated line,
+// cut on a rune boundary
+func truncateStatusPending(pending string) string {
+	if len(pending) <= shellStatusMaxPending {
+		return pending
+	}
+	start := len(pending) - shellStatusMaxPending
+	for start < len(pending) && !utf8.RuneStart(pending[start]) {
+		start++
+	}
+	return pending[start:]
+}
+
+func shellOutputFuncFromContext(ctx context.Context) actor.ShellOutputFunc {
+	if ctx == nil {
+		return nil
+	}
+	if fn, ok := ctx.Value(shellOutputKey{}).(actor.ShellOutputFunc); ok {
+		return fn
+	}
+	return nil
+}
+
 func registerShellBackgroundJob(sess *session.Session, cmd *exec.Cmd, cmdStr, workingDir string, startedAt time.Time) (*session.BackgroundJob, string) {
 	jobID := fmt.Sprintf("job_%d", time.Now().UnixNano())
 
@@ -604,6 +717,39 @@ func registerShellBackgroundJob(sess *session.Session, cmd *exec.Cmd, cmdStr, wo
 	return job, jobID
 }
 
+// BackgroundJobCompleted reports whether a background job has finished. Jobs
+// started by the shell actor only signal completion through their Done channel.
+func BackgroundJobCompleted(job *session.BackgroundJob) bool {
+	job.Mu.RLock()
+	completed := job.Completed
+	done := job.Done
+	job.Mu.RUnlock()
+
+	if completed {
+		return true
+	}
+	if done == nil {
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// SignalBackgroundJob sends SIGTERM (default) or SIGKILL to a background job,
+// preferring its process group so children started by the job stop as well.
+// It returns the normalized signal name.
+func SignalBackgroundJob(job *session.BackgroundJob, signal string) (string, error) {
+	sig, signalName, err := parseStopSignal(signal)
+	if err != nil {
+		return "", err
+	}
+	return signalName, sendSignalToBackgroundJob(job, sig, signalName)
+}
+
 func sendSignalToBackgroundJob(job *session.BackgroundJob, sig syscall.Signal, signalName string) error {
 	job.Mu.RLock()
 	processGroupID := job.ProcessGroupID
@@ -709,20 +855,9 @@ func (t *StopProgramTool) Execute(ctx context.Context, params map[string]interfa
 		return &ToolResult{Error: fmt.Sprintf("job not found: %s", jobID)}
 	}
 
-	signalInput := strings.ToUpper(strings.TrimSpace(GetStringParam(params, "signal", "SIGTERM")))
-	var (
-		sig        syscall.Signal
-		signalName string
-	)
-	switch signalInput {
-	case "", "TERM", "SIGTERM":
-		sig = syscall.SIGTERM
-		signalName = "SIGTERM"
-	case "KILL", "SIGKILL":
-		sig = syscall.SIGKILL
-		signalName = "SIGKILL"
-	default:
-		return &ToolResult{Error: fmt.Sprintf("unsupported signal: %s", signalInput)}
+	sig, signalName, err := parseStopSignal(GetStringParam(params, "signal", "SIGTERM"))
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
 	}
 
 	job.Mu.RLock()
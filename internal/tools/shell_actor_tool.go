@@ -81,7 +81,17 @@ func (t *ShellToolWithActor) executeForeground(ctx context.Context, cmdStr, work
 	}
 
 	args := []string{"sh", "-c", cmdStr}
-	stdout, stderr, exitCode, err := t.shellActor.ExecuteCommand(ctx, args, workingDir, timeout, "")
+	var (
+		stdout, stderr string
+		exitCode       int
+		err            error
+	)
+	streamer, canStream := t.shellActor.(ShellStreamExecutor)
+	if onOutput := shellOutputFuncFromContext(ctx); onOutput != nil && canStream {
+		stdout, stderr, exitCode, err = streamer.ExecuteCommandStreaming(ctx, args, workingDir, timeout, "", nil, onOutput)
+	} else {
+		stdout, stderr, exitCode, err = t.shellActor.ExecuteCommand(ctx, args, workingDir, timeout, "")
+	}
 	if err != nil {
 		return &ToolResult{
 			Result: map[string]interface{}{
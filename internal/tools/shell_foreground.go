@@ -11,10 +11,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/logger"
 	"github.com/codefionn/scriptschnell/internal/session"
 )
 
+// shellReaderDrainTimeout is how long the output of an exited command is
+// drained while processes it left behind still hold the pipes
+const shellReaderDrainTimeout = 500 * time.Millisecond
+
 type shellCommandRunner struct {
 	tool        *ShellTool
 	command     string
@@ -53,20 +58,32 @@ func (r *shellCommandRunner) run(ctx context.Context) *ToolResult {
 	cmd.Dir = r.workingDir
 	cmd.Env = os.Environ()
 
-	stdout, err := cmd.StdoutPipe()
+	// Use our own pipes instead of StdoutPipe: cmd.Wait closes those as soon
+	// as the process exits, which can drop output the readers haven't
+	// consumed yet
+	stdout, stdoutWriter, err := os.Pipe()
 	if err != nil {
 		logger.Error("shell: failed to create stdout pipe: %v", err)
 		return &ToolResult{Error: fmt.Sprintf("failed to create stdout pipe: %v", err)}
 	}
-
-	stderr, err := cmd.StderrPipe()
+	stderr, stderrWriter, err := os.Pipe()
 	if err != nil {
+		_ = stdout.Close()
+		_ = stdoutWriter.Close()
 		logger.Error("shell: failed to create stderr pipe: %v", err)
 		return &ToolResult{Error: fmt.Sprintf("failed to create stderr pipe: %v", err)}
 	}
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
 
 	r.startedAt = time.Now()
-	if err := cmd.Start(); err != nil {
+	err = cmd.Start()
+	// The child holds its own copies of the write ends
+	_ = stdoutWriter.Close()
+	_ = stderrWriter.Close()
+	if err != nil {
+		_ = stdout.Close()
+		_ = stderr.Close()
 		logger.Error("shell: failed to start command: %v", err)
 		return &ToolResult{Error: fmt.Sprintf("failed to start command: %v", err)}
 	}
@@ -75,7 +92,9 @@ func (r *shellCommandRunner) run(ctx context.Context) *ToolResult {
 
 	r.done = make(chan error, 1)
 	go func() {
-		r.done <- cmd.Wait()
+		err := cmd.Wait()
+		r.drainReaders(stdout, stderr)
+		r.done <- err
 	}()
 
 	var (
@@ -153,6 +172,27 @@ func (r *shellCommandRunner) startReaders(stdout io.Reader, stderr io.Reader) {
 	r.startStreamReader(stderr, r.output.handleStderrChunk)
 }
 
+// drainReaders waits until the readers consumed all output of the exited
+// command. Processes it started in the background may keep the pipes open, so
+// the pipes are closed after shellReaderDrainTimeout.
+func (r *shellCommandRunner) drainReaders(pipes ...io.Closer) {
+	drained := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(shellReaderDrainTimeout):
+		logger.Debug("shell: output pipes still open after the command exited, closing them")
+	}
+	for _, pipe := range pipes {
+		_ = pipe.Close()
+	}
+	<-drained
+}
+
 func (r *shellCommandRunner) startStreamReader(reader io.Reader, handler func([]byte)) {
 	r.wg.Add(1)
 	go func() {
@@ -264,6 +304,7 @@ type shellOutput struct {
 	stderrPending string
 	combined      strings.Builder
 	job           *session.BackgroundJob
+	onOutput      actor.ShellOutputFunc // streams output until the command is backgrounded
 }
 
 func newShellOutput() *shellOutput {
@@ -274,15 +315,27 @@ func newShellOutput() *shellOutput {
 }
 
 func (o *shellOutput) handleStdoutChunk(chunk []byte) {
-	o.mu.Lock()
-	defer o.mu.Unlock()
-	o.processChunk(chunk, true)
+	o.handleChunk(chunk, true)
 }
 
 func (o *shellOutput) handleStderrChunk(chunk []byte) {
+	o.handleChunk(chunk, false)
+}
+
+// handleChunk records chunk and then streams it outside the lock, so a slow
+// output callback does not block the other stream or backgrounding
+func (o *shellOutput) handleChunk(chunk []byte, isStdout bool) {
 	o.mu.Lock()
-	defer o.mu.Unlock()
-	o.processChunk(chunk, false)
+	onOutput := o.onOutput
+	if o.job != nil {
+		onOutput = nil
+	}
+	o.processChunk(chunk, isStdout)
+	o.mu.Unlock()
+
+	if onOutput != nil {
+		onOutput(string(chunk), !isStdout)
+	}
 }
 
 func (o *shellOutput) processChunk(chunk []byte, isStdout bool) {
@@ -2,11 +2,17 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/progress"
+	"github.com/codefionn/scriptschnell/internal/secretdetect"
 	"github.com/codefionn/scriptschnell/internal/session"
 )
 
@@ -383,3 +389,263 @@ func TestShellTool_BackgroundShortcut(t *testing.T) {
 		t.Errorf("expected job to be marked completed")
 	}
 }
+
+// shellOutputRecorder collects streamed shell output chunks
+type shellOutputRecorder struct {
+	mu     sync.Mutex
+	chunks []string
+	first  chan struct{}
+}
+
+func newShellOutputRecorder() *shellOutputRecorder {
+	return &shellOutputRecorder{first: make(chan struct{})}
+}
+
+func (r *shellOutputRecorder) record(chunk string, isStderr bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chunks = append(r.chunks, chunk)
+	if len(r.chunks) == 1 {
+		close(r.first)
+	}
+}
+
+func (r *shellOutputRecorder) output() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.chunks, "")
+}
+
+func TestShellTool_StreamsOutput(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-based tests require sh on non-Windows platforms")
+	}
+
+	workingDir := t.TempDir()
+	tool := NewShellTool(session.NewSession("test", workingDir), workingDir)
+
+	recorder := newShellOutputRecorder()
+	ctx := ContextWithShellOutput(context.Background(), recorder.record)
+
+	resultCh := make(chan *ToolResult, 1)
+	go func() {
+		resultCh <- tool.Execute(ctx, map[string]interface{}{"command": "echo one; sleep 1; echo two"})
+	}()
+
+	select {
+	case <-recorder.first:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected output to be streamed while the command runs")
+	}
+	select {
+	case <-resultCh:
+		t.Fatal("expected the first chunk before the final result")
+	default:
+	}
+	if got := recorder.output(); got != "one\n" {
+		t.Errorf("expected only the first line so far, got %q", got)
+	}
+
+	var result *ToolResult
+	select {
+	case result = <-resultCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for shell execution result")
+	}
+
+	resMap, ok := result.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map result, got %T", result.Result)
+	}
+	if stdout, _ := resMap["stdout"].(string); stdout != "one\ntwo\n" {
+		t.Errorf("expected full output in the final result, got %q", stdout)
+	}
+	if got := recorder.output(); got != "one\ntwo\n" {
+		t.Errorf("expected all output to be streamed, got %q", got)
+	}
+}
+
+func TestShellTool_KeepsOutputWrittenRightBeforeExit(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-based tests require sh on non-Windows platforms")
+	}
+
+	workingDir := t.TempDir()
+	tool := NewShellTool(session.NewSession("test", workingDir), workingDir)
+
+	want := strings.Repeat("line\n", 2000)
+	for i := 0; i < 20; i++ {
+		result := tool.Execute(context.Background(), map[string]interface{}{"command": "i=0; while [ $i -lt 2000 ]; do echo line; i=$((i+1)); done"})
+		resMap, ok := result.Result.(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected map result, got %T (%s)", result.Result, result.Error)
+		}
+		if stdout, _ := resMap["stdout"].(string); stdout != want {
+			t.Fatalf("run %d: expected %d bytes of output, got %d", i, len(want), len(stdout))
+		}
+	}
+}
+
+func TestShellTool_ReturnsWhileBackgroundChildHoldsOutput(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-based tests require sh on non-Windows platforms")
+	}
+
+	workingDir := t.TempDir()
+	tool := NewShellTool(session.NewSession("test", workingDir), workingDir)
+
+	resultCh := make(chan *ToolResult, 1)
+	go func() {
+		resultCh <- tool.Execute(context.Background(), map[string]interface{}{"command": "sleep 10 & echo started"})
+	}()
+
+	select {
+	case result := <-resultCh:
+		resMap, ok := result.Result.(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected map result, got %T (%s)", result.Result, result.Error)
+		}
+		if stdout, _ := resMap["stdout"].(string); stdout != "started\n" {
+			t.Errorf("expected the output of the command, got %q", stdout)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not return while its background child kept the output open")
+	}
+}
+
+func TestShellTool_StopsStreamingWhenBackgrounded(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-based tests require sh on non-Windows platforms")
+	}
+
+	workingDir := t.TempDir()
+	sess := session.NewSession("test", workingDir)
+	tool := NewShellTool(sess, workingDir)
+
+	recorder := newShellOutputRecorder()
+	backgroundChan := make(chan struct{}, 1)
+	ctx := ContextWithShellBackground(context.Background(), backgroundChan)
+	ctx = ContextWithShellOutput(ctx, recorder.record)
+
+	// The command only prints more output once the test created the release
+	// file, which happens after the command was backgrounded
+	release := filepath.Join(workingDir, "release")
+	resultCh := make(chan *ToolResult, 1)
+	go func() {
+		command := fmt.Sprintf("echo before; while [ ! -e %q ]; do sleep 0.05; done; echo after", release)
+		resultCh <- tool.Execute(ctx, map[string]interface{}{"command": command})
+	}()
+
+	select {
+	case <-recorder.first:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected output to be streamed before backgrounding")
+	}
+	backgroundChan <- struct{}{}
+
+	var result *ToolResult
+	select {
+	case result = <-resultCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for shell execution result")
+	}
+
+	resMap, _ := result.Result.(map[string]interface{})
+	jobID, _ := resMap["job_id"].(string)
+	job, ok := sess.GetBackgroundJob(jobID)
+	if !ok {
+		t.Fatalf("expected a background job, got %+v", result.Result)
+	}
+	if err := os.WriteFile(release, nil, 0o644); err != nil {
+		t.Fatalf("failed to create release file: %v", err)
+	}
+	select {
+	case <-job.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("background job %s did not complete", jobID)
+	}
+
+	if got := recorder.output(); got != "before\n" {
+		t.Errorf("expected streaming to stop once backgrounded, got %q", got)
+	}
+	job.Mu.RLock()
+	defer job.Mu.RUnlock()
+	if strings.Join(job.Stdout, "\n") != "before\nafter" {
+		t.Errorf("expected job to keep collecting output, got %q", job.Stdout)
+	}
+}
+
+func TestShellTool_ResultRedactsKnownAPIKey(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-based tests require sh on non-Windows platforms")
+	}
+
+	const apiKey = "test-provider-key-9f8e7d6c5b4a"
+
+	workingDir := t.TempDir()
+	sess := session.NewSession("test", workingDir)
+
+	redactor := secretdetect.NewRedactor("")
+	redactor.AddKnownSecret(apiKey)
+
+	registry := NewRegistry(nil)
+	registry.Register(NewShellTool(sess, workingDir))
+	registry.SetResultRedactor(redactor)
+
+	result := registry.Execute(context.Background(), &ToolCall{
+		ID:         "call-1",
+		Name:       ToolNameShell,
+		Parameters: map[string]interface{}{"command": "echo key=" + apiKey},
+	})
+	if result.Error != "" {
+		t.Fatalf("shell execute returned error: %s", result.Error)
+	}
+
+	resMap, ok := result.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map result, got %T", result.Result)
+	}
+	stdout, _ := resMap["stdout"].(string)
+	if strings.Contains(stdout, apiKey) {
+		t.Fatalf("API key leaked into shell result: %q", stdout)
+	}
+	if !strings.Contains(stdout, "key="+secretdetect.DefaultRedactionPlaceholder) {
+		t.Errorf("expected masked key in shell result, got %q", stdout)
+	}
+}
+
+func TestShellOutputStatus_RedactsTailAcrossChunks(t *testing.T) {
+	t.Parallel()
+
+	const apiKey = "test-provider-key-9f8e7d6c5b4a"
+	redactor := secretdetect.NewRedactor("")
+	redactor.AddKnownSecret(apiKey)
+
+	var statuses []string
+	onOutput := ShellOutputStatus(func(update progress.Update) error {
+		statuses = append(statuses, update.Message)
+		return nil
+	}, redactor)
+
+	onOutput("one\ntwo\n", false)
+	onOutput("key="+apiKey[:10], false)
+	onOutput(apiKey[10:]+"\nfour\n", true)
+
+	last := statuses[len(statuses)-1]
+	if last != "two\nkey="+secretdetect.DefaultRedactionPlaceholder+"\nfour" {
+		t.Errorf("expected the redacted last three lines, got %q", last)
+	}
+	for _, status := range statuses {
+		if strings.Contains(status, apiKey) {
+			t.Errorf("API key leaked into status: %q", status)
+		}
+	}
+}
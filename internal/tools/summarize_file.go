@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/codefionn/scriptschnell/internal/fs"
-	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/summarizer"
 )
 
 // SummarizeFileToolSpec is the static specification for the read_file_summarized tool
@@ -45,16 +45,16 @@ func (s *SummarizeFileToolSpec) Parameters() map[string]interface{} {
 
 // SummarizeFileTool is the executor with runtime dependencies
 type SummarizeFileTool struct {
-	fs              fs.FileSystem
-	session         *session.Session
-	summarizeClient llm.Client
+	fs         fs.FileSystem
+	session    *session.Session
+	summarizer *summarizer.Summarizer
 }
 
-func NewSummarizeFileTool(filesystem fs.FileSystem, sess *session.Session, summarizeClient llm.Client) *SummarizeFileTool {
+func NewSummarizeFileTool(filesystem fs.FileSystem, sess *session.Session, svc *summarizer.Summarizer) *SummarizeFileTool {
 	return &SummarizeFileTool{
-		fs:              filesystem,
-		session:         sess,
-		summarizeClient: summarizeClient,
+		fs:         filesystem,
+		session:    sess,
+		summarizer: svc,
 	}
 }
 
@@ -96,17 +96,12 @@ func (t *SummarizeFileTool) Execute(ctx context.Context, params map[string]inter
 
 	content := string(data)
 
-	// Create prompt for summarization
-	prompt := fmt.Sprintf(`Please summarize the following file based on this goal: %s
-
-File: %s
-Content:
-%s
-
-Provide a concise summary focusing on the specified goal.`, goal, path, content)
-
 	// Call summarize LLM
-	response, err := t.summarizeClient.Complete(ctx, prompt)
+	response, err := t.summarizer.Summarize(ctx, summarizer.TextRequest{
+		Context:     fmt.Sprintf("File: %s", path),
+		Instruction: fmt.Sprintf("Please summarize the following file based on this goal: %s", goal),
+		Text:        content,
+	})
 	if err != nil {
 		return &ToolResult{Error: fmt.Sprintf("error generating summary: %v", err)}
 	}
@@ -124,8 +119,8 @@ Provide a concise summary focusing on the specified goal.`, goal, path, content)
 }
 
 // NewSummarizeFileToolFactory creates a factory for SummarizeFileTool
-func NewSummarizeFileToolFactory(filesystem fs.FileSystem, sess *session.Session, summarizeClient llm.Client) ToolFactory {
+func NewSummarizeFileToolFactory(filesystem fs.FileSystem, sess *session.Session, svc *summarizer.Summarizer) ToolFactory {
 	return func(reg *Registry) ToolExecutor {
-		return NewSummarizeFileTool(filesystem, sess, summarizeClient)
+		return NewSummarizeFileTool(filesystem, sess, svc)
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/codefionn/scriptschnell/internal/fs"
 	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/summarizer"
 )
 
 // MockSummarizeClient is a mock LLM client for testing
@@ -92,7 +93,7 @@ func TestSummarizeFileTool_Name(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	if tool.Name() != ToolNameReadFileSummarized {
 		t.Errorf("expected name %s, got %s", ToolNameReadFileSummarized, tool.Name())
@@ -103,7 +104,7 @@ func TestSummarizeFileTool_MissingPath(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"goal": "summarize",
@@ -122,7 +123,7 @@ func TestSummarizeFileTool_MissingGoal(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"path": "test.txt",
@@ -141,7 +142,7 @@ func TestSummarizeFileTool_FileNotFound(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"path": "nonexistent.txt",
@@ -161,7 +162,7 @@ func TestSummarizeFileTool_RejectsBinaryFile(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "lib.dylib", []byte{0x7f, 'E', 'L', 'F', 0x00})
 
@@ -183,7 +184,7 @@ func TestSummarizeFileTool_SuccessfulSummarization(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "This is a summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	content := "line 1\nline 2\nline 3"
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte(content))
@@ -216,7 +217,7 @@ func TestSummarizeFileTool_TracksFileInSession(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	content := "test content"
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte(content))
@@ -235,7 +236,7 @@ func TestSummarizeFileTool_LLMError(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{err: fmt.Errorf("LLM error")}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte("content"))
 
@@ -266,7 +267,7 @@ func TestSummarizeFileTool_PromptContainsGoalAndContent(t *testing.T) {
 		},
 	}
 
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	content := "important content"
 	goal := "extract key points"
@@ -293,7 +294,7 @@ func TestSummarizeFileTool_PromptContainsGoalAndContent(t *testing.T) {
 func TestSummarizeFileTool_NilSession(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, nil, client)
+	tool := NewSummarizeFileTool(mockFS, nil, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte("content"))
 
@@ -312,7 +313,7 @@ func TestSummarizeFileTool_LargeFile(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary of large file"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	// Create a large file
 	var lines []string
@@ -341,7 +342,7 @@ func TestSummarizeFileTool_EmptyFile(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "empty file summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "empty.txt", []byte(""))
 
@@ -359,7 +360,7 @@ func TestSummarizeFileTool_SpecialCharactersInGoal(t *testing.T) {
 	mockFS := fs.NewMockFS()
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewSummarizeFileTool(mockFS, sess, client)
+	tool := NewSummarizeFileTool(mockFS, sess, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte("content"))
 
@@ -384,7 +385,7 @@ func TestNewSummarizeFileToolFactory(t *testing.T) {
 	sess := session.NewSession("test-session", ".")
 	client := &MockSummarizeClient{response: "summary"}
 
-	factory := NewSummarizeFileToolFactory(mockFS, sess, client)
+	factory := NewSummarizeFileToolFactory(mockFS, sess, summarizer.NewSummarizer(client))
 	registry := NewRegistry(nil)
 
 	executor := factory(registry)
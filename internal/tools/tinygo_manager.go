@@ -6,6 +6,8 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,13 +28,21 @@ const (
 	tinyGoBaseURL = "https://github.com/tinygo-org/tinygo/releases/download"
 )
 
+// tinyGoChecksums pins the SHA-256 digests of the tinyGoVersion release
+// assets by file name and has to be updated together with tinyGoVersion.
+// Assets without a pinned digest are never downloaded.
+var tinyGoChecksums = map[string]string{}
+
 // TinyGoManager handles downloading and caching TinyGo compiler
 type TinyGoManager struct {
 	cacheDir       string
 	mu             sync.Mutex
 	logger         *logger.Logger
-	statusCallback func(string) // Callback for status updates (e.g., to TUI)
-	cachedPath     string       // memoized binary path once resolved
+	statusCallback func(string)      // Callback for status updates (e.g., to TUI)
+	cachedPath     string            // memoized binary path once resolved
+	autoInstall    bool              // Download TinyGo if it is neither on PATH nor cached
+	baseURL        string            // Release download base URL (empty = tinyGoBaseURL)
+	checksums      map[string]string // Pinned SHA-256 digests by asset name (nil = tinyGoChecksums)
 }
 
 // NewTinyGoManager creates a new TinyGo manager with platform-specific cache directory
@@ -48,6 +58,12 @@ func NewTinyGoManager() (*TinyGoManager, error) {
 	}, nil
 }
 
+// ModuleCacheDir returns the Go module cache holding the third-party modules
+// approved for sandbox programs, shared across compilations
+func (m *TinyGoManager) ModuleCacheDir() string {
+	return filepath.Join(m.cacheDir, "gomodcache")
+}
+
 // SetStatusCallback sets a callback function for status updates
 func (m *TinyGoManager) SetStatusCallback(callback func(string)) {
 	m.mu.Lock()
@@ -55,6 +71,14 @@ func (m *TinyGoManager) SetStatusCallback(callback func(string)) {
 	m.statusCallback = callback
 }
 
+// SetAutoInstall enables downloading the pinned TinyGo release when TinyGo is
+// neither on PATH nor cached. Embedded archives are always used if present.
+func (m *TinyGoManager) SetAutoInstall(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autoInstall = enabled
+}
+
 // updateStatus sends a status update if callback is set
 // This acquires the lock internally, so it should NOT be called while holding the lock
 func (m *TinyGoManager) updateStatus(status string) {
@@ -198,13 +222,10 @@ func (m *TinyGoManager) GetTinyGoBinary(ctx context.Context) (string, error) {
 		}
 	}
 
-	// Download and extract TinyGo
-	m.logger.Info("TinyGo not found in cache, downloading version %s...", tinyGoVersion)
-	m.updateStatusLocked(fmt.Sprintf("Downloading TinyGo %s (first use only, ~50MB)...", tinyGoVersion))
-
-	if err := m.downloadTinyGo(ctx); err != nil {
+	// Extract the embedded archive or download TinyGo
+	if err := m.installTinyGo(ctx); err != nil {
 		m.updateStatusLocked("")
-		return "", fmt.Errorf("failed to download TinyGo: %w", err)
+		return "", err
 	}
 
 	m.logger.Info("TinyGo downloaded and cached successfully")
@@ -220,9 +241,29 @@ func (m *TinyGoManager) GetTinyGoBinary(ctx context.Context) (string, error) {
 	return tinyGoPath, nil
 }
 
-// downloadTinyGo downloads and extracts the TinyGo distribution for the current platform
-func (m *TinyGoManager) downloadTinyGo(ctx context.Context) error {
-	// First, try to use embedded archive if available
+// installTinyGo installs TinyGo into the cache directory, preferring the
+// archive embedded in the binary over a download
+func (m *TinyGoManager) installTinyGo(ctx context.Context) error {
+	if m.extractEmbeddedTinyGo() {
+		return nil
+	}
+
+	if !m.autoInstall {
+		return fmt.Errorf("TinyGo %s not found in PATH or cache and automatic installation is disabled (set auto_install_tinygo to true to download it)", tinyGoVersion)
+	}
+
+	m.logger.Info("TinyGo not found in cache, downloading version %s...", tinyGoVersion)
+	m.updateStatusLocked(fmt.Sprintf("Downloading TinyGo %s (first use only, ~50MB)...", tinyGoVersion))
+
+	if err := m.downloadTinyGo(ctx); err != nil {
+		return fmt.Errorf("failed to download TinyGo: %w", err)
+	}
+	return nil
+}
+
+// extractEmbeddedTinyGo extracts the TinyGo archive embedded in the binary, if
+// any, and reports whether it succeeded
+func (m *TinyGoManager) extractEmbeddedTinyGo() bool {
 	if HasEmbeddedArchive() {
 		m.logger.Info("Using embedded TinyGo archive from binary")
 		archiveData := GetEmbeddedArchive()
@@ -232,7 +273,8 @@ func (m *TinyGoManager) downloadTinyGo(ctx context.Context) error {
 
 			extractDir := filepath.Join(m.cacheDir, tinyGoVersion)
 			if err := os.MkdirAll(extractDir, 0755); err != nil {
-				return fmt.Errorf("failed to create cache directory: %w", err)
+				m.logger.Warn("Failed to create cache directory for embedded TinyGo: %v", err)
+				return false
 			}
 
 			m.updateStatusLocked(fmt.Sprintf("Extracting embedded TinyGo %s...", tinyGoVersion))
@@ -241,25 +283,34 @@ func (m *TinyGoManager) downloadTinyGo(ctx context.Context) error {
 					m.logger.Warn("Failed to extract embedded archive, falling back to download: %v", err)
 				} else {
 					m.logger.Info("Successfully extracted embedded TinyGo")
-					return nil
+					return true
 				}
 			} else {
 				if err := m.extractTarGzData(archiveData, extractDir); err != nil {
 					m.logger.Warn("Failed to extract embedded archive, falling back to download: %v", err)
 				} else {
 					m.logger.Info("Successfully extracted embedded TinyGo")
-					return nil
+					return true
 				}
 			}
 		}
 	}
+	return false
+}
 
+// downloadTinyGo downloads, verifies and extracts the TinyGo distribution for the current platform
+func (m *TinyGoManager) downloadTinyGo(ctx context.Context) error {
 	// Determine platform-specific download URL
 	downloadURL, fileName, err := m.getTinyGoDownloadURL()
 	if err != nil {
 		return err
 	}
 
+	expectedChecksum, err := m.tinyGoChecksum(fileName)
+	if err != nil {
+		return err
+	}
+
 	m.logger.Info("Downloading TinyGo from %s", downloadURL)
 
 	// Create temporary file for download
@@ -319,11 +370,17 @@ func (m *TinyGoManager) downloadTinyGo(ctx context.Context) error {
 		},
 	}
 
-	if _, err := io.Copy(tmpFile, progressReader); err != nil {
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpFile, hasher), progressReader); err != nil {
 		return fmt.Errorf("failed to save download: %w", err)
 	}
 	_ = tmpFile.Close()
 
+	m.updateStatusLocked(fmt.Sprintf("Verifying TinyGo %s checksum...", tinyGoVersion))
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expectedChecksum {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", fileName, expectedChecksum, actual)
+	}
+
 	// Extract to cache directory
 	m.logger.Info("Extracting TinyGo...")
 	m.updateStatusLocked(fmt.Sprintf("Extracting TinyGo %s...", tinyGoVersion))
@@ -338,44 +395,77 @@ func (m *TinyGoManager) downloadTinyGo(ctx context.Context) error {
 	return m.extractTarGz(tmpPath, extractDir)
 }
 
-// getTinyGoDownloadURL returns the download URL and filename for the current platform
-func (m *TinyGoManager) getTinyGoDownloadURL() (string, string, error) {
-	var fileName string
+// tinyGoPlatforms lists the GOOS/GOARCH pairs with a TinyGo release asset
+var tinyGoPlatforms = [][2]string{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"linux", "arm"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
+}
 
-	switch runtime.GOOS {
+// tinyGoAssetName returns the release asset name for a platform
+func tinyGoAssetName(goos, goarch string) (string, error) {
+	switch goos {
 	case "linux":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "amd64":
-			fileName = fmt.Sprintf("tinygo%s.linux-amd64.tar.gz", tinyGoVersion)
+			return fmt.Sprintf("tinygo%s.linux-amd64.tar.gz", tinyGoVersion), nil
 		case "arm64":
-			fileName = fmt.Sprintf("tinygo%s.linux-arm64.tar.gz", tinyGoVersion)
+			return fmt.Sprintf("tinygo%s.linux-arm64.tar.gz", tinyGoVersion), nil
 		case "arm":
-			fileName = fmt.Sprintf("tinygo%s.linux-armhf.tar.gz", tinyGoVersion)
+			return fmt.Sprintf("tinygo%s.linux-armhf.tar.gz", tinyGoVersion), nil
 		default:
-			return "", "", fmt.Errorf("unsupported Linux architecture: %s", runtime.GOARCH)
+			return "", fmt.Errorf("unsupported Linux architecture: %s", goarch)
 		}
 	case "darwin":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "amd64":
-			fileName = fmt.Sprintf("tinygo%s.darwin-amd64.tar.gz", tinyGoVersion)
+			return fmt.Sprintf("tinygo%s.darwin-amd64.tar.gz", tinyGoVersion), nil
 		case "arm64":
-			fileName = fmt.Sprintf("tinygo%s.darwin-arm64.tar.gz", tinyGoVersion)
+			return fmt.Sprintf("tinygo%s.darwin-arm64.tar.gz", tinyGoVersion), nil
 		default:
-			return "", "", fmt.Errorf("unsupported macOS architecture: %s", runtime.GOARCH)
+			return "", fmt.Errorf("unsupported macOS architecture: %s", goarch)
 		}
 	case "windows":
-		if runtime.GOARCH != "amd64" {
-			return "", "", fmt.Errorf("unsupported Windows architecture: %s", runtime.GOARCH)
+		if goarch != "amd64" {
+			return "", fmt.Errorf("unsupported Windows architecture: %s", goarch)
 		}
-		fileName = fmt.Sprintf("tinygo%s.windows-amd64.zip", tinyGoVersion)
+		return fmt.Sprintf("tinygo%s.windows-amd64.zip", tinyGoVersion), nil
 	default:
-		return "", "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+		return "", fmt.Errorf("unsupported operating system: %s", goos)
+	}
+}
+
+// getTinyGoDownloadURL returns the download URL and filename for the current platform
+func (m *TinyGoManager) getTinyGoDownloadURL() (string, string, error) {
+	fileName, err := tinyGoAssetName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", "", err
 	}
 
-	url := fmt.Sprintf("%s/v%s/%s", tinyGoBaseURL, tinyGoVersion, fileName)
+	baseURL := m.baseURL
+	if baseURL == "" {
+		baseURL = tinyGoBaseURL
+	}
+	url := fmt.Sprintf("%s/v%s/%s", baseURL, tinyGoVersion, fileName)
 	return url, fileName, nil
 }
 
+// tinyGoChecksum returns the pinned SHA-256 digest of a release asset
+func (m *TinyGoManager) tinyGoChecksum(fileName string) (string, error) {
+	checksums := m.checksums
+	if checksums == nil {
+		checksums = tinyGoChecksums
+	}
+	digest, ok := checksums[fileName]
+	if !ok {
+		return "", fmt.Errorf("no checksum pinned for %s, refusing to download it", fileName)
+	}
+	return digest, nil
+}
+
 // extractTarGz extracts a tar.gz file to the destination directory
 func (m *TinyGoManager) extractTarGzData(data []byte, destDir string) error {
 	gzr, err := gzip.NewReader(bytes.NewReader(data))
@@ -19,13 +19,18 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -136,7 +141,8 @@ func TestIntegration_TinyGoManager_Download(t *testing.T) {
 	}()
 
 	mgr := &TinyGoManager{
-		cacheDir: tmpDir,
+		cacheDir:    tmpDir,
+		autoInstall: true,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -767,3 +773,192 @@ func TestExtractWithNestedDirectories(t *testing.T) {
 		t.Errorf("Expected nested file %s to exist", expectedPath)
 	}
 }
+
+// fakeTinyGoArchive builds a tar.gz archive laid out like a TinyGo release
+func fakeTinyGoArchive(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	content := "#!/bin/sh\necho tinygo version " + tinyGoVersion + "\n"
+	hdr := &tar.Header{
+		Name:     "tinygo/bin/tinygo",
+		Mode:     0755,
+		Size:     int64(len(content)),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to write content: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// newFakeTinyGoRelease serves archive as the release asset for the current
+// platform and pins digest as its checksum
+func newFakeTinyGoRelease(t *testing.T, archive []byte, digest string) (*TinyGoManager, *atomic.Int32) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake release serves a tar.gz archive")
+	}
+
+	mgr := &TinyGoManager{
+		cacheDir:    t.TempDir(),
+		logger:      logger.Global().WithPrefix("test"),
+		autoInstall: true,
+	}
+	_, fileName, err := mgr.getTinyGoDownloadURL()
+	if err != nil {
+		t.Skipf("platform not supported by TinyGo releases: %v", err)
+	}
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v"+tinyGoVersion+"/"+fileName {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		_, _ = w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+
+	mgr.baseURL = server.URL
+	mgr.checksums = map[string]string{
+		"tinygo" + tinyGoVersion + ".other.tar.gz": strings.Repeat("0", 64),
+		fileName: digest,
+	}
+
+	// Make sure a system TinyGo is not picked up instead of the download
+	t.Setenv("PATH", t.TempDir())
+
+	return mgr, &downloads
+}
+
+func TestTinyGoManager_AutoInstallVerifiesChecksum(t *testing.T) {
+	archive := fakeTinyGoArchive(t)
+	sum := sha256.Sum256(archive)
+	mgr, downloads := newFakeTinyGoRelease(t, archive, hex.EncodeToString(sum[:]))
+
+	var mu sync.Mutex
+	var statuses []string
+	mgr.SetStatusCallback(func(status string) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, status)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	binaryPath, err := mgr.GetTinyGoBinary(ctx)
+	if err != nil {
+		t.Fatalf("Failed to install TinyGo: %v", err)
+	}
+	if expected := filepath.Join(mgr.cacheDir, tinyGoVersion, "bin", "tinygo"); binaryPath != expected {
+		t.Errorf("Expected binary at %s, got %s", expected, binaryPath)
+	}
+
+	mu.Lock()
+	joined := strings.Join(statuses, "\n")
+	mu.Unlock()
+	for _, want := range []string{"Downloading TinyGo", "Verifying TinyGo", "Extracting TinyGo"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected status update containing %q, got:\n%s", want, joined)
+		}
+	}
+
+	// Installing again reuses the cached installation instead of downloading
+	mgr.cachedPath = ""
+	if _, err := mgr.GetTinyGoBinary(ctx); err != nil {
+		t.Fatalf("Failed to reuse installed TinyGo: %v", err)
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("Expected exactly one download, got %d", got)
+	}
+}
+
+func TestTinyGoManager_AutoInstallRejectsCorruptedDownload(t *testing.T) {
+	archive := fakeTinyGoArchive(t)
+	sum := sha256.Sum256(archive)
+
+	// Corrupt the served archive after computing the published checksum
+	corrupted := append([]byte(nil), archive...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	mgr, _ := newFakeTinyGoRelease(t, corrupted, hex.EncodeToString(sum[:]))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := mgr.GetTinyGoBinary(ctx)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected checksum mismatch error, got %v", err)
+	}
+
+	// Nothing may be extracted from a corrupted download
+	if _, err := os.Stat(filepath.Join(mgr.cacheDir, tinyGoVersion)); !os.IsNotExist(err) {
+		t.Errorf("Expected no TinyGo installation after a failed verification, got %v", err)
+	}
+}
+
+func TestTinyGoManager_AutoInstallDisabled(t *testing.T) {
+	archive := fakeTinyGoArchive(t)
+	sum := sha256.Sum256(archive)
+	mgr, downloads := newFakeTinyGoRelease(t, archive, hex.EncodeToString(sum[:]))
+	mgr.SetAutoInstall(false)
+
+	_, err := mgr.GetTinyGoBinary(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "auto_install_tinygo") {
+		t.Fatalf("Expected error mentioning auto_install_tinygo, got %v", err)
+	}
+	if got := downloads.Load(); got != 0 {
+		t.Errorf("Expected no download with auto install disabled, got %d", got)
+	}
+}
+
+func TestTinyGoManager_AutoInstallRequiresPinnedChecksum(t *testing.T) {
+	archive := fakeTinyGoArchive(t)
+	mgr, downloads := newFakeTinyGoRelease(t, archive, "")
+	_, fileName, _ := mgr.getTinyGoDownloadURL()
+	delete(mgr.checksums, fileName)
+
+	_, err := mgr.GetTinyGoBinary(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no checksum pinned for "+fileName) {
+		t.Fatalf("Expected error about the missing checksum, got %v", err)
+	}
+	if got := downloads.Load(); got != 0 {
+		t.Errorf("Expected no download without a pinned checksum, got %d", got)
+	}
+}
+
+func TestTinyGoChecksumsCoverSupportedPlatforms(t *testing.T) {
+	if len(tinyGoChecksums) == 0 {
+		t.Skip("no TinyGo release digests pinned for v" + tinyGoVersion)
+	}
+
+	for _, platform := range tinyGoPlatforms {
+		fileName, err := tinyGoAssetName(platform[0], platform[1])
+		if err != nil {
+			t.Fatalf("%s/%s: %v", platform[0], platform[1], err)
+		}
+		digest, ok := tinyGoChecksums[fileName]
+		if !ok {
+			t.Errorf("%s/%s: no checksum pinned for %s", platform[0], platform[1], fileName)
+			continue
+		}
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+			t.Errorf("%s/%s: %q is not a SHA-256 hex digest", platform[0], platform[1], digest)
+		}
+	}
+}
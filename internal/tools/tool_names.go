@@ -5,12 +5,14 @@ const (
 	ToolNameReadFileSummarized   = "read_file_summarized"
 	ToolNameCreateFile           = "create_file"
 	ToolNameReplaceFile          = "replace_file"
+	ToolNameReplaceInFiles       = "replace_in_files"
 	ToolNameEditFile             = "edit_file"
 	ToolNameShell                = "shell"
 	ToolNameCommand              = "command"
 	ToolNameStatusProgram        = "status_program"
 	ToolNameWaitProgram          = "wait_program"
 	ToolNameStopProgram          = "stop_program"
+	ToolNameBackgroundProcesses  = "background_processes"
 	ToolNameParallel             = "parallel_tool_execution"
 	ToolNameGoSandbox            = "go_sandbox"
 	ToolNameGoSandboxDomain      = "go_sandbox_domain"
@@ -27,5 +29,8 @@ const (
 	ToolNameGrepContextFiles     = "grep_context_files"
 	ToolNameReadContextFile      = "read_context_file"
 	ToolNameAddContextDirectory  = "add_context_directory"
+	ToolNameFetchDocs            = "fetch_docs"
 	ToolNameRefactoringAgent     = "refactoring_agent"
+	ToolNameMemory               = "memory"
+	ToolNameWaitForFile          = "wait_for_file"
 )
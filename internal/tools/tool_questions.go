@@ -0,0 +1,36 @@
+package tools
+
+import "context"
+
+// ToolQuestion is a follow-up question a tool asks the user mid-execution,
+// e.g. which of several matching files was meant
+type ToolQuestion struct {
+	// ID is the key under which the answer is passed back to the tool
+	ID       string   `json:"id"`
+	Question string   `json:"question"`
+	Options  []string `json:"options,omitempty"`
+}
+
+type questionAnswersKey struct{}
+
+// ContextWithQuestionAnswers returns a context carrying the user's answers to
+// the questions of a previous invocation of a tool, keyed by ToolQuestion.ID
+func ContextWithQuestionAnswers(ctx context.Context, answers map[string]string) context.Context {
+	if len(answers) == 0 {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, questionAnswersKey{}, answers)
+}
+
+// QuestionAnswersFromContext returns the answers stored by
+// ContextWithQuestionAnswers, or nil if the tool has not asked anything yet
+func QuestionAnswersFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	answers, _ := ctx.Value(questionAnswersKey{}).(map[string]string)
+	return answers
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQuestionAnswersContext(t *testing.T) {
+	ctx := context.Background()
+	if answers := QuestionAnswersFromContext(ctx); answers != nil {
+		t.Fatalf("expected no answers, got %v", answers)
+	}
+	if got := ContextWithQuestionAnswers(ctx, nil); got != ctx {
+		t.Error("expected the context to be unchanged without answers")
+	}
+
+	ctx = ContextWithQuestionAnswers(ctx, map[string]string{"file": "b.go"})
+	if got := QuestionAnswersFromContext(ctx)["file"]; got != "b.go" {
+		t.Errorf("expected answer b.go, got %q", got)
+	}
+}
@@ -5,19 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/codefionn/scriptschnell/internal/llm"
+	"github.com/codefionn/scriptschnell/internal/summarizer"
 )
 
 // ToolSummarizeTool wraps another tool call and summarizes its output using LLM
 type ToolSummarizeTool struct {
-	registry        *Registry
-	summarizeClient llm.Client
+	registry   *Registry
+	summarizer *summarizer.Summarizer
 }
 
-func NewToolSummarizeTool(registry *Registry, summarizeClient llm.Client) *ToolSummarizeTool {
+func NewToolSummarizeTool(registry *Registry, svc *summarizer.Summarizer) *ToolSummarizeTool {
 	return &ToolSummarizeTool{
-		registry:        registry,
-		summarizeClient: summarizeClient,
+		registry:   registry,
+		summarizer: svc,
 	}
 }
 
@@ -103,33 +103,21 @@ func (t *ToolSummarizeTool) Execute(ctx context.Context, args map[string]interfa
 	resultStr := fmt.Sprintf("%v", result.Result)
 
 	// Check if summarization client is available
-	if t.summarizeClient == nil {
+	if !t.summarizer.Available() {
 		// If no summarization client, just return the raw result
-		return &ToolResult{Result: fmt.Sprintf("Tool output (no summarization available):\n\n%s", resultStr)}
+		return &ToolResult{Result: fmt.Sprintf("Tool output (no summarization available):\n\n%s", t.summarizer.FallbackText(resultStr))}
 	}
 
-	// Build summarization prompt
-	prompt := fmt.Sprintf(`You are analyzing the output of a tool execution. The user wants specific information extracted.
-
-Tool executed: %s
-Tool arguments: %s
-User's goal: %s
-
-Tool output:
-%s
-
-Based on the user's goal, extract and return ONLY the relevant information. Be concise and direct. If the information isn't in the output, say so clearly.`,
-		toolName,
-		formatArgs(toolArgs),
-		summaryGoal,
-		resultStr,
-	)
-
 	// Summarize using LLM
-	summary, err := t.summarizeClient.Complete(ctx, prompt)
+	summary, err := t.summarizer.Summarize(ctx, summarizer.TextRequest{
+		Context:     fmt.Sprintf("You are analyzing the output of a tool execution. The user wants specific information extracted.\n\nTool executed: %s\nTool arguments: %s", toolName, formatArgs(toolArgs)),
+		Instruction: fmt.Sprintf("User's goal: %s\n\nBased on the user's goal, extract and return ONLY the relevant information.", summaryGoal),
+		Label:       "Tool output",
+		Text:        resultStr,
+	})
 	if err != nil {
 		// If summarization fails, return raw result with error note
-		return &ToolResult{Result: fmt.Sprintf("Note: Summarization failed (%v)\n\nRaw tool output:\n%s", err, resultStr)}
+		return &ToolResult{Result: fmt.Sprintf("Note: Summarization failed (%v)\n\nRaw tool output:\n%s", err, t.summarizer.FallbackText(resultStr))}
 	}
 
 	return &ToolResult{Result: summary}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/codefionn/scriptschnell/internal/fs"
 	"github.com/codefionn/scriptschnell/internal/session"
+	"github.com/codefionn/scriptschnell/internal/summarizer"
 )
 
 func TestToolSummarizeTool_Name(t *testing.T) {
@@ -59,7 +60,7 @@ func TestToolSummarizeTool_Parameters(t *testing.T) {
 func TestToolSummarizeTool_MissingToolName(t *testing.T) {
 	registry := NewRegistry(nil)
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_args":    map[string]interface{}{},
@@ -78,7 +79,7 @@ func TestToolSummarizeTool_MissingToolName(t *testing.T) {
 func TestToolSummarizeTool_MissingToolArgs(t *testing.T) {
 	registry := NewRegistry(nil)
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_name":    "read_file",
@@ -97,7 +98,7 @@ func TestToolSummarizeTool_MissingToolArgs(t *testing.T) {
 func TestToolSummarizeTool_InvalidToolArgs(t *testing.T) {
 	registry := NewRegistry(nil)
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_name":    "read_file",
@@ -117,7 +118,7 @@ func TestToolSummarizeTool_InvalidToolArgs(t *testing.T) {
 func TestToolSummarizeTool_MissingSummaryGoal(t *testing.T) {
 	registry := NewRegistry(nil)
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_name": "read_file",
@@ -136,7 +137,7 @@ func TestToolSummarizeTool_MissingSummaryGoal(t *testing.T) {
 func TestToolSummarizeTool_ToolNotFound(t *testing.T) {
 	registry := NewRegistry(nil)
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_name":    "nonexistent_tool",
@@ -162,7 +163,7 @@ func TestToolSummarizeTool_ToolExecutionError(t *testing.T) {
 	registry.Register(readTool)
 
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	// Don't create the file - should cause read_file to error
 	result := tool.Execute(context.Background(), map[string]interface{}{
@@ -191,7 +192,7 @@ func TestToolSummarizeTool_SuccessfulExecution(t *testing.T) {
 	registry.Register(readTool)
 
 	client := &MockSummarizeClient{response: "This is the summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	content := "line 1\nline 2\nline 3"
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte(content))
@@ -257,7 +258,7 @@ func TestToolSummarizeTool_SummarizationFailure(t *testing.T) {
 	registry.Register(readTool)
 
 	client := &MockSummarizeClient{err: fmt.Errorf("LLM error")}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte("content"))
 
@@ -299,7 +300,7 @@ func TestToolSummarizeTool_PromptContainsAllInfo(t *testing.T) {
 		},
 	}
 
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte("test content"))
 
@@ -337,7 +338,7 @@ func TestToolSummarizeTool_ComplexToolArgs(t *testing.T) {
 	registry.Register(readTool)
 
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	_ = mockFS.WriteFile(context.Background(), "test.txt", []byte("content"))
 
@@ -368,7 +369,7 @@ func TestToolSummarizeTool_ToolReturnsNil(t *testing.T) {
 	registry.Register(nilTool)
 
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_name":    "nil_tool",
@@ -399,7 +400,7 @@ func TestToolSummarizeTool_EmptyToolArgs(t *testing.T) {
 	registry.Register(simpleTool)
 
 	client := &MockSummarizeClient{response: "summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	result := tool.Execute(context.Background(), map[string]interface{}{
 		"tool_name":    "simple_tool",
@@ -425,7 +426,7 @@ func TestToolSummarizeTool_LongToolOutput(t *testing.T) {
 	registry.Register(readTool)
 
 	client := &MockSummarizeClient{response: "concise summary"}
-	tool := NewToolSummarizeTool(registry, client)
+	tool := NewToolSummarizeTool(registry, summarizer.NewSummarizer(client))
 
 	// Create a large file
 	var lines []string
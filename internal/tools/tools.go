@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/codefionn/scriptschnell/internal/logger"
 	"github.com/codefionn/scriptschnell/internal/progress"
 	"github.com/codefionn/scriptschnell/internal/secretdetect"
 )
@@ -148,6 +149,11 @@ type ToolResult struct {
 	// If UIResult is set, it will be used for UI display instead of Result
 	// The LLM will always receive Result in its messages
 	UIResult interface{} `json:"ui_result,omitempty"`
+
+	// Questions asks the user for input before the tool can complete. The
+	// orchestrator surfaces them through the question callback and invokes the
+	// tool again with the answers available via QuestionAnswersFromContext.
+	Questions []ToolQuestion `json:"questions,omitempty"`
 }
 
 // ExecutionMetadata captures detailed information about tool execution
@@ -186,6 +192,9 @@ type ExecutionMetadata struct {
 	ToolType string                 `json:"tool_type,omitempty"`
 	Details  map[string]interface{} `json:"details,omitempty"`
 
+	// Changes made by write tools
+	Diff *DiffSummary `json:"diff,omitempty"`
+
 	// Error classification
 	ErrorType    string `json:"error_type,omitempty"`    // "timeout", "permission", "not_found", "syntax", etc.
 	ErrorContext string `json:"error_context,omitempty"` // Additional context for the error
@@ -239,6 +248,8 @@ type Registry struct {
 	manualSuggestions map[string]*ManualSuggestion
 	writeMu           sync.Mutex
 	secretDetector    secretdetect.Detector
+	resultRedactor    *secretdetect.Redactor
+	dryRun            bool
 }
 
 // NewRegistry creates a new tool registry with an optional authorizer
@@ -269,6 +280,77 @@ func (r *Registry) SetSecretDetector(detector secretdetect.Detector) {
 	r.secretDetector = detector
 }
 
+// SetResultRedactor masks secrets in tool results (and errors) before they
+// are returned to the caller. Pass nil to disable.
+func (r *Registry) SetResultRedactor(redactor *secretdetect.Redactor) {
+	r.resultRedactor = redactor
+}
+
+// ResultRedactor returns the redactor set with SetResultRedactor, or nil
+func (r *Registry) ResultRedactor() *secretdetect.Redactor {
+	return r.resultRedactor
+}
+
+// redactResult applies the result redactor to a finished tool result
+func (r *Registry) redactResult(result *ToolResult) *ToolResult {
+	if r.resultRedactor == nil || result == nil {
+		return result
+	}
+	result.Result = redactValue(r.resultRedactor, result.Result)
+	result.UIResult = redactValue(r.resultRedactor, result.UIResult)
+	result.Error = r.resultRedactor.Redact(result.Error)
+	return result
+}
+
+// redactValue masks secrets in strings nested in a tool result value
+func redactValue(redactor *secretdetect.Redactor, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redactor.Redact(v)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = redactor.Redact(s)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(redactor, item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = redactValue(redactor, item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// SetDryRun makes the registry simulate tool calls that would change the
+// filesystem or run programs (see IsMutatingToolCall) instead of executing them
+func (r *Registry) SetDryRun(enabled bool) {
+	r.dryRun = enabled
+}
+
+// DryRun reports whether mutating tool calls are simulated
+func (r *Registry) DryRun() bool {
+	return r.dryRun
+}
+
+// simulateDryRun returns the simulated result of a mutating call in dry-run
+// mode, or nil if the call should be executed
+func (r *Registry) simulateDryRun(call *ToolCall) *ToolResult {
+	if !r.dryRun || !IsMutatingToolCall(call.Name, call.Parameters) {
+		return nil
+	}
+	logger.Info("Dry run: simulating %s (id=%s)", call.Name, call.ID)
+	return r.redactResult(SimulateToolCall(call))
+}
+
 type exclusiveToolSpec interface {
 	RequiresExclusiveExecution() bool
 }
@@ -520,6 +602,10 @@ func (r *Registry) ExecuteWithApproval(ctx context.Context, call *ToolCall) *Too
 
 	// Skip authorization check - user has already approved
 
+	if simulated := r.simulateDryRun(call); simulated != nil {
+		return simulated
+	}
+
 	result := r.executeWithWriteLock(entry.exclusive, func() *ToolResult {
 		return executor.Execute(ctx, call.Parameters)
 	})
@@ -531,7 +617,7 @@ func (r *Registry) ExecuteWithApproval(ctx context.Context, call *ToolCall) *Too
 	}
 
 	result.ID = call.ID
-	return result
+	return r.redactResult(result)
 }
 
 // NewToolResultWithMetadata creates a ToolResult with execution metadata
@@ -709,6 +795,10 @@ func (r *Registry) Execute(ctx context.Context, call *ToolCall) *ToolResult {
 		}
 	}
 
+	if simulated := r.simulateDryRun(call); simulated != nil {
+		return simulated
+	}
+
 	result := r.executeWithWriteLock(entry.exclusive, func() *ToolResult {
 		return executor.Execute(ctx, call.Parameters)
 	})
@@ -720,7 +810,7 @@ func (r *Registry) Execute(ctx context.Context, call *ToolCall) *ToolResult {
 	}
 
 	result.ID = call.ID
-	return result
+	return r.redactResult(result)
 }
 
 // ExecuteWithCallbacks executes a tool call with optional callbacks and optional authorization skipping.
@@ -775,6 +865,10 @@ func (r *Registry) ExecuteWithCallbacks(ctx context.Context, call *ToolCall, too
 		}
 	}
 
+	if simulated := r.simulateDryRun(call); simulated != nil {
+		return simulated
+	}
+
 	// Allow tools to consume callbacks if they support it
 	if cbTool, ok := executor.(interface {
 		ExecuteWithCallbacks(ctx context.Context, params map[string]interface{}, progressCb progress.Callback, toolCallCb func(string, string, map[string]interface{}) error, toolResultCb func(string, string, string, string) error) *ToolResult
@@ -789,7 +883,7 @@ func (r *Registry) ExecuteWithCallbacks(ctx context.Context, call *ToolCall, too
 			}
 		}
 		result.ID = call.ID
-		return result
+		return r.redactResult(result)
 	}
 
 	// Fallback to regular execution
@@ -803,7 +897,7 @@ func (r *Registry) ExecuteWithCallbacks(ctx context.Context, call *ToolCall, too
 		}
 	}
 	result.ID = call.ID
-	return result
+	return r.redactResult(result)
 }
 
 // ToJSONSchema converts tools to JSON schema format for LLM
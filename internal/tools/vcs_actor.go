@@ -25,11 +25,19 @@ type (
 	VCSRefreshBranchMsg struct {
 		ResponseChan chan string
 	}
+
+	// VCSSetRepositoryMsg replaces the VCS (e.g. after a working directory
+	// change) and responds with its current branch
+	VCSSetRepositoryMsg struct {
+		VCS          vcs.VCS
+		ResponseChan chan string
+	}
 )
 
 // Implement actor.Message interface for all message types
 func (m VCSGetCurrentBranchMsg) Type() string { return "VCSGetCurrentBranchMsg" }
 func (m VCSRefreshBranchMsg) Type() string    { return "VCSRefreshBranchMsg" }
+func (m VCSSetRepositoryMsg) Type() string    { return "VCSSetRepositoryMsg" }
 
 // VCSActor manages VCS state as an actor
 type VCSActor struct {
@@ -105,6 +113,20 @@ func (a *VCSActor) Receive(ctx context.Context, msg actor.Message) error {
 		}
 		return nil
 
+	case VCSSetRepositoryMsg:
+		a.vcs = m.VCS
+		branch := ""
+		if a.vcs != nil {
+			if current, err := a.vcs.CurrentBranch(ctx); err == nil {
+				branch = current
+			}
+		}
+		a.mu.Lock()
+		a.branch = branch
+		a.mu.Unlock()
+		m.ResponseChan <- branch
+		return nil
+
 	default:
 		return fmt.Errorf("unknown message type: %T", msg)
 	}
@@ -139,3 +161,13 @@ func (c *VCSActorClient) RefreshBranch() (string, error) {
 	}
 	return <-respChan, nil
 }
+
+// SetRepository replaces the VCS of the actor and returns its current branch.
+// A nil VCS means the new working directory is not under version control.
+func (c *VCSActorClient) SetRepository(vcsInstance vcs.VCS) (string, error) {
+	respChan := make(chan string, 1)
+	if err := c.actorRef.Send(VCSSetRepositoryMsg{VCS: vcsInstance, ResponseChan: respChan}); err != nil {
+		return "", err
+	}
+	return <-respChan, nil
+}
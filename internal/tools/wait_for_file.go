@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+)
+
+const (
+	// defaultWaitForTimeoutSeconds is used when no timeout is given
+	defaultWaitForTimeoutSeconds = 60
+	// maxWaitForTimeoutSeconds caps how long a single call may block
+	maxWaitForTimeoutSeconds = 600
+	// defaultWaitForPollInterval is the delay between two condition checks
+	defaultWaitForPollInterval = 250 * time.Millisecond
+)
+
+// WaitForFileToolSpec is the static specification for the wait_for_file tool
+type WaitForFileToolSpec struct{}
+
+func (s *WaitForFileToolSpec) Name() string {
+	return ToolNameWaitForFile
+}
+
+func (s *WaitForFileToolSpec) Description() string {
+	return `Wait until a condition is met, e.g. after starting a build or a server in the background. Prefer this over sleep commands.
+Conditions:
+- exists: the file at "path" exists
+- contains: the file at "path" contains a line matching the regular expression "pattern" (e.g. a log line like "listening on")
+- port: a TCP connection to "port" on the local machine succeeds; "host" may only name a loopback address (default 127.0.0.1)
+Returns as soon as the condition is met and fails after "timeout_seconds".`
+}
+
+func (s *WaitForFileToolSpec) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"condition": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"exists", "contains", "port"},
+				"description": "The condition to wait for",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "File to check (for exists and contains)",
+			},
+			"pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "Regular expression a line of the file must match (for contains)",
+			},
+			"port": map[string]interface{}{
+				"type":        "integer",
+				"description": "TCP port that must accept connections (for port)",
+			},
+			"host": map[string]interface{}{
+				"type":        "string",
+				"description": "Loopback host of the port, e.g. localhost or ::1 (for port, default 127.0.0.1)",
+			},
+			"timeout_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum time to wait (default %d, max %d)", defaultWaitForTimeoutSeconds, maxWaitForTimeoutSeconds),
+			},
+		},
+		"required": []string{"condition"},
+	}
+}
+
+// WaitForFileTool polls a file or port condition until it holds or times out
+type WaitForFileTool struct {
+	fs           fs.FileSystem
+	pollInterval time.Duration
+}
+
+func NewWaitForFileTool(filesystem fs.FileSystem) *WaitForFileTool {
+	return &WaitForFileTool{
+		fs:           filesystem,
+		pollInterval: defaultWaitForPollInterval,
+	}
+}
+
+// Legacy interface implementation for backward compatibility
+func (t *WaitForFileTool) Name() string        { return ToolNameWaitForFile }
+func (t *WaitForFileTool) Description() string { return (&WaitForFileToolSpec{}).Description() }
+func (t *WaitForFileTool) Parameters() map[string]interface{} {
+	return (&WaitForFileToolSpec{}).Parameters()
+}
+
+// waitCondition checks the condition once. It returns a description of what
+// was found if the condition holds.
+type waitCondition func(ctx context.Context) (string, bool, error)
+
+func (t *WaitForFileTool) Execute(ctx context.Context, params map[string]interface{}) *ToolResult {
+	condition := GetStringParam(params, "condition", "")
+	timeoutSeconds := GetIntParam(params, "timeout_seconds", defaultWaitForTimeoutSeconds)
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultWaitForTimeoutSeconds
+	}
+	if timeoutSeconds > maxWaitForTimeoutSeconds {
+		timeoutSeconds = maxWaitForTimeoutSeconds
+	}
+
+	check, target, err := t.buildCondition(condition, params)
+	if err != nil {
+		return &ToolResult{Error: err.Error()}
+	}
+
+	start := time.Now()
+	deadline := time.NewTimer(time.Duration(timeoutSeconds) * time.Second)
+	defer deadline.Stop()
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		detail, ok, err := check(ctx)
+		if err != nil {
+			return &ToolResult{Error: err.Error()}
+		}
+		if ok {
+			waited := time.Since(start).Round(time.Millisecond)
+			result := map[string]interface{}{
+				"condition":      condition,
+				"target":         target,
+				"satisfied":      true,
+				"waited_seconds": waited.Seconds(),
+			}
+			if detail != "" {
+				result["match"] = detail
+			}
+			return &ToolResult{
+				Result:   result,
+				UIResult: fmt.Sprintf("%s: %s after %s", condition, target, waited),
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return &ToolResult{Error: fmt.Sprintf("cancelled while waiting for %s %s", condition, target)}
+		case <-deadline.C:
+			return &ToolResult{Error: fmt.Sprintf("timed out after %ds waiting for %s %s", timeoutSeconds, condition, target)}
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildCondition validates the parameters of a condition and returns its
+// check and a description of what is waited for
+func (t *WaitForFileTool) buildCondition(condition string, params map[string]interface{}) (waitCondition, string, error) {
+	path := GetStringParam(params, "path", "")
+
+	switch condition {
+	case "exists":
+		if path == "" {
+			return nil, "", fmt.Errorf("path is required for the exists condition")
+		}
+		return func(ctx context.Context) (string, bool, error) {
+			exists, err := t.fs.Exists(ctx, path)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to check %s: %w", path, err)
+			}
+			return "", exists, nil
+		}, path, nil
+
+	case "contains":
+		if path == "" {
+			return nil, "", fmt.Errorf("path is required for the contains condition")
+		}
+		pattern := GetStringParam(params, "pattern", "")
+		if pattern == "" {
+			return nil, "", fmt.Errorf("pattern is required for the contains condition")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pattern: %w", err)
+		}
+		return func(ctx context.Context) (string, bool, error) {
+			exists, err := t.fs.Exists(ctx, path)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to check %s: %w", path, err)
+			}
+			if !exists {
+				return "", false, nil
+			}
+			data, err := t.fs.ReadFile(ctx, path)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				if re.MatchString(line) {
+					return strings.TrimRight(line, "\r"), true, nil
+				}
+			}
+			return "", false, nil
+		}, fmt.Sprintf("%s =~ %q", path, pattern), nil
+
+	case "port":
+		port := GetIntParam(params, "port", 0)
+		if port <= 0 || port > 65535 {
+			return nil, "", fmt.Errorf("a port between 1 and 65535 is required for the port condition")
+		}
+		host := strings.Trim(GetStringParam(params, "host", "127.0.0.1"), "[]")
+		if !isLoopbackHost(host) {
+			return nil, "", fmt.Errorf("host %q is not a loopback address; the port condition only waits for local servers", host)
+		}
+		address := net.JoinHostPort(host, strconv.Itoa(port))
+		return func(ctx context.Context) (string, bool, error) {
+			dialer := net.Dialer{Timeout: time.Second}
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return "", false, nil
+			}
+			_ = conn.Close()
+			return "", true, nil
+		}, address, nil
+
+	case "":
+		return nil, "", fmt.Errorf("condition is required (exists, contains or port)")
+	default:
+		return nil, "", fmt.Errorf("unknown condition %q (expected exists, contains or port)", condition)
+	}
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP. Other
+// hosts are rejected without a DNS lookup, so the tool never contacts the
+// network and stays usable in read-only mode.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// NewWaitForFileToolFactory creates a factory for WaitForFileTool
+func NewWaitForFileToolFactory(filesystem fs.FileSystem) ToolFactory {
+	return func(reg *Registry) ToolExecutor {
+		return NewWaitForFileTool(filesystem)
+	}
+}
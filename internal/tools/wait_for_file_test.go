@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefionn/scriptschnell/internal/fs"
+)
+
+func newTestWaitForFileTool(filesystem fs.FileSystem) *WaitForFileTool {
+	tool := NewWaitForFileTool(filesystem)
+	tool.pollInterval = 10 * time.Millisecond
+	return tool
+}
+
+func TestWaitForFileToolExistsCreatedWhileWaiting(t *testing.T) {
+	ctx := context.Background()
+	mockFS := fs.NewMockFS()
+	tool := newTestWaitForFileTool(mockFS)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = mockFS.WriteFile(ctx, "dist/app", []byte("binary"))
+	}()
+
+	result := tool.Execute(ctx, map[string]interface{}{
+		"condition":       "exists",
+		"path":            "dist/app",
+		"timeout_seconds": 5,
+	})
+	if result.Error != "" {
+		t.Fatalf("expected the condition to be met, got error %q", result.Error)
+	}
+	res := result.Result.(map[string]interface{})
+	if res["satisfied"] != true || res["target"] != "dist/app" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if waited := res["waited_seconds"].(float64); waited <= 0 {
+		t.Errorf("expected to wait for the file, waited %v", waited)
+	}
+}
+
+func TestWaitForFileToolContainsPattern(t *testing.T) {
+	ctx := context.Background()
+	mockFS := fs.NewMockFS()
+	_ = mockFS.WriteFile(ctx, "server.log", []byte("starting\n"))
+	tool := newTestWaitForFileTool(mockFS)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = mockFS.WriteFile(ctx, "server.log", []byte("starting\nlistening on :8080\n"))
+	}()
+
+	result := tool.Execute(ctx, map[string]interface{}{
+		"condition":       "contains",
+		"path":            "server.log",
+		"pattern":         `listening on :\d+`,
+		"timeout_seconds": 5,
+	})
+	if result.Error != "" {
+		t.Fatalf("expected the pattern to match, got error %q", result.Error)
+	}
+	if match := result.Result.(map[string]interface{})["match"]; match != "listening on :8080" {
+		t.Errorf("expected the matching line, got %v", match)
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"condition": "contains", "path": "server.log", "pattern": "("})
+	if !strings.Contains(result.Error, "invalid pattern") {
+		t.Errorf("expected invalid pattern error, got %+v", result)
+	}
+}
+
+func TestWaitForFileToolTimeout(t *testing.T) {
+	tool := newTestWaitForFileTool(fs.NewMockFS())
+
+	start := time.Now()
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"condition":       "exists",
+		"path":            "never.txt",
+		"timeout_seconds": 1,
+	})
+	if !strings.Contains(result.Error, "timed out after 1s") {
+		t.Fatalf("expected timeout error, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 3*time.Second {
+		t.Errorf("expected to wait about 1s, waited %s", elapsed)
+	}
+}
+
+func TestWaitForFileToolRespectsCancellation(t *testing.T) {
+	tool := newTestWaitForFileTool(fs.NewMockFS())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := tool.Execute(ctx, map[string]interface{}{
+		"condition":       "exists",
+		"path":            "never.txt",
+		"timeout_seconds": 30,
+	})
+	if !strings.Contains(result.Error, "cancelled") {
+		t.Fatalf("expected cancellation error, got %+v", result)
+	}
+}
+
+func TestWaitForFileToolPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on localhost: %v", err)
+	}
+	defer listener.Close()
+
+	tool := newTestWaitForFileTool(fs.NewMockFS())
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"condition":       "port",
+		"port":            listener.Addr().(*net.TCPAddr).Port,
+		"timeout_seconds": 5,
+	})
+	if result.Error != "" {
+		t.Fatalf("expected the port to be reachable, got error %q", result.Error)
+	}
+}
+
+func TestWaitForFileToolPortRejectsRemoteHosts(t *testing.T) {
+	tool := newTestWaitForFileTool(fs.NewMockFS())
+	for _, host := range []string{"example.com", "10.0.0.1", "0.0.0.0", "localhost.example.com"} {
+		result := tool.Execute(context.Background(), map[string]interface{}{
+			"condition":       "port",
+			"host":            host,
+			"port":            80,
+			"timeout_seconds": 1,
+		})
+		if !strings.Contains(result.Error, "not a loopback address") {
+			t.Errorf("expected host %q to be rejected, got %+v", host, result)
+		}
+	}
+
+	for _, host := range []string{"localhost", "127.0.0.2", "::1"} {
+		if !isLoopbackHost(host) {
+			t.Errorf("expected %q to be a loopback host", host)
+		}
+	}
+}
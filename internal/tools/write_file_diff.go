@@ -210,14 +210,16 @@ func (t *WriteFileDiffTool) Execute(ctx context.Context, params map[string]inter
 	}
 
 	// Generate UI result with validation warning if present
-	uiResult := generateGitDiff(path, string(currentData), finalContent)
+	diffText := generateGitDiff(path, string(currentData), finalContent)
+	uiResult := diffText
 	if validationWarning != "" {
 		uiResult = fmt.Sprintf("%s\n\n⚠️  **Syntax Validation**\n%s", uiResult, validationWarning)
 	}
 
 	return &ToolResult{
-		Result:   resultMap,
-		UIResult: uiResult,
+		Result:            resultMap,
+		UIResult:          uiResult,
+		ExecutionMetadata: diffMetadata(diffText),
 	}
 }
 
@@ -143,6 +143,7 @@ func (t *WriteFileReplaceTool) Execute(ctx context.Context, params map[string]in
 
 		logger.Info("edit_file(replace): updated empty file %s", path)
 
+		uiResult := generateGitDiff(path, "", edits[0].NewString)
 		return &ToolResult{
 			Result: map[string]interface{}{
 				"path":          path,
@@ -150,7 +151,8 @@ func (t *WriteFileReplaceTool) Execute(ctx context.Context, params map[string]in
 				"edits_applied": len(edits),
 				"updated":       true,
 			},
-			UIResult: generateGitDiff(path, "", edits[0].NewString),
+			UIResult:          uiResult,
+			ExecutionMetadata: diffMetadata(uiResult),
 		}
 	}
 
@@ -240,14 +242,16 @@ func (t *WriteFileReplaceTool) Execute(ctx context.Context, params map[string]in
 	}
 
 	// Generate UI result with validation warning if present
-	uiResult := generateGitDiff(path, content, finalContent)
+	diffText := generateGitDiff(path, content, finalContent)
+	uiResult := diffText
 	if validationWarning != "" {
 		uiResult = fmt.Sprintf("%s\n\n⚠️  **Syntax Validation**\n%s", uiResult, validationWarning)
 	}
 
 	return &ToolResult{
-		Result:   resultMap,
-		UIResult: uiResult,
+		Result:            resultMap,
+		UIResult:          uiResult,
+		ExecutionMetadata: diffMetadata(diffText),
 	}
 }
 
@@ -127,13 +127,15 @@ func (t *WriteFileReplaceSingleTool) Execute(ctx context.Context, params map[str
 
 		logger.Info("write_file_replace_single: updated empty file %s", path)
 
+		uiResult := generateGitDiff(path, "", newString)
 		return &ToolResult{
 			Result: map[string]interface{}{
 				"path":         path,
 				"replacements": 0,
 				"updated":      true,
 			},
-			UIResult: generateGitDiff(path, "", newString),
+			UIResult:          uiResult,
+			ExecutionMetadata: diffMetadata(uiResult),
 		}
 	}
 
@@ -187,13 +189,15 @@ func (t *WriteFileReplaceSingleTool) Execute(ctx context.Context, params map[str
 
 	logger.Info("write_file_replace_single: updated %s (%d replacements)", path, totalReplacements)
 
+	uiResult := generateGitDiff(path, content, finalContent)
 	return &ToolResult{
 		Result: map[string]interface{}{
 			"path":         path,
 			"replacements": totalReplacements,
 			"updated":      true,
 		},
-		UIResult: generateGitDiff(path, content, finalContent),
+		UIResult:          uiResult,
+		ExecutionMetadata: diffMetadata(uiResult),
 	}
 }
 
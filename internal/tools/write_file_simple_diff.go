@@ -154,13 +154,15 @@ func (t *WriteFileSimpleDiffTool) Execute(ctx context.Context, params map[string
 
 	logger.Info("edit_file(simple): updated %s (%d bytes)", path, len(finalContent))
 
+	uiResult := generateGitDiff(path, string(currentData), finalContent)
 	return &ToolResult{
 		Result: map[string]interface{}{
 			"path":          path,
 			"bytes_written": len(finalContent),
 			"updated":       true,
 		},
-		UIResult: generateGitDiff(path, string(currentData), finalContent),
+		UIResult:          uiResult,
+		ExecutionMetadata: diffMetadata(uiResult),
 	}
 }
 
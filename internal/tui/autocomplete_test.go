@@ -116,6 +116,29 @@ func TestFilepathAutocompleteRespectsGitIgnore(t *testing.T) {
 	}
 }
 
+func TestFilepathAutocompleteUsesActiveTabDir(t *testing.T) {
+	mockFS := fs.NewMockFS()
+	_ = mockFS.WriteFile(context.Background(), "/test/main.go", []byte("package main"))
+	_ = mockFS.WriteFile(context.Background(), "/other/api/server.go", []byte("package api"))
+
+	m := New("test-model", "", false)
+	m.SetFilesystem(mockFS, "/test")
+	m.sessions = []*TabSession{{ID: 1}, {ID: 2, WorkingDir: "/other"}}
+	m.activeSessionIdx = 1
+
+	if got := m.getFilepathSuggestions("ser"); len(got) != 1 || got[0] != "api/server.go" {
+		t.Errorf("expected suggestions from the tab's directory, got %v", got)
+	}
+	if got := m.getFilepathSuggestions("api/"); len(got) != 1 || got[0] != "api/server.go" {
+		t.Errorf("expected directory suggestions from the tab's directory, got %v", got)
+	}
+
+	m.activeSessionIdx = 0
+	if got := m.getFilepathSuggestions("mai"); len(got) != 1 || got[0] != "main.go" {
+		t.Errorf("expected suggestions from the default directory, got %v", got)
+	}
+}
+
 func TestUpdateSuggestions(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ColorMode selects whether the TUI and CLI style their output
+type ColorMode string
+
+const (
+	// ColorModeAuto styles output for terminals, honoring NO_COLOR
+	ColorModeAuto ColorMode = "auto"
+	// ColorModeAlways styles output even when it is piped
+	ColorModeAlways ColorMode = "always"
+	// ColorModeNever emits plain output
+	ColorModeNever ColorMode = "never"
+)
+
+var (
+	colorModeMu sync.RWMutex
+	colorMode   = ColorModeAuto
+)
+
+// ParseColorMode parses the value of --color; an empty value means auto
+func ParseColorMode(value string) (ColorMode, error) {
+	switch mode := ColorMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ColorModeAuto, nil
+	case ColorModeAuto, ColorModeAlways, ColorModeNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid color mode %q (expected auto, always or never)", value)
+	}
+}
+
+// SetColorMode applies mode to lipgloss and to the markdown renderers created
+// afterwards. It returns the effective mode.
+func SetColorMode(mode ColorMode) ColorMode {
+	colorModeMu.Lock()
+	colorMode = mode
+	colorModeMu.Unlock()
+
+	resolved := effectiveColorMode()
+	switch resolved {
+	case ColorModeNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case ColorModeAlways:
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	}
+	return resolved
+}
+
+// ColorEnabled reports whether output should contain colors and styles
+func ColorEnabled() bool {
+	switch effectiveColorMode() {
+	case ColorModeNever:
+		return false
+	case ColorModeAlways:
+		return true
+	default:
+		return lipgloss.ColorProfile() != termenv.Ascii
+	}
+}
+
+// StripANSI removes ANSI escape sequences from s
+func StripANSI(s string) string {
+	return stripANSISequences(s)
+}
+
+// effectiveColorMode returns the configured color mode, with auto turned into
+// never when the NO_COLOR environment variable is set
+func effectiveColorMode() ColorMode {
+	colorModeMu.RLock()
+	mode := colorMode
+	colorModeMu.RUnlock()
+	return resolveColorMode(mode, os.Getenv)
+}
+
+func resolveColorMode(mode ColorMode, getenv func(string) string) ColorMode {
+	if mode == ColorModeAuto && getenv("NO_COLOR") != "" {
+		return ColorModeNever
+	}
+	return mode
+}
+
+// newMarkdownRenderer creates a glamour renderer for the current color mode
+func newMarkdownRenderer(wrapWidth int) (*glamour.TermRenderer, error) {
+	return glamour.NewTermRenderer(markdownRendererOptions(effectiveColorMode(), wrapWidth)...)
+}
+
+func markdownRendererOptions(mode ColorMode, wrapWidth int) []glamour.TermRendererOption {
+	options := []glamour.TermRendererOption{
+		glamour.WithWordWrap(wrapWidth),
+		glamour.WithPreservedNewLines(),
+	}
+	switch mode {
+	case ColorModeNever:
+		options = append(options,
+			glamour.WithStandardStyle(styles.NoTTYStyle),
+			glamour.WithColorProfile(termenv.Ascii))
+	case ColorModeAlways:
+		// The auto style would fall back to plain output when not on a terminal
+		style := styles.DarkStyle
+		if !lipgloss.HasDarkBackground() {
+			style = styles.LightStyle
+		}
+		options = append(options,
+			glamour.WithStandardStyle(style),
+			glamour.WithColorProfile(termenv.TrueColor))
+	default:
+		options = append(options, glamour.WithAutoStyle())
+	}
+	return options
+}
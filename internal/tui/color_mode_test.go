@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour"
+)
+
+func renderMarkdownForMode(t *testing.T, mode ColorMode) string {
+	t.Helper()
+	renderer, err := glamour.NewTermRenderer(markdownRendererOptions(mode, 80)...)
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+	out, err := renderer.Render("# Title\n\nSome **bold** text and `code`.")
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return out
+}
+
+func TestMarkdownRendererHonorsNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	mode := resolveColorMode(ColorModeAuto, func(key string) string {
+		if key == "NO_COLOR" {
+			return "1"
+		}
+		return ""
+	})
+	if mode != ColorModeNever {
+		t.Fatalf("expected NO_COLOR to disable colors in auto mode, got %q", mode)
+	}
+
+	renderer, err := newMarkdownRenderer(80)
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+	out, err := renderer.Render("Some **bold** text.")
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("expected plain output with NO_COLOR, got %q", out)
+	}
+}
+
+func TestMarkdownRendererForcedColor(t *testing.T) {
+	// --color=always wins over NO_COLOR
+	if mode := resolveColorMode(ColorModeAlways, func(string) string { return "1" }); mode != ColorModeAlways {
+		t.Fatalf("expected forced color to ignore NO_COLOR, got %q", mode)
+	}
+
+	if out := renderMarkdownForMode(t, ColorModeAlways); !strings.Contains(out, "\x1b[") {
+		t.Errorf("expected styled output when color is forced, got %q", out)
+	}
+	if out := renderMarkdownForMode(t, ColorModeNever); strings.Contains(out, "\x1b[") {
+		t.Errorf("expected plain output when color is disabled, got %q", out)
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	for input, want := range map[string]ColorMode{"": ColorModeAuto, "auto": ColorModeAuto, "Always": ColorModeAlways, "never": ColorModeNever} {
+		got, err := ParseColorMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseColorMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseColorMode("sometimes"); err == nil {
+		t.Error("expected an error for an unknown color mode")
+	}
+}
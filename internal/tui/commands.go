@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/codefionn/scriptschnell/internal/actor"
 	"github.com/codefionn/scriptschnell/internal/config"
@@ -28,6 +31,9 @@ type commandDefinition struct {
 	PlaceholderExample string
 	HelpEntries        []commandHelpEntry
 	Handler            func(*CommandHandler, []string) (MenuResult, error)
+	// RawHandler is used instead of Handler by commands that need the text
+	// after the command name as typed, including its whitespace
+	RawHandler func(*CommandHandler, string) (MenuResult, error)
 }
 
 func getDefaultCommandDefinitions() []commandDefinition {
@@ -110,9 +116,97 @@ func getDefaultCommandDefinitions() []commandDefinition {
 		{
 			Name:               "/new",
 			Description:        "Create new session tab with optional name (creates git worktree if named)",
-			Suggestions:        []string{"/new", "/new <name>"},
+			Suggestions:        []string{"/new", "/new <name>", "/new --dir <path>"},
 			PlaceholderExample: "/new feature-auth",
-			Handler:            (*CommandHandler).handleNew,
+			HelpEntries: []commandHelpEntry{
+				{
+					Usage:       "/new [name]",
+					Description: "Open a tab, in a new git worktree if named",
+				},
+				{
+					Usage:       "/new [name] --dir <path>",
+					Description: "Open a tab working in another project directory",
+				},
+			},
+			Handler: (*CommandHandler).handleNew,
+		},
+		{
+			Name:               "/paste",
+			Description:        "Show or set how escape sequences in pasted input are handled (strip, escape, fenced)",
+			Suggestions:        []string{"/paste", "/paste strip", "/paste escape", "/paste fenced"},
+			PlaceholderExample: "/paste fenced",
+			HelpEntries: []commandHelpEntry{
+				{
+					Usage:       "/paste",
+					Description: "Show the current paste mode",
+				},
+				{
+					Usage:       "/paste strip|escape|fenced",
+					Description: "Strip escape sequences, show them as visible text, or keep them inside ``` blocks",
+				},
+			},
+			Handler: (*CommandHandler).handlePaste,
+		},
+		{
+			Name:               "/compact",
+			Description:        "Summarize earlier context now instead of waiting for the context limit",
+			Suggestions:        []string{"/compact"},
+			PlaceholderExample: "/compact",
+			Handler:            (*CommandHandler).handleCompact,
+		},
+		{
+			Name:               "/pin",
+			Description:        "Pin a message so context compaction keeps it verbatim",
+			Suggestions:        []string{"/pin", "/pin <index>"},
+			PlaceholderExample: "/pin 0",
+			HelpEntries: []commandHelpEntry{
+				{
+					Usage:       "/pin",
+					Description: "List the conversation messages with their index and pin state",
+				},
+				{
+					Usage:       "/pin <index>",
+					Description: "Keep the message verbatim when older context is compacted",
+				},
+			},
+			Handler: (*CommandHandler).handlePin,
+		},
+		{
+			Name:               "/snippet",
+			Description:        "Submit or manage prompt snippets (/snippet help for subcommands)",
+			Suggestions:        []string{"/snippet", "/snippet list", "/snippet add", "/snippet remove"},
+			PlaceholderExample: "/snippet review main.go",
+			HelpEntries: []commandHelpEntry{
+				{
+					Usage:       "/snippet <name> [args]",
+					Description: "Expand a prompt snippet with the arguments and submit it",
+				},
+				{
+					Usage:       "/snippet list|add|remove",
+					Description: "List, add or remove prompt snippets",
+				},
+			},
+			RawHandler: (*CommandHandler).handleSnippet,
+		},
+		{
+			Name:               "/unpin",
+			Description:        "Allow a pinned message to be compacted again",
+			Suggestions:        []string{"/unpin <index>"},
+			PlaceholderExample: "/unpin 0",
+			Handler:            (*CommandHandler).handleUnpin,
+		},
+		{
+			Name:               "/export",
+			Description:        "Export the conversation with its timestamps as markdown or JSON",
+			Suggestions:        []string{"/export", "/export markdown", "/export json", "/export json <path>"},
+			PlaceholderExample: "/export json session.json",
+			HelpEntries: []commandHelpEntry{
+				{
+					Usage:       "/export [markdown|json] [path]",
+					Description: "Write the session to path (default: session-<id>.md or .json in the working directory)",
+				},
+			},
+			Handler: (*CommandHandler).handleExport,
 		},
 	}
 }
@@ -197,6 +291,9 @@ func (ch *CommandHandler) HandleCommand(command string) (MenuResult, error) {
 		return MenuResult{}, fmt.Errorf("unknown command: %s. Type /help for available commands", cmd)
 	}
 
+	if definition.RawHandler != nil {
+		return definition.RawHandler(ch, strings.TrimPrefix(command, cmd))
+	}
 	if definition.Handler == nil {
 		return MenuResult{}, fmt.Errorf("command %s is not implemented", cmd)
 	}
@@ -770,6 +867,191 @@ func splitKeyValue(input string, sep rune) (string, string, error) {
 	return key, val, nil
 }
 
+func (ch *CommandHandler) handlePaste(args []string) (MenuResult, error) {
+	if ch.config == nil {
+		return MenuResult{}, fmt.Errorf("configuration unavailable")
+	}
+
+	if len(args) == 0 {
+		return NewMenuResult(fmt.Sprintf("Paste mode: %s", normalizePasteANSIMode(ch.config.TUI.PasteANSIMode))), nil
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(args[0]))
+	switch mode {
+	case config.PasteANSIModeStrip, config.PasteANSIModeEscape, config.PasteANSIModeFenced:
+	default:
+		return MenuResult{}, fmt.Errorf("usage: /paste <strip|escape|fenced>")
+	}
+
+	ch.config.TUI.PasteANSIMode = mode
+	if err := ch.config.Save(config.GetConfigPath()); err != nil {
+		return MenuResult{}, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return NewMenuResult(fmt.Sprintf("Paste mode set to %s.", mode)), nil
+}
+
+// activeSession returns the session of the active tab
+func (ch *CommandHandler) activeSession() *session.Session {
+	if ch.getActiveTab != nil {
+		tab := ch.getActiveTab()
+		if tab == nil {
+			return nil
+		}
+		if tab.Runtime != nil && tab.Runtime.Orchestrator != nil {
+			return tab.Runtime.Orchestrator.GetSession()
+		}
+		return tab.Session
+	}
+	if ch.orchestrator != nil {
+		return ch.orchestrator.GetSession()
+	}
+	return nil
+}
+
+func (ch *CommandHandler) handlePin(args []string) (MenuResult, error) {
+	sess := ch.activeSession()
+	if sess == nil {
+		return MenuResult{}, fmt.Errorf("no active session")
+	}
+
+	if len(args) == 0 {
+		return NewMenuResult(formatPinnableMessages(sess.GetMessages())), nil
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(args[0]))
+	if err != nil {
+		return MenuResult{}, fmt.Errorf("usage: /pin <index>")
+	}
+	if err := sess.PinMessage(index); err != nil {
+		return MenuResult{}, err
+	}
+	return NewMenuResult(fmt.Sprintf("Message %d pinned; compaction will keep it verbatim.", index)), nil
+}
+
+func (ch *CommandHandler) handleUnpin(args []string) (MenuResult, error) {
+	sess := ch.activeSession()
+	if sess == nil {
+		return MenuResult{}, fmt.Errorf("no active session")
+	}
+	if len(args) == 0 {
+		return MenuResult{}, fmt.Errorf("usage: /unpin <index>")
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(args[0]))
+	if err != nil {
+		return MenuResult{}, fmt.Errorf("usage: /unpin <index>")
+	}
+	if err := sess.UnpinMessage(index); err != nil {
+		return MenuResult{}, err
+	}
+	return NewMenuResult(fmt.Sprintf("Message %d unpinned.", index)), nil
+}
+
+func (ch *CommandHandler) handleCompact(_ []string) (MenuResult, error) {
+	var orch *Orchestrator
+	if ch.getActiveTab != nil {
+		tab := ch.getActiveTab()
+		if tab != nil && tab.IsGenerating() {
+			return MenuResult{}, fmt.Errorf("cannot compact while the assistant is responding")
+		}
+		if tab != nil && tab.Runtime != nil {
+			orch = tab.Runtime.Orchestrator
+		}
+	} else {
+		orch = ch.orchestrator
+	}
+	if orch == nil {
+		return MenuResult{}, fmt.Errorf("no active session to compact")
+	}
+
+	progressCallback := ch.progressCallback
+	if ch.getProgressCallback != nil {
+		progressCallback = ch.getProgressCallback()
+	}
+
+	// Summarizing calls the model, so it runs in the background; the
+	// orchestrator reports the freed context through the progress callback
+	go func() {
+		if _, err := orch.CompactNow(ch.ctx, progressCallback, ch.contextCallback); err != nil {
+			if dispatchErr := progress.Dispatch(progressCallback, progress.Update{Message: fmt.Sprintf("\n⚠️ Compaction skipped: %v\n", err)}); dispatchErr != nil {
+				log.Printf("Failed to send progress update: %v", dispatchErr)
+			}
+		}
+	}()
+
+	return NewMenuResult("Compacting earlier context..."), nil
+}
+
+func (ch *CommandHandler) handleExport(args []string) (MenuResult, error) {
+	sess := ch.activeSession()
+	if sess == nil {
+		return MenuResult{}, fmt.Errorf("no active session")
+	}
+
+	format := "markdown"
+	if len(args) > 0 {
+		format = strings.ToLower(args[0])
+	}
+
+	var (
+		data []byte
+		ext  string
+	)
+	switch format {
+	case "markdown", "md":
+		data, ext = []byte(sess.ExportMarkdown()), ".md"
+	case "json":
+		exported, err := sess.ExportJSON()
+		if err != nil {
+			return MenuResult{}, fmt.Errorf("failed to export session: %w", err)
+		}
+		data, ext = exported, ".json"
+	default:
+		return MenuResult{}, fmt.Errorf("usage: /export [markdown|json] [path]")
+	}
+
+	path := "session-" + sess.ID + ext
+	if len(args) > 1 {
+		path = strings.Join(args[1:], " ")
+	}
+	if !filepath.IsAbs(path) && sess.WorkingDir != "" {
+		path = filepath.Join(sess.WorkingDir, path)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return MenuResult{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	return NewMenuResult(fmt.Sprintf("Session exported to %s.", path)), nil
+}
+
+// formatPinnableMessages lists the user and assistant messages of a session
+// with their index, so they can be passed to /pin
+func formatPinnableMessages(messages []*session.Message) string {
+	sb := acquireBuilder()
+	count := 0
+	for i, msg := range messages {
+		if msg == nil || (msg.Role != "user" && msg.Role != "assistant") || len(msg.ToolCalls) > 0 {
+			continue
+		}
+		marker := " "
+		if msg.Pinned {
+			marker = "📌"
+		}
+		preview := strings.Join(strings.Fields(msg.Content), " ")
+		if runes := []rune(preview); len(runes) > 70 {
+			preview = string(runes[:67]) + "..."
+		}
+		fmt.Fprintf(sb, "%s %3d  %-9s %s\n", marker, i, msg.Role, preview)
+		count++
+	}
+	if count == 0 {
+		releaseBuilder(sb)
+		return "No messages to pin yet."
+	}
+	sb.WriteString("\nUse /pin <index> to keep a message verbatim during compaction, /unpin <index> to undo.")
+	return builderString(sb)
+}
+
 func (ch *CommandHandler) handleSettings(_ []string) (MenuResult, error) {
 	return NewSettingsMenuResult(), nil
 }
@@ -987,11 +1269,7 @@ func (ch *CommandHandler) handleClear(_ []string) (MenuResult, error) {
 
 		// Create new session with fresh ID
 		sessionID := session.GenerateID()
-		workingDir := ch.factory.GetWorkingDir()
-		if tab.WorktreePath != "" {
-			workingDir = tab.WorktreePath
-		}
-		newSession := session.NewSession(sessionID, workingDir)
+		newSession := session.NewSession(sessionID, tab.Dir(ch.factory.GetWorkingDir()))
 		tab.Session = newSession
 
 		logger.Info("Created new session %s for tab %d after clear", sessionID, tab.ID)
@@ -1132,6 +1410,106 @@ func (ch *CommandHandler) handleContextRemove(args []string) (MenuResult, error)
 	return NewMenuResult(fmt.Sprintf("Removed context directory: %s", dir)), nil
 }
 
+func (ch *CommandHandler) handleSnippet(rawArgs string) (MenuResult, error) {
+	if ch.config == nil {
+		return MenuResult{}, fmt.Errorf("configuration unavailable")
+	}
+
+	args := strings.Fields(rawArgs)
+	if len(args) == 0 || args[0] == "help" {
+		return NewMenuResult(ch.snippetHelp()), nil
+	}
+
+	switch args[0] {
+	case "list":
+		return ch.handleSnippetList()
+	case "add":
+		// The template keeps its line breaks and indentation
+		return ch.handleSnippetAdd(strings.TrimPrefix(strings.TrimLeftFunc(rawArgs, unicode.IsSpace), "add"))
+	case "remove":
+		return ch.handleSnippetRemove(args[1:])
+	}
+
+	prompt, err := ch.config.ExpandSnippet(args[0], args[1:])
+	if err != nil {
+		return MenuResult{}, fmt.Errorf("%w. Type /snippet list for available snippets", err)
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return MenuResult{}, fmt.Errorf("snippet %s expanded to an empty prompt", args[0])
+	}
+	return NewSubmitPromptResult(prompt), nil
+}
+
+func (ch *CommandHandler) snippetHelp() string {
+	return `Prompt Snippet Commands:
+
+/snippet <name> [args]
+    Expand the snippet with the arguments and submit it as a prompt.
+
+/snippet list
+    Show the configured snippets.
+
+/snippet add <name> <template>
+    Add or replace a snippet. The template uses Go text/template syntax:
+    {{.Args}} is replaced by all arguments, {{index .Arg 0}} by the first one.
+
+/snippet remove <name>
+    Remove a snippet.
+
+Snippets are stored in the configuration and available in every workspace.
+
+Examples:
+  /snippet add review Review {{.Args}} for security issues and list concrete fixes.
+  /snippet review internal/auth/login.go
+  /snippet remove review
+`
+}
+
+func (ch *CommandHandler) handleSnippetList() (MenuResult, error) {
+	names := ch.config.SnippetNames()
+	if len(names) == 0 {
+		return NewMenuResult("No snippets configured. Use /snippet add <name> <template> to add one."), nil
+	}
+
+	sb := acquireBuilder()
+	sb.WriteString("Configured snippets:\n\n")
+	for _, name := range names {
+		text, _ := ch.config.GetSnippet(name)
+		fmt.Fprintf(sb, "%s: %s\n", name, formatQueuedPreview(text))
+	}
+	return NewMenuResult(builderString(sb)), nil
+}
+
+func (ch *CommandHandler) handleSnippetAdd(rawArgs string) (MenuResult, error) {
+	name, text, err := config.ParseSnippetAddArgs(rawArgs)
+	if err != nil {
+		return MenuResult{}, err
+	}
+	if err := ch.config.SetSnippet(name, text); err != nil {
+		return MenuResult{}, err
+	}
+	if err := ch.config.Save(config.GetConfigPath()); err != nil {
+		return MenuResult{}, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return NewMenuResult(fmt.Sprintf("Saved snippet %s. Use it with /snippet %s [args]", name, name)), nil
+}
+
+func (ch *CommandHandler) handleSnippetRemove(args []string) (MenuResult, error) {
+	if len(args) != 1 {
+		return MenuResult{}, fmt.Errorf("usage: /snippet remove <name>")
+	}
+
+	if !ch.config.RemoveSnippet(args[0]) {
+		return MenuResult{}, fmt.Errorf("snippet not found: %s", args[0])
+	}
+	if err := ch.config.Save(config.GetConfigPath()); err != nil {
+		return MenuResult{}, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return NewMenuResult(fmt.Sprintf("Removed snippet: %s", args[0])), nil
+}
+
 func (ch *CommandHandler) handleSession(_ []string) (MenuResult, error) {
 	return NewSessionMenuResult(), nil
 }
@@ -1157,11 +1535,20 @@ Commands:
 
 // handleNew creates a new session tab
 func (ch *CommandHandler) handleNew(args []string) (MenuResult, error) {
-	var name string
-	if len(args) > 0 {
-		name = strings.Join(args, " ")
+	var dir string
+	nameArgs := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--dir" {
+			nameArgs = append(nameArgs, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return MenuResult{}, fmt.Errorf("usage: /new [name] [--dir <path>]")
+		}
+		i++
+		dir = args[i]
 	}
 
 	// Validation will be done in the TUI's handleNewTab method
-	return NewNewTabResult(name), nil
+	return NewNewTabResult(strings.Join(nameArgs, " "), dir), nil
 }
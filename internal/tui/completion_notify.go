@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// shouldNotifyCompletion decides whether a finished generation is signalled.
+// Terminals that don't report focus are treated as focused, so the default
+// "unfocused" mode stays quiet there.
+func shouldNotifyCompletion(mode string, blurred bool) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case config.CompletionNotifyOff:
+		return false
+	case config.CompletionNotifyAlways:
+		return true
+	default:
+		return blurred
+	}
+}
+
+// completionNotification returns the escape sequences for a completion signal:
+// a bell and, if desktop is set, an OSC 9 notification carrying message.
+func completionNotification(message string, desktop bool) string {
+	if !desktop {
+		return "\a"
+	}
+	// Control characters would end the OSC sequence early
+	message = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, message)
+	return fmt.Sprintf("\x1b]9;%s\x07\a", message)
+}
+
+// completionNotifyMsg carries a completion signal to Update, so it is written
+// from the event loop instead of racing with it from a command goroutine
+type completionNotifyMsg struct {
+	sequence string
+}
+
+// notifyCompletion returns a command that signals a finished generation of
+// the named tab, or nil if the current focus and config say not to.
+func (m *Model) notifyCompletion(tabName string, genErr error) tea.Cmd {
+	var tuiCfg config.TUIConfig
+	if m.config != nil {
+		tuiCfg = m.config.TUI
+	}
+	if !shouldNotifyCompletion(tuiCfg.CompletionNotify, m.windowBlurred) {
+		return nil
+	}
+
+	message := fmt.Sprintf("scriptschnell: %s finished", tabName)
+	if genErr != nil {
+		message = fmt.Sprintf("scriptschnell: %s failed: %v", tabName, genErr)
+	}
+	sequence := completionNotification(message, tuiCfg.DesktopNotification)
+	return func() tea.Msg {
+		return completionNotifyMsg{sequence: sequence}
+	}
+}
+
+// writeCompletionNotification writes the escape sequences of a completion
+// signal to the terminal
+func (m *Model) writeCompletionNotification(sequence string) {
+	out := m.notifyOut
+	if out == nil {
+		out = os.Stdout
+	}
+	if _, err := io.WriteString(out, sequence); err != nil {
+		logger.Debug("Failed to write completion notification: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func TestShouldNotifyCompletion(t *testing.T) {
+	tests := []struct {
+		mode    string
+		blurred bool
+		want    bool
+	}{
+		{mode: "", blurred: false, want: false},
+		{mode: "", blurred: true, want: true},
+		{mode: config.CompletionNotifyUnfocused, blurred: true, want: true},
+		{mode: config.CompletionNotifyAlways, blurred: false, want: true},
+		{mode: config.CompletionNotifyOff, blurred: true, want: false},
+		{mode: "OFF", blurred: true, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := shouldNotifyCompletion(tt.mode, tt.blurred); got != tt.want {
+			t.Errorf("shouldNotifyCompletion(%q, blurred=%v) = %v, want %v", tt.mode, tt.blurred, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionNotification(t *testing.T) {
+	if got := completionNotification("done", false); got != "\a" {
+		t.Fatalf("expected plain bell, got %q", got)
+	}
+	if got := completionNotification("line\nbreak\x07", true); got != "\x1b]9;line break \x07\a" {
+		t.Fatalf("unexpected OSC 9 notification %q", got)
+	}
+}
+
+func TestNotifyCompletionFollowsFocus(t *testing.T) {
+	var out bytes.Buffer
+	m := New("test-model", "", true)
+	m.notifyOut = &out
+	m.SetConfig(&config.Config{TUI: config.TUIConfig{DesktopNotification: true}})
+
+	if cmd := m.notifyCompletion("Tab 1", nil); cmd != nil {
+		t.Fatalf("expected no notification while focused")
+	}
+
+	m.Update(tea.BlurMsg{})
+	cmd := m.notifyCompletion("Tab 1", errors.New("boom"))
+	if cmd == nil {
+		t.Fatalf("expected notification while unfocused")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing written before the message reaches Update, got %q", out.String())
+	}
+	m.Update(cmd())
+	if got := out.String(); got != "\x1b]9;scriptschnell: Tab 1 failed: boom\x07\a" {
+		t.Fatalf("unexpected notification output %q", got)
+	}
+}
@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -40,6 +43,13 @@ const (
 	authDialogHeightPadding = 12
 )
 
+const (
+	authChoiceApprove        = "approve"
+	authChoiceApproveSession = "approve_session"
+	authChoiceEdit           = "edit"
+	authChoiceDeny           = "deny"
+)
+
 type authChoiceItem struct {
 	label string
 	value string
@@ -81,11 +91,18 @@ type AuthorizationDialog struct {
 	width    int
 	height   int
 	tabName  string // Display name of the tab requesting authorization
+
+	// Parameter editor, shown instead of the choices while editing
+	editing bool
+	editor  textarea.Model
+	editErr string
 }
 
 // AuthorizationApprovedMsg is sent when user approves the authorization
 type AuthorizationApprovedMsg struct {
 	Approved bool
+	// EditedParams are set if the user edited the parameters before approving
+	EditedParams map[string]interface{}
 }
 
 func (m AuthorizationDialog) dialogWidth() int {
@@ -108,18 +125,37 @@ func (m AuthorizationDialog) listSize() (int, int) {
 
 // NewAuthorizationDialog constructs a dialog for authorization approval
 func NewAuthorizationDialog(req *AuthorizationRequest, tabName string) AuthorizationDialog {
+	approveDesc := "Allow this tool to execute with the specified parameters."
+	if req != nil && req.IsDomainAuth {
+		approveDesc = "Allow access to this domain and remember it in the configuration."
+	}
 	items := []list.Item{
 		authChoiceItem{
 			label: "Approve",
-			value: "approve",
-			desc:  "Allow this tool to execute with the specified parameters.",
-		},
-		authChoiceItem{
-			label: "Deny",
-			value: "deny",
-			desc:  "Prevent this tool from executing.",
+			value: authChoiceApprove,
+			desc:  approveDesc,
 		},
 	}
+	if req != nil && req.IsDomainAuth {
+		items = append(items, authChoiceItem{
+			label: "Approve for this session",
+			value: authChoiceApproveSession,
+			desc:  "Allow access to this domain until the session ends; nothing is saved.",
+		})
+	}
+	items = append(items, authChoiceItem{
+		label: "Deny",
+		value: authChoiceDeny,
+		desc:  "Prevent this tool from executing.",
+	})
+	denyIdx := len(items) - 1
+	if req != nil && !req.IsDomainAuth && len(req.Parameters) > 0 {
+		items = append(items, authChoiceItem{
+			label: "Edit and approve",
+			value: authChoiceEdit,
+			desc:  "Change the parameters first, e.g. add --dry-run to a command.",
+		})
+	}
 
 	dialog := AuthorizationDialog{
 		request: req,
@@ -139,7 +175,7 @@ func NewAuthorizationDialog(req *AuthorizationRequest, tabName string) Authoriza
 	l.SetFilteringEnabled(false)
 
 	// Default to Deny for safety
-	l.Select(1)
+	l.Select(denyIdx)
 
 	dialog.list = l
 
@@ -159,6 +195,27 @@ func (m AuthorizationDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list.SetSize(listWidth, listHeight)
 
 	case tea.KeyMsg:
+		if m.editing {
+			switch msg.String() {
+			case "esc":
+				m.stopEditing()
+				return m, nil
+			case "ctrl+s":
+				params, err := m.editedParams()
+				if err != nil {
+					m.editErr = err.Error()
+					return m, nil
+				}
+				m.choice = true
+				m.approved = true
+				m.quitting = true
+				return m, func() tea.Msg { return AuthorizationApprovedMsg{Approved: true, EditedParams: params} }
+			}
+			var cmd tea.Cmd
+			m.editor, cmd = m.editor.Update(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			// ESC or Ctrl+C means deny
@@ -173,8 +230,12 @@ func (m AuthorizationDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Note: The TUI's handleAuthorizationDialog intercepts this in practice,
 			// but we keep this for standalone testing
 			if item, ok := m.list.SelectedItem().(authChoiceItem); ok {
+				if item.value == authChoiceEdit {
+					cmd := m.startEditing()
+					return m, cmd
+				}
 				m.choice = true
-				m.approved = item.value == "approve"
+				m.approved = item.value == authChoiceApprove || item.value == authChoiceApproveSession
 				m.quitting = true
 				return m, func() tea.Msg { return AuthorizationApprovedMsg{Approved: m.approved} }
 			}
@@ -226,6 +287,19 @@ func (m AuthorizationDialog) View() string {
 		sb.WriteString("\n\n")
 	}
 
+	if m.editing {
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Edit parameters (JSON):"))
+		sb.WriteString("\n")
+		sb.WriteString(m.editor.View())
+		sb.WriteString("\n")
+		if m.editErr != "" {
+			sb.WriteString(errorStyle.Render(m.editErr))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(roleDescStyle.Render("Ctrl+S: Approve edited parameters • ESC: Back"))
+		return authDialogStyle.Width(m.dialogWidth()).Render(builderString(sb))
+	}
+
 	// Choice list
 	sb.WriteString(m.list.View())
 	sb.WriteString("\n")
@@ -238,6 +312,56 @@ func (m AuthorizationDialog) View() string {
 	return authDialogStyle.Width(dialogWidth).Render(builderString(sb))
 }
 
+// startEditing opens the parameter editor prefilled with the requested
+// parameters
+func (m *AuthorizationDialog) startEditing() tea.Cmd {
+	var params map[string]interface{}
+	if m.request != nil {
+		params = m.request.Parameters
+	}
+	content, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		content = []byte("{}")
+	}
+
+	editor := textarea.New()
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+	editor.SetWidth(max(10, m.dialogWidth()-authDialogListPadding-2))
+	editor.SetHeight(min(12, max(3, strings.Count(string(content), "\n")+2)))
+	editor.SetValue(string(content))
+	editor.Focus()
+
+	m.editor = editor
+	m.editing = true
+	m.editErr = ""
+	return textarea.Blink
+}
+
+// stopEditing returns to the choices, discarding the edits
+func (m *AuthorizationDialog) stopEditing() {
+	m.editing = false
+	m.editErr = ""
+	m.editor.Blur()
+}
+
+// editedParams parses the parameter editor
+func (m AuthorizationDialog) editedParams() (map[string]interface{}, error) {
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(m.editor.Value()), &params); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if params == nil {
+		return nil, fmt.Errorf("parameters must be a JSON object")
+	}
+	return params, nil
+}
+
+// IsEditing returns whether the parameter editor is open
+func (m AuthorizationDialog) IsEditing() bool {
+	return m.editing
+}
+
 // GetApproved returns whether the user approved the authorization
 func (m AuthorizationDialog) GetApproved() bool {
 	return m.approved
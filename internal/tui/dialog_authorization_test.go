@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -1238,3 +1239,125 @@ func TestHandlerPathClearsWaitingForAuth(t *testing.T) {
 		t.Fatal("WaitingForAuth should be false after handler-based authorization completes")
 	}
 }
+
+func TestAuthorizationDialogDomainApproveForSession(t *testing.T) {
+	dialog := NewAuthorizationDialog(&AuthorizationRequest{
+		ToolName:     "network_access",
+		Parameters:   map[string]interface{}{"domain": "api.example.com"},
+		IsDomainAuth: true,
+	}, "test-tab")
+
+	items := dialog.list.Items()
+	if len(items) != 3 || items[1].(authChoiceItem).value != authChoiceApproveSession {
+		t.Fatalf("expected approve, approve for session and deny, got %+v", items)
+	}
+	if dialog.list.Index() != 2 {
+		t.Fatalf("expected deny to be selected by default, got index %d", dialog.list.Index())
+	}
+
+	m := &Model{authorizationDialog: dialog, activeAuthorizationID: "auth-1"}
+	m.authorizationDialog.list.Select(1)
+	_, cmd := m.handleAuthorizationDialog(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("expected command from enter key")
+	}
+	resp, ok := cmd().(AuthorizationResponseMsg)
+	if !ok || !resp.Approved || !resp.SessionOnly {
+		t.Fatalf("expected session-only approval, got %+v", resp)
+	}
+}
+
+func TestAuthorizationEditorApprovesEditedParameters(t *testing.T) {
+	m := New("test-model", "", false)
+	m.ready = true
+
+	responseChan := make(chan bool, 1)
+	req := &AuthorizationRequest{
+		AuthID:       "test-auth-edit",
+		TabID:        1,
+		ToolName:     "shell",
+		Parameters:   map[string]interface{}{"command": "git clean -fd"},
+		Reason:       "Command deletes files",
+		ResponseChan: responseChan,
+	}
+	m.authorizationDialog = NewAuthorizationDialog(req, "test-tab")
+	m.authorizationDialogOpen = true
+	m.activeAuthorizationID = req.AuthID
+	m.pendingAuthorizations = map[string]*AuthorizationRequest{req.AuthID: req}
+	m.sessions = []*TabSession{{ID: 1}}
+	m.activeSessionIdx = 0
+
+	// Select "Edit and approve"
+	m.authorizationDialog.list.Select(2)
+	if item := m.authorizationDialog.list.SelectedItem().(authChoiceItem); item.value != authChoiceEdit {
+		t.Fatalf("expected the edit choice, got %q", item.value)
+	}
+	m.handleAuthorizationDialog(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.authorizationDialog.IsEditing() {
+		t.Fatal("expected the parameter editor to open")
+	}
+	if !strings.Contains(m.authorizationDialog.editor.Value(), `"command": "git clean -fd"`) {
+		t.Fatalf("expected the editor to hold the parameters, got %q", m.authorizationDialog.editor.Value())
+	}
+
+	// Invalid JSON keeps the editor open
+	m.authorizationDialog.editor.SetValue("{")
+	if _, cmd := m.handleAuthorizationDialog(tea.KeyMsg{Type: tea.KeyCtrlS}); cmd != nil || m.authorizationDialog.editErr == "" {
+		t.Fatal("expected invalid JSON to be reported in the editor")
+	}
+
+	m.authorizationDialog.editor.SetValue(`{"command": "git clean -fd --dry-run"}`)
+	_, cmd := m.handleAuthorizationDialog(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil {
+		t.Fatal("expected a response command")
+	}
+	resp, ok := cmd().(AuthorizationResponseMsg)
+	if !ok || !resp.Approved || resp.EditedParams["command"] != "git clean -fd --dry-run" {
+		t.Fatalf("expected an approval with edited parameters, got %+v", resp)
+	}
+
+	m.authorizationDialogOpen = false
+	m.Update(resp)
+	select {
+	case approved := <-responseChan:
+		if !approved || req.EditedParams["command"] != "git clean -fd --dry-run" {
+			t.Fatalf("expected the edited parameters to reach the callback, got %v %+v", approved, req.EditedParams)
+		}
+	default:
+		t.Fatal("expected response to be sent to channel")
+	}
+}
+
+func TestAuthorizationEditorEscapeReturnsToChoices(t *testing.T) {
+	dialog := NewAuthorizationDialog(&AuthorizationRequest{
+		ToolName:   "shell",
+		Parameters: map[string]interface{}{"command": "make deploy"},
+	}, "test-tab")
+	dialog.list.Select(2)
+
+	model, _ := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	dialog = model.(AuthorizationDialog)
+	if !dialog.IsEditing() || dialog.HasChoice() {
+		t.Fatal("expected the editor to open without a choice")
+	}
+
+	model, _ = dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	dialog = model.(AuthorizationDialog)
+	if dialog.IsEditing() || dialog.HasChoice() {
+		t.Fatal("expected ESC to return to the choices")
+	}
+}
+
+func TestAuthorizationDialogOffersEditOnlyForParameters(t *testing.T) {
+	for _, req := range []*AuthorizationRequest{
+		{ToolName: "shell"},
+		{ToolName: "network_access", Parameters: map[string]interface{}{"domain": "example.com"}, IsDomainAuth: true},
+	} {
+		dialog := NewAuthorizationDialog(req, "test-tab")
+		for _, item := range dialog.list.Items() {
+			if item.(authChoiceItem).value == authChoiceEdit {
+				t.Errorf("expected no edit choice for %s", req.ToolName)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// draftEstimateDebounce is the time to wait after the last keystroke before re-estimating the draft
+	draftEstimateDebounce = 150 * time.Millisecond
+
+	// draftWarningFraction is the share of the context window above which the draft estimate is highlighted
+	draftWarningFraction = 0.5
+)
+
+// draftEstimateWarningStyle highlights drafts that would take a large part of the context window
+var draftEstimateWarningStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("214")).
+	Bold(true)
+
+type draftEstimateMsg struct {
+	token int
+}
+
+// estimateDraftTokens approximates the tokens of the prompt draft with the
+// same 4 characters per token heuristic the orchestrator falls back to. It
+// is cheap enough to run while typing.
+func estimateDraftTokens(text string) int {
+	runes := utf8.RuneCountInString(text)
+	if runes == 0 {
+		return 0
+	}
+	return (runes + 3) / 4
+}
+
+// draftBudget formats the draft estimate relative to the context window and
+// reports whether it exceeds draftWarningFraction of it. An unknown window
+// (<= 0) only shows the token count.
+func draftBudget(tokens, contextWindow int) (string, bool) {
+	if tokens <= 0 {
+		return "", false
+	}
+	if contextWindow <= 0 {
+		return fmt.Sprintf("Draft: ~%s tokens", formatTokenCount(tokens)), false
+	}
+
+	fraction := float64(tokens) / float64(contextWindow)
+	return fmt.Sprintf("Draft: ~%s tokens (%.0f%%)", formatTokenCount(tokens), fraction*100), fraction > draftWarningFraction
+}
+
+// formatTokenCount shortens token counts to K above a thousand
+func formatTokenCount(tokens int) string {
+	if tokens < 1000 {
+		return fmt.Sprintf("%d", tokens)
+	}
+	return fmt.Sprintf("%.1fK", float64(tokens)/1000)
+}
+
+// scheduleDraftEstimate debounces re-estimating the draft while the user types
+func (m *Model) scheduleDraftEstimate() tea.Cmd {
+	m.draftEstimateToken++
+	token := m.draftEstimateToken
+	return tea.Tick(draftEstimateDebounce, func(time.Time) tea.Msg {
+		return draftEstimateMsg{token: token}
+	})
+}
+
+// renderDraftEstimate renders the draft estimate shown next to the context usage indicator
+func (m *Model) renderDraftEstimate() string {
+	text, warn := draftBudget(m.draftTokens, m.contextWindow)
+	if text == "" {
+		return ""
+	}
+	if warn {
+		return contextUsageStyle.Render(draftEstimateWarningStyle.Render(text))
+	}
+	return contextUsageStyle.Render(text)
+}
@@ -0,0 +1,37 @@
+package tui
+
+import "testing"
+
+func TestEstimateDraftTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{in: "", want: 0},
+		{in: "a", want: 1},
+		{in: "abcd", want: 1},
+		{in: "abcde", want: 2},
+		{in: "äöüß", want: 1}, // counted in runes, not bytes
+	}
+
+	for _, tt := range tests {
+		if got := estimateDraftTokens(tt.in); got != tt.want {
+			t.Errorf("estimateDraftTokens(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDraftBudget(t *testing.T) {
+	if text, warn := draftBudget(0, 128000); text != "" || warn {
+		t.Fatalf("expected empty draft to render nothing, got %q (warn=%v)", text, warn)
+	}
+	if text, warn := draftBudget(1200, 0); text != "Draft: ~1.2K tokens" || warn {
+		t.Fatalf("unexpected estimate without context window: %q (warn=%v)", text, warn)
+	}
+	if text, warn := draftBudget(250, 1000); text != "Draft: ~250 tokens (25%)" || warn {
+		t.Fatalf("unexpected estimate below warning threshold: %q (warn=%v)", text, warn)
+	}
+	if text, warn := draftBudget(600, 1000); text != "Draft: ~600 tokens (60%)" || !warn {
+		t.Fatalf("expected warning for draft above threshold: %q (warn=%v)", text, warn)
+	}
+}
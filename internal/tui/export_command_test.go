@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/session"
+)
+
+func TestExportCommandWritesMarkdownAndJSON(t *testing.T) {
+	workingDir := t.TempDir()
+	sess := session.NewSession("export-test", workingDir)
+	sess.AddMessage(&session.Message{Role: "user", Content: "What does main.go do?"})
+	sess.AddMessage(&session.Message{Role: "assistant", Content: "It starts the server."})
+
+	ch := NewCommandHandler(context.Background(), config.DefaultConfig(), nil, nil)
+	ch.SetGetActiveTab(func() *TabSession { return &TabSession{Session: sess} })
+
+	if _, err := ch.HandleCommand("/export"); err != nil {
+		t.Fatalf("markdown export failed: %v", err)
+	}
+	markdown, err := os.ReadFile(filepath.Join(workingDir, "session-export-test.md"))
+	if err != nil {
+		t.Fatalf("markdown export not written: %v", err)
+	}
+	for _, want := range []string{"## User (", "What does main.go do?", "## Assistant (", "It starts the server."} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("markdown export is missing %q:\n%s", want, markdown)
+		}
+	}
+
+	if _, err := ch.HandleCommand("/export json out.json"); err != nil {
+		t.Fatalf("JSON export failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(workingDir, "out.json"))
+	if err != nil {
+		t.Fatalf("JSON export not written: %v", err)
+	}
+	var exported session.ExportedSession
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid JSON export: %v", err)
+	}
+	if len(exported.Messages) != 2 || exported.Messages[0].Timestamp.IsZero() {
+		t.Errorf("unexpected JSON export: %+v", exported)
+	}
+
+	if _, err := ch.HandleCommand("/export pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultMaxPasteBytes is the paste size above which content is attached as a
+// file reference instead of being inlined into the textarea
+const defaultMaxPasteBytes = 100 * 1024
+
+// maxPasteBytes returns the configured paste limit; 0 or less disables diverting
+func (m *Model) maxPasteBytes() int {
+	if m.config == nil || m.config.TUI.MaxPasteBytes == 0 {
+		return defaultMaxPasteBytes
+	}
+	return m.config.TUI.MaxPasteBytes
+}
+
+// splitInsertedText compares the textarea value before and after an update
+// and returns the unchanged text around the inserted part. Boundaries are
+// aligned to runes so a multi-byte character is never split.
+func splitInsertedText(prev, current string) (before, inserted, after string) {
+	prefix := 0
+	for prefix < len(prev) && prefix < len(current) && prev[prefix] == current[prefix] {
+		prefix++
+	}
+	for prefix > 0 && prefix < len(current) && !utf8.RuneStart(current[prefix]) {
+		prefix--
+	}
+
+	suffix := 0
+	maxSuffix := min(len(prev), len(current)) - prefix
+	for suffix < maxSuffix && prev[len(prev)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !utf8.RuneStart(current[len(current)-suffix]) {
+		suffix--
+	}
+
+	return current[:prefix], current[prefix : len(current)-suffix], current[len(current)-suffix:]
+}
+
+// planPasteDiversion decides whether the text inserted by an update is too
+// large to inline. limit <= 0 never diverts.
+func planPasteDiversion(prev, current string, limit int) (before, inserted, after string, divert bool) {
+	if limit <= 0 || len(current)-len(prev) <= limit {
+		return "", "", "", false
+	}
+	before, inserted, after = splitInsertedText(prev, current)
+	return before, inserted, after, len(inserted) > limit
+}
+
+// fileReferenceInsertion builds an @path reference that stays separated from
+// the surrounding text so it is picked up by @file expansion.
+func fileReferenceInsertion(before, path, after string) string {
+	ref := "@" + path
+	if before != "" && !endsWithSpace(before) {
+		ref = " " + ref
+	}
+	if after != "" && !startsWithSpace(after) {
+		ref += " "
+	}
+	return before + ref + after
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+// divertLargePaste saves an oversized paste to a temp file and returns the
+// textarea value with an @path reference in its place. ok is false if the
+// paste is small enough to inline or the file could not be written.
+func (m *Model) divertLargePaste(prev, current string) (string, bool) {
+	before, inserted, after, divert := planPasteDiversion(prev, current, m.maxPasteBytes())
+	if !divert {
+		return current, false
+	}
+
+	dir := os.TempDir()
+	if m.config != nil && m.config.TempDir != "" {
+		dir = m.config.TempDir
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		m.AddSystemMessage(fmt.Sprintf("Large paste (%s) could not be saved as a file: %v", formatBytes(len(inserted)), err))
+		return current, false
+	}
+
+	file, err := os.CreateTemp(dir, "paste-*.txt")
+	if err != nil {
+		m.AddSystemMessage(fmt.Sprintf("Large paste (%s) could not be saved as a file: %v", formatBytes(len(inserted)), err))
+		return current, false
+	}
+	_, writeErr := file.WriteString(inserted)
+	closeErr := file.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(file.Name())
+		if writeErr == nil {
+			writeErr = closeErr
+		}
+		m.AddSystemMessage(fmt.Sprintf("Large paste (%s) could not be saved as a file: %v", formatBytes(len(inserted)), writeErr))
+		return current, false
+	}
+
+	m.AddSystemMessage(fmt.Sprintf("Large paste (%s) saved to %s and attached as a file reference; delete the @path to discard it", formatBytes(len(inserted)), file.Name()))
+	return fileReferenceInsertion(before, file.Name(), after), true
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func TestPlanPasteDiversion(t *testing.T) {
+	large := strings.Repeat("x", 2048)
+
+	tests := []struct {
+		name       string
+		prev       string
+		current    string
+		limit      int
+		divert     bool
+		wantBefore string
+		wantAfter  string
+	}{
+		{name: "normal paste is inlined", prev: "hello ", current: "hello world", limit: 1024},
+		{name: "typing is inlined", prev: large, current: large + "y", limit: 1024},
+		{name: "large paste at end", prev: "see ", current: "see " + large, limit: 1024, divert: true, wantBefore: "see "},
+		{name: "large paste in the middle", prev: "a b", current: "a " + large + "b", limit: 1024, divert: true, wantBefore: "a ", wantAfter: "b"},
+		{name: "disabled limit", prev: "", current: large, limit: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, inserted, after, divert := planPasteDiversion(tt.prev, tt.current, tt.limit)
+			if divert != tt.divert {
+				t.Fatalf("divert = %v, want %v", divert, tt.divert)
+			}
+			if !divert {
+				return
+			}
+			if before != tt.wantBefore || after != tt.wantAfter {
+				t.Fatalf("got before %q after %q, want %q and %q", before, after, tt.wantBefore, tt.wantAfter)
+			}
+			if inserted != large {
+				t.Fatalf("expected the pasted text to be split out, got %d bytes", len(inserted))
+			}
+		})
+	}
+}
+
+func TestSplitInsertedTextKeepsRunesIntact(t *testing.T) {
+	// "ä" and "ö" share their first UTF-8 byte
+	before, inserted, after := splitInsertedText("ä", "ö")
+	if before != "" || inserted != "ö" || after != "" {
+		t.Fatalf("unexpected split %q %q %q", before, inserted, after)
+	}
+	before, inserted, after = splitInsertedText("ä", "äö")
+	if before != "ä" || inserted != "ö" || after != "" {
+		t.Fatalf("unexpected split %q %q %q", before, inserted, after)
+	}
+}
+
+func TestDivertLargePasteWritesFileReference(t *testing.T) {
+	m := New("test-model", "", true)
+	m.SetConfig(&config.Config{TempDir: t.TempDir(), TUI: config.TUIConfig{MaxPasteBytes: 16}})
+
+	pasted := strings.Repeat("line\n", 10)
+	value, ok := m.divertLargePaste("fix this:", "fix this:"+pasted)
+	if !ok {
+		t.Fatalf("expected large paste to be diverted")
+	}
+	if !strings.HasPrefix(value, "fix this: @") {
+		t.Fatalf("expected @path reference, got %q", value)
+	}
+
+	path := strings.TrimPrefix(value, "fix this: @")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read diverted paste: %v", err)
+	}
+	if string(content) != pasted {
+		t.Fatalf("diverted file content = %q, want %q", content, pasted)
+	}
+
+	if value, ok := m.divertLargePaste("fix", "fix it"); ok || value != "fix it" {
+		t.Fatalf("expected small paste to be inlined, got %q (diverted=%v)", value, ok)
+	}
+}
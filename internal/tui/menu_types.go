@@ -26,6 +26,8 @@ const (
 	MenuTypeNewTab
 	// MenuTypeSession indicates the session management menu
 	MenuTypeSession
+	// MenuTypeSubmitPrompt indicates a prompt should be submitted on the active tab
+	MenuTypeSubmitPrompt
 )
 
 // ModelRole represents the role a model can have
@@ -50,8 +52,12 @@ type MenuResult struct {
 	ModelRole ModelRole
 	// TabName is used for MenuTypeNewTab to specify the tab name
 	TabName string
+	// TabDir is used for MenuTypeNewTab to pin the tab to a working directory
+	TabDir string
 	// LoadedSession carries session data when a saved session is restored
 	LoadedSession *LoadedSessionInfo
+	// Prompt is used for MenuTypeSubmitPrompt, e.g. an expanded snippet
+	Prompt string
 }
 
 // LoadedSessionInfo contains data needed to restore a saved session in the UI
@@ -119,11 +125,13 @@ func NewClearSessionResult() MenuResult {
 	}
 }
 
-// NewNewTabResult creates a MenuResult that creates a new tab
-func NewNewTabResult(name string) MenuResult {
+// NewNewTabResult creates a MenuResult that creates a new tab, optionally
+// pinned to dir
+func NewNewTabResult(name, dir string) MenuResult {
 	return MenuResult{
 		Type:    MenuTypeNewTab,
 		TabName: name,
+		TabDir:  dir,
 	}
 }
 
@@ -133,3 +141,11 @@ func NewSessionMenuResult() MenuResult {
 		Type: MenuTypeSession,
 	}
 }
+
+// NewSubmitPromptResult creates a MenuResult that submits a prompt
+func NewSubmitPromptResult(prompt string) MenuResult {
+	return MenuResult{
+		Type:   MenuTypeSubmitPrompt,
+		Prompt: prompt,
+	}
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/codefionn/scriptschnell/internal/config"
@@ -78,7 +79,63 @@ func TestSessionMessagesToTuiMessagesConversion(t *testing.T) {
 	if messages[0].role != "Assistant" || messages[0].content != "hi" {
 		t.Fatalf("unexpected first message: %+v", messages[0])
 	}
-	if messages[1].role != "Tool" || messages[1].toolName != "write_file" || messages[1].toolID != "1" || messages[1].content != "done" {
+	if messages[1].role != "Tool" || messages[1].toolName != "write_file" || messages[1].toolID != "1" || messages[1].fullResult != "done" {
 		t.Fatalf("unexpected tool message conversion: %+v", messages[1])
 	}
+	if !messages[1].summarized || messages[1].content == "" || messages[1].toolState != ToolStateCompleted {
+		t.Fatalf("expected restored tool result to be summarized and completed: %+v", messages[1])
+	}
+}
+
+func TestSessionMessagesToTuiMessagesRestoresToolCalls(t *testing.T) {
+	longOutput := strings.Repeat("line\n", 40)
+	stored := []*session.Message{
+		{Role: "user", Content: "list files"},
+		{Role: "assistant", ToolCalls: []map[string]interface{}{
+			{
+				"id":   "call-1",
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      "shell",
+					"arguments": `{"command":"ls"}`,
+				},
+			},
+			{
+				"id":   "call-2",
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      "read_file",
+					"arguments": `{"path":"missing.go"}`,
+				},
+			},
+		}},
+		{Role: "tool", ToolID: "call-1", ToolName: "shell", Content: longOutput},
+		{Role: "tool", ToolID: "call-2", ToolName: "read_file", Content: "Error: file not found"},
+		{Role: "assistant", Content: "Here are the files."},
+	}
+
+	messages := sessionMessagesToTuiMessages(stored)
+	if len(messages) != 4 {
+		t.Fatalf("expected tool-call-only assistant message to be skipped, got %d messages: %+v", len(messages), messages)
+	}
+
+	shell := messages[1]
+	if shell.parameters["command"] != "ls" {
+		t.Fatalf("expected tool call parameters to be restored, got %+v", shell.parameters)
+	}
+	if !shell.isCollapsible || !shell.isCollapsed || shell.fullResult != longOutput {
+		t.Fatalf("expected large tool output to be restored collapsed: %+v", shell)
+	}
+
+	failed := messages[2]
+	if failed.toolState != ToolStateFailed || failed.parameters["path"] != "missing.go" {
+		t.Fatalf("expected failed tool result with parameters, got %+v", failed)
+	}
+	if failed.isCollapsed {
+		t.Fatalf("expected short error result to stay expanded")
+	}
+
+	if messages[3].role != "Assistant" || messages[3].content != "Here are the files." {
+		t.Fatalf("unexpected final message: %+v", messages[3])
+	}
 }
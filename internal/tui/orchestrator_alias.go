@@ -4,13 +4,20 @@ import orchestratorpkg "github.com/codefionn/scriptschnell/internal/orchestrator
 
 type (
 	AuthorizationCallback   = orchestratorpkg.AuthorizationCallback
+	AuthorizationResponse   = orchestratorpkg.AuthorizationResponse
 	ProgressCallback        = orchestratorpkg.ProgressCallback
 	ProgressUpdate          = orchestratorpkg.ProgressUpdate
 	ContextUsageCallback    = orchestratorpkg.ContextUsageCallback
 	OpenRouterUsageCallback = orchestratorpkg.OpenRouterUsageCallback
+	PromptOverrides         = orchestratorpkg.PromptOverrides
+	PromptResult            = orchestratorpkg.Result
+	ToolCallCallback        = orchestratorpkg.ToolCallCallback
+	ToolResultCallback      = orchestratorpkg.ToolResultCallback
 )
 
 type Orchestrator = orchestratorpkg.Orchestrator
 
 var NewOrchestrator = orchestratorpkg.NewOrchestrator
 var NewOrchestratorWithRequireSandboxAuth = orchestratorpkg.NewOrchestratorWithRequireSandboxAuth
+var ContextWithPromptOverrides = orchestratorpkg.ContextWithPromptOverrides
+var ApprovalOnly = orchestratorpkg.ApprovalOnly
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -36,12 +37,19 @@ type TabRuntime struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	tabID        int
+	fsDir        string // Key of the tab's filesystem in dirFilesystems, "" for the shared one
+}
+
+// WorkingDir returns the working directory of the tab's orchestrator
+func (tr *TabRuntime) WorkingDir() string {
+	return tr.Orchestrator.GetWorkingDir()
 }
 
 // RuntimeFactory creates per-tab runtime instances
 type RuntimeFactory struct {
 	shared             *SharedResources
-	runtimes           map[int]*TabRuntime // Map of tabID -> runtime
+	runtimes           map[int]*TabRuntime      // Map of tabID -> runtime
+	dirFilesystems     map[string]fs.FileSystem // Filesystems of tabs outside workingDir, by directory
 	mu                 sync.RWMutex
 	workingDir         string
 	cliMode            bool
@@ -121,6 +129,7 @@ func NewRuntimeFactoryWithRequireSandboxAuth(cfg *config.Config, providerMgr *pr
 	return &RuntimeFactory{
 		shared:             shared,
 		runtimes:           make(map[int]*TabRuntime),
+		dirFilesystems:     make(map[string]fs.FileSystem),
 		workingDir:         workingDir,
 		cliMode:            cliMode,
 		requireSandboxAuth: requireSandboxAuth,
@@ -144,11 +153,12 @@ func (rf *RuntimeFactory) CreateTabRuntime(tabID int, sess *session.Session) (*T
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create per-tab orchestrator with shared resources
+	filesystem, fsDir := rf.filesystemFor(sess.WorkingDir)
 	orch, err := orchestrator.NewOrchestratorWithSharedResources(
 		rf.shared.config,
 		rf.shared.providerMgr,
 		rf.cliMode,
-		rf.shared.filesystem,        // Shared FS
+		filesystem,
 		sess,                        // Tab's session
 		rf.shared.sessionStorageRef, // Shared session storage
 		rf.shared.domainBlockerRef,  // Shared domain blocker
@@ -158,6 +168,7 @@ func (rf *RuntimeFactory) CreateTabRuntime(tabID int, sess *session.Session) (*T
 	)
 	if err != nil {
 		cancel()
+		closeFilesystem(rf.releaseFilesystem(fsDir))
 		return nil, fmt.Errorf("failed to create orchestrator for tab %d: %w", tabID, err)
 	}
 
@@ -166,6 +177,7 @@ func (rf *RuntimeFactory) CreateTabRuntime(tabID int, sess *session.Session) (*T
 		ctx:          ctx,
 		cancel:       cancel,
 		tabID:        tabID,
+		fsDir:        fsDir,
 	}
 
 	rf.runtimes[tabID] = runtime
@@ -173,6 +185,49 @@ func (rf *RuntimeFactory) CreateTabRuntime(tabID int, sess *session.Session) (*T
 	return runtime, nil
 }
 
+// filesystemFor returns the filesystem for a tab working in dir. Tabs in the
+// factory's working directory share one filesystem; tabs pinned to another
+// directory or worktree share one per directory. The returned key is "" for
+// the shared filesystem. rf.mu must be held.
+func (rf *RuntimeFactory) filesystemFor(dir string) (fs.FileSystem, string) {
+	if dir == "" || filepath.Clean(dir) == filepath.Clean(rf.workingDir) {
+		return rf.shared.filesystem, ""
+	}
+	dir = filepath.Clean(dir)
+	if filesystem, ok := rf.dirFilesystems[dir]; ok {
+		return filesystem, dir
+	}
+
+	cfg := rf.shared.config
+	filesystem := fs.NewCachedFS(dir, time.Duration(cfg.CacheTTL)*time.Second, cfg.MaxCacheEntries)
+	rf.dirFilesystems[dir] = filesystem
+	return filesystem, dir
+}
+
+// releaseFilesystem removes the filesystem of fsDir once no tab runtime uses
+// it anymore and returns it for closing, or nil if it is still in use. rf.mu
+// must be held.
+func (rf *RuntimeFactory) releaseFilesystem(fsDir string) fs.FileSystem {
+	if fsDir == "" {
+		return nil
+	}
+	for _, runtime := range rf.runtimes {
+		if runtime.fsDir == fsDir {
+			return nil
+		}
+	}
+	filesystem := rf.dirFilesystems[fsDir]
+	delete(rf.dirFilesystems, fsDir)
+	return filesystem
+}
+
+// closeFilesystem closes filesystem if it holds resources
+func closeFilesystem(filesystem fs.FileSystem) {
+	if cfs, ok := filesystem.(*fs.CachedFS); ok {
+		_ = cfs.Close()
+	}
+}
+
 // GetTabRuntime retrieves an existing runtime
 func (rf *RuntimeFactory) GetTabRuntime(tabID int) (*TabRuntime, bool) {
 	rf.mu.RLock()
@@ -191,13 +246,17 @@ func (rf *RuntimeFactory) DestroyTabRuntime(tabID int) error {
 		return fmt.Errorf("no runtime found for tab %d", tabID)
 	}
 	delete(rf.runtimes, tabID)
+	unusedFilesystem := rf.releaseFilesystem(runtime.fsDir)
 	rf.mu.Unlock()
 
 	logger.Info("Destroying runtime for tab %d", tabID)
 
-	// Cancel context and close orchestrator
+	// Cancel context and close orchestrator, then the filesystem of its
+	// directory if no other tab works there
 	runtime.cancel()
-	if err := runtime.Orchestrator.Close(); err != nil {
+	err := runtime.Orchestrator.Close()
+	closeFilesystem(unusedFilesystem)
+	if err != nil {
 		logger.Warn("Failed to close orchestrator for tab %d: %v", tabID, err)
 		return fmt.Errorf("failed to close orchestrator: %w", err)
 	}
@@ -213,6 +272,8 @@ func (rf *RuntimeFactory) Close() error {
 	rf.mu.Lock()
 	runtimes := rf.runtimes
 	rf.runtimes = make(map[int]*TabRuntime)
+	dirFilesystems := rf.dirFilesystems
+	rf.dirFilesystems = make(map[string]fs.FileSystem)
 	rf.mu.Unlock()
 
 	// Close all tab runtimes
@@ -249,8 +310,9 @@ func (rf *RuntimeFactory) Close() error {
 
 	// Close shared filesystem
 	logger.Debug("Closing shared filesystem")
-	if cfs, ok := rf.shared.filesystem.(*fs.CachedFS); ok {
-		_ = cfs.Close()
+	closeFilesystem(rf.shared.filesystem)
+	for _, filesystem := range dirFilesystems {
+		closeFilesystem(filesystem)
 	}
 
 	logger.Info("RuntimeFactory closed successfully")
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/provider"
+)
+
+func TestTabsPinnedToDifferentWorkingDirs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	baseDir := t.TempDir()
+	projects := map[string]string{}
+	for _, name := range []string{"frontend", "backend"} {
+		dir := filepath.Join(baseDir, name)
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "project.txt"), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		projects[name] = dir
+	}
+
+	providerMgr, err := provider.NewManager("", "")
+	if err != nil {
+		t.Fatalf("failed to create provider manager: %v", err)
+	}
+	cfg := &config.Config{WorkingDir: baseDir, CacheTTL: 1, MaxCacheEntries: 10}
+	factory, err := NewRuntimeFactory(cfg, providerMgr, baseDir, true)
+	if err != nil {
+		t.Fatalf("failed to create runtime factory: %v", err)
+	}
+	t.Cleanup(func() { _ = factory.Close() })
+
+	m := New("test-model", "", true)
+	m.workingDir = baseDir
+	m.handleNewTab("", "frontend")
+	m.handleNewTab("", projects["backend"])
+	if len(m.sessions) != 2 {
+		t.Fatalf("expected 2 tabs, got %d", len(m.sessions))
+	}
+
+	for _, tab := range m.sessions {
+		name := tab.DisplayName()
+		want, ok := projects[name]
+		if !ok {
+			t.Fatalf("expected the tab title to be a project name, got %q", name)
+		}
+		if tab.Dir(baseDir) != want {
+			t.Errorf("tab %q: expected dir %s, got %s", name, want, tab.Dir(baseDir))
+		}
+
+		runtime, err := factory.CreateTabRuntime(tab.ID, tab.Session)
+		if err != nil {
+			t.Fatalf("failed to create runtime for %q: %v", name, err)
+		}
+		if got := runtime.WorkingDir(); got != want {
+			t.Errorf("tab %q: expected runtime working dir %s, got %s", name, want, got)
+		}
+		data, err := runtime.Orchestrator.GetFilesystem().ReadFile(context.Background(), "project.txt")
+		if err != nil || string(data) != name {
+			t.Errorf("tab %q: expected to read its own project.txt, got %q, %v", name, data, err)
+		}
+	}
+
+	// The filesystem of a directory is closed with the last tab working there
+	for idx, tab := range m.sessions {
+		if err := factory.DestroyTabRuntime(tab.ID); err != nil {
+			t.Fatalf("failed to destroy runtime of tab %d: %v", tab.ID, err)
+		}
+		factory.mu.RLock()
+		remaining := len(factory.dirFilesystems)
+		factory.mu.RUnlock()
+		if want := len(m.sessions) - idx - 1; remaining != want {
+			t.Errorf("expected %d directory filesystems after destroying tab %d, got %d", want, tab.ID, remaining)
+		}
+	}
+}
+
+func TestHandleNewTabRejectsMissingDir(t *testing.T) {
+	m := New("test-model", "", true)
+	m.workingDir = t.TempDir()
+
+	m.handleNewTab("", "does-not-exist")
+
+	if len(m.sessions) != 0 {
+		t.Fatalf("expected no tab for a missing directory, got %d", len(m.sessions))
+	}
+}
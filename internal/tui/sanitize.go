@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+// normalizePasteANSIMode maps a configured paste mode to a known mode,
+// falling back to stripping for empty or unknown values.
+func normalizePasteANSIMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case config.PasteANSIModeEscape:
+		return config.PasteANSIModeEscape
+	case config.PasteANSIModeFenced:
+		return config.PasteANSIModeFenced
+	default:
+		return config.PasteANSIModeStrip
+	}
+}
+
+// sanitizePromptInputMode sanitizes prompt input according to the given paste
+// mode. Stripping is the default; see config.PasteANSIMode* for the others.
+func sanitizePromptInputMode(input string, state *ansiSanitizeState, mode string) string {
+	switch normalizePasteANSIMode(mode) {
+	case config.PasteANSIModeEscape:
+		return escapePromptInput(input)
+	case config.PasteANSIModeFenced:
+		return sanitizePromptInputFenced(input, state)
+	default:
+		return sanitizePromptInput(input, state)
+	}
+}
+
+// escapePromptInput rewrites ESC and BEL into visible text (\x1b, \x07) so
+// pasted terminal transcripts keep their escape sequences without the raw
+// control bytes reaching the terminal. The result contains no control bytes,
+// so escaping an already escaped value is a no-op and no state is needed
+// for fragmented sequences.
+func escapePromptInput(input string) string {
+	if strings.IndexByte(input, 0x1b) < 0 && strings.IndexByte(input, 0x07) < 0 && utf8.ValidString(input) {
+		return input
+	}
+
+	var b strings.Builder
+	b.Grow(len(input) + 8)
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// Drop invalid bytes like the stripping sanitizer does
+		case r == 0x1b:
+			b.WriteString(`\x1b`)
+		case r == 0x07:
+			b.WriteString(`\x07`)
+		default:
+			b.WriteString(input[i : i+size])
+		}
+		i += size
+	}
+
+	return b.String()
+}
+
+// sanitizePromptInputFenced keeps escape sequences verbatim inside fenced code
+// blocks (``` or ~~~) and strips them everywhere else. An unclosed fence
+// extends to the end of the input, so a transcript pasted right after an
+// opening fence is preserved while the closing fence is still being typed.
+// Fences are tracked per call because the TUI always passes the full
+// textarea value.
+func sanitizePromptInputFenced(input string, state *ansiSanitizeState) string {
+	if input == "" {
+		return input
+	}
+
+	var b strings.Builder
+	b.Grow(len(input))
+
+	segmentStart := 0
+	inFence := false
+	for pos := 0; pos < len(input); {
+		end := len(input)
+		if idx := strings.IndexByte(input[pos:], '\n'); idx >= 0 {
+			end = pos + idx + 1
+		}
+
+		if isCodeFenceLine(input[pos:end]) {
+			if inFence {
+				b.WriteString(input[segmentStart:end])
+			} else {
+				b.WriteString(sanitizePromptInput(input[segmentStart:end], state))
+				// Don't let a dangling sequence outside swallow the block's content
+				*state = ansiSanitizeState{}
+			}
+			inFence = !inFence
+			segmentStart = end
+		}
+		pos = end
+	}
+
+	if inFence {
+		b.WriteString(input[segmentStart:])
+	} else {
+		b.WriteString(sanitizePromptInput(input[segmentStart:], state))
+	}
+
+	return b.String()
+}
+
+// isCodeFenceLine reports whether line opens or closes a markdown code fence
+func isCodeFenceLine(line string) bool {
+	trimmed := strings.TrimLeft(line, " \t")
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
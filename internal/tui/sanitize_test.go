@@ -1,6 +1,10 @@
 package tui
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
 
 func TestSanitizePromptInput(t *testing.T) {
 	tests := []struct {
@@ -178,3 +182,63 @@ func TestStripANSISequences(t *testing.T) {
 		})
 	}
 }
+
+// feedPromptChunks mimics the TUI, which appends each fragment to the textarea
+// value and sanitizes the whole value again.
+func feedPromptChunks(mode, prefix string, chunks []string) string {
+	var state ansiSanitizeState
+	value := sanitizePromptInputMode(prefix, &state, mode)
+	for _, chunk := range chunks {
+		value = sanitizePromptInputMode(value+chunk, &state, mode)
+	}
+	return value
+}
+
+func TestSanitizePromptInputModesFragmented(t *testing.T) {
+	belChunks := []string{"\x1b", "]11;rgb:1818/1818/1818", "\x07"}
+	stChunks := []string{"\x1b", "]11;rgb:1818/1818/1818", "\x1b", "\\"}
+
+	tests := []struct {
+		name   string
+		mode   string
+		prefix string
+		chunks []string
+		want   string
+	}{
+		{name: "strip bel", mode: config.PasteANSIModeStrip, chunks: belChunks, want: ""},
+		{name: "strip st", mode: config.PasteANSIModeStrip, chunks: stChunks, want: ""},
+		{name: "unknown mode strips", mode: "bogus", chunks: stChunks, want: ""},
+		{name: "escape bel", mode: config.PasteANSIModeEscape, chunks: belChunks, want: `\x1b]11;rgb:1818/1818/1818\x07`},
+		{name: "escape st", mode: config.PasteANSIModeEscape, chunks: stChunks, want: `\x1b]11;rgb:1818/1818/1818\x1b\`},
+		{name: "fenced outside block strips", mode: config.PasteANSIModeFenced, chunks: stChunks, want: ""},
+		{name: "fenced bel inside block", mode: config.PasteANSIModeFenced, prefix: "```\n", chunks: belChunks, want: "```\n\x1b]11;rgb:1818/1818/1818\x07"},
+		{name: "fenced st inside block", mode: config.PasteANSIModeFenced, prefix: "```\n", chunks: stChunks, want: "```\n\x1b]11;rgb:1818/1818/1818\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := feedPromptChunks(tt.mode, tt.prefix, tt.chunks); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizePromptInputFencedMixed(t *testing.T) {
+	var state ansiSanitizeState
+	in := "see \x1b[31mthis\x1b[0m:\n~~~\n\x1b[32mok\x1b[0m\n~~~\ndone\x1b[K"
+	want := "see this:\n~~~\n\x1b[32mok\x1b[0m\n~~~\ndone"
+	if got := sanitizePromptInputMode(in, &state, config.PasteANSIModeFenced); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestEscapePromptInputIsIdempotent(t *testing.T) {
+	once := escapePromptInput("\x1b[31mred\x1b[0m\a")
+	if once != `\x1b[31mred\x1b[0m\x07` {
+		t.Fatalf("unexpected escaped output %q", once)
+	}
+	if twice := escapePromptInput(once); twice != once {
+		t.Fatalf("escaping twice changed the value: %q", twice)
+	}
+}
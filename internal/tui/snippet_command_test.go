@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func newSnippetTestHandler(t *testing.T) *CommandHandler {
+	t.Helper()
+	// Snippets are saved to the user config
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	return NewCommandHandler(context.Background(), config.DefaultConfig(), nil, nil)
+}
+
+func TestSnippetCommandExpandsWithArgs(t *testing.T) {
+	ch := newSnippetTestHandler(t)
+
+	if _, err := ch.HandleCommand("/snippet add review Review {{.Args}} for security issues, starting with {{index .Arg 0}}."); err != nil {
+		t.Fatalf("adding the snippet failed: %v", err)
+	}
+
+	result, err := ch.HandleCommand("/snippet review auth.go login.go")
+	if err != nil {
+		t.Fatalf("expanding the snippet failed: %v", err)
+	}
+	if result.Type != MenuTypeSubmitPrompt {
+		t.Fatalf("expected a prompt submission, got %+v", result)
+	}
+	if want := "Review auth.go login.go for security issues, starting with auth.go."; result.Prompt != want {
+		t.Errorf("expanded prompt = %q, want %q", result.Prompt, want)
+	}
+
+	if _, err := ch.HandleCommand("/snippet review"); err == nil {
+		t.Error("expected an error when the snippet's argument is missing")
+	}
+	if _, err := ch.HandleCommand("/snippet unknown"); err == nil {
+		t.Error("expected an error for an unknown snippet")
+	}
+}
+
+func TestSnippetCommandListAddRemove(t *testing.T) {
+	ch := newSnippetTestHandler(t)
+
+	result, err := ch.HandleCommand("/snippet list")
+	if err != nil || !strings.Contains(result.Message, "No snippets configured") {
+		t.Fatalf("expected no snippets, got %q (%v)", result.Message, err)
+	}
+
+	for _, cmd := range []string{"/snippet add tests Write tests for {{.Args}}", "/snippet add explain Explain {{.Args}}"} {
+		if _, err := ch.HandleCommand(cmd); err != nil {
+			t.Fatalf("%s failed: %v", cmd, err)
+		}
+	}
+
+	result, err = ch.HandleCommand("/snippet list")
+	if err != nil {
+		t.Fatalf("listing failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "explain: Explain {{.Args}}") || !strings.Contains(result.Message, "tests: Write tests for {{.Args}}") {
+		t.Errorf("expected both snippets in the list, got %q", result.Message)
+	}
+
+	// The snippets are persisted
+	saved, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("loading the saved config failed: %v", err)
+	}
+	if len(saved.Snippets) != 2 {
+		t.Errorf("expected 2 saved snippets, got %v", saved.Snippets)
+	}
+
+	if _, err := ch.HandleCommand("/snippet remove tests"); err != nil {
+		t.Fatalf("removing failed: %v", err)
+	}
+	if _, err := ch.HandleCommand("/snippet remove tests"); err == nil {
+		t.Error("expected an error when removing a missing snippet")
+	}
+	if names := ch.config.SnippetNames(); len(names) != 1 || names[0] != "explain" {
+		t.Errorf("expected only explain to remain, got %v", names)
+	}
+
+	for _, cmd := range []string{"/snippet add list Reserved", "/snippet add bad name {{.Nope}}", "/snippet add x"} {
+		if _, err := ch.HandleCommand(cmd); err == nil {
+			t.Errorf("expected %q to be rejected", cmd)
+		}
+	}
+}
+
+func TestSnippetCommandAddKeepsWhitespace(t *testing.T) {
+	ch := newSnippetTestHandler(t)
+
+	template := "  Review {{.Args}}:\n\t- naming\n\t- error  handling"
+	if _, err := ch.HandleCommand("/snippet add checklist\n" + template); err != nil {
+		t.Fatalf("adding the snippet failed: %v", err)
+	}
+	if got, _ := ch.config.GetSnippet("checklist"); got != template {
+		t.Errorf("expected the template as typed, got %q", got)
+	}
+
+	if _, err := ch.HandleCommand("/snippet add  review \tCheck {{.Args}}"); err != nil {
+		t.Fatalf("adding the snippet failed: %v", err)
+	}
+	if got, _ := ch.config.GetSnippet("review"); got != "Check {{.Args}}" {
+		t.Errorf("expected the blanks after the name to be dropped, got %q", got)
+	}
+}
@@ -29,6 +29,7 @@ type SocketClientWrapper struct {
 
 	// Message handlers for streaming responses
 	onChatMessage   func(msg socketclient.ChatMessage)
+	onReasoning     func(msg socketclient.ReasoningMessage)
 	onToolCall      func(msg socketclient.ToolCall)
 	onToolResult    func(msg socketclient.ToolResult)
 	onProgress      func(msg socketclient.ProgressData)
@@ -110,6 +111,16 @@ func (w *SocketClientWrapper) setupClientCallbacks() {
 		}
 	})
 
+	// Reasoning callback
+	w.client.SetReasoningCallback(func(msg socketclient.ReasoningMessage) {
+		w.mu.RLock()
+		handler := w.onReasoning
+		w.mu.RUnlock()
+		if handler != nil {
+			handler(msg)
+		}
+	})
+
 	// Tool call callback
 	w.client.SetToolCallCallback(func(msg socketclient.ToolCall) {
 		w.mu.RLock()
@@ -208,6 +219,13 @@ func (w *SocketClientWrapper) SetChatMessageHandler(handler func(msg socketclien
 	w.onChatMessage = handler
 }
 
+// SetReasoningHandler sets the callback for model reasoning
+func (w *SocketClientWrapper) SetReasoningHandler(handler func(msg socketclient.ReasoningMessage)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onReasoning = handler
+}
+
 // SetToolCallHandler sets the callback for tool calls
 func (w *SocketClientWrapper) SetToolCallHandler(handler func(msg socketclient.ToolCall)) {
 	w.mu.Lock()
@@ -82,6 +82,18 @@ func (m *Model) setupSocketModeCallbacks() {
 		})
 	})
 
+	// Reasoning handler - attached to the assistant message being streamed
+	m.socketFactory.SetReasoningHandler(func(msg socketclient.ReasoningMessage) {
+		if m.program == nil {
+			return
+		}
+		m.program.Send(SocketChatMessageMsg{
+			SessionID: msg.SessionID,
+			Role:      "assistant",
+			Reasoning: msg.Content,
+		})
+	})
+
 	// Tool call handler
 	m.socketFactory.SetToolCallHandler(func(msg socketclient.ToolCall) {
 		if m.program == nil {
@@ -205,17 +217,20 @@ func (m *Model) handleSocketChatMessage(msg SocketChatMessageMsg) {
 			m.appendAssistantChunkForTab(tabIdx, msg.Content)
 		}
 
-		// Handle reasoning content
+		// Handle reasoning content, which arrives before the answer it belongs to
 		if msg.Reasoning != "" {
 			msgs := m.sessions[tabIdx].Messages
-			if len(msgs) > 0 {
-				lastMsg := &msgs[len(msgs)-1]
-				if lastMsg.role == "Assistant" {
-					lastMsg.reasoning = msg.Reasoning
-					if tabIdx == m.activeSessionIdx {
-						m.viewportDirty = true
-					}
-				}
+			if len(msgs) == 0 || msgs[len(msgs)-1].role != "Assistant" {
+				m.addMessageForTab(tabIdx, "Assistant", "")
+				msgs = m.sessions[tabIdx].Messages
+			}
+			lastMsg := &msgs[len(msgs)-1]
+			if lastMsg.reasoning != "" {
+				lastMsg.reasoning += "\n\n"
+			}
+			lastMsg.reasoning += msg.Reasoning
+			if tabIdx == m.activeSessionIdx {
+				m.viewportDirty = true
 			}
 		}
 	}
@@ -314,6 +314,11 @@ func (sf *SocketRuntimeFactory) SetChatMessageHandler(handler func(msg socketcli
 	sf.wrapper.SetChatMessageHandler(handler)
 }
 
+// SetReasoningHandler sets the callback for model reasoning
+func (sf *SocketRuntimeFactory) SetReasoningHandler(handler func(msg socketclient.ReasoningMessage)) {
+	sf.wrapper.SetReasoningHandler(handler)
+}
+
 // SetToolCallHandler sets the callback for tool calls
 func (sf *SocketRuntimeFactory) SetToolCallHandler(handler func(msg socketclient.ToolCall)) {
 	sf.wrapper.SetToolCallHandler(handler)
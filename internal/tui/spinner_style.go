@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/codefionn/scriptschnell/internal/config"
+	"github.com/codefionn/scriptschnell/internal/logger"
+)
+
+// defaultSpinnerStyle is used when spinner_style is unset or unknown
+const defaultSpinnerStyle = "line"
+
+// spinnerStyles maps the spinner_style config values to the bubbles presets
+var spinnerStyles = map[string]spinner.Spinner{
+	"line":      spinner.Line,
+	"dot":       spinner.Dot,
+	"minidot":   spinner.MiniDot,
+	"jump":      spinner.Jump,
+	"pulse":     spinner.Pulse,
+	"points":    spinner.Points,
+	"globe":     spinner.Globe,
+	"moon":      spinner.Moon,
+	"monkey":    spinner.Monkey,
+	"meter":     spinner.Meter,
+	"hamburger": spinner.Hamburger,
+	"ellipsis":  spinner.Ellipsis,
+}
+
+// resolveSpinner returns the spinner for a configured style and frame
+// interval. Unknown styles fall back to the default style, and a zero
+// interval keeps the style's own rate.
+func resolveSpinner(style string, interval time.Duration) spinner.Spinner {
+	name := strings.ToLower(strings.TrimSpace(style))
+	if name == "" {
+		name = defaultSpinnerStyle
+	}
+	sp, ok := spinnerStyles[name]
+	if !ok {
+		logger.Warn("Unknown spinner style %q, using %q", style, defaultSpinnerStyle)
+		sp = spinnerStyles[defaultSpinnerStyle]
+	}
+	if interval > 0 {
+		sp.FPS = interval
+	}
+	return sp
+}
+
+// applySpinnerConfig sets the style and rate of the status and tool spinners
+// from the config
+func (m *Model) applySpinnerConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	sp := resolveSpinner(cfg.SpinnerStyle, cfg.GetSpinnerInterval())
+	m.spinner.Spinner = sp
+	GetToolStyles().Spinner.Spinner = sp
+}
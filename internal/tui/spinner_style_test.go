@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/codefionn/scriptschnell/internal/config"
+)
+
+func TestResolveSpinnerFromConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		style      string
+		intervalMs int
+		wantFrames []string
+		wantFPS    time.Duration
+	}{
+		{"default", "", 0, spinner.Line.Frames, spinner.Line.FPS},
+		{"preset", "minidot", 0, spinner.MiniDot.Frames, spinner.MiniDot.FPS},
+		{"case insensitive", " Dot ", 0, spinner.Dot.Frames, spinner.Dot.FPS},
+		{"slower rate", "dot", 500, spinner.Dot.Frames, 500 * time.Millisecond},
+		{"unknown style falls back", "sparkles", 0, spinner.Line.Frames, spinner.Line.FPS},
+		{"negative interval keeps the rate", "pulse", -10, spinner.Pulse.Frames, spinner.Pulse.FPS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{SpinnerStyle: tt.style, SpinnerIntervalMs: tt.intervalMs}
+			m := New("test-model", "", false)
+			m.applySpinnerConfig(cfg)
+
+			if !reflect.DeepEqual(m.spinner.Spinner.Frames, tt.wantFrames) {
+				t.Errorf("frames = %q, want %q", m.spinner.Spinner.Frames, tt.wantFrames)
+			}
+			if m.spinner.Spinner.FPS != tt.wantFPS {
+				t.Errorf("tick interval = %s, want %s", m.spinner.Spinner.FPS, tt.wantFPS)
+			}
+			if toolSpinner := GetToolStyles().Spinner.Spinner; !reflect.DeepEqual(toolSpinner.Frames, tt.wantFrames) || toolSpinner.FPS != tt.wantFPS {
+				t.Errorf("tool spinner = %q at %s, want %q at %s", toolSpinner.Frames, toolSpinner.FPS, tt.wantFrames, tt.wantFPS)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxStatusAreaLines caps the multi-line status area so it never pushes the
+// conversation off screen; further activities are summarized in one line.
+const maxStatusAreaLines = 4
+
+// statusActivity is one concurrently running activity (usually a tool call)
+type statusActivity struct {
+	ID        string
+	TabID     int
+	Label     string
+	Status    string
+	StartedAt time.Time
+}
+
+// statusActivities tracks running activities keyed by ID in start order.
+// Only used from the Bubble Tea update loop, so it needs no locking.
+type statusActivities struct {
+	order []string
+	byID  map[string]*statusActivity
+	now   func() time.Time
+}
+
+func newStatusActivities() *statusActivities {
+	return &statusActivities{
+		byID: make(map[string]*statusActivity),
+		now:  time.Now,
+	}
+}
+
+// Set adds an activity or updates an existing one. Empty label or status
+// values keep the current ones.
+func (s *statusActivities) Set(tabID int, id, label, status string) {
+	if id == "" {
+		return
+	}
+	if activity, ok := s.byID[id]; ok {
+		if label != "" {
+			activity.Label = label
+		}
+		if status != "" {
+			activity.Status = status
+		}
+		return
+	}
+	s.byID[id] = &statusActivity{ID: id, TabID: tabID, Label: label, Status: status, StartedAt: s.now()}
+	s.order = append(s.order, id)
+}
+
+// Remove drops a finished activity
+func (s *statusActivities) Remove(id string) {
+	if _, ok := s.byID[id]; !ok {
+		return
+	}
+	delete(s.byID, id)
+	for i, existing := range s.order {
+		if existing == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// ClearTab drops all activities of a tab, e.g. when its generation ends
+func (s *statusActivities) ClearTab(tabID int) {
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if s.byID[id].TabID == tabID {
+			delete(s.byID, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// ForTab returns the running activities of a tab in start order
+func (s *statusActivities) ForTab(tabID int) []statusActivity {
+	var activities []statusActivity
+	for _, id := range s.order {
+		if activity := s.byID[id]; activity.TabID == tabID {
+			activities = append(activities, *activity)
+		}
+	}
+	return activities
+}
+
+// Lines renders the status area for a tab. It is empty while at most one
+// activity runs, so the single-line footer status stays as it is.
+func (s *statusActivities) Lines(tabID int) []string {
+	activities := s.ForTab(tabID)
+	if len(activities) < 2 {
+		return nil
+	}
+
+	shown := activities
+	if len(shown) > maxStatusAreaLines {
+		shown = shown[:maxStatusAreaLines-1]
+	}
+
+	lines := make([]string, 0, len(shown)+1)
+	for _, activity := range shown {
+		elapsed := s.now().Sub(activity.StartedAt).Round(time.Second)
+		line := activity.Label
+		if activity.Status != "" {
+			line = fmt.Sprintf("%s: %s", line, activity.Status)
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s)", line, elapsed))
+	}
+	if hidden := len(activities) - len(shown); hidden > 0 {
+		lines = append(lines, fmt.Sprintf("+%d more running", hidden))
+	}
+	return lines
+}
+
+// statusArea returns the activity tracker, creating it on first use
+func (m *Model) statusArea() *statusActivities {
+	if m.activities == nil {
+		m.activities = newStatusActivities()
+	}
+	return m.activities
+}
+
+// activeTabID returns the ID of the active tab, or -1 without tabs
+func (m *Model) activeTabID() int {
+	if !m.validTabIndex(m.activeSessionIdx) {
+		return -1
+	}
+	return m.sessions[m.activeSessionIdx].ID
+}
+
+// statusAreaLines returns the multi-line status area of the active tab
+func (m *Model) statusAreaLines() []string {
+	return m.statusArea().Lines(m.activeTabID())
+}
+
+// viewportHeightFor returns the viewport height for a window height,
+// leaving room for the status area
+func (m *Model) viewportHeightFor(height int) int {
+	vpHeight := height - 10 - len(m.statusAreaLines())
+	if vpHeight < 1 {
+		vpHeight = 1
+	}
+	return vpHeight
+}
+
+// syncStatusAreaHeight resizes the viewport after the status area grew or
+// shrank, keeping the conversation pinned to the bottom if it was.
+func (m *Model) syncStatusAreaHeight() {
+	if !m.ready {
+		return
+	}
+	vpHeight := m.viewportHeightFor(m.height)
+	if vpHeight == m.viewport.Height {
+		return
+	}
+	atBottom := m.viewport.AtBottom()
+	m.viewport.Height = vpHeight
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// renderStatusArea renders one line per running activity, each with a spinner
+func (m *Model) renderStatusArea() string {
+	lines := m.statusAreaLines()
+	if len(lines) == 0 {
+		return ""
+	}
+
+	indicator := "⚙️ "
+	if !m.animationsDisabled && m.spinnerActive {
+		indicator = m.spinner.View()
+	}
+
+	sb := acquireBuilder()
+	for _, line := range lines {
+		sb.WriteString(statusStyle.Render(fmt.Sprintf("%s %s", indicator, line)))
+		sb.WriteString("\n")
+	}
+	return builderString(sb)
+}
+
+// toolActivityLabel describes a running tool call for the status area
+func toolActivityLabel(toolName string, parameters map[string]interface{}) string {
+	if primary := extractPrimaryParameter(toolName, parameters); primary != "" {
+		return fmt.Sprintf("%s %s", toolName, primary)
+	}
+	return toolName
+}
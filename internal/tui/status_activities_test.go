@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStatusActivities() (*statusActivities, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newStatusActivities()
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestStatusActivitiesSingleActivityKeepsSingleLine(t *testing.T) {
+	s, _ := newTestStatusActivities()
+	s.Set(1, "call-1", "shell ls", "")
+
+	if got := len(s.ForTab(1)); got != 1 {
+		t.Fatalf("expected 1 activity, got %d", got)
+	}
+	if lines := s.Lines(1); lines != nil {
+		t.Fatalf("expected no status area for a single activity, got %v", lines)
+	}
+}
+
+func TestStatusActivitiesAddUpdateRemove(t *testing.T) {
+	s, now := newTestStatusActivities()
+	s.Set(1, "call-1", "shell make", "")
+	*now = now.Add(2 * time.Second)
+	s.Set(1, "call-2", "go_sandbox", "")
+	s.Set(2, "call-3", "read_file a.go", "") // other tab
+
+	*now = now.Add(3 * time.Second)
+	s.Set(1, "call-2", "", "downloading TinyGo")
+
+	lines := s.Lines(1)
+	want := []string{"shell make (5s)", "go_sandbox: downloading TinyGo (3s)"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected status lines %q, want %q", lines, want)
+	}
+
+	s.Remove("call-1")
+	if lines := s.Lines(1); lines != nil {
+		t.Fatalf("expected status area to collapse to single line after removal, got %v", lines)
+	}
+	if activities := s.ForTab(1); len(activities) != 1 || activities[0].ID != "call-2" {
+		t.Fatalf("unexpected remaining activities %+v", activities)
+	}
+
+	s.ClearTab(1)
+	if activities := s.ForTab(1); len(activities) != 0 {
+		t.Fatalf("expected tab 1 to be cleared, got %+v", activities)
+	}
+	if activities := s.ForTab(2); len(activities) != 1 {
+		t.Fatalf("expected other tab to keep its activity, got %+v", activities)
+	}
+}
+
+func TestStatusActivitiesCapsLines(t *testing.T) {
+	s, _ := newTestStatusActivities()
+	for i := 0; i < 6; i++ {
+		s.Set(1, string(rune('a'+i)), "tool", "")
+	}
+
+	lines := s.Lines(1)
+	if len(lines) != maxStatusAreaLines {
+		t.Fatalf("expected %d lines, got %d: %v", maxStatusAreaLines, len(lines), lines)
+	}
+	if last := lines[len(lines)-1]; last != "+3 more running" {
+		t.Fatalf("expected overflow summary, got %q", last)
+	}
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/codefionn/scriptschnell/internal/session"
@@ -13,6 +14,7 @@ type TabSession struct {
 	Session      *session.Session // The actual session data
 	Name         string           // User-provided name (optional)
 	WorktreePath string           // Git worktree path (empty if no worktree)
+	WorkingDir   string           // Directory the tab is pinned to (empty = worktree or default directory)
 	Messages     []message        // TUI-specific message display cache
 	CreatedAt    time.Time
 	LastActiveAt time.Time
@@ -33,9 +35,24 @@ func (ts *TabSession) DisplayName() string {
 	if ts.Name != "" {
 		return ts.Name
 	}
+	if ts.WorkingDir != "" {
+		return filepath.Base(ts.WorkingDir)
+	}
 	return fmt.Sprintf("Tab %d", ts.ID)
 }
 
+// Dir returns the working directory of the tab: the directory it is pinned
+// to, its worktree or defaultDir
+func (ts *TabSession) Dir(defaultDir string) string {
+	if ts.WorkingDir != "" {
+		return ts.WorkingDir
+	}
+	if ts.WorktreePath != "" {
+		return ts.WorktreePath
+	}
+	return defaultDir
+}
+
 // HasMessages returns true if the session has any messages
 func (ts *TabSession) HasMessages() bool {
 	return len(ts.Messages) > 0
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,8 +17,9 @@ import (
 
 const maxTabs = 10
 
-// handleNewTab creates a new session tab
-func (m *Model) handleNewTab(name string) tea.Cmd {
+// handleNewTab creates a new session tab. A non-empty dir pins the tab to
+// that directory instead of the default directory or a worktree for name.
+func (m *Model) handleNewTab(name, dir string) tea.Cmd {
 	// Validate max tabs
 	if len(m.sessions) >= maxTabs {
 		m.AddSystemMessage(fmt.Sprintf("Maximum %d tabs allowed", maxTabs))
@@ -39,14 +42,28 @@ func (m *Model) handleNewTab(name string) tea.Cmd {
 		}
 	}
 
+	pinnedDir := ""
+	if dir != "" {
+		resolved, err := resolveTabDir(m.workingDir, dir)
+		if err != nil {
+			m.AddSystemMessage(fmt.Sprintf("Invalid working directory: %v", err))
+			return nil
+		}
+		pinnedDir = resolved
+	}
+
 	var tabSession *TabSession
 
 	if m.useSocketMode && m.socketFactory != nil {
 		// Socket mode: create session on server
 		workingDir := m.workingDir
+		workspace := m.workingDir // Use working dir as workspace
 		worktreePath := ""
 
-		if name != "" {
+		if pinnedDir != "" {
+			workingDir = pinnedDir
+			workspace = pinnedDir
+		} else if name != "" {
 			// Note: Worktree creation handled by server in socket mode
 			wtp, err := m.handleWorktreeCreation(name)
 			if err == nil && wtp != "" {
@@ -60,7 +77,6 @@ func (m *Model) handleNewTab(name string) tea.Cmd {
 		defer cancel()
 
 		var err error
-		workspace := m.workingDir // Use working dir as workspace
 		tabSession, err = m.socketFactory.CreateSocketSession(ctx, workspace, workingDir)
 		if err != nil {
 			m.AddSystemMessage(fmt.Sprintf("Failed to create socket session: %v", err))
@@ -70,6 +86,7 @@ func (m *Model) handleNewTab(name string) tea.Cmd {
 		// Set tab metadata
 		tabSession.Name = name
 		tabSession.WorktreePath = worktreePath
+		tabSession.WorkingDir = pinnedDir
 	} else {
 		// Local mode: create session locally
 		m.sessionIDCounter++
@@ -80,7 +97,9 @@ func (m *Model) handleNewTab(name string) tea.Cmd {
 		workingDir := m.workingDir
 		worktreePath := ""
 
-		if name != "" {
+		if pinnedDir != "" {
+			workingDir = pinnedDir
+		} else if name != "" {
 			// Try to create worktree for named session
 			wtp, err := m.handleWorktreeCreation(name)
 			if err == nil && wtp != "" {
@@ -98,6 +117,7 @@ func (m *Model) handleNewTab(name string) tea.Cmd {
 			Session:            sess,
 			Name:               name,
 			WorktreePath:       worktreePath,
+			WorkingDir:         pinnedDir,
 			Messages:           []message{},
 			CreatedAt:          time.Now(),
 			LastActiveAt:       time.Now(),
@@ -138,6 +158,7 @@ func (m *Model) handleSwitchTab(newIdx int) tea.Cmd {
 	m.thinkingTokens = newTabSession.ThinkingTokens
 	m.contextFreePercent = newTabSession.ContextFreePercent
 	m.contextWindow = newTabSession.ContextWindow
+	m.syncStatusAreaHeight()
 	m.updateViewport()
 
 	// Update context file if new tab has a runtime
@@ -284,6 +305,7 @@ func (m *Model) saveTabState() error {
 		TabIDs:        make([]int, len(m.sessions)),
 		TabNames:      make(map[int]string),
 		WorktreePaths: make(map[int]string),
+		WorkingDirs:   make(map[int]string),
 	}
 
 	for i, ts := range m.sessions {
@@ -294,6 +316,9 @@ func (m *Model) saveTabState() error {
 		if ts.WorktreePath != "" {
 			tabState.WorktreePaths[ts.ID] = ts.WorktreePath
 		}
+		if ts.WorkingDir != "" {
+			tabState.WorkingDirs[ts.ID] = ts.WorkingDir
+		}
 	}
 
 	// Use thread-safe method to set tab state
@@ -327,9 +352,19 @@ func (m *Model) restoreTabs() error {
 			}
 		}
 
+		pinnedDir := tabState.WorkingDirs[tabID]
+		if pinnedDir != "" {
+			if info, err := os.Stat(pinnedDir); err != nil || !info.IsDir() {
+				logger.Warn("Tab working directory no longer exists: %s", pinnedDir)
+				pinnedDir = ""
+			}
+		}
+
 		// Determine working directory
 		workingDir := m.workingDir
-		if worktreePath != "" {
+		if pinnedDir != "" {
+			workingDir = pinnedDir
+		} else if worktreePath != "" {
 			workingDir = worktreePath
 		}
 
@@ -342,6 +377,7 @@ func (m *Model) restoreTabs() error {
 			Session:            sess,
 			Name:               name,
 			WorktreePath:       worktreePath,
+			WorkingDir:         pinnedDir,
 			Messages:           []message{},
 			CreatedAt:          time.Now(),
 			LastActiveAt:       time.Now(),
@@ -391,6 +427,34 @@ func (m *Model) createDefaultTab() error {
 	return nil
 }
 
+// resolveTabDir resolves the directory a tab is pinned to, relative to
+// baseDir, and checks that it exists
+func resolveTabDir(baseDir, dir string) (string, error) {
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return dir, nil
+}
+
 // isValidSessionName validates a session name
 func isValidSessionName(name string) bool {
 	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, name)
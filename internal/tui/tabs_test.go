@@ -34,7 +34,7 @@ func TestHandleNewTabValidation(t *testing.T) {
 	t.Run("rejects invalid name", func(t *testing.T) {
 		m := New("test-model", "", true)
 
-		m.handleNewTab("bad name")
+		m.handleNewTab("bad name", "")
 
 		if len(m.sessions) != 0 {
 			t.Fatalf("expected no sessions to be created, got %d", len(m.sessions))
@@ -56,7 +56,7 @@ func TestHandleNewTabValidation(t *testing.T) {
 		}}
 		m.activeSessionIdx = 0
 
-		m.handleNewTab("duplicate")
+		m.handleNewTab("duplicate", "")
 
 		if len(m.sessions) != 1 {
 			t.Fatalf("expected sessions to remain unchanged, got %d", len(m.sessions))
@@ -70,7 +70,7 @@ func TestHandleNewTabValidation(t *testing.T) {
 func TestHandleNewTabMaxLimit(t *testing.T) {
 	m := newModelWithTabs(t, maxTabs)
 
-	m.handleNewTab("extra")
+	m.handleNewTab("extra", "")
 
 	if len(m.sessions) != maxTabs {
 		t.Fatalf("expected tab count to stay at max (%d), got %d", maxTabs, len(m.sessions))
@@ -83,7 +83,7 @@ func TestHandleNewTabMaxLimit(t *testing.T) {
 func TestHandleNewTabCreatesSession(t *testing.T) {
 	m := New("test-model", "", true)
 
-	cmd := m.handleNewTab("")
+	cmd := m.handleNewTab("", "")
 
 	if cmd != nil {
 		t.Fatalf("expected nil command for new tab, got %v", cmd)
@@ -213,8 +213,13 @@ func (rf *ResultFormatter) formatCreateFileResult(result string, metadata *tools
 
 // formatEditFileResult formats edit/replace file results with enhanced diff display
 func (rf *ResultFormatter) formatEditFileResult(result string, metadata *tools.ExecutionMetadata, state ToolState) string {
-	// Count actual changes in the diff
-	additions, deletions := countDiffChanges(result)
+	// Prefer the counts the tool computed; fall back to counting the diff
+	var additions, deletions int
+	if metadata != nil && metadata.Diff != nil {
+		additions, deletions = metadata.Diff.Additions, metadata.Diff.Deletions
+	} else {
+		additions, deletions = countDiffChanges(result)
+	}
 
 	var duration time.Duration
 	if metadata != nil && metadata.DurationMs > 0 {
@@ -310,9 +310,10 @@ func InitializeToolStyles() *ToolStyles {
 		Padding(1).
 		Margin(1, 0)
 
-	// Initialize spinner for running state
+	// Initialize spinner for running state; applySpinnerConfig switches it to
+	// the configured spinner_style
 	ts.Spinner = spinner.New(
-		spinner.WithSpinner(spinner.Dot),
+		spinner.WithSpinner(spinnerStyles[defaultSpinnerStyle]),
 		spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(ColorStateRunning))),
 	)
 
@@ -345,7 +346,7 @@ func GetToolTypeFromName(name string) ToolType {
 		return ToolTypeGoSandbox
 	case tools.ToolNameWebSearch:
 		return ToolTypeWebSearch
-	case tools.ToolNameWebFetch:
+	case tools.ToolNameWebFetch, tools.ToolNameFetchDocs:
 		return ToolTypeWebFetch
 	case tools.ToolNameStatusProgram:
 		return ToolTypeStatus
@@ -669,6 +670,10 @@ func extractPrimaryParameter(toolName string, parameters map[string]interface{})
 		if jobID, ok := parameters["job_id"].(string); ok {
 			return truncateStringSmart(jobID, 15)
 		}
+	case tools.ToolNameBackgroundProcesses:
+		if pid, ok := parameters["pid"].(float64); ok {
+			return fmt.Sprintf("pid %d", int(pid))
+		}
 
 	// Search operations - pattern is primary
 	case tools.ToolNameSearchFiles:
@@ -713,6 +718,13 @@ func extractPrimaryParameter(toolName string, parameters map[string]interface{})
 		if directory, ok := parameters["directory"].(string); ok {
 			return truncatePathSmart(directory, 40)
 		}
+	case tools.ToolNameFetchDocs:
+		if url, ok := parameters["url"].(string); ok && url != "" {
+			return truncateURLSmart(url, 35)
+		}
+		if pkg, ok := parameters["package"].(string); ok {
+			return truncateStringSmart(pkg, 35)
+		}
 
 	// Parallel execution - count
 	case tools.ToolNameParallel:
@@ -792,7 +804,9 @@ func extractSecondaryParameters(toolName string, parameters map[string]interface
 		if libs, ok := parameters["libraries"].([]interface{}); ok && len(libs) > 0 {
 			secondary["libs"] = fmt.Sprintf("%d", len(libs))
 		}
-		if timeout, ok := parameters["timeout"].(float64); ok && timeout > 0 {
+		if timeout, ok := parameters["timeout_seconds"].(float64); ok && timeout > 0 {
+			secondary["timeout"] = fmt.Sprintf("%.0fs", timeout)
+		} else if timeout, ok := parameters["timeout"].(float64); ok && timeout > 0 {
 			secondary["timeout"] = fmt.Sprintf("%.0fs", timeout)
 		}
 		if bg, ok := parameters["background"].(bool); ok && bg {
@@ -241,3 +241,23 @@ func toolSummaryContainsStrHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestEnhancedToolSummaryUsesDiffMetadata(t *testing.T) {
+	m := &Model{}
+	metadata := &tools.ExecutionMetadata{
+		ToolType: "file_write",
+		Diff:     &tools.DiffSummary{FilesChanged: 2, Additions: 5, Deletions: 3, Hunks: 4},
+	}
+
+	// The raw diff would count header-like lines such as "+++" as additions
+	raw := "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b\n"
+	summary := m.generateEnhancedToolSummary(tools.ToolNameReplaceInFiles, raw, metadata, false)
+	if summary != "✓ **2 files updated** • +5/-3 lines in 4 hunks" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+
+	summary = m.generateEnhancedToolSummary(tools.ToolNameEditFile, raw, nil, false)
+	if summary != "✓ **Changed: +1/-1 lines**" {
+		t.Errorf("unexpected fallback summary %q", summary)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -24,6 +25,7 @@ import (
 	"github.com/codefionn/scriptschnell/internal/consts"
 	"github.com/codefionn/scriptschnell/internal/fs"
 	"github.com/codefionn/scriptschnell/internal/htmlconv"
+	"github.com/codefionn/scriptschnell/internal/llm"
 	"github.com/codefionn/scriptschnell/internal/logger"
 	"github.com/codefionn/scriptschnell/internal/progress"
 	"github.com/codefionn/scriptschnell/internal/provider"
@@ -156,6 +158,11 @@ type Model struct {
 	thinkingTokens       int                    // Current thinking/reasoning tokens during generation
 	contentReceived      bool                   // Track if any content has been received in current generation
 	sanitizeState        ansiSanitizeState
+	draftTokens          int // Debounced token estimate of the prompt draft
+	draftEstimateToken   int
+	windowBlurred        bool              // Terminal reported that it lost focus
+	activities           *statusActivities // Concurrently running tool calls for the status area
+	notifyOut            io.Writer         // Destination of completion bells (defaults to stdout)
 	showTodoPanel        bool
 	todoClient           *tools.TodoActorClient
 	todoViewport         viewport.Model
@@ -381,6 +388,7 @@ type TabToolResultMsg struct {
 	ToolID   string
 	Result   string
 	Error    string
+	Metadata *tools.ExecutionMetadata // nil if the tool reported none
 }
 
 // AuthorizationRequest represents a pending authorization request
@@ -390,7 +398,11 @@ type AuthorizationRequest struct {
 	ToolName     string
 	Parameters   map[string]interface{}
 	Reason       string
+	IsDomainAuth bool      // Network access, which can also be approved for the session only
 	ResponseChan chan bool // Channel to send approval result
+	// EditedParams are set before an approval is sent if the user edited
+	// the parameters
+	EditedParams map[string]interface{}
 }
 
 // pendingUserInteraction stores channels for handler-based user interactions
@@ -404,6 +416,10 @@ type pendingUserInteraction struct {
 type AuthorizationResponseMsg struct {
 	AuthID   string
 	Approved bool
+	// SessionOnly limits the approval to the current session
+	SessionOnly bool
+	// EditedParams replace the tool's parameters of an approval
+	EditedParams map[string]interface{}
 }
 
 // ShowAuthorizationDialogMsg is sent to display an authorization dialog
@@ -433,7 +449,14 @@ type RendererReadyMsg struct {
 
 // NewTabMsg is sent to create a new tab
 type NewTabMsg struct {
-	Name string
+	Name       string
+	WorkingDir string // Directory to pin the tab to (empty = worktree or default directory)
+}
+
+// SubmitPromptMsg is sent to submit a prompt on the active tab as if it was
+// typed, e.g. an expanded snippet
+type SubmitPromptMsg struct {
+	Prompt string
 }
 
 func New(currentModel, contextFile string, disableAnimations bool) *Model {
@@ -456,14 +479,10 @@ func New(currentModel, contextFile string, disableAnimations bool) *Model {
 
 	// Create markdown renderer with a default width
 	// Will be updated when window size is received
-	renderer, _ := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(80),
-		glamour.WithPreservedNewLines(),
-	)
+	renderer, _ := newMarkdownRenderer(80)
 
 	sp := spinner.New(
-		spinner.WithSpinner(spinner.Line),
+		spinner.WithSpinner(spinnerStyles[defaultSpinnerStyle]),
 		spinner.WithStyle(statusStyle.MarginLeft(0)),
 	)
 
@@ -513,6 +532,7 @@ func NewWithFactory(factory *RuntimeFactory, cfg *config.Config, providerMgr *pr
 	currentModel := providerMgr.GetOrchestrationModel()
 
 	m := New(currentModel, "", cfg.DisableAnimations)
+	m.applySpinnerConfig(cfg)
 	m.factory = factory
 	m.config = cfg
 	m.workingDir = factory.GetWorkingDir()
@@ -530,6 +550,7 @@ func NewWithSocketFactory(socketFactory *SocketRuntimeFactory, cfg *config.Confi
 	currentModel := providerMgr.GetOrchestrationModel()
 
 	m := New(currentModel, "", cfg.DisableAnimations)
+	m.applySpinnerConfig(cfg)
 	m.socketFactory = socketFactory
 	m.config = cfg
 	m.workingDir = socketFactory.GetWorkingDir()
@@ -567,10 +588,9 @@ func (m *Model) getExtendedContextFileWithoutOrchestrator() string {
 	filesystem := m.factory.GetSharedFilesystem()
 	ctx := context.Background()
 
-	// First try AGENTS.md (llm.AgentsFileName)
-	exists, err := filesystem.Exists(ctx, "AGENTS.md")
-	if err == nil && exists {
-		return "AGENTS.md"
+	// First try the configured context files
+	if files := llm.ContextFilesForConfig(ctx, filesystem, "", m.config); len(files) > 0 {
+		return files[0]
 	}
 
 	// Fall back to README variants
@@ -647,6 +667,14 @@ func (m *Model) SetConfig(cfg *config.Config) {
 	m.config = cfg
 }
 
+// pasteANSIMode returns how escape sequences in prompt input are handled
+func (m *Model) pasteANSIMode() string {
+	if m.config == nil {
+		return config.PasteANSIModeStrip
+	}
+	return normalizePasteANSIMode(m.config.TUI.PasteANSIMode)
+}
+
 // SetActiveMCPProvider registers a callback that supplies currently active MCP servers.
 func (m *Model) SetActiveMCPProvider(provider func() []string) {
 	m.activeMCPProvider = provider
@@ -690,6 +718,20 @@ func (m *Model) GetActiveTab() *TabSession {
 	return nil
 }
 
+// activeWorkingDir returns the working directory of the active tab, which may
+// be pinned to another directory than the one the TUI was started in
+func (m *Model) activeWorkingDir() string {
+	if tab := m.GetActiveTab(); tab != nil {
+		return tab.Dir(m.workingDir)
+	}
+	return m.workingDir
+}
+
+// ActiveWorkingDir returns the working directory of the active tab
+func (m *Model) ActiveWorkingDir() string {
+	return m.activeWorkingDir()
+}
+
 // GetAllTabs returns all tab sessions
 func (m *Model) GetAllTabs() []*TabSession {
 	return m.sessions
@@ -727,11 +769,7 @@ func (m *Model) findTabIndexByID(tabID int) int {
 
 func (m *Model) createRendererAsync(wrapWidth int) tea.Cmd {
 	return func() tea.Msg {
-		renderer, err := glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(wrapWidth),
-			glamour.WithPreservedNewLines(),
-		)
+		renderer, err := newMarkdownRenderer(wrapWidth)
 		return RendererReadyMsg{
 			Renderer: renderer,
 			Width:    wrapWidth,
@@ -786,10 +824,7 @@ func (m *Model) applyWindowSize(width, height int) (bool, bool, tea.Cmd) {
 	}
 	m.contentWidth = available
 
-	vpHeight := height - 10
-	if vpHeight < 1 {
-		vpHeight = 1
-	}
+	vpHeight := m.viewportHeightFor(height)
 	if !m.ready {
 		m.viewport = viewport.New(available, vpHeight)
 	} else {
@@ -1113,20 +1148,21 @@ func (m *Model) getFilepathSuggestions(partialPath string) []string {
 
 // getDirectoryBasedSuggestions searches within a specific directory path
 func (m *Model) getDirectoryBasedSuggestions(ctx context.Context, partialPath string) []string {
+	workingDir := m.activeWorkingDir()
 	var searchDir string
 	var prefix string
 
 	if strings.HasSuffix(partialPath, "/") {
 		// Path ends with / - list that directory
-		searchDir = filepath.Join(m.workingDir, partialPath)
+		searchDir = filepath.Join(workingDir, partialPath)
 		prefix = ""
 	} else {
 		// Path has a partial filename - list parent dir and filter
 		dir, file := filepath.Split(partialPath)
 		if dir == "" {
-			searchDir = m.workingDir
+			searchDir = workingDir
 		} else {
-			searchDir = filepath.Join(m.workingDir, dir)
+			searchDir = filepath.Join(workingDir, dir)
 		}
 		prefix = file
 	}
@@ -1178,13 +1214,14 @@ func (m *Model) getDirectoryBasedSuggestions(ctx context.Context, partialPath st
 
 // getRecursiveFilenameSuggestions searches for matching filenames in current dir and subdirectories
 func (m *Model) getRecursiveFilenameSuggestions(ctx context.Context, prefix string) []string {
+	workingDir := m.activeWorkingDir()
 	var suggestions []string
 	maxDepth := 3    // Limit recursion depth to avoid performance issues
 	maxResults := 20 // Limit total results
 
 	// If empty prefix, just show current directory contents
 	if prefix == "" {
-		entries, err := m.filesystem.ListDir(ctx, m.workingDir)
+		entries, err := m.filesystem.ListDir(ctx, workingDir)
 		if err != nil {
 			return nil
 		}
@@ -1250,7 +1287,7 @@ func (m *Model) getRecursiveFilenameSuggestions(ctx context.Context, prefix stri
 			// Check if basename matches prefix
 			if strings.HasPrefix(baseName, prefix) {
 				// Calculate relative path from working dir
-				relPath, err := filepath.Rel(m.workingDir, entry.Path)
+				relPath, err := filepath.Rel(workingDir, entry.Path)
 				if err != nil {
 					relPath = entry.Path
 				}
@@ -1269,7 +1306,7 @@ func (m *Model) getRecursiveFilenameSuggestions(ctx context.Context, prefix stri
 		}
 	}
 
-	searchDir(m.workingDir, 0)
+	searchDir(workingDir, 0)
 	return suggestions
 }
 
@@ -1390,6 +1427,10 @@ func (m *Model) cycleSuggestion(direction int) {
 func (m *Model) handleAuthorizationDialog(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.authorizationDialog.IsEditing() {
+			return m.handleAuthorizationEditor(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			// Deny and close
@@ -1417,14 +1458,19 @@ func (m *Model) handleAuthorizationDialog(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			if typedItem, ok := item.(authChoiceItem); ok {
-				approved := typedItem.value == "approve"
+				if typedItem.value == authChoiceEdit {
+					return m, m.authorizationDialog.startEditing()
+				}
+				approved := typedItem.value == authChoiceApprove || typedItem.value == authChoiceApproveSession
+				sessionOnly := typedItem.value == authChoiceApproveSession
 				authID := m.activeAuthorizationID
 				logger.Debug("User %s authorization via Enter for authID %s", map[bool]string{true: "approved", false: "denied"}[approved], authID)
 				// Return as command to avoid deadlock from calling program.Send() within Update
 				return m, func() tea.Msg {
 					return AuthorizationResponseMsg{
-						AuthID:   authID,
-						Approved: approved,
+						AuthID:      authID,
+						Approved:    approved,
+						SessionOnly: sessionOnly,
 					}
 				}
 			} else {
@@ -1478,6 +1524,44 @@ func (m *Model) handleAuthorizationDialog(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleAuthorizationEditor handles keys while the parameters of an
+// authorization are edited
+func (m *Model) handleAuthorizationEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Back to the choices; ctrl+c still denies
+		m.authorizationDialog.stopEditing()
+		return m, nil
+
+	case "ctrl+c":
+		authID := m.activeAuthorizationID
+		return m, func() tea.Msg {
+			return AuthorizationResponseMsg{AuthID: authID, Approved: false}
+		}
+
+	case "ctrl+s":
+		params, err := m.authorizationDialog.editedParams()
+		if err != nil {
+			m.authorizationDialog.editErr = err.Error()
+			return m, nil
+		}
+		authID := m.activeAuthorizationID
+		logger.Debug("User approved authorization with edited parameters for authID %s", authID)
+		// Return as command to avoid deadlock from calling program.Send() within Update
+		return m, func() tea.Msg {
+			return AuthorizationResponseMsg{
+				AuthID:       authID,
+				Approved:     true,
+				EditedParams: params,
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.authorizationDialog.editor, cmd = m.authorizationDialog.editor.Update(msg)
+	return m, cmd
+}
+
 // handleDirectoryAccessDialog handles messages when directory access dialog is open
 func (m *Model) handleDirectoryAccessDialog(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -1661,6 +1745,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		vpCmd tea.Cmd
 	)
 
+	// Track terminal focus for completion notifications, even while a dialog is open
+	switch msg := msg.(type) {
+	case tea.FocusMsg:
+		m.windowBlurred = false
+	case tea.BlurMsg:
+		m.windowBlurred = true
+	case completionNotifyMsg:
+		m.writeCompletionNotification(msg.sequence)
+		return m, nil
+	}
+
 	// Handle AuthorizationResponseMsg first, even if dialog is open
 	// This ensures the response is processed and the dialog is closed
 	if _, ok := msg.(AuthorizationResponseMsg); ok {
@@ -1724,15 +1819,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Sanitize ANSI sequences
 		currentValue := m.textarea.Value()
-		if sanitized := sanitizePromptInput(currentValue, &m.sanitizeState); sanitized != currentValue {
+		if sanitized := sanitizePromptInputMode(currentValue, &m.sanitizeState, m.pasteANSIMode()); sanitized != currentValue {
 			currentValue = sanitized
 			m.textarea.SetValue(currentValue)
 		}
 
+		// Attach oversized pastes as a file reference instead of inlining them
+		if diverted, ok := m.divertLargePaste(prevValue, currentValue); ok {
+			currentValue = diverted
+			m.textarea.SetValue(currentValue)
+		}
+
 		// Convert HTML to markdown if detected (on paste or large content changes)
 		// Only check if there's a significant amount of new content (likely a paste)
+		// and skip oversized input, which would stall the UI
 		contentGrowth := len(currentValue) - len(prevValue)
-		if contentGrowth > 100 || (len(currentValue) > 200 && contentGrowth > 50) {
+		limit := m.maxPasteBytes()
+		tooLarge := limit > 0 && len(currentValue) > limit
+		if !tooLarge && (contentGrowth > 100 || (len(currentValue) > 200 && contentGrowth > 50)) {
 			if converted, wasConverted := htmlconv.ConvertIfHTML(currentValue); wasConverted {
 				m.textarea.SetValue(converted)
 				// Show conversion message with size info
@@ -1744,6 +1848,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if valueChanged && m.onPromptActivity != nil {
 			m.onPromptActivity()
 		}
+		if valueChanged {
+			tiCmd = tea.Batch(tiCmd, m.scheduleDraftEstimate())
+		}
 		if _, ok := msg.(tea.KeyMsg); ok {
 			m.originalSuggestions = nil
 			m.originalInput = ""
@@ -1797,7 +1904,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, baseCmd
 
 		case "ctrl+d":
-			if strings.TrimSpace(sanitizePromptInput(m.textarea.Value(), &m.sanitizeState)) == "" {
+			if strings.TrimSpace(sanitizePromptInputMode(m.textarea.Value(), &m.sanitizeState, m.pasteANSIMode())) == "" {
 				return m, tea.Batch(baseCmd, tea.Quit)
 			}
 			return m, baseCmd
@@ -1851,7 +1958,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "ctrl+t":
 			// Create new unnamed tab
-			return m, m.handleNewTab("")
+			return m, m.handleNewTab("", "")
 
 		case "ctrl+w":
 			// Close current tab
@@ -1962,7 +2069,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			// Process the prompt, but handle autocomplete first.
-			rawInput := sanitizePromptInput(m.textarea.Value(), &m.sanitizeState)
+			rawInput := sanitizePromptInputMode(m.textarea.Value(), &m.sanitizeState, m.pasteANSIMode())
 			input := strings.TrimSpace(rawInput)
 
 			// If we have autocomplete suggestions:
@@ -2003,15 +2110,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Batch(baseCmd, m.handleCommand(input))
 			}
 
-			// Check if current tab is generating
-			if m.isCurrentTabGenerating() {
-				// Queue the prompt for this tab
-				m.queuePrompt(m.activeSessionIdx, input)
-				return m, baseCmd
-			}
-
-			// Start prompt on active tab
-			return m, tea.Batch(baseCmd, m.startPrompt(m.activeSessionIdx, input))
+			return m, tea.Batch(baseCmd, m.submitPrompt(input))
 		}
 
 		return m, baseCmd
@@ -2048,6 +2147,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, baseCmd
 
+	case draftEstimateMsg:
+		if msg.token == m.draftEstimateToken {
+			m.draftTokens = estimateDraftTokens(m.textarea.Value())
+		}
+		return m, baseCmd
+
 	case RendererReadyMsg:
 		m.rendererInitMutex.Lock()
 		if msg.Err == nil && msg.Renderer != nil {
@@ -2121,7 +2226,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, baseCmd
 
 	case NewTabMsg:
-		return m, m.handleNewTab(msg.Name)
+		return m, m.handleNewTab(msg.Name, msg.WorkingDir)
+
+	case SubmitPromptMsg:
+		return m, m.submitPrompt(msg.Prompt)
 
 	case UserInputRequestMsg:
 		cmd := m.handleUserInputRequest(msg)
@@ -2270,6 +2378,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Mark tab as no longer generating
 		m.setTabGenerating(tabIdx, false)
+		m.statusArea().ClearTab(msg.TabID)
+		m.syncStatusAreaHeight()
 		m.sessions[tabIdx].ThinkingTokens = 0
 
 		// Clear status if this was the active tab
@@ -2286,8 +2396,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		tabName := fmt.Sprintf("Tab %d", tabIdx+1)
+		if m.sessions[tabIdx].Name != "" {
+			tabName = m.sessions[tabIdx].Name
+		}
+
 		// Process queued prompts for this tab
-		return m, m.processNextQueuedPromptForTab(tabIdx)
+		return m, tea.Batch(m.notifyCompletion(tabName, msg.Error), m.processNextQueuedPromptForTab(tabIdx))
 
 	case TabAuthorizationRequiredMsg:
 		tabIdx := m.findTabIndexByID(msg.TabID)
@@ -2334,11 +2449,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Create a temporary AuthorizationRequest for the dialog (without ResponseChan)
 		tempRequest := &AuthorizationRequest{
-			AuthID:     msg.RequestID,
-			TabID:      msg.TabID,
-			ToolName:   msg.ToolName,
-			Parameters: msg.Parameters,
-			Reason:     msg.Reason,
+			AuthID:       msg.RequestID,
+			TabID:        msg.TabID,
+			ToolName:     msg.ToolName,
+			Parameters:   msg.Parameters,
+			Reason:       msg.Reason,
+			IsDomainAuth: msg.IsDomainAuth,
 		}
 
 		// Create authorization dialog
@@ -2366,7 +2482,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// First try the new handler-based approach
 		if m.userInteractionHandler != nil {
-			m.userInteractionHandler.HandleAuthorizationResponse(msg.AuthID, msg.Approved)
+			if msg.Approved && msg.EditedParams != nil {
+				m.userInteractionHandler.HandleEditedAuthorizationResponse(msg.AuthID, msg.EditedParams)
+			} else {
+				m.userInteractionHandler.HandleScopedAuthorizationResponse(msg.AuthID, msg.Approved, msg.SessionOnly)
+			}
 		}
 
 		// Then try the legacy channel-based approach
@@ -2378,6 +2498,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if ok {
 			// Get tab index and remove from pending while holding lock
 			tabIdxToUpdate = m.findTabIndexByID(request.TabID)
+			request.EditedParams = msg.EditedParams
 			delete(m.pendingAuthorizations, msg.AuthID)
 			logger.Debug("Found pending authorization request for authID %s, will send response", msg.AuthID)
 		} else {
@@ -2479,6 +2600,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		tabIdx := m.findTabIndexByID(msg.TabID)
 		if tabIdx >= 0 {
 			m.addToolCallMessageForTab(tabIdx, msg.ToolName, msg.ToolID, msg.Description, msg.Parameters)
+			m.statusArea().Set(msg.TabID, msg.ToolID, toolActivityLabel(msg.ToolName, msg.Parameters), "")
+			m.syncStatusAreaHeight()
 			// Track active tool for this tab for progress panel updates
 			m.activeToolPerTab[msg.TabID] = msg.ToolID
 			// Start tracking in progress panel
@@ -2492,7 +2615,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TabToolResultMsg:
 		tabIdx := m.findTabIndexByID(msg.TabID)
 		if tabIdx >= 0 {
-			m.addToolResultMessageForTab(tabIdx, msg.ToolName, msg.ToolID, msg.Result, msg.Error)
+			m.addToolResultMessageForTab(tabIdx, msg.ToolName, msg.ToolID, msg.Result, msg.Error, msg.Metadata)
+			m.statusArea().Remove(msg.ToolID)
+			m.syncStatusAreaHeight()
 			// Clear active tool ID when tool completes
 			delete(m.activeToolPerTab, msg.TabID)
 
@@ -2566,6 +2691,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showProgressPanel = len(activeTools) > 0
 		}
 
+		// Show the latest status of each running tool in the status area
+		if msg.Status != "" {
+			m.statusArea().Set(msg.TabID, msg.ToolID, msg.ToolName, msg.Status)
+			m.syncStatusAreaHeight()
+		}
+
 		// Also update the status field in the message itself
 		if msg.Status != "" {
 			tabIdx := m.findTabIndexByID(msg.TabID)
@@ -2592,6 +2723,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(baseCmd, m.scheduleViewportRefresh())
 
 	case ToolProgressCompleteMsg:
+		m.statusArea().Remove(msg.ToolID)
+		m.syncStatusAreaHeight()
+
 		// Mark tool as complete in tracker
 		if m.toolProgressTracker != nil {
 			var err error
@@ -2780,7 +2914,7 @@ func (m *Model) startPromptForTab(tabIdx int, input string) tea.Cmd {
 	progressCallback := m.createProgressCallbackForTab(tab.ID)
 
 	// Create authorization callback for this tab
-	authorizationCallback := func(toolName string, params map[string]interface{}, reason string) (bool, error) {
+	authorizationCallback := func(toolName string, params map[string]interface{}, reason string) (AuthorizationResponse, error) {
 		logger.Debug("Tab %d: authorization requested for tool %s: %s", tab.ID, toolName, reason)
 
 		// Generate unique authorization ID
@@ -2831,9 +2965,13 @@ func (m *Model) startPromptForTab(tabIdx int, input string) tea.Cmd {
 		// Cleanup
 		m.authorizationMu.Lock()
 		delete(m.pendingAuthorizations, authID)
+		editedParams := request.EditedParams
 		m.authorizationMu.Unlock()
 
-		return approved, nil
+		if !approved {
+			editedParams = nil
+		}
+		return AuthorizationResponse{Approved: approved, EditedParams: editedParams}, nil
 	}
 
 	// Create tool call callback for this tab
@@ -2865,6 +3003,7 @@ func (m *Model) startPromptForTab(tabIdx int, input string) tea.Cmd {
 			ToolID:   toolID,
 			Result:   result,
 			Error:    errorMsg,
+			Metadata: runtime.Orchestrator.ToolResultMetadata(toolID),
 		})
 		return nil
 	}
@@ -3038,6 +3177,16 @@ func (m *Model) autoSaveSession(runtime *TabRuntime, orchErr error) {
 	}
 }
 
+// submitPrompt starts a prompt on the active tab, or queues it while the tab
+// is generating
+func (m *Model) submitPrompt(input string) tea.Cmd {
+	if m.isCurrentTabGenerating() {
+		m.queuePrompt(m.activeSessionIdx, input)
+		return nil
+	}
+	return m.startPrompt(m.activeSessionIdx, input)
+}
+
 func (m *Model) startPrompt(tabIdx int, input string) tea.Cmd {
 	// In concurrent mode, directly start the prompt for the specified tab
 	if m.useSocketMode {
@@ -3191,6 +3340,9 @@ func (m *Model) View() string {
 	sb.WriteString(m.renderContextUsage())
 	sb.WriteString("\n")
 
+	// Concurrent activities (only while more than one is running)
+	sb.WriteString(m.renderStatusArea())
+
 	// Footer
 	sb.WriteString(m.renderMainFooter())
 
@@ -3619,7 +3771,7 @@ func (m *Model) appendToLastMessageForTab(tabIdx int, content string) {
 	lastMsg := &msgs[len(msgs)-1]
 	if lastMsg.role == "Tool" && !lastMsg.summarized && lastMsg.fullResult != "" {
 		// Replace with summary
-		summary := m.generateEnhancedToolSummary(lastMsg.toolName, lastMsg.fullResult, false)
+		summary := m.generateEnhancedToolSummary(lastMsg.toolName, lastMsg.fullResult, lastMsg.executionMetadata, false)
 		lastMsg.content = summary
 		lastMsg.summarized = true
 	}
@@ -3640,7 +3792,7 @@ func (m *Model) summarizeLastToolResultForTab(tabIdx int) {
 
 	lastMsg := &msgs[len(msgs)-1]
 	if lastMsg.role == "Tool" && !lastMsg.summarized && lastMsg.fullResult != "" {
-		summary := m.generateEnhancedToolSummary(lastMsg.toolName, lastMsg.fullResult, false)
+		summary := m.generateEnhancedToolSummary(lastMsg.toolName, lastMsg.fullResult, lastMsg.executionMetadata, false)
 		lastMsg.content = summary
 		lastMsg.summarized = true
 		m.storeMessagesForTab(tabIdx, msgs, tabIdx == m.activeSessionIdx)
@@ -3859,10 +4011,10 @@ func (m *Model) handleParallelToolCall(tabIdx int, toolID string, parameters map
 }
 
 func (m *Model) addToolResultMessage(toolName, toolID, result, errorMsg string) {
-	m.addToolResultMessageForTab(m.targetGenerationTab(), toolName, toolID, result, errorMsg)
+	m.addToolResultMessageForTab(m.targetGenerationTab(), toolName, toolID, result, errorMsg, nil)
 }
 
-func (m *Model) addToolResultMessageForTab(tabIdx int, toolName, toolID, result, errorMsg string) {
+func (m *Model) addToolResultMessageForTab(tabIdx int, toolName, toolID, result, errorMsg string, metadata *tools.ExecutionMetadata) {
 	isPlanning := strings.HasPrefix(toolName, "Planning: ")
 	realToolName := toolName
 	if isPlanning {
@@ -3875,8 +4027,10 @@ func (m *Model) addToolResultMessageForTab(tabIdx int, toolName, toolID, result,
 		state = ToolStateFailed
 	}
 
-	// Extract metadata for enhanced formatting
-	metadata := m.extractExecutionMetadata(result)
+	// Fall back to metadata embedded in the result for enhanced formatting
+	if metadata == nil {
+		metadata = m.extractExecutionMetadata(result)
+	}
 
 	// Use the enhanced ResultFormatter
 	rf := NewResultFormatter()
@@ -4010,8 +4164,7 @@ func (m *Model) generateToolSummary(toolName, result string, noOutput bool) stri
 
 	case tools.ToolNameEditFile:
 		// Count additions and deletions in the diff
-		additions := strings.Count(result, "\n+")
-		deletions := strings.Count(result, "\n-")
+		additions, deletions := countDiffChanges(result)
 		if additions > 0 && deletions > 0 {
 			return fmt.Sprintf("✓ **Changed: +%d/-%d lines**", additions, deletions)
 		} else if additions > 0 {
@@ -4035,13 +4188,16 @@ func (m *Model) generateToolSummary(toolName, result string, noOutput bool) stri
 }
 
 // generateEnhancedToolSummary creates detailed summaries using execution metadata when available
-func (m *Model) generateEnhancedToolSummary(toolName, result string, noOutput bool) string {
+func (m *Model) generateEnhancedToolSummary(toolName, result string, metadata *tools.ExecutionMetadata, noOutput bool) string {
 	if noOutput {
 		return "✓ **Executed successfully**"
 	}
 
 	// Try to extract metadata from the result if it's in JSON/map format
-	if metadata := m.extractExecutionMetadata(result); metadata != nil {
+	if metadata == nil {
+		metadata = m.extractExecutionMetadata(result)
+	}
+	if metadata != nil {
 		return m.generateMetadataAwareSummary(toolName, metadata)
 	}
 
@@ -4235,6 +4391,9 @@ func (m *Model) generateMetadataAwareSummary(toolName string, metadata *tools.Ex
 	case tools.ToolNameCreateFile, tools.ToolNameEditFile:
 		return m.generateFileOperationSummary(toolName, metadata)
 	default:
+		if metadata.Diff != nil {
+			return m.generateFileOperationSummary(toolName, metadata)
+		}
 		return m.generateGenericSummary(metadata)
 	}
 }
@@ -4406,12 +4565,23 @@ func (m *Model) generateFileOperationSummary(toolName string, metadata *tools.Ex
 	switch toolName {
 	case tools.ToolNameCreateFile:
 		action = "created"
-	case tools.ToolNameEditFile:
+	case tools.ToolNameEditFile, tools.ToolNameReplaceFile, tools.ToolNameReplaceInFiles:
 		action = "updated"
 	default:
 		action = "processed"
 	}
 
+	if diff := metadata.Diff; diff != nil {
+		summary := fmt.Sprintf("✓ **File %s** • +%d/-%d lines", action, diff.Additions, diff.Deletions)
+		if diff.FilesChanged > 1 {
+			summary = fmt.Sprintf("✓ **%d files %s** • +%d/-%d lines", diff.FilesChanged, action, diff.Additions, diff.Deletions)
+		}
+		if diff.Hunks > 1 {
+			summary += fmt.Sprintf(" in %d hunks", diff.Hunks)
+		}
+		return summary
+	}
+
 	summary := fmt.Sprintf("✓ **File %s**", action)
 	if metadata.OutputSizeBytes > 0 {
 		if metadata.OutputLineCount > 1 {
@@ -4444,6 +4614,8 @@ func (m *Model) resetInputState() {
 	m.textarea.Reset()
 	m.textarea.Placeholder = defaultInputPlaceholder
 	m.sanitizeState = ansiSanitizeState{}
+	m.draftTokens = 0
+	m.draftEstimateToken++
 	m.suggestions = nil
 	m.selectedSuggIndex = 0
 	m.originalSuggestions = nil
@@ -4633,9 +4805,13 @@ func (m *Model) ResumeLastSession(ctx context.Context, storageRef *actor.ActorRe
 	return nil
 }
 
-// sessionMessagesToTuiMessages converts persisted session messages into the TUI's display format
+// sessionMessagesToTuiMessages converts persisted session messages into the TUI's display format.
+// Tool results are formatted like live results (summarized, collapsed when large) and carry the
+// parameters of the assistant tool call that requested them. Assistant messages that only
+// contain tool calls are skipped, as the tool results already show those calls.
 func sessionMessagesToTuiMessages(stored []*session.Message) []message {
 	messages := make([]message, 0, len(stored))
+	toolCalls := make(map[string]map[string]interface{})
 	for _, msg := range stored {
 		role := msg.Role
 		switch strings.ToLower(msg.Role) {
@@ -4645,6 +4821,8 @@ func sessionMessagesToTuiMessages(stored []*session.Message) []message {
 			role = "Assistant"
 		case "tool":
 			role = "Tool"
+		case "system":
+			role = "System"
 		}
 
 		timestamp := ""
@@ -4652,6 +4830,22 @@ func sessionMessagesToTuiMessages(stored []*session.Message) []message {
 			timestamp = msg.Timestamp.Format("15:04:05")
 		}
 
+		if role == "Assistant" {
+			for _, call := range msg.ToolCalls {
+				if id, params := restoredToolCallParameters(call); id != "" {
+					toolCalls[id] = params
+				}
+			}
+			if msg.Content == "" && msg.Reasoning == "" && len(msg.ToolCalls) > 0 {
+				continue
+			}
+		}
+
+		if role == "Tool" {
+			messages = append(messages, restoredToolMessage(msg, timestamp, toolCalls[msg.ToolID]))
+			continue
+		}
+
 		messages = append(messages, message{
 			role:        role,
 			content:     msg.Content,
@@ -4665,6 +4859,73 @@ func sessionMessagesToTuiMessages(stored []*session.Message) []message {
 	return messages
 }
 
+// restoredToolCallParameters extracts the ID and decoded arguments of a persisted tool call
+func restoredToolCallParameters(call map[string]interface{}) (string, map[string]interface{}) {
+	id, _ := call["id"].(string)
+	function, ok := call["function"].(map[string]interface{})
+	if id == "" || !ok {
+		return id, nil
+	}
+
+	switch args := function["arguments"].(type) {
+	case map[string]interface{}:
+		return id, args
+	case string:
+		var params map[string]interface{}
+		if err := json.Unmarshal([]byte(args), &params); err == nil {
+			return id, params
+		}
+	}
+	return id, nil
+}
+
+// restoredToolMessage rebuilds a completed tool result message from a persisted tool message
+func restoredToolMessage(msg *session.Message, timestamp string, parameters map[string]interface{}) message {
+	result := msg.Content
+	state := ToolStateCompleted
+	errorMsg := ""
+	// The orchestrator stores failed tool calls as "Error: <message>"
+	if strings.HasPrefix(result, "Error: ") {
+		state = ToolStateFailed
+		errorMsg = strings.TrimPrefix(result, "Error: ")
+	}
+
+	var metadata *tools.ExecutionMetadata
+	var resultMap map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &resultMap); err == nil {
+		metadata = mapExecutionMetadata(resultMap["_execution_metadata"])
+	}
+
+	var executionTime time.Duration
+	if metadata != nil {
+		executionTime = time.Duration(metadata.DurationMs) * time.Millisecond
+	}
+
+	toolName := msg.ToolName
+	collapsible := shouldBeCollapsible(result)
+	return message{
+		role:              "Tool",
+		content:           NewResultFormatter().FormatToolResult(toolName, result, errorMsg, metadata, state),
+		timestamp:         timestamp,
+		toolName:          toolName,
+		toolID:            msg.ToolID,
+		description:       msg.ToolDescription,
+		toolState:         state,
+		toolType:          GetToolTypeFromName(toolName),
+		fullResult:        result,
+		summarized:        true,
+		isCollapsible:     collapsible,
+		isCollapsed:       collapsible,
+		progress:          1.0,
+		status:            "complete",
+		outputLines:       strings.Count(result, "\n"),
+		executionTime:     executionTime,
+		executionMetadata: metadata,
+		parameters:        parameters,
+		paramsCollapsed:   shouldAutoCollapseParams(parameters, DefaultParamCollapseConfig()),
+	}
+}
+
 // handleUserInputRequest handles single question user input requests
 func (m *Model) handleUserInputRequest(msg UserInputRequestMsg) tea.Cmd {
 	// Create user input dialog
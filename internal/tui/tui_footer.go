@@ -129,6 +129,9 @@ func (m *Model) contextDisplay() string {
 	}
 
 	// Add accumulated usage from session
+	if totalTokens > 0 {
+		parts = append(parts, fmt.Sprintf("Session: ~%s tokens", formatTokenCount(totalTokens)))
+	}
 	if totalCost > 0 {
 		parts = append(parts, fmt.Sprintf("Cost: $%.6f", totalCost))
 	}
@@ -141,8 +144,14 @@ func (m *Model) contextDisplay() string {
 	return strings.Join(parts, " | ")
 }
 
-// renderContextUsage renders the context freedom percentage indicator.
+// renderContextUsage renders the context freedom percentage indicator,
+// followed by the estimate for the current draft while typing.
 func (m *Model) renderContextUsage() string {
+	return m.renderContextFree() + m.renderDraftEstimate()
+}
+
+// renderContextFree renders the free context percentage.
+func (m *Model) renderContextFree() string {
 	percent := m.contextFreePercent
 	if percent < 0 {
 		return contextUsageStyle.Render("Free context: unknown")
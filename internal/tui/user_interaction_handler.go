@@ -168,11 +168,12 @@ func (h *TUIInteractionHandler) createDisplayMessage(req *actor.UserInteractionR
 		// Create an AuthorizationRequest compatible with existing TUI handling
 		// Note: We don't set ResponseChan since we use our own response mechanism
 		return TUIAuthorizationRequestMsg{
-			RequestID:  req.RequestID,
-			TabID:      req.TabID,
-			ToolName:   payload.ToolName,
-			Parameters: payload.Parameters,
-			Reason:     payload.Reason,
+			RequestID:    req.RequestID,
+			TabID:        req.TabID,
+			ToolName:     payload.ToolName,
+			Parameters:   payload.Parameters,
+			Reason:       payload.Reason,
+			IsDomainAuth: payload.IsDomainAuth,
 		}
 	case actor.InteractionTypePlanningQuestion, actor.InteractionTypeUserInputSingle:
 		payload, ok := req.Payload.(*actor.UserInputSinglePayload)
@@ -225,9 +226,27 @@ func (h *TUIInteractionHandler) HandleDialogDisplayed(requestID string) {
 
 // HandleAuthorizationResponse is called by TUI when user responds to authorization dialog
 func (h *TUIInteractionHandler) HandleAuthorizationResponse(requestID string, approved bool) {
+	h.HandleScopedAuthorizationResponse(requestID, approved, false)
+}
+
+// HandleScopedAuthorizationResponse is like HandleAuthorizationResponse, but
+// sessionOnly limits an approval to the current session
+func (h *TUIInteractionHandler) HandleScopedAuthorizationResponse(requestID string, approved, sessionOnly bool) {
 	h.handleResponse(requestID, &actor.UserInteractionResponse{
 		RequestID:    requestID,
 		Approved:     approved,
+		SessionOnly:  approved && sessionOnly,
+		Acknowledged: true,
+	})
+}
+
+// HandleEditedAuthorizationResponse is called by TUI when the user approves
+// an authorization after editing the tool's parameters
+func (h *TUIInteractionHandler) HandleEditedAuthorizationResponse(requestID string, params map[string]interface{}) {
+	h.handleResponse(requestID, &actor.UserInteractionResponse{
+		RequestID:    requestID,
+		Approved:     true,
+		EditedParams: params,
 		Acknowledged: true,
 	})
 }
@@ -335,11 +354,12 @@ func (h *TUIInteractionHandler) SetProgram(program *tea.Program) {
 
 // TUIAuthorizationRequestMsg is sent to TUI to display an authorization dialog via the handler
 type TUIAuthorizationRequestMsg struct {
-	RequestID  string
-	TabID      int
-	ToolName   string
-	Parameters map[string]interface{}
-	Reason     string
+	RequestID    string
+	TabID        int
+	ToolName     string
+	Parameters   map[string]interface{}
+	Reason       string
+	IsDomainAuth bool
 }
 
 // TUIUserInputRequestMsg is sent to TUI to display a user input dialog via the handler
@@ -1,7 +1,9 @@
 package vcs
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -91,6 +93,50 @@ func (g *Git) IsIgnored(ctx context.Context, absPath string) (bool, error) {
 	return ignored, nil
 }
 
+// IgnoredPaths checks all absPaths with one git check-ignore process and
+// returns the ignored ones. Paths outside the repository are never ignored.
+func (g *Git) IgnoredPaths(ctx context.Context, absPaths []string) (map[string]bool, error) {
+	ignored := make(map[string]bool)
+	repoRoot, err := g.getRepoRoot(ctx)
+	if err != nil {
+		return ignored, nil // Not in a repo, so nothing is ignored
+	}
+
+	absByRel := make(map[string]string, len(absPaths))
+	var input bytes.Buffer
+	for _, absPath := range absPaths {
+		relPath, err := filepath.Rel(repoRoot, absPath)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			continue
+		}
+		absByRel[relPath] = absPath
+		input.WriteString(relPath)
+		input.WriteByte(0)
+	}
+	if len(absByRel) == 0 {
+		return ignored, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "check-ignore", "--stdin", "-z")
+	cmd.Stdin = &input
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means none of the paths are ignored
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return ignored, nil
+		}
+		return nil, fmt.Errorf("git check-ignore failed: %w", err)
+	}
+
+	for _, relPath := range strings.Split(string(output), "\x00") {
+		if absPath, ok := absByRel[relPath]; ok {
+			ignored[absPath] = true
+		}
+	}
+	return ignored, nil
+}
+
 // CreateWorktree creates a new git worktree with the specified session name.
 // Returns the path to the created worktree.
 // The worktree is created in the parent directory of the repository root,
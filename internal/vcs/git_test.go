@@ -193,6 +193,38 @@ func TestGit_IsIgnored(t *testing.T) {
 		}
 	})
 
+	t.Run("checks many paths at once", func(t *testing.T) {
+		repoDir, cleanup := setupTestRepo(t)
+		defer cleanup()
+
+		if err := os.WriteFile(filepath.Join(repoDir, ".gitignore"), []byte("*.log\nbuild/\n"), 0644); err != nil {
+			t.Fatalf("Failed to create .gitignore: %v", err)
+		}
+		if err := os.Mkdir(filepath.Join(repoDir, "build"), 0755); err != nil {
+			t.Fatalf("Failed to create build dir: %v", err)
+		}
+
+		paths := []string{
+			filepath.Join(repoDir, "main.go"),
+			filepath.Join(repoDir, "debug.log"),
+			filepath.Join(repoDir, "build"),
+			filepath.Join(os.TempDir(), "outside.log"),
+		}
+		git := NewGit(repoDir)
+		ignored, err := git.IgnoredPaths(ctx, paths)
+		if err != nil {
+			t.Fatalf("IgnoredPaths failed: %v", err)
+		}
+		if len(ignored) != 2 || !ignored[paths[1]] || !ignored[paths[2]] {
+			t.Errorf("expected debug.log and build to be ignored, got %v", ignored)
+		}
+
+		ignored, err = git.IgnoredPaths(ctx, paths[:1])
+		if err != nil || len(ignored) != 0 {
+			t.Errorf("expected nothing ignored, got %v (err %v)", ignored, err)
+		}
+	})
+
 	t.Run("returns false for file outside repo", func(t *testing.T) {
 		repoDir, cleanup := setupTestRepo(t)
 		defer cleanup()
@@ -14,6 +14,9 @@ type MockVCS struct {
 
 	// CreateWorktreeFunc is the mock implementation for CreateWorktree
 	CreateWorktreeFunc func(ctx context.Context, sessionName string) (string, error)
+
+	// CurrentBranchFunc is the mock implementation for CurrentBranch
+	CurrentBranchFunc func(ctx context.Context) (string, error)
 }
 
 // RepositoryRoot calls the mock RepositoryRootFunc if set, otherwise returns empty string.
@@ -39,3 +42,11 @@ func (m *MockVCS) CreateWorktree(ctx context.Context, sessionName string) (strin
 	}
 	return "", nil
 }
+
+// CurrentBranch calls the mock CurrentBranchFunc if set, otherwise returns empty string.
+func (m *MockVCS) CurrentBranch(ctx context.Context) (string, error) {
+	if m.CurrentBranchFunc != nil {
+		return m.CurrentBranchFunc(ctx)
+	}
+	return "", nil
+}
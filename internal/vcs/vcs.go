@@ -27,3 +27,11 @@ type VCS interface {
 	// Returns an empty string if not in a repository or on a detached HEAD.
 	CurrentBranch(ctx context.Context) (string, error)
 }
+
+// BatchIgnoreChecker is implemented by VCS implementations that can check
+// many paths with a single lookup.
+type BatchIgnoreChecker interface {
+	// IgnoredPaths returns which of the given absolute paths are ignored.
+	// Results are not cached between calls.
+	IgnoredPaths(ctx context.Context, absPaths []string) (map[string]bool, error)
+}
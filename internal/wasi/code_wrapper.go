@@ -181,12 +181,37 @@ func Fetch(method, url, body string) (string, int) {
 }
 
 //go:wasmimport env command
-func commandHost(cmdPtr *byte, cmdLen int32, stdinPtr *byte, stdinLen int32, stdoutPtr *byte, stdoutCap int32, stderrPtr *byte, stderrCap int32) int32
+func commandHost(cmdPtr *byte, cmdLen int32, stdinPtr *byte, stdinLen int32, optsPtr *byte, optsLen int32, stdoutPtr *byte, stdoutCap int32, stderrPtr *byte, stderrCap int32) int32
+
+// CommandOptions are optional per-call settings for ExecuteCommand
+type CommandOptions struct {
+	// WorkingDir is the directory to run the command in, relative to the
+	// workspace root. It must stay inside the workspace.
+	WorkingDir string
+	// Env holds extra environment variables for the command
+	Env map[string]string
+}
 
 // Command executes a shell command with stdin input using the host's shell function.
 // The command must be provided as a slice where the first element is the binary
-// and the remaining elements are arguments. Returns stdout, stderr, and exit code.
-func ExecuteCommand(command []string, stdin string) (stdout string, stderr string, exitCode int) {
+// and the remaining elements are arguments. An optional CommandOptions sets the
+// working directory and extra environment variables. Returns stdout, stderr, and exit code.
+func ExecuteCommand(command []string, stdin string, opts ...CommandOptions) (stdout string, stderr string, exitCode int) {
+	return executeCommand(command, stdin, "", opts)
+}
+
+// ExecuteCommandWithStdinFile executes a command like ExecuteCommand, piping the
+// workspace file at stdinPath into its stdin. The host reads the file directly,
+// so large inputs never have to be loaded into the sandbox. The path is relative
+// to the workspace root and must stay inside it.
+func ExecuteCommandWithStdinFile(command []string, stdinPath string, opts ...CommandOptions) (stdout string, stderr string, exitCode int) {
+	if stdinPath == "" {
+		return "", "Error: stdin file path must not be empty", -1
+	}
+	return executeCommand(command, "", stdinPath, opts)
+}
+
+func executeCommand(command []string, stdin string, stdinFile string, opts []CommandOptions) (stdout string, stderr string, exitCode int) {
 	if len(command) == 0 {
 		return "", "Error: command must include at least one argument", -1
 	}
@@ -209,6 +234,27 @@ func ExecuteCommand(command []string, stdin string) (stdout string, stderr strin
 		stdinPtr = &stdinBytes[0]
 	}
 
+	// Prepare options
+	var optsBytes []byte
+	if len(opts) > 0 || stdinFile != "" {
+		optsMap := map[string]interface{}{}
+		if len(opts) > 0 {
+			optsMap["working_dir"] = opts[0].WorkingDir
+			optsMap["env"] = opts[0].Env
+		}
+		if stdinFile != "" {
+			optsMap["stdin_file"] = stdinFile
+		}
+		optsBytes, err = json.Marshal(optsMap)
+		if err != nil {
+			return "", fmt.Sprintf("Error: failed to marshal command options: %v", err), -1
+		}
+	}
+	var optsPtr *byte
+	if len(optsBytes) > 0 {
+		optsPtr = &optsBytes[0]
+	}
+
 	// Prepare stdout buffer (max 1MB)
 	stdoutBuffer := make([]byte, 1024*1024)
 	var stdoutPtr *byte
@@ -227,6 +273,7 @@ func ExecuteCommand(command []string, stdin string) (stdout string, stderr strin
 	exitCodeRaw := commandHost(
 		cmdPtr, int32(len(cmdBytes)),
 		stdinPtr, int32(len(stdinBytes)),
+		optsPtr, int32(len(optsBytes)),
 		stdoutPtr, int32(len(stdoutBuffer)),
 		stderrPtr, int32(len(stderrBuffer)),
 	)
@@ -150,9 +150,9 @@ func (mb *MessageBroker) ProcessUserMessage(ctx context.Context, message string,
 	pendingErrors := make(chan error, 10)
 
 	// Create auth callback - sends request to web client and waits for response
-	authCallback := func(toolName string, params map[string]interface{}, reason string) (bool, error) {
+	authCallback := orchestrator.ApprovalOnly(func(toolName string, params map[string]interface{}, reason string) (bool, error) {
 		return mb.handleAuthorization(ctx, toolName, params, reason, callback)
-	}
+	})
 
 	// Create question callback for planning agent
 	questionCallback := func(question string) (string, error) {
@@ -646,6 +646,10 @@ func (mb *MessageBroker) ListSessions(workingDir string) ([]SessionInfo, error)
 			UpdatedAt:    m.UpdatedAt,
 			MessageCount: m.MessageCount,
 		}
+		if !m.LastMessageAt.IsZero() {
+			lastMessageAt := m.LastMessageAt
+			infos[i].LastMessageAt = &lastMessageAt
+		}
 	}
 	return infos, nil
 }
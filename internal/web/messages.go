@@ -103,6 +103,8 @@ type SessionInfo struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	MessageCount int       `json:"message_count"`
+	// LastMessageAt is the timestamp of the newest message (omitted if there are none)
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
 }
 
 // ConfigInfo represents configuration information